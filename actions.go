@@ -0,0 +1,156 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/gitlab"
+)
+
+// resultActions are the -open and -copy flags of the commands printing a
+// result, taking the next step of a recruiter's workflow once it is printed
+type resultActions struct {
+	open *int
+	copy *bool
+}
+
+// resultActionFlags adds -open and -copy to fs
+func resultActionFlags(fs *flag.FlagSet) resultActions {
+	return resultActions{
+		open: fs.Int("open", 0, "open the profile of the candidate ranked `n` in a browser"),
+		copy: fs.Bool("copy", false, "copy a shortlist of the candidates to the clipboard"),
+	}
+}
+
+// check exits when -open is out of range
+func (a resultActions) check() {
+	if *a.open < 0 {
+		exitf(exitUsage, "Error: -open must be a rank, 1 or more\n")
+	}
+}
+
+// run takes the requested actions on result, sourced from the
+// comma-separated platforms source. The result is already printed, so a
+// failure only warns.
+func (a resultActions) run(result *agent.FinalResult, source string, logger *slog.Logger) {
+	if *a.open > 0 {
+		if err := openCandidate(result, *a.open, source); err != nil {
+			logger.Warn("Profile not opened", "error", err)
+		}
+	}
+	if *a.copy {
+		if len(result.TopCandidates) == 0 {
+			logger.Warn("Shortlist not copied: no candidates")
+		} else if err := copyToClipboard(agent.Shortlist(result)); err != nil {
+			logger.Warn("Shortlist not copied", "error", err)
+		} else if !quiet && !jsonLogs {
+			fmt.Fprintf(os.Stderr, "Copied a shortlist of %d candidates to the clipboard\n", len(result.TopCandidates))
+		}
+	}
+}
+
+// openCandidate opens the profile of the candidate ranked rank, built from
+// their username on source when the result has none
+func openCandidate(result *agent.FinalResult, rank int, source string) error {
+	for _, c := range result.TopCandidates {
+		if c.Rank != rank {
+			continue
+		}
+		return openBrowser(cmp.Or(c.GitHubURL, profileURL(c.Username, source)))
+	}
+	return fmt.Errorf("no candidate ranked %d of %d", rank, len(result.TopCandidates))
+}
+
+// profileURL returns the profile of username on the platform qualifying it,
+// e.g. gitlab:ana, or else on the first of the comma-separated platforms
+// source
+func profileURL(username, source string) string {
+	platform := sourceGitHub
+	if list := splitList(source); len(list) > 0 {
+		platform = list[0]
+	}
+	if qualifier, name, ok := strings.Cut(username, ":"); ok {
+		platform, username = qualifier, name
+	}
+	switch platform {
+	case sourceGitLab:
+		api := cmp.Or(os.Getenv("GITLAB_BASE_URL"), gitlab.DefaultBaseURL)
+		return strings.TrimSuffix(strings.TrimRight(api, "/"), "/api/v4") + "/" + url.PathEscape(username)
+	case sourceBitbucket:
+		return "https://bitbucket.org/" + url.PathEscape(username)
+	default:
+		return "https://github.com/" + url.PathEscape(username)
+	}
+}
+
+// openBrowser opens link with $BROWSER or the platform's URL handler. Only
+// http and https links are opened, so a profile cannot have a file or
+// another handler's URL opened on the recruiter's machine.
+func openBrowser(link string) error {
+	if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("not opening %q: not an http or https URL", link)
+	}
+	var cmd *exec.Cmd
+	switch browser := os.Getenv("BROWSER"); {
+	case browser != "":
+		cmd = exec.Command(browser, link)
+	case runtime.GOOS == "darwin":
+		cmd = exec.Command("open", link)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		cmd = exec.Command("xdg-open", link)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", cmd.Path, err)
+	}
+	return nil
+}
+
+// clipboardCommands are the commands copying stdin to the clipboard, in the
+// order tried on Linux and the BSDs
+var clipboardCommands = [][]string{
+	{"wl-copy"},
+	{"xclip", "-selection", "clipboard"},
+	{"xsel", "--clipboard", "--input"},
+}
+
+// copyToClipboard places text on the system clipboard
+func copyToClipboard(text string) error {
+	var args []string
+	switch runtime.GOOS {
+	case "darwin":
+		args = []string{"pbcopy"}
+	case "windows":
+		args = []string{"clip"}
+	default:
+		for _, candidate := range clipboardCommands {
+			// wl-copy needs a Wayland session, xclip and xsel an X display
+			if candidate[0] == "wl-copy" && os.Getenv("WAYLAND_DISPLAY") == "" {
+				continue
+			}
+			if _, err := exec.LookPath(candidate[0]); err == nil {
+				args = candidate
+				break
+			}
+		}
+		if args == nil {
+			return errors.New("no clipboard command found: install wl-clipboard, xclip or xsel")
+		}
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", args[0], err)
+	}
+	return nil
+}
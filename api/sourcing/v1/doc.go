@@ -0,0 +1,5 @@
+// Package sourcingv1 is the gRPC API of sourcing-agent serve, generated from
+// sourcing.proto with protoc-gen-go and protoc-gen-go-grpc.
+package sourcingv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative sourcing/v1/sourcing.proto
@@ -0,0 +1,1279 @@
+// The sourcing API runs the sourcing pipeline for other services. Searches
+// run as jobs of the server's queue, shared with the HTTP API of
+// sourcing-agent serve, and are identified by their run ID.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: sourcing/v1/sourcing.proto
+
+package sourcingv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SearchState int32
+
+const (
+	SearchState_SEARCH_STATE_UNSPECIFIED SearchState = 0
+	SearchState_SEARCH_STATE_QUEUED      SearchState = 1
+	SearchState_SEARCH_STATE_RUNNING     SearchState = 2
+	SearchState_SEARCH_STATE_COMPLETED   SearchState = 3
+	SearchState_SEARCH_STATE_FAILED      SearchState = 4
+	SearchState_SEARCH_STATE_CANCELLED   SearchState = 5
+)
+
+// Enum value maps for SearchState.
+var (
+	SearchState_name = map[int32]string{
+		0: "SEARCH_STATE_UNSPECIFIED",
+		1: "SEARCH_STATE_QUEUED",
+		2: "SEARCH_STATE_RUNNING",
+		3: "SEARCH_STATE_COMPLETED",
+		4: "SEARCH_STATE_FAILED",
+		5: "SEARCH_STATE_CANCELLED",
+	}
+	SearchState_value = map[string]int32{
+		"SEARCH_STATE_UNSPECIFIED": 0,
+		"SEARCH_STATE_QUEUED":      1,
+		"SEARCH_STATE_RUNNING":     2,
+		"SEARCH_STATE_COMPLETED":   3,
+		"SEARCH_STATE_FAILED":      4,
+		"SEARCH_STATE_CANCELLED":   5,
+	}
+)
+
+func (x SearchState) Enum() *SearchState {
+	p := new(SearchState)
+	*p = x
+	return p
+}
+
+func (x SearchState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SearchState) Descriptor() protoreflect.EnumDescriptor {
+	return file_sourcing_v1_sourcing_proto_enumTypes[0].Descriptor()
+}
+
+func (SearchState) Type() protoreflect.EnumType {
+	return &file_sourcing_v1_sourcing_proto_enumTypes[0]
+}
+
+func (x SearchState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SearchState.Descriptor instead.
+func (SearchState) EnumDescriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{0}
+}
+
+type StartSearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The role to source for, in natural language.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Language code of the ranking prose, overriding the server's -lang.
+	Lang string `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	// Usernames and org:name entries to leave out, added to the server's -exclude.
+	Exclude       []string `protobuf:"bytes,3,rep,name=exclude,proto3" json:"exclude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartSearchRequest) Reset() {
+	*x = StartSearchRequest{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartSearchRequest) ProtoMessage() {}
+
+func (x *StartSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartSearchRequest.ProtoReflect.Descriptor instead.
+func (*StartSearchRequest) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StartSearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *StartSearchRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *StartSearchRequest) GetExclude() []string {
+	if x != nil {
+		return x.Exclude
+	}
+	return nil
+}
+
+type WatchSearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SearchId      string                 `protobuf:"bytes,1,opt,name=search_id,json=searchId,proto3" json:"search_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSearchRequest) Reset() {
+	*x = WatchSearchRequest{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSearchRequest) ProtoMessage() {}
+
+func (x *WatchSearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSearchRequest.ProtoReflect.Descriptor instead.
+func (*WatchSearchRequest) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *WatchSearchRequest) GetSearchId() string {
+	if x != nil {
+		return x.SearchId
+	}
+	return ""
+}
+
+type GetResultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SearchId      string                 `protobuf:"bytes,1,opt,name=search_id,json=searchId,proto3" json:"search_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetResultRequest) Reset() {
+	*x = GetResultRequest{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetResultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetResultRequest) ProtoMessage() {}
+
+func (x *GetResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetResultRequest.ProtoReflect.Descriptor instead.
+func (*GetResultRequest) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetResultRequest) GetSearchId() string {
+	if x != nil {
+		return x.SearchId
+	}
+	return ""
+}
+
+// Search is a search job with its progress.
+type Search struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The run ID of the search.
+	SearchId           string                 `protobuf:"bytes,1,opt,name=search_id,json=searchId,proto3" json:"search_id,omitempty"`
+	Query              string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
+	State              SearchState            `protobuf:"varint,3,opt,name=state,proto3,enum=sourcing.v1.SearchState" json:"state,omitempty"`
+	Stages             []*StageProgress       `protobuf:"bytes,4,rep,name=stages,proto3" json:"stages,omitempty"`
+	CandidatesEnriched int32                  `protobuf:"varint,5,opt,name=candidates_enriched,json=candidatesEnriched,proto3" json:"candidates_enriched,omitempty"`
+	CreatedAt          *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	StartedAt          *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt         *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Error              string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	// The error class also reported by the run summary, e.g. llm_quota.
+	ErrorClass string `protobuf:"bytes,10,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"`
+	// Set once the search completed.
+	Result        *Result `protobuf:"bytes,11,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Search) Reset() {
+	*x = Search{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Search) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Search) ProtoMessage() {}
+
+func (x *Search) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Search.ProtoReflect.Descriptor instead.
+func (*Search) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Search) GetSearchId() string {
+	if x != nil {
+		return x.SearchId
+	}
+	return ""
+}
+
+func (x *Search) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *Search) GetState() SearchState {
+	if x != nil {
+		return x.State
+	}
+	return SearchState_SEARCH_STATE_UNSPECIFIED
+}
+
+func (x *Search) GetStages() []*StageProgress {
+	if x != nil {
+		return x.Stages
+	}
+	return nil
+}
+
+func (x *Search) GetCandidatesEnriched() int32 {
+	if x != nil {
+		return x.CandidatesEnriched
+	}
+	return 0
+}
+
+func (x *Search) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Search) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *Search) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *Search) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Search) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+func (x *Search) GetResult() *Result {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// StageProgress is the progress of one pipeline stage of a search.
+type StageProgress struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Stage string                 `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	// Running, completed, failed or cancelled.
+	State SearchState `protobuf:"varint,2,opt,name=state,proto3,enum=sourcing.v1.SearchState" json:"state,omitempty"`
+	// The duration of the stage, so far while running.
+	DurationMs    int64 `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StageProgress) Reset() {
+	*x = StageProgress{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageProgress) ProtoMessage() {}
+
+func (x *StageProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageProgress.ProtoReflect.Descriptor instead.
+func (*StageProgress) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StageProgress) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *StageProgress) GetState() SearchState {
+	if x != nil {
+		return x.State
+	}
+	return SearchState_SEARCH_STATE_UNSPECIFIED
+}
+
+func (x *StageProgress) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// SearchEvent is a pipeline event of a search.
+type SearchEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Time  *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*SearchEvent_StageStarted
+	//	*SearchEvent_StageFailed
+	//	*SearchEvent_CandidateEnriched
+	//	*SearchEvent_Finished
+	Event         isSearchEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchEvent) Reset() {
+	*x = SearchEvent{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchEvent) ProtoMessage() {}
+
+func (x *SearchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchEvent.ProtoReflect.Descriptor instead.
+func (*SearchEvent) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SearchEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetEvent() isSearchEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetStageStarted() *StageStarted {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_StageStarted); ok {
+			return x.StageStarted
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetStageFailed() *StageFailed {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_StageFailed); ok {
+			return x.StageFailed
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetCandidateEnriched() *CandidateEnriched {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_CandidateEnriched); ok {
+			return x.CandidateEnriched
+		}
+	}
+	return nil
+}
+
+func (x *SearchEvent) GetFinished() *Search {
+	if x != nil {
+		if x, ok := x.Event.(*SearchEvent_Finished); ok {
+			return x.Finished
+		}
+	}
+	return nil
+}
+
+type isSearchEvent_Event interface {
+	isSearchEvent_Event()
+}
+
+type SearchEvent_StageStarted struct {
+	StageStarted *StageStarted `protobuf:"bytes,2,opt,name=stage_started,json=stageStarted,proto3,oneof"`
+}
+
+type SearchEvent_StageFailed struct {
+	StageFailed *StageFailed `protobuf:"bytes,3,opt,name=stage_failed,json=stageFailed,proto3,oneof"`
+}
+
+type SearchEvent_CandidateEnriched struct {
+	CandidateEnriched *CandidateEnriched `protobuf:"bytes,4,opt,name=candidate_enriched,json=candidateEnriched,proto3,oneof"`
+}
+
+type SearchEvent_Finished struct {
+	// The last event of the stream: the search in its final state.
+	Finished *Search `protobuf:"bytes,5,opt,name=finished,proto3,oneof"`
+}
+
+func (*SearchEvent_StageStarted) isSearchEvent_Event() {}
+
+func (*SearchEvent_StageFailed) isSearchEvent_Event() {}
+
+func (*SearchEvent_CandidateEnriched) isSearchEvent_Event() {}
+
+func (*SearchEvent_Finished) isSearchEvent_Event() {}
+
+type StageStarted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         string                 `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StageStarted) Reset() {
+	*x = StageStarted{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageStarted) ProtoMessage() {}
+
+func (x *StageStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageStarted.ProtoReflect.Descriptor instead.
+func (*StageStarted) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StageStarted) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+type StageFailed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stage         string                 `protobuf:"bytes,1,opt,name=stage,proto3" json:"stage,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorClass    string                 `protobuf:"bytes,3,opt,name=error_class,json=errorClass,proto3" json:"error_class,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StageFailed) Reset() {
+	*x = StageFailed{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StageFailed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StageFailed) ProtoMessage() {}
+
+func (x *StageFailed) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StageFailed.ProtoReflect.Descriptor instead.
+func (*StageFailed) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *StageFailed) GetStage() string {
+	if x != nil {
+		return x.Stage
+	}
+	return ""
+}
+
+func (x *StageFailed) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *StageFailed) GetErrorClass() string {
+	if x != nil {
+		return x.ErrorClass
+	}
+	return ""
+}
+
+// CandidateEnriched reports a candidate found and enriched with their
+// repositories, before ranking.
+type CandidateEnriched struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Username             string                 `protobuf:"bytes,1,opt,name=username,proto3" json:"username,omitempty"`
+	RelevantRepositories int32                  `protobuf:"varint,2,opt,name=relevant_repositories,json=relevantRepositories,proto3" json:"relevant_repositories,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *CandidateEnriched) Reset() {
+	*x = CandidateEnriched{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CandidateEnriched) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CandidateEnriched) ProtoMessage() {}
+
+func (x *CandidateEnriched) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CandidateEnriched.ProtoReflect.Descriptor instead.
+func (*CandidateEnriched) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CandidateEnriched) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *CandidateEnriched) GetRelevantRepositories() int32 {
+	if x != nil {
+		return x.RelevantRepositories
+	}
+	return 0
+}
+
+type Result struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TopCandidates []*RankedCandidate     `protobuf:"bytes,1,rep,name=top_candidates,json=topCandidates,proto3" json:"top_candidates,omitempty"`
+	Summary       *ResultSummary         `protobuf:"bytes,2,opt,name=summary,proto3" json:"summary,omitempty"`
+	// Set when ranking failed and the candidates are presented unranked.
+	Partial       bool `protobuf:"varint,3,opt,name=partial,proto3" json:"partial,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Result) Reset() {
+	*x = Result{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Result) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Result) ProtoMessage() {}
+
+func (x *Result) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Result.ProtoReflect.Descriptor instead.
+func (*Result) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Result) GetTopCandidates() []*RankedCandidate {
+	if x != nil {
+		return x.TopCandidates
+	}
+	return nil
+}
+
+func (x *Result) GetSummary() *ResultSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *Result) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+type RankedCandidate struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Rank                int32                  `protobuf:"varint,1,opt,name=rank,proto3" json:"rank,omitempty"`
+	Username            string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	Name                string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Location            string                 `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	GithubUrl           string                 `protobuf:"bytes,5,opt,name=github_url,json=githubUrl,proto3" json:"github_url,omitempty"`
+	FinalMatchScore     float64                `protobuf:"fixed64,6,opt,name=final_match_score,json=finalMatchScore,proto3" json:"final_match_score,omitempty"`
+	MatchBreakdown      *MatchBreakdown        `protobuf:"bytes,7,opt,name=match_breakdown,json=matchBreakdown,proto3" json:"match_breakdown,omitempty"`
+	KeyQualifications   []string               `protobuf:"bytes,8,rep,name=key_qualifications,json=keyQualifications,proto3" json:"key_qualifications,omitempty"`
+	TopRelevantProjects []*RelevantProject     `protobuf:"bytes,9,rep,name=top_relevant_projects,json=topRelevantProjects,proto3" json:"top_relevant_projects,omitempty"`
+	MatchReasoning      string                 `protobuf:"bytes,10,opt,name=match_reasoning,json=matchReasoning,proto3" json:"match_reasoning,omitempty"`
+	PotentialConcerns   string                 `protobuf:"bytes,11,opt,name=potential_concerns,json=potentialConcerns,proto3" json:"potential_concerns,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *RankedCandidate) Reset() {
+	*x = RankedCandidate{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RankedCandidate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RankedCandidate) ProtoMessage() {}
+
+func (x *RankedCandidate) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RankedCandidate.ProtoReflect.Descriptor instead.
+func (*RankedCandidate) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RankedCandidate) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *RankedCandidate) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+func (x *RankedCandidate) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RankedCandidate) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *RankedCandidate) GetGithubUrl() string {
+	if x != nil {
+		return x.GithubUrl
+	}
+	return ""
+}
+
+func (x *RankedCandidate) GetFinalMatchScore() float64 {
+	if x != nil {
+		return x.FinalMatchScore
+	}
+	return 0
+}
+
+func (x *RankedCandidate) GetMatchBreakdown() *MatchBreakdown {
+	if x != nil {
+		return x.MatchBreakdown
+	}
+	return nil
+}
+
+func (x *RankedCandidate) GetKeyQualifications() []string {
+	if x != nil {
+		return x.KeyQualifications
+	}
+	return nil
+}
+
+func (x *RankedCandidate) GetTopRelevantProjects() []*RelevantProject {
+	if x != nil {
+		return x.TopRelevantProjects
+	}
+	return nil
+}
+
+func (x *RankedCandidate) GetMatchReasoning() string {
+	if x != nil {
+		return x.MatchReasoning
+	}
+	return ""
+}
+
+func (x *RankedCandidate) GetPotentialConcerns() string {
+	if x != nil {
+		return x.PotentialConcerns
+	}
+	return ""
+}
+
+type MatchBreakdown struct {
+	state                    protoimpl.MessageState `protogen:"open.v1"`
+	RequiredSkillsScore      float64                `protobuf:"fixed64,1,opt,name=required_skills_score,json=requiredSkillsScore,proto3" json:"required_skills_score,omitempty"`
+	RepositoryRelevanceScore float64                `protobuf:"fixed64,2,opt,name=repository_relevance_score,json=repositoryRelevanceScore,proto3" json:"repository_relevance_score,omitempty"`
+	ExperienceScore          float64                `protobuf:"fixed64,3,opt,name=experience_score,json=experienceScore,proto3" json:"experience_score,omitempty"`
+	ProfileQualityScore      float64                `protobuf:"fixed64,4,opt,name=profile_quality_score,json=profileQualityScore,proto3" json:"profile_quality_score,omitempty"`
+	unknownFields            protoimpl.UnknownFields
+	sizeCache                protoimpl.SizeCache
+}
+
+func (x *MatchBreakdown) Reset() {
+	*x = MatchBreakdown{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchBreakdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchBreakdown) ProtoMessage() {}
+
+func (x *MatchBreakdown) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchBreakdown.ProtoReflect.Descriptor instead.
+func (*MatchBreakdown) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *MatchBreakdown) GetRequiredSkillsScore() float64 {
+	if x != nil {
+		return x.RequiredSkillsScore
+	}
+	return 0
+}
+
+func (x *MatchBreakdown) GetRepositoryRelevanceScore() float64 {
+	if x != nil {
+		return x.RepositoryRelevanceScore
+	}
+	return 0
+}
+
+func (x *MatchBreakdown) GetExperienceScore() float64 {
+	if x != nil {
+		return x.ExperienceScore
+	}
+	return 0
+}
+
+func (x *MatchBreakdown) GetProfileQualityScore() float64 {
+	if x != nil {
+		return x.ProfileQualityScore
+	}
+	return 0
+}
+
+type RelevantProject struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	WhyRelevant   string                 `protobuf:"bytes,3,opt,name=why_relevant,json=whyRelevant,proto3" json:"why_relevant,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelevantProject) Reset() {
+	*x = RelevantProject{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelevantProject) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelevantProject) ProtoMessage() {}
+
+func (x *RelevantProject) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelevantProject.ProtoReflect.Descriptor instead.
+func (*RelevantProject) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RelevantProject) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *RelevantProject) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *RelevantProject) GetWhyRelevant() string {
+	if x != nil {
+		return x.WhyRelevant
+	}
+	return ""
+}
+
+type ResultSummary struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	TotalCandidatesFound int32                  `protobuf:"varint,1,opt,name=total_candidates_found,json=totalCandidatesFound,proto3" json:"total_candidates_found,omitempty"`
+	CandidatesPresented  int32                  `protobuf:"varint,2,opt,name=candidates_presented,json=candidatesPresented,proto3" json:"candidates_presented,omitempty"`
+	AverageMatchScore    float64                `protobuf:"fixed64,3,opt,name=average_match_score,json=averageMatchScore,proto3" json:"average_match_score,omitempty"`
+	SearchQuality        string                 `protobuf:"bytes,4,opt,name=search_quality,json=searchQuality,proto3" json:"search_quality,omitempty"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *ResultSummary) Reset() {
+	*x = ResultSummary{}
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResultSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResultSummary) ProtoMessage() {}
+
+func (x *ResultSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_sourcing_v1_sourcing_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResultSummary.ProtoReflect.Descriptor instead.
+func (*ResultSummary) Descriptor() ([]byte, []int) {
+	return file_sourcing_v1_sourcing_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ResultSummary) GetTotalCandidatesFound() int32 {
+	if x != nil {
+		return x.TotalCandidatesFound
+	}
+	return 0
+}
+
+func (x *ResultSummary) GetCandidatesPresented() int32 {
+	if x != nil {
+		return x.CandidatesPresented
+	}
+	return 0
+}
+
+func (x *ResultSummary) GetAverageMatchScore() float64 {
+	if x != nil {
+		return x.AverageMatchScore
+	}
+	return 0
+}
+
+func (x *ResultSummary) GetSearchQuality() string {
+	if x != nil {
+		return x.SearchQuality
+	}
+	return ""
+}
+
+var File_sourcing_v1_sourcing_proto protoreflect.FileDescriptor
+
+const file_sourcing_v1_sourcing_proto_rawDesc = "" +
+	"\n" +
+	"\x1asourcing/v1/sourcing.proto\x12\vsourcing.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"X\n" +
+	"\x12StartSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x12\n" +
+	"\x04lang\x18\x02 \x01(\tR\x04lang\x12\x18\n" +
+	"\aexclude\x18\x03 \x03(\tR\aexclude\"1\n" +
+	"\x12WatchSearchRequest\x12\x1b\n" +
+	"\tsearch_id\x18\x01 \x01(\tR\bsearchId\"/\n" +
+	"\x10GetResultRequest\x12\x1b\n" +
+	"\tsearch_id\x18\x01 \x01(\tR\bsearchId\"\xe7\x03\n" +
+	"\x06Search\x12\x1b\n" +
+	"\tsearch_id\x18\x01 \x01(\tR\bsearchId\x12\x14\n" +
+	"\x05query\x18\x02 \x01(\tR\x05query\x12.\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x18.sourcing.v1.SearchStateR\x05state\x122\n" +
+	"\x06stages\x18\x04 \x03(\v2\x1a.sourcing.v1.StageProgressR\x06stages\x12/\n" +
+	"\x13candidates_enriched\x18\x05 \x01(\x05R\x12candidatesEnriched\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"started_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12\x1f\n" +
+	"\verror_class\x18\n" +
+	" \x01(\tR\n" +
+	"errorClass\x12+\n" +
+	"\x06result\x18\v \x01(\v2\x13.sourcing.v1.ResultR\x06result\"v\n" +
+	"\rStageProgress\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\tR\x05stage\x12.\n" +
+	"\x05state\x18\x02 \x01(\x0e2\x18.sourcing.v1.SearchStateR\x05state\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x03R\n" +
+	"durationMs\"\xcb\x02\n" +
+	"\vSearchEvent\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12@\n" +
+	"\rstage_started\x18\x02 \x01(\v2\x19.sourcing.v1.StageStartedH\x00R\fstageStarted\x12=\n" +
+	"\fstage_failed\x18\x03 \x01(\v2\x18.sourcing.v1.StageFailedH\x00R\vstageFailed\x12O\n" +
+	"\x12candidate_enriched\x18\x04 \x01(\v2\x1e.sourcing.v1.CandidateEnrichedH\x00R\x11candidateEnriched\x121\n" +
+	"\bfinished\x18\x05 \x01(\v2\x13.sourcing.v1.SearchH\x00R\bfinishedB\a\n" +
+	"\x05event\"$\n" +
+	"\fStageStarted\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\tR\x05stage\"Z\n" +
+	"\vStageFailed\x12\x14\n" +
+	"\x05stage\x18\x01 \x01(\tR\x05stage\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12\x1f\n" +
+	"\verror_class\x18\x03 \x01(\tR\n" +
+	"errorClass\"d\n" +
+	"\x11CandidateEnriched\x12\x1a\n" +
+	"\busername\x18\x01 \x01(\tR\busername\x123\n" +
+	"\x15relevant_repositories\x18\x02 \x01(\x05R\x14relevantRepositories\"\x9d\x01\n" +
+	"\x06Result\x12C\n" +
+	"\x0etop_candidates\x18\x01 \x03(\v2\x1c.sourcing.v1.RankedCandidateR\rtopCandidates\x124\n" +
+	"\asummary\x18\x02 \x01(\v2\x1a.sourcing.v1.ResultSummaryR\asummary\x12\x18\n" +
+	"\apartial\x18\x03 \x01(\bR\apartial\"\xdb\x03\n" +
+	"\x0fRankedCandidate\x12\x12\n" +
+	"\x04rank\x18\x01 \x01(\x05R\x04rank\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x1a\n" +
+	"\blocation\x18\x04 \x01(\tR\blocation\x12\x1d\n" +
+	"\n" +
+	"github_url\x18\x05 \x01(\tR\tgithubUrl\x12*\n" +
+	"\x11final_match_score\x18\x06 \x01(\x01R\x0ffinalMatchScore\x12D\n" +
+	"\x0fmatch_breakdown\x18\a \x01(\v2\x1b.sourcing.v1.MatchBreakdownR\x0ematchBreakdown\x12-\n" +
+	"\x12key_qualifications\x18\b \x03(\tR\x11keyQualifications\x12P\n" +
+	"\x15top_relevant_projects\x18\t \x03(\v2\x1c.sourcing.v1.RelevantProjectR\x13topRelevantProjects\x12'\n" +
+	"\x0fmatch_reasoning\x18\n" +
+	" \x01(\tR\x0ematchReasoning\x12-\n" +
+	"\x12potential_concerns\x18\v \x01(\tR\x11potentialConcerns\"\xe1\x01\n" +
+	"\x0eMatchBreakdown\x122\n" +
+	"\x15required_skills_score\x18\x01 \x01(\x01R\x13requiredSkillsScore\x12<\n" +
+	"\x1arepository_relevance_score\x18\x02 \x01(\x01R\x18repositoryRelevanceScore\x12)\n" +
+	"\x10experience_score\x18\x03 \x01(\x01R\x0fexperienceScore\x122\n" +
+	"\x15profile_quality_score\x18\x04 \x01(\x01R\x13profileQualityScore\"Z\n" +
+	"\x0fRelevantProject\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12!\n" +
+	"\fwhy_relevant\x18\x03 \x01(\tR\vwhyRelevant\"\xcf\x01\n" +
+	"\rResultSummary\x124\n" +
+	"\x16total_candidates_found\x18\x01 \x01(\x05R\x14totalCandidatesFound\x121\n" +
+	"\x14candidates_presented\x18\x02 \x01(\x05R\x13candidatesPresented\x12.\n" +
+	"\x13average_match_score\x18\x03 \x01(\x01R\x11averageMatchScore\x12%\n" +
+	"\x0esearch_quality\x18\x04 \x01(\tR\rsearchQuality*\xaf\x01\n" +
+	"\vSearchState\x12\x1c\n" +
+	"\x18SEARCH_STATE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13SEARCH_STATE_QUEUED\x10\x01\x12\x18\n" +
+	"\x14SEARCH_STATE_RUNNING\x10\x02\x12\x1a\n" +
+	"\x16SEARCH_STATE_COMPLETED\x10\x03\x12\x17\n" +
+	"\x13SEARCH_STATE_FAILED\x10\x04\x12\x1a\n" +
+	"\x16SEARCH_STATE_CANCELLED\x10\x052\xe3\x01\n" +
+	"\x0fSourcingService\x12C\n" +
+	"\vStartSearch\x12\x1f.sourcing.v1.StartSearchRequest\x1a\x13.sourcing.v1.Search\x12J\n" +
+	"\vWatchSearch\x12\x1f.sourcing.v1.WatchSearchRequest\x1a\x18.sourcing.v1.SearchEvent0\x01\x12?\n" +
+	"\tGetResult\x12\x1d.sourcing.v1.GetResultRequest\x1a\x13.sourcing.v1.SearchB?Z=github.com/luillyfe/sourcing-agent/api/sourcing/v1;sourcingv1b\x06proto3"
+
+var (
+	file_sourcing_v1_sourcing_proto_rawDescOnce sync.Once
+	file_sourcing_v1_sourcing_proto_rawDescData []byte
+)
+
+func file_sourcing_v1_sourcing_proto_rawDescGZIP() []byte {
+	file_sourcing_v1_sourcing_proto_rawDescOnce.Do(func() {
+		file_sourcing_v1_sourcing_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sourcing_v1_sourcing_proto_rawDesc), len(file_sourcing_v1_sourcing_proto_rawDesc)))
+	})
+	return file_sourcing_v1_sourcing_proto_rawDescData
+}
+
+var file_sourcing_v1_sourcing_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sourcing_v1_sourcing_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_sourcing_v1_sourcing_proto_goTypes = []any{
+	(SearchState)(0),              // 0: sourcing.v1.SearchState
+	(*StartSearchRequest)(nil),    // 1: sourcing.v1.StartSearchRequest
+	(*WatchSearchRequest)(nil),    // 2: sourcing.v1.WatchSearchRequest
+	(*GetResultRequest)(nil),      // 3: sourcing.v1.GetResultRequest
+	(*Search)(nil),                // 4: sourcing.v1.Search
+	(*StageProgress)(nil),         // 5: sourcing.v1.StageProgress
+	(*SearchEvent)(nil),           // 6: sourcing.v1.SearchEvent
+	(*StageStarted)(nil),          // 7: sourcing.v1.StageStarted
+	(*StageFailed)(nil),           // 8: sourcing.v1.StageFailed
+	(*CandidateEnriched)(nil),     // 9: sourcing.v1.CandidateEnriched
+	(*Result)(nil),                // 10: sourcing.v1.Result
+	(*RankedCandidate)(nil),       // 11: sourcing.v1.RankedCandidate
+	(*MatchBreakdown)(nil),        // 12: sourcing.v1.MatchBreakdown
+	(*RelevantProject)(nil),       // 13: sourcing.v1.RelevantProject
+	(*ResultSummary)(nil),         // 14: sourcing.v1.ResultSummary
+	(*timestamppb.Timestamp)(nil), // 15: google.protobuf.Timestamp
+}
+var file_sourcing_v1_sourcing_proto_depIdxs = []int32{
+	0,  // 0: sourcing.v1.Search.state:type_name -> sourcing.v1.SearchState
+	5,  // 1: sourcing.v1.Search.stages:type_name -> sourcing.v1.StageProgress
+	15, // 2: sourcing.v1.Search.created_at:type_name -> google.protobuf.Timestamp
+	15, // 3: sourcing.v1.Search.started_at:type_name -> google.protobuf.Timestamp
+	15, // 4: sourcing.v1.Search.finished_at:type_name -> google.protobuf.Timestamp
+	10, // 5: sourcing.v1.Search.result:type_name -> sourcing.v1.Result
+	0,  // 6: sourcing.v1.StageProgress.state:type_name -> sourcing.v1.SearchState
+	15, // 7: sourcing.v1.SearchEvent.time:type_name -> google.protobuf.Timestamp
+	7,  // 8: sourcing.v1.SearchEvent.stage_started:type_name -> sourcing.v1.StageStarted
+	8,  // 9: sourcing.v1.SearchEvent.stage_failed:type_name -> sourcing.v1.StageFailed
+	9,  // 10: sourcing.v1.SearchEvent.candidate_enriched:type_name -> sourcing.v1.CandidateEnriched
+	4,  // 11: sourcing.v1.SearchEvent.finished:type_name -> sourcing.v1.Search
+	11, // 12: sourcing.v1.Result.top_candidates:type_name -> sourcing.v1.RankedCandidate
+	14, // 13: sourcing.v1.Result.summary:type_name -> sourcing.v1.ResultSummary
+	12, // 14: sourcing.v1.RankedCandidate.match_breakdown:type_name -> sourcing.v1.MatchBreakdown
+	13, // 15: sourcing.v1.RankedCandidate.top_relevant_projects:type_name -> sourcing.v1.RelevantProject
+	1,  // 16: sourcing.v1.SourcingService.StartSearch:input_type -> sourcing.v1.StartSearchRequest
+	2,  // 17: sourcing.v1.SourcingService.WatchSearch:input_type -> sourcing.v1.WatchSearchRequest
+	3,  // 18: sourcing.v1.SourcingService.GetResult:input_type -> sourcing.v1.GetResultRequest
+	4,  // 19: sourcing.v1.SourcingService.StartSearch:output_type -> sourcing.v1.Search
+	6,  // 20: sourcing.v1.SourcingService.WatchSearch:output_type -> sourcing.v1.SearchEvent
+	4,  // 21: sourcing.v1.SourcingService.GetResult:output_type -> sourcing.v1.Search
+	19, // [19:22] is the sub-list for method output_type
+	16, // [16:19] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
+}
+
+func init() { file_sourcing_v1_sourcing_proto_init() }
+func file_sourcing_v1_sourcing_proto_init() {
+	if File_sourcing_v1_sourcing_proto != nil {
+		return
+	}
+	file_sourcing_v1_sourcing_proto_msgTypes[5].OneofWrappers = []any{
+		(*SearchEvent_StageStarted)(nil),
+		(*SearchEvent_StageFailed)(nil),
+		(*SearchEvent_CandidateEnriched)(nil),
+		(*SearchEvent_Finished)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sourcing_v1_sourcing_proto_rawDesc), len(file_sourcing_v1_sourcing_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_sourcing_v1_sourcing_proto_goTypes,
+		DependencyIndexes: file_sourcing_v1_sourcing_proto_depIdxs,
+		EnumInfos:         file_sourcing_v1_sourcing_proto_enumTypes,
+		MessageInfos:      file_sourcing_v1_sourcing_proto_msgTypes,
+	}.Build()
+	File_sourcing_v1_sourcing_proto = out.File
+	file_sourcing_v1_sourcing_proto_goTypes = nil
+	file_sourcing_v1_sourcing_proto_depIdxs = nil
+}
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/crawler"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/notify"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+	"github.com/luillyfe/sourcing-agent/pkg/vectorstore"
+	"github.com/luillyfe/sourcing-agent/pkg/vertexai"
+	"google.golang.org/genai"
+)
+
+// appOptions selects what newApp sets up for a subcommand
+type appOptions struct {
+	RunID string
+	Query string // Recorded in the audit log and the run recording
+	// Source and LLM select the clients the subcommand needs; credentials
+	// are only required for those
+	Source bool
+	LLM    bool
+	// Provider names the LLM provider; defaults to LLM_PROVIDER or Vertex AI
+	Provider string
+	// SourceName names the source platforms, comma-separated; defaults to
+	// SOURCE_PROVIDER or GitHub
+	SourceName string
+	// RecordDir, if set, records every LLM call and source request for replay
+	RecordDir string
+	// Store opens the database runs are stored in, if DATABASE_URL is set,
+	// and with LLM the index of their candidates' embeddings, if
+	// VECTOR_STORE_URL is set. With Source, the database also caches the
+	// developer profiles fetched, which searches use first.
+	Store bool
+	// Uncached leaves the source out of the profile cache, e.g. for the
+	// crawler filling it
+	Uncached bool
+	// SourceThrottle, if set, paces the requests to the source platforms
+	SourceThrottle observability.TransportMiddleware
+	// Notify sets up the notifications of new candidates, if configured
+	Notify bool
+	// Digest adds the email digest to the notifications, for the scheduled
+	// searches of batch and watch
+	Digest bool
+}
+
+// app holds the clients and observability shared by the subcommands. Both
+// clients report into usage.
+type app struct {
+	logger *slog.Logger
+	usage  *observability.UsageCollector
+	redact func(string) string
+
+	source    agent.SourceProvider // Nil unless appOptions.Source
+	enrichers []agent.Enricher     // Named by ENRICHERS, with appOptions.Source
+	llm       llm.Client           // Nil unless appOptions.LLM
+	embedder  llm.Embedder         // Nil unless the provider has embeddings
+	vertex    *vertexai.Client     // Nil unless the provider is Vertex AI
+	failover  *llm.FailoverClient  // Nil unless a second provider is configured
+	cache     *llm.CacheClient     // Nil unless LLM_CACHE_DIR is set
+	store     *storage.Store       // Nil unless appOptions.Store and DATABASE_URL is set
+	index     *vectorstore.Indexer // Nil unless appOptions.Store and LLM, and VECTOR_STORE_URL is set
+	notifier  notify.Notifier      // Nil unless appOptions.Notify and a notifier is configured
+
+	closers []func()
+}
+
+// newApp builds the clients selected by opts from the environment, exiting
+// with a hint when a required setting is missing
+func newApp(ctx context.Context, logger *slog.Logger, opts appOptions) *app {
+	a := &app{logger: logger, usage: observability.NewUsageCollector()}
+	if opts.SourceName == "" {
+		opts.SourceName = defaultSource()
+	}
+
+	// Candidate personal data masked in the LLM log, redacted recordings and error reports
+	redaction, err := llm.ParseRedaction(os.Getenv("PII_REDACTION"))
+	if err != nil {
+		exitf(exitConfig, "Error parsing PII_REDACTION: %v\n", err)
+	}
+	a.redact = redaction.Redactor()
+
+	// Optional recording of every LLM call and source request, replayable with search -replay
+	var recorder *observability.Recorder
+	if opts.RecordDir != "" {
+		config := observability.RecorderConfig{Dir: opts.RecordDir, RunID: opts.RunID, Query: opts.Query, Source: opts.SourceName, Enrichers: envList("ENRICHERS"), Logger: logger}
+		if os.Getenv("RUN_RECORD_REDACT") == "true" {
+			config.Redact = a.redact
+		}
+		r, err := observability.NewRecorder(config)
+		if err != nil {
+			exitf(exitConfig, "Error initializing run recorder: %v\n", err)
+		}
+		recorder = r
+	}
+
+	// Optional database of runs, queryable with the runs command
+	if url := os.Getenv("DATABASE_URL"); opts.Store && url != "" {
+		store, err := storage.Open(ctx, url)
+		if err != nil {
+			exitf(exitConfig, "Error opening DATABASE_URL: %v\n", err)
+		}
+		a.closers = append(a.closers, func() { store.Close() })
+		a.store = store
+	}
+	if opts.Source {
+		// The enrichers share the source's client, so their lookups are counted, audited and recorded too
+		httpClient := a.sourceHTTPClient(opts, recorder)
+		a.source = a.newSource(opts, httpClient)
+		// Profiles cached in the database, e.g. by the crawl command, spare searches most requests.
+		// A recorded run makes every request, so it replays without the cache.
+		maxAge, err := profileCacheTTL()
+		if err != nil {
+			exitf(exitConfig, "Error parsing %v\n", err)
+		}
+		if a.store != nil && !opts.Uncached && recorder == nil && maxAge > 0 {
+			a.source = &crawler.CachedSource{Source: a.source, Cache: a.store, MaxAge: maxAge, Logger: logger}
+		}
+		enrichers, err := newEnrichers(envList("ENRICHERS"), httpClient, logger)
+		if err != nil {
+			exitf(exitConfig, "Error initializing ENRICHERS: %v\n", err)
+		}
+		a.enrichers = enrichers
+	}
+	if opts.LLM {
+		a.newLLMClient(ctx, opts, recorder)
+	}
+	// Optional index of the candidates of finished runs, searched by the similar command
+	if url := os.Getenv("VECTOR_STORE_URL"); opts.Store && opts.LLM && url != "" {
+		if a.embedder == nil {
+			exitf(exitConfig, "Error: VECTOR_STORE_URL needs an LLM provider with embeddings: %s, %s or %s\n", providerVertexAI, providerOpenAI, providerOllama)
+		}
+		vectors, err := vectorstore.Open(ctx, url)
+		if err != nil {
+			exitf(exitConfig, "Error opening VECTOR_STORE_URL: %v\n", err)
+		}
+		a.closers = append(a.closers, func() { vectors.Close() })
+		a.index = &vectorstore.Indexer{Store: vectors, Embedder: a.embedder}
+	}
+	if opts.Notify {
+		a.notifier = newNotifier(logger, opts.Digest)
+	}
+	return a
+}
+
+// profileCacheTTL returns PROFILE_CACHE_TTL, how long searches use cached
+// profiles, or crawler.DefaultMaxAge when unset; zero disables the cache
+func profileCacheTTL() (time.Duration, error) {
+	if os.Getenv("PROFILE_CACHE_TTL") == "" {
+		return crawler.DefaultMaxAge, nil
+	}
+	return envDuration("PROFILE_CACHE_TTL")
+}
+
+// newNotifier builds the notifiers configured in the environment, nil when
+// there are none. The email digest is only included with digest.
+func newNotifier(logger *slog.Logger, digest bool) notify.Notifier {
+	var notifiers notify.Multi
+	// Optional Slack message of the new top candidates of each run
+	if webhook, token := os.Getenv("SLACK_WEBHOOK_URL"), os.Getenv("SLACK_BOT_TOKEN"); webhook != "" || token != "" {
+		channel := os.Getenv("SLACK_CHANNEL")
+		if token != "" && channel == "" {
+			exitf(exitConfig, "Error: SLACK_CHANNEL must name the channel SLACK_BOT_TOKEN posts to\n")
+		}
+		notifiers = append(notifiers, &notify.Slack{WebhookURL: webhook, Token: token, Channel: channel, Logger: logger})
+	}
+	// Optional email digest of the run report, for hiring managers who do not run the CLI
+	if host := os.Getenv("SMTP_HOST"); digest && host != "" {
+		if err := requireEnv("DIGEST_FROM", "DIGEST_TO"); err != nil {
+			exitf(exitConfig, "Error configuring the email digest: %v\n", err)
+		}
+		port := os.Getenv("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		notifiers = append(notifiers, &notify.Email{
+			Addr:     net.JoinHostPort(host, port),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("DIGEST_FROM"),
+			To:       envList("DIGEST_TO"),
+			Logger:   logger,
+		})
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notifiers
+}
+
+// notify tells the configured notifiers about the new candidates of the
+// result of query. A failure is only logged: the run itself succeeded.
+func (a *app) notify(ctx context.Context, query string, result *agent.FinalResult, candidates []agent.RankedCandidate) {
+	if a.notifier == nil || len(candidates) == 0 || result.Partial {
+		return
+	}
+	n := notify.Notification{
+		Query:      query,
+		RunID:      result.RunID,
+		Candidates: candidates,
+		Presented:  len(result.TopCandidates),
+		Result:     result,
+	}
+	if err := a.notifier.Notify(ctx, n); err != nil {
+		a.logger.Warn("Notification failed", "run_id", result.RunID, "error", err)
+	}
+}
+
+// runStore returns the store runs are saved to, nil without DATABASE_URL
+// or VECTOR_STORE_URL
+func (a *app) runStore() agent.RunStore {
+	switch {
+	case a.store != nil && a.index != nil:
+		return indexedStore{Store: a.store, index: a.index}
+	case a.store != nil:
+		return a.store
+	case a.index != nil:
+		return a.index
+	}
+	return nil
+}
+
+// indexedStore saves runs to the database and indexes their candidates,
+// keeping the database's candidate history and learned scoring
+type indexedStore struct {
+	*storage.Store
+	index *vectorstore.Indexer
+}
+
+func (s indexedStore) SaveRun(ctx context.Context, run agent.RunRecord) error {
+	return errors.Join(s.Store.SaveRun(ctx, run), s.index.SaveRun(ctx, run))
+}
+
+// Close releases the clients and flushes the logs opened by newApp
+func (a *app) Close() {
+	for i := len(a.closers) - 1; i >= 0; i-- {
+		a.closers[i]()
+	}
+}
+
+// newSource builds the client of the platforms developers are sourced from,
+// opts.SourceName
+func (a *app) newSource(opts appOptions, httpClient *http.Client) agent.SourceProvider {
+	if slices.Contains(splitList(opts.SourceName), sourceGitHub) && os.Getenv("GITHUB_TOKEN") == "" {
+		exitf(exitConfig, "Error: GITHUB_TOKEN environment variable is not set\nPlease create a .env file with your GitHub token or set it as an environment variable\n")
+	}
+	source, err := newSources(opts.SourceName, httpClient, a.logger)
+	if err != nil {
+		exitf(exitConfig, "Error initializing source: %v\n", err)
+	}
+	return source
+}
+
+// sourceHTTPClient returns the HTTP client of the source platform and the
+// enrichers, counting, auditing and recording their requests
+func (a *app) sourceHTTPClient(opts appOptions, recorder *observability.Recorder) *http.Client {
+	// Optional append-only trail of every developer profile accessed, for compliance review
+	var auditTransport observability.TransportMiddleware
+	if auditPath := os.Getenv("AUDIT_LOG_FILE"); auditPath != "" {
+		auditLog, err := observability.OpenAuditLog(auditPath, opts.RunID, opts.Query)
+		if err != nil {
+			exitf(exitConfig, "Error initializing audit log: %v\n", err)
+		}
+		a.closers = append(a.closers, func() { auditLog.Close() })
+		auditTransport = auditLog.Transport
+	}
+	var recordTransport observability.TransportMiddleware
+	if recorder != nil {
+		recordTransport = recorder.Transport
+	}
+	// Outermost first: count → audit → record → throttle
+	transport := observability.ChainTransport(http.DefaultTransport,
+		observability.CountingTransportMiddleware(a.usage),
+		auditTransport,
+		recordTransport,
+		opts.SourceThrottle,
+	)
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+	}
+}
+
+func (a *app) newLLMClient(ctx context.Context, opts appOptions, recorder *observability.Recorder) {
+	provider := opts.Provider
+	if provider == "" {
+		provider = defaultLLMProvider()
+	}
+	client, err := llmProviders().New(ctx, provider)
+	if err != nil {
+		hint := ""
+		var authErr *vertexai.AuthError
+		if errors.As(err, &authErr) {
+			hint = "\nRun 'gcloud auth application-default login', or set VERTEX_CREDENTIALS_FILE to a service account key or workload identity config"
+		}
+		exitf(exitConfig, "Error initializing LLM provider: %v%s\nPlease create a .env file with the provider's settings or set them as environment variables\n", err, hint)
+	}
+	if closer, ok := client.(interface{ Close() error }); ok {
+		a.closers = append(a.closers, func() { closer.Close() })
+	}
+	a.vertex, _ = client.(*vertexai.Client)
+	a.embedder, _ = client.(llm.Embedder)
+	model := providerModel(client)
+
+	// Bound each provider call so a hung request fails fast and can be retried or failed over
+	callTimeout, err := envDuration("LLM_CALL_TIMEOUT")
+	if err != nil {
+		exitf(exitConfig, "Error parsing %v\n", err)
+	}
+	var llmClient llm.Client = llm.WithTimeout(client, callTimeout)
+	// Fail over from Vertex AI to Anthropic when Vertex is rate limited or unavailable, if configured
+	if anthropicConfig, ok := anthropicPlatform(); ok && provider == providerVertexAI {
+		a.failover = llm.NewFailoverClient(
+			llm.Provider{Name: providerVertexAI, Client: llmClient},
+			llm.Provider{Name: providerAnthropic, Client: llm.WithTimeout(newAnthropicClient(anthropicConfig), callTimeout)},
+		)
+		llmClient = a.failover
+	}
+	// Optional client-side quotas keep long runs under provider limits
+	var rateLimit observability.Middleware
+	rateLimitConfig := llm.RateLimitConfig{
+		RequestsPerMinute: envInt("LLM_REQUESTS_PER_MINUTE"),
+		TokensPerMinute:   envInt("LLM_TOKENS_PER_MINUTE"),
+	}
+	if rateLimitConfig.RequestsPerMinute > 0 || rateLimitConfig.TokensPerMinute > 0 {
+		rateLimit = observability.RateLimitMiddleware(rateLimitConfig)
+	}
+	// Optional on-disk response cache, handy for repeated development runs
+	var cache observability.Middleware
+	if cacheDir := os.Getenv("LLM_CACHE_DIR"); cacheDir != "" {
+		store, err := llm.NewFileCache(cacheDir)
+		if err != nil {
+			exitf(exitConfig, "Error initializing LLM cache: %v\n", err)
+		}
+		ttl, err := envDuration("LLM_CACHE_TTL")
+		if err != nil {
+			exitf(exitConfig, "Error parsing %v\n", err)
+		}
+		cache = func(c llm.Client) llm.Client {
+			a.cache = llm.WithCache(c, llm.CacheConfig{Model: model, ModelFor: providerModelFor(client), TTL: ttl, Store: store})
+			return a.cache
+		}
+	}
+	// Optional prompt/response log for debugging prompt regressions
+	var logging observability.Middleware
+	if logPath := os.Getenv("LLM_LOG_FILE"); logPath != "" {
+		logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			exitf(exitConfig, "Error opening LLM log file: %v\n", err)
+		}
+		a.closers = append(a.closers, func() { logFile.Close() })
+		logger := slog.New(slog.NewJSONHandler(logFile, nil))
+		if opts.RunID != "" {
+			logger = logger.With("run_id", opts.RunID)
+		}
+		logging = observability.LoggingMiddleware(llm.LoggingConfig{
+			Logger:     logger,
+			LogContent: true,
+			Redact:     a.redact,
+		})
+	}
+	var record observability.Middleware
+	if recorder != nil {
+		record = recorder.Client
+	}
+	// Outermost first: count → record → log → cache → rate limit → provider.
+	// The agent adds its own retries outside the chain.
+	a.llm = observability.Chain(llmClient,
+		observability.CountingMiddleware(a.usage),
+		record,
+		logging,
+		cache,
+		rateLimit,
+	)
+}
+
+// newVertexClient builds the Vertex AI client from the VERTEX_* settings
+func newVertexClient(ctx context.Context) (*vertexai.Client, error) {
+	if err := requireEnv("VERTEX_PROJECT_ID", "VERTEX_REGION"); err != nil {
+		return nil, err
+	}
+
+	safetySettings, err := vertexai.ParseSafetySettings(os.Getenv("VERTEX_SAFETY_SETTINGS"))
+	if err != nil {
+		return nil, err
+	}
+	includeThoughts := os.Getenv("VERTEX_INCLUDE_THOUGHTS") == "true"
+	var thinking *genai.ThinkingConfig
+	if budget, err := strconv.Atoi(os.Getenv("VERTEX_THINKING_BUDGET")); err == nil {
+		thinking = vertexai.ThinkingBudget(int32(budget), includeThoughts)
+	}
+	stageThinking, err := vertexai.ParseThinkingBudgets(os.Getenv("VERTEX_STAGE_THINKING_BUDGETS"), includeThoughts)
+	if err != nil {
+		return nil, err
+	}
+	contextCache, err := vertexContextCache()
+	if err != nil {
+		return nil, err
+	}
+	return vertexai.NewClientWithConfig(ctx, vertexai.Config{
+		ProjectID:       os.Getenv("VERTEX_PROJECT_ID"),
+		Region:          os.Getenv("VERTEX_REGION"),
+		FallbackRegions: envList("VERTEX_FALLBACK_REGIONS"),
+		CredentialsFile: os.Getenv("VERTEX_CREDENTIALS_FILE"),
+		CredentialsJSON: []byte(os.Getenv("VERTEX_CREDENTIALS_JSON")),
+		Model:           os.Getenv("VERTEX_MODEL"),
+		StageModels:     envMap("VERTEX_STAGE_MODELS"),
+		SafetySettings:  safetySettings,
+		Retry:           retryConfig(envInt("VERTEX_MAX_ATTEMPTS")),
+		Thinking:        thinking,
+		StageThinking:   stageThinking,
+		CandidateCount:  int32(envInt("VERTEX_CANDIDATE_COUNT")),
+		ContextCache:    contextCache,
+	})
+}
+
+// events returns a bus counting the app's failures, plus the optional
+// reporting of stage failures to Sentry configured by SENTRY_DSN
+func (a *app) events(runID string, subscribers ...observability.Subscriber) *observability.EventBus {
+	events := observability.NewEventBus(append([]observability.Subscriber{a.usage.Subscriber()}, subscribers...)...)
+	// Optional reporting of stage failures to Sentry, for teams running the agent as a service
+	if dsn := os.Getenv("SENTRY_DSN"); dsn != "" {
+		reporter, err := observability.NewSentryReporter(dsn)
+		if err != nil {
+			exitf(exitConfig, "Error initializing error reporting: %v\n", err)
+		}
+		reporter.Environment = os.Getenv("SENTRY_ENVIRONMENT")
+		reporter.Redact = a.redact
+		events.Subscribe(observability.ErrorReportSubscriber(reporter, runID, a.logger))
+	}
+	return events
+}
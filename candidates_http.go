@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+)
+
+// maxCandidateRequestBytes bounds the body of a talent pool request
+const maxCandidateRequestBytes = 64 << 10
+
+// handleCandidates serves the talent pool of store on mux:
+//
+//	GET    /candidates                    lists the candidates, filtered by the
+//	                                      status, tag and limit parameters
+//	GET    /candidates/{username}         returns a candidate's history, contacts
+//	                                      and notes
+//	PUT    /candidates/{username}/status  {"status": "replied"} moves them in the
+//	                                      pipeline
+//	POST   /candidates/{username}/contacts  {"run_id": "..."} records a contact
+//	POST   /candidates/{username}/tags    {"tags": ["..."]} tags them
+//	DELETE /candidates/{username}/tags/{tag}  removes a tag
+//	POST   /candidates/{username}/notes   {"text": "..."} attaches a note
+//	POST   /candidates/{username}/feedback  {"verdict": "hired", "run_id": "..."}
+//	                                      rates their ranking
+//	GET    /scoring                       lists the scoring learned from feedback
+//	                                      per role family
+//
+// Updates answer with the updated candidate.
+func handleCandidates(mux *http.ServeMux, store *storage.Store) {
+	mux.HandleFunc("GET /candidates", func(w http.ResponseWriter, r *http.Request) {
+		filter := storage.CandidateFilter{Status: r.FormValue("status"), Tag: r.FormValue("tag")}
+		if limit := r.FormValue("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil || n < 0 {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "limit is not a count"})
+				return
+			}
+			filter.Limit = n
+		}
+		candidates, err := store.ListCandidates(r.Context(), filter)
+		if err != nil {
+			writeCandidateError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, candidates)
+	})
+	mux.HandleFunc("GET /candidates/{username}", func(w http.ResponseWriter, r *http.Request) {
+		writeCandidate(w, r, store, nil)
+	})
+	mux.HandleFunc("PUT /candidates/{username}/status", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Status string `json:"status"`
+		}
+		if decodeCandidateRequest(w, r, &req) {
+			writeCandidate(w, r, store, store.SetStatus(r.Context(), r.PathValue("username"), req.Status, time.Now()))
+		}
+	})
+	mux.HandleFunc("POST /candidates/{username}/contacts", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			RunID string `json:"run_id"`
+		}
+		// The body is optional
+		if r.ContentLength == 0 || decodeCandidateRequest(w, r, &req) {
+			writeCandidate(w, r, store, store.MarkContacted(r.Context(), r.PathValue("username"), req.RunID, time.Now()))
+		}
+	})
+	mux.HandleFunc("POST /candidates/{username}/tags", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Tags []string `json:"tags"`
+		}
+		if decodeCandidateRequest(w, r, &req) {
+			writeCandidate(w, r, store, store.AddTags(r.Context(), r.PathValue("username"), req.Tags...))
+		}
+	})
+	mux.HandleFunc("DELETE /candidates/{username}/tags/{tag}", func(w http.ResponseWriter, r *http.Request) {
+		writeCandidate(w, r, store, store.RemoveTags(r.Context(), r.PathValue("username"), r.PathValue("tag")))
+	})
+	mux.HandleFunc("POST /candidates/{username}/notes", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Text string `json:"text"`
+		}
+		if decodeCandidateRequest(w, r, &req) {
+			writeCandidate(w, r, store, store.AddNote(r.Context(), r.PathValue("username"), req.Text, time.Now()))
+		}
+	})
+	mux.HandleFunc("POST /candidates/{username}/feedback", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Verdict string `json:"verdict"`
+			RunID   string `json:"run_id"`
+		}
+		if decodeCandidateRequest(w, r, &req) {
+			_, err := store.AddFeedback(r.Context(), r.PathValue("username"), req.RunID, req.Verdict, time.Now())
+			writeCandidate(w, r, store, err)
+		}
+	})
+	mux.HandleFunc("GET /scoring", func(w http.ResponseWriter, r *http.Request) {
+		scorings, err := store.Scorings(r.Context())
+		if err != nil {
+			writeCandidateError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, scorings)
+	})
+}
+
+// decodeCandidateRequest decodes the JSON body of r into v, answering a bad
+// request when it is not
+func decodeCandidateRequest(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCandidateRequestBytes)).Decode(v); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expected a JSON body: " + err.Error()})
+		return false
+	}
+	return true
+}
+
+// writeCandidate answers with the candidate of the request's username, or
+// with updateErr when the update before failed
+func writeCandidate(w http.ResponseWriter, r *http.Request, store *storage.Store, updateErr error) {
+	if updateErr != nil {
+		writeCandidateError(w, updateErr)
+		return
+	}
+	candidate, err := store.Candidate(r.Context(), r.PathValue("username"))
+	if err != nil {
+		writeCandidateError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, candidate)
+}
+
+// writeCandidateError answers with err, mapped to its status
+func writeCandidateError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, storage.ErrInvalid):
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/export"
+	"github.com/luillyfe/sourcing-agent/pkg/notify"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// Batch query outcomes recorded in the index
+const (
+	batchOK      = "ok"
+	batchFailed  = "failed"
+	batchSkipped = "skipped"
+)
+
+// batchExtensions maps result formats to result file extensions
+var batchExtensions = map[string]string{
+	agent.FormatJSON:     ".json",
+	export.CSV:           ".csv",
+	export.XLSX:          ".xlsx",
+	agent.FormatMarkdown: ".md",
+	agent.FormatTable:    ".txt",
+	agent.FormatHTML:     ".html",
+	agent.FormatPretty:   ".txt",
+}
+
+// batchIndex is the summary of a batch run written next to its results
+type batchIndex struct {
+	Source       string            `json:"source"`
+	GeneratedAt  time.Time         `json:"generated_at"`
+	TotalCostUSD float64           `json:"total_cost_usd"`
+	Queries      []batchIndexEntry `json:"queries"`
+}
+
+// batchIndexEntry records the outcome of one query of a batch
+type batchIndexEntry struct {
+	Name       string  `json:"name"`
+	Query      string  `json:"query"`
+	Status     string  `json:"status"`
+	RunID      string  `json:"run_id,omitempty"`
+	File       string  `json:"file,omitempty"`
+	Candidates int     `json:"candidates"`
+	Error      string  `json:"error,omitempty"`
+	ErrorClass string  `json:"error_class,omitempty"`
+	CostUSD    float64 `json:"cost_usd"`
+	DurationMS int64   `json:"duration_ms"`
+}
+
+// runBatch runs every query of a file through the pipeline, one after the
+// other with shared clients and budgets, writing a result file per query and
+// an index.json summary: sourcing-agent batch queries.txt -out results/
+func runBatch(args []string, logger *slog.Logger) {
+	fs := newFlagSet("batch")
+	outDir := fs.String("out", "results", "write result files and index.json to `dir`")
+	format := fs.String("format", agent.FormatJSON, "write results as `format`: "+strings.Join(resultFormats, ", "))
+	maxCost := fs.Float64("max-cost", 0, "skip the remaining queries once the batch has spent `usd` on LLM calls (0 for no limit)")
+	provider := llmFlag(fs)
+	source := sourceFlag(fs)
+	language := langFlag(fs)
+	exclude := excludeFlags(fs)
+	targetCount := targetCountFlag(fs)
+	maxCandidates := maxCandidatesFlag(fs)
+	relevanceThreshold := relevanceThresholdFlag(fs)
+	timeout := timeoutFlag(fs)
+	budget := budgetFlag(fs)
+	maxLLMCalls := maxLLMCallsFlag(fs)
+	dumpDir := fs.String("dump-dir", os.Getenv("FAILURE_DUMP_DIR"), "dump the pipeline state to `dir` when a stage fails")
+	progress := progressFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent batch [flags] <queries.txt|queries.yaml> [flags]")
+		fmt.Fprintln(fs.Output(), "\nA text file holds one query per line; a YAML file a list of entries with a query and")
+		fmt.Fprintln(fs.Output(), "optional name, target_count, max_candidates and relevance_threshold.")
+		fs.PrintDefaults()
+	}
+	// Flags may follow the file name too
+	fs.Parse(args)
+	path := fs.Arg(0)
+	fs.Parse(fs.Args()[min(1, fs.NArg()):])
+	if path == "" || fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	checkFormat(*format)
+	checkSource(*source)
+	defaults := agent.AgentConfig{
+		Logger:             logger,
+		Progress:           progressReporter(*progress),
+		FailureDumpDir:     *dumpDir,
+		TargetCount:        *targetCount,
+		MaxSearchResults:   *maxCandidates,
+		RelevanceThreshold: relevanceThreshold,
+		Timeout:            *timeout,
+		MaxCostUSD:         *budget,
+		MaxLLMCalls:        *maxLLMCalls,
+		Language:           *language,
+		Exclude:            exclude(),
+		Source:             *source,
+	}
+	checkSearchLimits(defaults)
+
+	queries := readBatch(path)
+	for _, q := range queries {
+		checkSearchLimits(q.Apply(defaults))
+	}
+	if err := os.MkdirAll(*outDir, 0o755); err != nil {
+		fatalf("Error creating output directory: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Runs share the clients, so rate limits apply across the batch; each
+	// passes its run ID to the audit log through its context
+	app := newApp(ctx, logger, appOptions{Source: true, SourceName: *source, LLM: true, Provider: *provider, Store: true, Notify: true, Digest: true})
+	defer app.Close()
+
+	index := batchIndex{Source: path, GeneratedAt: time.Now().UTC()}
+	failed, skipped := 0, 0
+	for i, q := range queries {
+		entry := batchIndexEntry{Name: q.Name, Query: q.Query, Status: batchSkipped}
+		spent := app.usage.Total().Usage.EstimatedCostUSD
+		switch {
+		case ctx.Err() != nil:
+			entry.Error = "batch interrupted"
+		case *maxCost > 0 && spent >= *maxCost:
+			entry.Error = fmt.Sprintf("batch cost budget of $%.2f spent", *maxCost)
+		default:
+			logger.Info("Batch query started", "query", q.Query, "position", i+1, "of", len(queries))
+			entry = runBatchQuery(ctx, app, q, defaults, *outDir, *format)
+			entry.CostUSD = app.usage.Total().Usage.EstimatedCostUSD - spent
+			if entry.Status == batchFailed {
+				failed++
+			}
+		}
+		if entry.Status == batchSkipped {
+			skipped++
+		}
+		index.Queries = append(index.Queries, entry)
+	}
+	index.TotalCostUSD = app.usage.Total().Usage.EstimatedCostUSD
+
+	data, _ := json.MarshalIndent(index, "", "  ")
+	if err := os.WriteFile(filepath.Join(*outDir, "index.json"), append(data, '\n'), 0o644); err != nil {
+		fatalf("Error writing batch index: %v\n", err)
+	}
+	if !quiet && !jsonLogs {
+		printBatchSummary(index)
+	}
+	switch {
+	case failed == len(queries):
+		exitStatus = exitFailure
+	case failed > 0 || skipped > 0:
+		exitStatus = exitPartial
+	}
+}
+
+// runBatchQuery runs one query of a batch and writes its result file
+func runBatchQuery(ctx context.Context, app *app, q agent.BatchQuery, defaults agent.AgentConfig, outDir, format string) batchIndexEntry {
+	entry := batchIndexEntry{Name: q.Name, Query: q.Query, RunID: agent.NewRunID()}
+	config := q.Apply(defaults)
+	config.RunID = entry.RunID
+	config.Events = app.events(entry.RunID)
+	config.Store = app.runStore()
+	config.Enrichers = app.enrichers
+
+	start := time.Now()
+	result, err := agent.RunStage2WithConfig(observability.WithAuditRun(ctx, entry.RunID, q.Query), app.llm, app.source, q.Query, config)
+	entry.DurationMS = time.Since(start).Milliseconds()
+	if err == nil {
+		entry.File = q.Name + batchExtensions[format]
+		err = writeResultFile(filepath.Join(outDir, entry.File), format, result)
+	}
+	if err != nil {
+		entry.Status = batchFailed
+		entry.File = ""
+		entry.Error = err.Error()
+		entry.ErrorClass = string(observability.ClassifyError(err))
+		app.logger.Error("Batch query failed", "query", q.Query, "run_id", entry.RunID, "error", err)
+		return entry
+	}
+	entry.Status = batchOK
+	entry.Candidates = len(result.TopCandidates)
+	app.notify(ctx, q.Query, result, notify.NewCandidates(result))
+	return entry
+}
+
+// readBatch parses the batch file at path, as YAML for .yaml and .yml
+func readBatch(path string) []agent.BatchQuery {
+	f, err := os.Open(path)
+	if err != nil {
+		fatalf("Error opening batch file: %v\n", err)
+	}
+	defer f.Close()
+	ext := strings.ToLower(filepath.Ext(path))
+	queries, err := agent.ParseBatch(f, ext == ".yaml" || ext == ".yml")
+	if err != nil {
+		fatalf("Error reading %s: %v\n", path, err)
+	}
+	if len(queries) == 0 {
+		fatalf("Error: %s has no queries\n", path)
+	}
+	return queries
+}
+
+// writeResultFile writes result to path in format
+func writeResultFile(path, format string, result *agent.FinalResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeResultTo(f, format, result); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// printBatchSummary prints one line per query of the batch
+func printBatchSummary(index batchIndex) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tCANDIDATES\tCOST\tFILE")
+	for _, e := range index.Queries {
+		file := e.File
+		if file == "" {
+			file = e.Error
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t$%.4f\t%s\n", e.Name, e.Status, e.Candidates, e.CostUSD, file)
+	}
+	tw.Flush()
+	fmt.Printf("Estimated LLM cost: $%.4f\n", index.TotalCostUSD)
+}
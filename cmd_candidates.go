@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+)
+
+// runCandidates queries the candidates of the runs stored in DATABASE_URL
+// and manages them as a talent pool:
+//
+//	sourcing-agent candidates list [-n 50] [-status s] [-tag t] [-json]
+//	sourcing-agent candidates show [-json] <username>
+//	sourcing-agent candidates contact [-run id] <username>...
+//	sourcing-agent candidates status <username> new|contacted|replied|rejected
+//	sourcing-agent candidates tag|untag <username> <tag>...
+//	sourcing-agent candidates note <username> "<text>"
+//	sourcing-agent candidates feedback [-run id] <username> up|down|hired|rejected
+//	sourcing-agent candidates scoring [-json] [family]
+func runCandidates(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent candidates list|show|contact|status|tag|untag|note|feedback|scoring [flags] [arguments]")
+		fmt.Fprintln(fs.Output(), "\n  list [-n 50] [-status s] [-tag t] [-json]  List the candidates of the stored runs, last seen first")
+		fmt.Fprintln(fs.Output(), "  show [-json] <username>                    Show the runs that surfaced a candidate, with their scores, contacts and notes")
+		fmt.Fprintln(fs.Output(), "  contact [-run id] <username>...            Record that candidates were contacted, flagging them in later results")
+		fmt.Fprintln(fs.Output(), "  status <username> <status>                 Set a candidate's pipeline status: "+strings.Join(storage.Statuses, ", "))
+		fmt.Fprintln(fs.Output(), "  tag|untag <username> <tag>...              Add or remove tags of a candidate")
+		fmt.Fprintln(fs.Output(), "  note <username> \"<text>\"                   Attach a note to a candidate")
+		fmt.Fprintln(fs.Output(), "  feedback [-run id] <username> <verdict>    Rate a candidate's ranking ("+strings.Join(agent.Verdicts, ", ")+"), adjusting later rankings")
+		fmt.Fprintln(fs.Output(), "  scoring [-json] [family]                   Show the scoring weights and keyword boosts learned per role family")
+		fmt.Fprintln(fs.Output(), "\nRuns of search, resume, batch, watch and serve are stored when DATABASE_URL is set.")
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	switch sub, args := fs.Arg(0), fs.Args()[1:]; sub {
+	case "list", "ls":
+		listCandidates(args, logger)
+	case "show":
+		showCandidate(args, logger)
+	case "contact":
+		contactCandidates(args, logger)
+	case "status":
+		setCandidateStatus(args, logger)
+	case "tag", "untag":
+		tagCandidate(sub, args, logger)
+	case "note":
+		noteCandidate(args, logger)
+	case "feedback":
+		rateCandidate(args, logger)
+	case "scoring":
+		showScoring(args, logger)
+	default:
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+}
+
+// listCandidates prints the candidates last seen by the stored runs
+func listCandidates(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates list")
+	limit := fs.Int("n", 50, "list the last `n` candidates seen; 0 for all")
+	status := fs.String("status", "", "list only the candidates with this pipeline `status`: "+strings.Join(storage.Statuses, ", "))
+	tag := fs.String("tag", "", "list only the candidates with this `tag`")
+	asJSON := fs.Bool("json", false, "print the candidates as JSON")
+	fs.Parse(args)
+
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	candidates, err := store.ListCandidates(context.Background(), storage.CandidateFilter{Status: *status, Tag: *tag, Limit: *limit})
+	if errors.Is(err, storage.ErrInvalid) {
+		exitf(exitUsage, "Error: %v\n", err)
+	}
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if *asJSON {
+		printJSON(candidates)
+		return
+	}
+	if len(candidates) == 0 && (*status != "" || *tag != "") {
+		fmt.Println("No matching candidates.")
+		return
+	}
+	if len(candidates) == 0 {
+		fmt.Println("No stored candidates yet.")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USERNAME\tNAME\tSTATUS\tRUNS\tBEST SCORE\tLAST SEEN\tCONTACTED\tTAGS")
+	for _, c := range candidates {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n", c.Username, c.Name, c.Status, c.Runs, formatOptionalScore(c.BestScore),
+			c.LastSeenAt.Local().Format(time.DateOnly), formatOptionalDate(c.ContactedAt), strings.Join(c.Tags, ", "))
+	}
+	tw.Flush()
+}
+
+// showCandidate prints the history of a candidate across the stored runs
+func showCandidate(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates show")
+	asJSON := fs.Bool("json", false, "print the candidate's history as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		exitf(exitUsage, "Usage: sourcing-agent candidates show [flags] <username>\n")
+	}
+
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	c, err := store.Candidate(context.Background(), fs.Arg(0))
+	if errors.Is(err, storage.ErrNotFound) {
+		exitf(exitUsage, "Error: %v; no stored run surfaced them\n", err)
+	}
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if *asJSON {
+		printJSON(c)
+		return
+	}
+	title := "@" + c.Username
+	if c.Name != "" {
+		title = c.Name + " (@" + c.Username + ")"
+	}
+	runs := "1 run"
+	if c.Runs != 1 {
+		runs = fmt.Sprintf("%d runs", c.Runs)
+	}
+	fmt.Printf("%s, %s, seen in %s, best score %s\n", title, c.Status, runs, formatOptionalScore(c.BestScore))
+	if len(c.Tags) > 0 {
+		fmt.Println("Tags: " + strings.Join(c.Tags, ", "))
+	}
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN ID\tSTARTED\tRANK\tSCORE\tQUERY")
+	for _, s := range c.Sightings {
+		rank := "-"
+		if s.Rank > 0 {
+			rank = fmt.Sprint(s.Rank)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.RunID, s.Time.Local().Format(time.DateTime), rank, formatOptionalScore(s.FinalMatchScore), s.Query)
+	}
+	tw.Flush()
+	if len(c.Contacts) > 0 {
+		fmt.Println()
+	}
+	for _, contact := range c.Contacts {
+		line := "Contacted " + contact.Time.Local().Format(time.DateTime)
+		if contact.RunID != "" {
+			line += " from run " + contact.RunID
+		}
+		fmt.Println(line)
+	}
+	for _, note := range c.Notes {
+		fmt.Printf("\nNote of %s:\n%s\n", note.Time.Local().Format(time.DateTime), note.Text)
+	}
+	if len(c.Feedback) > 0 {
+		fmt.Println()
+	}
+	for _, f := range c.Feedback {
+		fmt.Printf("Rated %s %s on run %s (%s role)\n", f.Verdict, f.Time.Local().Format(time.DateTime), f.RunID, f.RoleFamily)
+	}
+}
+
+// contactCandidates records that candidates were contacted
+func contactCandidates(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates contact")
+	runID := fs.String("run", "", "the `run-id` of the results the candidates were contacted from")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		exitf(exitUsage, "Usage: sourcing-agent candidates contact [flags] <username>...\n")
+	}
+
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	now := time.Now()
+	for _, username := range fs.Args() {
+		err := store.MarkContacted(context.Background(), username, *runID, now)
+		if errors.Is(err, storage.ErrNotFound) {
+			exitf(exitUsage, "Error: %v; no stored run surfaced them\n", err)
+		}
+		if err != nil {
+			fatalf("Error: %v\n", err)
+		}
+		fmt.Printf("Recorded the contact of %s.\n", username)
+	}
+}
+
+// setCandidateStatus moves a candidate in the pipeline
+func setCandidateStatus(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates status")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		exitf(exitUsage, "Usage: sourcing-agent candidates status <username> %s\n", strings.Join(storage.Statuses, "|"))
+	}
+	updateCandidate(logger, func(ctx context.Context, store *storage.Store) error {
+		return store.SetStatus(ctx, fs.Arg(0), fs.Arg(1), time.Now())
+	})
+	fmt.Printf("Moved %s to %s.\n", fs.Arg(0), fs.Arg(1))
+}
+
+// tagCandidate adds tags to a candidate, or removes them for untag
+func tagCandidate(sub string, args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates " + sub)
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		exitf(exitUsage, "Usage: sourcing-agent candidates %s <username> <tag>...\n", sub)
+	}
+	username, tags := fs.Arg(0), fs.Args()[1:]
+	updateCandidate(logger, func(ctx context.Context, store *storage.Store) error {
+		if sub == "untag" {
+			return store.RemoveTags(ctx, username, tags...)
+		}
+		return store.AddTags(ctx, username, tags...)
+	})
+}
+
+// noteCandidate attaches a note to a candidate
+func noteCandidate(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates note")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		exitf(exitUsage, "Usage: sourcing-agent candidates note <username> \"<text>\"\n")
+	}
+	updateCandidate(logger, func(ctx context.Context, store *storage.Store) error {
+		return store.AddNote(ctx, fs.Arg(0), fs.Arg(1), time.Now())
+	})
+}
+
+// rateCandidate records a verdict on a ranked candidate
+func rateCandidate(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates feedback")
+	runID := fs.String("run", "", "the `run-id` whose ranking is rated; the latest run that surfaced the candidate if empty")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		exitf(exitUsage, "Usage: sourcing-agent candidates feedback [flags] <username> %s\n", strings.Join(agent.Verdicts, "|"))
+	}
+	var feedback *storage.Feedback
+	updateCandidate(logger, func(ctx context.Context, store *storage.Store) (err error) {
+		feedback, err = store.AddFeedback(ctx, fs.Arg(0), *runID, fs.Arg(1), time.Now())
+		return err
+	})
+	fmt.Printf("Rated %s %s on run %s, learned for %s roles.\n", fs.Arg(0), feedback.Verdict, feedback.RunID, feedback.RoleFamily)
+}
+
+// showScoring prints the scoring learned from feedback for each role family
+// rated, or only for family
+func showScoring(args []string, logger *slog.Logger) {
+	fs := newFlagSet("candidates scoring")
+	asJSON := fs.Bool("json", false, "print the learned scoring as JSON")
+	fs.Parse(args)
+	if fs.NArg() > 1 {
+		exitf(exitUsage, "Usage: sourcing-agent candidates scoring [flags] [family]\n")
+	}
+
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	scorings, err := store.Scorings(context.Background())
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if family := fs.Arg(0); family != "" {
+		scorings = slices.DeleteFunc(scorings, func(s agent.Scoring) bool { return s.RoleFamily != family })
+		if len(scorings) == 0 {
+			scorings = []agent.Scoring{{RoleFamily: family, Weights: agent.DefaultScoringWeights}}
+		}
+	}
+	if *asJSON {
+		printJSON(scorings)
+		return
+	}
+	if len(scorings) == 0 {
+		fmt.Println("No feedback yet; candidates are scored with the default weights.")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ROLE FAMILY	FEEDBACK	SKILLS	REPOS	EXPERIENCE	QUALITY	KEYWORD BOOSTS")
+	for _, s := range scorings {
+		boosts := "-"
+		if s.Feedback < agent.MinFeedback {
+			boosts = fmt.Sprintf("(defaults until %d feedback)", agent.MinFeedback)
+		} else if len(s.KeywordBoosts) > 0 {
+			var parts []string
+			for _, k := range slices.Sorted(maps.Keys(s.KeywordBoosts)) {
+				parts = append(parts, fmt.Sprintf("%s %+.1f", k, s.KeywordBoosts[k]))
+			}
+			boosts = strings.Join(parts, ", ")
+		}
+		w := s.Weights
+		fmt.Fprintf(tw, "%s\t%d\t%.1f%%\t%.1f%%\t%.1f%%\t%.1f%%\t%s\n", s.RoleFamily, s.Feedback,
+			w.RequiredSkills*100, w.RepositoryRelevance*100, w.Experience*100, w.ProfileQuality*100, boosts)
+	}
+	tw.Flush()
+}
+
+// updateCandidate applies update to the store, exiting on failure
+func updateCandidate(logger *slog.Logger, update func(ctx context.Context, store *storage.Store) error) {
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	err := update(context.Background(), store)
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		exitf(exitUsage, "Error: %v; no stored run surfaced them\n", err)
+	case errors.Is(err, storage.ErrInvalid):
+		exitf(exitUsage, "Error: %v\n", err)
+	case err != nil:
+		fatalf("Error: %v\n", err)
+	}
+}
+
+// formatOptionalScore renders a score that may be missing as "-"
+func formatOptionalScore(score *float64) string {
+	if score == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", *score)
+}
+
+// formatOptionalDate renders a time that may be missing as "-"
+func formatOptionalDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format(time.DateOnly)
+}
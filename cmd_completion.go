@@ -0,0 +1,187 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// completionShells are the shells completion scripts are generated for
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// completionSpec is a subcommand with the flags to complete for it
+type completionSpec struct {
+	name    string
+	summary string
+	flags   []completionFlag
+}
+
+// completionFlag is a flag of a subcommand. values lists the choices of a
+// flag taking one of a fixed set; a flag taking any other value completes
+// file names.
+type completionFlag struct {
+	name       string
+	usage      string
+	takesValue bool
+	values     []string
+}
+
+// runCompletion prints a completion script for a shell:
+// source <(sourcing-agent completion bash)
+func runCompletion(args []string) {
+	fs := newFlagSet("completion")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent completion bash|zsh|fish")
+		fmt.Fprintln(fs.Output(), "\nbash: source <(sourcing-agent completion bash), e.g. from ~/.bashrc")
+		fmt.Fprintln(fs.Output(), "zsh:  source <(sourcing-agent completion zsh), e.g. from ~/.zshrc")
+		fmt.Fprintln(fs.Output(), "fish: sourcing-agent completion fish > ~/.config/fish/completions/sourcing-agent.fish")
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 || !slices.Contains(completionShells, fs.Arg(0)) {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	specs := completionSpecs()
+	switch fs.Arg(0) {
+	case "bash":
+		writeBashCompletion(os.Stdout, specs)
+	case "zsh":
+		// zsh runs the bash completion through its compatibility layer
+		fmt.Println("#compdef sourcing-agent")
+		fmt.Println("autoload -U +X bashcompinit && bashcompinit")
+		writeBashCompletion(os.Stdout, specs)
+	case "fish":
+		writeFishCompletion(os.Stdout, specs)
+	}
+}
+
+// completionSpecs collects the flags of every subcommand from its own flag
+// set, so completions never fall behind the commands
+func completionSpecs() []completionSpec {
+	values := map[string][]string{
+		"llm":    llmProviders().Names(),
+		"format": resultFormats,
+		"source": sourceNames,
+		"lang":   slices.Sorted(maps.Keys(agent.Languages)),
+	}
+	var specs []completionSpec
+	for _, c := range commands {
+		spec := completionSpec{name: c.name, summary: c.summary}
+		if fs := commandFlags(c); fs != nil {
+			fs.VisitAll(func(f *flag.Flag) {
+				_, usage := flag.UnquoteUsage(f)
+				boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+				spec.flags = append(spec.flags, completionFlag{
+					name:       f.Name,
+					usage:      usage,
+					takesValue: !ok || !boolFlag.IsBoolFlag(),
+					values:     values[f.Name],
+				})
+			})
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// commandFlags returns the flag set c defines, by running it with -h while
+// newFlagSet collects the flag set and panics instead of printing help
+func commandFlags(c command) (fs *flag.FlagSet) {
+	collectFlags = &fs
+	defer func() {
+		collectFlags = nil
+		if r := recover(); r != nil && r != flag.ErrHelp {
+			panic(r)
+		}
+	}()
+	c.run([]string{"-h"}, slog.New(slog.DiscardHandler))
+	return fs
+}
+
+// collectFlags receives the next flag set newFlagSet creates, while set
+var collectFlags **flag.FlagSet
+
+// writeBashCompletion writes a bash completion function for specs
+func writeBashCompletion(w io.Writer, specs []completionSpec) {
+	names := []string{"completion", "help"}
+	choices := map[string][]string{}
+	for _, spec := range specs {
+		names = append(names, spec.name)
+		for _, f := range spec.flags {
+			if len(f.values) > 0 {
+				choices[f.name] = f.values
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "# bash completion for sourcing-agent")
+	fmt.Fprintln(w, "_sourcing_agent() {")
+	fmt.Fprintln(w, "\tlocal cur=${COMP_WORDS[COMP_CWORD]} prev=${COMP_WORDS[COMP_CWORD-1]} flags=")
+	fmt.Fprintln(w, "\tif [[ $COMP_CWORD -eq 1 ]]; then")
+	fmt.Fprintf(w, "\t\tCOMPREPLY=($(compgen -W %q -- \"$cur\"))\n", strings.Join(names, " "))
+	fmt.Fprintln(w, "\t\treturn")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "\tcase $prev in")
+	for _, name := range slices.Sorted(maps.Keys(choices)) {
+		fmt.Fprintf(w, "\t-%s) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", name, strings.Join(choices[name], " "))
+	}
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "\tcase ${COMP_WORDS[1]} in")
+	for _, spec := range specs {
+		var flags []string
+		for _, f := range spec.flags {
+			flags = append(flags, "-"+f.name)
+		}
+		fmt.Fprintf(w, "\t%s) flags=%q ;;\n", spec.name, strings.Join(flags, " "))
+	}
+	fmt.Fprintf(w, "\tcompletion) COMPREPLY=($(compgen -W %q -- \"$cur\")); return ;;\n", strings.Join(completionShells, " "))
+	fmt.Fprintln(w, "\tesac")
+	fmt.Fprintln(w, "\tif [[ $cur == -* ]]; then")
+	fmt.Fprintln(w, "\t\tCOMPREPLY=($(compgen -W \"$flags\" -- \"$cur\"))")
+	fmt.Fprintln(w, "\telse")
+	fmt.Fprintln(w, "\t\tCOMPREPLY=($(compgen -f -- \"$cur\"))")
+	fmt.Fprintln(w, "\tfi")
+	fmt.Fprintln(w, "}")
+	fmt.Fprintln(w, "complete -o filenames -F _sourcing_agent sourcing-agent")
+}
+
+// writeFishCompletion writes fish completions for specs
+func writeFishCompletion(w io.Writer, specs []completionSpec) {
+	const cmd = "complete -c sourcing-agent"
+	fmt.Fprintln(w, "# fish completion for sourcing-agent")
+	fmt.Fprintln(w, cmd+" -f")
+	for _, spec := range specs {
+		fmt.Fprintf(w, "%s -n __fish_use_subcommand -a %s -d %s\n", cmd, spec.name, fishQuote(spec.summary))
+	}
+	fmt.Fprintf(w, "%s -n __fish_use_subcommand -a completion -d %s\n", cmd, fishQuote("Print a shell completion script"))
+	fmt.Fprintf(w, "%s -n '__fish_seen_subcommand_from completion' -a %s\n", cmd, fishQuote(strings.Join(completionShells, " ")))
+	for _, spec := range specs {
+		for _, f := range spec.flags {
+			line := fmt.Sprintf("%s -n '__fish_seen_subcommand_from %s' -o %s -d %s", cmd, spec.name, f.name, fishQuote(f.usage))
+			switch {
+			case len(f.values) > 0:
+				line += " -x -a " + fishQuote(strings.Join(f.values, " "))
+			case f.takesValue:
+				line += " -r -F"
+			}
+			fmt.Fprintln(w, line)
+		}
+		if spec.name == "batch" {
+			// The queries file
+			fmt.Fprintf(w, "%s -n '__fish_seen_subcommand_from batch' -F\n", cmd)
+		}
+	}
+}
+
+// fishQuote single-quotes s for fish
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/crawler"
+)
+
+// defaultCrawlRequestsPerHour leaves a fifth of GitHub's 5,000 requests an
+// hour to interactive searches
+const defaultCrawlRequestsPerHour = 4000
+
+// runCrawl caches the developer profiles of target languages and regions in
+// DATABASE_URL ahead of searches, on a schedule:
+//
+//	sourcing-agent crawl -languages go,rust -locations Lima,Peru [-interval 24h]
+func runCrawl(args []string, logger *slog.Logger) {
+	fs := newFlagSet("crawl")
+	languages := fs.String("languages", os.Getenv("CRAWL_LANGUAGES"), "comma-separated `languages` to crawl the developers of, e.g. go,rust")
+	locations := fs.String("locations", os.Getenv("CRAWL_LOCATIONS"), "comma-separated `locations` to crawl each language in, e.g. Lima,Peru; anywhere if none")
+	perTarget := fs.Int("per-target", crawler.DefaultProfilesPerTarget, "crawl up to `n` developers per language and location")
+	minRepos := fs.Int("min-repos", 5, "crawl only developers with more than `n` public repositories")
+	requestsPerHour := fs.Int("requests-per-hour", defaultCrawlRequestsPerHour, "send at most `n` requests an hour to the source platforms, leaving the rest of their rate limit to searches")
+	interval := fs.Duration("interval", 24*time.Hour, "crawl again every `duration`")
+	once := fs.Bool("once", false, "crawl once and exit, e.g. from cron")
+	source := sourceFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent crawl [flags]")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nProfiles are cached in DATABASE_URL, where search, resume, batch, watch and serve use them first for PROFILE_CACHE_TTL.")
+	}
+	fs.Parse(args)
+	if fs.NArg() > 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	targets := crawler.Targets(splitList(*languages), splitList(*locations))
+	if len(targets) == 0 {
+		exitf(exitUsage, "Error: -languages or CRAWL_LANGUAGES must name the languages to crawl\n")
+	}
+	if *requestsPerHour <= 0 || *perTarget <= 0 {
+		exitf(exitUsage, "Error: -requests-per-hour and -per-target must be positive\n")
+	}
+	if *interval <= 0 {
+		exitf(exitUsage, "Error: -interval must be positive\n")
+	}
+	checkSource(*source)
+	if os.Getenv("DATABASE_URL") == "" {
+		exitf(exitConfig, "Error: DATABASE_URL is not set\nSet it to a SQLite file, e.g. sourcing.db, or a postgres:// URL to cache profiles in\n")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app := newApp(ctx, logger, appOptions{Source: true, SourceName: *source, Store: true, Uncached: true,
+		SourceThrottle: crawler.Throttle(*requestsPerHour)})
+	defer app.Close()
+
+	maxAge, err := profileCacheTTL()
+	if err != nil {
+		exitf(exitConfig, "Error parsing %v\n", err)
+	}
+	c := &crawler.Crawler{
+		Source:            app.source,
+		Cache:             app.store,
+		Targets:           targets,
+		ProfilesPerTarget: *perTarget,
+		MinRepos:          *minRepos,
+		MaxAge:            maxAge,
+		Logger:            logger,
+	}
+	for {
+		start := time.Now()
+		stats, err := c.Crawl(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil && *once:
+			fatalRunError(err)
+		case err != nil:
+			logger.Error("Crawl failed, retrying at the next interval", "error", err)
+		}
+		if err == nil && !quiet && !jsonLogs {
+			fmt.Fprintf(os.Stderr, "=== Crawled %d targets in %s: %d profiles, %d with repositories fetched, %d still fresh, %d failed ===\n",
+				stats.Targets, time.Since(start).Round(time.Second), stats.Profiles, stats.Repositories, stats.Fresh, stats.Failed)
+		}
+		logger.Info("Crawl finished", "duration", time.Since(start), "targets", stats.Targets, "profiles", stats.Profiles,
+			"repositories", stats.Repositories, "fresh", stats.Fresh, "failed", stats.Failed, "rate_limited", stats.RateLimited)
+		if *once {
+			return
+		}
+		logger.Info("Next crawl", "at", time.Now().Add(*interval).Format(time.RFC3339))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(*interval):
+		}
+	}
+}
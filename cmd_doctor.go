@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"github.com/luillyfe/sourcing-agent/pkg/vertexai"
+)
+
+// errSkipped marks a doctor check that does not apply to this configuration
+var errSkipped = errors.New("skipped")
+
+// errWarning marks a doctor check that passed with a finding worth acting on
+var errWarning = errors.New("warning")
+
+// fixError is a doctor finding with the change that addresses it
+type fixError struct {
+	err error
+	fix string
+}
+
+func (e *fixError) Error() string { return e.err.Error() }
+
+func (e *fixError) Unwrap() error { return e.err }
+
+// withFix attaches the change that addresses err
+func withFix(err error, fix string) error {
+	return &fixError{err: err, fix: fix}
+}
+
+// doctorMinGitHubRequests is the core rate limit budget below which a search
+// is likely to run out midway: each candidate costs a profile and a
+// repositories request
+const doctorMinGitHubRequests = 100
+
+// doctorCheck is one configuration check. It returns a one-line finding, or
+// an error (errSkipped when it does not apply, errWarning when it passed but
+// needs attention), with a fix attached by withFix.
+type doctorCheck struct {
+	name string
+	run  func(ctx context.Context) (string, error)
+}
+
+// runDoctor checks configuration, credentials and connectivity, exiting
+// non-zero if any check fails
+func runDoctor(args []string, logger *slog.Logger) {
+	fs := newFlagSet("doctor")
+	ping := fs.Bool("ping", false, "also send a one-line prompt to the model (billed)")
+	provider := llmFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent doctor [-llm provider] [-ping]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	checks := []doctorCheck{
+		{"Settings", checkSettings},
+		{"GitHub", checkGitHub},
+		{"LLM provider", func(ctx context.Context) (string, error) { return checkLLM(ctx, *provider, *ping) }},
+		{"Anthropic failover", func(ctx context.Context) (string, error) { return checkAnthropic(*provider) }},
+	}
+	for _, key := range []string{"LLM_CACHE_DIR", "FAILURE_DUMP_DIR", "RUN_RECORD_DIR", "CHECKPOINT_DIR"} {
+		checks = append(checks, doctorCheck{key, func(context.Context) (string, error) { return checkWritableDir(os.Getenv(key)) }})
+	}
+
+	failed := 0
+	for _, check := range checks {
+		finding, err := check.run(ctx)
+		switch {
+		case errors.Is(err, errSkipped):
+			fmt.Printf("[skip] %s: %s\n", check.name, finding)
+		case errors.Is(err, errWarning):
+			fmt.Printf("[warn] %s: %s\n", check.name, finding)
+		case err != nil:
+			failed++
+			fmt.Printf("[FAIL] %s: %v\n", check.name, err)
+		default:
+			fmt.Printf("[ok]   %s: %s\n", check.name, finding)
+		}
+		var fixErr *fixError
+		if errors.As(err, &fixErr) {
+			fmt.Printf("       fix: %s\n", fixErr.fix)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d check(s) failed\n", failed)
+		os.Exit(exitConfig)
+	}
+}
+
+// checkSettings parses the settings that are otherwise only read mid-run
+func checkSettings(context.Context) (string, error) {
+	if err := parseSources(defaultSource()); err != nil {
+		return "", fmt.Errorf("SOURCE_PROVIDER: %w", err)
+	}
+	if _, err := newEnrichers(envList("ENRICHERS"), nil, nil); err != nil {
+		return "", fmt.Errorf("ENRICHERS: %w", err)
+	}
+	if _, err := llm.ParseRedaction(os.Getenv("PII_REDACTION")); err != nil {
+		return "", fmt.Errorf("PII_REDACTION: %w", err)
+	}
+	if _, err := vertexai.ParseSafetySettings(os.Getenv("VERTEX_SAFETY_SETTINGS")); err != nil {
+		return "", fmt.Errorf("VERTEX_SAFETY_SETTINGS: %w", err)
+	}
+	if _, err := vertexai.ParseThinkingBudgets(os.Getenv("VERTEX_STAGE_THINKING_BUDGETS"), false); err != nil {
+		return "", fmt.Errorf("VERTEX_STAGE_THINKING_BUDGETS: %w", err)
+	}
+	for _, key := range []string{"LLM_CALL_TIMEOUT", "LLM_CACHE_TTL", "VERTEX_CONTEXT_CACHE_TTL", "PROFILE_CACHE_TTL"} {
+		if _, err := envDuration(key); err != nil {
+			return "", err
+		}
+	}
+	return "valid", nil
+}
+
+// checkGitHub verifies the token, its scopes and its remaining budgets
+// against the rate limit endpoint, which does not use up any of them
+func checkGitHub(context.Context) (string, error) {
+	if source := defaultSource(); !slices.Contains(splitList(source), sourceGitHub) {
+		return "not used with SOURCE_PROVIDER=" + source, errSkipped
+	}
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return "", withFix(errors.New("GITHUB_TOKEN is not set"),
+			"create a token at https://github.com/settings/tokens (it needs no scopes) and set GITHUB_TOKEN")
+	}
+	limits, err := github.NewClient(token).GetRateLimits()
+	var apiErr *github.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized:
+		return "", withFix(fmt.Errorf("token rejected: %w", err), "the token is invalid, expired or revoked; create a new one and update GITHUB_TOKEN")
+	case err != nil:
+		return "", withFix(fmt.Errorf("API unreachable: %w", err), "check the connection to api.github.com, including HTTPS_PROXY if you use one")
+	}
+
+	token = "fine-grained token"
+	if limits.ClassicToken {
+		token = "classic token without scopes"
+		if len(limits.Scopes) > 0 {
+			token = "classic token with scopes " + strings.Join(limits.Scopes, ", ")
+		}
+	}
+	finding := fmt.Sprintf("%s accepted, %d of %d requests and %d of %d searches remaining", token,
+		limits.Core.Remaining, limits.Core.Limit, limits.Search.Remaining, limits.Search.Limit)
+
+	reset := func(limit github.RateLimit) string { return time.Unix(limit.Reset, 0).Format(time.Kitchen) }
+	switch {
+	case limits.Core.Remaining == 0:
+		return "", withFix(fmt.Errorf("rate limit exhausted: %s", finding), "wait until "+reset(limits.Core)+" or use another token")
+	case limits.Search.Remaining == 0:
+		return "", withFix(fmt.Errorf("search rate limit exhausted: %s", finding), "wait until "+reset(limits.Search))
+	case limits.Core.Remaining < doctorMinGitHubRequests:
+		return finding, withFix(errWarning, "a search may run out of requests midway; wait until "+reset(limits.Core)+" or use another token")
+	}
+	var broad []string
+	for _, scope := range limits.Scopes {
+		if !strings.HasPrefix(scope, "read:") {
+			broad = append(broad, scope)
+		}
+	}
+	if len(broad) > 0 {
+		return finding, withFix(errWarning, "the agent only reads public profiles; a token without the "+strings.Join(broad, ", ")+" scopes limits the damage if it leaks")
+	}
+	return finding, nil
+}
+
+// checkLLM builds the selected provider's client, which validates its
+// settings and resolves credentials, and with ping sends a minimal prompt to
+// each model it is configured with, which confirms the model is served
+func checkLLM(ctx context.Context, provider string, ping bool) (string, error) {
+	client, err := llmProviders().New(ctx, provider)
+	if err != nil {
+		var authErr *vertexai.AuthError
+		if errors.As(err, &authErr) {
+			return "", withFix(err, "run 'gcloud auth application-default login' or set VERTEX_CREDENTIALS_FILE")
+		}
+		return "", withFix(err, "set the provider's settings in .env, see .env.example")
+	}
+	if closer, ok := client.(interface{ Close() error }); ok {
+		defer closer.Close()
+	}
+
+	model := providerModel(client)
+	finding := fmt.Sprintf("%s configured, model %s", provider, model)
+	if provider == providerVertexAI {
+		finding = fmt.Sprintf("credentials found for project %s in %s, model %s",
+			os.Getenv("VERTEX_PROJECT_ID"), os.Getenv("VERTEX_REGION"), model)
+	}
+	if !ping {
+		return finding + " (run with -ping to call the model)", nil
+	}
+
+	// One call per distinct model, labelled with a stage that selects it
+	models := map[string]string{model: ""}
+	for stage, stageModel := range providerStageModels(client) {
+		if _, ok := models[stageModel]; !ok && stageModel != "" {
+			models[stageModel] = stage
+		}
+	}
+	start := time.Now()
+	for _, m := range slices.Sorted(maps.Keys(models)) {
+		_, err := client.CallAPI(ctx, []llm.Message{llm.UserText("Reply with OK.")}, nil, llm.WithStage(models[m]), llm.WithMaxOutputTokens(8))
+		if err != nil {
+			return "", withFix(fmt.Errorf("model %s call failed: %w", m, err), llmFix(provider, m, err))
+		}
+	}
+	if len(models) > 1 {
+		finding = fmt.Sprintf("%s, %d models", finding, len(models))
+	}
+	return fmt.Sprintf("%s, responded in %s", finding, time.Since(start).Round(time.Millisecond)), nil
+}
+
+// llmFix suggests the change that addresses a failed call to model
+func llmFix(provider, model string, err error) string {
+	var apiErr *llm.APIError
+	status := 0
+	if errors.As(err, &apiErr) {
+		status = apiErr.StatusCode
+	}
+	prefix := strings.ToUpper(provider)
+	switch {
+	case status == http.StatusNotFound && provider == providerVertexAI:
+		return fmt.Sprintf("%s is not served in %s for this project; set VERTEX_REGION to a region offering it, e.g. global or us-central1, or choose another model with VERTEX_MODEL",
+			model, os.Getenv("VERTEX_REGION"))
+	case status == http.StatusNotFound && provider == providerOllama:
+		return fmt.Sprintf("pull the model with 'ollama pull %s'", model)
+	case status == http.StatusNotFound:
+		return fmt.Sprintf("check the model name in %s_MODEL and %s_STAGE_MODELS", prefix, prefix)
+	case (status == http.StatusUnauthorized || status == http.StatusForbidden) && provider == providerVertexAI:
+		return "grant the credentials the Vertex AI User role and enable the API with 'gcloud services enable aiplatform.googleapis.com'"
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return fmt.Sprintf("check %s_API_KEY and that the key may use %s", prefix, model)
+	case observability.ClassifyError(err) == observability.ErrorClassLLMQuota:
+		if provider == providerVertexAI {
+			return "quota exhausted; wait, request a quota increase, or set VERTEX_FALLBACK_REGIONS"
+		}
+		return "quota exhausted; wait or raise the account's rate limits"
+	case provider == providerOllama:
+		return "start Ollama with 'ollama serve', or set OLLAMA_BASE_URL to where it runs"
+	}
+	return "retry later; if it persists, check the provider's status page"
+}
+
+// checkAnthropic reports whether Claude is configured as a failover provider,
+// which only backs Vertex AI
+func checkAnthropic(provider string) (string, error) {
+	if provider != providerVertexAI {
+		return "only used with -llm " + providerVertexAI, errSkipped
+	}
+	config, ok := anthropicPlatform()
+	if !ok {
+		return "not configured", errSkipped
+	}
+	platform := config.Platform
+	if platform == "" {
+		platform = "Anthropic API"
+	}
+	return "configured on " + platform, nil
+}
+
+// checkWritableDir verifies a configured output directory can be written
+func checkWritableDir(dir string) (string, error) {
+	if dir == "" {
+		return "not set", errSkipped
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return "", fmt.Errorf("%s is not writable: %w", dir, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return dir + " is writable", nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/ats"
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+)
+
+// runExport exports the top candidates of a result to an ATS:
+//
+//	sourcing-agent search -format json "..." | sourcing-agent export -to lever
+//	sourcing-agent export -to lever -run <run-id>
+func runExport(args []string, logger *slog.Logger) {
+	fs := newFlagSet("export")
+	to := atsFlag(fs)
+	in := fs.String("in", "-", "read the result JSON from `file` (- for stdin)")
+	runID := fs.String("run", "", "export the result of the stored `run-id` instead, from DATABASE_URL")
+	limit := fs.Int("n", 0, "export only the top `n` candidates; 0 for all")
+	asJSON := fs.Bool("json", false, "print the exported candidates as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent export -to <ATS> [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if *to == "" {
+		exitf(exitUsage, "Error: -to or ATS_EXPORTER must name the ATS to export to\n")
+	}
+	exporter, err := atsExporters().New(*to)
+	if err != nil {
+		exitf(exitConfig, "Error: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var result *agent.FinalResult
+	if *runID != "" {
+		result = storedResult(ctx, *runID, logger)
+	} else {
+		result = readResult(*in)
+	}
+
+	exported, err := ats.Export(ctx, exporter, result, *limit)
+	if err != nil {
+		if len(exported) == 0 {
+			fatalf("Error exporting to %s: %v\n", *to, err)
+		}
+		fmt.Fprintf(os.Stderr, "Some candidates were not exported to %s: %v\n", *to, err)
+		exitStatus = exitPartial
+	}
+	if *asJSON {
+		printJSON(exported)
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "USERNAME\tID\tURL")
+	for _, e := range exported {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Username, e.ID, e.URL)
+	}
+	tw.Flush()
+}
+
+// storedResult returns the result of a stored run, exiting when it has none
+func storedResult(ctx context.Context, runID string, logger *slog.Logger) *agent.FinalResult {
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	run, err := store.GetRun(ctx, runID)
+	if errors.Is(err, storage.ErrNotFound) {
+		exitf(exitUsage, "Error: %v; see sourcing-agent runs list\n", err)
+	}
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if run.Result == nil {
+		fatalf("Error: run %s did not finish; its last completed stage is %s\n", run.ID, run.Stage)
+	}
+	return run.Result
+}
+
+// readResult decodes the result JSON at path, or stdin for "-"
+func readResult(path string) *agent.FinalResult {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fatalf("Error reading result: %v\n", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	var result agent.FinalResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		fatalf("Error reading result: %v\n", err)
+	}
+	return &result
+}
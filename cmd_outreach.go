@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/outreach"
+)
+
+// runOutreach drafts an outreach message per top candidate of a result from
+// a template, optionally polished by the LLM, and writes them to a file:
+//
+//	sourcing-agent search -format json "..." | sourcing-agent outreach -template intro.tmpl
+//	sourcing-agent outreach -run <run-id> -polish -out outreach.csv
+func runOutreach(args []string, logger *slog.Logger) {
+	fs := newFlagSet("outreach")
+	templatePath := fs.String("template", os.Getenv("OUTREACH_TEMPLATE"), "fill the outreach template in `file` (default: a built-in introduction)")
+	in := fs.String("in", "-", "read the result JSON from `file` (- for stdin)")
+	runID := fs.String("run", "", "draft for the result of the stored `run-id` instead, from DATABASE_URL")
+	limit := fs.Int("n", 0, "draft for the top `n` candidates only; 0 for all")
+	role := fs.String("role", "", "the `role` hired for, as {{.Role}}")
+	sender := fs.String("from", os.Getenv("OUTREACH_SENDER"), "the `name` signing the messages, as {{.Sender}}")
+	recontact := fs.Bool("recontact", false, "also draft for candidates already contacted in an earlier run")
+	polish := fs.Bool("polish", false, "have the LLM polish each message, keeping its facts")
+	provider := llmFlag(fs)
+	outPath := fs.String("out", "", "write the messages to `file`: markdown, or CSV or JSON by extension; - for stdout (default outreach-<run-id>.md)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent outreach [flags]")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nPlaceholders: {{.Name}}, {{.FirstName}}, {{.Username}}, {{.GitHubURL}}, {{.Location}}, {{.Score}},")
+		fmt.Fprintln(fs.Output(), "{{.TopRepo}}, {{.TopRepoURL}}, {{.TopRepoWhy}}, {{.MatchedSkills}}, {{.Skills}}, {{.Role}} and {{.Sender}}.")
+		fmt.Fprintln(fs.Output(), "A first line \"Subject: ...\" followed by a blank line sets the subject.")
+	}
+	fs.Parse(args)
+
+	text := outreach.DefaultTemplate
+	if *templatePath != "" {
+		data, err := os.ReadFile(*templatePath)
+		if err != nil {
+			exitf(exitUsage, "Error reading outreach template: %v\n", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := outreach.ParseTemplate(text)
+	if err != nil {
+		exitf(exitUsage, "Error: %v\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	var result *agent.FinalResult
+	if *runID != "" {
+		result = storedResult(ctx, *runID, logger)
+	} else {
+		result = readResult(*in)
+	}
+
+	var candidates []agent.RankedCandidate
+	contacted := 0
+	for _, c := range result.TopCandidates {
+		if !*recontact && c.SeenBefore != nil && c.SeenBefore.ContactedAt != nil {
+			contacted++
+			continue
+		}
+		candidates = append(candidates, c)
+	}
+	if *limit > 0 && len(candidates) > *limit {
+		candidates = candidates[:*limit]
+	}
+	messages, err := outreach.Merge(tmpl, candidates, outreach.Fields{Role: *role, Sender: *sender})
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+
+	if *polish && len(messages) > 0 {
+		app := newApp(ctx, logger, appOptions{RunID: result.RunID, LLM: true, Provider: *provider})
+		defer app.Close()
+		for i, m := range messages {
+			polished, err := outreach.Polish(ctx, app.llm, m)
+			if err != nil {
+				// The unpolished message is still ready to send
+				logger.Warn("Outreach not polished", "username", m.Username, "error", err)
+				exitStatus = exitPartial
+				continue
+			}
+			messages[i] = polished
+		}
+	}
+
+	path := *outPath
+	if path == "" {
+		path = "outreach.md"
+		if result.RunID != "" {
+			path = "outreach-" + result.RunID + ".md"
+		}
+	}
+	if path == "-" {
+		if err := outreach.Write(os.Stdout, outreach.FormatMarkdown, result.RunID, messages); err != nil {
+			fatalf("Error writing outreach: %v\n", err)
+		}
+		return
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fatalf("Error writing outreach: %v\n", err)
+	}
+	if err := outreach.Write(f, outreach.FileFormat(path), result.RunID, messages); err != nil {
+		f.Close()
+		fatalf("Error writing outreach: %v\n", err)
+	}
+	if err := f.Close(); err != nil {
+		fatalf("Error writing outreach: %v\n", err)
+	}
+	noun := "messages"
+	if len(messages) == 1 {
+		noun = "message"
+	}
+	fmt.Printf("Wrote %d outreach %s to %s.\n", len(messages), noun, path)
+	if contacted > 0 {
+		fmt.Printf("Skipped %d already contacted; -recontact includes them.\n", contacted)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// runProfile looks at a single GitHub user. With a query it assesses the
+// user against it, e.g. an inbound applicant:
+//
+//	sourcing-agent profile <username> "<query>"
+//
+// Without one it shows how the user is enriched against a set of skills,
+// without calling the LLM: sourcing-agent profile -skills go,grpc <username>
+func runProfile(args []string, logger *slog.Logger) {
+	fs := newFlagSet("profile")
+	skills := fs.String("skills", "", "without a query, comma-separated required `skills` to score repositories against, e.g. go,grpc")
+	keywords := fs.String("keywords", "", "without a query, comma-separated repository `keywords`, e.g. microservices,backend")
+	provider := llmFlag(fs)
+	source := sourceFlag(fs)
+	format := formatFlag(fs, "the assessment")
+	actions := resultActionFlags(fs)
+	language := langFlag(fs)
+	relevanceThreshold := relevanceThresholdFlag(fs)
+	timeout := timeoutFlag(fs)
+	budget := budgetFlag(fs)
+	maxLLMCalls := maxLLMCallsFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent profile [flags] <username> [\"<query>\"|-]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	checkSource(*source)
+	username, query := fs.Arg(0), queryArgs(fs.Args()[1:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if strings.TrimSpace(query) == "" {
+		app := newApp(ctx, logger, appOptions{Source: true, SourceName: *source})
+		defer app.Close()
+
+		candidate, err := agent.EnrichProfile(ctx, app.source, username, splitList(*skills), splitList(*keywords))
+		if err != nil {
+			fatalRunError(err)
+		}
+		printJSON(candidate)
+		return
+	}
+
+	checkFormat(*format)
+	actions.check()
+	config := agent.AgentConfig{RelevanceThreshold: relevanceThreshold, Timeout: *timeout, MaxCostUSD: *budget, MaxLLMCalls: *maxLLMCalls, Language: *language, Source: *source}
+	checkSearchLimits(config)
+
+	runID := agent.NewRunID()
+	app := newApp(ctx, logger, appOptions{RunID: runID, Query: query, Source: true, SourceName: *source, LLM: true, Provider: *provider})
+	defer app.Close()
+
+	config.RunID = runID
+	config.Logger = logger
+	config.Events = app.events(runID)
+	config.Enrichers = app.enrichers
+	result, err := agent.AssessProfile(ctx, app.llm, app.source, username, query, config)
+	if err != nil {
+		fatalRunError(err)
+	}
+	writeResult(*format, result)
+	actions.run(result, *source, logger)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/notify"
+)
+
+// runResume continues a search that failed or was interrupted from the
+// checkpoint of its last completed stage: sourcing-agent resume <run-id>
+func runResume(args []string, logger *slog.Logger) {
+	fs := newFlagSet("resume")
+	checkpointDir := checkpointDirFlag(fs)
+	format := formatFlag(fs, "the result")
+	outPath := fs.String("out", "", "also write the result as JSON to `file`")
+	actions := resultActionFlags(fs)
+	provider := llmFlag(fs)
+	timeout := timeoutFlag(fs)
+	budget := budgetFlag(fs)
+	maxLLMCalls := maxLLMCallsFlag(fs)
+	dumpDir := fs.String("dump-dir", os.Getenv("FAILURE_DUMP_DIR"), "dump the pipeline state to `dir` when a stage fails")
+	progress := progressFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent resume [flags] <run-id>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	checkFormat(*format)
+	actions.check()
+	config := agent.AgentConfig{Timeout: *timeout, MaxCostUSD: *budget, MaxLLMCalls: *maxLLMCalls}
+	checkSearchLimits(config)
+	if *checkpointDir == "" {
+		exitf(exitUsage, "Error: set -checkpoint-dir or CHECKPOINT_DIR to the directory the run saved its checkpoints to\n")
+	}
+	checkpoint, err := agent.LoadCheckpoint(*checkpointDir, fs.Arg(0))
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if !quiet && !jsonLogs {
+		fmt.Fprintf(os.Stderr, "Resuming run %s after the %s stage\nQuery: %s\n\n", checkpoint.RunID, checkpoint.Stage, checkpoint.Query)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app := newApp(ctx, logger, appOptions{RunID: checkpoint.RunID, Query: checkpoint.Query, Source: true, SourceName: checkpoint.Source, LLM: true, Provider: *provider, Store: true, Notify: true})
+	defer app.Close()
+
+	config.Logger = logger
+	config.Events = app.events(checkpoint.RunID)
+	config.Store = app.runStore()
+	config.Enrichers = app.enrichers
+	config.Progress = progressReporter(*progress)
+	config.FailureDumpDir = *dumpDir
+	config.CheckpointDir = *checkpointDir
+	result, err := agent.ResumeStage2(ctx, app.llm, app.source, checkpoint, config)
+	if err != nil {
+		fatalRunError(err)
+	}
+	writeResult(*format, result)
+	reportPartial(result)
+	actions.run(result, checkpoint.Source, logger)
+	app.notify(ctx, checkpoint.Query, result, notify.NewCandidates(result))
+	if *outPath != "" {
+		resultJSON, _ := json.MarshalIndent(result, "", "  ")
+		if err := os.WriteFile(*outPath, append(resultJSON, '\n'), 0o644); err != nil {
+			logger.Warn("Result not written", "path", *outPath, "error", err)
+		}
+	}
+}
+
+// checkpointDirFlag adds the -checkpoint-dir flag, defaulting to CHECKPOINT_DIR
+func checkpointDirFlag(fs *flag.FlagSet) *string {
+	return fs.String("checkpoint-dir", os.Getenv("CHECKPOINT_DIR"), "save the pipeline state to `dir` after every stage, for resume")
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+)
+
+// runRuns queries the runs stored in DATABASE_URL:
+//
+//	sourcing-agent runs list [-n 20] [-json]
+//	sourcing-agent runs show [-format f] [-json] <run-id>
+func runRuns(args []string, logger *slog.Logger) {
+	fs := newFlagSet("runs")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent runs list|show [flags] [arguments]")
+		fmt.Fprintln(fs.Output(), "\n  list [-n 20] [-json]              List the last stored runs, newest first")
+		fmt.Fprintln(fs.Output(), "  show [-format f] [-json] <run-id>  Print a stored run's result, or with -json everything stored of it")
+		fmt.Fprintln(fs.Output(), "\nRuns of search, resume, batch, watch and serve are stored when DATABASE_URL is set.")
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	switch sub, args := fs.Arg(0), fs.Args()[1:]; sub {
+	case "list", "ls":
+		listRuns(args, logger)
+	case "show":
+		showRun(args, logger)
+	default:
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+}
+
+// listRuns prints the last stored runs
+func listRuns(args []string, logger *slog.Logger) {
+	fs := newFlagSet("runs list")
+	limit := fs.Int("n", 20, "list the last `n` runs; 0 for all")
+	asJSON := fs.Bool("json", false, "print the runs as JSON")
+	fs.Parse(args)
+
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	runs, err := store.ListRuns(context.Background(), *limit)
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if *asJSON {
+		printJSON(runs)
+		return
+	}
+	if len(runs) == 0 {
+		fmt.Println("No stored runs yet.")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RUN ID\tSTARTED\tSTAGE\tCANDIDATES\tRANKED\tQUERY")
+	for _, run := range runs {
+		stage := run.Stage
+		switch {
+		case run.Partial:
+			stage = "unranked"
+		case run.FinishedAt != nil:
+			stage = "finished"
+		}
+		query := strings.Join(strings.Fields(run.Query), " ")
+		if len(query) > 50 {
+			query = query[:47] + "..."
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%s\n", run.ID, run.StartedAt.Local().Format(time.DateTime), stage, run.CandidateCount, run.RankedCount, query)
+	}
+	tw.Flush()
+}
+
+// showRun prints the result of a stored run
+func showRun(args []string, logger *slog.Logger) {
+	fs := newFlagSet("runs show")
+	format := formatFlag(fs, "the result")
+	asJSON := fs.Bool("json", false, "print everything stored of the run as JSON: requirements, strategy, candidates and result")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		exitf(exitUsage, "Usage: sourcing-agent runs show [flags] <run-id>\n")
+	}
+	checkFormat(*format)
+
+	store, closeStore := openStore(logger)
+	defer closeStore()
+	run, err := store.GetRun(context.Background(), fs.Arg(0))
+	if errors.Is(err, storage.ErrNotFound) {
+		exitf(exitUsage, "Error: %v; see sourcing-agent runs list\n", err)
+	}
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	if *asJSON {
+		printJSON(run)
+		return
+	}
+	if run.Result == nil {
+		fatalf("Error: run %s did not finish; its last completed stage is %s\n", run.ID, run.Stage)
+	}
+	writeResult(*format, run.Result)
+}
+
+// openStore opens the store of DATABASE_URL, exiting when it is not set
+func openStore(logger *slog.Logger) (*storage.Store, func()) {
+	if os.Getenv("DATABASE_URL") == "" {
+		exitf(exitConfig, "Error: DATABASE_URL is not set\nSet it to a SQLite file, e.g. sourcing.db, or a postgres:// URL to store runs\n")
+	}
+	app := newApp(context.Background(), logger, appOptions{Store: true})
+	return app.store, app.Close
+}
@@ -0,0 +1,422 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/notify"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// runSearch runs the whole pipeline for a query: sourcing-agent search "<query>"
+func runSearch(args []string, logger *slog.Logger) {
+	fs := newFlagSet("search")
+	reportPath := fs.String("report", os.Getenv("REPORT_FILE"), "write a run report to `file` (HTML for .html, markdown otherwise)")
+	recordDir := fs.String("record", os.Getenv("RUN_RECORD_DIR"), "record every LLM call and GitHub request to `dir`")
+	replayDir := fs.String("replay", os.Getenv("RUN_REPLAY_DIR"), "rerun the run recorded in `dir` instead of a new query; needs no credentials")
+	format := formatFlag(fs, "the result")
+	outPath := fs.String("out", "", "also write the result as JSON to `file`, e.g. to feed enrich or rank")
+	actions := resultActionFlags(fs)
+	provider := llmFlag(fs)
+	source := sourceFlag(fs)
+	language := langFlag(fs)
+	exclude := excludeFlags(fs)
+	targetCount := targetCountFlag(fs)
+	maxCandidates := maxCandidatesFlag(fs)
+	relevanceThreshold := relevanceThresholdFlag(fs)
+	timeout := timeoutFlag(fs)
+	budget := budgetFlag(fs)
+	maxLLMCalls := maxLLMCallsFlag(fs)
+	dumpDir := fs.String("dump-dir", os.Getenv("FAILURE_DUMP_DIR"), "dump the pipeline state to `dir` when a stage fails")
+	checkpointDir := checkpointDirFlag(fs)
+	progress := progressFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent search [flags] \"<query>\"|-")
+		fmt.Fprintln(fs.Output(), "\nWith -, the query is read from stdin, e.g. a whole job description.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	checkFormat(*format)
+	checkSource(*source)
+	actions.check()
+	config := agent.AgentConfig{
+		FailureDumpDir:     *dumpDir,
+		CheckpointDir:      *checkpointDir,
+		TargetCount:        *targetCount,
+		MaxSearchResults:   *maxCandidates,
+		RelevanceThreshold: relevanceThreshold,
+		Timeout:            *timeout,
+		MaxCostUSD:         *budget,
+		MaxLLMCalls:        *maxLLMCalls,
+		Language:           *language,
+		Exclude:            exclude(),
+		Source:             *source,
+	}
+	checkSearchLimits(config)
+
+	// Replaying a recorded run needs no credentials
+	if *replayDir != "" {
+		replayRun(*replayDir, logger)
+		return
+	}
+
+	query := queryArgs(fs.Args())
+	if strings.TrimSpace(query) == "" {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	// One ID correlates this run's logs, recording and result
+	runID := agent.NewRunID()
+
+	if jsonLogs {
+		logger.Info("Run started", "run_id", runID, "query", query)
+	} else if !quiet {
+		fmt.Println("=== GitHub Developer Sourcing Agent ===")
+		fmt.Printf("Query: %s\n", query)
+		fmt.Printf("Run ID: %s\n\n", runID)
+		fmt.Println("Searching...")
+		fmt.Println()
+	}
+
+	// Cancel in-flight LLM calls on Ctrl-C instead of waiting for them to finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app := newApp(ctx, logger, appOptions{RunID: runID, Query: query, Source: true, SourceName: *source, LLM: true, Provider: *provider, RecordDir: *recordDir, Store: true, Notify: true})
+	defer app.Close()
+	usage := app.usage
+
+	// Count lifecycle events for the run summary
+	eventCounter := &observability.EventCounter{}
+	searchLog := &observability.SearchLog{}
+	events := app.events(runID, eventCounter.Subscriber(), searchLog.Subscriber())
+
+	// Optional export of the run's telemetry to a monitoring stack
+	metrics, flushMetrics := metricsBackend(logger)
+	events.Subscribe(observability.MetricsSubscriber(metrics))
+	exportMetrics := func() {
+		observability.RecordReportMetrics(metrics, usage.Report())
+		flushMetrics()
+	}
+
+	// Run the sourcing agent
+	startTime := time.Now()
+	config.RunID = runID
+	config.Logger = logger
+	config.Events = events
+	config.Store = app.runStore()
+	config.Enrichers = app.enrichers
+	config.Progress = progressReporter(*progress)
+	result, err := agent.RunStage2WithConfig(ctx, app.llm, app.source, query, config)
+	if err != nil {
+		exportMetrics()
+		if *checkpointDir != "" && !errors.Is(err, agent.ErrUnclearRequest) {
+			fmt.Fprintf(os.Stderr, "Continue with: sourcing-agent resume -checkpoint-dir %s %s\n", *checkpointDir, runID)
+		}
+		fatalRunError(err)
+	}
+	duration := time.Since(startTime)
+	exportMetrics()
+
+	// Display result
+	writeResult(*format, result)
+	reportPartial(result)
+	actions.run(result, *source, logger)
+	app.notify(ctx, query, result, notify.NewCandidates(result))
+	if *outPath != "" {
+		resultJSON, _ := json.MarshalIndent(result, "", "  ")
+		if err := os.WriteFile(*outPath, append(resultJSON, '\n'), 0o644); err != nil {
+			logger.Warn("Result not written", "path", *outPath, "error", err)
+		}
+	}
+
+	report := usage.Report()
+	if path := *reportPath; path != "" {
+		writeRunReport(path, agent.RunReport{Query: query, Result: result, Searches: searchLog.Searches(), Usage: &report}, logger)
+	}
+	if path := os.Getenv("TOKEN_HISTOGRAM_FILE"); path != "" {
+		recordTokenHistograms(path, report, logger)
+	}
+	eventCounts := eventCounter.Counts()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	if jsonLogs {
+		// One structured record carries everything the text summary prints
+		attrs := []any{"run_id", runID, "duration_ms", duration.Milliseconds(), "report", report, "events", eventCounts}
+		if app.cache != nil {
+			attrs = append(attrs, "llm_cache_hits", app.cache.Hits, "llm_cache_misses", app.cache.Misses)
+		}
+		if app.vertex != nil && len(app.vertex.FallbackRegions) > 0 {
+			attrs = append(attrs, "vertex_regions", app.vertex.RegionUsage())
+		}
+		if app.failover != nil {
+			attrs = append(attrs, "providers", app.failover.Health())
+		}
+		attrs = append(attrs, "memory_alloc_mib", bToMb(m.Alloc), "memory_sys_mib", bToMb(m.Sys), "num_gc", m.NumGC)
+		logger.Info("Run summary", attrs...)
+		return
+	}
+	if quiet {
+		return
+	}
+
+	fmt.Printf("\nTotal execution time: %.2f seconds\n", duration.Seconds())
+	for _, s := range report.Stages {
+		fmt.Printf("LLM %s: %d calls, %d input + %d output tokens, $%.4f\n",
+			s.Stage, s.Calls, s.Usage.InputTokens, s.Usage.OutputTokens, s.Usage.EstimatedCostUSD)
+	}
+	for _, m := range report.Models {
+		fmt.Printf("LLM model %s: %d calls, $%.4f\n", m.Model, m.Calls, m.Usage.EstimatedCostUSD)
+	}
+	total := report.Total
+	fmt.Printf("Total LLM calls: %d\n", total.Calls)
+	if total.Usage.ThinkingTokens > 0 || total.Usage.CachedInputTokens > 0 {
+		fmt.Printf("LLM tokens: %d thinking, %d cached input\n", total.Usage.ThinkingTokens, total.Usage.CachedInputTokens)
+	}
+	fmt.Printf("Estimated LLM cost: $%.4f\n", total.Usage.EstimatedCostUSD)
+	if app.cache != nil {
+		fmt.Printf("LLM cache: %d hits, %d misses\n", app.cache.Hits, app.cache.Misses)
+	}
+	fmt.Printf("Total GitHub API calls: %d\n", report.HTTPRequests())
+	for _, h := range report.HTTP {
+		if h.RateLimitRemaining != nil {
+			fmt.Printf("%s rate limit remaining: %d\n", h.Host, *h.RateLimitRemaining)
+		}
+	}
+	for _, f := range report.Failures {
+		fmt.Printf("Failures %s/%s: %d\n", f.Source, f.Class, f.Count)
+	}
+	if eventCounts["fallback_used"] > 0 || eventCounts["budget_warning"] > 0 {
+		fmt.Printf("Pipeline fallbacks: %d, budget warnings: %d\n", eventCounts["fallback_used"], eventCounts["budget_warning"])
+	}
+	if app.vertex != nil && len(app.vertex.FallbackRegions) > 0 {
+		for _, r := range app.vertex.RegionUsage() {
+			fmt.Printf("Vertex AI region %s: %d calls, %d capacity errors\n", r.Region, r.Calls, r.CapacityErrors)
+		}
+	}
+	if app.failover != nil {
+		for _, h := range app.failover.Health() {
+			fmt.Printf("Provider %s: %d calls, %d failures\n", h.Name, h.Calls, h.Failures)
+		}
+	}
+
+	// Memory usage
+	fmt.Printf("Memory usage: Alloc = %v MiB, TotalAlloc = %v MiB, Sys = %v MiB, NumGC = %v\n",
+		bToMb(m.Alloc), bToMb(m.TotalAlloc), bToMb(m.Sys), m.NumGC)
+}
+
+// metricsBackend returns the Metrics configured by PROMETHEUS_TEXTFILE or
+// STATSD_ADDR, no-op metrics if neither is set, and a function flushing the
+// samples once the run is over
+func metricsBackend(logger *slog.Logger) (observability.Metrics, func()) {
+	if path := os.Getenv("PROMETHEUS_TEXTFILE"); path != "" {
+		metrics := observability.NewPrometheusMetrics()
+		return metrics, func() {
+			if err := metrics.WriteFile(path); err != nil {
+				logger.Warn("Metrics not written", "error", err)
+			}
+		}
+	}
+	if addr := os.Getenv("STATSD_ADDR"); addr != "" {
+		metrics, err := observability.NewStatsDMetrics(addr, os.Getenv("STATSD_PREFIX"))
+		if err != nil {
+			exitf(exitConfig, "Error initializing StatsD metrics: %v\n", err)
+		}
+		return metrics, func() { metrics.Close() }
+	}
+	return observability.NoopMetrics{}, func() {}
+}
+
+// writeRunReport writes report to path, as HTML for .html files and
+// markdown otherwise. The run already succeeded, so a failure only warns.
+func writeRunReport(path string, report agent.RunReport, logger *slog.Logger) {
+	f, err := os.Create(path)
+	if err != nil {
+		logger.Warn("Run report not written", "error", err)
+		return
+	}
+	defer f.Close()
+	if err := agent.WriteReport(f, agent.ReportFormat(path), report); err != nil {
+		logger.Warn("Run report not written", "error", err)
+		return
+	}
+	logger.Info("Run report written", "path", path)
+}
+
+// recordTokenHistograms adds the run's token usage to the per-stage history
+// at path, first warning about prompts well above their stage's usual size
+func recordTokenHistograms(path string, report observability.Report, logger *slog.Logger) {
+	histograms, err := observability.LoadTokenHistograms(path)
+	if err != nil {
+		logger.Warn("Token histograms not updated", "error", err)
+		return
+	}
+	for _, call := range report.Calls {
+		if call.Error != "" {
+			continue
+		}
+		if p95, above := histograms.InputAbove(call.Stage, call.Usage.InputTokens, 0.95); above {
+			logger.Warn("Prompt larger than usual for its stage", "stage", call.Stage,
+				"input_tokens", call.Usage.InputTokens, "p95_input_tokens", p95)
+		}
+	}
+	histograms.ObserveReport(report)
+	if err := histograms.Save(path); err != nil {
+		logger.Warn("Token histograms not saved", "error", err)
+	}
+}
+
+// replayRun reruns the pipeline against the run recorded in dir, serving
+// every LLM and source response from the recording
+func replayRun(dir string, logger *slog.Logger) {
+	run, err := observability.LoadRun(dir)
+	if err != nil {
+		fatalf("Error loading recorded run: %v\n", err)
+	}
+	if jsonLogs {
+		logger.Info("Replay started", "dir", dir, "recorded_run_id", run.Meta.RunID, "query", run.Meta.Query)
+	} else if !quiet {
+		fmt.Println("=== GitHub Developer Sourcing Agent (replay) ===")
+		fmt.Printf("Query: %s\n\n", run.Meta.Query)
+	}
+
+	httpClient := &http.Client{Transport: run.HTTP}
+	source, err := newSources(run.Meta.Source, httpClient, logger)
+	if err != nil {
+		fatalf("Error replaying recorded run: %v\n", err)
+	}
+	enrichers, err := newEnrichers(run.Meta.Enrichers, httpClient, logger)
+	if err != nil {
+		fatalf("Error replaying recorded run: %v\n", err)
+	}
+
+	result, err := agent.RunStage2WithConfig(context.Background(), run.LLM, source, run.Meta.Query, agent.AgentConfig{Logger: logger, Enrichers: enrichers})
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	resultJSON, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(resultJSON))
+}
+
+// queryArgs joins the query arguments. A lone "-" reads the query from
+// stdin instead, so a job description can be piped in without quoting it.
+func queryArgs(args []string) string {
+	if len(args) != 1 || args[0] != "-" {
+		return strings.Join(args, " ")
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fatalf("Error reading the query from stdin: %v\n", err)
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// langFlag adds the -lang flag choosing the language of the ranking's prose,
+// defaulting to RESULT_LANGUAGE
+func langFlag(fs *flag.FlagSet) *string {
+	return fs.String("lang", os.Getenv("RESULT_LANGUAGE"), "write the candidates' reasoning and the summary in language `code`, e.g. es or pt-BR (default English)")
+}
+
+// excludeFlags adds the repeatable -exclude flag and -exclude-file, which
+// defaults to EXCLUDE_FILE. The returned function lists the exclusions of
+// both once the flags are parsed.
+func excludeFlags(fs *flag.FlagSet) func() []string {
+	var exclude []string
+	fs.Func("exclude", "never present `developer`, a username or org:name matched against the profile's company; repeatable or comma-separated", func(s string) error {
+		exclude = append(exclude, splitList(s)...)
+		return nil
+	})
+	file := fs.String("exclude-file", os.Getenv("EXCLUDE_FILE"), "never present the developers listed in `file`, one per line, e.g. employees or people already contacted")
+	return func() []string {
+		if *file == "" {
+			return exclude
+		}
+		listed, err := readExcludeFile(*file)
+		if err != nil {
+			exitf(exitUsage, "Error reading -exclude-file: %v\n", err)
+		}
+		return append(exclude, listed...)
+	}
+}
+
+// readExcludeFile reads one exclusion per line, ignoring blank lines and
+// anything after a #
+func readExcludeFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var exclude []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			exclude = append(exclude, line)
+		}
+	}
+	return exclude, nil
+}
+
+// targetCountFlag adds the -target-count flag sizing the ranked result
+func targetCountFlag(fs *flag.FlagSet) *int {
+	return fs.Int("target-count", 0, "present at most `n` ranked candidates (default: the model decides)")
+}
+
+// maxCandidatesFlag adds the -max-candidates flag capping each GitHub search
+func maxCandidatesFlag(fs *flag.FlagSet) *int {
+	return fs.Int("max-candidates", agent.DefaultMaxSearchResults, "enrich at most `n` developers per GitHub search (1-100)")
+}
+
+// relevanceThresholdFlag adds the -relevance-threshold flag for repository relevance
+func relevanceThresholdFlag(fs *flag.FlagSet) *float64 {
+	return fs.Float64("relevance-threshold", agent.DefaultRelevanceThreshold, "count repositories scoring above `score` (0-1) as relevant")
+}
+
+// timeoutFlag adds the -timeout flag bounding a whole run
+func timeoutFlag(fs *flag.FlagSet) *time.Duration {
+	return fs.Duration("timeout", 0, "abandon the run after `duration`, e.g. 10m; a cut-short ranking falls back to unranked candidates (0 for no limit)")
+}
+
+// budgetFlag adds the -budget flag capping a run's LLM spend
+func budgetFlag(fs *flag.FlagSet) *float64 {
+	return fs.Float64("budget", 0, "stop calling the LLM once the run has spent `usd` (0 for no limit)")
+}
+
+// maxLLMCallsFlag adds the -max-llm-calls flag capping a run's LLM calls
+func maxLLMCallsFlag(fs *flag.FlagSet) *int {
+	return fs.Int("max-llm-calls", 0, "stop calling the LLM after `n` calls (0 for no limit)")
+}
+
+// checkSearchLimits exits when a search limit flag is out of range, -lang
+// is unknown or an exclusion is invalid; zero keeps the default, but for
+// -relevance-threshold, where 0 counts every repository scoring at all.
+// GitHub returns at most 100 developers per search.
+func checkSearchLimits(config agent.AgentConfig) {
+	if err := config.Validate(); err != nil {
+		exitf(exitUsage, "Error: %v\n", err)
+	}
+	switch {
+	case config.TargetCount < 0:
+		exitf(exitUsage, "Error: -target-count must not be negative\n")
+	case config.MaxSearchResults < 0 || config.MaxSearchResults > 100:
+		exitf(exitUsage, "Error: -max-candidates must be between 1 and 100\n")
+	case config.RelevanceThreshold != nil && (*config.RelevanceThreshold < 0 || *config.RelevanceThreshold >= 1):
+		exitf(exitUsage, "Error: -relevance-threshold must be at least 0 and below 1\n")
+	case config.Timeout < 0 || config.MaxCostUSD < 0 || config.MaxLLMCalls < 0:
+		exitf(exitUsage, "Error: -timeout, -budget and -max-llm-calls must not be negative\n")
+	}
+}
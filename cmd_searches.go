@@ -0,0 +1,233 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// runSearches manages named queries saved with their settings, so recurring
+// roles rerun with one short command:
+//
+//	sourcing-agent searches save [flags] <name> "<query>"
+//	sourcing-agent searches list
+//	sourcing-agent searches run <name> [search flags]
+//	sourcing-agent searches delete <name>
+func runSearches(args []string, logger *slog.Logger) {
+	fs := newFlagSet("searches")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent searches save|list|run|delete [flags] [arguments]")
+		fmt.Fprintln(fs.Output(), "\n  save [flags] <name> \"<query>\"|-   Save a query with the -llm, -source, -lang, -exclude and search limit flags given")
+		fmt.Fprintln(fs.Output(), "  list [-json]                      List the saved searches")
+		fmt.Fprintln(fs.Output(), "  run <name> [search flags]         Run a saved search; flags add to or override its settings")
+		fmt.Fprintln(fs.Output(), "  delete <name>                     Delete a saved search")
+		fmt.Fprintf(fs.Output(), "\nSearches are saved to %s (SAVED_SEARCHES_FILE).\n", savedSearchesPath())
+	}
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	switch sub, args := fs.Arg(0), fs.Args()[1:]; sub {
+	case "save":
+		saveSearch(args)
+	case "list", "ls":
+		listSearches(args)
+	case "run":
+		runSavedSearch(args, logger)
+	case "delete", "rm":
+		deleteSearch(args)
+	default:
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+}
+
+// saveSearch saves a query under a name with the settings given as flags.
+// Only flags given are saved; the others keep following the defaults.
+func saveSearch(args []string) {
+	fs := newFlagSet("searches save")
+	provider := llmFlag(fs)
+	source := sourceFlag(fs)
+	language := langFlag(fs)
+	exclude := excludeFlags(fs)
+	targetCount := targetCountFlag(fs)
+	maxCandidates := maxCandidatesFlag(fs)
+	relevanceThreshold := relevanceThresholdFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent searches save [flags] <name> \"<query>\"|-")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	name, err := agent.SavedSearchName(fs.Arg(0))
+	if err != nil {
+		exitf(exitUsage, "Error: %v\n", err)
+	}
+	query := queryArgs(fs.Args()[1:])
+	if query == "" {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+
+	search := agent.SavedSearch{BatchQuery: agent.BatchQuery{Name: name, Query: query}, SavedAt: time.Now().UTC()}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "llm":
+			search.Provider = *provider
+		case "source":
+			search.Source = *source
+		case "lang":
+			search.Language = *language
+		case "target-count":
+			search.TargetCount = *targetCount
+		case "max-candidates":
+			search.MaxCandidates = *maxCandidates
+		case "relevance-threshold":
+			search.RelevanceThreshold = relevanceThreshold
+		}
+	})
+	search.Exclude = exclude()
+	if search.Provider != "" && !slices.Contains(llmProviders().Names(), search.Provider) {
+		exitf(exitUsage, "Error: unknown LLM provider %q: want one of %s\n", search.Provider, strings.Join(llmProviders().Names(), ", "))
+	}
+	checkSource(search.Source)
+	checkSearchLimits(agent.AgentConfig{
+		TargetCount:        search.TargetCount,
+		MaxSearchResults:   search.MaxCandidates,
+		RelevanceThreshold: search.RelevanceThreshold,
+		Language:           search.Language,
+		Exclude:            search.Exclude,
+	})
+
+	path := savedSearchesPath()
+	searches := loadSavedSearches(path)
+	replaced := searches.Put(search)
+	if err := searches.Save(path); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	verb := "Saved"
+	if replaced {
+		verb = "Replaced"
+	}
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "%s search %s; run it with: sourcing-agent searches run %s\n", verb, name, name)
+	}
+}
+
+// listSearches prints the saved searches with their settings
+func listSearches(args []string) {
+	fs := newFlagSet("searches list")
+	asJSON := fs.Bool("json", false, "print the saved searches as JSON")
+	fs.Parse(args)
+
+	searches := loadSavedSearches(savedSearchesPath())
+	if *asJSON {
+		printJSON(searches)
+		return
+	}
+	if len(searches) == 0 {
+		fmt.Println("No saved searches. Save one with: sourcing-agent searches save <name> \"<query>\"")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tQUERY\tSETTINGS")
+	for _, s := range searches {
+		query := strings.Join(strings.Fields(s.Query), " ")
+		if len(query) > 60 {
+			query = query[:57] + "..."
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", s.Name, query, strings.Join(savedSearchArgs(s), " "))
+	}
+	tw.Flush()
+}
+
+// runSavedSearch runs a saved search through the search command, with any
+// flags given after the name adding to or overriding its settings
+func runSavedSearch(args []string, logger *slog.Logger) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		exitf(exitUsage, "Usage: sourcing-agent searches run <name> [search flags]\n")
+	}
+	search, ok := loadSavedSearches(savedSearchesPath()).Get(args[0])
+	if !ok {
+		exitf(exitUsage, "Error: no saved search %q; see sourcing-agent searches list\n", args[0])
+	}
+	searchArgs := append(savedSearchArgs(search), args[1:]...)
+	runSearch(append(searchArgs, "--", search.Query), logger)
+}
+
+// deleteSearch deletes a saved search
+func deleteSearch(args []string) {
+	if len(args) != 1 {
+		exitf(exitUsage, "Usage: sourcing-agent searches delete <name>\n")
+	}
+	path := savedSearchesPath()
+	searches := loadSavedSearches(path)
+	if !searches.Delete(args[0]) {
+		exitf(exitUsage, "Error: no saved search %q\n", args[0])
+	}
+	if err := searches.Save(path); err != nil {
+		fatalf("Error: %v\n", err)
+	}
+}
+
+// savedSearchArgs returns the search flags of a saved search's settings
+func savedSearchArgs(s agent.SavedSearch) []string {
+	var args []string
+	if s.Provider != "" {
+		args = append(args, "-llm", s.Provider)
+	}
+	if s.Source != "" {
+		args = append(args, "-source", s.Source)
+	}
+	if s.Language != "" {
+		args = append(args, "-lang", s.Language)
+	}
+	for _, entry := range s.Exclude {
+		args = append(args, "-exclude", entry)
+	}
+	if s.TargetCount > 0 {
+		args = append(args, "-target-count", strconv.Itoa(s.TargetCount))
+	}
+	if s.MaxCandidates > 0 {
+		args = append(args, "-max-candidates", strconv.Itoa(s.MaxCandidates))
+	}
+	if s.RelevanceThreshold != nil {
+		args = append(args, "-relevance-threshold", strconv.FormatFloat(*s.RelevanceThreshold, 'g', -1, 64))
+	}
+	return args
+}
+
+// savedSearchesPath returns the saved searches file: SAVED_SEARCHES_FILE, or
+// searches.yaml in the user's configuration directory
+func savedSearchesPath() string {
+	if path := os.Getenv("SAVED_SEARCHES_FILE"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "searches.yaml"
+	}
+	return filepath.Join(dir, "sourcing-agent", "searches.yaml")
+}
+
+// loadSavedSearches reads the saved searches at path, exiting on a broken file
+func loadSavedSearches(path string) agent.SavedSearches {
+	searches, err := agent.LoadSavedSearches(path)
+	if err != nil {
+		exitf(exitConfig, "Error: %v\n", err)
+	}
+	return searches
+}
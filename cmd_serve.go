@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	sourcingv1 "github.com/luillyfe/sourcing-agent/api/sourcing/v1"
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/jobs"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"google.golang.org/grpc"
+)
+
+// maxSearchRequestBytes bounds the body of a search request
+const maxSearchRequestBytes = 1 << 20
+
+// retainedJobs is the number of finished jobs kept for GET /jobs
+const retainedJobs = 1000
+
+// runServe serves searches over HTTP until interrupted:
+//
+//	POST /search {"query": "..."}  runs the pipeline and returns the result;
+//	                               an optional "lang" overrides -lang and
+//	                               "exclude" adds to -exclude
+//	POST /jobs {"query": "..."}    queues the search as a job, answering 202
+//	                               with it at once
+//	GET  /jobs                     lists the jobs without their results
+//	GET  /jobs/{id}                reports a job's state, stage progress and,
+//	                               once completed, its result
+//	POST /jobs/{id}/cancel         cancels a queued or running job
+//	GET  /jobs/{id}/download       downloads a job's result as CSV or XLSX
+//	                               (see handleDownloads)
+//	GET  /healthz                  reports the server is up
+//	GET  /metrics                  exposes metrics in the Prometheus text format
+//
+// With DATABASE_URL set, the candidates of the stored runs are served as a
+// talent pool under /candidates (see handleCandidates), and their results as
+// downloads under /runs.
+//
+// With -grpc-addr, the same searches are served over gRPC, as defined in
+// api/sourcing/v1/sourcing.proto.
+//
+// With SERVE_TOKEN set, every request but GET /healthz, over HTTP or gRPC,
+// needs it as its bearer token.
+//
+// Searches of both endpoints run as jobs on -workers workers sharing the
+// GitHub and LLM clients, so LLM_REQUESTS_PER_MINUTE, LLM_TOKENS_PER_MINUTE
+// and the GitHub token's rate limit are budgets across all of them.
+func runServe(args []string, logger *slog.Logger) {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", ":8080", "listen on `address`")
+	timeout := fs.Duration("timeout", 5*time.Minute, "abandon a search after `duration`")
+	grpcAddr := fs.String("grpc-addr", "", "also serve the gRPC API on `address`, e.g. :9090")
+	workers := fs.Int("workers", 2, "run at most `n` searches at once")
+	queueSize := fs.Int("queue-size", 100, "queue at most `n` searches waiting for a worker")
+	provider := llmFlag(fs)
+	source := sourceFlag(fs)
+	language := langFlag(fs)
+	exclude := excludeFlags(fs)
+	targetCount := targetCountFlag(fs)
+	maxCandidates := maxCandidatesFlag(fs)
+	relevanceThreshold := relevanceThresholdFlag(fs)
+	budget := budgetFlag(fs)
+	maxLLMCalls := maxLLMCallsFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent serve [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	checkSource(*source)
+	limits := agent.AgentConfig{
+		TargetCount:        *targetCount,
+		MaxSearchResults:   *maxCandidates,
+		RelevanceThreshold: relevanceThreshold,
+		MaxCostUSD:         *budget,
+		MaxLLMCalls:        *maxLLMCalls,
+		Language:           *language,
+		Exclude:            exclude(),
+		Source:             *source,
+	}
+	checkSearchLimits(limits)
+	if *workers < 1 || *queueSize < 1 {
+		exitf(exitUsage, "Error: -workers and -queue-size must be at least 1\n")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Runs share the clients; each passes its run ID to the audit log through
+	// its context
+	app := newApp(ctx, logger, appOptions{Source: true, SourceName: *source, LLM: true, Provider: *provider, Store: true})
+	defer app.Close()
+
+	eventMetrics := observability.NewPrometheusMetrics()
+	searches := &searchService{
+		app:     app,
+		queue:   jobs.NewQueue(ctx, *workers, *queueSize, retainedJobs),
+		limits:  limits,
+		timeout: *timeout,
+		metrics: eventMetrics,
+		logger:  logger,
+	}
+	queue := searches.queue
+	// submit queues the search of a request, answering it on failure
+	submit := func(w http.ResponseWriter, r *http.Request) (job jobs.Job, runErr *error, ok bool) {
+		var req struct {
+			Query    string   `json:"query"`
+			Language string   `json:"lang"`
+			Exclude  []string `json:"exclude"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSearchRequestBytes)).Decode(&req); err != nil || strings.TrimSpace(req.Query) == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": `expected a JSON body with a "query"`})
+			return jobs.Job{}, nil, false
+		}
+		job, runErr, err := searches.submit(req.Query, req.Language, req.Exclude)
+		if errors.Is(err, jobs.ErrQueueFull) {
+			w.Header().Set("Retry-After", "60")
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": err.Error()})
+			return jobs.Job{}, nil, false
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return jobs.Job{}, nil, false
+		}
+		return job, runErr, true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /search", func(w http.ResponseWriter, r *http.Request) {
+		job, runErr, ok := submit(w, r)
+		if !ok {
+			return
+		}
+		id := job.ID
+		job, err := queue.Wait(r.Context(), id)
+		if errors.Is(err, jobs.ErrNotFound) {
+			// Pruned by newer jobs as soon as it finished
+			writeJSON(w, http.StatusGone, map[string]string{"run_id": id, "error": "the search finished, but its result is no longer retained"})
+			return
+		}
+		if err != nil {
+			// The client is gone, so is the point of the search
+			queue.Cancel(id)
+			return
+		}
+		if job.State != jobs.Completed {
+			err := *runErr
+			if err == nil {
+				// Cancelled through /jobs before it started
+				err = context.Canceled
+			}
+			class := observability.ClassifyError(err)
+			status := searchErrorStatus(class)
+			if errors.Is(err, agent.ErrUnclearRequest) {
+				status = http.StatusUnprocessableEntity
+			}
+			writeJSON(w, status, map[string]string{"run_id": job.ID, "error": err.Error(), "error_class": string(class)})
+			return
+		}
+		writeJSON(w, http.StatusOK, job.Result)
+	})
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+		job, _, ok := submit(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Location", "/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+	})
+	mux.HandleFunc("GET /jobs", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, queue.List())
+	})
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		job, err := queue.Get(r.PathValue("id"))
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	})
+	mux.HandleFunc("POST /jobs/{id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+		job, err := queue.Cancel(r.PathValue("id"))
+		switch {
+		case errors.Is(err, jobs.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, jobs.ErrFinished):
+			writeJSON(w, http.StatusConflict, map[string]any{"error": err.Error(), "job": job})
+		default:
+			writeJSON(w, http.StatusAccepted, job)
+		}
+	})
+	handleDownloads(mux, queue, app.store)
+	if app.store != nil {
+		handleCandidates(mux, app.store)
+	}
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		// Usage is cumulative, so it is rendered fresh on every scrape
+		usageMetrics := observability.NewPrometheusMetrics()
+		observability.RecordReportMetrics(usageMetrics, app.usage.Report())
+		queue.RecordMetrics(usageMetrics)
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		eventMetrics.WriteTo(w)
+		usageMetrics.WriteTo(w)
+	})
+
+	token := os.Getenv("SERVE_TOKEN")
+	server := &http.Server{Addr: *addr, Handler: requireToken(token, mux), ReadHeaderTimeout: 10 * time.Second}
+	var grpcServer *grpc.Server
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			fatalf("Error serving gRPC: %v\n", err)
+		}
+		grpcServer = grpc.NewServer(grpcTokenOptions(token)...)
+		sourcingv1.RegisterSourcingServiceServer(grpcServer, &sourcingServer{searches: searches})
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				fatalf("Error serving gRPC: %v\n", err)
+			}
+		}()
+		logger.Info("Serving the gRPC API", "addr", *grpcAddr)
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if grpcServer != nil {
+			// Streams end as the cancelled jobs finish
+			go grpcServer.GracefulStop()
+		}
+		server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info("Serving searches", "addr", *addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatalf("Error serving: %v\n", err)
+	}
+}
+
+// requireToken answers 401 to the requests without token as their bearer
+// token, but for GET /healthz. An empty token lets every request through.
+func requireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" && !validToken(r.Header.Get("Authorization"), token) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "a valid bearer token is required"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// validToken reports whether the Authorization header authorization
+// carries token as its bearer token
+func validToken(authorization, token string) bool {
+	bearer, ok := strings.CutPrefix(authorization, "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1
+}
+
+// searchService queues the searches of the HTTP and gRPC APIs as jobs
+type searchService struct {
+	app     *app
+	queue   *jobs.Queue
+	limits  agent.AgentConfig
+	timeout time.Duration
+	metrics observability.Metrics
+	logger  *slog.Logger
+}
+
+// submit queues a search for query, with lang overriding the server's -lang
+// and exclude adding to its -exclude. runErr is set to the error of the run
+// once the job finished, since the job only carries it as text. The error
+// is jobs.ErrQueueFull or one of invalid settings.
+func (s *searchService) submit(query, lang string, exclude []string) (job jobs.Job, runErr *error, err error) {
+	if strings.TrimSpace(query) == "" {
+		return jobs.Job{}, nil, errors.New("a query is required")
+	}
+	config := s.limits
+	if lang != "" {
+		config.Language = lang
+	}
+	config.Exclude = append(slices.Clip(s.limits.Exclude), exclude...)
+	if err := config.Validate(); err != nil {
+		return jobs.Job{}, nil, err
+	}
+
+	runID := agent.NewRunID()
+	config.RunID = runID
+	config.Logger = s.logger
+	config.FailureDumpDir = os.Getenv("FAILURE_DUMP_DIR")
+	config.Store = s.app.runStore()
+	config.Enrichers = s.app.enrichers
+	runErr = new(error)
+	job, err = s.queue.Submit(runID, query, func(ctx context.Context, progress observability.Subscriber) (*agent.FinalResult, error) {
+		ctx, cancel := context.WithTimeout(observability.WithAuditRun(ctx, runID, query), s.timeout)
+		defer cancel()
+		config.Events = s.app.events(runID, observability.MetricsSubscriber(s.metrics), progress)
+		result, err := agent.RunStage2WithConfig(ctx, s.app.llm, s.app.source, query, config)
+		*runErr = err
+		return result, err
+	})
+	if err != nil {
+		return jobs.Job{}, nil, err
+	}
+	return job, runErr, nil
+}
+
+// searchErrorStatus maps a failed search to an HTTP status. Provider
+// failures and unusable model output are upstream failures.
+func searchErrorStatus(class observability.ErrorClass) int {
+	switch class {
+	case observability.ErrorClassCancelled:
+		return http.StatusGatewayTimeout
+	case observability.ErrorClassLLMQuota, observability.ErrorClassGitHubRateLimit,
+		observability.ErrorClassGitLabRateLimit, observability.ErrorClassBitbucketRateLimit:
+		return http.StatusTooManyRequests
+	case observability.ErrorClassOther:
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// writeJSON writes v as the JSON response body with status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/luillyfe/sourcing-agent/pkg/vectorstore"
+)
+
+// runSimilar finds the indexed candidates of earlier runs most similar to a
+// job description, without searching the source platforms:
+//
+//	sourcing-agent similar [-n 10] [-min-score 0.5] [-json] "<job description>"|-
+//	sourcing-agent similar -index
+func runSimilar(args []string, logger *slog.Logger) {
+	fs := newFlagSet("similar")
+	limit := fs.Int("n", 10, "print the `n` most similar candidates")
+	minScore := fs.Float64("min-score", 0, "print only candidates with a similarity of at least `score`, from -1 to 1")
+	asJSON := fs.Bool("json", false, "print the candidates as JSON")
+	index := fs.Bool("index", false, "first index the candidates of the finished runs stored in DATABASE_URL, e.g. of runs before VECTOR_STORE_URL was set")
+	provider := llmFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent similar [flags] [\"<job description>\"|-]")
+		fs.PrintDefaults()
+		fmt.Fprintln(fs.Output(), "\nThe candidates of search, resume, batch, watch and serve runs are indexed when VECTOR_STORE_URL is set.")
+	}
+	fs.Parse(args)
+	query := queryArgs(fs.Args())
+	if strings.TrimSpace(query) == "" && !*index {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	if os.Getenv("VECTOR_STORE_URL") == "" {
+		exitf(exitConfig, "Error: VECTOR_STORE_URL is not set\nSet it to a SQLite file, e.g. vectors.db, or a postgres:// URL of a database with pgvector to index candidates\n")
+	}
+	if *index && os.Getenv("DATABASE_URL") == "" {
+		exitf(exitConfig, "Error: -index needs DATABASE_URL, the database of the runs to index\n")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app := newApp(ctx, logger, appOptions{LLM: true, Provider: *provider, Store: true})
+	defer app.Close()
+
+	if *index {
+		indexStoredRuns(ctx, app)
+	}
+	if strings.TrimSpace(query) == "" {
+		return
+	}
+	matches, err := app.index.Similar(ctx, query, *limit)
+	if err != nil {
+		fatalRunError(err)
+	}
+	for i, m := range matches {
+		if m.Score < *minScore {
+			matches = matches[:i]
+			break
+		}
+	}
+	if *asJSON {
+		printJSON(matches)
+		return
+	}
+	printMatches(matches)
+}
+
+// indexStoredRuns indexes the candidates of the finished runs in the
+// database, each as last enriched
+func indexStoredRuns(ctx context.Context, app *app) {
+	runs, err := app.store.ListRuns(ctx, 0)
+	if err != nil {
+		fatalf("Error: %v\n", err)
+	}
+	seen := map[string]bool{}
+	indexed, indexedRuns := 0, 0
+	for _, summary := range runs {
+		if summary.FinishedAt == nil || summary.CandidateCount == 0 {
+			continue
+		}
+		run, err := app.store.GetRun(ctx, summary.ID)
+		if err != nil {
+			fatalf("Error: %v\n", err)
+		}
+		// Runs come newest first, so each candidate is indexed as last enriched
+		candidates := run.Candidates[:0]
+		for _, c := range run.Candidates {
+			if !seen[c.Username] {
+				seen[c.Username] = true
+				candidates = append(candidates, c)
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		if err := app.index.Index(ctx, run.ID, candidates); err != nil {
+			fatalRunError(fmt.Errorf("failed to index run %s: %w", run.ID, err))
+		}
+		indexed += len(candidates)
+		indexedRuns++
+	}
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Indexed %d candidates of %d runs\n", indexed, indexedRuns)
+	}
+}
+
+// printMatches prints similar candidates as a table
+func printMatches(matches []vectorstore.Match) {
+	if len(matches) == 0 {
+		fmt.Println("No similar candidates indexed yet.")
+		return
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SIMILARITY\tUSERNAME\tNAME\tLOCATION\tSKILLS\tRUN ID")
+	for _, m := range matches {
+		c := m.Candidate
+		fmt.Fprintf(tw, "%.2f\t%s\t%s\t%s\t%s\t%s\n", m.Score, c.Username, c.Name, c.Location, strings.Join(c.SkillsFound, ", "), m.RunID)
+	}
+	tw.Flush()
+}
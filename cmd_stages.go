@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/export"
+)
+
+// runEnrich searches and enriches candidates for a saved strategy, e.g. a
+// search result or a failure snapshot, and prints the pipeline state with
+// the candidates for rank
+func runEnrich(args []string, logger *slog.Logger) {
+	fs := newFlagSet("enrich")
+	in := fs.String("in", "-", "read requirements and strategy JSON from `file` (- for stdin)")
+	maxCandidates := maxCandidatesFlag(fs)
+	relevanceThreshold := relevanceThresholdFlag(fs)
+	source := sourceFlag(fs)
+	progress := progressFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent enrich [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	checkSource(*source)
+	config := agent.AgentConfig{MaxSearchResults: *maxCandidates, RelevanceThreshold: relevanceThreshold, Source: *source}
+	checkSearchLimits(config)
+
+	state := readPipelineState(*in)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app := newApp(ctx, logger, appOptions{RunID: state.RunID, Query: state.Query, Source: true, SourceName: *source})
+	defer app.Close()
+
+	config.RunID = state.RunID
+	config.Logger = logger
+	config.Events = app.events(state.RunID)
+	config.Enrichers = app.enrichers
+	config.Progress = progressReporter(*progress)
+	candidates, err := agent.EnrichCandidates(ctx, app.source, state.Requirements, state.Strategy, config)
+	if err != nil {
+		fatalRunError(err)
+	}
+	state.Candidates = candidates
+	printJSON(state)
+}
+
+// runRank ranks previously enriched candidates, e.g. the output of enrich or
+// a failure snapshot of a failed ranking, and prints the result
+func runRank(args []string, logger *slog.Logger) {
+	fs := newFlagSet("rank")
+	in := fs.String("in", "-", "read requirements and candidates JSON from `file` (- for stdin)")
+	provider := llmFlag(fs)
+	targetCount := targetCountFlag(fs)
+	format := formatFlag(fs, "the result")
+	actions := resultActionFlags(fs)
+	language := langFlag(fs)
+	exclude := excludeFlags(fs)
+	timeout := timeoutFlag(fs)
+	budget := budgetFlag(fs)
+	maxLLMCalls := maxLLMCallsFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent rank [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	checkFormat(*format)
+	actions.check()
+	config := agent.AgentConfig{TargetCount: *targetCount, Timeout: *timeout, MaxCostUSD: *budget, MaxLLMCalls: *maxLLMCalls, Language: *language, Exclude: exclude()}
+	checkSearchLimits(config)
+
+	state := readPipelineState(*in)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	app := newApp(ctx, logger, appOptions{RunID: state.RunID, Query: state.Query, LLM: true, Provider: *provider})
+	defer app.Close()
+
+	config.RunID = state.RunID
+	config.Logger = logger
+	config.Events = app.events(state.RunID)
+	result, err := agent.RankCandidates(ctx, app.llm, state.Requirements, state.Candidates, config)
+	if err != nil {
+		fatalRunError(err)
+	}
+	result.Strategy = state.Strategy
+	writeResult(*format, result)
+	actions.run(result, defaultSource(), logger)
+}
+
+// formatFlag adds the -format flag for a result printed on stdout. It
+// defaults to pretty cards for a person at a terminal and to JSON when the
+// output is piped or redirected, e.g. into rank or jq.
+func formatFlag(fs *flag.FlagSet, what string) *string {
+	format := agent.FormatJSON
+	if isTerminal(os.Stdout) {
+		format = agent.FormatPretty
+	}
+	return fs.String("format", format, "print "+what+" as `format`: "+strings.Join(resultFormats, ", "))
+}
+
+// resultFormats lists the -format values: the agent's text formats and the
+// spreadsheet formats of package export
+var resultFormats = slices.Concat(agent.ResultFormats, export.Formats)
+
+// writeResult prints result on stdout in format, coloring pretty output
+// unless NO_COLOR is set or stdout is not a terminal
+func writeResult(format string, result *agent.FinalResult) {
+	var err error
+	switch {
+	case format == agent.FormatPretty:
+		err = agent.WritePretty(os.Stdout, result, isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "")
+	case format == export.XLSX && isTerminal(os.Stdout):
+		exitf(exitUsage, "Error: xlsx is a binary format; redirect it to a file, e.g. > candidates.xlsx\n")
+	default:
+		err = writeResultTo(os.Stdout, format, result)
+	}
+	if err != nil {
+		fatalf("Error writing result: %v\n", err)
+	}
+}
+
+// writeResultTo writes result to w in format, through package export for
+// the spreadsheet formats
+func writeResultTo(w io.Writer, format string, result *agent.FinalResult) error {
+	if slices.Contains(export.Formats, format) {
+		return export.Write(w, format, result)
+	}
+	return agent.WriteResult(w, format, result)
+}
+
+// checkFormat exits with usage help for an unknown -format
+func checkFormat(format string) {
+	if !slices.Contains(resultFormats, format) {
+		exitf(exitUsage, "Error: unknown format %q: want one of %s\n", format, strings.Join(resultFormats, ", "))
+	}
+}
+
+// readPipelineState decodes the pipeline state at path, or stdin for "-"
+func readPipelineState(path string) agent.PipelineState {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			fatalf("Error reading pipeline state: %v\n", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	var state agent.PipelineState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		fatalf("Error reading pipeline state: %v\n", err)
+	}
+	return state
+}
+
+// printJSON prints v as indented JSON on stdout
+func printJSON(v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fatalf("Error encoding output: %v\n", err)
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/openai"
+	"github.com/luillyfe/sourcing-agent/pkg/vertexai"
+)
+
+// Build details, set by release builds with
+// -ldflags "-X main.version=v1.2.0 -X main.commit=$(git rev-parse HEAD) -X main.buildDate=$(date -u +%FT%TZ)".
+// Otherwise the version and commit are read from the build info the Go
+// toolchain embeds, which has the commit time but no build date.
+var (
+	version   string
+	commit    string
+	buildDate string
+)
+
+// providerSDKs are the modules each provider's client is built on. The
+// others call their provider's REST API directly.
+var providerSDKs = map[string]string{
+	providerVertexAI: "google.golang.org/genai",
+}
+
+// versionInfo describes the build, for comparing results across builds
+type versionInfo struct {
+	Version         string          `json:"version"`
+	Commit          string          `json:"commit,omitempty"`
+	Modified        bool            `json:"modified,omitempty"`
+	CommitTime      string          `json:"commit_time,omitempty"`
+	BuildDate       string          `json:"build_date,omitempty"`
+	GoVersion       string          `json:"go_version"`
+	DefaultProvider string          `json:"default_provider"`
+	Providers       []providerBuild `json:"providers"`
+}
+
+// providerBuild is the configured models and client SDK of a provider
+type providerBuild struct {
+	Name        string            `json:"name"`
+	Model       string            `json:"model,omitempty"`
+	StageModels map[string]string `json:"stage_models,omitempty"`
+	SDK         string            `json:"sdk,omitempty"`
+}
+
+// runVersion prints the build and the models it is configured to call:
+// sourcing-agent version
+func runVersion(args []string, logger *slog.Logger) {
+	fs := newFlagSet("version")
+	asJSON := fs.Bool("json", false, "print the build details as JSON")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent version [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	info := buildVersion()
+	if *asJSON {
+		printJSON(info)
+		return
+	}
+	fmt.Printf("sourcing-agent %s\n", info.Version)
+	if info.Commit != "" {
+		modified := ""
+		if info.Modified {
+			modified = " (modified)"
+		}
+		fmt.Printf("  commit:    %s%s\n", info.Commit, modified)
+	}
+	if info.CommitTime != "" {
+		fmt.Printf("  committed: %s\n", info.CommitTime)
+	}
+	if info.BuildDate != "" {
+		fmt.Printf("  built:     %s\n", info.BuildDate)
+	}
+	fmt.Printf("  go:        %s\n", info.GoVersion)
+	fmt.Printf("\nDefault provider: %s\n", info.DefaultProvider)
+	for _, p := range info.Providers {
+		model := p.Model
+		if model == "" {
+			model = "(not configured)"
+		}
+		sdk := "REST API"
+		if p.SDK != "" {
+			sdk = p.SDK
+		}
+		fmt.Printf("  %-10s %-40s %s\n", p.Name, model, sdk)
+		for _, stage := range slices.Sorted(maps.Keys(p.StageModels)) {
+			fmt.Printf("  %-10s   %s: %s\n", "", stage, p.StageModels[stage])
+		}
+	}
+}
+
+// buildVersion collects the build details, preferring the values set at
+// link time over the embedded build info
+func buildVersion() versionInfo {
+	info := versionInfo{
+		Version:         "(devel)",
+		GoVersion:       runtime.Version(),
+		DefaultProvider: defaultLLMProvider(),
+	}
+	deps := map[string]string{}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if build.Main.Version != "" {
+			info.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.Commit = setting.Value
+			case "vcs.time":
+				info.CommitTime = setting.Value
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+		for _, dep := range build.Deps {
+			if dep.Replace != nil {
+				dep = dep.Replace
+			}
+			deps[dep.Path] = dep.Version
+		}
+	}
+	if version != "" {
+		info.Version = version
+	}
+	if commit != "" && commit != info.Commit {
+		// The embedded commit details are of a different checkout
+		info.Commit, info.Modified, info.CommitTime = commit, false, ""
+	}
+	if buildDate != "" {
+		info.BuildDate = buildDate
+	}
+
+	for _, name := range llmProviders().Names() {
+		p := providerBuild{Name: name}
+		switch name {
+		case providerVertexAI:
+			p.Model = envOr("VERTEX_MODEL", vertexai.DefaultModel)
+			p.StageModels = envMap("VERTEX_STAGE_MODELS")
+		case providerAnthropic:
+			config, _ := anthropicPlatform()
+			client := newAnthropicClient(config)
+			p.Model, p.StageModels = client.Model, client.StageModels
+		case providerOpenAI:
+			p.Model = envOr("OPENAI_MODEL", openai.DefaultModel)
+			p.StageModels = envMap("OPENAI_STAGE_MODELS")
+		case providerOllama:
+			// Ollama has no default: it runs whatever model was pulled
+			p.Model = os.Getenv("OLLAMA_MODEL")
+			p.StageModels = envMap("OLLAMA_STAGE_MODELS")
+		}
+		if module, ok := providerSDKs[name]; ok {
+			p.SDK = strings.TrimSpace(module + " " + deps[module])
+		}
+		info.Providers = append(info.Providers, p)
+	}
+	return info
+}
+
+// envOr returns the value of key, or fallback when it is not set
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// runWatch reruns a search on a schedule and reports only the candidates no
+// earlier run surfaced: sourcing-agent watch -interval 24h "<query>"
+func runWatch(args []string, logger *slog.Logger) {
+	fs := newFlagSet("watch")
+	interval := fs.Duration("interval", 24*time.Hour, "rerun the search every `duration`")
+	once := fs.Bool("once", false, "run the search once and exit, e.g. from cron")
+	statePath := fs.String("state", "sourcing-watch.json", "remember the reported candidates in `file` across restarts")
+	format := formatFlag(fs, "new candidates")
+	provider := llmFlag(fs)
+	source := sourceFlag(fs)
+	language := langFlag(fs)
+	exclude := excludeFlags(fs)
+	targetCount := targetCountFlag(fs)
+	maxCandidates := maxCandidatesFlag(fs)
+	relevanceThreshold := relevanceThresholdFlag(fs)
+	timeout := timeoutFlag(fs)
+	budget := budgetFlag(fs)
+	maxLLMCalls := maxLLMCallsFlag(fs)
+	verbosityFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sourcing-agent watch [flags] \"<query>\"|-")
+		fmt.Fprintln(fs.Output(), "\nThe first run reports every candidate; later runs only candidates no earlier run reported.")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	query := queryArgs(fs.Args())
+	if strings.TrimSpace(query) == "" {
+		fs.Usage()
+		os.Exit(exitUsage)
+	}
+	checkFormat(*format)
+	checkSource(*source)
+	if *interval <= 0 {
+		exitf(exitUsage, "Error: -interval must be positive\n")
+	}
+	config := agent.AgentConfig{
+		Logger:             logger,
+		TargetCount:        *targetCount,
+		MaxSearchResults:   *maxCandidates,
+		RelevanceThreshold: relevanceThreshold,
+		Timeout:            *timeout,
+		MaxCostUSD:         *budget,
+		MaxLLMCalls:        *maxLLMCalls,
+		Language:           *language,
+		Exclude:            exclude(),
+		Source:             *source,
+	}
+	checkSearchLimits(config)
+	state, err := agent.LoadWatchState(*statePath, query)
+	if err != nil {
+		exitf(exitUsage, "Error: %v; pass -state with another file to watch this query\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// Runs share the clients, so rate limits apply across them
+	app := newApp(ctx, logger, appOptions{Query: query, Source: true, SourceName: *source, LLM: true, Provider: *provider, Store: true, Notify: true, Digest: true})
+	defer app.Close()
+
+	for {
+		err := watchRun(ctx, app, state, *statePath, query, *format, config)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil && (*once || errors.Is(err, agent.ErrUnclearRequest)):
+			// Rerunning an unclear query cannot succeed
+			fatalRunError(err)
+		case err != nil:
+			logger.Error("Watch run failed, retrying at the next interval", "error", err)
+		}
+		if *once {
+			return
+		}
+		logger.Info("Next watch run", "at", time.Now().Add(*interval).Format(time.RFC3339))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// watchRun runs the watched query once, writes the candidates no earlier
+// run reported and saves them to the state
+func watchRun(ctx context.Context, app *app, state *agent.WatchState, statePath, query, format string, config agent.AgentConfig) error {
+	runID := agent.NewRunID()
+	config.RunID = runID
+	config.Events = app.events(runID)
+	config.Store = app.runStore()
+	config.Enrichers = app.enrichers
+	result, err := agent.RunStage2WithConfig(observability.WithAuditRun(ctx, runID, query), app.llm, app.source, query, config)
+	if err != nil {
+		return err
+	}
+	if result.Partial {
+		// Unranked candidates are left for the next ranking to report
+		app.logger.Warn("Ranking failed, leaving the candidates for the next run", "run_id", runID)
+		exitStatus = exitPartial
+		return nil
+	}
+
+	now := time.Now().UTC()
+	diff := state.Diff(result, now)
+	if err := state.Save(statePath); err != nil {
+		return err
+	}
+	if !quiet && !jsonLogs {
+		fmt.Fprintf(os.Stderr, "=== Watch run %d at %s: %d new of %d candidates ===\n",
+			state.Runs, now.Format(time.RFC3339), len(diff.TopCandidates), len(result.TopCandidates))
+	}
+	app.logger.Info("Watch run finished", "run_id", runID, "new_candidates", len(diff.TopCandidates), "candidates", len(result.TopCandidates))
+	if len(diff.TopCandidates) > 0 {
+		writeResult(format, diff)
+	}
+	app.notify(ctx, query, result, diff.TopCandidates)
+	return nil
+}
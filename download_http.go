@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/export"
+	"github.com/luillyfe/sourcing-agent/pkg/jobs"
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+)
+
+// handleDownloads serves results as spreadsheet files on mux:
+//
+//	GET /jobs/{id}/download?format=xlsx  the result of a completed job
+//	GET /runs/{id}/download?format=xlsx  the result of a stored run, with a store
+//
+// The format is csv or xlsx, csv if not given.
+func handleDownloads(mux *http.ServeMux, queue *jobs.Queue, store *storage.Store) {
+	mux.HandleFunc("GET /jobs/{id}/download", func(w http.ResponseWriter, r *http.Request) {
+		job, err := queue.Get(r.PathValue("id"))
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		if job.Result == nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("job %s is %s, without a result", job.ID, job.State)})
+			return
+		}
+		writeDownload(w, r, job.ID, job.Result)
+	})
+	if store == nil {
+		return
+	}
+	mux.HandleFunc("GET /runs/{id}/download", func(w http.ResponseWriter, r *http.Request) {
+		run, err := store.GetRun(r.Context(), r.PathValue("id"))
+		if errors.Is(err, storage.ErrNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if run.Result == nil {
+			writeJSON(w, http.StatusConflict, map[string]string{"error": fmt.Sprintf("run %s did not finish; its last completed stage is %s", run.ID, run.Stage)})
+			return
+		}
+		writeDownload(w, r, run.ID, run.Result)
+	})
+}
+
+// writeDownload answers with result as an attachment in the requested format
+func writeDownload(w http.ResponseWriter, r *http.Request, id string, result *agent.FinalResult) {
+	format := r.FormValue("format")
+	if format == "" {
+		format = export.CSV
+	}
+	if !slices.Contains(export.Formats, format) {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("unknown format %q: want one of %s", format, strings.Join(export.Formats, ", "))})
+		return
+	}
+	// Rendered first, so a failure can still be answered as an error
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, result); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="sourcing-%s.%s"`, id, format))
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/huggingface"
+	"github.com/luillyfe/sourcing-agent/pkg/kaggle"
+	"github.com/luillyfe/sourcing-agent/pkg/stackoverflow"
+)
+
+// Enrichers selectable with ENRICHERS
+const (
+	enricherStackOverflow = "stackoverflow"
+	enricherHuggingFace   = "huggingface"
+	enricherKaggle        = "kaggle"
+)
+
+// enricherNames lists the enrichers
+var enricherNames = []string{enricherStackOverflow, enricherHuggingFace, enricherKaggle}
+
+// newEnrichers builds the enrichers named, in order, from their settings,
+// sending their requests through httpClient. The Hugging Face and Kaggle
+// enrichers only run for machine learning roles.
+func newEnrichers(names []string, httpClient *http.Client, logger *slog.Logger) ([]agent.Enricher, error) {
+	var enrichers []agent.Enricher
+	for _, name := range names {
+		switch name {
+		case enricherStackOverflow:
+			client := stackoverflow.NewClient(os.Getenv("STACKEXCHANGE_KEY"))
+			client.HTTPClient = httpClient
+			client.Logger = logger
+			enricher := &stackoverflow.Enricher{Client: client}
+			if s := os.Getenv("STACKOVERFLOW_MIN_CONFIDENCE"); s != "" {
+				confidence, err := strconv.ParseFloat(s, 64)
+				if err != nil || confidence <= 0 || confidence > 1 {
+					return nil, fmt.Errorf("STACKOVERFLOW_MIN_CONFIDENCE: want a confidence above 0 and up to 1, got %q", s)
+				}
+				enricher.MinConfidence = confidence
+			}
+			enrichers = append(enrichers, enricher)
+		case enricherHuggingFace:
+			client := huggingface.NewClient(os.Getenv("HF_TOKEN"))
+			client.HTTPClient = httpClient
+			client.Logger = logger
+			enrichers = append(enrichers, &huggingface.Enricher{Client: client})
+		case enricherKaggle:
+			if err := requireEnv("KAGGLE_USERNAME", "KAGGLE_KEY"); err != nil {
+				return nil, fmt.Errorf("kaggle: %w", err)
+			}
+			client := kaggle.NewClient(os.Getenv("KAGGLE_USERNAME"), os.Getenv("KAGGLE_KEY"))
+			client.HTTPClient = httpClient
+			client.Logger = logger
+			enrichers = append(enrichers, &kaggle.Enricher{Client: client})
+		default:
+			return nil, fmt.Errorf("unknown enricher %q: want any of %s", name, strings.Join(enricherNames, ", "))
+		}
+	}
+	return enrichers, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// Exit codes, listed in the usage text, so wrapper scripts and CI jobs can
+// branch on the kind of failure
+const (
+	exitOK           = 0
+	exitFailure      = 1 // Any other failure
+	exitUsage        = 2 // Invalid flags or arguments, as the flag package exits
+	exitConfig       = 3 // Missing or invalid settings or credentials; doctor found a problem
+	exitUnclearQuery = 4 // The query is too vague to search for; the clarification question is printed
+	exitRateLimited  = 5 // An LLM provider or source platform rate limit or quota was exhausted
+	exitPartial      = 6 // A result was printed, but unranked after a ranking failure, some batch queries failed, some candidates were not exported, or some outreach messages were not polished
+)
+
+// exitStatus is the code main exits with once the command returns, for
+// commands that print a result and still report a problem
+var exitStatus = exitOK
+
+// exitCode returns the exit code for a failed run
+func exitCode(err error) int {
+	if errors.Is(err, agent.ErrUnclearRequest) {
+		return exitUnclearQuery
+	}
+	switch observability.ClassifyError(err) {
+	case observability.ErrorClassLLMQuota, observability.ErrorClassGitHubRateLimit,
+		observability.ErrorClassGitLabRateLimit, observability.ErrorClassBitbucketRateLimit:
+		return exitRateLimited
+	}
+	return exitFailure
+}
+
+// fatalRunError reports a failed run with its error class and exits with
+// its exit code
+func fatalRunError(err error) {
+	exitf(exitCode(err), "Error [%s]: %v\n", observability.ClassifyError(err), err)
+}
+
+// fatalf reports a failure and exits with exitFailure
+func fatalf(format string, args ...any) {
+	exitf(exitFailure, format, args...)
+}
+
+// exitf prints a failure to stdout (stderr in quiet mode), or logs it on
+// stderr in JSON log mode, and exits with code
+func exitf(code int, format string, args ...any) {
+	switch {
+	case jsonLogs:
+		slog.Error(strings.TrimSpace(fmt.Sprintf(format, args...)), "exit_code", code)
+	case quiet:
+		fmt.Fprintf(os.Stderr, format, args...)
+	default:
+		fmt.Printf(format, args...)
+	}
+	os.Exit(code)
+}
+
+// reportPartial sets exitPartial when result is a fallback rather than a ranking
+func reportPartial(result *agent.FinalResult) {
+	if result.Partial {
+		exitStatus = exitPartial
+	}
+}
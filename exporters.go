@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/ats"
+)
+
+// ATS exporters selectable with export -to or ATS_EXPORTER
+const (
+	atsLever = "lever"
+)
+
+// atsExporters registers the exporters export -to chooses from. Each
+// factory validates its own settings, so only the selected ATS needs them.
+func atsExporters() *ats.Exporters {
+	var exporters ats.Exporters
+	exporters.Register(atsLever, func() (ats.Exporter, error) {
+		if err := requireEnv("LEVER_API_KEY", "LEVER_PERFORM_AS"); err != nil {
+			return nil, err
+		}
+		lever := ats.NewLever(os.Getenv("LEVER_API_KEY"), os.Getenv("LEVER_PERFORM_AS"))
+		if baseURL := os.Getenv("LEVER_BASE_URL"); baseURL != "" {
+			lever.BaseURL = baseURL
+		}
+		lever.PostingID = os.Getenv("LEVER_POSTING_ID")
+		lever.StageID = os.Getenv("LEVER_STAGE_ID")
+		lever.Tags = envList("LEVER_TAGS")
+		return lever, nil
+	})
+	return &exporters
+}
+
+// atsFlag adds the -to ATS flag to fs, defaulting to ATS_EXPORTER
+func atsFlag(fs *flag.FlagSet) *string {
+	return fs.String("to", os.Getenv("ATS_EXPORTER"), "`ATS` to export to: "+strings.Join(atsExporters().Names(), ", "))
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	sourcingv1 "github.com/luillyfe/sourcing-agent/api/sourcing/v1"
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/jobs"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sourcingServer serves the searches of serve over gRPC
+type sourcingServer struct {
+	sourcingv1.UnimplementedSourcingServiceServer
+	searches *searchService
+}
+
+func (s *sourcingServer) StartSearch(ctx context.Context, req *sourcingv1.StartSearchRequest) (*sourcingv1.Search, error) {
+	job, _, err := s.searches.submit(req.GetQuery(), req.GetLang(), req.GetExclude())
+	if errors.Is(err, jobs.ErrQueueFull) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return searchProto(job), nil
+}
+
+func (s *sourcingServer) WatchSearch(req *sourcingv1.WatchSearchRequest, stream grpc.ServerStreamingServer[sourcingv1.SearchEvent]) error {
+	job, err := s.searches.queue.Watch(stream.Context(), req.GetSearchId(), func(e jobs.Event) error {
+		if event := searchEventProto(e); event != nil {
+			return stream.Send(event)
+		}
+		return nil
+	})
+	if err != nil {
+		return grpcError(err)
+	}
+	return stream.Send(&sourcingv1.SearchEvent{
+		Time:  timestamppb.New(*job.FinishedAt),
+		Event: &sourcingv1.SearchEvent_Finished{Finished: searchProto(job)},
+	})
+}
+
+func (s *sourcingServer) GetResult(ctx context.Context, req *sourcingv1.GetResultRequest) (*sourcingv1.Search, error) {
+	job, err := s.searches.queue.Get(req.GetSearchId())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return searchProto(job), nil
+}
+
+// grpcTokenOptions require token as the bearer token in the authorization
+// metadata of every call; there are none when token is empty
+func grpcTokenOptions(token string) []grpc.ServerOption {
+	if token == "" {
+		return nil
+	}
+	authorize := func(ctx context.Context) error {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, authorization := range md.Get("authorization") {
+			if validToken(authorization, token) {
+				return nil
+			}
+		}
+		return status.Error(codes.Unauthenticated, "a valid bearer token is required")
+	}
+	return []grpc.ServerOption{
+		grpc.UnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := authorize(ctx); err != nil {
+				return nil, err
+			}
+			return handler(ctx, req)
+		}),
+		grpc.StreamInterceptor(func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := authorize(stream.Context()); err != nil {
+				return err
+			}
+			return handler(srv, stream)
+		}),
+	}
+}
+
+// grpcError maps a jobs or context error to a gRPC status
+func grpcError(err error) error {
+	if errors.Is(err, jobs.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	if s := status.FromContextError(err); s.Code() != codes.Unknown {
+		return s.Err()
+	}
+	return err
+}
+
+// searchEventProto converts the pipeline events streamed by WatchSearch,
+// returning nil for the others
+func searchEventProto(e jobs.Event) *sourcingv1.SearchEvent {
+	event := &sourcingv1.SearchEvent{Time: timestamppb.New(e.Time)}
+	switch e := e.Event.(type) {
+	case observability.StageStarted:
+		event.Event = &sourcingv1.SearchEvent_StageStarted{StageStarted: &sourcingv1.StageStarted{Stage: e.Stage}}
+	case observability.StageFailed:
+		event.Event = &sourcingv1.SearchEvent_StageFailed{StageFailed: &sourcingv1.StageFailed{
+			Stage:      e.Stage,
+			Error:      e.Err.Error(),
+			ErrorClass: string(observability.ClassifyError(e.Err)),
+		}}
+	case observability.CandidateEnriched:
+		if e.Err != nil {
+			return nil
+		}
+		event.Event = &sourcingv1.SearchEvent_CandidateEnriched{CandidateEnriched: &sourcingv1.CandidateEnriched{
+			Username:             e.Username,
+			RelevantRepositories: int32(e.RelevantRepositories),
+		}}
+	default:
+		return nil
+	}
+	return event
+}
+
+// searchProto converts a job
+func searchProto(job jobs.Job) *sourcingv1.Search {
+	search := &sourcingv1.Search{
+		SearchId:           job.ID,
+		Query:              job.Query,
+		State:              searchStateProto(job.State),
+		CandidatesEnriched: int32(job.Enriched),
+		CreatedAt:          timestamppb.New(job.CreatedAt),
+		Error:              job.Error,
+		ErrorClass:         job.ErrorClass,
+		Result:             resultProto(job.Result),
+	}
+	if job.StartedAt != nil {
+		search.StartedAt = timestamppb.New(*job.StartedAt)
+	}
+	if job.FinishedAt != nil {
+		search.FinishedAt = timestamppb.New(*job.FinishedAt)
+	}
+	for _, stage := range job.Stages {
+		search.Stages = append(search.Stages, &sourcingv1.StageProgress{
+			Stage:      stage.Stage,
+			State:      searchStateProto(stage.State),
+			DurationMs: stage.DurationMS,
+		})
+	}
+	return search
+}
+
+// searchStateProto converts a job state
+func searchStateProto(state jobs.State) sourcingv1.SearchState {
+	switch state {
+	case jobs.Queued:
+		return sourcingv1.SearchState_SEARCH_STATE_QUEUED
+	case jobs.Running:
+		return sourcingv1.SearchState_SEARCH_STATE_RUNNING
+	case jobs.Completed:
+		return sourcingv1.SearchState_SEARCH_STATE_COMPLETED
+	case jobs.Failed:
+		return sourcingv1.SearchState_SEARCH_STATE_FAILED
+	case jobs.Cancelled:
+		return sourcingv1.SearchState_SEARCH_STATE_CANCELLED
+	default:
+		return sourcingv1.SearchState_SEARCH_STATE_UNSPECIFIED
+	}
+}
+
+// resultProto converts a search result, nil while there is none
+func resultProto(result *agent.FinalResult) *sourcingv1.Result {
+	if result == nil {
+		return nil
+	}
+	r := &sourcingv1.Result{
+		Summary: &sourcingv1.ResultSummary{
+			TotalCandidatesFound: int32(result.Summary.TotalCandidatesFound),
+			CandidatesPresented:  int32(result.Summary.CandidatesPresented),
+			AverageMatchScore:    result.Summary.AverageMatchScore,
+			SearchQuality:        result.Summary.SearchQuality,
+		},
+		Partial: result.Partial,
+	}
+	for _, c := range result.TopCandidates {
+		candidate := &sourcingv1.RankedCandidate{
+			Rank:            int32(c.Rank),
+			Username:        c.Username,
+			Name:            c.Name,
+			Location:        c.Location,
+			GithubUrl:       c.GitHubURL,
+			FinalMatchScore: c.FinalMatchScore,
+			MatchBreakdown: &sourcingv1.MatchBreakdown{
+				RequiredSkillsScore:      c.MatchBreakdown.RequiredSkillsScore,
+				RepositoryRelevanceScore: c.MatchBreakdown.RepositoryRelevanceScore,
+				ExperienceScore:          c.MatchBreakdown.ExperienceScore,
+				ProfileQualityScore:      c.MatchBreakdown.ProfileQualityScore,
+			},
+			KeyQualifications: c.KeyQualifications,
+			MatchReasoning:    c.MatchReasoning,
+			PotentialConcerns: c.PotentialConcerns,
+		}
+		for _, p := range c.TopRelevantProjects {
+			candidate.TopRelevantProjects = append(candidate.TopRelevantProjects, &sourcingv1.RelevantProject{
+				Name: p.Name, Url: p.URL, WhyRelevant: p.WhyRelevant,
+			})
+		}
+		r.TopCandidates = append(r.TopCandidates, candidate)
+	}
+	return r
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	sourcingv1 "github.com/luillyfe/sourcing-agent/api/sourcing/v1"
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/jobs"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// stageResponses are the model's answers to each pipeline stage
+var stageResponses = map[string]string{
+	agent.StageRequirements: `{"required_skills": ["Go"], "experience_level": "senior", "locations": ["Lima"], "keywords": ["backend"]}`,
+	agent.StageStrategy:     `{"primary_search": {"language": "go", "location": "lima"}, "strategy_notes": "Go developers in Lima"}`,
+	agent.StageRanking: `{"top_candidates": [{"username": "gopher_lima", "name": "Ana Quispe", "location": "Lima, Peru",
+		"match_breakdown": {"required_skills_score": 95, "repository_relevance_score": 90, "experience_score": 85, "profile_quality_score": 80},
+		"key_qualifications": ["Go", "gRPC"], "match_reasoning": "Senior Go backend engineer in Lima."}],
+		"summary": {"total_candidates_found": 1, "candidates_presented": 1, "search_quality": "good"}}`,
+}
+
+// stageLLM answers each call with the stage's response
+var stageLLM = llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	stage := llm.ApplyOptions(opts).Stage
+	text, ok := stageResponses[stage]
+	if !ok {
+		return nil, errors.New("unexpected stage " + stage)
+	}
+	return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: text}}, StopReason: "end_turn"}, nil
+})
+
+// limaSource finds a single Go developer in Lima
+type limaSource struct{}
+
+func (limaSource) SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error) {
+	return &github.SearchResult{TotalFound: 1, Candidates: []github.Candidate{{
+		Username: "gopher_lima", Name: "Ana Quispe", Location: "Lima, Peru", Bio: "Backend engineer. Go and gRPC.",
+		PublicRepos: 32, Followers: 140, GitHubURL: "https://github.com/gopher_lima",
+	}}}, nil
+}
+
+func (limaSource) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	return &github.UserDetail{Login: username, Name: "Ana Quispe", Location: "Lima, Peru",
+		PublicRepos: 32, Followers: 140, HTMLURL: "https://github.com/" + username}, nil
+}
+
+func (limaSource) GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error) {
+	return []github.Repository{{
+		Name: "grpc-gateway-kit", Description: "Microservices toolkit for Go backends", Language: "Go", Stars: 210,
+		Topics: []string{"backend", "grpc"}, URL: "https://github.com/" + username + "/grpc-gateway-kit",
+	}}, nil
+}
+
+// newSourcingClient serves the searches of a queue over an in-memory
+// connection with the server options, returning its client
+func newSourcingClient(t *testing.T, opts ...grpc.ServerOption) sourcingv1.SourcingServiceClient {
+	t.Helper()
+	t.Setenv("SENTRY_DSN", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := slog.New(slog.DiscardHandler)
+	searches := &searchService{
+		app:     &app{logger: logger, usage: observability.NewUsageCollector(), llm: stageLLM, source: limaSource{}},
+		queue:   jobs.NewQueue(ctx, 1, 10, 10),
+		timeout: time.Minute,
+		metrics: observability.NewPrometheusMetrics(),
+		logger:  logger,
+	}
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	sourcingv1.RegisterSourcingServiceServer(server, &sourcingServer{searches: searches})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to dial the server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return sourcingv1.NewSourcingServiceClient(conn)
+}
+
+func TestGRPCSearch(t *testing.T) {
+	client := newSourcingClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	search, err := client.StartSearch(ctx, &sourcingv1.StartSearchRequest{Query: "Senior Go developers in Lima"})
+	if err != nil {
+		t.Fatalf("StartSearch failed: %v", err)
+	}
+	if search.GetSearchId() == "" || search.GetQuery() != "Senior Go developers in Lima" {
+		t.Fatalf("Expected the queued search with an ID, got %v", search)
+	}
+
+	stream, err := client.WatchSearch(ctx, &sourcingv1.WatchSearchRequest{SearchId: search.GetSearchId()})
+	if err != nil {
+		t.Fatalf("WatchSearch failed: %v", err)
+	}
+	var started, enriched []string
+	var finished *sourcingv1.Search
+	for finished == nil {
+		event, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Expected events until the search finished, got %v", err)
+		}
+		switch e := event.GetEvent().(type) {
+		case *sourcingv1.SearchEvent_StageStarted:
+			started = append(started, e.StageStarted.GetStage())
+		case *sourcingv1.SearchEvent_CandidateEnriched:
+			enriched = append(enriched, e.CandidateEnriched.GetUsername())
+		case *sourcingv1.SearchEvent_StageFailed:
+			t.Errorf("Unexpected failure of stage %s: %s", e.StageFailed.GetStage(), e.StageFailed.GetError())
+		case *sourcingv1.SearchEvent_Finished:
+			finished = e.Finished
+		}
+	}
+	wantStages := []string{agent.StageRequirements, agent.StageStrategy, agent.StageEnrichment, agent.StageRanking}
+	if len(started) != len(wantStages) {
+		t.Errorf("Expected the stages %v started, got %v", wantStages, started)
+	}
+	for i := range min(len(started), len(wantStages)) {
+		if started[i] != wantStages[i] {
+			t.Errorf("Expected the stages %v started, got %v", wantStages, started)
+			break
+		}
+	}
+	if len(enriched) != 1 || enriched[0] != "gopher_lima" {
+		t.Errorf("Expected gopher_lima enriched, got %v", enriched)
+	}
+	if finished.GetState() != sourcingv1.SearchState_SEARCH_STATE_COMPLETED {
+		t.Fatalf("Expected the search completed, got %v: %s", finished.GetState(), finished.GetError())
+	}
+	if _, err := stream.Recv(); err == nil {
+		t.Error("Expected the stream to end after the finished search")
+	}
+
+	result, err := client.GetResult(ctx, &sourcingv1.GetResultRequest{SearchId: search.GetSearchId()})
+	if err != nil {
+		t.Fatalf("GetResult failed: %v", err)
+	}
+	top := result.GetResult().GetTopCandidates()
+	if len(top) != 1 || top[0].GetUsername() != "gopher_lima" || top[0].GetRank() != 1 {
+		t.Errorf("Expected gopher_lima ranked first, got %v", top)
+	}
+	if url := top[0].GetGithubUrl(); url != "https://github.com/gopher_lima" {
+		t.Errorf("Expected the enriched profile URL, got %q", url)
+	}
+}
+
+func TestGRPCErrors(t *testing.T) {
+	client := newSourcingClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := client.StartSearch(ctx, &sourcingv1.StartSearchRequest{Query: "  "})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an empty query, got %v", err)
+	}
+	_, err = client.StartSearch(ctx, &sourcingv1.StartSearchRequest{Query: "Go developers", Lang: "klingon"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for an unknown language, got %v", err)
+	}
+	_, err = client.GetResult(ctx, &sourcingv1.GetResultRequest{SearchId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for an unknown search, got %v", err)
+	}
+}
+
+func TestGRPCToken(t *testing.T) {
+	client := newSourcingClient(t, grpcTokenOptions("s3cret")...)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	_, err := client.GetResult(ctx, &sourcingv1.GetResultRequest{SearchId: "missing"})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated without a token, got %v", err)
+	}
+	stream, err := client.WatchSearch(ctx, &sourcingv1.WatchSearchRequest{SearchId: "missing"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected Unauthenticated for a stream without a token, got %v", err)
+	}
+
+	authorized := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer s3cret")
+	_, err = client.GetResult(authorized, &sourcingv1.GetResultRequest{SearchId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound with the token, got %v", err)
+	}
+}
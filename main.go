@@ -1,116 +1,381 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
-	"net/http"
+	"io"
+	"log/slog"
 	"os"
-	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
-	"github.com/luillyfe/sourcing-agent/pkg/agent"
-	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/anthropic"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
 	"github.com/luillyfe/sourcing-agent/pkg/observability"
 	"github.com/luillyfe/sourcing-agent/pkg/vertexai"
 )
 
 func main() {
 	// Load environment variables
-	if err := godotenv.Load(); err != nil {
-		fmt.Println("Warning: .env file not found, using system environment variables")
-	}
-
-	// Get API keys from environment
-	projectID := os.Getenv("VERTEX_PROJECT_ID")
-	if projectID == "" {
-		fmt.Println("Error: VERTEX_PROJECT_ID environment variable is not set")
-		fmt.Println("Please create a .env file with your Project ID or set it as an environment variable")
-		os.Exit(1)
-	}
-
-	region := os.Getenv("VERTEX_REGION")
-	if region == "" {
-		fmt.Println("Error: VERTEX_REGION environment variable is not set")
-		fmt.Println("Please create a .env file with your Region or set it as an environment variable")
-		os.Exit(1)
-	}
-
-	githubToken := os.Getenv("GITHUB_TOKEN")
-	if githubToken == "" {
-		fmt.Println("Error: GITHUB_TOKEN environment variable is not set")
-		fmt.Println("Please create a .env file with your GitHub token or set it as an environment variable")
-		os.Exit(1)
-	}
-
-	// Check for command line arguments
-	if len(os.Args) < 2 {
-		fmt.Println("=== GitHub Developer Sourcing Agent ===")
-		fmt.Println()
-		fmt.Println("Usage:")
-		fmt.Println("  go run main.go \"<your query>\"")
-		fmt.Println()
-		fmt.Println("Examples:")
-		fmt.Println("  go run main.go \"Find Go developers in Lima\"")
-		fmt.Println("  go run main.go \"Looking for Python engineers in Peru\"")
-		fmt.Println("  go run main.go \"Need React developers with TypeScript experience\"")
-		fmt.Println()
+	envErr := godotenv.Load()
+
+	command := ""
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+	}
+
+	// Diagnostics go to stderr through slog so stdout carries only the result
+	jsonLogs = strings.EqualFold(os.Getenv("LOG_FORMAT"), "json")
+	logLevel.Set(defaultLogLevel(os.Getenv("LOG_LEVEL"), command))
+	logger := newLogger(os.Getenv("LOG_FORMAT"))
+	slog.SetDefault(logger)
+	if envErr != nil && jsonLogs {
+		logger.Warn(".env file not found, using system environment variables")
+	} else if envErr != nil {
+		// On stderr, so subcommands printing JSON can be piped into each other
+		fmt.Fprintln(os.Stderr, "Warning: .env file not found, using system environment variables")
+	}
+
+	if command == "" {
+		usage()
 		os.Exit(0)
 	}
+	switch command {
+	case "help", "-h", "-help", "--help":
+		usage()
+	case "completion":
+		runCompletion(os.Args[2:])
+	default:
+		if c, ok := lookupCommand(command); ok {
+			c.run(os.Args[2:], logger)
+			break
+		}
+		// A bare query, with or without search flags, is shorthand for search,
+		// as before subcommands existed
+		runSearch(os.Args[1:], logger)
+	}
+	os.Exit(exitStatus)
+}
+
+// command is a subcommand and its entry point
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, logger *slog.Logger)
+}
 
-	// Get query from command line
-	query := strings.Join(os.Args[1:], " ")
+// commands lists the subcommands, for dispatch and shell completion.
+// completion itself is dispatched by main, as it lists the others.
+var commands = []command{
+	{"search", "Run the whole pipeline for a query", runSearch},
+	{"enrich", "Search and enrich candidates for a saved strategy", runEnrich},
+	{"rank", "Rank previously enriched candidates", runRank},
+	{"resume", "Continue a failed or interrupted search from its checkpoint", runResume},
+	{"profile", "Assess a GitHub user against a query", runProfile},
+	{"batch", "Run every query of a file", runBatch},
+	{"searches", "Save, list and run named searches", runSearches},
+	{"runs", "List and show the runs stored in DATABASE_URL", runRuns},
+	{"candidates", "Track the candidates of the stored runs and manage them as a talent pool", runCandidates},
+	{"similar", "Find the indexed candidates of earlier runs most similar to a job description", runSimilar},
+	{"export", "Export the top candidates of a result to an ATS", runExport},
+	{"outreach", "Draft an outreach message per top candidate from a template", runOutreach},
+	{"crawl", "Cache the developer profiles of target languages and regions ahead of searches", runCrawl},
+	{"watch", "Rerun a search on a schedule, reporting only new candidates", runWatch},
+	{"serve", "Serve searches over HTTP", runServe},
+	{"doctor", "Check configuration, credentials and connectivity", runDoctor},
+	{"version", "Print the build, configured models and provider SDKs", runVersion},
+}
 
-	fmt.Println("=== GitHub Developer Sourcing Agent ===")
-	fmt.Printf("Query: %s\n\n", query)
-	fmt.Println("Searching...")
-	fmt.Println()
+// lookupCommand returns the subcommand called name
+func lookupCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
 
-	// Initialize clients
-	// 1. GitHub Client with Observability
-	countingTransport := &observability.CountingTransport{Transport: http.DefaultTransport}
-	httpClient := &http.Client{
-		Timeout:   30 * time.Second,
-		Transport: countingTransport,
+// newFlagSet returns the flag set of a subcommand, exiting on invalid flags.
+// While collectFlags is set, it instead records the flag set for shell
+// completion and panics on -h, before the command does anything.
+func newFlagSet(name string) *flag.FlagSet {
+	if collectFlags != nil {
+		fs := flag.NewFlagSet(name, flag.PanicOnError)
+		fs.SetOutput(io.Discard)
+		*collectFlags = fs
+		return fs
 	}
+	return flag.NewFlagSet(name, flag.ExitOnError)
+}
 
-	githubClient := github.NewClient(githubToken)
-	githubClient.HTTPClient = httpClient
+// usage prints the subcommands
+func usage() {
+	fmt.Println(`=== GitHub Developer Sourcing Agent ===
 
-	ctx := context.Background()
-	vertexClient, err := vertexai.NewClient(ctx, projectID, region)
-	if err != nil {
-		fmt.Printf("Error initializing Vertex AI client: %v\n", err)
-		os.Exit(1)
+Usage:
+  sourcing-agent <command> [flags] [arguments]
+
+Commands:
+  search "<query>"     Run the whole pipeline for a query; "-" reads the query,
+                       e.g. a job description, from stdin
+  enrich               Search and enrich candidates for a saved strategy
+  rank                 Rank previously enriched candidates
+  resume <run-id>      Continue a failed or interrupted search from its checkpoint
+  profile <username> ["<query>"]
+                       Assess a GitHub user against a query, or without one
+                       show how they are enriched, without the LLM
+  batch <file>         Run every query of a file, writing one result per query
+  searches save|list|run|delete
+                       Save a query with its settings under a name, and rerun
+                       it with: sourcing-agent searches run <name>
+  runs list|show       List the runs stored when DATABASE_URL is set, and print
+                       the result of one: sourcing-agent runs show <run-id>
+  candidates list|show|contact|status|tag|untag|note|feedback|scoring
+                       Show which stored runs surfaced a candidate with their
+                       scores, and manage them as a talent pool: contacts,
+                       pipeline status, tags and notes, flagged in later results,
+                       and feedback adjusting the ranking of each role family
+  similar "<query>"    Find the candidates of earlier runs, indexed when
+                       VECTOR_STORE_URL is set, most similar to a job
+                       description, without searching again; "-" reads stdin
+  export -to lever     Export the top candidates of a result, read from stdin or
+                       a stored run (-run <run-id>), to an ATS
+  outreach             Draft an outreach message per top candidate of a result
+                       from a template, optionally polished by the LLM (-polish)
+  watch "<query>"      Rerun a search on a schedule, e.g. -interval 24h, reporting
+                       only candidates no earlier run surfaced
+  crawl -languages go  Cache the developer profiles of target languages and
+                       regions (-locations) in DATABASE_URL within a request
+                       budget, so searches are served from the cache first
+  serve                Serve searches over HTTP
+  doctor               Check configuration, credentials and connectivity
+  version              Print the version, commit, build date, configured models
+                       and provider SDK versions
+  completion bash|zsh|fish
+                       Print a shell completion script
+
+Run 'sourcing-agent <command> -h' for a command's flags.
+
+Exit codes:
+  0  Success
+  1  Failure not covered below
+  2  Invalid flags or arguments
+  3  Missing or invalid settings or credentials, or a failed doctor check
+  4  Query too vague to search for; the clarification question is printed
+  5  LLM provider or source platform rate limit or quota exhausted
+  6  Partial result: candidates printed unranked after a ranking failure,
+     some batch queries failed or were skipped, some candidates were
+     not exported, or some outreach messages were not polished
+
+Examples:
+  sourcing-agent search "Find Go developers in Lima"
+  sourcing-agent search -report report.html "Looking for Python engineers in Peru"
+  sourcing-agent search -llm anthropic "Find Go developers in Lima"
+  sourcing-agent search - < job-description.txt
+  sourcing-agent search -out result.json "Find Go developers in Lima"
+  sourcing-agent enrich -in result.json | sourcing-agent rank
+  sourcing-agent batch queries.txt -out results/ -max-cost 5
+  sourcing-agent watch -interval 24h "Find Go developers in Lima"
+  sourcing-agent similar - < job-description.txt`)
+}
+
+// jsonLogs is set when LOG_FORMAT=json. Every diagnostic is then a JSON line
+// on stderr and stdout carries only the result, so output can be piped to jq.
+var jsonLogs bool
+
+// quiet is set by -q. Stdout then carries only the result and stderr only
+// errors, for scripting.
+var quiet bool
+
+// logLevel is the level of the stderr logger, from LOG_LEVEL unless -q, -v
+// or -debug is given
+var logLevel = new(slog.LevelVar)
+
+// defaultLogLevel returns level if it names one. Otherwise the CLI only
+// reports warnings, while servers and JSON logs, which carry the run summary,
+// log at info.
+func defaultLogLevel(level, command string) slog.Level {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err == nil {
+		return lvl
+	}
+	if jsonLogs || command == "serve" {
+		return slog.LevelInfo
 	}
-	defer vertexClient.Close()
+	return slog.LevelWarn
+}
+
+// verbosityFlags adds -q, -v and -debug to fs, overriding LOG_LEVEL; the
+// last one given wins
+func verbosityFlags(fs *flag.FlagSet) {
+	fs.BoolFunc("q", "quiet: print only the result on stdout and only errors on stderr", func(string) error {
+		quiet = true
+		logLevel.Set(slog.LevelError)
+		return nil
+	})
+	fs.BoolFunc("v", "verbose: also log stage progress and timings", func(string) error {
+		quiet = false
+		logLevel.Set(slog.LevelInfo)
+		return nil
+	})
+	fs.BoolFunc("debug", "also log requirements, strategy and GitHub request URLs", func(string) error {
+		quiet = false
+		logLevel.Set(slog.LevelDebug)
+		return nil
+	})
+}
 
-	// 2. LLM Client with Observability
-	countingLLMClient := &observability.CountingLLMClient{Wrapped: vertexClient}
+// progressFlag adds the -progress flag, on by default when a person is
+// watching stderr
+func progressFlag(fs *flag.FlagSet) *bool {
+	return fs.Bool("progress", isTerminal(os.Stderr), "draw enrichment progress (candidates enriched and the current username) on stderr")
+}
 
-	// Run the sourcing agent
-	startTime := time.Now()
-	result, err := agent.RunStage2(countingLLMClient, githubClient, query)
+// isTerminal reports whether f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressReporter draws enrichment progress on stderr if enabled, staying
+// silent in quiet mode and JSON log mode
+func progressReporter(enabled bool) observability.ProgressReporter {
+	if enabled && !jsonLogs && !quiet {
+		return observability.NewTerminalProgress(os.Stderr)
+	}
+	return observability.NoopProgress{}
+}
+
+// newLogger builds the stderr logger at logLevel from LOG_FORMAT (text or
+// json; default text)
+func newLogger(format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: logLevel}
+	if strings.EqualFold(format, "json") {
+		return slog.New(slog.NewJSONHandler(os.Stderr, opts))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, opts))
+}
+
+// envInt reads an integer environment variable, returning 0 when unset or invalid
+func envInt(key string) int {
+	v, err := strconv.Atoi(os.Getenv(key))
 	if err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
-	}
-	duration := time.Since(startTime)
-
-	// Display result
-	resultJSON, _ := json.MarshalIndent(result, "", "  ")
-	fmt.Println(string(resultJSON))
-	fmt.Printf("\nTotal execution time: %.2f seconds\n", duration.Seconds())
-	fmt.Printf("Total LLM calls: %d\n", countingLLMClient.Count)
-	fmt.Printf("Total GitHub API calls: %d\n", countingTransport.Count)
-
-	// Memory usage
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-	fmt.Printf("Memory usage: Alloc = %v MiB, TotalAlloc = %v MiB, Sys = %v MiB, NumGC = %v\n",
-		bToMb(m.Alloc), bToMb(m.TotalAlloc), bToMb(m.Sys), m.NumGC)
+		return 0
+	}
+	return v
+}
+
+// envDuration parses key as a duration, e.g. 30s, zero when unset
+func envDuration(key string) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return d, nil
+}
+
+// retryConfig returns the default backoff capped at maxAttempts, or a
+// disabled config when maxAttempts is not set
+func retryConfig(maxAttempts int) llm.RetryConfig {
+	if maxAttempts <= 1 {
+		return llm.RetryConfig{}
+	}
+	config := llm.DefaultRetryConfig()
+	config.MaxAttempts = maxAttempts
+	return config
+}
+
+// anthropicPlatform reads where Claude is served from. Claude is enabled with
+// an Anthropic API key, or through ANTHROPIC_PLATFORM=vertex (reusing the
+// Google Cloud project and credentials) or ANTHROPIC_PLATFORM=bedrock (using
+// the standard AWS environment variables or a Bedrock API key).
+func anthropicPlatform() (anthropic.Config, bool) {
+	switch platform := os.Getenv("ANTHROPIC_PLATFORM"); platform {
+	case anthropic.PlatformVertex:
+		region := os.Getenv("ANTHROPIC_REGION")
+		if region == "" {
+			region = os.Getenv("VERTEX_REGION")
+		}
+		return anthropic.Config{
+			Platform:  platform,
+			ProjectID: os.Getenv("VERTEX_PROJECT_ID"),
+			Region:    region,
+		}, true
+	case anthropic.PlatformBedrock:
+		region := os.Getenv("ANTHROPIC_REGION")
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		return anthropic.Config{
+			Platform: platform,
+			APIKey:   os.Getenv("AWS_BEARER_TOKEN_BEDROCK"),
+			Region:   region,
+			AWS: anthropic.AWSCredentials{
+				AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			},
+		}, true
+	default:
+		apiKey := os.Getenv("ANTHROPIC_API_KEY")
+		return anthropic.Config{APIKey: apiKey}, apiKey != ""
+	}
+}
+
+// vertexContextCache reads the Gemini context caching settings
+func vertexContextCache() (vertexai.ContextCacheConfig, error) {
+	ttl, err := envDuration("VERTEX_CONTEXT_CACHE_TTL")
+	return vertexai.ContextCacheConfig{
+		Enabled: os.Getenv("VERTEX_CONTEXT_CACHE") == "true",
+		TTL:     ttl,
+	}, err
+}
+
+// envMap parses a comma-separated list of key=value pairs, e.g. "ranking=gemini-2.5-pro,strategy=gemini-2.5-flash"
+func envMap(key string) map[string]string {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(os.Getenv(key), ",") {
+		k, v, ok := strings.Cut(pair, "=")
+		if ok && strings.TrimSpace(k) != "" {
+			m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return m
+}
+
+// envIntMap parses a comma-separated list of key=number pairs, e.g.
+// "ranking=8192", skipping entries that are not integers
+func envIntMap(key string) map[string]int {
+	m := make(map[string]int)
+	for k, v := range envMap(key) {
+		if n, err := strconv.Atoi(v); err == nil {
+			m[k] = n
+		}
+	}
+	return m
+}
+
+// envList parses a comma-separated list, e.g. "us-east4,europe-west4"
+func envList(key string) []string {
+	return splitList(os.Getenv(key))
+}
+
+// splitList parses a comma-separated list, skipping empty items
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
 }
 
 func bToMb(b uint64) uint64 {
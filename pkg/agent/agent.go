@@ -1,90 +1,64 @@
 package agent
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/luillyfe/sourcing-agent/pkg/github"
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"github.com/luillyfe/sourcing-agent/pkg/prompts"
 )
 
 // Run executes the sourcing agent with a user query
-func Run(client llm.Client, githubClient *github.Client, query string) (string, error) {
-	// System prompt
-	systemPrompt := `You are a developer sourcing assistant. Your job is to search GitHub for developers matching hiring requirements.
-
-You have ONE tool: search_github_developers
-
-Process:
-1. Extract: programming language, location, and relevant keywords from the query
-2. Call search_github_developers with appropriate parameters
-3. Present the results in a clear, readable format
-
-Keep it simple. One search, one response.`
+func Run(ctx context.Context, client llm.Client, source SourceProvider, query string) (string, error) {
+	// Tools
+	tools := []llm.Tool{getToolDefinition()}
 
-	// Initial messages
-	messages := []llm.Message{
-		{
-			Role:    "system",
-			Content: systemPrompt,
-		},
-		{
-			Role:    "user",
-			Content: fmt.Sprintf("User query: %s", query),
-		},
+	// System prompt
+	systemPrompt, err := prompts.Render(prompts.SearchAgent, prompts.Data{Tools: tools})
+	if err != nil {
+		return "", err
 	}
 
-	// Tools
-	tools := []llm.Tool{getToolDefinition()}
+	conversation := llm.NewConversation(systemPrompt)
+	conversation.AddUser(fmt.Sprintf("User query: %s", query))
 
 	// Initial search
-	fmt.Println("Analyzing query and searching GitHub...")
-	resp, err := client.CallAPI(messages, tools)
+	logger := slog.Default()
+	logger.Info("Analyzing query and searching GitHub")
+	resp, err := client.CallAPI(ctx, conversation.Messages(), tools)
 	if err != nil {
 		return "", fmt.Errorf("failed to call LLM API: %w", err)
 	}
 
 	// Check if LLM wants to use a tool
 	if resp.StopReason == "tool_use" {
-		// Find tool use blocks
 		var toolResults []llm.ContentBlock
+		for _, block := range llm.ToolUses(resp) {
+			logger.Info("Agent wants to use tool", "tool", block.Name)
 
-		for _, block := range resp.Content {
-			if block.Type == "tool_use" {
-				fmt.Printf("Agent wants to use tool: %s\n", block.Name)
-
-				// Execute tool
-				result, err := executeTool(githubClient, block.Name, block.Input)
-				if err != nil {
-					return "", fmt.Errorf("failed to execute tool %s: %w", block.Name, err)
-				}
-
-				// Add tool result
-				toolResults = append(toolResults, llm.ContentBlock{
-					Type:      "tool_result",
-					ToolUseID: block.ID,
-					Content:   result,
-				})
+			// Execute tool
+			result, err := executeTool(ctx, source, block.Name, block.Input)
+			if err != nil {
+				return "", fmt.Errorf("failed to execute tool %s: %w", block.Name, err)
 			}
+			toolResults = append(toolResults, llm.ToolResult(block.ID, result))
 		}
 
-		// Append assistant's tool use to messages
-		messages = append(messages, llm.Message{
-			Role:    "assistant",
-			Content: resp.Content,
-		})
-
-		// Append tool results to messages
-		messages = append(messages, llm.Message{
-			Role:    "user",
-			Content: toolResults,
-		})
+		// Send the assistant's tool use back along with the tool results
+		conversation.AddResponse(resp).AddToolResults(toolResults...)
 
 		// Call LLM again with tool results
-		fmt.Println("Processing search results...")
-		resp, err = client.CallAPI(messages, tools)
+		logger.Info("Processing search results")
+		resp, err = client.CallAPI(ctx, conversation.Messages(), tools)
 		if err != nil {
 			return "", fmt.Errorf("failed to call LLM API with tool results: %w", err)
 		}
@@ -102,7 +76,7 @@ Keep it simple. One search, one response.`
 }
 
 // executeTool executes a tool call and returns the result
-func executeTool(githubClient *github.Client, toolName string, toolInput interface{}) (string, error) {
+func executeTool(ctx context.Context, source SourceProvider, toolName string, toolInput interface{}) (string, error) {
 	if toolName != "search_github_developers" {
 		return "", fmt.Errorf("unknown tool: %s", toolName)
 	}
@@ -119,7 +93,7 @@ func executeTool(githubClient *github.Client, toolName string, toolInput interfa
 	}
 
 	// Execute the search
-	result, err := githubClient.SearchDevelopers(input)
+	result, err := source.SearchDevelopers(ctx, input)
 	if err != nil {
 		return "", fmt.Errorf("failed to search GitHub developers: %w", err)
 	}
@@ -169,80 +143,365 @@ func getToolDefinition() llm.Tool {
 	}
 }
 
-// RunStage2 executes the multi-prompt sourcing agent (Stage 2)
-func RunStage2(client llm.Client, githubClient *github.Client, query string) (*FinalResult, error) {
-	startTime := time.Now()
+// Stage names used to label LLM calls for per-stage usage accounting
+const (
+	StageRequirements = "requirements"
+	StageStrategy     = "strategy"
+	StageRanking      = "ranking"
+	// StageEnrichment is programmatic and makes no LLM calls; it labels its timing only
+	StageEnrichment = "enrichment"
+)
+
+// ErrUnclearRequest is returned, with the model's clarification question,
+// when a query is too vague to search for
+var ErrUnclearRequest = errors.New("request unclear")
+
+// AgentConfig configures a pipeline run
+type AgentConfig struct {
+	// RunID correlates the run's logs, recordings and result. Generated when empty.
+	RunID string
+	// Logger receives progress and diagnostics. Defaults to slog.Default(),
+	// which writes to stderr so stdout stays reserved for the result.
+	Logger *slog.Logger
+	// Events receives the run's lifecycle events, e.g. for metrics or a
+	// progress UI. Events are always logged to Logger.
+	Events *observability.EventBus
+	// Progress shows the candidate enrichment loop. Defaults to no progress display.
+	Progress observability.ProgressReporter
+	// FailureDumpDir, if set, receives a FailureSnapshot directory for every
+	// failed stage, including a ranking failure the run recovers from
+	FailureDumpDir string
+	// CheckpointDir, if set, receives a Checkpoint of the run after every
+	// completed stage, for ResumeStage2
+	CheckpointDir string
+	// Store, if set, persists the run after every completed stage and once
+	// it finished, so it can be queried later. A failure to store it is
+	// logged and leaves the run going.
+	Store RunStore
+	// TargetCount is the number of ranked candidates to present. Zero leaves
+	// it to the model, or DefaultFallbackCount for unranked results.
+	TargetCount int
+	// MaxSearchResults caps the developers each GitHub search returns for
+	// enrichment. Defaults to DefaultMaxSearchResults.
+	MaxSearchResults int
+	// RelevanceThreshold is the score (0-1) a repository must exceed to count
+	// as relevant to the role; 0 counts every repository scoring at all.
+	// Defaults to DefaultRelevanceThreshold when nil.
+	RelevanceThreshold *float64
+	// Timeout bounds the whole run. A ranking cut short falls back to
+	// unranked candidates like any other ranking failure. Zero for no limit.
+	Timeout time.Duration
+	// MaxCostUSD and MaxLLMCalls stop the run's LLM calls, with
+	// llm.ErrBudgetExceeded, once its calls have cost or numbered that much.
+	// Zero for no limit.
+	MaxCostUSD  float64
+	MaxLLMCalls int
+	// Language is the code, from Languages, of the language the ranking
+	// writes its reasoning, qualifications, concerns and summary in, e.g. es
+	// or pt-BR. Usernames, URLs, project names and scores are unchanged.
+	// Empty for English.
+	Language string
+	// Exclude names developers never to present, e.g. employees or people
+	// already contacted: usernames, and organizations as org:name, matched
+	// against the company on a developer's GitHub profile
+	Exclude []string
+	// Source names the platform the run sources developers from, e.g.
+	// gitlab, or several comma-separated, so a resumed run sources from them
+	// again. Empty means GitHub.
+	Source string
+	// Enrichers add signals from other platforms to each enriched
+	// candidate, e.g. their Stack Overflow reputation. None by default.
+	Enrichers []Enricher
+	// MergeThreshold is the confidence (0-1) at which profiles found on
+	// several source platforms, with a MultiSource, are merged as one
+	// person. Defaults to DefaultMergeThreshold.
+	MergeThreshold float64
+}
+
+// Defaults for the AgentConfig search limits
+const (
+	DefaultMaxSearchResults   = 15 // Aim for 15-20 as per spec
+	DefaultRelevanceThreshold = 0.3
+	DefaultFallbackCount      = 10
+)
+
+// Validate reports an unknown Language or an invalid Exclude entry, which
+// fail a run before it starts
+func (c AgentConfig) Validate() error {
+	if _, err := LanguageName(c.Language); err != nil {
+		return err
+	}
+	_, err := newExclusions(c.Exclude)
+	return err
+}
+
+// language returns the name of Language for the ranking prompt; empty for
+// English. Validate reports an unknown code.
+func (c AgentConfig) language() string {
+	name, _ := LanguageName(c.Language)
+	return name
+}
+
+// exclusions returns the developers Exclude names. Validate reports an
+// invalid entry.
+func (c AgentConfig) exclusions() exclusions {
+	ex, _ := newExclusions(c.Exclude)
+	return ex
+}
+
+func (c AgentConfig) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+// start returns the run ID, generated if unset, and the run's logger and
+// event bus, both tagged with it
+func (c AgentConfig) start() (string, *slog.Logger, *observability.EventBus) {
+	runID := c.RunID
+	if runID == "" {
+		runID = NewRunID()
+	}
+	logger := c.logger().With("run_id", runID)
+	events := observability.NewEventBus(observability.LogSubscriber(logger))
+	if c.Events != nil {
+		events.Subscribe(c.Events.Publish)
+	}
+	return runID, logger, events
+}
+
+func (c AgentConfig) progress() observability.ProgressReporter {
+	if c.Progress == nil {
+		return observability.NoopProgress{}
+	}
+	return c.Progress
+}
+
+func (c AgentConfig) maxSearchResults() int {
+	if c.MaxSearchResults <= 0 {
+		return DefaultMaxSearchResults
+	}
+	return c.MaxSearchResults
+}
+
+func (c AgentConfig) mergeThreshold() float64 {
+	if c.MergeThreshold <= 0 {
+		return DefaultMergeThreshold
+	}
+	return c.MergeThreshold
+}
+
+func (c AgentConfig) relevanceThreshold() float64 {
+	if c.RelevanceThreshold == nil {
+		return DefaultRelevanceThreshold
+	}
+	return *c.RelevanceThreshold
+}
+
+// limit applies the run's timeout to ctx and its budgets to client, which is
+// also wrapped in retries unless the caller already configured them
+func (c AgentConfig) limit(ctx context.Context, client llm.Client) (context.Context, context.CancelFunc, llm.Client) {
+	// Retry transient provider failures unless the caller already configured retries
+	if _, ok := client.(*llm.RetryClient); !ok {
+		client = llm.WithRetry(client, llm.DefaultRetryConfig())
+	}
+	if c.MaxCostUSD > 0 || c.MaxLLMCalls > 0 {
+		client = llm.WithBudget(client, c.MaxCostUSD, c.MaxLLMCalls)
+	}
+	if c.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+		return ctx, cancel, client
+	}
+	return ctx, func() {}, client
+}
+
+// fallbackCount is the number of unranked candidates returned when ranking fails
+func (c AgentConfig) fallbackCount() int {
+	if c.TargetCount <= 0 {
+		return DefaultFallbackCount
+	}
+	return c.TargetCount
+}
+
+// NewRunID returns a run ID that sorts by start time and is unique across
+// concurrent runs, e.g. 20251120T150000-9f86d081e4b3a2c7
+func NewRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// RunStage2 executes the multi-prompt sourcing agent (Stage 2) with the default configuration
+func RunStage2(ctx context.Context, client llm.Client, source SourceProvider, query string) (*FinalResult, error) {
+	return RunStage2WithConfig(ctx, client, source, query, AgentConfig{})
+}
+
+// RunStage2WithConfig executes the multi-prompt sourcing agent (Stage 2)
+func RunStage2WithConfig(ctx context.Context, client llm.Client, source SourceProvider, query string, config AgentConfig) (*FinalResult, error) {
+	return runPipeline(ctx, client, source, query, config, &Checkpoint{})
+}
+
+// ResumeStage2 continues the run saved in checkpoint after its last completed
+// stage, with the run's ID and search limits. A checkpoint of a finished run
+// resumes at ranking.
+func ResumeStage2(ctx context.Context, client llm.Client, source SourceProvider, checkpoint *Checkpoint, config AgentConfig) (*FinalResult, error) {
+	config = checkpoint.Apply(config)
+	config.logger().Info("Resuming run", "run_id", checkpoint.RunID, "completed_stage", checkpoint.Stage)
+	resumed := *checkpoint
+	return runPipeline(ctx, client, source, checkpoint.Query, config, &resumed)
+}
+
+// runPipeline runs the stages whose output checkpoint does not hold yet
+func runPipeline(ctx context.Context, client llm.Client, source SourceProvider, query string, config AgentConfig, checkpoint *Checkpoint) (*FinalResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	runID, logger, events := config.start()
+	timer := newRunTimer()
 	defer func() {
-		fmt.Printf("Total execution time: %v\n", time.Since(startTime))
+		logger.Info("Pipeline finished", "duration", time.Since(timer.start))
 	}()
 
-	var totalInputTokens, totalOutputTokens int
-
-	fmt.Println("Step 1: Analyzing requirements...")
-	stepStart := time.Now()
-	// Step 1: Analyze Requirements
-	requirements, usage, err := analyzeRequirements(client, query)
-	if err != nil {
-		return nil, fmt.Errorf("requirements analysis failed: %w", err)
+	ctx, cancel, client := config.limit(ctx, client)
+	defer cancel()
+	// Time external calls, retries included, for the latency breakdown
+	client = &timedClient{Wrapped: client, timer: timer}
+	source = timedSource(source, timer, runID)
+
+	requirements, strategy, enrichedCandidates := checkpoint.Requirements, checkpoint.Strategy, checkpoint.Candidates
+	started := time.Now().UTC()
+	snapshot := func(stage string) Checkpoint {
+		return Checkpoint{RunID: runID, Query: query, Stage: stage, Time: time.Now().UTC(),
+			TargetCount: config.TargetCount, MaxSearchResults: config.MaxSearchResults, RelevanceThreshold: config.RelevanceThreshold,
+			Language: config.Language, Exclude: config.Exclude, Source: config.Source,
+			Requirements: requirements, Strategy: strategy, Candidates: enrichedCandidates}
+	}
+	stageDone := func(stage string) {
+		if config.CheckpointDir == "" && config.Store == nil {
+			return
+		}
+		*checkpoint = snapshot(stage)
+		if config.CheckpointDir != "" {
+			if err := writeCheckpoint(config.CheckpointDir, *checkpoint); err != nil {
+				logger.Warn("Checkpoint not written", "stage", stage, "error", err)
+			}
+		}
+		config.saveRun(ctx, RunRecord{Checkpoint: *checkpoint, StartedAt: started})
 	}
-	fmt.Printf("Requirements analysis took %v\n", time.Since(stepStart))
-	if usage != nil {
-		fmt.Printf("  Usage: %d input, %d output tokens\n", usage.InputTokens, usage.OutputTokens)
-		totalInputTokens += usage.InputTokens
-		totalOutputTokens += usage.OutputTokens
+	stageFailed := func(stage string, err error) {
+		events.Publish(observability.StageFailed{Stage: stage, Err: err})
+		if config.FailureDumpDir == "" {
+			return
+		}
+		snapshot := FailureSnapshot{RunID: runID, Query: query, Stage: stage, Time: time.Now(),
+			Requirements: requirements, Strategy: strategy, Candidates: enrichedCandidates}
+		if path, dumpErr := writeFailureSnapshot(config.FailureDumpDir, snapshot, err); dumpErr != nil {
+			logger.Warn("Failure snapshot not written", "error", dumpErr)
+		} else {
+			logger.Warn("Failure snapshot written", "stage", stage, "path", path)
+		}
 	}
-	fmt.Printf("Requirements: %+v\n", requirements)
 
-	// Check for unclear requirements (Fail Fast)
-	if requirements.UnclearRequest {
-		return nil, fmt.Errorf("request unclear: %s", requirements.ClarificationQuestion)
+	var totalInputTokens, totalOutputTokens int
+	var totalCost float64
+	addUsage := func(stage string, usage *llm.Usage, started time.Time) {
+		timer.stage(stage, started)
+		attrs := []any{"stage", stage, "duration", time.Since(started)}
+		if usage != nil {
+			attrs = append(attrs, "input_tokens", usage.InputTokens, "output_tokens", usage.OutputTokens)
+			totalInputTokens += usage.InputTokens
+			totalOutputTokens += usage.OutputTokens
+			totalCost += usage.EstimatedCostUSD
+		}
+		logger.Info("Stage complete", attrs...)
 	}
 
-	fmt.Println("Step 2: Generating search strategy...")
-	stepStart = time.Now()
-	// Step 2: Generate Search Strategy
-	strategy, usage, err := generateSearchStrategy(client, requirements)
-	if err != nil {
-		return nil, fmt.Errorf("strategy generation failed: %w", err)
+	var usage *llm.Usage
+	var err error
+	var stepStart time.Time
+	if requirements == nil {
+		events.Publish(observability.StageStarted{Stage: StageRequirements})
+		stepStart = time.Now()
+		// Step 1: Analyze Requirements
+		requirements, usage, err = analyzeRequirements(ctx, client, query)
+		if err != nil {
+			stageFailed(StageRequirements, err)
+			return nil, fmt.Errorf("requirements analysis failed: %w", err)
+		}
+		addUsage(StageRequirements, usage, stepStart)
+		logger.Debug("Requirements analyzed", "requirements", requirements)
+
+		// Check for unclear requirements (Fail Fast)
+		if requirements.UnclearRequest {
+			return nil, fmt.Errorf("%w: %s", ErrUnclearRequest, requirements.ClarificationQuestion)
+		}
+		stageDone(StageRequirements)
 	}
-	fmt.Printf("Strategy generation took %v\n", time.Since(stepStart))
-	if usage != nil {
-		fmt.Printf("  Usage: %d input, %d output tokens\n", usage.InputTokens, usage.OutputTokens)
-		totalInputTokens += usage.InputTokens
-		totalOutputTokens += usage.OutputTokens
+
+	if strategy == nil {
+		events.Publish(observability.StageStarted{Stage: StageStrategy})
+		stepStart = time.Now()
+		// Step 2: Generate Search Strategy
+		strategy, usage, err = generateSearchStrategy(ctx, client, requirements)
+		if err != nil {
+			stageFailed(StageStrategy, err)
+			return nil, fmt.Errorf("strategy generation failed: %w", err)
+		}
+		addUsage(StageStrategy, usage, stepStart)
+		logger.Debug("Search strategy generated", "strategy", strategy)
+		stageDone(StageStrategy)
 	}
-	strategyJSON, _ := json.MarshalIndent(strategy, "", "  ")
-	fmt.Printf("Strategy: %s\n", string(strategyJSON))
 
-	fmt.Println("Step 3: Finding and enriching candidates...")
-	stepStart = time.Now()
-	// Step 3: Find and Enrich Candidates
-	// Note: Prompt 3 is currently programmatic (no LLM usage), so no tokens to track for now.
-	enrichedCandidates, err := findAndEnrichCandidates(client, githubClient, strategy, requirements)
-	if err != nil {
-		return nil, fmt.Errorf("candidate search failed: %w", err)
+	if enrichedCandidates == nil {
+		events.Publish(observability.StageStarted{Stage: StageEnrichment})
+		stepStart = time.Now()
+		// Step 3: Find and Enrich Candidates
+		// Note: Prompt 3 is currently programmatic (no LLM usage), so no tokens to track for now.
+		enrichedCandidates, err = findAndEnrichCandidates(ctx, client, source, strategy, requirements, events, config)
+		if err != nil {
+			stageFailed(StageEnrichment, err)
+			return nil, fmt.Errorf("candidate search failed: %w", err)
+		}
+		timer.stage(StageEnrichment, stepStart)
+		logger.Info("Stage complete", "stage", StageEnrichment, "duration", time.Since(stepStart),
+			"candidates_found", enrichedCandidates.SearchMetadata.TotalProfilesFound,
+			"candidates_analyzed", enrichedCandidates.SearchMetadata.ProfilesAnalyzed)
+		stageDone(StageEnrichment)
 	}
-	fmt.Printf("Found %d candidates, analyzed %d\n", enrichedCandidates.SearchMetadata.TotalProfilesFound, enrichedCandidates.SearchMetadata.ProfilesAnalyzed)
-	fmt.Printf("Candidate search and enrichment took %v\n", time.Since(stepStart))
 
-	fmt.Println("Step 4: Ranking and presenting...")
+	// Candidates from a checkpoint may predate the exclusions
+	enrichedCandidates = config.exclusions().filter(enrichedCandidates)
+	events.Publish(observability.StageStarted{Stage: StageRanking})
 	stepStart = time.Now()
 	// Step 4: Rank and Present
-	finalResult, usage, err := rankAndPresent(client, enrichedCandidates, requirements)
+	finalResult, usage, err := rankAndPresent(ctx, client, enrichedCandidates, requirements, rankingOptions{
+		scoring:     config.scoring(ctx, requirements),
+		targetCount: config.TargetCount,
+		language:    config.language(),
+		logger:      logger,
+		events:      events,
+	})
 	if err != nil {
-		fmt.Printf("Ranking step failed (%v), falling back to unranked results.\n", err)
-		finalResult = createFallbackResult(enrichedCandidates)
-	} else if usage != nil {
-		fmt.Printf("  Usage: %d input, %d output tokens\n", usage.InputTokens, usage.OutputTokens)
-		totalInputTokens += usage.InputTokens
-		totalOutputTokens += usage.OutputTokens
+		stageFailed(StageRanking, err)
+		events.Publish(observability.FallbackUsed{Stage: StageRanking, Reason: "ranking failed, returning unranked results", Err: err})
+		finalResult = createFallbackResult(enrichedCandidates, config.fallbackCount())
+		usage = nil
 	}
-	fmt.Printf("Ranking took %v\n", time.Since(stepStart))
-
-	fmt.Println("--------------------------------------------------")
-	fmt.Printf("Total Token Usage: %d input + %d output = %d total\n",
-		totalInputTokens, totalOutputTokens, totalInputTokens+totalOutputTokens)
-	fmt.Println("--------------------------------------------------")
-
+	addUsage(StageRanking, usage, stepStart)
+
+	logger.Info("Token usage",
+		"input_tokens", totalInputTokens,
+		"output_tokens", totalOutputTokens,
+		"total_tokens", totalInputTokens+totalOutputTokens,
+		"estimated_cost_usd", totalCost)
+
+	finalResult.RunID = runID
+	finalResult.Timings = timer.timings()
+	finalResult.Requirements = requirements
+	finalResult.Strategy = strategy
+	finalResult.SearchMetadata = &enrichedCandidates.SearchMetadata
+	config.flagSeenBefore(ctx, finalResult)
+	config.saveRun(ctx, RunRecord{Checkpoint: snapshot(StageRanking), StartedAt: started, Result: finalResult})
 	return finalResult, nil
 }
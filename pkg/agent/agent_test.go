@@ -1,9 +1,11 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/luillyfe/sourcing-agent/pkg/github"
@@ -29,7 +31,7 @@ func TestExecuteTool(t *testing.T) {
 	}
 
 	t.Run("UnknownTool", func(t *testing.T) {
-		_, err := executeTool(client, "unknown_tool", map[string]interface{}{})
+		_, err := executeTool(context.Background(), client, "unknown_tool", map[string]interface{}{})
 		if err == nil {
 			t.Error("Expected error for unknown tool")
 		}
@@ -44,9 +46,37 @@ func TestExecuteTool(t *testing.T) {
 			"language": "go",
 		}
 
-		_, err := executeTool(client, "search_github_developers", input)
+		_, err := executeTool(context.Background(), client, "search_github_developers", input)
 		if err != nil {
 			t.Errorf("Expected success, got error: %v", err)
 		}
 	})
+
+	t.Run("NoEmail", func(t *testing.T) {
+		result, err := executeTool(context.Background(), emailSource{}, "search_github_developers", map[string]interface{}{"language": "go"})
+		if err != nil {
+			t.Fatalf("Expected success, got error: %v", err)
+		}
+		if strings.Contains(result, "ana@example.com") || strings.Contains(result, `"email"`) {
+			t.Errorf("Expected the tool result sent to the model without emails, got %s", result)
+		}
+	})
+}
+
+// emailSource finds a developer with a public email
+type emailSource struct{ fakeSource }
+
+func (emailSource) SearchDevelopers(context.Context, github.ToolInput) (*github.SearchResult, error) {
+	return &github.SearchResult{Candidates: []github.Candidate{{Username: "ana", Email: "ana@example.com"}}}, nil
+}
+
+func TestRelevanceThreshold(t *testing.T) {
+	if got := (AgentConfig{}).relevanceThreshold(); got != DefaultRelevanceThreshold {
+		t.Errorf("Expected the default threshold when unset, got %v", got)
+	}
+	// Zero counts every repository scoring at all, rather than the default
+	zero := 0.0
+	if got := (AgentConfig{RelevanceThreshold: &zero}).relevanceThreshold(); got != 0 {
+		t.Errorf("Expected a zero threshold kept, got %v", got)
+	}
 }
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BatchQuery is one search of a batch run. The optional settings override
+// the batch-wide AgentConfig, e.g. a wider search for a hard-to-fill role.
+type BatchQuery struct {
+	// Name identifies the query in result file names and the batch index.
+	// Defaults to a slug of the query.
+	Name               string   `yaml:"name,omitempty" json:"name"`
+	Query              string   `yaml:"query" json:"query"`
+	TargetCount        int      `yaml:"target_count,omitempty" json:"target_count,omitempty"`
+	MaxCandidates      int      `yaml:"max_candidates,omitempty" json:"max_candidates,omitempty"`
+	RelevanceThreshold *float64 `yaml:"relevance_threshold,omitempty" json:"relevance_threshold,omitempty"`
+}
+
+// Apply returns config with the query's settings taking precedence
+func (q BatchQuery) Apply(config AgentConfig) AgentConfig {
+	if q.TargetCount > 0 {
+		config.TargetCount = q.TargetCount
+	}
+	if q.MaxCandidates > 0 {
+		config.MaxSearchResults = q.MaxCandidates
+	}
+	if q.RelevanceThreshold != nil {
+		config.RelevanceThreshold = q.RelevanceThreshold
+	}
+	return config
+}
+
+// ParseBatch reads the queries of a batch run: a YAML list of BatchQuery
+// entries when isYAML, otherwise one query per line, skipping blank lines and
+// # comments. Every query gets a unique Name.
+func ParseBatch(r io.Reader, isYAML bool) ([]BatchQuery, error) {
+	var queries []BatchQuery
+	if isYAML {
+		if err := yaml.NewDecoder(r).Decode(&queries); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse batch: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(r)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			queries = append(queries, BatchQuery{Query: line})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read batch: %w", err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i := range queries {
+		q := &queries[i]
+		q.Query = strings.TrimSpace(q.Query)
+		if q.Query == "" {
+			return nil, fmt.Errorf("batch entry %d has no query", i+1)
+		}
+		name := slugify(q.Name)
+		if name == "" {
+			name = slugify(q.Query)
+		}
+		// Numbered suffixes keep repeated names from overwriting each other's results
+		unique := name
+		for n := 2; seen[unique]; n++ {
+			unique = name + "-" + strconv.Itoa(n)
+		}
+		seen[unique] = true
+		q.Name = unique
+	}
+	return queries, nil
+}
+
+// maxSlugLength keeps result file names short
+const maxSlugLength = 48
+
+// slugify returns a lowercase, file-name-safe form of s
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= maxSlugLength {
+			break
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBatchLines(t *testing.T) {
+	queries, err := ParseBatch(strings.NewReader(`# Open roles
+Find Go developers in Lima
+
+Find Go developers in Lima
+Looking for Python/ML engineers in Perú
+`), false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	names := []string{"find-go-developers-in-lima", "find-go-developers-in-lima-2", "looking-for-python-ml-engineers-in-per"}
+	if len(queries) != len(names) {
+		t.Fatalf("Expected %d queries, got %+v", len(names), queries)
+	}
+	for i, name := range names {
+		if queries[i].Name != name {
+			t.Errorf("Expected name %q, got %q", name, queries[i].Name)
+		}
+	}
+}
+
+func TestParseBatchYAML(t *testing.T) {
+	queries, err := ParseBatch(strings.NewReader(`
+- name: Backend Lima
+  query: Find Go developers in Lima
+  target_count: 5
+- query: Find Rust developers
+  max_candidates: 50
+  relevance_threshold: 0.2
+`), true)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(queries) != 2 || queries[0].Name != "backend-lima" || queries[1].Name != "find-rust-developers" {
+		t.Fatalf("Unexpected queries %+v", queries)
+	}
+
+	config := queries[1].Apply(AgentConfig{TargetCount: 10, MaxSearchResults: 15})
+	if config.TargetCount != 10 || config.MaxSearchResults != 50 || config.RelevanceThreshold == nil || *config.RelevanceThreshold != 0.2 {
+		t.Errorf("Expected per-role settings over batch defaults, got %+v", config)
+	}
+
+	if _, err := ParseBatch(strings.NewReader("- name: empty\n"), true); err == nil {
+		t.Error("Expected an error for an entry without a query")
+	}
+}
@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+const (
+	// rankingTokenBudget is the maximum prompt size sent to the ranking stage
+	rankingTokenBudget = 32000
+	// minReposPerCandidate is how many relevant repositories are kept when trimming
+	minReposPerCandidate = 3
+)
+
+// buildRankingMessages builds the Prompt 4 conversation for the given candidates
+func buildRankingMessages(systemPrompt string, candidates *EnrichedCandidates, requirements *Requirements) []llm.Message {
+	input := map[string]interface{}{
+		"candidates":   candidates,
+		"requirements": requirements,
+	}
+	inputJSON, _ := json.Marshal(input)
+
+	return []llm.Message{
+		llm.SystemText(systemPrompt),
+		llm.UserText(fmt.Sprintf("Input Data: %s", string(inputJSON))),
+	}
+}
+
+// fitCandidatesToBudget returns candidates trimmed so the ranking prompt stays
+// within budget tokens. It first keeps only the most relevant repositories per
+// candidate, then drops the lowest-scoring candidates. The input is not modified.
+func fitCandidatesToBudget(ctx context.Context, client llm.Client, systemPrompt string, candidates *EnrichedCandidates, requirements *Requirements, budget int, events *observability.EventBus) (*EnrichedCandidates, error) {
+	messages := buildRankingMessages(systemPrompt, candidates, requirements)
+	counted, err := llm.CountTokens(ctx, client, messages, nil)
+	if err != nil {
+		return nil, err
+	}
+	if counted <= budget {
+		return candidates, nil
+	}
+
+	// Use the heuristic while trimming, scaled to match the provider's count
+	estimated := llm.EstimateTokens(messages, nil)
+	scale := 1.0
+	if estimated > 0 {
+		scale = float64(counted) / float64(estimated)
+	}
+	fits := func(c *EnrichedCandidates) bool {
+		msgs := buildRankingMessages(systemPrompt, c, requirements)
+		return int(float64(llm.EstimateTokens(msgs, nil))*scale) <= budget
+	}
+
+	trimmed := &EnrichedCandidates{
+		Candidates:     make([]EnrichedCandidate, len(candidates.Candidates)),
+		SearchMetadata: candidates.SearchMetadata,
+	}
+	copy(trimmed.Candidates, candidates.Candidates)
+
+	// 1. Keep only the most relevant repositories per candidate
+	for i := range trimmed.Candidates {
+		cand := &trimmed.Candidates[i]
+		if len(cand.RelevantRepositories) <= minReposPerCandidate {
+			continue
+		}
+		repos := make([]RelevantRepository, len(cand.RelevantRepositories))
+		copy(repos, cand.RelevantRepositories)
+		sort.SliceStable(repos, func(a, b int) bool {
+			return repos[a].RelevanceScore > repos[b].RelevanceScore
+		})
+		cand.RelevantRepositories = repos[:minReposPerCandidate]
+	}
+
+	// 2. Drop the weakest candidates until the payload fits
+	sort.SliceStable(trimmed.Candidates, func(a, b int) bool {
+		return trimmed.Candidates[a].InitialMatchScore > trimmed.Candidates[b].InitialMatchScore
+	})
+	for len(trimmed.Candidates) > 1 && !fits(trimmed) {
+		trimmed.Candidates = trimmed.Candidates[:len(trimmed.Candidates)-1]
+	}
+
+	events.Publish(observability.BudgetWarning{
+		Stage:        StageRanking,
+		BudgetTokens: budget,
+		Kept:         len(trimmed.Candidates),
+		Candidates:   len(candidates.Candidates),
+	})
+
+	return trimmed, nil
+}
@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+func TestFitCandidatesToBudget(t *testing.T) {
+	newCandidates := func(n int) *EnrichedCandidates {
+		cands := &EnrichedCandidates{}
+		for i := 0; i < n; i++ {
+			cand := EnrichedCandidate{
+				Username:          fmt.Sprintf("user%d", i),
+				Bio:               strings.Repeat("bio ", 50),
+				InitialMatchScore: float64(i) / float64(n),
+			}
+			for r := 0; r < 8; r++ {
+				cand.RelevantRepositories = append(cand.RelevantRepositories, RelevantRepository{
+					Name:           fmt.Sprintf("repo%d", r),
+					Description:    strings.Repeat("description ", 20),
+					RelevanceScore: float64(r) / 10,
+				})
+			}
+			cands.Candidates = append(cands.Candidates, cand)
+		}
+		return cands
+	}
+	reqs := &Requirements{RequiredSkills: []string{"Go"}}
+
+	t.Run("UnderBudgetUnchanged", func(t *testing.T) {
+		cands := newCandidates(2)
+		got, err := fitCandidatesToBudget(context.Background(), &MockLLMClient{}, "system", cands, reqs, 1_000_000, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != cands {
+			t.Error("Expected candidates to be returned unchanged")
+		}
+	})
+
+	t.Run("OverBudgetTrimmed", func(t *testing.T) {
+		cands := newCandidates(15)
+		var warnings []observability.BudgetWarning
+		events := observability.NewEventBus(func(event observability.Event) {
+			if w, ok := event.(observability.BudgetWarning); ok {
+				warnings = append(warnings, w)
+			}
+		})
+		got, err := fitCandidatesToBudget(context.Background(), &MockLLMClient{}, "system", cands, reqs, 3000, events)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(got.Candidates) == 0 || len(got.Candidates) >= 15 {
+			t.Fatalf("Expected some candidates to be dropped, got %d", len(got.Candidates))
+		}
+		if got.Candidates[0].Username != "user14" {
+			t.Errorf("Expected highest scoring candidate first, got %s", got.Candidates[0].Username)
+		}
+		if n := len(got.Candidates[0].RelevantRepositories); n != minReposPerCandidate {
+			t.Errorf("Expected %d repositories per candidate, got %d", minReposPerCandidate, n)
+		}
+		if got.Candidates[0].RelevantRepositories[0].Name != "repo7" {
+			t.Errorf("Expected most relevant repository to be kept, got %s", got.Candidates[0].RelevantRepositories[0].Name)
+		}
+		if len(cands.Candidates) != 15 || len(cands.Candidates[0].RelevantRepositories) != 8 {
+			t.Error("Expected input candidates not to be modified")
+		}
+		if len(warnings) != 1 || warnings[0].Kept != len(got.Candidates) || warnings[0].Candidates != 15 {
+			t.Errorf("Expected one budget warning for the trimmed payload, got %+v", warnings)
+		}
+	})
+}
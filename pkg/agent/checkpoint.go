@@ -0,0 +1,101 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrNoCheckpoint is returned by LoadCheckpoint when a run left no checkpoint
+var ErrNoCheckpoint = errors.New("no checkpoint for run")
+
+// Checkpoint is the pipeline state saved after every completed stage, so an
+// interrupted or failed run can resume without repeating the LLM calls and
+// GitHub searches it already made
+type Checkpoint struct {
+	RunID string    `json:"run_id"`
+	Query string    `json:"query"`
+	Stage string    `json:"stage"` // Last completed stage
+	Time  time.Time `json:"time"`
+
+	// Search limits of the run, reapplied on resume
+	TargetCount        int      `json:"target_count,omitempty"`
+	MaxSearchResults   int      `json:"max_search_results,omitempty"`
+	RelevanceThreshold *float64 `json:"relevance_threshold,omitempty"`
+	Language           string   `json:"language,omitempty"`
+	Exclude            []string `json:"exclude,omitempty"`
+	Source             string   `json:"source,omitempty"`
+
+	// Outputs of the completed stages
+	Requirements *Requirements       `json:"requirements,omitempty"`
+	Strategy     *SearchStrategy     `json:"strategy,omitempty"`
+	Candidates   *EnrichedCandidates `json:"candidates,omitempty"`
+}
+
+// Apply returns config with the checkpointed run ID, search limits,
+// language, exclusions and source
+func (c *Checkpoint) Apply(config AgentConfig) AgentConfig {
+	config.RunID = c.RunID
+	config.TargetCount = c.TargetCount
+	config.MaxSearchResults = c.MaxSearchResults
+	config.RelevanceThreshold = c.RelevanceThreshold
+	config.Language = c.Language
+	config.Exclude = c.Exclude
+	config.Source = c.Source
+	return config
+}
+
+// checkpointPath returns the checkpoint file of runID in dir
+func checkpointPath(dir, runID string) string {
+	return filepath.Join(dir, runID+".json")
+}
+
+// writeCheckpoint saves checkpoint to dir, replacing the run's previous one
+func writeCheckpoint(dir string, checkpoint Checkpoint) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	if err := writeFileAtomic(checkpointPath(dir, checkpoint.RunID), append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with data through a temporary
+// file, so a crash never leaves it truncated
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadCheckpoint reads the checkpoint of runID from dir
+func LoadCheckpoint(dir, runID string) (*Checkpoint, error) {
+	if runID == "" || filepath.Base(runID) != runID {
+		return nil, fmt.Errorf("invalid run ID %q", runID)
+	}
+	data, err := os.ReadFile(checkpointPath(dir, runID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w %s in %s", ErrNoCheckpoint, runID, dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	if checkpoint.RunID != runID || checkpoint.Query == "" {
+		return nil, fmt.Errorf("checkpoint %s is incomplete", runID)
+	}
+	return &checkpoint, nil
+}
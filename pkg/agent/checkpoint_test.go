@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestResumeStage2(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+	ctx := context.Background()
+	dir := t.TempDir()
+	query := "Find senior Go backend developers in Lima"
+
+	// Keep the recorded response of each stage to replay them out of order
+	golden := goldenLLMClient(t, "stage2_go_lima")
+	responses := make(map[string]*llm.Response)
+	recorder := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		resp, err := golden.CallAPI(ctx, messages, tools, opts...)
+		responses[llm.ApplyOptions(opts).Stage] = resp
+		return resp, err
+	})
+	if _, err := RunStage2(ctx, recorder, githubClient, query); err != nil {
+		t.Fatalf("RunStage2 failed: %v", err)
+	}
+
+	// The first attempt fails after analyzing the requirements
+	failing := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		stage := llm.ApplyOptions(opts).Stage
+		if stage == StageStrategy {
+			return nil, errors.New("invalid request")
+		}
+		return responses[stage], nil
+	})
+	config := AgentConfig{RunID: "run-7", CheckpointDir: dir, TargetCount: 2, Source: "gitlab", Logger: slog.New(slog.DiscardHandler)}
+	if _, err := RunStage2WithConfig(ctx, failing, githubClient, query, config); err == nil {
+		t.Fatal("Expected the strategy stage to fail")
+	}
+
+	checkpoint, err := LoadCheckpoint(dir, "run-7")
+	if err != nil {
+		t.Fatalf("Expected a checkpoint, got %v", err)
+	}
+	if checkpoint.Stage != StageRequirements || checkpoint.Query != query || checkpoint.Requirements == nil ||
+		checkpoint.Strategy != nil || checkpoint.TargetCount != 2 || checkpoint.Source != "gitlab" {
+		t.Fatalf("Expected a checkpoint after requirements, got %+v", checkpoint)
+	}
+
+	var stages []string
+	client := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		stage := llm.ApplyOptions(opts).Stage
+		stages = append(stages, stage)
+		return responses[stage], nil
+	})
+	result, err := ResumeStage2(ctx, client, githubClient, checkpoint, AgentConfig{CheckpointDir: dir, Logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("ResumeStage2 failed: %v", err)
+	}
+	if !slices.Equal(stages, []string{StageStrategy, StageRanking}) {
+		t.Errorf("Expected only the remaining stages called, got %v", stages)
+	}
+	if result.RunID != "run-7" || len(result.TopCandidates) != 2 || result.Requirements == nil {
+		t.Errorf("Expected the resumed run's result, got %+v", result)
+	}
+
+	checkpoint, err = LoadCheckpoint(dir, "run-7")
+	if err != nil || checkpoint.Stage != StageEnrichment || checkpoint.Candidates == nil {
+		t.Errorf("Expected the checkpoint advanced to enrichment, got %+v, %v", checkpoint, err)
+	}
+}
+
+func TestLoadCheckpointMissing(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadCheckpoint(dir, "run-1"); !errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Expected ErrNoCheckpoint, got %v", err)
+	}
+	if _, err := LoadCheckpoint(dir, "../run-1"); err == nil || errors.Is(err, ErrNoCheckpoint) {
+		t.Errorf("Expected a path in the run ID rejected, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// Enricher adds signals from outside the source platform to an enriched
+// candidate, typically an ExternalProfile. Enrichers run after the
+// candidate's repositories are analyzed and before ranking, which weighs
+// each profile by its confidence.
+type Enricher interface {
+	// Name identifies the enricher in logs, e.g. stackoverflow
+	Name() string
+	// Enrich looks the candidate up and updates it. Finding nothing is not
+	// an error.
+	Enrich(ctx context.Context, candidate *EnrichedCandidate) error
+}
+
+// ConditionalEnricher is an Enricher only worth running for some roles,
+// e.g. Hugging Face lookups for machine learning roles
+type ConditionalEnricher interface {
+	Enricher
+	// AppliesTo reports whether the enricher runs for requirements
+	AppliesTo(requirements *Requirements) bool
+}
+
+// runEnrichers applies the enrichers that apply to requirements to
+// candidate in order, then scores the candidate's published machine learning
+// work for machine learning roles. A failing enricher is reported and leaves
+// the candidate to the others, so an outage of an optional platform never
+// fails the run.
+func runEnrichers(ctx context.Context, enrichers []Enricher, requirements *Requirements, candidate *EnrichedCandidate, events *observability.EventBus) {
+	for _, e := range enrichers {
+		if ctx.Err() != nil {
+			return
+		}
+		if c, ok := e.(ConditionalEnricher); ok && !c.AppliesTo(requirements) {
+			continue
+		}
+		before := len(candidate.ExternalProfiles)
+		err := e.Enrich(ctx, candidate)
+		events.Publish(observability.ExternalProfileLookup{
+			Enricher: e.Name(),
+			Username: candidate.Username,
+			Found:    len(candidate.ExternalProfiles) - before,
+			Err:      err,
+		})
+	}
+	if len(enrichers) > 0 && IsMLRole(requirements) {
+		score := MLExperienceScore(candidate.ExternalProfiles)
+		candidate.MLExperienceScore = &score
+	}
+}
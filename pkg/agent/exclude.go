@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExcludeOrgPrefix marks an AgentConfig.Exclude entry naming an organization
+// rather than a username, as in GitHub search: org:acme
+const ExcludeOrgPrefix = "org:"
+
+// exclusions matches the developers named by AgentConfig.Exclude
+type exclusions struct {
+	usernames map[string]bool
+	orgs      map[string]bool
+}
+
+// newExclusions parses AgentConfig.Exclude entries, ignoring blank ones
+func newExclusions(entries []string) (exclusions, error) {
+	ex := exclusions{usernames: make(map[string]bool), orgs: make(map[string]bool)}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if org, ok := strings.CutPrefix(strings.ToLower(entry), ExcludeOrgPrefix); ok {
+			org = strings.TrimPrefix(strings.TrimSpace(org), "@")
+			if org == "" {
+				return exclusions{}, fmt.Errorf("invalid exclusion %q: want a username or %sname", entry, ExcludeOrgPrefix)
+			}
+			ex.orgs[org] = true
+			continue
+		}
+		ex.usernames[strings.ToLower(strings.TrimPrefix(entry, "@"))] = true
+	}
+	return ex, nil
+}
+
+// excludes reports whether the developer with username, working at company
+// according to their GitHub profile, is excluded. Company is free text such
+// as "@acme", "Acme Corp" or "@acme, @acme-labs": an organization matches
+// any of its names or the whole of it.
+func (e exclusions) excludes(username, company string) bool {
+	if e.usernames[strings.ToLower(username)] {
+		return true
+	}
+	if len(e.orgs) == 0 || company == "" {
+		return false
+	}
+	company = strings.ToLower(strings.TrimSpace(company))
+	if e.orgs[strings.TrimPrefix(company, "@")] {
+		return true
+	}
+	for _, name := range strings.FieldsFunc(company, func(r rune) bool {
+		return r == ' ' || r == ',' || r == ';' || r == '/' || r == '|'
+	}) {
+		if e.orgs[strings.TrimPrefix(name, "@")] {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns candidates without the excluded developers, counting them
+// in the search metadata
+func (e exclusions) filter(candidates *EnrichedCandidates) *EnrichedCandidates {
+	if len(e.usernames) == 0 && len(e.orgs) == 0 {
+		return candidates
+	}
+	filtered := *candidates
+	filtered.Candidates = make([]EnrichedCandidate, 0, len(candidates.Candidates))
+	for _, c := range candidates.Candidates {
+		if e.excludes(c.Username, c.Company) {
+			filtered.SearchMetadata.CandidatesExcluded++
+			continue
+		}
+		filtered.Candidates = append(filtered.Candidates, c)
+	}
+	return &filtered
+}
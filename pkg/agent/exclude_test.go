@@ -0,0 +1,59 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+func TestExclusions(t *testing.T) {
+	ex, err := newExclusions([]string{"@Gopher_Lima", " ", "org:Andes-Labs", "org:Acme Corp"})
+	if err != nil {
+		t.Fatalf("newExclusions failed: %v", err)
+	}
+	tests := []struct {
+		username, company string
+		want              bool
+	}{
+		{"gopher_lima", "", true},
+		{"rustacean_pe", "@andes-labs, @rust-peru", true},
+		{"dev", "Acme Corp", true},
+		{"dev", "@ACME-CORP", false},
+		{"dev", "Andes", false},
+		{"dev", "", false},
+	}
+	for _, tt := range tests {
+		if got := ex.excludes(tt.username, tt.company); got != tt.want {
+			t.Errorf("excludes(%q, %q): expected %v, got %v", tt.username, tt.company, tt.want, got)
+		}
+	}
+	if _, err := newExclusions([]string{"org:"}); err == nil {
+		t.Error("Expected an error for an organization without a name")
+	}
+}
+
+func TestEnrichCandidatesExclude(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+	strategy := &SearchStrategy{}
+	strategy.PrimarySearch.Language = "Go"
+
+	config := AgentConfig{Exclude: []string{"org:andes-labs"}}
+	candidates, err := EnrichCandidates(context.Background(), githubClient, &Requirements{}, strategy, config)
+	if err != nil {
+		t.Fatalf("EnrichCandidates failed: %v", err)
+	}
+	if len(candidates.Candidates) != 1 || candidates.Candidates[0].Username != "gopher_lima" {
+		t.Fatalf("Expected only gopher_lima, got %+v", candidates.Candidates)
+	}
+	if meta := candidates.SearchMetadata; meta.CandidatesExcluded != 1 || meta.TotalProfilesFound != 2 {
+		t.Errorf("Expected 1 of 2 profiles excluded, got %+v", meta)
+	}
+
+	config.Exclude = []string{"org:"}
+	if _, err := EnrichCandidates(context.Background(), githubClient, &Requirements{}, strategy, config); err == nil {
+		t.Error("Expected an error for an invalid exclusion")
+	}
+}
@@ -1,16 +1,31 @@
 package agent
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
 )
 
 // MockLLMClient is already defined in run_test.go
 
+// recordingProgress records progress updates as "done/total"
+type recordingProgress struct {
+	updates  []string
+	finished bool
+}
+
+func (p *recordingProgress) Update(step string, done, total int) {
+	p.updates = append(p.updates, fmt.Sprintf("%d/%d", done, total))
+}
+
+func (p *recordingProgress) Finish(step string) { p.finished = true }
+
 func TestFindAndEnrichCandidates_FallbackStrategies(t *testing.T) {
 	// Setup Mock GitHub Server
 	mockGitHub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -114,7 +129,9 @@ func TestFindAndEnrichCandidates_FallbackStrategies(t *testing.T) {
 	reqs := &Requirements{RequiredSkills: []string{"Go"}}
 
 	// Execute
-	results, err := findAndEnrichCandidates(llmClient, ghClient, strategy, reqs)
+	counter := &observability.EventCounter{}
+	progress := &recordingProgress{}
+	results, err := findAndEnrichCandidates(context.Background(), llmClient, ghClient, strategy, reqs, observability.NewEventBus(counter.Subscriber()), AgentConfig{Progress: progress})
 	if err != nil {
 		t.Fatalf("findAndEnrichCandidates failed: %v", err)
 	}
@@ -129,4 +146,11 @@ func TestFindAndEnrichCandidates_FallbackStrategies(t *testing.T) {
 	if results.Candidates[0].Username != "success_user" {
 		t.Errorf("Expected candidate 'success_user', got '%s'", results.Candidates[0].Username)
 	}
+	counts := counter.Counts()
+	if counts["search_executed"] != 3 || counts["fallback_used"] != 2 || counts["candidate_enriched"] != 1 {
+		t.Errorf("Expected 3 searches, 2 fallbacks and 1 enriched candidate, got %v", counts)
+	}
+	if strings.Join(progress.updates, " ") != "0/1" || !progress.finished {
+		t.Errorf("Expected progress reported for the candidate and finished, got %v (finished %v)", progress.updates, progress.finished)
+	}
 }
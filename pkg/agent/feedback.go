@@ -0,0 +1,337 @@
+package agent
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Verdicts a recruiter gives a ranked candidate, which LearnScoring learns from
+const (
+	VerdictUp       = "up"       // A good match
+	VerdictDown     = "down"     // A poor match
+	VerdictHired    = "hired"    // Hired, the best evidence of a good match
+	VerdictRejected = "rejected" // Rejected after review or interviews
+)
+
+// Verdicts lists the valid verdicts
+var Verdicts = []string{VerdictUp, VerdictDown, VerdictHired, VerdictRejected}
+
+// verdictWeights weigh each verdict in learning; hires and rejections, decided
+// after interviews, count double
+var verdictWeights = map[string]float64{VerdictUp: 1, VerdictDown: -1, VerdictHired: 2, VerdictRejected: -2}
+
+// Role families feedback is learned per, so what recruiters like in
+// frontend candidates does not skew the ranking of backend ones
+const (
+	RoleFamilyML       = "ml"
+	RoleFamilyMobile   = "mobile"
+	RoleFamilyFrontend = "frontend"
+	RoleFamilyDevOps   = "devops"
+	RoleFamilyData     = "data"
+	RoleFamilySecurity = "security"
+	RoleFamilyBackend  = "backend"
+	RoleFamilyGeneral  = "general" // None of the others
+)
+
+// roleFamilyTerms mark requirements as work of a role family, in the order
+// ties are broken. As with mlTerms, short terms must match a whole
+// requirement.
+var roleFamilyTerms = []struct {
+	family     string
+	terms      []string
+	exactTerms []string
+}{
+	{RoleFamilyMobile, []string{"mobile", "android", "react native", "flutter", "swiftui", "objective-c"}, []string{"ios", "swift", "kotlin"}},
+	{RoleFamilyFrontend, []string{"frontend", "front-end", "front end", "react", "angular", "svelte", "next.js", "tailwind"}, []string{"vue", "css", "html", "ui", "ux"}},
+	{RoleFamilyDevOps, []string{"devops", "kubernetes", "docker", "terraform", "ansible", "infrastructure", "site reliability", "ci/cd", "cloud"}, []string{"k8s", "sre", "aws", "gcp", "azure", "helm"}},
+	{RoleFamilyData, []string{"data engineer", "data pipeline", "spark", "airflow", "kafka", "snowflake", "bigquery", "hadoop", "warehouse"}, []string{"etl", "dbt", "sql"}},
+	{RoleFamilySecurity, []string{"security", "penetration", "pentest", "cryptograph", "appsec", "infosec", "vulnerabilit"}, []string{"soc"}},
+	{RoleFamilyBackend, []string{"backend", "back-end", "back end", "golang", "microservice", "distributed systems", "node.js", "django", "spring", "rails", "postgres", ".net", "grpc"}, []string{"go", "java", "rust", "ruby", "php", "c#", "scala", "elixir", "api", "apis"}},
+}
+
+// RoleFamily returns the role family of requirements: RoleFamilyML for
+// machine learning roles, else the family most of their skills and keywords
+// belong to, RoleFamilyGeneral for none
+func RoleFamily(requirements *Requirements) string {
+	if requirements == nil {
+		return RoleFamilyGeneral
+	}
+	if IsMLRole(requirements) {
+		return RoleFamilyML
+	}
+	family, best := RoleFamilyGeneral, 0
+	for _, f := range roleFamilyTerms {
+		hits := 0
+		for _, list := range [][]string{requirements.RequiredSkills, requirements.Keywords} {
+			for _, item := range list {
+				item = strings.ToLower(strings.TrimSpace(item))
+				if slices.Contains(f.exactTerms, item) || slices.ContainsFunc(f.terms, func(term string) bool { return strings.Contains(item, term) }) {
+					hits++
+				}
+			}
+		}
+		if hits > best {
+			family, best = f.family, hits
+		}
+	}
+	return family
+}
+
+// ScoringWeights weigh the components of a MatchBreakdown into the final
+// match score. They sum to 1.
+type ScoringWeights struct {
+	RequiredSkills      float64 `json:"required_skills"`
+	RepositoryRelevance float64 `json:"repository_relevance"`
+	Experience          float64 `json:"experience"`
+	ProfileQuality      float64 `json:"profile_quality"`
+}
+
+// DefaultScoringWeights weigh skills 40%, repositories 30%, experience 20%
+// and profile quality 10%
+var DefaultScoringWeights = ScoringWeights{RequiredSkills: 0.4, RepositoryRelevance: 0.3, Experience: 0.2, ProfileQuality: 0.1}
+
+// Score returns the weighted sum of the components of bd
+func (w ScoringWeights) Score(bd MatchBreakdown) float64 {
+	return bd.RequiredSkillsScore*w.RequiredSkills + bd.RepositoryRelevanceScore*w.RepositoryRelevance +
+		bd.ExperienceScore*w.Experience + bd.ProfileQualityScore*w.ProfileQuality
+}
+
+// Scoring is how the candidates of a role family are scored, learned from
+// recruiter feedback on earlier rankings
+type Scoring struct {
+	RoleFamily string         `json:"role_family"`
+	Weights    ScoringWeights `json:"weights"`
+	// KeywordBoosts add points to the final match score of candidates whose
+	// repositories or external profiles mention the keyword, as found by
+	// CandidateKeywords; negative boosts take points away
+	KeywordBoosts map[string]float64 `json:"keyword_boosts,omitempty"`
+	Feedback      int                `json:"feedback"` // Number of feedback entries learned from
+}
+
+// Feedback is a verdict on a candidate, with what the ranking knew of them,
+// as LearnScoring learns from
+type Feedback struct {
+	Verdict string
+	// Breakdown is the candidate's match breakdown in the rated run, nil if
+	// the run did not rank them
+	Breakdown *MatchBreakdown
+	Keywords  []string // CandidateKeywords of the candidate
+}
+
+// Limits of learning
+const (
+	// MinFeedback is the number of feedback entries a role family needs
+	// before its scoring departs from the defaults
+	MinFeedback = 5
+	// fullConfidenceFeedback is the number of feedback entries at which
+	// adjustments reach their full size; fewer scale them down
+	fullConfidenceFeedback = 20
+	maxWeightShift         = 0.5  // A weight moves by at most half of itself
+	maxKeywordBoost        = 5.0  // Points a keyword adds or takes away
+	maxTotalBoost          = 10.0 // Points all the keywords of a candidate add or take away
+	maxKeywordBoosts       = 10
+	minKeywordSupport      = 2 // Feedback entries that must mention a keyword to boost it
+	minKeywordBoost        = 0.5
+)
+
+// LearnScoring learns the scoring of a role family from feedback on its
+// candidates, nil with fewer than MinFeedback entries. Each weight grows
+// with how much higher the candidates rated up scored on its component than
+// those rated down, and shrinks the other way. Keywords more common among
+// the candidates rated up than among those rated down are boosted, the
+// others penalized. Adjustments grow with the amount of feedback and are
+// bounded, so a few verdicts cannot overturn the ranking.
+func LearnScoring(family string, feedback []Feedback) *Scoring {
+	if len(feedback) < MinFeedback {
+		return nil
+	}
+	confidence := min(1, float64(len(feedback))/fullConfidenceFeedback)
+	scoring := &Scoring{RoleFamily: family, Weights: DefaultScoringWeights, Feedback: len(feedback)}
+
+	// Mean component scores of the candidates rated up and down
+	var up, down [4]float64
+	var upWeight, downWeight float64
+	for _, f := range feedback {
+		w := verdictWeights[f.Verdict]
+		if f.Breakdown == nil || w == 0 {
+			continue
+		}
+		scores := f.Breakdown.components()
+		for i := range scores {
+			if w > 0 {
+				up[i] += w * scores[i]
+			} else {
+				down[i] -= w * scores[i]
+			}
+		}
+		if w > 0 {
+			upWeight += w
+		} else {
+			downWeight -= w
+		}
+	}
+	if upWeight > 0 && downWeight > 0 {
+		weights := scoring.Weights.components()
+		var sum float64
+		for i := range weights {
+			// Component scores run from 0 to 100
+			shift := (up[i]/upWeight - down[i]/downWeight) / 100
+			weights[i] *= 1 + max(-maxWeightShift, min(maxWeightShift, shift))*confidence
+			sum += weights[i]
+		}
+		scoring.Weights = ScoringWeights{
+			RequiredSkills:      round(weights[0]/sum, 3),
+			RepositoryRelevance: round(weights[1]/sum, 3),
+			Experience:          round(weights[2]/sum, 3),
+			ProfileQuality:      round(weights[3]/sum, 3),
+		}
+	}
+
+	// Share of the up and down verdicts mentioning each keyword
+	var upTotal, downTotal float64
+	upShare, downShare, support := map[string]float64{}, map[string]float64{}, map[string]int{}
+	for _, f := range feedback {
+		w := verdictWeights[f.Verdict]
+		if w > 0 {
+			upTotal += w
+		} else {
+			downTotal -= w
+		}
+		for _, k := range uniqueKeywords(f.Keywords) {
+			support[k]++
+			if w > 0 {
+				upShare[k] += w
+			} else {
+				downShare[k] -= w
+			}
+		}
+	}
+	type boost struct {
+		keyword string
+		points  float64
+	}
+	var boosts []boost
+	for k, n := range support {
+		if n < minKeywordSupport {
+			continue
+		}
+		var lift float64
+		if upTotal > 0 {
+			lift += upShare[k] / upTotal
+		}
+		if downTotal > 0 {
+			lift -= downShare[k] / downTotal
+		}
+		if points := round(lift*maxKeywordBoost*confidence, 1); math.Abs(points) >= minKeywordBoost {
+			boosts = append(boosts, boost{k, points})
+		}
+	}
+	slices.SortFunc(boosts, func(a, b boost) int {
+		if c := cmp.Compare(math.Abs(b.points), math.Abs(a.points)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.keyword, b.keyword)
+	})
+	for _, b := range boosts[:min(len(boosts), maxKeywordBoosts)] {
+		if scoring.KeywordBoosts == nil {
+			scoring.KeywordBoosts = make(map[string]float64)
+		}
+		scoring.KeywordBoosts[b.keyword] = b.points
+	}
+	return scoring
+}
+
+// score returns the final match score of a candidate with breakdown bd and
+// keywords, by DefaultScoringWeights for a nil scoring
+func (s *Scoring) score(bd MatchBreakdown, keywords []string) float64 {
+	if s == nil {
+		return DefaultScoringWeights.Score(bd)
+	}
+	var boost float64
+	for _, k := range uniqueKeywords(keywords) {
+		boost += s.KeywordBoosts[k]
+	}
+	boost = max(-maxTotalBoost, min(maxTotalBoost, boost))
+	return max(0, min(100, s.Weights.Score(bd)+boost))
+}
+
+// CandidateKeywords returns the keywords of a candidate keyword boosts match:
+// the languages and topics of their relevant repositories and the top tags
+// of their external profiles, lowercased
+func CandidateKeywords(c EnrichedCandidate) []string {
+	var keywords []string
+	for _, repo := range c.RelevantRepositories {
+		if repo.Language != "" {
+			keywords = append(keywords, repo.Language)
+		}
+		keywords = append(keywords, repo.Topics...)
+	}
+	for _, p := range c.ExternalProfiles {
+		keywords = append(keywords, p.TopTags...)
+	}
+	return uniqueKeywords(keywords)
+}
+
+// ScoringStore is implemented by a RunStore that keeps feedback on ranked
+// candidates, e.g. storage.Store. A run's candidates are scored with the
+// Scoring learned for its role family.
+type ScoringStore interface {
+	// Scoring returns the scoring learned for family, nil without enough
+	// feedback
+	Scoring(ctx context.Context, family string) (*Scoring, error)
+}
+
+// scoring returns the scoring the configured store learned for the role
+// family of requirements, nil for the defaults. A failure is logged and
+// leaves the defaults.
+func (c AgentConfig) scoring(ctx context.Context, requirements *Requirements) *Scoring {
+	store, ok := c.Store.(ScoringStore)
+	if !ok {
+		return nil
+	}
+	family := RoleFamily(requirements)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	scoring, err := store.Scoring(ctx, family)
+	if err != nil {
+		c.logger().Warn("Learned scoring not read, using the default weights", "role_family", family, "error", err)
+		return nil
+	}
+	if scoring != nil {
+		c.logger().Debug("Scoring with learned weights", "role_family", family, "feedback", scoring.Feedback,
+			"weights", scoring.Weights, "keyword_boosts", len(scoring.KeywordBoosts))
+	}
+	return scoring
+}
+
+// components returns the component scores of bd in the order of ScoringWeights
+func (bd MatchBreakdown) components() [4]float64 {
+	return [4]float64{bd.RequiredSkillsScore, bd.RepositoryRelevanceScore, bd.ExperienceScore, bd.ProfileQualityScore}
+}
+
+// components returns the weights of w in order
+func (w ScoringWeights) components() [4]float64 {
+	return [4]float64{w.RequiredSkills, w.RepositoryRelevance, w.Experience, w.ProfileQuality}
+}
+
+// uniqueKeywords lowercases and trims keywords, dropping empty and repeated ones
+func uniqueKeywords(keywords []string) []string {
+	var unique []string
+	for _, k := range keywords {
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k != "" && !slices.Contains(unique, k) {
+			unique = append(unique, k)
+		}
+	}
+	return unique
+}
+
+// round rounds x to the given number of decimals
+func round(x float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(x*p) / p
+}
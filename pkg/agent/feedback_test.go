@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestRoleFamily(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirements *Requirements
+		want         string
+	}{
+		{"nil", nil, RoleFamilyGeneral},
+		{"ml", &Requirements{RequiredSkills: []string{"Go", "PyTorch"}}, RoleFamilyML},
+		{"backend", &Requirements{RequiredSkills: []string{"Go", "PostgreSQL"}, Keywords: []string{"microservices"}}, RoleFamilyBackend},
+		{"frontend", &Requirements{RequiredSkills: []string{"React", "CSS", "Go"}}, RoleFamilyFrontend},
+		{"mobile before frontend", &Requirements{RequiredSkills: []string{"React Native"}}, RoleFamilyMobile},
+		{"devops", &Requirements{RequiredSkills: []string{"Kubernetes", "Terraform"}, Keywords: []string{"AWS"}}, RoleFamilyDevOps},
+		{"short terms match whole", &Requirements{RequiredSkills: []string{"Golf", "Social skills"}}, RoleFamilyGeneral},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RoleFamily(tt.requirements); got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLearnScoring(t *testing.T) {
+	if got := LearnScoring(RoleFamilyBackend, make([]Feedback, MinFeedback-1)); got != nil {
+		t.Errorf("Expected no scoring below %d feedback entries, got %+v", MinFeedback, got)
+	}
+
+	// Hired candidates stood out on experience, rejected ones on skills
+	var feedback []Feedback
+	for range 10 {
+		feedback = append(feedback,
+			Feedback{Verdict: VerdictHired, Breakdown: &MatchBreakdown{RequiredSkillsScore: 60, RepositoryRelevanceScore: 70, ExperienceScore: 90, ProfileQualityScore: 60},
+				Keywords: []string{"Go", "kubernetes"}},
+			Feedback{Verdict: VerdictRejected, Breakdown: &MatchBreakdown{RequiredSkillsScore: 90, RepositoryRelevanceScore: 70, ExperienceScore: 40, ProfileQualityScore: 60},
+				Keywords: []string{"go", "php"}})
+	}
+	scoring := LearnScoring(RoleFamilyBackend, feedback)
+	if scoring == nil || scoring.RoleFamily != RoleFamilyBackend || scoring.Feedback != 20 {
+		t.Fatalf("Expected a backend scoring learned from 20 entries, got %+v", scoring)
+	}
+	w := scoring.Weights
+	if w.Experience <= DefaultScoringWeights.Experience || w.RequiredSkills >= DefaultScoringWeights.RequiredSkills {
+		t.Errorf("Expected experience weighed up and skills down, got %+v", w)
+	}
+	if sum := w.RequiredSkills + w.RepositoryRelevance + w.Experience + w.ProfileQuality; sum < 0.999 || sum > 1.001 {
+		t.Errorf("Expected the weights to sum to 1, got %v", sum)
+	}
+	// Experience moves up by the capped half, to 0.3 of 0.98 before normalizing
+	if w.Experience != 0.306 || w.RepositoryRelevance != 0.306 {
+		t.Errorf("Expected the weight shifts capped, got %+v", w)
+	}
+	if got := scoring.KeywordBoosts["kubernetes"]; got != maxKeywordBoost {
+		t.Errorf("Expected kubernetes boosted by %v, got %v", maxKeywordBoost, got)
+	}
+	if got := scoring.KeywordBoosts["php"]; got != -maxKeywordBoost {
+		t.Errorf("Expected php penalized by %v, got %v", maxKeywordBoost, got)
+	}
+	if _, ok := scoring.KeywordBoosts["go"]; ok {
+		t.Errorf("Expected no boost for a keyword common to both, got %v", scoring.KeywordBoosts)
+	}
+
+	// Only positive feedback leaves the weights alone but boosts keywords,
+	// in proportion to the amount of feedback
+	var liked []Feedback
+	for range 10 {
+		liked = append(liked, Feedback{Verdict: VerdictUp, Keywords: []string{"rust"}})
+	}
+	scoring = LearnScoring(RoleFamilyBackend, liked)
+	if scoring.Weights != DefaultScoringWeights || scoring.KeywordBoosts["rust"] != 2.5 {
+		t.Errorf("Expected the default weights and rust boosted by 2.5 at half confidence, got %+v", scoring)
+	}
+}
+
+func TestRankAndPresentScoring(t *testing.T) {
+	client := &MockLLMClient{
+		CallAPIFunc: func(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+			return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: `{
+  "top_candidates": [
+    {"username": "skilled", "match_breakdown": {"required_skills_score": 90, "experience_score": 40}},
+    {"username": "seasoned", "match_breakdown": {"required_skills_score": 70, "experience_score": 80}}
+  ],
+  "summary": {"candidates_presented": 2}
+}`}}}, nil
+		},
+	}
+	candidates := &EnrichedCandidates{Candidates: []EnrichedCandidate{
+		{Username: "skilled", RelevantRepositories: []RelevantRepository{{Language: "PHP"}}},
+		{Username: "seasoned", RelevantRepositories: []RelevantRepository{{Language: "Go", Topics: []string{"kubernetes"}}}},
+	}}
+
+	result, _, err := rankAndPresent(context.Background(), client, candidates, &Requirements{}, rankingOptions{logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.TopCandidates[0].Username != "skilled" || result.Scoring != nil {
+		t.Fatalf("Expected skilled first by the default weights, got %+v", result.TopCandidates)
+	}
+
+	scoring := &Scoring{RoleFamily: RoleFamilyBackend, Weights: DefaultScoringWeights,
+		KeywordBoosts: map[string]float64{"kubernetes": 5, "php": -5}}
+	result, _, err = rankAndPresent(context.Background(), client, candidates, &Requirements{}, rankingOptions{scoring: scoring, logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// skilled: 36 + 8 - 5, seasoned: 28 + 16 + 5
+	if result.TopCandidates[0].Username != "seasoned" || result.TopCandidates[0].FinalMatchScore != 49 || result.TopCandidates[1].FinalMatchScore != 39 {
+		t.Errorf("Expected the boosts to put seasoned first, got %+v", result.TopCandidates)
+	}
+	if result.Scoring != scoring {
+		t.Errorf("Expected the result to carry the scoring used")
+	}
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Result output formats
+const (
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+	FormatTable    = "table"
+	FormatHTML     = "html"
+	FormatPretty   = "pretty"
+)
+
+// ResultFormats lists the formats WriteResult accepts
+var ResultFormats = []string{FormatPretty, FormatJSON, FormatMarkdown, FormatTable, FormatHTML}
+
+// WriteResult writes result to w in format. JSON is the full result and
+// pretty its candidates as uncolored cards for reading (see WritePretty); the
+// other formats are a flat table of the top candidates for pasting into
+// hiring documents. Spreadsheet formats are written by package export.
+func WriteResult(w io.Writer, format string, result *FinalResult) error {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal result: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case FormatMarkdown:
+		return writeResultMarkdown(w, result)
+	case FormatTable:
+		return writeResultTable(w, result)
+	case FormatHTML:
+		return htmlResult.Execute(w, result)
+	case FormatPretty:
+		return WritePretty(w, result, false)
+	default:
+		return fmt.Errorf("unknown format %q: want one of %s", format, strings.Join(ResultFormats, ", "))
+	}
+}
+
+// formatScore renders a match score without trailing noise
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 2, 64)
+}
+
+// projectNames lists a candidate's top project names
+func projectNames(c RankedCandidate) []string {
+	names := make([]string, len(c.TopRelevantProjects))
+	for i, p := range c.TopRelevantProjects {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// SeenBeforeNote describes the earlier runs that surfaced a candidate, e.g.
+// "seen in run X (2026-01-02), score 81.0, and 1 other run; contacted
+// 2026-01-05; replied; tagged backend"; empty for a candidate new to the
+// store
+func SeenBeforeNote(seen *SeenBefore) string {
+	if seen == nil {
+		return ""
+	}
+	note := fmt.Sprintf("seen in run %s (%s)", seen.RunID, seen.Time.Local().Format(time.DateOnly))
+	if seen.FinalMatchScore != nil {
+		note += fmt.Sprintf(", score %.1f", *seen.FinalMatchScore)
+	}
+	switch others := seen.Runs - 1; {
+	case others == 1:
+		note += ", and 1 other run"
+	case others > 1:
+		note += fmt.Sprintf(", and %d other runs", others)
+	}
+	if seen.ContactedAt != nil {
+		note += "; contacted " + seen.ContactedAt.Local().Format(time.DateOnly)
+	}
+	// A contacted candidate's status only adds news past contacted
+	if seen.Status != "" && seen.Status != "new" && (seen.Status != "contacted" || seen.ContactedAt == nil) {
+		note += "; " + seen.Status
+	}
+	if len(seen.Tags) > 0 {
+		note += "; tagged " + strings.Join(seen.Tags, ", ")
+	}
+	return note
+}
+
+func writeResultMarkdown(w io.Writer, result *FinalResult) error {
+	var b strings.Builder
+	b.WriteString("| Rank | Candidate | Location | Score | Key qualifications | Top projects | Concerns |\n")
+	b.WriteString("|------|-----------|----------|-------|--------------------|--------------|----------|\n")
+	for _, c := range result.TopCandidates {
+		candidate := fmt.Sprintf("[%s](%s)", c.Username, c.GitHubURL)
+		if c.Name != "" {
+			candidate = markdownCell(c.Name) + " " + candidate
+		}
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s | %s | %s |\n", c.Rank, candidate, markdownCell(c.Location), formatScore(c.FinalMatchScore),
+			markdownCell(strings.Join(c.KeyQualifications, ", ")), markdownCell(strings.Join(projectNames(c), ", ")), markdownCell(c.PotentialConcerns))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeResultTable(w io.Writer, result *FinalResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "RANK\tUSERNAME\tNAME\tLOCATION\tSCORE\tKEY QUALIFICATIONS")
+	for _, c := range result.TopCandidates {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n", c.Rank, c.Username, c.Name, c.Location, formatScore(c.FinalMatchScore),
+			strings.Join(c.KeyQualifications, ", "))
+	}
+	return tw.Flush()
+}
+
+var htmlResult = htmltemplate.Must(htmltemplate.New("result").Funcs(map[string]any{
+	"join":     strings.Join,
+	"score":    formatScore,
+	"projects": projectNames,
+}).Parse(`<table>
+<tr><th>Rank</th><th>Candidate</th><th>Location</th><th>Score</th><th>Key qualifications</th><th>Top projects</th><th>Concerns</th></tr>
+{{range .TopCandidates}}<tr><td>{{.Rank}}</td><td>{{with .Name}}{{.}} {{end}}<a href="{{.GitHubURL}}">{{.Username}}</a></td><td>{{.Location}}</td><td>{{score .FinalMatchScore}}</td><td>{{join .KeyQualifications ", "}}</td><td>{{join (projects .) ", "}}</td><td>{{.PotentialConcerns}}</td></tr>
+{{end}}</table>
+`))
+
+// Shortlist renders the top candidates as plain text for pasting into an
+// email or chat message: one numbered entry per candidate with its score,
+// location, leading qualifications and profile URL
+func Shortlist(result *FinalResult) string {
+	var b strings.Builder
+	for _, c := range result.TopCandidates {
+		title := "@" + c.Username
+		if c.Name != "" {
+			title = c.Name + " (@" + c.Username + ")"
+		}
+		fmt.Fprintf(&b, "%d. %s, score %s", c.Rank, title, formatScore(c.FinalMatchScore))
+		if c.Location != "" {
+			b.WriteString(", " + c.Location)
+		}
+		b.WriteString("\n")
+		if len(c.KeyQualifications) > 0 {
+			b.WriteString("   " + strings.Join(c.KeyQualifications[:min(3, len(c.KeyQualifications))], ", ") + "\n")
+		}
+		if c.GitHubURL != "" {
+			b.WriteString("   " + c.GitHubURL + "\n")
+		}
+	}
+	return b.String()
+}
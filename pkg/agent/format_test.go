@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func formatFixture() *FinalResult {
+	return &FinalResult{TopCandidates: []RankedCandidate{{
+		Rank: 1, Username: "gopher_lima", Name: "Ana Quispe", Location: "Lima, Peru",
+		GitHubURL: "https://github.com/gopher_lima", FinalMatchScore: 90,
+		KeyQualifications:   []string{"Go", "gRPC"},
+		TopRelevantProjects: []RelevantProject{{Name: "grpc-gateway-kit"}},
+		MatchReasoning:      "Strong Go backend, \"popular\" toolkit",
+		PotentialConcerns:   "Few <public> repos | unclear seniority",
+	}}}
+}
+
+func TestWriteResultMarkdownAndTable(t *testing.T) {
+	var md bytes.Buffer
+	if err := WriteResult(&md, FormatMarkdown, formatFixture()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	want := `| 1 | Ana Quispe [gopher_lima](https://github.com/gopher_lima) | Lima, Peru | 90.00 | Go, gRPC | grpc-gateway-kit | Few <public> repos \| unclear seniority |`
+	if !strings.Contains(md.String(), want) {
+		t.Errorf("Expected markdown row %q, got:\n%s", want, md.String())
+	}
+
+	var table bytes.Buffer
+	if err := WriteResult(&table, FormatTable, formatFixture()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "RANK  USERNAME") || !strings.Contains(lines[1], "gopher_lima  Ana Quispe") {
+		t.Errorf("Expected an aligned table, got:\n%s", table.String())
+	}
+}
+
+func TestShortlist(t *testing.T) {
+	result := formatFixture()
+	result.TopCandidates = append(result.TopCandidates, RankedCandidate{Rank: 2, Username: "rustacean", FinalMatchScore: 71.5})
+	want := "1. Ana Quispe (@gopher_lima), score 90.00, Lima, Peru\n" +
+		"   Go, gRPC\n" +
+		"   https://github.com/gopher_lima\n" +
+		"2. @rustacean, score 71.50\n"
+	if got := Shortlist(result); got != want {
+		t.Errorf("Expected shortlist:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestWriteResultHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResult(&buf, FormatHTML, formatFixture()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Contains(buf.String(), "<public>") || !strings.Contains(buf.String(), `<a href="https://github.com/gopher_lima">gopher_lima</a>`) {
+		t.Errorf("Expected an escaped table with profile links, got:\n%s", buf.String())
+	}
+}
+
+func TestWriteResultUnknownFormat(t *testing.T) {
+	if err := WriteResult(&bytes.Buffer{}, "xml", formatFixture()); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
+
+func TestWritePretty(t *testing.T) {
+	result := formatFixture()
+	result.Summary = ResultSummary{TotalCandidatesFound: 14, AverageMatchScore: 90}
+	result.TopCandidates[0].TopRelevantProjects[0].URL = "https://github.com/gopher_lima/grpc-gateway-kit"
+
+	var plain bytes.Buffer
+	if err := WriteResult(&plain, FormatPretty, result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, want := range []string{
+		"1 candidates presented, 14 found, average score 90.0",
+		"#1  Ana Quispe (@gopher_lima)  ·  Lima, Peru",
+		"    ██████████████████░░ 90.0",
+		"    ▸ grpc-gateway-kit  https://github.com/gopher_lima/grpc-gateway-kit",
+		"    Concerns: Few <public> repos | unclear seniority",
+	} {
+		if !strings.Contains(plain.String(), want) {
+			t.Errorf("Expected %q in:\n%s", want, plain.String())
+		}
+	}
+	if strings.Contains(plain.String(), "\033[") {
+		t.Error("Expected no ANSI codes without color")
+	}
+
+	var colored bytes.Buffer
+	WritePretty(&colored, result, true)
+	if !strings.Contains(colored.String(), ansiGreen+"██████████████████░░"+ansiReset) {
+		t.Errorf("Expected a green score bar, got:\n%q", colored.String())
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("one two three four", 12, "  ")
+	if got != "  one two\n  three four\n" {
+		t.Errorf("Expected wrapped, indented lines, got %q", got)
+	}
+}
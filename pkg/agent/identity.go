@@ -0,0 +1,254 @@
+package agent
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// DefaultMergeThreshold is the confidence at which profiles on two source
+// platforms are merged as the same person: a shared email, a link between
+// the profiles or the same website alone reach it, while a name needs the
+// username to match as well
+const DefaultMergeThreshold = 0.7
+
+// Identity is one of a candidate's profiles on a source platform
+type Identity struct {
+	Platform string `json:"platform"`
+	Username string `json:"username"`
+	URL      string `json:"url"`
+	// Confidence (0-1) that the profile is the same person as the first
+	// identity, and the signals it rests on, e.g. email; unset for the first
+	Confidence float64  `json:"confidence,omitempty"`
+	Signals    []string `json:"signals,omitempty"`
+}
+
+// Signals identity resolution matches profiles on
+const (
+	SignalEmail    = "email"    // The same public email
+	SignalLink     = "link"     // One profile links to the other
+	SignalWebsite  = "website"  // The same website
+	SignalExternal = "external" // The same external profile, linked from both
+	SignalName     = "name"     // The same full name
+	SignalUsername = "username" // The same username
+	SignalLocation = "location" // A place in common, counted with a name or username
+)
+
+// signalConfidence is the confidence each signal gives on its own. Signals
+// combine as independent evidence: two profiles are different people only
+// if every signal is a coincidence.
+var signalConfidence = map[string]float64{
+	SignalEmail:    0.95,
+	SignalLink:     0.95,
+	SignalExternal: 0.9,
+	SignalWebsite:  0.8,
+	SignalName:     0.5,
+	SignalUsername: 0.4,
+	SignalLocation: 0.2,
+}
+
+// maxMergeConfidence keeps merges short of certain
+const maxMergeConfidence = 0.99
+
+// mergeConfidence returns the confidence that a and b are the same person,
+// with the signals that matched
+func mergeConfidence(a, b *EnrichedCandidate) (float64, []string) {
+	var signals []string
+	if a.Email != "" && strings.EqualFold(a.Email, b.Email) {
+		signals = append(signals, SignalEmail)
+	}
+	if linksTo(a.Blog+" "+a.Bio, b.GitHubURL) || linksTo(b.Blog+" "+b.Bio, a.GitHubURL) {
+		signals = append(signals, SignalLink)
+	}
+	if website := NormalizeURL(a.Blog); website != "" && website == NormalizeURL(b.Blog) {
+		signals = append(signals, SignalWebsite)
+	}
+	if sharesLinkedProfile(a.ExternalProfiles, b.ExternalProfiles) {
+		signals = append(signals, SignalExternal)
+	}
+	named := false
+	if name := NormalizeName(a.Name); strings.Contains(name, " ") && name == NormalizeName(b.Name) {
+		signals, named = append(signals, SignalName), true
+	}
+	if strings.EqualFold(platformUsername(a.Username), platformUsername(b.Username)) {
+		signals, named = append(signals, SignalUsername), true
+	}
+	if named && SharePlace(a.Location, b.Location) {
+		signals = append(signals, SignalLocation)
+	}
+
+	coincidence := 1.0
+	for _, s := range signals {
+		coincidence *= 1 - signalConfidence[s]
+	}
+	return math.Round(min(1-coincidence, maxMergeConfidence)*100) / 100, signals
+}
+
+// resolveIdentities merges the candidates found on several source platforms
+// that are likely the same person, reaching at least threshold confidence,
+// into the first of them. A person keeps at most one profile per platform.
+// It returns the candidates left and the number merged away.
+func resolveIdentities(candidates []EnrichedCandidate, threshold float64, events *observability.EventBus) ([]EnrichedCandidate, int) {
+	var resolved []EnrichedCandidate
+	// The profiles merged into each resolved candidate, as found
+	var profiles [][]EnrichedCandidate
+	merged := 0
+	for _, c := range candidates {
+		best, bestConfidence, bestSignals := -1, 0.0, []string(nil)
+		for i := range resolved {
+			if c.Platform == "" || slices.ContainsFunc(profiles[i], func(p EnrichedCandidate) bool { return p.Platform == c.Platform }) {
+				continue
+			}
+			for _, p := range profiles[i] {
+				if confidence, signals := mergeConfidence(&p, &c); confidence >= threshold && confidence > bestConfidence {
+					best, bestConfidence, bestSignals = i, confidence, signals
+				}
+			}
+		}
+		if best < 0 {
+			resolved = append(resolved, c)
+			profiles = append(profiles, []EnrichedCandidate{c})
+			continue
+		}
+		mergeInto(&resolved[best], c, bestConfidence, bestSignals)
+		profiles[best] = append(profiles[best], c)
+		merged++
+		events.Publish(observability.ProfilesMerged{Username: resolved[best].Username, Merged: c.Username, Confidence: bestConfidence, Signals: bestSignals})
+	}
+	return resolved, merged
+}
+
+// mergeInto combines other's profile and evidence into c
+func mergeInto(c *EnrichedCandidate, other EnrichedCandidate, confidence float64, signals []string) {
+	if len(c.Identities) == 0 {
+		c.Identities = []Identity{{Platform: c.Platform, Username: c.Username, URL: c.GitHubURL}}
+		c.MergeConfidence = confidence
+	}
+	c.Identities = append(c.Identities, Identity{
+		Platform:   other.Platform,
+		Username:   other.Username,
+		URL:        other.GitHubURL,
+		Confidence: confidence,
+		Signals:    signals,
+	})
+	c.MergeConfidence = min(c.MergeConfidence, confidence)
+
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&c.Name, other.Name)
+	fill(&c.Location, other.Location)
+	fill(&c.Company, other.Company)
+	fill(&c.Bio, other.Bio)
+	fill(&c.Blog, other.Blog)
+	fill(&c.Email, other.Email)
+	c.PublicRepos += other.PublicRepos
+	c.Followers += other.Followers
+	c.RelevantRepositories = append(c.RelevantRepositories, other.RelevantRepositories...)
+	for _, skill := range other.SkillsFound {
+		if !slices.Contains(c.SkillsFound, skill) {
+			c.SkillsFound = append(c.SkillsFound, skill)
+		}
+	}
+	c.ExperienceIndicators.AccountAgeYears = max(c.ExperienceIndicators.AccountAgeYears, other.ExperienceIndicators.AccountAgeYears)
+	c.ExperienceIndicators.TotalStars += other.ExperienceIndicators.TotalStars
+	c.ExperienceIndicators.HasPopularProjects = c.ExperienceIndicators.HasPopularProjects || other.ExperienceIndicators.HasPopularProjects
+	c.InitialMatchScore = max(c.InitialMatchScore, other.InitialMatchScore)
+	for _, p := range other.ExternalProfiles {
+		if !slices.ContainsFunc(c.ExternalProfiles, func(q ExternalProfile) bool { return q.Platform == p.Platform && q.URL == p.URL }) {
+			c.ExternalProfiles = append(c.ExternalProfiles, p)
+		}
+	}
+	if c.MLExperienceScore != nil || other.MLExperienceScore != nil {
+		score := MLExperienceScore(c.ExternalProfiles)
+		c.MLExperienceScore = &score
+	}
+}
+
+// sharesLinkedProfile reports whether both lists hold the same external
+// profile linked from the candidate's own profile; a profile matched by
+// name would match namesakes on both platforms alike
+func sharesLinkedProfile(a, b []ExternalProfile) bool {
+	for _, p := range a {
+		if p.MatchedBy != MatchedByLink || p.URL == "" {
+			continue
+		}
+		for _, q := range b {
+			if q.MatchedBy == MatchedByLink && q.Platform == p.Platform && q.URL == p.URL {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// platformUsername strips the platform MultiSource qualifies usernames with
+func platformUsername(username string) string {
+	if _, name, ok := strings.Cut(username, ":"); ok {
+		return name
+	}
+	return username
+}
+
+// linksTo reports whether text links to the profile at profileURL, e.g.
+// "gitlab.com/ana" but not "gitlab.com/anabel"
+func linksTo(text, profileURL string) bool {
+	target := NormalizeURL(profileURL)
+	if target == "" {
+		return false
+	}
+	for rest := strings.ToLower(text); ; {
+		i := strings.Index(rest, target)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(target):]
+		// The link ends where the username does
+		if r, _ := utf8.DecodeRuneInString(rest); rest == "" || !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return true
+		}
+	}
+}
+
+// NormalizeName lowercases name and reduces it to its words
+func NormalizeName(name string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// NormalizeURL reduces a URL to its host and path, e.g. gitlab.com/ana
+func NormalizeURL(u string) string {
+	u = strings.ToLower(strings.TrimSpace(u))
+	for _, prefix := range []string{"https://", "http://", "www."} {
+		u = strings.TrimPrefix(u, prefix)
+	}
+	return strings.TrimRight(u, "/")
+}
+
+// SharePlace reports whether two free-form locations name a place in
+// common, e.g. "Lima, Peru" and "Lima"
+func SharePlace(a, b string) bool {
+	places := func(s string) []string {
+		var out []string
+		for _, word := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return !unicode.IsLetter(r) }) {
+			if len(word) >= 3 {
+				out = append(out, word)
+			}
+		}
+		return out
+	}
+	other := places(b)
+	for _, place := range places(a) {
+		if slices.Contains(other, place) {
+			return true
+		}
+	}
+	return false
+}
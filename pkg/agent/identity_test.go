@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+func TestMergeConfidence(t *testing.T) {
+	ana := &EnrichedCandidate{Username: "ana", Name: "Ana Quispe", Location: "Lima, Peru", Platform: "github",
+		GitHubURL: "https://github.com/ana", Email: "ana@example.com", Blog: "https://ana.dev"}
+	tests := []struct {
+		name    string
+		other   EnrichedCandidate
+		want    float64
+		signals []string
+	}{
+		{"email", EnrichedCandidate{Username: "gitlab:aq", Email: "ANA@example.com"}, 0.95, []string{SignalEmail}},
+		{"link", EnrichedCandidate{Username: "gitlab:aq", Bio: "Also on github.com/ana."}, 0.95, []string{SignalLink}},
+		{"link to a longer username", EnrichedCandidate{Username: "gitlab:aq", Bio: "Fan of github.com/anabel"}, 0, nil},
+		{"website", EnrichedCandidate{Username: "gitlab:aq", Blog: "ana.dev/"}, 0.8, []string{SignalWebsite}},
+		{"name", EnrichedCandidate{Username: "gitlab:aq", Name: "ana quispe"}, 0.5, []string{SignalName}},
+		{"name and place", EnrichedCandidate{Username: "gitlab:aq", Name: "Ana Quispe", Location: "Lima"}, 0.6, []string{SignalName, SignalLocation}},
+		{"name, username and place", EnrichedCandidate{Username: "gitlab:ana", Name: "Ana Quispe", Location: "Lima"}, 0.76,
+			[]string{SignalName, SignalUsername, SignalLocation}},
+		{"place alone", EnrichedCandidate{Username: "gitlab:bob", Location: "Lima"}, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, signals := mergeConfidence(ana, &tt.other)
+			if got != tt.want || !slices.Equal(signals, tt.signals) {
+				t.Errorf("Expected %v %v, got %v %v", tt.want, tt.signals, got, signals)
+			}
+		})
+	}
+}
+
+func TestResolveIdentities(t *testing.T) {
+	candidates := []EnrichedCandidate{
+		{Username: "ana", Name: "Ana Quispe", Platform: "github", GitHubURL: "https://github.com/ana", Email: "ana@example.com",
+			PublicRepos: 10, SkillsFound: []string{"Go"}, InitialMatchScore: 0.5,
+			RelevantRepositories: []RelevantRepository{{Name: "gateway", URL: "https://github.com/ana/gateway"}}},
+		{Username: "bob", Name: "Bob", Platform: "github", GitHubURL: "https://github.com/bob"},
+		{Username: "gitlab:aquispe", Platform: "gitlab", GitHubURL: "https://gitlab.com/aquispe", Email: "ana@example.com",
+			Location: "Lima", PublicRepos: 4, SkillsFound: []string{"Go", "Kubernetes"}, InitialMatchScore: 0.7,
+			RelevantRepositories: []RelevantRepository{{Name: "operator", URL: "https://gitlab.com/aquispe/operator"}}},
+		// A second GitLab profile of the same email is not merged: one profile per platform
+		{Username: "gitlab:ana2", Platform: "gitlab", GitHubURL: "https://gitlab.com/ana2", Email: "ana@example.com"},
+		// Same username on a single platform run: never merged
+		{Username: "bob", Name: "Bob"},
+	}
+	var events []observability.ProfilesMerged
+	bus := observability.NewEventBus(func(e observability.Event) {
+		if merged, ok := e.(observability.ProfilesMerged); ok {
+			events = append(events, merged)
+		}
+	})
+
+	resolved, merged := resolveIdentities(candidates, DefaultMergeThreshold, bus)
+	if merged != 1 || len(resolved) != 4 {
+		t.Fatalf("Expected one merge leaving 4 candidates, got %d: %+v", merged, resolved)
+	}
+	ana := resolved[0]
+	if ana.Username != "ana" || ana.Location != "Lima" || ana.PublicRepos != 14 || ana.InitialMatchScore != 0.7 ||
+		!slices.Equal(ana.SkillsFound, []string{"Go", "Kubernetes"}) || len(ana.RelevantRepositories) != 2 {
+		t.Errorf("Expected the evidence combined, got %+v", ana)
+	}
+	if len(ana.Identities) != 2 || ana.Identities[1].Username != "gitlab:aquispe" || ana.Identities[1].Confidence != 0.95 || ana.MergeConfidence != 0.95 {
+		t.Errorf("Unexpected identities %+v", ana.Identities)
+	}
+	if len(events) != 1 || events[0].Merged != "gitlab:aquispe" {
+		t.Errorf("Expected a merge event, got %+v", events)
+	}
+
+	// The merged identities survive a fallback ranking
+	result := createFallbackResult(&EnrichedCandidates{Candidates: resolved}, 10)
+	for _, c := range result.TopCandidates {
+		if c.Username == "ana" && (len(c.Identities) != 2 || c.TopRelevantProjects[1].URL != "https://gitlab.com/aquispe/operator") {
+			t.Errorf("Expected the identities and project URLs kept, got %+v", c)
+		}
+	}
+}
+
+// namedSource serves one developer, named after the platform
+type namedSource string
+
+func (s namedSource) SearchDevelopers(context.Context, github.ToolInput) (*github.SearchResult, error) {
+	return &github.SearchResult{Candidates: []github.Candidate{{Username: "ana"}}, TotalFound: 1}, nil
+}
+
+func (s namedSource) GetUserDetail(_ context.Context, username string) (*github.UserDetail, error) {
+	return &github.UserDetail{Login: username, HTMLURL: "https://" + string(s) + ".example/" + username}, nil
+}
+
+func (s namedSource) GetRepositories(_ context.Context, username string, _ int) ([]github.Repository, error) {
+	return []github.Repository{{Name: string(s) + "-" + username}}, nil
+}
+
+// failingSource fails every search
+type failingSource struct{ namedSource }
+
+func (failingSource) SearchDevelopers(context.Context, github.ToolInput) (*github.SearchResult, error) {
+	return nil, errors.New("service unavailable")
+}
+
+func TestMultiSource(t *testing.T) {
+	source := MultiSource{{Name: "github", SourceProvider: namedSource("github")}, {Name: "gitlab", SourceProvider: namedSource("gitlab")}}
+	result, err := source.SearchDevelopers(context.Background(), github.ToolInput{Language: "Go"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.Candidates) != 2 || result.TotalFound != 2 ||
+		result.Candidates[0].Username != "ana" || result.Candidates[0].Platform != "github" ||
+		result.Candidates[1].Username != "gitlab:ana" || result.Candidates[1].Platform != "gitlab" {
+		t.Fatalf("Unexpected candidates %+v", result.Candidates)
+	}
+
+	detail, _ := source.GetUserDetail(context.Background(), "gitlab:ana")
+	if detail.Login != "gitlab:ana" || detail.HTMLURL != "https://gitlab.example/ana" {
+		t.Errorf("Expected the GitLab profile under its qualified username, got %+v", detail)
+	}
+	for username, want := range map[string]string{"ana": "github-ana", "gitlab:ana": "gitlab-ana", "bitbucket:ana": "github-bitbucket:ana"} {
+		if repos, _ := source.GetRepositories(context.Background(), username, 5); repos[0].Name != want {
+			t.Errorf("Expected %s routed to %s, got %+v", username, want, repos)
+		}
+	}
+
+	// A platform failing leaves the others' developers
+	source[1].SourceProvider = failingSource{}
+	result, err = source.SearchDevelopers(context.Background(), github.ToolInput{Language: "Go"})
+	if err != nil || len(result.Candidates) != 1 || result.Candidates[0].Platform != "github" {
+		t.Errorf("Expected the GitHub developer only, got %+v, %v", result, err)
+	}
+	source[0].SourceProvider = failingSource{}
+	if _, err := source.SearchDevelopers(context.Background(), github.ToolInput{Language: "Go"}); err == nil {
+		t.Error("Expected an error when every platform fails")
+	}
+}
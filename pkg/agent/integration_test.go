@@ -55,14 +55,14 @@ func TestConsistency(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			// Run Query A
 			t.Logf("Running Query A: %s", tc.queryA)
-			resultA, err := RunStage2(vertexClient, githubClient, tc.queryA)
+			resultA, err := RunStage2(context.Background(), vertexClient, githubClient, tc.queryA)
 			if err != nil {
 				t.Fatalf("Query A failed: %v", err)
 			}
 
 			// Run Query B
 			t.Logf("Running Query B: %s", tc.queryB)
-			resultB, err := RunStage2(vertexClient, githubClient, tc.queryB)
+			resultB, err := RunStage2(context.Background(), vertexClient, githubClient, tc.queryB)
 			if err != nil {
 				t.Fatalf("Query B failed: %v", err)
 			}
@@ -0,0 +1,45 @@
+package agent
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Languages maps the codes AgentConfig.Language accepts to the language the
+// ranking prompt asks for
+var Languages = map[string]string{
+	"de":    "German",
+	"en":    "English",
+	"es":    "Spanish",
+	"fr":    "French",
+	"hi":    "Hindi",
+	"it":    "Italian",
+	"ja":    "Japanese",
+	"ko":    "Korean",
+	"nl":    "Dutch",
+	"pl":    "Polish",
+	"pt":    "Portuguese",
+	"pt-BR": "Brazilian Portuguese",
+	"tr":    "Turkish",
+	"uk":    "Ukrainian",
+	"zh":    "Chinese",
+}
+
+// LanguageName returns the language of code, matched case-insensitively.
+// English, the language of the prompts, and an empty code return "".
+func LanguageName(code string) (string, error) {
+	if code == "" {
+		return "", nil
+	}
+	for c, name := range Languages {
+		if strings.EqualFold(c, code) {
+			if c == "en" {
+				return "", nil
+			}
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("unknown language %q: want one of %s", code, strings.Join(slices.Sorted(maps.Keys(Languages)), ", "))
+}
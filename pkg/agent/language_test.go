@@ -0,0 +1,30 @@
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestLanguageName(t *testing.T) {
+	tests := map[string]string{"": "", "en": "", "es": "Spanish", "PT-br": "Brazilian Portuguese"}
+	for code, want := range tests {
+		if got, err := LanguageName(code); err != nil || got != want {
+			t.Errorf("LanguageName(%q): expected %q, got %q, %v", code, want, got, err)
+		}
+	}
+	if _, err := LanguageName("spanish"); err == nil {
+		t.Error("Expected an error for an unknown code")
+	}
+}
+
+func TestRunStage2UnknownLanguage(t *testing.T) {
+	client := llm.ClientFunc(func(context.Context, []llm.Message, []llm.Tool, ...llm.CallOption) (*llm.Response, error) {
+		t.Fatal("Expected no LLM call")
+		return nil, nil
+	})
+	if _, err := RunStage2WithConfig(context.Background(), client, nil, "Find Go developers", AgentConfig{Language: "xx"}); err == nil {
+		t.Error("Expected an error for an unknown language")
+	}
+}
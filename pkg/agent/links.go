@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ProfileLinks finds the user of a platform profile linked from a
+// candidate's blog or bio, for enrichers that only look up linked profiles
+type ProfileLinks struct {
+	// Pattern matches a link to the platform, capturing its path
+	Pattern *regexp.Regexp
+	// Sections are first path segments the user follows, e.g. datasets in
+	// /datasets/ana/quechua
+	Sections []string
+	// Reserved are first path segments that are not users, e.g. login
+	Reserved []string
+}
+
+// User returns the user of the first link in texts, skipping links to
+// reserved pages
+func (l ProfileLinks) User(texts ...string) (string, bool) {
+	for _, text := range texts {
+		for _, m := range l.Pattern.FindAllStringSubmatch(text, -1) {
+			segments := strings.Split(strings.Trim(m[1], "/."), "/")
+			if slices.Contains(l.Sections, segments[0]) {
+				segments = segments[1:]
+			}
+			if len(segments) > 0 && segments[0] != "" && !slices.Contains(l.Reserved, segments[0]) {
+				return segments[0], true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,29 @@
+package agent
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestProfileLinksUser(t *testing.T) {
+	links := ProfileLinks{
+		Pattern:  regexp.MustCompile(`(?i)example\.com/([\w./-]+)`),
+		Sections: []string{"code"},
+		Reserved: []string{"login", "code"},
+	}
+	tests := []struct{ text, want string }{
+		{"https://example.com/ana", "ana"},
+		{"See https://EXAMPLE.com/ana/notebook.", "ana"},
+		{"https://example.com/code/ana/baseline", "ana"},
+		{"https://example.com/login and example.com/bob", "bob"},
+		{"https://example.com/code", ""},
+		{"https://example.com/code/login", ""},
+		{"no links", ""},
+	}
+	for _, tt := range tests {
+		got, ok := links.User(tt.text)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Expected %q linked from %q, got %q (%v)", tt.want, tt.text, got, ok)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package agent
+
+import (
+	"math"
+	"strings"
+)
+
+// Metrics of ExternalProfile counting published machine learning work, which
+// MLExperienceScore rates
+const (
+	MetricModels    = "models"    // Models published, e.g. on Hugging Face
+	MetricDatasets  = "datasets"  // Datasets published
+	MetricNotebooks = "notebooks" // Public notebooks, e.g. Kaggle code
+	MetricDownloads = "downloads" // Downloads of the models and datasets
+	MetricLikes     = "likes"     // Likes or votes the work received
+)
+
+// mlTerms mark a requirement as machine learning work. Short terms must match
+// a whole requirement, so "ml" does not match "html".
+var (
+	mlTerms = []string{
+		"machine learning", "deep learning", "reinforcement learning", "computer vision",
+		"natural language processing", "data science", "pytorch", "tensorflow", "keras",
+		"scikit-learn", "transformers", "hugging face", "huggingface", "kaggle", "mlops",
+		"neural network", "large language model", "fine-tuning",
+	}
+	mlExactTerms = []string{"ml", "ai", "nlp", "llm", "llms", "jax", "xgboost", "sklearn"}
+)
+
+// IsMLRole reports whether requirements ask for machine learning experience
+func IsMLRole(requirements *Requirements) bool {
+	if requirements == nil {
+		return false
+	}
+	for _, list := range [][]string{requirements.RequiredSkills, requirements.Keywords, requirements.NiceToHave} {
+		for _, item := range list {
+			item = strings.ToLower(strings.TrimSpace(item))
+			for _, term := range mlExactTerms {
+				if item == term {
+					return true
+				}
+			}
+			for _, term := range mlTerms {
+				if strings.Contains(item, term) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// MLExperienceScore rates the published machine learning work of profiles
+// from 0 to 100. Each metric counts logarithmically up to a cap, so a few
+// well-used models outweigh many unused ones, and each profile counts in
+// proportion to its confidence. Profiles below 0.5 confidence are ignored,
+// as the ranking ignores them.
+func MLExperienceScore(profiles []ExternalProfile) float64 {
+	var score float64
+	for _, p := range profiles {
+		if p.Confidence < 0.5 || len(p.Metrics) == 0 {
+			continue
+		}
+		points := func(metric string, weight, limit float64, log func(float64) float64) float64 {
+			return min(limit, weight*log(1+float64(p.Metrics[metric])))
+		}
+		score += p.Confidence * (points(MetricModels, 10, 30, math.Log2) +
+			points(MetricDatasets, 6, 15, math.Log2) +
+			points(MetricNotebooks, 5, 15, math.Log2) +
+			points(MetricDownloads, 5, 25, math.Log10) +
+			points(MetricLikes, 3, 15, math.Log2))
+	}
+	return math.Round(min(score, 100))
+}
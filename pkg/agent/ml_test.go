@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"context"
+	"testing"
+)
+
+func TestIsMLRole(t *testing.T) {
+	tests := []struct {
+		name         string
+		requirements *Requirements
+		want         bool
+	}{
+		{"nil", nil, false},
+		{"backend", &Requirements{RequiredSkills: []string{"Go", "HTML"}, Keywords: []string{"microservices"}}, false},
+		{"skill", &Requirements{RequiredSkills: []string{"Python", "PyTorch"}}, true},
+		{"keyword", &Requirements{Keywords: []string{"Machine Learning infrastructure"}}, true},
+		{"short term", &Requirements{NiceToHave: []string{"ML"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMLRole(tt.requirements); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMLExperienceScore(t *testing.T) {
+	prolific := ExternalProfile{Confidence: 1, Metrics: map[string]int{
+		MetricModels: 20, MetricDatasets: 10, MetricNotebooks: 30, MetricDownloads: 1_000_000, MetricLikes: 500}}
+	if got := MLExperienceScore([]ExternalProfile{prolific}); got != 100 {
+		t.Errorf("Expected a prolific author to score 100, got %v", got)
+	}
+
+	// One model with 1,000 downloads: 10 + 5*log10(1001) + 3*log2(4)
+	one := ExternalProfile{Confidence: 1, Metrics: map[string]int{MetricModels: 1, MetricDownloads: 1000, MetricLikes: 3}}
+	if got := MLExperienceScore([]ExternalProfile{one}); got != 31 {
+		t.Errorf("Expected 31, got %v", got)
+	}
+	one.Confidence = 0.5
+	if got := MLExperienceScore([]ExternalProfile{one}); got != 16 {
+		t.Errorf("Expected the score halved at half confidence, got %v", got)
+	}
+	one.Confidence = 0.4
+	if got := MLExperienceScore([]ExternalProfile{one, {Confidence: 1, Reputation: 9000}}); got != 0 {
+		t.Errorf("Expected unconfident and metricless profiles ignored, got %v", got)
+	}
+}
+
+// mlEnricher adds a Hugging Face profile, for machine learning roles only
+type mlEnricher struct{}
+
+func (mlEnricher) Name() string { return "huggingface" }
+
+func (mlEnricher) AppliesTo(requirements *Requirements) bool { return IsMLRole(requirements) }
+
+func (mlEnricher) Enrich(_ context.Context, candidate *EnrichedCandidate) error {
+	candidate.ExternalProfiles = append(candidate.ExternalProfiles, ExternalProfile{
+		Platform: "huggingface", Confidence: 1, Metrics: map[string]int{MetricModels: 1}})
+	return nil
+}
+
+func TestRunEnrichersForRole(t *testing.T) {
+	backend := &EnrichedCandidate{}
+	runEnrichers(context.Background(), []Enricher{mlEnricher{}}, &Requirements{RequiredSkills: []string{"Go"}}, backend, nil)
+	if len(backend.ExternalProfiles) != 0 || backend.MLExperienceScore != nil {
+		t.Errorf("Expected no lookup for a backend role, got %+v", backend)
+	}
+
+	ml := &EnrichedCandidate{}
+	runEnrichers(context.Background(), []Enricher{mlEnricher{}}, &Requirements{RequiredSkills: []string{"PyTorch"}}, ml, nil)
+	if len(ml.ExternalProfiles) != 1 || ml.MLExperienceScore == nil || *ml.MLExperienceScore != 10 {
+		t.Errorf("Expected the profile scored for a machine learning role, got %+v", ml)
+	}
+}
@@ -0,0 +1,243 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"github.com/luillyfe/sourcing-agent/pkg/vertexai"
+)
+
+// goldenLLMClient replays testdata/<name>.json. Run with LLM_RECORD=1 (and
+// Vertex credentials) to re-record the golden file against the real provider.
+func goldenLLMClient(t *testing.T, name string) llm.Client {
+	t.Helper()
+	path := "testdata/" + name + ".json"
+
+	if os.Getenv("LLM_RECORD") != "1" {
+		client, err := llm.NewGoldenClient(nil, path, false)
+		if err != nil {
+			t.Fatalf("Failed to load golden file: %v", err)
+		}
+		return client
+	}
+
+	_ = godotenv.Load("../../.env")
+	vertexClient, err := vertexai.NewClient(context.Background(), os.Getenv("VERTEX_PROJECT_ID"), os.Getenv("VERTEX_REGION"))
+	if err != nil {
+		t.Fatalf("Failed to create Vertex client for recording: %v", err)
+	}
+	client, err := llm.NewGoldenClient(vertexClient, path, true)
+	if err != nil {
+		t.Fatalf("Failed to create recording client: %v", err)
+	}
+	return client
+}
+
+// newPipelineGitHubServer serves a small, fixed GitHub dataset for end-to-end runs
+func newPipelineGitHubServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/users", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"total_count": 2, "items": [
+			{"login": "gopher_lima", "html_url": "https://github.com/gopher_lima"},
+			{"login": "rustacean_pe", "html_url": "https://github.com/rustacean_pe"}
+		]}`))
+	})
+	mux.HandleFunc("/users/gopher_lima", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login": "gopher_lima", "name": "Ana Quispe", "location": "Lima, Peru",
+			"bio": "Backend engineer. Go, gRPC and Kubernetes.", "public_repos": 32, "followers": 140,
+			"html_url": "https://github.com/gopher_lima"}`))
+	})
+	mux.HandleFunc("/users/gopher_lima/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"name": "grpc-gateway-kit", "description": "Microservices toolkit for Go backends", "language": "Go",
+			 "stargazers_count": 210, "topics": ["backend", "grpc"], "html_url": "https://github.com/gopher_lima/grpc-gateway-kit"},
+			{"name": "dotfiles", "description": "My config", "language": "Shell", "stargazers_count": 2}
+		]`))
+	})
+	mux.HandleFunc("/users/rustacean_pe", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login": "rustacean_pe", "name": "Luis Mamani", "location": "Lima",
+			"company": "@andes-labs, @rust-peru", "bio": "Systems programmer", "public_repos": 12, "followers": 30,
+			"html_url": "https://github.com/rustacean_pe"}`))
+	})
+	mux.HandleFunc("/users/rustacean_pe/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"name": "tiny-kv", "description": "Key value store", "language": "Go", "stargazers_count": 12}
+		]`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRunStage2Golden(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+	client := goldenLLMClient(t, "stage2_go_lima")
+
+	result, err := RunStage2(context.Background(), client, githubClient, "Find senior Go backend developers in Lima")
+	if err != nil {
+		t.Fatalf("RunStage2 failed: %v", err)
+	}
+
+	if len(result.TopCandidates) != 2 {
+		t.Fatalf("Expected 2 ranked candidates, got %d", len(result.TopCandidates))
+	}
+	top := result.TopCandidates[0]
+	if top.Username != "gopher_lima" || top.Rank != 1 {
+		t.Errorf("Expected gopher_lima ranked first, got %s at rank %d", top.Username, top.Rank)
+	}
+	if top.FinalMatchScore <= result.TopCandidates[1].FinalMatchScore {
+		t.Errorf("Expected candidates sorted by score, got %.1f then %.1f",
+			top.FinalMatchScore, result.TopCandidates[1].FinalMatchScore)
+	}
+	if result.RunID == "" {
+		t.Error("Expected a generated run ID on the result")
+	}
+	if !strings.Contains(result.Summary.SearchQuality, "good") {
+		t.Errorf("Expected recorded search quality, got %q", result.Summary.SearchQuality)
+	}
+
+	timings := result.Timings
+	if timings == nil || len(timings.Stages) != 4 || timings.Stages[2].Stage != StageEnrichment {
+		t.Fatalf("Expected timings for the 4 stages, got %+v", timings)
+	}
+	if len(timings.Calls) != 2 || timings.Calls[0].Category != CallCategoryLLM || timings.Calls[0].Calls != 3 ||
+		timings.Calls[1].Category != CallCategoryGitHub || timings.Calls[1].Calls == 0 {
+		t.Errorf("Expected 3 timed LLM calls and some GitHub calls, got %+v", timings.Calls)
+	}
+	if _, ok := githubClient.HTTPClient.Transport.(*timedTransport); ok {
+		t.Error("Expected the caller's GitHub client left untouched")
+	}
+}
+
+func TestRunStage2RunID(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+
+	var logs bytes.Buffer
+	githubLogs := &bytes.Buffer{}
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client(),
+		Logger: slog.New(slog.NewTextHandler(githubLogs, &slog.HandlerOptions{Level: slog.LevelDebug}))}
+	config := AgentConfig{RunID: "run-42", Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+
+	result, err := RunStage2WithConfig(context.Background(), goldenLLMClient(t, "stage2_go_lima"), githubClient,
+		"Find senior Go backend developers in Lima", config)
+	if err != nil {
+		t.Fatalf("RunStage2WithConfig failed: %v", err)
+	}
+
+	if result.RunID != "run-42" {
+		t.Errorf("Expected run ID run-42 on the result, got %q", result.RunID)
+	}
+	for name, buf := range map[string]*bytes.Buffer{"agent": &logs, "github": githubLogs} {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		for _, line := range lines {
+			if !strings.Contains(line, "run_id=run-42") {
+				t.Errorf("Expected every %s log line tagged with the run ID, got %q", name, line)
+			}
+		}
+	}
+}
+
+func TestNewRunIDUnique(t *testing.T) {
+	if a, b := NewRunID(), NewRunID(); a == b || len(a) != len(b) {
+		t.Errorf("Expected distinct run IDs of equal length, got %q and %q", a, b)
+	}
+}
+
+func TestRunStage2FailureSnapshot(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+
+	golden := goldenLLMClient(t, "stage2_go_lima")
+	client := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		if llm.ApplyOptions(opts).Stage == StageRanking {
+			return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: "Sorry, I ran out of room"}}}, nil
+		}
+		return golden.CallAPI(ctx, messages, tools, opts...)
+	})
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+	dir := t.TempDir()
+	config := AgentConfig{RunID: "run-9", FailureDumpDir: dir, Logger: slog.New(slog.DiscardHandler)}
+
+	result, err := RunStage2WithConfig(context.Background(), client, githubClient, "Find senior Go backend developers in Lima", config)
+	if err != nil {
+		t.Fatalf("Expected the run to fall back, got %v", err)
+	}
+	if len(result.TopCandidates) == 0 {
+		t.Error("Expected unranked fallback candidates")
+	}
+
+	dumps, _ := filepath.Glob(filepath.Join(dir, "*-ranking-run-9"))
+	if len(dumps) != 1 {
+		t.Fatalf("Expected one ranking failure snapshot, got %v", dumps)
+	}
+	data, err := os.ReadFile(filepath.Join(dumps[0], "snapshot.json"))
+	if err != nil {
+		t.Fatalf("Expected snapshot.json, got %v", err)
+	}
+	var snapshot FailureSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("Expected JSON snapshot, got %v", err)
+	}
+	if snapshot.ErrorClass != observability.ErrorClassLLMParse || snapshot.ModelOutput != "Sorry, I ran out of room" {
+		t.Errorf("Expected the unparseable ranking output captured, got %+v", snapshot)
+	}
+	if snapshot.Requirements == nil || snapshot.Strategy == nil || snapshot.Candidates == nil || len(snapshot.Candidates.Candidates) == 0 {
+		t.Errorf("Expected stage inputs captured, got %+v", snapshot)
+	}
+	if output, _ := os.ReadFile(filepath.Join(dumps[0], "model_output.txt")); string(output) != "Sorry, I ran out of room" {
+		t.Errorf("Expected raw model output dumped, got %q", output)
+	}
+}
+
+func TestRunStage2Budget(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+	config := AgentConfig{MaxLLMCalls: 2, Logger: slog.New(slog.DiscardHandler)}
+
+	// The budget covers requirements and strategy, so ranking falls back
+	result, err := RunStage2WithConfig(context.Background(), goldenLLMClient(t, "stage2_go_lima"), githubClient,
+		"Find senior Go backend developers in Lima", config)
+	if err != nil {
+		t.Fatalf("Expected the run to fall back, got %v", err)
+	}
+	if !result.Partial || len(result.TopCandidates) != 2 {
+		t.Errorf("Expected unranked candidates once the budget ran out, got %+v", result)
+	}
+
+	config.MaxLLMCalls = 1
+	_, err = RunStage2WithConfig(context.Background(), goldenLLMClient(t, "stage2_go_lima"), githubClient,
+		"Find senior Go backend developers in Lima", config)
+	if !errors.Is(err, llm.ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded from the strategy stage, got %v", err)
+	}
+}
+
+func TestRunStage2Timeout(t *testing.T) {
+	hung := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	config := AgentConfig{Timeout: 20 * time.Millisecond, Logger: slog.New(slog.DiscardHandler)}
+	_, err := RunStage2WithConfig(context.Background(), llm.WithRetry(hung, llm.RetryConfig{}), nil, "Find Go developers", config)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the run's deadline to stop it, got %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// prettyWidth is the line width pretty output wraps prose at
+const prettyWidth = 88
+
+// prettyMaxProjects caps the key repositories shown per candidate
+const prettyMaxProjects = 3
+
+// ANSI styles used by colored pretty output
+const (
+	ansiReset  = "\033[0m"
+	ansiBold   = "\033[1m"
+	ansiDim    = "\033[2m"
+	ansiRed    = "\033[31m"
+	ansiGreen  = "\033[32m"
+	ansiYellow = "\033[33m"
+	ansiCyan   = "\033[36m"
+)
+
+// WritePretty writes result as ranked candidate cards for reading in a
+// terminal, with ANSI colors if color is set
+func WritePretty(w io.Writer, result *FinalResult, color bool) error {
+	style := func(code, s string) string {
+		if !color || s == "" {
+			return s
+		}
+		return code + s + ansiReset
+	}
+
+	var b strings.Builder
+	summary := result.Summary
+	fmt.Fprintf(&b, "%s\n", style(ansiBold, fmt.Sprintf("%d candidates presented, %d found, average score %.1f",
+		len(result.TopCandidates), summary.TotalCandidatesFound, summary.AverageMatchScore)))
+	if summary.SearchQuality != "" {
+		b.WriteString(wrapText("Search quality: "+summary.SearchQuality, prettyWidth, ""))
+	}
+	if result.Partial {
+		b.WriteString(style(ansiYellow, "Ranking failed: candidates are ordered by their initial keyword match") + "\n")
+	}
+
+	for _, c := range result.TopCandidates {
+		b.WriteString("\n")
+		title := "@" + c.Username
+		if c.Name != "" {
+			title = c.Name + " (@" + c.Username + ")"
+		}
+		header := fmt.Sprintf("#%d  %s", c.Rank, style(ansiBold, title))
+		if c.Location != "" {
+			header += style(ansiDim, "  ·  "+c.Location)
+		}
+		fmt.Fprintln(&b, header)
+		if c.SeenBefore != nil {
+			fmt.Fprintf(&b, "    %s\n", style(ansiYellow, "Already "+SeenBeforeNote(c.SeenBefore)))
+		}
+
+		scoreStyle := ansiRed
+		switch {
+		case c.FinalMatchScore >= 80:
+			scoreStyle = ansiGreen
+		case c.FinalMatchScore >= 60:
+			scoreStyle = ansiYellow
+		}
+		fmt.Fprintf(&b, "    %s %s\n", style(scoreStyle, scoreBar(c.FinalMatchScore, 20)), style(ansiBold, fmt.Sprintf("%.1f", c.FinalMatchScore)))
+		bd := c.MatchBreakdown
+		fmt.Fprintf(&b, "    %s\n", style(ansiDim, fmt.Sprintf("skills %.2f · repositories %.2f · experience %.2f · profile %.2f",
+			bd.RequiredSkillsScore, bd.RepositoryRelevanceScore, bd.ExperienceScore, bd.ProfileQualityScore)))
+		if len(c.KeyQualifications) > 0 {
+			b.WriteString(wrapText(strings.Join(c.KeyQualifications, ", "), prettyWidth, "    "))
+		}
+		if c.MatchReasoning != "" {
+			b.WriteString(wrapText(c.MatchReasoning, prettyWidth, "    "))
+		}
+		for i, p := range c.TopRelevantProjects {
+			if i == prettyMaxProjects {
+				fmt.Fprintf(&b, "      %s\n", style(ansiDim, fmt.Sprintf("and %d more", len(c.TopRelevantProjects)-i)))
+				break
+			}
+			line := "    ▸ " + style(ansiBold, p.Name)
+			if p.URL != "" {
+				line += "  " + style(ansiCyan, p.URL)
+			}
+			fmt.Fprintln(&b, line)
+			if p.WhyRelevant != "" {
+				b.WriteString(style(ansiDim, strings.TrimSuffix(wrapText(p.WhyRelevant, prettyWidth, "      "), "\n")) + "\n")
+			}
+		}
+		if c.PotentialConcerns != "" {
+			b.WriteString(style(ansiYellow, strings.TrimSuffix(wrapText("Concerns: "+c.PotentialConcerns, prettyWidth, "    "), "\n")) + "\n")
+		}
+		if c.GitHubURL != "" {
+			fmt.Fprintf(&b, "    %s\n", style(ansiCyan, c.GitHubURL))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// scoreBar renders a 0-100 score as a bar of width cells
+func scoreBar(score float64, width int) string {
+	filled := int(score/100*float64(width) + 0.5)
+	filled = max(0, min(width, filled))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
+}
+
+// wrapText word-wraps s to width, prefixing every line with indent
+func wrapText(s string, width int, indent string) string {
+	var b strings.Builder
+	line := indent
+	for _, word := range strings.Fields(s) {
+		if line != indent && len(line)+1+len(word) > width {
+			b.WriteString(line + "\n")
+			line = indent
+		}
+		if line != indent {
+			line += " "
+		}
+		line += word
+	}
+	if line != indent {
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
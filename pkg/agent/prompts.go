@@ -1,54 +1,32 @@
 package agent
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"sort"
 	"strings"
 
 	"github.com/luillyfe/sourcing-agent/pkg/github"
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+	"github.com/luillyfe/sourcing-agent/pkg/prompts"
 )
 
 // analyzeRequirements (Prompt 1)
-func analyzeRequirements(client llm.Client, userQuery string) (*Requirements, *llm.Usage, error) {
-	systemPrompt := `You are a requirements analyzer for technical recruiting.
-
-Your task: Parse the user's hiring request into structured requirements.
-
-Extract:
-1. Required skills (programming languages, frameworks, technologies)
-2. Experience level (junior, mid, senior, lead)
-3. Location requirements (city, country, region, remote)
-4. Keywords for relevance matching
-5. Nice-to-have skills (optional qualifications)
-
-Output Format (JSON):
-{
-  "required_skills": ["skill1", "skill2"],
-  "experience_level": "senior|mid|junior|lead",
-  "locations": ["location1", "location2"],
-  "keywords": ["keyword1", "keyword2"],
-  "nice_to_have": ["skill3", "skill4"],
-  "unclear_request": false,
-  "clarification_question": "string (only if unclear)"
-}
-
-Be specific and extract all relevant information from the query.
-If the query is too vague (e.g., "find developers", "search github"), set "unclear_request" to true and ask a specific clarification question.`
+func analyzeRequirements(ctx context.Context, client llm.Client, userQuery string) (*Requirements, *llm.Usage, error) {
+	systemPrompt, err := prompts.Render(prompts.Requirements, prompts.Data{})
+	if err != nil {
+		return nil, nil, err
+	}
 
 	messages := []llm.Message{
-		{
-			Role:    "system",
-			Content: systemPrompt,
-		},
-		{
-			Role:    "user",
-			Content: fmt.Sprintf("User query: %s", userQuery),
-		},
+		llm.SystemText(systemPrompt),
+		llm.UserText(fmt.Sprintf("User query: %s", userQuery)),
 	}
 
-	resp, err := client.CallAPI(messages, nil)
+	resp, err := client.CallAPI(ctx, messages, nil, llm.WithStage(StageRequirements), llm.WithResponseSchema(requirementsSchema))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to call LLM: %w", err)
 	}
@@ -61,102 +39,34 @@ If the query is too vague (e.g., "find developers", "search github"), set "uncle
 	}
 
 	// Extract JSON from content (in case of markdown code blocks)
-	jsonStr := extractJSON(content)
+	jsonStr := llm.ExtractJSON(content)
 
 	var requirements Requirements
 	if err := json.Unmarshal([]byte(jsonStr), &requirements); err != nil {
-		return nil, &resp.Usage, fmt.Errorf("failed to parse requirements JSON: %w", err)
+		return nil, &resp.Usage, fmt.Errorf("failed to parse requirements JSON: %w", &llm.DecodeError{Content: content, Err: err})
 	}
 
 	if err := requirements.Validate(); err != nil {
-		return nil, &resp.Usage, fmt.Errorf("invalid requirements: %w", err)
+		return nil, &resp.Usage, observability.WithErrorClass(observability.ErrorClassValidation, fmt.Errorf("invalid requirements: %w", err))
 	}
 
 	return &requirements, &resp.Usage, nil
 }
 
 // generateSearchStrategy (Prompt 2)
-func generateSearchStrategy(client llm.Client, requirements *Requirements) (*SearchStrategy, *llm.Usage, error) {
-	systemPrompt := `You are a search strategy expert for GitHub developer sourcing.
-
-## Available Search Capabilities
-
-The system can search GitHub using these parameters:
-
-**User Search (primary)**
-- language: programming language (inferred from user's repos)
-- location: matches user's profile location field (freeform text, inconsistent)
-- followers: minimum follower count (e.g., ">10", ">100")
-
-**Repository Search (secondary)**
-- keywords: searches repo names, descriptions, and READMEs
-- stars: minimum star count
-- language: exact match on repo primary language
-
-**Post-Search Filtering (applied locally after fetching results)**
-- min_repos: minimum public repository count
-- bio_keywords: substring match against user bio
-- recent_activity_days: only users with commits within N days
-
-## Limitations
-
-- Cannot search by years of experience directly
-- Location is unreliable (~40% of users have it filled, format varies)
-- Language filter only works if user has public repos in that language
-- GitHub API rate limits: prefer precise queries over broad ones
-
-## Your Task
-
-Given structured job requirements, generate an optimal search strategy:
-
-1. Create a primary search (most specific, highest signal)
-2. Create fallback searches (progressively broader for when primary yields few results)
-3. Configure repository search to find users via their project work
-4. Set post-filters to refine results locally
-5. Plan for low/no results scenario in your fallbacks
-
-## Output Format (JSON)
-
-{
-  "primary_search": {
-    "language": "string",
-    "location": "string", 
-    "followers": "string (e.g., '>10') or null"
-  },
-  "fallback_searches": [
-    {
-      "language": "string",
-      "location": "string or null (broader)",
-      "followers": "string or null",
-      "rationale": "string (why this fallback)"
-    }
-  ],
-  "repository_search": {
-    "keywords": ["keyword1", "keyword2"],
-    "min_stars": "number or null",
-    "language": "string"
-  },
-  "post_filters": {
-    "min_repos": "number",
-    "bio_keywords": ["keyword1", "keyword2"],
-    "recent_activity_days": "number or null"
-  },
-  "strategy_notes": "string (brief explanation of your approach)"
-}`
+func generateSearchStrategy(ctx context.Context, client llm.Client, requirements *Requirements) (*SearchStrategy, *llm.Usage, error) {
+	systemPrompt, err := prompts.Render(prompts.Strategy, prompts.Data{})
+	if err != nil {
+		return nil, nil, err
+	}
 
 	reqJSON, _ := json.Marshal(requirements)
 	messages := []llm.Message{
-		{
-			Role:    "system",
-			Content: systemPrompt,
-		},
-		{
-			Role:    "user",
-			Content: fmt.Sprintf("Requirements: %s", string(reqJSON)),
-		},
+		llm.SystemText(systemPrompt),
+		llm.UserText(fmt.Sprintf("Requirements: %s", string(reqJSON))),
 	}
 
-	resp, err := client.CallAPI(messages, nil)
+	resp, err := client.CallAPI(ctx, messages, nil, llm.WithStage(StageStrategy), llm.WithResponseSchema(strategySchema))
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to call LLM: %w", err)
 	}
@@ -168,22 +78,22 @@ Given structured job requirements, generate an optimal search strategy:
 		}
 	}
 
-	jsonStr := extractJSON(content)
+	jsonStr := llm.ExtractJSON(content)
 
 	var strategy SearchStrategy
 	if err := json.Unmarshal([]byte(jsonStr), &strategy); err != nil {
-		return nil, &resp.Usage, fmt.Errorf("failed to parse strategy JSON: %w", err)
+		return nil, &resp.Usage, fmt.Errorf("failed to parse strategy JSON: %w", &llm.DecodeError{Content: content, Err: err})
 	}
 
 	if err := strategy.Validate(); err != nil {
-		return nil, &resp.Usage, fmt.Errorf("invalid strategy: %w", err)
+		return nil, &resp.Usage, observability.WithErrorClass(observability.ErrorClassValidation, fmt.Errorf("invalid strategy: %w", err))
 	}
 
 	return &strategy, &resp.Usage, nil
 }
 
 // findAndEnrichCandidates (Prompt 3)
-func findAndEnrichCandidates(client llm.Client, githubClient *github.Client, strategy *SearchStrategy, requirements *Requirements) (*EnrichedCandidates, error) {
+func findAndEnrichCandidates(ctx context.Context, client llm.Client, source SourceProvider, strategy *SearchStrategy, requirements *Requirements, events *observability.EventBus, config AgentConfig) (*EnrichedCandidates, error) {
 	// 1. Execute primary search
 	// Note: We are NOT using the LLM to call the tool here as per the "Programmatic" flow in the spec example,
 	// BUT the spec says "Prompt 3: Candidate Finder & Enricher... This prompt has tool access".
@@ -265,7 +175,7 @@ func findAndEnrichCandidates(client llm.Client, githubClient *github.Client, str
 	// Actually, the output of Prompt 3 is `EnrichedCandidates` JSON.
 	// I can generate that programmatically.
 	//
-	// So, `findAndEnrichCandidates` will NOT call the LLM. It will use the `githubClient` and the `analyzeRepositoryRelevance` function.
+	// So, `findAndEnrichCandidates` will NOT call the LLM. It will use the `source` and the `analyzeRepositoryRelevance` function.
 	// This seems like a smart deviation/optimization.
 	//
 	// Let's verify if this is acceptable.
@@ -281,32 +191,39 @@ func findAndEnrichCandidates(client llm.Client, githubClient *github.Client, str
 		Language:   strategy.PrimarySearch.Language,
 		Location:   strategy.PrimarySearch.Location,
 		MinRepos:   strategy.PostFilters.MinRepos,
-		MaxResults: 15, // Aim for 15-20 as per spec
+		MaxResults: config.maxSearchResults(),
 	}
 	if len(strategy.RepositorySearch.Keywords) > 0 {
 		input.Keywords = strings.Join(strategy.RepositorySearch.Keywords, " ")
 	}
 
-	result, err := githubClient.SearchDevelopers(input)
+	result, err := source.SearchDevelopers(ctx, input)
+	publishSearch(events, input, 0, result, err)
 	if err != nil || (result != nil && len(result.Candidates) == 0) {
 		// Try fallback
 		// Try fallback strategies
 		for i, fallback := range strategy.FallbackSearches {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, fmt.Errorf("candidate search cancelled: %w", ctxErr)
+			}
 			searchesExecuted++
-			if err == nil {
-				fmt.Printf("Search returned no results, switching to fallback strategy %d...\n", i+1)
+			reason := fmt.Sprintf("search returned no results, trying fallback strategy %d", i+1)
+			if err != nil {
+				reason = fmt.Sprintf("search failed, trying fallback strategy %d", i+1)
 			}
+			events.Publish(observability.FallbackUsed{Stage: StageEnrichment, Reason: reason, Err: err})
 
 			input = github.ToolInput{
 				Language:   fallback.Language,
 				Location:   fallback.Location,
 				MinRepos:   strategy.PostFilters.MinRepos,
-				MaxResults: 15,
+				MaxResults: config.maxSearchResults(),
 			}
 			if len(strategy.RepositorySearch.Keywords) > 0 {
 				input.Keywords = strings.Join(strategy.RepositorySearch.Keywords, " ")
 			}
-			result, err = githubClient.SearchDevelopers(input)
+			result, err = source.SearchDevelopers(ctx, input)
+			publishSearch(events, input, i+1, result, err)
 
 			if err == nil && result != nil && len(result.Candidates) > 0 {
 				break
@@ -322,149 +239,201 @@ func findAndEnrichCandidates(client llm.Client, githubClient *github.Client, str
 			SearchMetadata: SearchMetadata{SearchesExecuted: searchesExecuted},
 		}, nil
 	}
-	candidates = result.Candidates
+	// Drop excluded developers before spending GitHub requests on them
+	excluded := 0
+	ex := config.exclusions()
+	for _, cand := range result.Candidates {
+		if ex.excludes(cand.Username, cand.Company) {
+			excluded++
+			continue
+		}
+		candidates = append(candidates, cand)
+	}
 
 	// 2. Enrich
 	enriched := []EnrichedCandidate{}
 	profilesAnalyzed := 0
 
+	// One GitHub round trip per candidate makes this the slowest step to watch
+	progress := config.progress()
+	defer progress.Finish(enrichmentProgressStep)
+
 	for _, cand := range candidates {
+		if err := ctx.Err(); err != nil {
+			// Return what was enriched so far for failure snapshots
+			partial := &EnrichedCandidates{Candidates: enriched, SearchMetadata: SearchMetadata{
+				SearchesExecuted:   searchesExecuted,
+				TotalProfilesFound: len(result.Candidates),
+				ProfilesAnalyzed:   profilesAnalyzed,
+				CandidatesExcluded: excluded,
+			}}
+			return partial, fmt.Errorf("candidate enrichment cancelled: %w", err)
+		}
+		observability.UpdateItem(progress, enrichmentProgressStep, profilesAnalyzed, len(candidates), cand.Username)
 		profilesAnalyzed++
 
-		// Get Repos
-		repos, err := githubClient.GetDeveloperRepositories(cand.Username, 10)
+		candidate, err := enrichCandidate(ctx, source, cand, requirements.RequiredSkills, strategy.RepositorySearch.Keywords, config.relevanceThreshold())
 		if err != nil {
-			fmt.Printf("Failed to get repos for %s: %v\n", cand.Username, err)
+			events.Publish(observability.CandidateEnriched{Username: cand.Username, Err: err})
 			continue
 		}
-
-		// Analyze
-		relevantRepos := []RelevantRepository{}
-		for _, repo := range repos {
-			analysis := analyzeRepositoryRelevance(repo, requirements.RequiredSkills, strategy.RepositorySearch.Keywords)
-			if analysis.Score > 0.3 { // Threshold
-				relevantRepos = append(relevantRepos, RelevantRepository{
-					Name:            repo.Name,
-					Description:     repo.Description,
-					Language:        repo.Language,
-					Stars:           repo.Stars,
-					Topics:          repo.Topics,
-					RelevanceScore:  analysis.Score,
-					RelevanceReason: strings.Join(analysis.Reasons, ", "),
-				})
-			}
-		}
-
-		// Calc initial match score (simplified)
-		matchScore := 0.5 // Base
-		if len(relevantRepos) > 0 {
-			matchScore += 0.2
-		}
-		// ... more logic ...
-
-		enriched = append(enriched, EnrichedCandidate{
-			Username:             cand.Username,
-			Name:                 cand.Name,
-			Location:             cand.Location,
-			Bio:                  cand.Bio,
-			PublicRepos:          cand.PublicRepos,
-			Followers:            cand.Followers,
-			GitHubURL:            cand.GitHubURL,
-			RelevantRepositories: relevantRepos,
-			SkillsFound:          requirements.RequiredSkills, // Placeholder, should extract from bio/repos
-			ExperienceIndicators: ExperienceIndicators{
-				TotalStars: 0, // Need to sum
-			},
-			InitialMatchScore: matchScore,
-		})
+		events.Publish(observability.CandidateEnriched{Username: cand.Username, RelevantRepositories: len(candidate.RelevantRepositories)})
+		runEnrichers(ctx, config.Enrichers, requirements, &candidate, events)
+		enriched = append(enriched, candidate)
 	}
 
+	// With several source platforms, the same person may have been found on more than one
+	enriched, merged := resolveIdentities(enriched, config.mergeThreshold(), events)
+
 	finalEnrichedCandidates := &EnrichedCandidates{
 		Candidates: enriched,
 		SearchMetadata: SearchMetadata{
 			SearchesExecuted:   searchesExecuted,
-			TotalProfilesFound: len(candidates),
+			TotalProfilesFound: len(result.Candidates),
 			ProfilesAnalyzed:   profilesAnalyzed,
+			CandidatesExcluded: excluded,
+			ProfilesMerged:     merged,
 		},
 	}
 
 	if err := finalEnrichedCandidates.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid enriched candidates: %w", err)
+		return nil, observability.WithErrorClass(observability.ErrorClassValidation, fmt.Errorf("invalid enriched candidates: %w", err))
 	}
 
 	return finalEnrichedCandidates, nil
 }
 
-// rankAndPresent (Prompt 4)
-func rankAndPresent(client llm.Client, candidates *EnrichedCandidates, requirements *Requirements) (*FinalResult, *llm.Usage, error) {
-	systemPrompt := `You are a candidate ranking and presentation specialist.
-
-Given enriched candidate data, produce final rankings and presentation.
-
-Your task:
-1. Evaluate each candidate's fit based on:
-   - Required skills coverage
-   - Repository relevance
-   - Experience indicators
-   - Location match
-   - Profile quality (bio, followers, activity)
-2. Format the top candidates for presentation
-3. Provide reasoning for each candidate
-
-Evaluate each candidate on a 0-100 scale for these components:
-- Required skills match
-- Repository relevance
-- Experience indicators
-- Profile quality
-
-Output Format (JSON):
-{
-  "top_candidates": [
-    {
-      "username": "string",
-      "name": "string",
-      "location": "string",
-      "github_url": "string",
-      "match_breakdown": {
-        "required_skills_score": number,
-        "repository_relevance_score": number,
-        "experience_score": number,
-        "profile_quality_score": number
-      },
-      "key_qualifications": ["qual1", "qual2"],
-      "top_relevant_projects": [
-        { "name": "string", "url": "string", "why_relevant": "string" }
-      ],
-      "match_reasoning": "string",
-      "potential_concerns": "string"
-    }
-  ],
-  "summary": {
-    "total_candidates_found": number,
-    "candidates_presented": number,
-    "average_match_score": number,
-    "search_quality": "string"
-  }
-}`
-
-	input := map[string]interface{}{
-		"candidates":   candidates,
-		"requirements": requirements,
-	}
-	inputJSON, _ := json.Marshal(input)
+// enrichmentProgressStep names the candidate enrichment loop in progress reports
+const enrichmentProgressStep = "Enriching candidates"
 
-	messages := []llm.Message{
-		{
-			Role:    "system",
-			Content: systemPrompt,
-		},
-		{
-			Role:    "user",
-			Content: fmt.Sprintf("Input Data: %s", string(inputJSON)),
+// enrichCandidate fetches a candidate's repositories and keeps those scoring
+// above threshold for relevance to the required skills and keywords
+func enrichCandidate(ctx context.Context, source SourceProvider, cand github.Candidate, requiredSkills, keywords []string, threshold float64) (EnrichedCandidate, error) {
+	// Get Repos
+	repos, err := source.GetRepositories(ctx, cand.Username, 10)
+	if err != nil {
+		return EnrichedCandidate{}, fmt.Errorf("failed to get repositories: %w", err)
+	}
+
+	// Analyze
+	relevantRepos := []RelevantRepository{}
+	for _, repo := range repos {
+		analysis := analyzeRepositoryRelevance(repo, requiredSkills, keywords)
+		if analysis.Score > threshold {
+			relevantRepos = append(relevantRepos, RelevantRepository{
+				Name:            repo.Name,
+				URL:             repo.URL,
+				Description:     repo.Description,
+				Language:        repo.Language,
+				Stars:           repo.Stars,
+				Topics:          repo.Topics,
+				RelevanceScore:  analysis.Score,
+				RelevanceReason: strings.Join(analysis.Reasons, ", "),
+			})
+		}
+	}
+
+	// Calc initial match score (simplified)
+	matchScore := 0.5 // Base
+	if len(relevantRepos) > 0 {
+		matchScore += 0.2
+	}
+	// ... more logic ...
+
+	return EnrichedCandidate{
+		Username:             cand.Username,
+		Name:                 cand.Name,
+		Location:             cand.Location,
+		Company:              cand.Company,
+		Bio:                  cand.Bio,
+		PublicRepos:          cand.PublicRepos,
+		Followers:            cand.Followers,
+		GitHubURL:            cand.GitHubURL,
+		Blog:                 cand.Blog,
+		Platform:             cand.Platform,
+		Email:                cand.Email,
+		RelevantRepositories: relevantRepos,
+		SkillsFound:          requiredSkills, // Placeholder, should extract from bio/repos
+		ExperienceIndicators: ExperienceIndicators{
+			TotalStars: 0, // Need to sum
 		},
+		InitialMatchScore: matchScore,
+	}, nil
+}
+
+// publishSearch publishes the outcome of a GitHub developer search
+func publishSearch(events *observability.EventBus, input github.ToolInput, fallback int, result *github.SearchResult, err error) {
+	event := observability.SearchExecuted{
+		Language: input.Language,
+		Location: input.Location,
+		Keywords: input.Keywords,
+		Fallback: fallback,
+		Err:      err,
+	}
+	if result != nil {
+		event.Results = len(result.Candidates)
 	}
+	events.Publish(event)
+}
 
-	resp, err := client.CallAPI(messages, nil)
+// rankingProgressInterval is how many streamed characters pass between ranking progress logs
+const rankingProgressInterval = 2000
+
+// attachEnriched copies the profile URL, merged identities and merge
+// confidence of candidates to the ranked candidates, so links never come
+// from the model's output. A candidate the model made up is left without a
+// profile URL.
+func attachEnriched(result *FinalResult, candidates *EnrichedCandidates) {
+	for i := range result.TopCandidates {
+		ranked := &result.TopCandidates[i]
+		ranked.GitHubURL = ""
+		for _, c := range candidates.Candidates {
+			if c.Username == ranked.Username {
+				ranked.GitHubURL = c.GitHubURL
+				ranked.Identities, ranked.MergeConfidence = c.Identities, c.MergeConfidence
+				break
+			}
+		}
+	}
+}
+
+// rankingOptions are the settings of rankAndPresent
+type rankingOptions struct {
+	scoring     *Scoring // Weights of the final match scores, the defaults when nil
+	targetCount int      // Candidates presented at most, when positive
+	language    string   // Language of the ranking's prose, English when empty
+	logger      *slog.Logger
+	events      *observability.EventBus
+}
+
+// rankAndPresent (Prompt 4) ranks the enriched candidates, presenting at
+// most opts.targetCount of them when it is positive. Final match scores
+// weigh the model's component scores by opts.scoring.
+func rankAndPresent(ctx context.Context, client llm.Client, candidates *EnrichedCandidates, requirements *Requirements, opts rankingOptions) (*FinalResult, *llm.Usage, error) {
+	systemPrompt, err := prompts.Render(prompts.Ranking, prompts.Data{TargetCount: opts.targetCount, Language: opts.language})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Make sure the candidate payload fits the ranking prompt before sending it
+	candidates, err = fitCandidatesToBudget(ctx, client, systemPrompt, candidates, requirements, rankingTokenBudget, opts.events)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fit candidates into ranking prompt: %w", err)
+	}
+
+	messages := buildRankingMessages(systemPrompt, candidates, requirements)
+
+	// Stream the ranking so long outputs show progress; providers without
+	// streaming support simply ignore the handler
+	received, reported := 0, 0
+	progress := llm.WithStream(func(chunk llm.StreamChunk) {
+		received += len(chunk.Text)
+		if received-reported >= rankingProgressInterval {
+			reported = received
+			opts.logger.Info("Receiving ranking", "characters", received)
+		}
+	})
+	resp, err := client.CallAPI(ctx, messages, nil, llm.WithStage(StageRanking), llm.WithResponseSchema(rankingSchema), progress)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to call LLM: %w", err)
 	}
@@ -476,33 +445,44 @@ Output Format (JSON):
 		}
 	}
 
-	jsonStr := extractJSON(content)
+	jsonStr := llm.ExtractJSON(content)
 
 	var result FinalResult
 	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		return nil, &resp.Usage, fmt.Errorf("failed to parse final result JSON: %w", err)
+		return nil, &resp.Usage, fmt.Errorf("failed to parse final result JSON: %w", &llm.DecodeError{Content: content, Err: err})
 	}
 
-	// Calculate scores programmatically to ensure accuracy
-	// Weights: Skills (40%), Repos (30%), Experience (20%), Quality (10%)
+	// Calculate scores programmatically to ensure accuracy, with the
+	// weights and keyword boosts learned from feedback if any
+	keywords := make(map[string][]string)
+	if opts.scoring != nil && len(opts.scoring.KeywordBoosts) > 0 {
+		for _, c := range candidates.Candidates {
+			keywords[c.Username] = CandidateKeywords(c)
+		}
+	}
 	var totalScore float64
 	for i := range result.TopCandidates {
 		cand := &result.TopCandidates[i]
-		bd := cand.MatchBreakdown
-
-		finalScore := (bd.RequiredSkillsScore * 0.4) +
-			(bd.RepositoryRelevanceScore * 0.3) +
-			(bd.ExperienceScore * 0.2) +
-			(bd.ProfileQualityScore * 0.1)
-
+		finalScore := opts.scoring.score(cand.MatchBreakdown, keywords[cand.Username])
 		cand.FinalMatchScore = finalScore
 		totalScore += finalScore
 	}
+	attachEnriched(&result, candidates)
 
 	// Sort candidates by score desc
 	sort.Slice(result.TopCandidates, func(i, j int) bool {
 		return result.TopCandidates[i].FinalMatchScore > result.TopCandidates[j].FinalMatchScore
 	})
+	// The model may present more candidates than asked for
+	if opts.targetCount > 0 && len(result.TopCandidates) > opts.targetCount {
+		dropped := result.TopCandidates[opts.targetCount:]
+		for _, c := range dropped {
+			totalScore -= c.FinalMatchScore
+		}
+		result.TopCandidates = result.TopCandidates[:opts.targetCount]
+		result.Summary.CandidatesPresented = opts.targetCount
+	}
+	result.Scoring = opts.scoring
 
 	// Assign ranks
 	for i := range result.TopCandidates {
@@ -517,23 +497,27 @@ Output Format (JSON):
 	return &result, &resp.Usage, nil
 }
 
-// createFallbackResult creates a FinalResult from enriched candidates without LLM ranking
-func createFallbackResult(candidates *EnrichedCandidates) *FinalResult {
+// createFallbackResult creates a FinalResult of at most limit enriched
+// candidates without LLM ranking
+func createFallbackResult(candidates *EnrichedCandidates, limit int) *FinalResult {
 	topCandidates := []RankedCandidate{}
 	var totalScore float64
 
 	// Convert enriched candidates to ranked candidates
 	for i, cand := range candidates.Candidates {
-		// Just take top 10 if there are many
-		if i >= 10 {
+		if i >= limit {
 			break
 		}
 
 		relevantProjects := []RelevantProject{}
 		for _, repo := range cand.RelevantRepositories {
+			url := repo.URL
+			if url == "" {
+				url = cand.GitHubURL + "/" + repo.Name
+			}
 			relevantProjects = append(relevantProjects, RelevantProject{
 				Name:        repo.Name,
-				URL:         cand.GitHubURL + "/" + repo.Name,
+				URL:         url,
 				WhyRelevant: repo.RelevanceReason,
 			})
 		}
@@ -546,6 +530,8 @@ func createFallbackResult(candidates *EnrichedCandidates) *FinalResult {
 			FinalMatchScore:     cand.InitialMatchScore * 100, // Scale to 0-100
 			MatchReasoning:      "Ranking step unavailable; score is based on initial keyword match.",
 			TopRelevantProjects: relevantProjects,
+			Identities:          cand.Identities,
+			MergeConfidence:     cand.MergeConfidence,
 		}
 		topCandidates = append(topCandidates, ranked)
 		totalScore += ranked.FinalMatchScore
@@ -568,6 +554,7 @@ func createFallbackResult(candidates *EnrichedCandidates) *FinalResult {
 
 	return &FinalResult{
 		TopCandidates: topCandidates,
+		Partial:       true,
 		Summary: ResultSummary{
 			TotalCandidatesFound: candidates.SearchMetadata.TotalProfilesFound,
 			CandidatesPresented:  len(topCandidates),
@@ -576,15 +563,3 @@ func createFallbackResult(candidates *EnrichedCandidates) *FinalResult {
 		},
 	}
 }
-
-// Helper to extract JSON from markdown code blocks
-func extractJSON(content string) string {
-	if strings.Contains(content, "```json") {
-		parts := strings.Split(content, "```json")
-		if len(parts) > 1 {
-			sub := strings.Split(parts[1], "```")
-			return strings.TrimSpace(sub[0])
-		}
-	}
-	return content
-}
@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -16,7 +17,7 @@ type MockLLMClientForFallback struct {
 	CallCount int
 }
 
-func (m *MockLLMClientForFallback) CallAPI(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+func (m *MockLLMClientForFallback) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
 	m.CallCount++
 
 	// Prompt 1: Requirements Analysis
@@ -103,7 +104,7 @@ func TestRunStage2_RankingFallback(t *testing.T) {
 	llmClient := &MockLLMClientForFallback{}
 
 	// Execute RunStage2
-	result, err := RunStage2(llmClient, ghClient, "find go developers")
+	result, err := RunStage2(context.Background(), llmClient, ghClient, "find go developers")
 
 	// We expect NO error, because fallback should handle it
 	if err != nil {
@@ -117,6 +118,9 @@ func TestRunStage2_RankingFallback(t *testing.T) {
 	if result.Summary.SearchQuality != "Fallback (Ranking Unavailable)" {
 		t.Errorf("Expected SearchQuality 'Fallback (Ranking Unavailable)', got '%s'", result.Summary.SearchQuality)
 	}
+	if !result.Partial {
+		t.Error("Expected the fallback result marked partial")
+	}
 	if len(result.TopCandidates) != 1 {
 		t.Fatalf("Expected 1 candidate, got %d", len(result.TopCandidates))
 	}
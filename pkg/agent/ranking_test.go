@@ -1,6 +1,9 @@
 package agent
 
 import (
+	"context"
+	"log/slog"
+	"strings"
 	"testing"
 
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
@@ -21,7 +24,7 @@ func TestRankAndPresent(t *testing.T) {
       "username": "testuser",
       "name": "Test User",
       "location": "Lima",
-      "github_url": "https://github.com/testuser",
+      "github_url": "file:///etc/passwd",
       "final_match_score": 0.95,
       "match_breakdown": {
         "required_skills_score": 0.4,
@@ -52,10 +55,10 @@ func TestRankAndPresent(t *testing.T) {
 		},
 	}
 
-	candidates := &EnrichedCandidates{}
+	candidates := &EnrichedCandidates{Candidates: []EnrichedCandidate{{Username: "testuser", GitHubURL: "https://gitlab.com/testuser"}}}
 	requirements := &Requirements{}
 
-	result, _, err := rankAndPresent(client, candidates, requirements)
+	result, _, err := rankAndPresent(context.Background(), client, candidates, requirements, rankingOptions{logger: slog.New(slog.DiscardHandler)})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -66,7 +69,52 @@ func TestRankAndPresent(t *testing.T) {
 	if result.TopCandidates[0].Username != "testuser" {
 		t.Errorf("Expected username testuser, got %s", result.TopCandidates[0].Username)
 	}
+	// The profile URL is the enriched candidate's, never the model's
+	if result.TopCandidates[0].GitHubURL != "https://gitlab.com/testuser" {
+		t.Errorf("Expected the enriched profile URL, got %s", result.TopCandidates[0].GitHubURL)
+	}
 	if result.Summary.TotalCandidatesFound != 10 {
 		t.Errorf("Expected 10 candidates found, got %d", result.Summary.TotalCandidatesFound)
 	}
 }
+
+func TestRankAndPresentTargetCount(t *testing.T) {
+	var systemPrompt string
+	client := &MockLLMClient{
+		CallAPIFunc: func(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+			systemPrompt, _ = messages[0].Content.(string)
+			return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: `{
+  "top_candidates": [
+    {"username": "weak", "match_breakdown": {"required_skills_score": 20}},
+    {"username": "strong", "match_breakdown": {"required_skills_score": 90}},
+    {"username": "medium", "match_breakdown": {"required_skills_score": 50}}
+  ],
+  "summary": {"candidates_presented": 3}
+}`}}}, nil
+		},
+	}
+
+	result, _, err := rankAndPresent(context.Background(), client, &EnrichedCandidates{}, &Requirements{}, rankingOptions{targetCount: 2, logger: slog.New(slog.DiscardHandler)})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(systemPrompt, "Present at most 2 candidates") {
+		t.Errorf("Expected the target count in the ranking prompt, got %q", systemPrompt)
+	}
+	if len(result.TopCandidates) != 2 || result.TopCandidates[0].Username != "strong" || result.TopCandidates[1].Username != "medium" {
+		t.Fatalf("Expected the 2 best candidates, got %+v", result.TopCandidates)
+	}
+	if result.Summary.CandidatesPresented != 2 || result.Summary.AverageMatchScore != 28 {
+		t.Errorf("Expected summary of the presented candidates, got %+v", result.Summary)
+	}
+}
+
+func TestCreateFallbackResultLimit(t *testing.T) {
+	candidates := &EnrichedCandidates{}
+	for _, username := range []string{"a", "b", "c"} {
+		candidates.Candidates = append(candidates.Candidates, EnrichedCandidate{Username: username, InitialMatchScore: 0.5})
+	}
+	if got := len(createFallbackResult(candidates, 2).TopCandidates); got != 2 {
+		t.Errorf("Expected 2 fallback candidates, got %d", got)
+	}
+}
@@ -0,0 +1,259 @@
+package agent
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// Run report formats
+const (
+	ReportMarkdown = "markdown"
+	ReportHTML     = "html"
+)
+
+// RunReport is a self-contained summary of a run that a recruiter can attach
+// to a hiring ticket: the query, how it was interpreted and searched, how many
+// profiles survived each filter, the top candidates, and what the run cost
+type RunReport struct {
+	Query       string
+	Result      *FinalResult
+	Searches    []observability.SearchExecuted // e.g. from an observability.SearchLog
+	Usage       *observability.Report          // Optional LLM cost and HTTP usage
+	GeneratedAt time.Time
+}
+
+// ReportFormat picks the format for a report file by its extension: HTML for
+// .html and .htm, markdown otherwise
+func ReportFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return ReportHTML
+	}
+	return ReportMarkdown
+}
+
+// WriteReport renders report to w in format (ReportMarkdown or ReportHTML)
+func WriteReport(w io.Writer, format string, report RunReport) error {
+	if report.Result == nil {
+		return fmt.Errorf("run report has no result")
+	}
+	if report.GeneratedAt.IsZero() {
+		report.GeneratedAt = time.Now()
+	}
+	var err error
+	switch format {
+	case ReportMarkdown:
+		err = markdownReport.Execute(w, report)
+	case ReportHTML:
+		err = htmlReport.Execute(w, report)
+	default:
+		return fmt.Errorf("unknown report format %q: want %s or %s", format, ReportMarkdown, ReportHTML)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render run report: %w", err)
+	}
+	return nil
+}
+
+var reportFuncs = map[string]any{
+	"join":   strings.Join,
+	"inc":    func(i int) int { return i + 1 },
+	"cell":   markdownCell,
+	"date":   func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 UTC") },
+	"search": describeSearch,
+}
+
+// markdownCell keeps free text from breaking a markdown table row
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func describeSearch(s observability.SearchExecuted) string {
+	parts := []string{"language:" + s.Language}
+	if s.Location != "" {
+		parts = append(parts, "location:"+s.Location)
+	}
+	if s.Keywords != "" {
+		parts = append(parts, "keywords:"+s.Keywords)
+	}
+	return strings.Join(parts, " ")
+}
+
+var markdownReport = template.Must(template.New("report").Funcs(reportFuncs).Parse(`# Sourcing report
+
+**Query:** {{.Query}}
+{{with .Result.RunID}}**Run ID:** {{.}}
+{{end}}**Generated:** {{date .GeneratedAt}}
+{{with .Result.Requirements}}
+## Requirements
+
+- Required skills: {{join .RequiredSkills ", "}}
+{{- with .ExperienceLevel}}
+- Experience level: {{.}}{{end}}
+{{- with .Locations}}
+- Locations: {{join . ", "}}{{end}}
+{{- with .Keywords}}
+- Keywords: {{join . ", "}}{{end}}
+{{- with .NiceToHave}}
+- Nice to have: {{join . ", "}}{{end}}
+{{end}}
+{{- with .Result.Strategy}}
+## Strategy
+
+- Primary search: language:{{.PrimarySearch.Language}}{{with .PrimarySearch.Location}} location:{{.}}{{end}}
+{{- range .FallbackSearches}}
+- Fallback search: language:{{.Language}}{{with .Location}} location:{{.}}{{end}}{{end}}
+- Minimum repositories: {{.PostFilters.MinRepos}}
+{{- with .StrategyNotes}}
+
+{{.}}{{end}}
+{{end}}
+{{- with .Searches}}
+## Searches executed
+
+| # | Search | Results |
+|---|--------|---------|
+{{range $i, $s := .}}| {{inc $i}} | {{cell (search $s)}}{{if $s.Fallback}} (fallback {{$s.Fallback}}){{end}} | {{if $s.Err}}failed: {{cell $s.Err.Error}}{{else}}{{$s.Results}}{{end}} |
+{{end}}{{end}}
+{{- with .Result.SearchMetadata}}
+## Filter attrition
+
+| Step | Profiles |
+|------|----------|
+| Found by search | {{.TotalProfilesFound}} |
+| Analyzed | {{.ProfilesAnalyzed}} |
+| Matched requirements | {{$.Result.Summary.TotalCandidatesFound}} |
+| Presented | {{$.Result.Summary.CandidatesPresented}} |
+{{end}}
+## Top candidates
+{{range .Result.TopCandidates}}
+### {{.Rank}}. {{if .Name}}{{.Name}} ({{.Username}}){{else}}{{.Username}}{{end}} — {{printf "%.1f" .FinalMatchScore}}
+
+{{.GitHubURL}}{{with .Location}} · {{.}}{{end}}
+{{with .MatchReasoning}}
+{{.}}
+{{end}}
+{{- with .KeyQualifications}}
+- Key qualifications: {{join . ", "}}{{end}}
+{{- range .TopRelevantProjects}}
+- Project [{{.Name}}]({{.URL}}): {{.WhyRelevant}}{{end}}
+{{- with .PotentialConcerns}}
+- Concerns: {{.}}{{end}}
+{{else}}
+No candidates matched.
+{{end}}
+Search quality: {{.Result.Summary.SearchQuality}}. Average match score: {{printf "%.1f" .Result.Summary.AverageMatchScore}}.
+{{with .Usage}}
+## Costs
+
+| Stage | Calls | Input tokens | Output tokens | Est. cost (USD) |
+|-------|-------|--------------|---------------|-----------------|
+{{range .Stages}}| {{.Stage}} | {{.Calls}} | {{.Usage.InputTokens}} | {{.Usage.OutputTokens}} | {{printf "%.4f" .Usage.EstimatedCostUSD}} |
+{{end}}| **Total** | {{.Total.Calls}} | {{.Total.Usage.InputTokens}} | {{.Total.Usage.OutputTokens}} | {{printf "%.4f" .Total.Usage.EstimatedCostUSD}} |
+{{end}}
+{{- with .Result.Timings}}
+## Timings
+
+| Stage | Duration (ms) |
+|-------|---------------|
+{{range .Stages}}| {{.Stage}} | {{.DurationMS}} |
+{{end}}| **Total** | {{.TotalMS}} |
+{{end}}`))
+
+var htmlReport = htmltemplate.Must(htmltemplate.New("report").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Sourcing report: {{.Query}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+.candidate { border-top: 1px solid #eee; padding-top: 0.5em; }
+.muted { color: #666; }
+</style>
+</head>
+<body>
+<h1>Sourcing report</h1>
+<p><strong>Query:</strong> {{.Query}}<br>
+{{with .Result.RunID}}<strong>Run ID:</strong> {{.}}<br>{{end}}
+<strong>Generated:</strong> {{date .GeneratedAt}}</p>
+{{with .Result.Requirements}}
+<h2>Requirements</h2>
+<ul>
+<li>Required skills: {{join .RequiredSkills ", "}}</li>
+{{with .ExperienceLevel}}<li>Experience level: {{.}}</li>{{end}}
+{{with .Locations}}<li>Locations: {{join . ", "}}</li>{{end}}
+{{with .Keywords}}<li>Keywords: {{join . ", "}}</li>{{end}}
+{{with .NiceToHave}}<li>Nice to have: {{join . ", "}}</li>{{end}}
+</ul>
+{{end}}
+{{with .Result.Strategy}}
+<h2>Strategy</h2>
+<ul>
+<li>Primary search: language:{{.PrimarySearch.Language}}{{with .PrimarySearch.Location}} location:{{.}}{{end}}</li>
+{{range .FallbackSearches}}<li>Fallback search: language:{{.Language}}{{with .Location}} location:{{.}}{{end}}</li>{{end}}
+<li>Minimum repositories: {{.PostFilters.MinRepos}}</li>
+</ul>
+{{with .StrategyNotes}}<p>{{.}}</p>{{end}}
+{{end}}
+{{with .Searches}}
+<h2>Searches executed</h2>
+<table>
+<tr><th>#</th><th>Search</th><th>Results</th></tr>
+{{range $i, $s := .}}<tr><td>{{inc $i}}</td><td>{{search $s}}{{if $s.Fallback}} (fallback {{$s.Fallback}}){{end}}</td><td>{{if $s.Err}}failed: {{$s.Err.Error}}{{else}}{{$s.Results}}{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+{{with .Result.SearchMetadata}}
+<h2>Filter attrition</h2>
+<table>
+<tr><th>Step</th><th>Profiles</th></tr>
+<tr><td>Found by search</td><td>{{.TotalProfilesFound}}</td></tr>
+<tr><td>Analyzed</td><td>{{.ProfilesAnalyzed}}</td></tr>
+<tr><td>Matched requirements</td><td>{{$.Result.Summary.TotalCandidatesFound}}</td></tr>
+<tr><td>Presented</td><td>{{$.Result.Summary.CandidatesPresented}}</td></tr>
+</table>
+{{end}}
+<h2>Top candidates</h2>
+{{range .Result.TopCandidates}}
+<div class="candidate">
+<h3>{{.Rank}}. {{if .Name}}{{.Name}} ({{.Username}}){{else}}{{.Username}}{{end}} — {{printf "%.1f" .FinalMatchScore}}</h3>
+<p class="muted"><a href="{{.GitHubURL}}">{{.GitHubURL}}</a>{{with .Location}} · {{.}}{{end}}</p>
+{{with .MatchReasoning}}<p>{{.}}</p>{{end}}
+<ul>
+{{with .KeyQualifications}}<li>Key qualifications: {{join . ", "}}</li>{{end}}
+{{range .TopRelevantProjects}}<li>Project <a href="{{.URL}}">{{.Name}}</a>: {{.WhyRelevant}}</li>{{end}}
+{{with .PotentialConcerns}}<li>Concerns: {{.}}</li>{{end}}
+</ul>
+</div>
+{{else}}
+<p>No candidates matched.</p>
+{{end}}
+<p>Search quality: {{.Result.Summary.SearchQuality}}. Average match score: {{printf "%.1f" .Result.Summary.AverageMatchScore}}.</p>
+{{with .Usage}}
+<h2>Costs</h2>
+<table>
+<tr><th>Stage</th><th>Calls</th><th>Input tokens</th><th>Output tokens</th><th>Est. cost (USD)</th></tr>
+{{range .Stages}}<tr><td>{{.Stage}}</td><td>{{.Calls}}</td><td>{{.Usage.InputTokens}}</td><td>{{.Usage.OutputTokens}}</td><td>{{printf "%.4f" .Usage.EstimatedCostUSD}}</td></tr>
+{{end}}<tr><th>Total</th><th>{{.Total.Calls}}</th><th>{{.Total.Usage.InputTokens}}</th><th>{{.Total.Usage.OutputTokens}}</th><th>{{printf "%.4f" .Total.Usage.EstimatedCostUSD}}</th></tr>
+</table>
+{{end}}
+{{with .Result.Timings}}
+<h2>Timings</h2>
+<table>
+<tr><th>Stage</th><th>Duration (ms)</th></tr>
+{{range .Stages}}<tr><td>{{.Stage}}</td><td>{{.DurationMS}}</td></tr>
+{{end}}<tr><th>Total</th><th>{{.TotalMS}}</th></tr>
+</table>
+{{end}}
+</body>
+</html>
+`))
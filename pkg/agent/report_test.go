@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+func runReportFixture(t *testing.T) RunReport {
+	t.Helper()
+	server := newPipelineGitHubServer(t)
+	t.Cleanup(server.Close)
+
+	searches := &observability.SearchLog{}
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+	query := "Find senior Go backend developers in Lima"
+	result, err := RunStage2WithConfig(context.Background(), goldenLLMClient(t, "stage2_go_lima"), githubClient, query,
+		AgentConfig{Events: observability.NewEventBus(searches.Subscriber())})
+	if err != nil {
+		t.Fatalf("RunStage2WithConfig failed: %v", err)
+	}
+	return RunReport{Query: query, Result: result, Searches: searches.Searches(), Usage: &observability.Report{}}
+}
+
+func TestWriteReportMarkdown(t *testing.T) {
+	report := runReportFixture(t)
+	report.Searches = append(report.Searches, observability.SearchExecuted{Language: "go", Fallback: 1, Err: errors.New("rate | limited")})
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, ReportMarkdown, report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"**Query:** Find senior Go backend developers in Lima",
+		"**Run ID:** " + report.Result.RunID,
+		"## Requirements",
+		"## Strategy",
+		"| 1 | language:",
+		`(fallback 1) | failed: rate \| limited |`,
+		"| Found by search | 2 |",
+		"### 1. Ana Quispe (gopher_lima)",
+		"## Costs",
+		"## Timings",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteReportHTML(t *testing.T) {
+	report := runReportFixture(t)
+	report.Query = "<script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	if err := WriteReport(&buf, ReportFormat("report.HTML"), report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "<script>") {
+		t.Error("Expected the query escaped in the HTML report")
+	}
+	if !strings.Contains(out, `<a href="https://github.com/gopher_lima">`) || !strings.Contains(out, "<h2>Filter attrition</h2>") {
+		t.Errorf("Expected candidate links and attrition in the HTML report, got:\n%s", out)
+	}
+}
+
+func TestWriteReportErrors(t *testing.T) {
+	if err := WriteReport(&bytes.Buffer{}, ReportMarkdown, RunReport{}); err == nil {
+		t.Error("Expected error for a report without a result")
+	}
+	if err := WriteReport(&bytes.Buffer{}, "pdf", RunReport{Result: &FinalResult{}}); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
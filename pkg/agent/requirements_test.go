@@ -1,9 +1,11 @@
 package agent
 
 import (
+	"context"
 	"testing"
 
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
 )
 
 func TestAnalyzeRequirementsUnclear(t *testing.T) {
@@ -34,7 +36,7 @@ func TestAnalyzeRequirementsUnclear(t *testing.T) {
 		},
 	}
 
-	reqs, _, err := analyzeRequirements(client, "Find senior Go devs in Lima")
+	reqs, _, err := analyzeRequirements(context.Background(), client, "Find senior Go devs in Lima")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -71,7 +73,7 @@ func TestRunStage2Unclear(t *testing.T) {
 	}
 
 	// githubClient can be nil because it shouldn't be reached
-	_, err := RunStage2(client, nil, "bad query")
+	_, err := RunStage2(context.Background(), client, nil, "bad query")
 
 	if err == nil {
 		t.Fatal("Expected error for unclear request, got nil")
@@ -82,3 +84,34 @@ func TestRunStage2Unclear(t *testing.T) {
 		t.Errorf("Expected error '%s', got '%s'", expectedErr, err.Error())
 	}
 }
+
+func TestRunStage2ClassifiesStageFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want observability.ErrorClass
+	}{
+		{"Unparseable", "I cannot answer in JSON", observability.ErrorClassLLMParse},
+		{"Invalid", `{"required_skills": []}`, observability.ErrorClassValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &MockLLMClient{
+				CallAPIFunc: func(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+					return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: tt.text}}}, nil
+				},
+			}
+			collector := observability.NewUsageCollector()
+			config := AgentConfig{Events: observability.NewEventBus(collector.Subscriber())}
+
+			_, err := RunStage2WithConfig(context.Background(), client, nil, "Go developers", config)
+			if got := observability.ClassifyError(err); got != tt.want {
+				t.Errorf("Expected %q error, got %q (%v)", tt.want, got, err)
+			}
+			failures := collector.Failures()
+			if len(failures) != 1 || failures[0].Source != observability.FailureSourceStage || failures[0].Class != tt.want {
+				t.Errorf("Expected one %q stage failure, got %+v", tt.want, failures)
+			}
+		})
+	}
+}
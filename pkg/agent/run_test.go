@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"testing"
 
 	"github.com/luillyfe/sourcing-agent/pkg/github"
@@ -11,7 +12,7 @@ type MockLLMClient struct {
 	CallAPIFunc func(messages []llm.Message, tools []llm.Tool) (*llm.Response, error)
 }
 
-func (m *MockLLMClient) CallAPI(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+func (m *MockLLMClient) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
 	return m.CallAPIFunc(messages, tools)
 }
 
@@ -32,7 +33,7 @@ func TestRun(t *testing.T) {
 
 	mockGithub := &github.Client{} // We don't need a real client for this test as we won't call it
 
-	result, err := Run(mockLLM, mockGithub, "Find Go devs")
+	result, err := Run(context.Background(), mockLLM, mockGithub, "Find Go devs")
 	if err != nil {
 		t.Fatalf("Run failed: %v", err)
 	}
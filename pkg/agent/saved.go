@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SavedSearch is a named query saved with the settings it runs with, for
+// roles searched again and again. Unset settings follow the defaults of the
+// run. A file of saved searches is also a batch file running every query
+// with its search limits.
+type SavedSearch struct {
+	BatchQuery `yaml:",inline"`
+	// Provider is the -llm provider to run the search with
+	Provider string `yaml:"llm,omitempty" json:"llm,omitempty"`
+	Language string `yaml:"lang,omitempty" json:"lang,omitempty"`
+	// Source is the -source platform to run the search against
+	Source  string    `yaml:"source,omitempty" json:"source,omitempty"`
+	Exclude []string  `yaml:"exclude,omitempty" json:"exclude,omitempty"`
+	SavedAt time.Time `yaml:"saved_at" json:"saved_at"`
+}
+
+// SavedSearches are the searches of a saved searches file, in the order
+// they were first saved
+type SavedSearches []SavedSearch
+
+// SavedSearchName returns the name a search saved as name is stored and run
+// under: a lowercase slug, e.g. senior-go-latam
+func SavedSearchName(name string) (string, error) {
+	slug := slugify(name)
+	if slug == "" {
+		return "", fmt.Errorf("invalid search name %q: use letters, digits and dashes", name)
+	}
+	return slug, nil
+}
+
+// Get returns the search called name
+func (s SavedSearches) Get(name string) (SavedSearch, bool) {
+	i := slices.IndexFunc(s, func(search SavedSearch) bool { return search.Name == slugify(name) })
+	if i < 0 {
+		return SavedSearch{}, false
+	}
+	return s[i], true
+}
+
+// Put adds search, replacing a search of the same name, and reports whether
+// it replaced one
+func (s *SavedSearches) Put(search SavedSearch) bool {
+	i := slices.IndexFunc(*s, func(saved SavedSearch) bool { return saved.Name == search.Name })
+	if i < 0 {
+		*s = append(*s, search)
+		return false
+	}
+	(*s)[i] = search
+	return true
+}
+
+// Delete removes the search called name and reports whether it existed
+func (s *SavedSearches) Delete(name string) bool {
+	before := len(*s)
+	*s = slices.DeleteFunc(*s, func(search SavedSearch) bool { return search.Name == slugify(name) })
+	return len(*s) < before
+}
+
+// LoadSavedSearches reads the saved searches at path; a missing file holds none
+func LoadSavedSearches(path string) (SavedSearches, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved searches: %w", err)
+	}
+	var searches SavedSearches
+	if err := yaml.Unmarshal(data, &searches); err != nil {
+		return nil, fmt.Errorf("failed to parse saved searches %s: %w", path, err)
+	}
+	return searches, nil
+}
+
+// Save writes the searches to path, creating its directory
+func (s SavedSearches) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create saved searches directory: %w", err)
+	}
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal saved searches: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write saved searches: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSavedSearches(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config", "searches.yaml")
+	searches, err := LoadSavedSearches(path)
+	if err != nil || len(searches) != 0 {
+		t.Fatalf("Expected no saved searches without a file, got %v, %v", searches, err)
+	}
+
+	name, err := SavedSearchName("Senior Go, LATAM")
+	if err != nil || name != "senior-go-latam" {
+		t.Fatalf("Expected senior-go-latam, got %q, %v", name, err)
+	}
+	goSearch := SavedSearch{
+		BatchQuery: BatchQuery{Name: name, Query: "Senior Go engineers in Latin America", TargetCount: 5},
+		Provider:   "anthropic",
+		Language:   "es",
+		Exclude:    []string{"org:acme"},
+		SavedAt:    time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	if searches.Put(goSearch) {
+		t.Error("Expected a new search not to replace one")
+	}
+	searches.Put(SavedSearch{BatchQuery: BatchQuery{Name: "rust-berlin", Query: "Rust developers in Berlin"}})
+	goSearch.TargetCount = 8
+	if !searches.Put(goSearch) || len(searches) != 2 {
+		t.Errorf("Expected the search to be replaced in place, got %+v", searches)
+	}
+	if err := searches.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadSavedSearches(path)
+	if err != nil {
+		t.Fatalf("LoadSavedSearches failed: %v", err)
+	}
+	got, ok := loaded.Get("Senior Go LATAM")
+	if !ok || got.TargetCount != 8 || got.Provider != "anthropic" || !got.SavedAt.Equal(goSearch.SavedAt) {
+		t.Fatalf("Expected the saved search, got %+v", got)
+	}
+
+	// The file runs as a batch of every saved search
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	batch, err := ParseBatch(f, true)
+	if err != nil || len(batch) != 2 || batch[0].Name != "senior-go-latam" || batch[0].TargetCount != 8 {
+		t.Errorf("Expected the saved searches as a batch, got %+v, %v", batch, err)
+	}
+
+	if !loaded.Delete("rust-berlin") || loaded.Delete("rust-berlin") || len(loaded) != 1 {
+		t.Errorf("Expected rust-berlin deleted once, got %+v", loaded)
+	}
+	if _, err := SavedSearchName("!!"); err == nil {
+		t.Error("Expected an error for a name without letters or digits")
+	}
+}
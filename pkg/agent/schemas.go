@@ -0,0 +1,124 @@
+package agent
+
+import "github.com/luillyfe/sourcing-agent/pkg/llm"
+
+// Response schemas for the LLM stages. They mirror the output formats in the
+// stage prompts so providers with native JSON mode return the bare document.
+
+var stringList = &llm.Property{Type: "array", Items: &llm.Property{Type: "string"}}
+
+var requirementsSchema = &llm.ResponseSchema{
+	Name:        "requirements",
+	Description: "Structured hiring requirements parsed from the user's query.",
+	Schema: llm.InputSchema{
+		Type: "object",
+		Properties: map[string]llm.Property{
+			"required_skills":        *stringList,
+			"experience_level":       {Type: "string", Enum: []string{"junior", "mid", "senior", "lead"}},
+			"locations":              *stringList,
+			"keywords":               *stringList,
+			"nice_to_have":           *stringList,
+			"unclear_request":        {Type: "boolean"},
+			"clarification_question": {Type: "string"},
+		},
+		Required: []string{"required_skills", "locations", "keywords", "unclear_request"},
+	},
+}
+
+var searchQuerySchema = llm.Property{
+	Type: "object",
+	Properties: map[string]llm.Property{
+		"language":  {Type: "string"},
+		"location":  {Type: "string"},
+		"followers": {Type: "string", Description: "Minimum followers, e.g. '>10'"},
+		"rationale": {Type: "string"},
+	},
+	Required: []string{"language"},
+}
+
+var strategySchema = &llm.ResponseSchema{
+	Name:        "search_strategy",
+	Description: "GitHub search strategy with a primary search, fallbacks and post-filters.",
+	Schema: llm.InputSchema{
+		Type: "object",
+		Properties: map[string]llm.Property{
+			"primary_search":    searchQuerySchema,
+			"fallback_searches": {Type: "array", Items: &searchQuerySchema},
+			"repository_search": {
+				Type: "object",
+				Properties: map[string]llm.Property{
+					"keywords":  *stringList,
+					"min_stars": {Type: "integer"},
+					"language":  {Type: "string"},
+				},
+			},
+			"post_filters": {
+				Type: "object",
+				Properties: map[string]llm.Property{
+					"min_repos":            {Type: "integer"},
+					"bio_keywords":         *stringList,
+					"recent_activity_days": {Type: "integer"},
+				},
+			},
+			"strategy_notes": {Type: "string"},
+		},
+		Required: []string{"primary_search", "fallback_searches", "repository_search", "post_filters"},
+	},
+}
+
+var rankingSchema = &llm.ResponseSchema{
+	Name:        "ranked_candidates",
+	Description: "Ranked candidates with score breakdowns and a summary.",
+	Schema: llm.InputSchema{
+		Type: "object",
+		Properties: map[string]llm.Property{
+			"top_candidates": {
+				Type: "array",
+				Items: &llm.Property{
+					Type: "object",
+					Properties: map[string]llm.Property{
+						"username":   {Type: "string"},
+						"name":       {Type: "string"},
+						"location":   {Type: "string"},
+						"github_url": {Type: "string"},
+						"match_breakdown": {
+							Type: "object",
+							Properties: map[string]llm.Property{
+								"required_skills_score":      {Type: "number"},
+								"repository_relevance_score": {Type: "number"},
+								"experience_score":           {Type: "number"},
+								"profile_quality_score":      {Type: "number"},
+							},
+							Required: []string{"required_skills_score", "repository_relevance_score", "experience_score", "profile_quality_score"},
+						},
+						"key_qualifications": *stringList,
+						"top_relevant_projects": {
+							Type: "array",
+							Items: &llm.Property{
+								Type: "object",
+								Properties: map[string]llm.Property{
+									"name":         {Type: "string"},
+									"url":          {Type: "string"},
+									"why_relevant": {Type: "string"},
+								},
+							},
+						},
+						"match_reasoning":    {Type: "string"},
+						"potential_concerns": {Type: "string"},
+					},
+					Required: []string{"username", "match_breakdown", "match_reasoning"},
+				},
+			},
+			"summary": {
+				Type: "object",
+				Properties: map[string]llm.Property{
+					"total_candidates_found": {Type: "integer"},
+					"candidates_presented":   {Type: "integer"},
+					"average_match_score":    {Type: "number"},
+					"search_quality":         {Type: "string"},
+				},
+			},
+		},
+		Required: []string{"top_candidates", "summary"},
+	},
+}
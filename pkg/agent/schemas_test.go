@@ -0,0 +1,41 @@
+package agent
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// TestSchemasMatchTypes guards against a stage schema drifting from the Go
+// type its response is decoded into
+func TestSchemasMatchTypes(t *testing.T) {
+	cases := []struct {
+		name   string
+		schema *llm.ResponseSchema
+		value  interface{}
+	}{
+		{"Requirements", requirementsSchema, Requirements{UnclearRequest: true, ClarificationQuestion: "?"}},
+		{"Strategy", strategySchema, SearchStrategy{}},
+		{"Ranking", rankingSchema, FinalResult{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _ := json.Marshal(tc.value)
+			var fields map[string]interface{}
+			json.Unmarshal(data, &fields)
+
+			for name := range tc.schema.Schema.Properties {
+				if _, ok := fields[name]; !ok {
+					t.Errorf("Schema property %q has no matching field in %T", name, tc.value)
+				}
+			}
+			for _, name := range tc.schema.Schema.Required {
+				if _, ok := tc.schema.Schema.Properties[name]; !ok {
+					t.Errorf("Required property %q is not defined", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// FailureSnapshot is the pipeline state dumped when a stage fails, so a
+// failure such as an unparseable ranking can be diagnosed after the fact
+type FailureSnapshot struct {
+	RunID      string                   `json:"run_id"`
+	Query      string                   `json:"query"`
+	Stage      string                   `json:"stage"`
+	Time       time.Time                `json:"time"`
+	Error      string                   `json:"error"`
+	ErrorClass observability.ErrorClass `json:"error_class"`
+
+	// Stage inputs and outputs produced before the failure
+	Requirements *Requirements       `json:"requirements,omitempty"`
+	Strategy     *SearchStrategy     `json:"strategy,omitempty"`
+	Candidates   *EnrichedCandidates `json:"candidates,omitempty"` // Partial when enrichment was interrupted
+
+	// ModelOutput is the LLM response that failed to decode, if any. It is
+	// also written verbatim to model_output.txt.
+	ModelOutput string `json:"model_output,omitempty"`
+}
+
+// writeFailureSnapshot dumps snapshot to a new timestamped directory under
+// dir and returns its path
+func writeFailureSnapshot(dir string, snapshot FailureSnapshot, err error) (string, error) {
+	snapshot.Error = err.Error()
+	snapshot.ErrorClass = observability.ClassifyError(err)
+	var decodeErr *llm.DecodeError
+	if errors.As(err, &decodeErr) {
+		snapshot.ModelOutput = decodeErr.Content
+	}
+
+	name := fmt.Sprintf("%s-%s-%s", snapshot.Time.UTC().Format("20060102T150405Z"), snapshot.Stage, snapshot.RunID)
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create failure snapshot directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal failure snapshot: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "snapshot.json"), append(data, '\n'), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write failure snapshot: %w", err)
+	}
+	if snapshot.ModelOutput != "" {
+		if err := os.WriteFile(filepath.Join(path, "model_output.txt"), []byte(snapshot.ModelOutput), 0o644); err != nil {
+			return "", fmt.Errorf("failed to write failure snapshot: %w", err)
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,112 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// SourceProvider is a code hosting platform the pipeline sources developers
+// from, such as github.Client. Every source speaks in GitHub's types: a
+// candidate's GitHubURL is their profile on the source, whichever it is.
+type SourceProvider interface {
+	// SearchDevelopers finds developers matching input, with their profiles
+	SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error)
+	GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error)
+	// GetRepositories returns up to maxRepos of a developer's repositories,
+	// most starred first
+	GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error)
+}
+
+var _ SourceProvider = (*github.Client)(nil)
+
+// NamedSource is a source platform with its name, e.g. gitlab
+type NamedSource struct {
+	Name string
+	SourceProvider
+}
+
+// MultiSource sources developers from several platforms at once, each
+// search returning every platform's developers, the first platform's first.
+// The first platform's usernames are unchanged, so stored history and
+// exclusions keep matching; the others' are qualified with their platform,
+// e.g. gitlab:ana, so each request reaches the developer's platform.
+type MultiSource []NamedSource
+
+var _ SourceProvider = MultiSource(nil)
+
+// SearchDevelopers runs input on every platform. Up to input.MaxResults
+// developers are returned per platform. A platform failing is logged and
+// left out; the search fails only when every platform does.
+func (m MultiSource) SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error) {
+	combined := &github.SearchResult{Candidates: []github.Candidate{}}
+	var errs []error
+	for i, source := range m {
+		result, err := source.SearchDevelopers(ctx, input)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			slog.Default().Warn("Source search failed; continuing with the other platforms", "platform", source.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", source.Name, err))
+			continue
+		}
+		for _, c := range result.Candidates {
+			c.Username = m.qualify(i, c.Username)
+			c.Platform = source.Name
+			combined.Candidates = append(combined.Candidates, c)
+		}
+		combined.TotalFound += result.TotalFound
+		if combined.SearchCriteria == nil {
+			combined.SearchCriteria = result.SearchCriteria
+		}
+	}
+	if len(errs) == len(m) && len(m) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return combined, nil
+}
+
+// GetUserDetail returns the profile of a username returned by SearchDevelopers
+func (m MultiSource) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	i, name := m.route(username)
+	detail, err := m[i].GetUserDetail(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	qualified := *detail
+	qualified.Login = m.qualify(i, detail.Login)
+	return &qualified, nil
+}
+
+// GetRepositories returns the repositories of a username returned by SearchDevelopers
+func (m MultiSource) GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error) {
+	i, name := m.route(username)
+	return m[i].GetRepositories(ctx, name, maxRepos)
+}
+
+// qualify prefixes username with the name of the i-th platform, unless it
+// is the first
+func (m MultiSource) qualify(i int, username string) string {
+	if i == 0 {
+		return username
+	}
+	return m[i].Name + ":" + username
+}
+
+// route returns the index of the platform username is on, and the username
+// on that platform
+func (m MultiSource) route(username string) (int, string) {
+	if platform, name, ok := strings.Cut(username, ":"); ok {
+		for i, source := range m[1:] {
+			if source.Name == platform {
+				return i + 1, name
+			}
+		}
+	}
+	return 0, username
+}
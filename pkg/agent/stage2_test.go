@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"context"
 	"testing"
 
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
@@ -33,7 +34,7 @@ Here are the requirements:
 		},
 	}
 
-	reqs, _, err := analyzeRequirements(client, "Find senior Go devs in Lima")
+	reqs, _, err := analyzeRequirements(context.Background(), client, "Find senior Go devs in Lima")
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -85,7 +86,7 @@ func TestGenerateSearchStrategy(t *testing.T) {
 	}
 	reqs := &Requirements{RequiredSkills: []string{"Go"}}
 
-	strategy, _, err := generateSearchStrategy(client, reqs)
+	strategy, _, err := generateSearchStrategy(context.Background(), client, reqs)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
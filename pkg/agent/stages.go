@@ -0,0 +1,178 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// PipelineState carries stage outputs between separately run stages. Its
+// JSON fields match FinalResult and FailureSnapshot, so a saved result or a
+// snapshot of a failed run can be fed back into EnrichCandidates or
+// RankCandidates.
+type PipelineState struct {
+	RunID        string              `json:"run_id,omitempty"`
+	Query        string              `json:"query,omitempty"`
+	Requirements *Requirements       `json:"requirements,omitempty"`
+	Strategy     *SearchStrategy     `json:"strategy,omitempty"`
+	Candidates   *EnrichedCandidates `json:"candidates,omitempty"`
+}
+
+// EnrichCandidates runs only the search and enrichment stage of RunStage2,
+// executing strategy against GitHub. It makes no LLM calls.
+func EnrichCandidates(ctx context.Context, source SourceProvider, requirements *Requirements, strategy *SearchStrategy, config AgentConfig) (*EnrichedCandidates, error) {
+	if requirements == nil || strategy == nil {
+		return nil, fmt.Errorf("enrichment needs requirements and a search strategy")
+	}
+	if err := strategy.Validate(); err != nil {
+		return nil, observability.WithErrorClass(observability.ErrorClassValidation, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	_, logger, events := config.start()
+
+	events.Publish(observability.StageStarted{Stage: StageEnrichment})
+	start := time.Now()
+	candidates, err := findAndEnrichCandidates(ctx, nil, source, strategy, requirements, events, config)
+	if err != nil {
+		events.Publish(observability.StageFailed{Stage: StageEnrichment, Err: err})
+		return nil, fmt.Errorf("candidate search failed: %w", err)
+	}
+	logger.Info("Stage complete", "stage", StageEnrichment, "duration", time.Since(start),
+		"candidates_found", candidates.SearchMetadata.TotalProfilesFound,
+		"candidates_analyzed", candidates.SearchMetadata.ProfilesAnalyzed)
+	return candidates, nil
+}
+
+// RankCandidates runs only the ranking stage of RunStage2 on previously
+// enriched candidates. Unlike RunStage2 it does not fall back to unranked
+// results, so rerunning a failed ranking shows why it failed.
+func RankCandidates(ctx context.Context, client llm.Client, requirements *Requirements, candidates *EnrichedCandidates, config AgentConfig) (*FinalResult, error) {
+	if requirements == nil || candidates == nil {
+		return nil, fmt.Errorf("ranking needs requirements and enriched candidates")
+	}
+	if err := candidates.Validate(); err != nil {
+		return nil, observability.WithErrorClass(observability.ErrorClassValidation, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	runID, logger, events := config.start()
+	ctx, cancel, client := config.limit(ctx, client)
+	defer cancel()
+
+	candidates = config.exclusions().filter(candidates)
+	events.Publish(observability.StageStarted{Stage: StageRanking})
+	start := time.Now()
+	result, usage, err := rankAndPresent(ctx, client, candidates, requirements, rankingOptions{
+		scoring:     config.scoring(ctx, requirements),
+		targetCount: config.TargetCount,
+		language:    config.language(),
+		logger:      logger,
+		events:      events,
+	})
+	if err != nil {
+		events.Publish(observability.StageFailed{Stage: StageRanking, Err: err})
+		return nil, fmt.Errorf("ranking failed: %w", err)
+	}
+	logger.Info("Stage complete", "stage", StageRanking, "duration", time.Since(start),
+		"input_tokens", usage.InputTokens, "output_tokens", usage.OutputTokens)
+
+	result.RunID = runID
+	result.Requirements = requirements
+	result.SearchMetadata = &candidates.SearchMetadata
+	return result, nil
+}
+
+// EnrichProfile enriches a single GitHub user the way the enrichment stage
+// does, scoring their repositories against requiredSkills and keywords
+func EnrichProfile(ctx context.Context, source SourceProvider, username string, requiredSkills, keywords []string) (*EnrichedCandidate, error) {
+	return enrichProfile(ctx, source, username, requiredSkills, keywords, DefaultRelevanceThreshold)
+}
+
+// AssessProfile evaluates a single GitHub user against a query, e.g. an
+// inbound applicant: it analyzes the query's requirements, enriches the
+// user's profile and repositories, and ranks them as the only candidate.
+// The result has exactly one candidate.
+func AssessProfile(ctx context.Context, client llm.Client, source SourceProvider, username, query string, config AgentConfig) (*FinalResult, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	runID, logger, events := config.start()
+	ctx, cancel, client := config.limit(ctx, client)
+	defer cancel()
+
+	events.Publish(observability.StageStarted{Stage: StageRequirements})
+	requirements, _, err := analyzeRequirements(ctx, client, query)
+	if err != nil {
+		events.Publish(observability.StageFailed{Stage: StageRequirements, Err: err})
+		return nil, fmt.Errorf("requirements analysis failed: %w", err)
+	}
+	if requirements.UnclearRequest {
+		return nil, fmt.Errorf("%w: %s", ErrUnclearRequest, requirements.ClarificationQuestion)
+	}
+	logger.Debug("Requirements analyzed", "requirements", requirements)
+
+	events.Publish(observability.StageStarted{Stage: StageEnrichment})
+	candidate, err := enrichProfile(ctx, source, username, requirements.RequiredSkills, requirements.Keywords, config.relevanceThreshold())
+	if err != nil {
+		events.Publish(observability.StageFailed{Stage: StageEnrichment, Err: err})
+		return nil, err
+	}
+	events.Publish(observability.CandidateEnriched{Username: candidate.Username, RelevantRepositories: len(candidate.RelevantRepositories)})
+	runEnrichers(ctx, config.Enrichers, requirements, candidate, events)
+
+	events.Publish(observability.StageStarted{Stage: StageRanking})
+	candidates := &EnrichedCandidates{
+		Candidates:     []EnrichedCandidate{*candidate},
+		SearchMetadata: SearchMetadata{TotalProfilesFound: 1, ProfilesAnalyzed: 1},
+	}
+	result, _, err := rankAndPresent(ctx, client, candidates, requirements, rankingOptions{
+		scoring:     config.scoring(ctx, requirements),
+		targetCount: 1,
+		language:    config.language(),
+		logger:      logger,
+		events:      events,
+	})
+	if err == nil && len(result.TopCandidates) == 0 {
+		err = fmt.Errorf("the model returned no assessment of %s", candidate.Username)
+	}
+	if err != nil {
+		events.Publish(observability.StageFailed{Stage: StageRanking, Err: err})
+		return nil, fmt.Errorf("assessment failed: %w", err)
+	}
+
+	result.RunID = runID
+	result.Requirements = requirements
+	result.SearchMetadata = &candidates.SearchMetadata
+	return result, nil
+}
+
+// enrichProfile enriches username, counting repositories scoring above threshold as relevant
+func enrichProfile(ctx context.Context, source SourceProvider, username string, requiredSkills, keywords []string, threshold float64) (*EnrichedCandidate, error) {
+	user, err := source.GetUserDetail(ctx, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get profile: %w", err)
+	}
+	candidate, err := enrichCandidate(ctx, source, github.Candidate{
+		Username:    user.Login,
+		Name:        user.Name,
+		Location:    user.Location,
+		Company:     user.Company,
+		Bio:         user.Bio,
+		Blog:        user.Blog,
+		PublicRepos: user.PublicRepos,
+		Followers:   user.Followers,
+		GitHubURL:   user.HTMLURL,
+		AvatarURL:   user.AvatarURL,
+	}, requiredSkills, keywords, threshold)
+	if err != nil {
+		return nil, err
+	}
+	return &candidate, nil
+}
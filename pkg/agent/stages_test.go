@@ -0,0 +1,209 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+func TestRunStagesSeparately(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+	ctx := context.Background()
+
+	// Keep the recorded ranking response to replay it when ranking alone
+	golden := goldenLLMClient(t, "stage2_go_lima")
+	var rankingResp *llm.Response
+	client := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		resp, err := golden.CallAPI(ctx, messages, tools, opts...)
+		if llm.ApplyOptions(opts).Stage == StageRanking {
+			rankingResp = resp
+		}
+		return resp, err
+	})
+	full, err := RunStage2(ctx, client, githubClient, "Find senior Go backend developers in Lima")
+	if err != nil {
+		t.Fatalf("RunStage2 failed: %v", err)
+	}
+
+	// A saved result carries what the enrichment stage needs
+	data, _ := json.Marshal(full)
+	var state PipelineState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatalf("Expected a result to decode as pipeline state, got %v", err)
+	}
+
+	candidates, err := EnrichCandidates(ctx, githubClient, state.Requirements, state.Strategy, AgentConfig{})
+	if err != nil {
+		t.Fatalf("EnrichCandidates failed: %v", err)
+	}
+	if len(candidates.Candidates) != 2 {
+		t.Fatalf("Expected 2 enriched candidates, got %d", len(candidates.Candidates))
+	}
+
+	replay := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		return rankingResp, nil
+	})
+	result, err := RankCandidates(ctx, replay, state.Requirements, candidates, AgentConfig{RunID: "rerun"})
+	if err != nil {
+		t.Fatalf("RankCandidates failed: %v", err)
+	}
+	if result.RunID != "rerun" || len(result.TopCandidates) != len(full.TopCandidates) ||
+		result.TopCandidates[0].Username != full.TopCandidates[0].Username {
+		t.Errorf("Expected the same ranking as the full run, got %+v", result.TopCandidates)
+	}
+}
+
+func TestStagesNeedInputs(t *testing.T) {
+	if _, err := EnrichCandidates(context.Background(), nil, &Requirements{}, nil, AgentConfig{}); err == nil {
+		t.Error("Expected error for enrichment without a strategy")
+	}
+	if _, err := RankCandidates(context.Background(), nil, nil, &EnrichedCandidates{}, AgentConfig{}); err == nil {
+		t.Error("Expected error for ranking without requirements")
+	}
+}
+
+func TestEnrichProfile(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+
+	candidate, err := EnrichProfile(context.Background(), githubClient, "gopher_lima", []string{"Go"}, []string{"microservices"})
+	if err != nil {
+		t.Fatalf("EnrichProfile failed: %v", err)
+	}
+	if candidate.Name != "Ana Quispe" || candidate.GitHubURL != "https://github.com/gopher_lima" {
+		t.Errorf("Expected the profile details, got %+v", candidate)
+	}
+	if len(candidate.RelevantRepositories) != 1 || candidate.RelevantRepositories[0].Name != "grpc-gateway-kit" {
+		t.Errorf("Expected only the Go microservices repository kept, got %+v", candidate.RelevantRepositories)
+	}
+
+	if _, err := EnrichProfile(context.Background(), githubClient, "nobody", nil, nil); err == nil {
+		t.Error("Expected error for an unknown user")
+	}
+}
+
+// fakeSource serves one developer with one Go repository
+type fakeSource struct{}
+
+func (fakeSource) SearchDevelopers(context.Context, github.ToolInput) (*github.SearchResult, error) {
+	return &github.SearchResult{Candidates: []github.Candidate{{Username: "ana"}}}, nil
+}
+
+func (fakeSource) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	if username != "ana" {
+		return nil, errors.New("not found")
+	}
+	return &github.UserDetail{Login: "ana", Name: "Ana", HTMLURL: "https://gitlab.com/ana"}, nil
+}
+
+func (fakeSource) GetRepositories(context.Context, string, int) ([]github.Repository, error) {
+	return []github.Repository{{Name: "gateway", Language: "Go", URL: "https://gitlab.com/ana/gateway"}}, nil
+}
+
+func TestEnrichProfileFromSource(t *testing.T) {
+	candidate, err := EnrichProfile(context.Background(), fakeSource{}, "ana", []string{"Go"}, nil)
+	if err != nil {
+		t.Fatalf("EnrichProfile failed: %v", err)
+	}
+	if candidate.GitHubURL != "https://gitlab.com/ana" || len(candidate.SkillsFound) != 1 || candidate.SkillsFound[0] != "Go" {
+		t.Errorf("Expected the source's profile and its repository's language, got %+v", candidate)
+	}
+}
+
+func TestAssessProfile(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+
+	var rankingPrompt string
+	client := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		text := `{"required_skills": ["Go"], "keywords": ["microservices"], "locations": ["Lima"]}`
+		if llm.ApplyOptions(opts).Stage == StageRanking {
+			data, _ := json.Marshal(messages)
+			rankingPrompt = string(data)
+			text = `{"top_candidates": [{"username": "gopher_lima", "name": "Ana Quispe",
+				"match_breakdown": {"required_skills_score": 90, "repository_relevance_score": 80, "experience_score": 70, "profile_quality_score": 60},
+				"match_reasoning": "Go microservices in Lima"}],
+				"summary": {"candidates_presented": 1}}`
+		}
+		return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: text}}}, nil
+	})
+
+	result, err := AssessProfile(context.Background(), client, githubClient, "gopher_lima", "Go backend developer in Lima", AgentConfig{RunID: "inbound"})
+	if err != nil {
+		t.Fatalf("AssessProfile failed: %v", err)
+	}
+	if !strings.Contains(rankingPrompt, "gopher_lima") || strings.Contains(rankingPrompt, "rustacean_pe") {
+		t.Errorf("Expected only the assessed user in the ranking prompt, got %s", rankingPrompt)
+	}
+	if result.RunID != "inbound" || len(result.TopCandidates) != 1 || result.TopCandidates[0].Rank != 1 {
+		t.Fatalf("Expected a single ranked candidate, got %+v", result)
+	}
+	if result.TopCandidates[0].FinalMatchScore != 80 || result.Requirements.RequiredSkills[0] != "Go" {
+		t.Errorf("Expected the scored assessment with its requirements, got %+v", result)
+	}
+}
+
+// fakeEnricher adds profile to each candidate, or fails with err
+type fakeEnricher struct {
+	profile ExternalProfile
+	err     error
+}
+
+func (e fakeEnricher) Name() string { return e.profile.Platform }
+
+func (e fakeEnricher) Enrich(_ context.Context, candidate *EnrichedCandidate) error {
+	if e.err != nil {
+		return e.err
+	}
+	candidate.ExternalProfiles = append(candidate.ExternalProfiles, e.profile)
+	return nil
+}
+
+func TestAssessProfileWithEnrichers(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+
+	var rankingPrompt string
+	client := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		text := `{"required_skills": ["Go"], "keywords": ["microservices"]}`
+		if llm.ApplyOptions(opts).Stage == StageRanking {
+			data, _ := json.Marshal(messages)
+			rankingPrompt = string(data)
+			text = `{"top_candidates": [{"username": "gopher_lima", "match_reasoning": "Go"}], "summary": {"candidates_presented": 1}}`
+		}
+		return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: text}}}, nil
+	})
+
+	var lookups []observability.ExternalProfileLookup
+	config := AgentConfig{
+		Enrichers: []Enricher{
+			fakeEnricher{err: errors.New("quota exhausted"), profile: ExternalProfile{Platform: "down"}},
+			fakeEnricher{profile: ExternalProfile{Platform: "stackoverflow", Confidence: 0.9, MatchedBy: MatchedByName, Reputation: 5400, TopTags: []string{"go"}}},
+		},
+		Events: observability.NewEventBus(func(e observability.Event) {
+			if lookup, ok := e.(observability.ExternalProfileLookup); ok {
+				lookups = append(lookups, lookup)
+			}
+		}),
+	}
+	if _, err := AssessProfile(context.Background(), client, githubClient, "gopher_lima", "Go backend developer", config); err != nil {
+		t.Fatalf("Expected the failing enricher to be skipped, got %v", err)
+	}
+	if !strings.Contains(rankingPrompt, `\"reputation\":5400`) {
+		t.Errorf("Expected the external profile in the ranking prompt, got %s", rankingPrompt)
+	}
+	if len(lookups) != 2 || lookups[0].Err == nil || lookups[1].Found != 1 {
+		t.Errorf("Expected a failed and a successful lookup, got %+v", lookups)
+	}
+}
@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// RunRecord is a run as persisted by a RunStore: the run's checkpoint, its
+// start time and, once ranked, its result
+type RunRecord struct {
+	Checkpoint
+	StartedAt time.Time
+	// Result is set once the run finished, with unranked candidates when
+	// ranking failed
+	Result *FinalResult
+}
+
+// RunStore persists runs so their queries, requirements, strategies,
+// candidates and rankings outlive the process, e.g. storage.Store. SaveRun
+// is called after every completed stage with the run so far, and once with
+// its result, replacing what was saved of the run before.
+type RunStore interface {
+	SaveRun(ctx context.Context, run RunRecord) error
+}
+
+// CandidateHistory is implemented by a RunStore that remembers the
+// candidates of earlier runs, e.g. storage.Store. The ranked candidates of a
+// run it has seen before are flagged with SeenBefore.
+type CandidateHistory interface {
+	// SeenBefore returns what runs other than runID knew of the usernames
+	// they surfaced, by username as given
+	SeenBefore(ctx context.Context, runID string, usernames []string) (map[string]SeenBefore, error)
+}
+
+// SeenBefore tells that earlier runs surfaced a candidate, for recruiters to
+// spot candidates they have already reviewed or contacted
+type SeenBefore struct {
+	// The latest earlier run that surfaced the candidate, with the score it
+	// ranked them with, if it ranked them
+	RunID           string    `json:"run_id"`
+	Query           string    `json:"query"`
+	Time            time.Time `json:"time"`
+	FinalMatchScore *float64  `json:"final_match_score,omitempty"`
+	Runs            int       `json:"runs"` // Number of earlier runs that surfaced the candidate
+	// ContactedAt is when the candidate was last contacted, if ever
+	ContactedAt *time.Time `json:"contacted_at,omitempty"`
+	// The candidate's pipeline status and tags in the talent pool, if kept
+	Status string   `json:"status,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// saveRun saves run to the configured store, if any. A failure is logged
+// and leaves the run going, as with checkpoints.
+func (c AgentConfig) saveRun(ctx context.Context, run RunRecord) {
+	if c.Store == nil {
+		return
+	}
+	// A run cut short by its deadline is still worth keeping
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := c.Store.SaveRun(ctx, run); err != nil {
+		c.logger().Warn("Run not stored", "run_id", run.RunID, "stage", run.Stage, "error", err)
+	}
+}
+
+// flagSeenBefore sets SeenBefore on the candidates of result the configured
+// store has seen in other runs. A failure is logged and leaves them unflagged.
+func (c AgentConfig) flagSeenBefore(ctx context.Context, result *FinalResult) {
+	history, ok := c.Store.(CandidateHistory)
+	if !ok || len(result.TopCandidates) == 0 {
+		return
+	}
+	usernames := make([]string, len(result.TopCandidates))
+	for i, candidate := range result.TopCandidates {
+		usernames[i] = candidate.Username
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	seen, err := history.SeenBefore(ctx, result.RunID, usernames)
+	if err != nil {
+		c.logger().Warn("Candidate history not read", "run_id", result.RunID, "error", err)
+		return
+	}
+	for i := range result.TopCandidates {
+		if before, ok := seen[result.TopCandidates[i].Username]; ok {
+			result.TopCandidates[i].SeenBefore = &before
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// runStoreFunc adapts a function to RunStore
+type runStoreFunc func(ctx context.Context, run RunRecord) error
+
+func (f runStoreFunc) SaveRun(ctx context.Context, run RunRecord) error { return f(ctx, run) }
+
+func TestRunStore(t *testing.T) {
+	server := newPipelineGitHubServer(t)
+	defer server.Close()
+	githubClient := &github.Client{BaseURL: server.URL, Token: "test-token", HTTPClient: server.Client()}
+
+	var saved []RunRecord
+	store := runStoreFunc(func(ctx context.Context, run RunRecord) error {
+		saved = append(saved, run)
+		return errors.New("database is locked")
+	})
+	config := AgentConfig{RunID: "run-9", Store: store, Logger: slog.New(slog.DiscardHandler)}
+	result, err := RunStage2WithConfig(context.Background(), goldenLLMClient(t, "stage2_go_lima"), githubClient, "Find senior Go backend developers in Lima", config)
+	if err != nil {
+		t.Fatalf("Expected the run to succeed although the store fails, got %v", err)
+	}
+
+	var stages []string
+	for _, run := range saved {
+		stages = append(stages, run.Stage)
+		if run.RunID != "run-9" || run.StartedAt.IsZero() || !run.StartedAt.Equal(saved[0].StartedAt) {
+			t.Errorf("Expected every save of run-9 with its start, got %+v", run)
+		}
+	}
+	if !slices.Equal(stages, []string{StageRequirements, StageStrategy, StageEnrichment, StageRanking}) {
+		t.Fatalf("Expected the run saved after every stage, got %v", stages)
+	}
+	if saved[1].Result != nil || saved[1].Strategy == nil || saved[1].Candidates != nil {
+		t.Errorf("Expected the strategy without candidates or result, got %+v", saved[1])
+	}
+	last := saved[len(saved)-1]
+	if last.Result != result || last.Candidates == nil {
+		t.Errorf("Expected the last save to carry the candidates and result, got %+v", last)
+	}
+}
+
+// historyStore is a RunStore remembering candidates of earlier runs
+type historyStore struct {
+	runStoreFunc
+	seen map[string]SeenBefore
+}
+
+func (s historyStore) SeenBefore(ctx context.Context, runID string, usernames []string) (map[string]SeenBefore, error) {
+	seen := make(map[string]SeenBefore)
+	for _, username := range usernames {
+		if before, ok := s.seen[username]; ok {
+			seen[username] = before
+		}
+	}
+	return seen, nil
+}
+
+func TestFlagSeenBefore(t *testing.T) {
+	store := historyStore{
+		runStoreFunc: func(ctx context.Context, run RunRecord) error { return nil },
+		seen:         map[string]SeenBefore{"bob": {RunID: "run-1", Runs: 2}},
+	}
+	result := &FinalResult{RunID: "run-2", TopCandidates: []RankedCandidate{{Username: "alice"}, {Username: "bob"}}}
+	AgentConfig{Store: store}.flagSeenBefore(context.Background(), result)
+	if result.TopCandidates[0].SeenBefore != nil {
+		t.Errorf("Expected alice not flagged, got %+v", result.TopCandidates[0].SeenBefore)
+	}
+	if seen := result.TopCandidates[1].SeenBefore; seen == nil || seen.RunID != "run-1" || seen.Runs != 2 {
+		t.Errorf("Expected bob flagged as seen in run-1, got %+v", seen)
+	}
+	if note := SeenBeforeNote(result.TopCandidates[1].SeenBefore); !strings.HasPrefix(note, "seen in run run-1 (") || !strings.HasSuffix(note, "and 1 other run") {
+		t.Errorf("Expected a note on run-1 and 1 other run, got %q", note)
+	}
+}
@@ -0,0 +1,189 @@
+package agent
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// External call categories timed in RunTimings
+const (
+	CallCategoryLLM    = "llm"
+	CallCategoryGitHub = "github"
+	// CallCategorySource times the calls to sources other than GitHub, one
+	// per search, profile or repository listing
+	CallCategorySource = "source"
+)
+
+// RunTimings breaks the wall-clock time of a run down by pipeline stage and
+// by category of external call, to show whether enrichment or ranking dominates
+type RunTimings struct {
+	TotalMS int64         `json:"total_ms"`
+	Stages  []StageTiming `json:"stages"`
+	Calls   []CallTiming  `json:"calls,omitempty"`
+}
+
+// StageTiming is the wall-clock time of one pipeline stage
+type StageTiming struct {
+	Stage      string `json:"stage"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// CallTiming is the time spent waiting on one category of external call.
+// Calls made concurrently each count their full duration.
+type CallTiming struct {
+	Category   string `json:"category"`
+	Calls      int    `json:"calls"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// runTimer accumulates RunTimings while a run progresses
+type runTimer struct {
+	start time.Time
+
+	mu     sync.Mutex
+	stages []StageTiming
+	calls  map[string]*callTotal
+}
+
+type callTotal struct {
+	calls    int
+	duration time.Duration
+}
+
+func newRunTimer() *runTimer {
+	return &runTimer{start: time.Now(), calls: make(map[string]*callTotal)}
+}
+
+// stage records a stage that started at start and just finished
+func (t *runTimer) stage(name string, start time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stages = append(t.stages, StageTiming{Stage: name, DurationMS: time.Since(start).Milliseconds()})
+}
+
+// call records an external call of category that started at start and just finished
+func (t *runTimer) call(category string, start time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	total, ok := t.calls[category]
+	if !ok {
+		total = &callTotal{}
+		t.calls[category] = total
+	}
+	total.calls++
+	total.duration += time.Since(start)
+}
+
+// timings returns the timings recorded so far
+func (t *runTimer) timings() *RunTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := &RunTimings{
+		TotalMS: time.Since(t.start).Milliseconds(),
+		Stages:  append([]StageTiming(nil), t.stages...),
+	}
+	for _, category := range []string{CallCategoryLLM, CallCategoryGitHub, CallCategorySource} {
+		if total, ok := t.calls[category]; ok {
+			out.Calls = append(out.Calls, CallTiming{Category: category, Calls: total.calls, DurationMS: total.duration.Milliseconds()})
+		}
+	}
+	return out
+}
+
+// timedClient times every LLM call made through it
+type timedClient struct {
+	Wrapped llm.Client
+	timer   *runTimer
+}
+
+func (c *timedClient) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	defer c.timer.call(CallCategoryLLM, time.Now())
+	return c.Wrapped.CallAPI(ctx, messages, tools, opts...)
+}
+
+// Unwrap returns the wrapped client
+func (c *timedClient) Unwrap() llm.Client { return c.Wrapped }
+
+// timedTransport times every HTTP request sent through it
+type timedTransport struct {
+	Transport http.RoundTripper
+	category  string
+	timer     *runTimer
+}
+
+func (t *timedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	defer t.timer.call(t.category, time.Now())
+	return t.Transport.RoundTrip(req)
+}
+
+// timedSource returns source with its calls timed. A github.Client is copied
+// with its requests timed and its logs tagged with runID, leaving the
+// caller's client untouched; other sources are timed call by call.
+func timedSource(source SourceProvider, timer *runTimer, runID string) SourceProvider {
+	switch s := source.(type) {
+	case nil:
+		return nil
+	case *github.Client:
+		if s == nil {
+			return nil
+		}
+		timed := timedGitHubClient(s, timer)
+		logger := timed.Logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		timed.Logger = logger.With("run_id", runID)
+		return timed
+	default:
+		return &timedSourceProvider{Wrapped: source, timer: timer}
+	}
+}
+
+// timedSourceProvider times every call made through it
+type timedSourceProvider struct {
+	Wrapped SourceProvider
+	timer   *runTimer
+}
+
+func (s *timedSourceProvider) SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error) {
+	defer s.timer.call(CallCategorySource, time.Now())
+	return s.Wrapped.SearchDevelopers(ctx, input)
+}
+
+func (s *timedSourceProvider) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	defer s.timer.call(CallCategorySource, time.Now())
+	return s.Wrapped.GetUserDetail(ctx, username)
+}
+
+func (s *timedSourceProvider) GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error) {
+	defer s.timer.call(CallCategorySource, time.Now())
+	return s.Wrapped.GetRepositories(ctx, username, maxRepos)
+}
+
+// timedGitHubClient returns a copy of client whose requests are timed, leaving
+// the caller's client untouched
+func timedGitHubClient(client *github.Client, timer *runTimer) *github.Client {
+	if client == nil {
+		return nil
+	}
+	timed := *client
+	httpClient := http.DefaultClient
+	if client.HTTPClient != nil {
+		httpClient = client.HTTPClient
+	}
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	wrapped := *httpClient
+	wrapped.Transport = &timedTransport{Transport: transport, category: CallCategoryGitHub, timer: timer}
+	timed.HTTPClient = &wrapped
+	return &timed
+}
@@ -51,18 +51,64 @@ type EnrichedCandidate struct {
 	Username             string               `json:"username"`
 	Name                 string               `json:"name"`
 	Location             string               `json:"location"`
+	Company              string               `json:"company,omitempty"`
 	Bio                  string               `json:"bio"`
 	PublicRepos          int                  `json:"public_repos"`
 	Followers            int                  `json:"followers"`
 	GitHubURL            string               `json:"github_url"`
+	Blog                 string               `json:"blog,omitempty"` // Website linked from the profile
 	RelevantRepositories []RelevantRepository `json:"relevant_repositories"`
 	SkillsFound          []string             `json:"skills_found"`
 	ExperienceIndicators ExperienceIndicators `json:"experience_indicators"`
 	InitialMatchScore    float64              `json:"initial_match_score"`
-}
+	// ExternalProfiles are the candidate's profiles on other platforms,
+	// found by the run's Enrichers
+	ExternalProfiles []ExternalProfile `json:"external_profiles,omitempty"`
+	// MLExperienceScore (0-100) rates the machine learning work published
+	// on ExternalProfiles; set only for machine learning roles
+	MLExperienceScore *float64 `json:"ml_experience_score,omitempty"`
+	// Platform is the source the candidate was found on, set when a run
+	// sources from several
+	Platform string `json:"platform,omitempty"`
+	// Email is the public email of the profile, used to resolve identities
+	// and never sent to the model or saved
+	Email string `json:"-"`
+	// Identities are the profiles on several source platforms merged into
+	// the candidate, the candidate's own first
+	Identities []Identity `json:"identities,omitempty"`
+	// MergeConfidence (0-1) is the lowest confidence of the merged
+	// Identities that they are one person
+	MergeConfidence float64 `json:"merge_confidence,omitempty"`
+}
+
+// ExternalProfile is a candidate's profile on a platform other than the
+// source, e.g. Stack Overflow, as an additional experience signal
+type ExternalProfile struct {
+	Platform    string `json:"platform"` // e.g. stackoverflow
+	URL         string `json:"url"`
+	DisplayName string `json:"display_name,omitempty"`
+	// Confidence (0-1) that the profile is the candidate's: 1 when linked
+	// from their profile, lower when matched by name
+	Confidence float64 `json:"confidence"`
+	MatchedBy  string  `json:"matched_by"` // MatchedByLink or MatchedByName
+	Reputation int     `json:"reputation,omitempty"`
+	// TopTags are the topics the candidate is most active in, best first
+	TopTags []string `json:"top_tags,omitempty"`
+	// Metrics count the candidate's published work, e.g. MetricModels
+	Metrics map[string]int `json:"metrics,omitempty"`
+	// Highlights name the candidate's most used work, e.g. model IDs
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+// How an ExternalProfile was matched to a candidate
+const (
+	MatchedByLink = "link" // Linked from the candidate's profile
+	MatchedByName = "name" // Found by name, with heuristics for the confidence
+)
 
 type RelevantRepository struct {
 	Name            string   `json:"name"`
+	URL             string   `json:"url,omitempty"`
 	Description     string   `json:"description"`
 	Language        string   `json:"language"`
 	Stars           int      `json:"stars"`
@@ -81,12 +127,28 @@ type SearchMetadata struct {
 	SearchesExecuted   int `json:"searches_executed"`
 	TotalProfilesFound int `json:"total_profiles_found"`
 	ProfilesAnalyzed   int `json:"profiles_analyzed"`
+	CandidatesExcluded int `json:"candidates_excluded,omitempty"` // Dropped by AgentConfig.Exclude
+	ProfilesMerged     int `json:"profiles_merged,omitempty"`     // Merged into another platform's profile of the same person
 }
 
 // Final Result structure (output of Prompt 4)
 type FinalResult struct {
+	RunID         string            `json:"run_id,omitempty"` // Set by RunStage2
 	TopCandidates []RankedCandidate `json:"top_candidates"`
 	Summary       ResultSummary     `json:"summary"`
+	Timings       *RunTimings       `json:"timings,omitempty"` // Set by RunStage2
+	// Partial is set when ranking failed and the candidates are presented
+	// unranked, by their initial match score
+	Partial bool `json:"partial,omitempty"`
+
+	// What the ranking was based on, set by RunStage2 so a run can be
+	// explained after the fact, e.g. in a run report
+	Requirements   *Requirements   `json:"requirements,omitempty"`
+	Strategy       *SearchStrategy `json:"strategy,omitempty"`
+	SearchMetadata *SearchMetadata `json:"search_metadata,omitempty"`
+	// Scoring is the scoring learned from feedback the candidates were
+	// ranked with, unset for the default weights
+	Scoring *Scoring `json:"scoring,omitempty"`
 }
 
 type RankedCandidate struct {
@@ -101,6 +163,12 @@ type RankedCandidate struct {
 	TopRelevantProjects []RelevantProject `json:"top_relevant_projects"`
 	MatchReasoning      string            `json:"match_reasoning"`
 	PotentialConcerns   string            `json:"potential_concerns,omitempty"`
+	// SeenBefore is set when earlier stored runs surfaced the candidate
+	SeenBefore *SeenBefore `json:"seen_before,omitempty"`
+	// Identities and MergeConfidence are copied from the enriched candidate
+	// when profiles on several platforms were merged into it
+	Identities      []Identity `json:"identities,omitempty"`
+	MergeConfidence float64    `json:"merge_confidence,omitempty"`
 }
 
 type MatchBreakdown struct {
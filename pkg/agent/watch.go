@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// WatchState is what a watch remembers between runs of its query: every
+// candidate it has reported, so a candidate drifting in and out of the
+// ranking is only reported the first time it surfaces
+type WatchState struct {
+	Query     string               `json:"query"`
+	Runs      int                  `json:"runs"`
+	LastRun   time.Time            `json:"last_run,omitempty"`
+	LastRunID string               `json:"last_run_id,omitempty"`
+	Seen      map[string]time.Time `json:"seen"` // When each username was first reported, lowercased
+}
+
+// NewWatchState returns the state of a watch of query that has not run yet
+func NewWatchState(query string) *WatchState {
+	return &WatchState{Query: query, Seen: make(map[string]time.Time)}
+}
+
+// LoadWatchState reads the watch state saved at path, or returns a new state
+// for query when there is none. A state saved for another query is an error,
+// as diffing against it would report every candidate.
+func LoadWatchState(path, query string) (*WatchState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewWatchState(query), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state: %w", err)
+	}
+	var state WatchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state: %w", err)
+	}
+	if state.Query != query {
+		return nil, fmt.Errorf("watch state %s is for the query %q", path, state.Query)
+	}
+	if state.Seen == nil {
+		state.Seen = make(map[string]time.Time)
+	}
+	return &state, nil
+}
+
+// Save writes the state to path, replacing the previous one
+func (s *WatchState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal watch state: %w", err)
+	}
+	if err := writeFileAtomic(path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write watch state: %w", err)
+	}
+	return nil
+}
+
+// Diff records a run of the watched query at now and returns result with
+// only the candidates no earlier run surfaced. They keep their rank, which
+// places them in the whole ranking.
+func (s *WatchState) Diff(result *FinalResult, now time.Time) *FinalResult {
+	diff := *result
+	diff.TopCandidates = nil
+	for _, c := range result.TopCandidates {
+		key := strings.ToLower(c.Username)
+		if _, seen := s.Seen[key]; seen {
+			continue
+		}
+		s.Seen[key] = now
+		diff.TopCandidates = append(diff.TopCandidates, c)
+	}
+	s.Runs++
+	s.LastRun = now
+	s.LastRunID = result.RunID
+	return &diff
+}
@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchStateDiff(t *testing.T) {
+	state := NewWatchState("Find Go developers in Lima")
+	first := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	result := &FinalResult{RunID: "run-1", TopCandidates: []RankedCandidate{
+		{Rank: 1, Username: "alice"},
+		{Rank: 2, Username: "bob"},
+	}}
+	if diff := state.Diff(result, first); len(diff.TopCandidates) != 2 {
+		t.Fatalf("Expected every candidate of the first run, got %+v", diff.TopCandidates)
+	}
+
+	// bob drops out and returns, carol is new
+	second := first.Add(24 * time.Hour)
+	result = &FinalResult{RunID: "run-2", TopCandidates: []RankedCandidate{
+		{Rank: 1, Username: "Alice"},
+		{Rank: 2, Username: "carol"},
+	}}
+	diff := state.Diff(result, second)
+	if len(diff.TopCandidates) != 1 || diff.TopCandidates[0].Username != "carol" || diff.TopCandidates[0].Rank != 2 {
+		t.Fatalf("Expected only carol at rank 2, got %+v", diff.TopCandidates)
+	}
+	if len(result.TopCandidates) != 2 {
+		t.Errorf("Expected the result to be left whole, got %+v", result.TopCandidates)
+	}
+	result = &FinalResult{RunID: "run-3", TopCandidates: []RankedCandidate{{Rank: 1, Username: "bob"}}}
+	if diff := state.Diff(result, second.Add(time.Hour)); len(diff.TopCandidates) != 0 {
+		t.Errorf("Expected a returning candidate not to be reported, got %+v", diff.TopCandidates)
+	}
+	if state.Runs != 3 || state.LastRunID != "run-3" || !state.Seen["carol"].Equal(second) {
+		t.Errorf("Expected 3 runs and carol first seen at %v, got %+v", second, state)
+	}
+}
+
+func TestLoadWatchState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "watch.json")
+	query := "Find Go developers in Lima"
+
+	state, err := LoadWatchState(path, query)
+	if err != nil {
+		t.Fatalf("Expected a new state without a file, got %v", err)
+	}
+	state.Diff(&FinalResult{RunID: "run-1", TopCandidates: []RankedCandidate{{Rank: 1, Username: "alice"}}}, time.Now())
+	if err := state.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadWatchState(path, query)
+	if err != nil {
+		t.Fatalf("LoadWatchState failed: %v", err)
+	}
+	if _, ok := loaded.Seen["alice"]; !ok || loaded.Runs != 1 {
+		t.Errorf("Expected the saved state, got %+v", loaded)
+	}
+	if _, err := LoadWatchState(path, "Find Rust developers"); err == nil {
+		t.Error("Expected an error for a state of another query")
+	}
+}
@@ -0,0 +1,210 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+const (
+	batchesURL = apiURL + "/batches"
+
+	// DefaultBatchPollInterval is how often CallBatch checks whether a batch has ended
+	DefaultBatchPollInterval = 30 * time.Second
+
+	// batchDiscount is the share of the list price billed for batched calls
+	batchDiscount = 0.5
+)
+
+var _ llm.BatchCaller = (*Client)(nil)
+
+// CallBatch submits requests as one Message Batches API batch, waits for it
+// to end and returns one result per request, in order. Batches are billed at
+// half price but may take minutes to hours, so use them for work that is not
+// latency sensitive. Streaming options are ignored. Vertex AI and Bedrock have
+// no Message Batches API, so on those platforms requests are sent one by one.
+func (c *Client) CallBatch(ctx context.Context, requests []llm.BatchRequest) ([]llm.BatchResult, error) {
+	if c.Platform != "" && c.Platform != PlatformAnthropic {
+		return llm.BatchCall(ctx, llm.ClientFunc(c.CallAPI), requests, llm.BatchConfig{})
+	}
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	options := make([]llm.Options, len(requests))
+	entries := make([]MessageBatchRequest, len(requests))
+	for i, req := range requests {
+		options[i] = llm.ApplyOptions(req.Options)
+		entries[i] = MessageBatchRequest{
+			CustomID: batchID(i),
+			Params:   c.buildRequest(req.Messages, req.Tools, options[i]),
+		}
+	}
+
+	var batch MessageBatch
+	if err := c.batchCall(ctx, "POST", batchesURL, map[string]interface{}{"requests": entries}, &batch); err != nil {
+		return nil, fmt.Errorf("failed to create message batch: %w", err)
+	}
+
+	interval := c.BatchPollInterval
+	if interval <= 0 {
+		interval = DefaultBatchPollInterval
+	}
+	for batch.ProcessingStatus != "ended" {
+		select {
+		case <-ctx.Done():
+			c.cancelBatch(batch.ID)
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+		if err := c.batchCall(ctx, "GET", batchesURL+"/"+batch.ID, nil, &batch); err != nil {
+			return nil, fmt.Errorf("failed to poll message batch %s: %w", batch.ID, err)
+		}
+	}
+
+	return c.batchResults(ctx, batch, options)
+}
+
+// batchResults downloads the results of an ended batch and converts them in
+// the order of the original requests
+func (c *Client) batchResults(ctx context.Context, batch MessageBatch, options []llm.Options) ([]llm.BatchResult, error) {
+	body, err := c.batchGet(ctx, batch.ResultsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch results of message batch %s: %w", batch.ID, err)
+	}
+	defer body.Close()
+
+	results := make([]llm.BatchResult, len(options))
+	found := make([]bool, len(options))
+	decoder := json.NewDecoder(body)
+	for decoder.More() {
+		var line MessageBatchResult
+		if err := decoder.Decode(&line); err != nil {
+			return nil, fmt.Errorf("failed to parse message batch results: %w", err)
+		}
+		var i int
+		if _, err := fmt.Sscanf(line.CustomID, "request-%d", &i); err != nil || i < 0 || i >= len(results) {
+			continue
+		}
+		found[i] = true
+		results[i] = c.batchResult(line, options[i])
+	}
+	for i := range results {
+		if !found[i] {
+			results[i].Err = fmt.Errorf("message batch %s returned no result for request %d", batch.ID, i)
+		}
+	}
+	return results, nil
+}
+
+// batchResult converts one batch result line, billing successes at the batch discount
+func (c *Client) batchResult(line MessageBatchResult, options llm.Options) llm.BatchResult {
+	switch line.Result.Type {
+	case "succeeded":
+		if line.Result.Message == nil {
+			return llm.BatchResult{Err: fmt.Errorf("batch request %s succeeded without a message", line.CustomID)}
+		}
+		resp, err := convertResponse(line.Result.Message, options)
+		if err != nil {
+			return llm.BatchResult{Err: err}
+		}
+		resp.Usage.EstimatedCostUSD *= batchDiscount
+		c.recordUsage(resp.Model, resp.Usage)
+		return llm.BatchResult{Response: resp}
+	case "errored":
+		apiErr := &llm.APIError{Provider: "anthropic", Message: "batch request failed"}
+		if e := line.Result.Error; e != nil {
+			apiErr.StatusCode = streamErrorStatus[e.Error.Type]
+			apiErr.Status = e.Error.Type
+			apiErr.Message = e.Error.Message
+		}
+		return llm.BatchResult{Err: apiErr}
+	default:
+		return llm.BatchResult{Err: fmt.Errorf("batch request %s was %s", line.CustomID, line.Result.Type)}
+	}
+}
+
+// cancelBatch asks the API to stop processing a batch whose caller gave up
+// waiting. It is best effort: the batch ends on its own within 24 hours.
+func (c *Client) cancelBatch(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	c.batchCall(ctx, "POST", batchesURL+"/"+id+"/cancel", nil, nil)
+}
+
+// batchCall sends a Message Batches API request and decodes the JSON response into out
+func (c *Client) batchCall(ctx context.Context, method, url string, in, out interface{}) error {
+	var body io.Reader
+	var data []byte
+	if in != nil {
+		var err error
+		if data, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := c.authorize(ctx, req, data); err != nil {
+		return err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp, respBody)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// batchGet opens the JSON lines results at url. Results can be large, so the
+// body is returned for streaming decoding rather than read into memory.
+func (c *Client) batchGet(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.authorize(ctx, req, nil); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, newAPIError(resp, body)
+	}
+	return resp.Body, nil
+}
+
+// batchID is the custom_id of the request at index i
+func batchID(i int) string {
+	return fmt.Sprintf("request-%d", i)
+}
@@ -0,0 +1,112 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func newBatchTestClient(handler func(req *http.Request, body []byte) (int, string)) *Client {
+	client := NewClientWithConfig(Config{APIKey: "test-key", BatchPollInterval: time.Millisecond})
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body []byte
+		if req.Body != nil {
+			body, _ = io.ReadAll(req.Body)
+		}
+		status, respBody := handler(req, body)
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(respBody))}, nil
+	})}
+	return client
+}
+
+func TestCallBatch(t *testing.T) {
+	polls := 0
+	client := newBatchTestClient(func(req *http.Request, body []byte) (int, string) {
+		if req.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("Expected API key on %s, got %v", req.URL, req.Header)
+		}
+		switch {
+		case req.Method == "POST" && req.URL.String() == batchesURL:
+			var submitted struct {
+				Requests []MessageBatchRequest `json:"requests"`
+			}
+			json.Unmarshal(body, &submitted)
+			if len(submitted.Requests) != 3 || submitted.Requests[2].CustomID != "request-2" || submitted.Requests[2].Params.ToolChoice == nil {
+				t.Errorf("Expected 3 requests with structured output on the last, got %+v", submitted.Requests)
+			}
+			return http.StatusOK, `{"id": "msgbatch_1", "processing_status": "in_progress"}`
+		case req.URL.String() == batchesURL+"/msgbatch_1":
+			polls++
+			if polls < 2 {
+				return http.StatusOK, `{"id": "msgbatch_1", "processing_status": "in_progress"}`
+			}
+			return http.StatusOK, `{"id": "msgbatch_1", "processing_status": "ended", "results_url": "https://api.anthropic.com/v1/messages/batches/msgbatch_1/results"}`
+		case strings.HasSuffix(req.URL.Path, "/results"):
+			// Results are not guaranteed to be in request order
+			return http.StatusOK, `{"custom_id": "request-2", "result": {"type": "succeeded", "message": {"model": "claude-sonnet-4-20250514", "content": [{"type": "tool_use", "id": "toolu_1", "name": "analysis", "input": {"score": 0.8}}], "usage": {"input_tokens": 1000000, "output_tokens": 0}}}}
+{"custom_id": "request-0", "result": {"type": "succeeded", "message": {"model": "claude-sonnet-4-20250514", "content": [{"type": "text", "text": "ok"}], "usage": {"input_tokens": 10, "output_tokens": 2}}}}
+{"custom_id": "request-1", "result": {"type": "errored", "error": {"type": "error", "error": {"type": "invalid_request_error", "message": "prompt is too long"}}}}
+`
+		}
+		t.Errorf("Unexpected request %s %s", req.Method, req.URL)
+		return http.StatusNotFound, `{}`
+	})
+
+	schema := &llm.ResponseSchema{Name: "analysis", Schema: llm.InputSchema{Type: "object"}}
+	results, err := client.CallBatch(context.Background(), []llm.BatchRequest{
+		{Messages: []llm.Message{llm.UserText("first")}},
+		{Messages: []llm.Message{llm.UserText("second")}},
+		{Messages: []llm.Message{llm.UserText("third")}, Options: []llm.CallOption{llm.WithResponseSchema(schema)}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(results) != 3 || results[0].Err != nil || llm.ResponseText(results[0].Response) != "ok" {
+		t.Fatalf("Expected first result ok, got %+v", results)
+	}
+	var apiErr *llm.APIError
+	if !errors.As(results[1].Err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || llm.IsTransient(results[1].Err) {
+		t.Errorf("Expected a fatal invalid request error, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || llm.ResponseText(results[2].Response) != `{"score":0.8}` {
+		t.Errorf("Expected structured output as text, got %+v", results[2])
+	}
+	// 1M input tokens at $3, billed at half price
+	if cost := results[2].Response.Usage.EstimatedCostUSD; math.Abs(cost-1.5) > 1e-9 {
+		t.Errorf("Expected batch discount applied, got $%.4f", cost)
+	}
+	if polls != 2 {
+		t.Errorf("Expected 2 polls, got %d", polls)
+	}
+}
+
+func TestCallBatchCanceled(t *testing.T) {
+	canceled := false
+	ctx, cancel := context.WithCancel(context.Background())
+	client := newBatchTestClient(func(req *http.Request, body []byte) (int, string) {
+		if strings.HasSuffix(req.URL.Path, "/cancel") {
+			canceled = true
+			return http.StatusOK, `{"id": "msgbatch_1", "processing_status": "canceling"}`
+		}
+		cancel()
+		return http.StatusOK, `{"id": "msgbatch_1", "processing_status": "in_progress"}`
+	})
+	client.BatchPollInterval = time.Hour
+
+	_, err := client.CallBatch(ctx, []llm.BatchRequest{{Messages: []llm.Message{llm.UserText("hi")}}})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if !canceled {
+		t.Error("Expected the batch to be canceled")
+	}
+}
@@ -2,92 +2,346 @@ package anthropic
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 
+	"cloud.google.com/go/auth"
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
 )
 
 const (
-	apiURL    = "https://api.anthropic.com/v1/messages"
-	modelName = "claude-sonnet-4-20250514"
-	maxTokens = 4096
+	apiURL = "https://api.anthropic.com/v1/messages"
+
+	// DefaultModel is the Claude model used by the client
+	DefaultModel = "claude-sonnet-4-20250514"
+	// DefaultMaxTokens is the output ceiling used when none is configured
+	DefaultMaxTokens = 4096
+
+	// responseHeaderTimeout bounds the wait for a response to start. Reading
+	// it is left unbounded, as streamed responses with extended thinking can
+	// take minutes; callers bound whole calls through their context.
+	responseHeaderTimeout = 60 * time.Second
 )
 
+// Config configures an Anthropic client
+type Config struct {
+	// APIKey authenticates to the Anthropic API, or to Bedrock when set to a
+	// Bedrock API key
+	APIKey string
+	// Platform selects where Claude is served from: PlatformAnthropic (the
+	// default), PlatformVertex or PlatformBedrock
+	Platform string
+	// ProjectID is the Google Cloud project for PlatformVertex
+	ProjectID string
+	// Region is the Vertex AI location or AWS region for PlatformVertex and
+	// PlatformBedrock
+	Region string
+	// AWS signs Bedrock requests when no Bedrock API key is set
+	AWS AWSCredentials
+	// Model is the Claude model to call, in the platform's naming. Defaults
+	// to DefaultModel, DefaultVertexModel or DefaultBedrockModel.
+	Model string
+	// StageModels overrides Model for calls labelled with llm.WithStage,
+	// e.g. Haiku for the cheaper stages
+	StageModels map[string]string
+	// MaxTokens caps the output of each call. Defaults to DefaultMaxTokens;
+	// calls can override it with llm.WithMaxOutputTokens.
+	MaxTokens int
+	// ThinkingBudget enables extended thinking with this many reasoning
+	// tokens (at least 1024). Zero disables it.
+	ThinkingBudget int
+	// StageThinkingBudgets overrides ThinkingBudget for calls labelled with
+	// llm.WithStage, e.g. deeper reasoning for ranking only
+	StageThinkingBudgets map[string]int
+	// BatchPollInterval is how often CallBatch checks on a submitted batch.
+	// Defaults to DefaultBatchPollInterval.
+	BatchPollInterval time.Duration
+	// Retry retries 529 overloaded, 429 rate limit and other transient errors
+	// inside the client, honoring Retry-After. The zero value disables it,
+	// leaving retries to llm.WithRetry around the client. Errors that remain
+	// are *llm.APIError values; llm.IsTransient tells retryable from fatal.
+	Retry llm.RetryConfig
+}
+
 // Client handles interactions with the Anthropic API
 type Client struct {
-	APIKey     string
-	HTTPClient *http.Client
+	APIKey      string
+	Model       string
+	StageModels map[string]string
+	MaxTokens   int
+	Retry       llm.RetryConfig
+	HTTPClient  *http.Client
+
+	ThinkingBudget       int
+	StageThinkingBudgets map[string]int
+
+	Platform  string
+	ProjectID string
+	Region    string
+	AWS       AWSCredentials
+
+	BatchPollInterval time.Duration
+	// GoogleCredentials authenticates PlatformVertex calls. Application
+	// Default Credentials are detected on first use when nil.
+	GoogleCredentials auth.TokenProvider
+	credsOnce         sync.Once
+	credsErr          error
+
+	mu         sync.Mutex
+	modelUsage map[string]*ModelUsage
 }
 
-// NewClient creates a new Anthropic Client
+// NewClient creates a new Anthropic Client using DefaultModel
 func NewClient(apiKey string) *Client {
+	return NewClientWithConfig(Config{APIKey: apiKey})
+}
+
+// NewClientWithConfig creates a new Anthropic Client from config
+func NewClientWithConfig(config Config) *Client {
+	model := config.Model
+	if model == "" {
+		model = defaultModelFor(config.Platform)
+	}
+	maxTokens := config.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
 	return &Client{
-		APIKey: apiKey,
-		HTTPClient: &http.Client{
-			Timeout: 60 * time.Second,
-		},
+		APIKey:               config.APIKey,
+		Model:                model,
+		StageModels:          config.StageModels,
+		MaxTokens:            maxTokens,
+		Retry:                config.Retry,
+		HTTPClient:           &http.Client{Transport: newTransport()},
+		ThinkingBudget:       config.ThinkingBudget,
+		StageThinkingBudgets: config.StageThinkingBudgets,
+		Platform:             config.Platform,
+		ProjectID:            config.ProjectID,
+		Region:               config.Region,
+		AWS:                  config.AWS,
+		BatchPollInterval:    config.BatchPollInterval,
+	}
+}
+
+// newTransport returns the default transport with responseHeaderTimeout
+func newTransport() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = responseHeaderTimeout
+	return transport
+}
+
+// modelFor returns the model to use for a call with options
+func (c *Client) modelFor(options llm.Options) string {
+	if model, ok := c.StageModels[options.Stage]; ok && model != "" {
+		return model
 	}
+	if c.Model != "" {
+		return c.Model
+	}
+	return defaultModelFor(c.Platform)
+}
+
+// maxTokensFor returns the output ceiling for a call with options
+func (c *Client) maxTokensFor(options llm.Options) int {
+	if gen := options.Generation; gen != nil && gen.MaxOutputTokens > 0 {
+		return int(gen.MaxOutputTokens)
+	}
+	if c.MaxTokens > 0 {
+		return c.MaxTokens
+	}
+	return DefaultMaxTokens
+}
+
+// thinkingBudgetFor returns the extended thinking budget for a call with options
+func (c *Client) thinkingBudgetFor(options llm.Options) int {
+	if budget, ok := c.StageThinkingBudgets[options.Stage]; ok {
+		return budget
+	}
+	return c.ThinkingBudget
 }
 
 // CallAPI calls the Anthropic API with messages and tools
-func (c *Client) CallAPI(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
-	// Convert llm.Message to anthropic.Message
-	var anthropicMessages []Message
-	for _, msg := range messages {
-		anthropicMessages = append(anthropicMessages, Message{
-			Role:    msg.Role,
-			Content: msg.Content,
+func (c *Client) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	if c.Retry.MaxAttempts > 1 {
+		return llm.WithRetry(llm.ClientFunc(c.call), c.Retry).CallAPI(ctx, messages, tools, opts...)
+	}
+	return c.call(ctx, messages, tools, opts...)
+}
+
+// call makes a single Messages API request
+func (c *Client) call(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	options := llm.ApplyOptions(opts)
+	requestBody := c.buildRequest(messages, tools, options)
+
+	structuredTool := ""
+	if options.ResponseSchema != nil {
+		structuredTool = options.ResponseSchema.Name
+	}
+	if options.Stream != nil {
+		requestBody.Stream = true
+	}
+
+	apiResponse, err := c.send(ctx, requestBody, structuredTool, options.Stream)
+	if err != nil {
+		return nil, err
+	}
+	if apiResponse.Model == "" {
+		apiResponse.Model = requestBody.Model
+	}
+
+	resp, err := convertResponse(apiResponse, options)
+	if err != nil {
+		return nil, err
+	}
+	c.recordUsage(resp.Model, resp.Usage)
+	return resp, nil
+}
+
+// buildRequest converts a call into a Messages API request
+func (c *Client) buildRequest(messages []llm.Message, tools []llm.Tool, options llm.Options) Request {
+	// Structured output is implemented as a forced call to a tool whose input schema is the response schema
+	if options.ResponseSchema != nil {
+		tools = append(tools, llm.Tool{
+			Name:        options.ResponseSchema.Name,
+			Description: structuredToolDescription(options.ResponseSchema),
+			InputSchema: options.ResponseSchema.Schema,
 		})
 	}
 
+	// The Messages API takes the system prompt as a top-level parameter, not a message
+	system, anthropicMessages := convertMessages(messages)
+
 	// Convert llm.Tool to anthropic.Tool
 	var anthropicTools []Tool
 	for _, tool := range tools {
-		// Convert llm.InputSchema to anthropic.InputSchema
-		props := make(map[string]Property)
-		for k, v := range tool.InputSchema.Properties {
-			props[k] = Property{
-				Type:        v.Type,
-				Description: v.Description,
-				Default:     v.Default,
-			}
-		}
-
 		anthropicTools = append(anthropicTools, Tool{
 			Name:        tool.Name,
 			Description: tool.Description,
 			InputSchema: InputSchema{
 				Type:       tool.InputSchema.Type,
-				Properties: props,
+				Properties: convertProperties(tool.InputSchema.Properties),
 				Required:   tool.InputSchema.Required,
 			},
 		})
 	}
 
 	requestBody := Request{
-		Model:     modelName,
-		MaxTokens: maxTokens,
+		Model:     c.modelFor(options),
+		MaxTokens: c.maxTokensFor(options),
+		System:    system,
 		Messages:  anthropicMessages,
 		Tools:     anthropicTools,
 	}
+	if gen := options.Generation; gen != nil {
+		requestBody.Temperature = gen.Temperature
+		requestBody.TopP = gen.TopP
+		requestBody.TopK = gen.TopK
+		requestBody.StopSequences = gen.StopSequences
+	}
+	if choice := options.ToolChoice; choice != nil && len(anthropicTools) > 0 {
+		requestBody.ToolChoice = &ToolChoice{Type: choice.Type, Name: choice.Name}
+	}
+	// The structured-output tool must be called whatever the caller chose
+	if options.ResponseSchema != nil {
+		requestBody.ToolChoice = &ToolChoice{Type: "tool", Name: options.ResponseSchema.Name}
+	}
+	if budget := c.thinkingBudgetFor(options); budget > 0 {
+		applyThinking(&requestBody, budget)
+	}
+	return requestBody
+}
+
+// convertResponse converts a Messages API response to an llm.Response
+func convertResponse(apiResponse *Response, options llm.Options) (*llm.Response, error) {
+	usage := convertUsage(apiResponse)
+
+	// Convert anthropic.Response to llm.Response
+	var content []llm.ContentBlock
+	stopReason := apiResponse.StopReason
+	for _, block := range apiResponse.Content {
+		switch block.Type {
+		case "thinking":
+			// The signature must be sent back with the block on the next turn
+			content = append(content, llm.ContentBlock{Type: "thinking", Text: block.Thinking, ThoughtSignature: block.Signature})
+			continue
+		case "redacted_thinking":
+			content = append(content, llm.ContentBlock{Type: "redacted_thinking", ThoughtSignature: block.Data})
+			continue
+		}
+		// The forced structured-output tool call becomes the JSON text of the response
+		if options.ResponseSchema != nil && block.Type == "tool_use" && block.Name == options.ResponseSchema.Name {
+			data, err := json.Marshal(block.Input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal structured output: %w", err)
+			}
+			content = append(content, llm.ContentBlock{Type: "text", Text: string(data)})
+			stopReason = "end_turn"
+			continue
+		}
+		content = append(content, llm.ContentBlock{
+			Type:      block.Type,
+			Text:      block.Text,
+			ID:        block.ID,
+			Name:      block.Name,
+			Input:     block.Input,
+			ToolUseID: block.ToolUseID,
+			Content:   block.Content,
+		})
+	}
+
+	return &llm.Response{
+		ID:         apiResponse.ID,
+		Type:       apiResponse.Type,
+		Role:       apiResponse.Role,
+		Content:    content,
+		Model:      apiResponse.Model,
+		StopReason: stopReason,
+		Usage:      usage,
+	}, nil
+}
+
+// applyThinking enables extended thinking on request. Thinking is incompatible
+// with sampling overrides and forced tool calls, so those are relaxed: a forced
+// structured-output tool becomes optional (the answer may then arrive as text,
+// which llm.DecodeStructured accepts), and max_tokens grows by the budget so
+// the answer keeps its own ceiling.
+func applyThinking(request *Request, budget int) {
+	request.Thinking = &Thinking{Type: "enabled", BudgetTokens: budget}
+	request.MaxTokens += budget
+	request.Temperature = nil
+	request.TopP = nil
+	request.TopK = nil
+	if request.ToolChoice != nil && (request.ToolChoice.Type == "tool" || request.ToolChoice.Type == "any") {
+		request.ToolChoice = &ToolChoice{Type: "auto"}
+	}
+}
 
+// send posts requestBody to the Messages API of the platform and returns the
+// response, assembling it from server-sent events when streaming
+func (c *Client) send(ctx context.Context, requestBody Request, structuredTool string, handler llm.StreamHandler) (*Response, error) {
+	endpoint, err := c.endpoint(&requestBody)
+	if err != nil {
+		return nil, err
+	}
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.APIKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	if err := c.authorize(ctx, req, jsonData); err != nil {
+		return nil, err
+	}
 
 	client := c.HTTPClient
 	resp, err := client.Do(req)
@@ -96,45 +350,80 @@ func (c *Client) CallAPI(messages []llm.Message, tools []llm.Tool) (*llm.Respons
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusOK && requestBody.Stream {
+		return readStream(resp.Body, structuredTool, handler)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var apiResponse Response
 	if err := json.Unmarshal(body, &apiResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	if handler != nil && !requestBody.Stream {
+		replay(&apiResponse, structuredTool, handler)
+	}
+	return &apiResponse, nil
+}
 
-	// Convert anthropic.Response to llm.Response
-	var content []llm.ContentBlock
-	for _, block := range apiResponse.Content {
-		content = append(content, llm.ContentBlock{
-			Type:             block.Type,
-			Text:             block.Text,
-			ID:               block.ID,
-			Name:             block.Name,
-			Input:            block.Input,
-			ToolUseID:        block.ToolUseID,
-			Content:          block.Content,
-			ThoughtSignature: block.ThoughtSignature,
-		})
+// newAPIError converts a non-200 Anthropic response into an llm.APIError
+func newAPIError(resp *http.Response, body []byte) error {
+	apiErr := &llm.APIError{
+		Provider:   "anthropic",
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
+		RetryAfter: llm.ParseRetryAfter(resp.Header.Get("retry-after")),
 	}
 
-	return &llm.Response{
-		ID:         apiResponse.ID,
-		Type:       apiResponse.Type,
-		Role:       apiResponse.Role,
-		Content:    content,
-		Model:      apiResponse.Model,
-		StopReason: apiResponse.StopReason,
-		Usage: llm.Usage{
-			InputTokens:  apiResponse.Usage.InputTokens,
-			OutputTokens: apiResponse.Usage.OutputTokens,
-		},
-	}, nil
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Type != "" {
+		apiErr.Status = errResp.Error.Type
+		apiErr.Message = errResp.Error.Message
+	}
+
+	return apiErr
+}
+
+// structuredToolDescription describes the synthetic tool used for structured output
+func structuredToolDescription(schema *llm.ResponseSchema) string {
+	desc := "Return the final answer as structured data by calling this tool."
+	if schema.Description != "" {
+		desc = schema.Description + " " + desc
+	}
+	return desc
+}
+
+// convertProperties recursively converts llm properties to Anthropic's JSON schema properties
+func convertProperties(props map[string]llm.Property) map[string]Property {
+	if props == nil {
+		return nil
+	}
+	out := make(map[string]Property, len(props))
+	for name, prop := range props {
+		out[name] = convertProperty(prop)
+	}
+	return out
+}
+
+func convertProperty(prop llm.Property) Property {
+	out := Property{
+		Type:        prop.Type,
+		Description: prop.Description,
+		Default:     prop.Default,
+		Enum:        prop.Enum,
+		Properties:  convertProperties(prop.Properties),
+		Required:    prop.Required,
+	}
+	if prop.Items != nil {
+		items := convertProperty(*prop.Items)
+		out.Items = &items
+	}
+	return out
 }
@@ -0,0 +1,291 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// roundTripFunc lets tests intercept requests sent to the Anthropic API
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestClient(handler func(req *http.Request, body Request) (int, string)) *Client {
+	client := NewClient("test-key")
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		var body Request
+		data, _ := io.ReadAll(req.Body)
+		json.Unmarshal(data, &body)
+
+		status, respBody := handler(req, body)
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Retry-After": []string{"3"}},
+			Body:       io.NopCloser(strings.NewReader(respBody)),
+		}, nil
+	})}
+	return client
+}
+
+func TestCallAPIStructuredOutput(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		if body.ToolChoice == nil || body.ToolChoice.Type != "tool" || body.ToolChoice.Name != "requirements" {
+			t.Errorf("Expected forced tool choice for 'requirements', got %+v", body.ToolChoice)
+		}
+		if len(body.Tools) != 1 || body.Tools[0].Name != "requirements" {
+			t.Errorf("Expected synthetic structured-output tool, got %+v", body.Tools)
+		}
+		return http.StatusOK, `{
+			"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-sonnet-4-20250514",
+			"stop_reason": "tool_use",
+			"content": [{"type": "tool_use", "id": "toolu_1", "name": "requirements", "input": {"level": "senior"}}],
+			"usage": {"input_tokens": 10, "output_tokens": 5}
+		}`
+	})
+
+	schema := &llm.ResponseSchema{
+		Name:   "requirements",
+		Schema: llm.InputSchema{Type: "object", Properties: map[string]llm.Property{"level": {Type: "string"}}},
+	}
+
+	var out struct {
+		Level string `json:"level"`
+	}
+	resp, err := llm.CallStructured(context.Background(), client, []llm.Message{{Role: "user", Content: "hi"}}, schema, &out)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out.Level != "senior" {
+		t.Errorf("Expected level 'senior', got %q", out.Level)
+	}
+	if resp.StopReason != "end_turn" {
+		t.Errorf("Expected stop reason 'end_turn', got %q", resp.StopReason)
+	}
+}
+
+func TestCallAPIError(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		return 529, `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`
+	})
+
+	_, err := client.CallAPI(context.Background(), []llm.Message{{Role: "user", Content: "hi"}}, nil)
+	if !llm.IsTransient(err) {
+		t.Fatalf("Expected transient error, got %v", err)
+	}
+
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *llm.APIError, got %T", err)
+	}
+	if apiErr.Status != "overloaded_error" || apiErr.Message != "Overloaded" {
+		t.Errorf("Expected parsed error type and message, got %+v", apiErr)
+	}
+	if apiErr.RetryAfter.Seconds() != 3 {
+		t.Errorf("Expected Retry-After of 3s, got %v", apiErr.RetryAfter)
+	}
+}
+
+func TestConvertProperty(t *testing.T) {
+	prop := llm.Property{
+		Type: "array",
+		Items: &llm.Property{
+			Type: "object",
+			Properties: map[string]llm.Property{
+				"name":  {Type: "string"},
+				"level": {Type: "string", Enum: []string{"mid", "senior"}},
+			},
+			Required: []string{"name"},
+		},
+	}
+
+	got := convertProperty(prop)
+	if got.Type != "array" || got.Items == nil {
+		t.Fatalf("Expected array with items, got %+v", got)
+	}
+	if got.Items.Type != "object" || len(got.Items.Required) != 1 {
+		t.Errorf("Expected object items with required fields, got %+v", got.Items)
+	}
+	if level := got.Items.Properties["level"]; len(level.Enum) != 2 {
+		t.Errorf("Expected nested enum to be preserved, got %+v", level)
+	}
+}
+
+func TestConvertPropertyDefaults(t *testing.T) {
+	cases := []struct {
+		name     string
+		def      interface{}
+		expected string
+	}{
+		{"Integer", 5, `"default":5`},
+		{"String", "go", `"default":"go"`},
+		{"FalseBoolean", false, `"default":false`},
+		{"ZeroInteger", 0, `"default":0`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, _ := json.Marshal(convertProperty(llm.Property{Type: "string", Default: tc.def}))
+			if !strings.Contains(string(data), tc.expected) {
+				t.Errorf("Expected %s in %s", tc.expected, data)
+			}
+		})
+	}
+
+	t.Run("NoDefault", func(t *testing.T) {
+		data, _ := json.Marshal(convertProperty(llm.Property{Type: "string"}))
+		if strings.Contains(string(data), "default") {
+			t.Errorf("Expected no default field, got %s", data)
+		}
+	})
+}
+
+func TestCallAPIImageContent(t *testing.T) {
+	var sent string
+	client := NewClient("test-key")
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(req.Body)
+		sent = string(data)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(`{"content": [{"type": "text", "text": "ok"}]}`)),
+		}, nil
+	})}
+
+	messages := []llm.Message{{Role: "user", Content: []llm.ContentBlock{
+		llm.ImageBlock("image/png", []byte("png-bytes")),
+		{Type: "text", Text: "Describe this screenshot"},
+	}}}
+	if _, err := client.CallAPI(context.Background(), messages, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := `"source":{"type":"base64","media_type":"image/png","data":"cG5nLWJ5dGVz"}`
+	if !strings.Contains(sent, expected) {
+		t.Errorf("Expected image source %s in request, got %s", expected, sent)
+	}
+}
+
+func TestCallAPISystemPrompt(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		if body.System != "You are a sourcing assistant." {
+			t.Errorf("Expected top-level system prompt, got %q", body.System)
+		}
+		for _, msg := range body.Messages {
+			if msg.Role == "system" {
+				t.Errorf("Expected no inline system messages, got %+v", body.Messages)
+			}
+		}
+		return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}]}`
+	})
+
+	messages := []llm.Message{
+		{Role: "system", Content: "You are a sourcing assistant."},
+		{Role: "user", Content: "Find Go developers"},
+	}
+	if _, err := client.CallAPI(context.Background(), messages, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCallAPIGenerationOptions(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		if body.Temperature == nil || *body.Temperature != 0.5 {
+			t.Errorf("Expected temperature 0.5, got %v", body.Temperature)
+		}
+		if body.MaxTokens != 256 || len(body.StopSequences) != 1 {
+			t.Errorf("Expected max_tokens 256 and one stop sequence, got %d and %v", body.MaxTokens, body.StopSequences)
+		}
+		return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}]}`
+	})
+
+	_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil,
+		llm.WithTemperature(0.5),
+		llm.WithGeneration(llm.GenerationConfig{MaxOutputTokens: 256, StopSequences: []string{"\n\n"}}),
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCallAPIModelConfig(t *testing.T) {
+	var got []Request
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		got = append(got, body)
+		return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}]}`
+	})
+	client.Model = "claude-sonnet-4-5"
+	client.StageModels = map[string]string{"requirements": "claude-haiku-4-5"}
+	client.MaxTokens = 8192
+
+	calls := [][]llm.CallOption{
+		{llm.WithStage("requirements")},
+		{llm.WithStage("ranking"), llm.WithMaxOutputTokens(16000)},
+		nil,
+	}
+	for _, opts := range calls {
+		if _, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil, opts...); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	want := []struct {
+		model     string
+		maxTokens int
+	}{
+		{"claude-haiku-4-5", 8192},
+		{"claude-sonnet-4-5", 16000},
+		{"claude-sonnet-4-5", 8192},
+	}
+	for i, w := range want {
+		if got[i].Model != w.model || got[i].MaxTokens != w.maxTokens {
+			t.Errorf("Call %d: expected %s with %d tokens, got %s with %d", i, w.model, w.maxTokens, got[i].Model, got[i].MaxTokens)
+		}
+	}
+}
+
+func TestNewClientDefaults(t *testing.T) {
+	client := NewClient("key")
+	if client.Model != DefaultModel || client.MaxTokens != DefaultMaxTokens {
+		t.Errorf("Expected defaults %s and %d, got %s and %d", DefaultModel, DefaultMaxTokens, client.Model, client.MaxTokens)
+	}
+	// A client-wide timeout would cut long streams short
+	if client.HTTPClient.Timeout != 0 {
+		t.Errorf("Expected no client-wide timeout, got %v", client.HTTPClient.Timeout)
+	}
+}
+
+func TestCallAPIToolChoice(t *testing.T) {
+	tools := []llm.Tool{{Name: "search_github_developers", InputSchema: llm.InputSchema{Type: "object"}}}
+
+	tests := []struct {
+		name string
+		opts []llm.CallOption
+		want *ToolChoice
+	}{
+		{"unset", nil, nil},
+		{"forced", []llm.CallOption{llm.WithForcedTool("search_github_developers")}, &ToolChoice{Type: "tool", Name: "search_github_developers"}},
+		{"none", []llm.CallOption{llm.WithoutTools()}, &ToolChoice{Type: "none"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(func(req *http.Request, body Request) (int, string) {
+				if (body.ToolChoice == nil) != (tt.want == nil) || (tt.want != nil && *body.ToolChoice != *tt.want) {
+					t.Errorf("Expected tool_choice %+v, got %+v", tt.want, body.ToolChoice)
+				}
+				return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}]}`
+			})
+			if _, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, tools, tt.opts...); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		})
+	}
+}
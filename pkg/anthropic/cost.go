@@ -0,0 +1,75 @@
+package anthropic
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// ModelUsage totals the calls, tokens and estimated cost a client spent on one model
+type ModelUsage struct {
+	Model string
+	Calls int
+	Usage llm.Usage
+}
+
+// convertUsage converts the usage of resp, counting cache reads and writes as
+// input so token totals match what was sent, and prices it with llm.Pricing
+func convertUsage(resp *Response) llm.Usage {
+	usage := llm.Usage{
+		InputTokens:       resp.Usage.InputTokens + resp.Usage.CacheCreationInputTokens + resp.Usage.CacheReadInputTokens,
+		OutputTokens:      resp.Usage.OutputTokens,
+		CachedInputTokens: resp.Usage.CacheReadInputTokens,
+	}
+	usage.EstimatedCostUSD = llm.EstimateCost(pricingModel(resp.Model), usage)
+	return usage
+}
+
+// pricingModel maps platform model names to the Anthropic names llm.Pricing
+// is keyed by, e.g. Bedrock's "us.anthropic.claude-sonnet-4-20250514-v1:0".
+// Vertex AI names such as "claude-sonnet-4@20250514" already share the prefix.
+func pricingModel(model string) string {
+	if _, name, ok := strings.Cut(model, "anthropic."); ok {
+		return name
+	}
+	return model
+}
+
+func (c *Client) recordUsage(model string, usage llm.Usage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.modelUsage == nil {
+		c.modelUsage = make(map[string]*ModelUsage)
+	}
+	total, ok := c.modelUsage[model]
+	if !ok {
+		total = &ModelUsage{Model: model}
+		c.modelUsage[model] = total
+	}
+	total.Calls++
+	total.Usage = total.Usage.Add(usage)
+}
+
+// ModelUsage returns a snapshot of the usage of each model called, sorted by model
+func (c *Client) ModelUsage() []ModelUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]ModelUsage, 0, len(c.modelUsage))
+	for _, usage := range c.modelUsage {
+		out = append(out, *usage)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Model < out[j].Model })
+	return out
+}
+
+// TotalCost returns the estimated cost in USD of every call made by the client
+func (c *Client) TotalCost() float64 {
+	var total float64
+	for _, usage := range c.ModelUsage() {
+		total += usage.Usage.EstimatedCostUSD
+	}
+	return total
+}
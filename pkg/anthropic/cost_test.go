@@ -0,0 +1,64 @@
+package anthropic
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCallAPIUsage(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		if body.Model == "claude-haiku-4-5" {
+			return http.StatusOK, `{"model": "claude-haiku-4-5", "content": [{"type": "text", "text": "ok"}],
+				"usage": {"input_tokens": 1000000, "output_tokens": 0}}`
+		}
+		return http.StatusOK, `{"model": "claude-sonnet-4-20250514", "content": [{"type": "text", "text": "ok"}],
+			"usage": {"input_tokens": 100000, "output_tokens": 100000, "cache_creation_input_tokens": 100000, "cache_read_input_tokens": 800000}}`
+	})
+	client.StageModels = map[string]string{"requirements": "claude-haiku-4-5"}
+
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("rank")}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.Usage.InputTokens != 1_000_000 || resp.Usage.CachedInputTokens != 800_000 {
+		t.Errorf("Expected cache reads and writes counted as input, got %+v", resp.Usage)
+	}
+	// 200k uncached input at $3, 800k cached at $0.30 and 100k output at $15
+	if want := 0.6 + 0.24 + 1.5; math.Abs(resp.Usage.EstimatedCostUSD-want) > 1e-9 {
+		t.Errorf("Expected cost %.4f, got %.4f", want, resp.Usage.EstimatedCostUSD)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil, llm.WithStage("requirements")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	usage := client.ModelUsage()
+	if len(usage) != 2 || usage[0].Model != "claude-haiku-4-5" || usage[0].Calls != 2 || usage[1].Calls != 1 {
+		t.Fatalf("Expected usage per model, got %+v", usage)
+	}
+	if usage[0].Usage.InputTokens != 2_000_000 || math.Abs(usage[0].Usage.EstimatedCostUSD-2.0) > 1e-9 {
+		t.Errorf("Expected haiku totals of 2M tokens and $2, got %+v", usage[0].Usage)
+	}
+	if want := 2.0 + 2.34; math.Abs(client.TotalCost()-want) > 1e-9 {
+		t.Errorf("Expected total cost %.4f, got %.4f", want, client.TotalCost())
+	}
+}
+
+func TestPricingModel(t *testing.T) {
+	tests := map[string]string{
+		"us.anthropic.claude-sonnet-4-20250514-v1:0": "claude-sonnet-4-20250514-v1:0",
+		"anthropic.claude-3-5-haiku-20241022-v1:0":   "claude-3-5-haiku-20241022-v1:0",
+		"claude-sonnet-4@20250514":                   "claude-sonnet-4@20250514",
+	}
+	for model, want := range tests {
+		if got := pricingModel(model); got != want {
+			t.Errorf("Expected %s for %s, got %s", want, model, got)
+		}
+	}
+}
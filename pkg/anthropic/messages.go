@@ -0,0 +1,89 @@
+package anthropic
+
+import "github.com/luillyfe/sourcing-agent/pkg/llm"
+
+// convertMessages maps the conversation to the Messages API: system messages
+// become the top-level system parameter, consecutive messages with the same
+// role are merged since the API requires alternating turns, and fields other
+// providers attach to blocks are dropped because the API rejects unknown fields.
+func convertMessages(messages []llm.Message) (string, []Message) {
+	system, messages := llm.SplitSystem(messages)
+
+	var out []Message
+	for _, msg := range messages {
+		blocks := convertBlocks(msg.Content)
+		if len(blocks) == 0 {
+			continue
+		}
+		if n := len(out); n > 0 && out[n-1].Role == msg.Role {
+			prev := out[n-1].Content.([]ContentBlock)
+			out[n-1].Content = append(prev, blocks...)
+			continue
+		}
+		out = append(out, Message{Role: msg.Role, Content: blocks})
+	}
+
+	// Plain single-text turns keep the compact string form
+	for i, msg := range out {
+		blocks := msg.Content.([]ContentBlock)
+		if len(blocks) == 1 && blocks[0].Type == "text" {
+			out[i].Content = blocks[0].Text
+		}
+	}
+	return system, out
+}
+
+// convertBlocks returns the Messages API blocks for string or block content
+func convertBlocks(content interface{}) []ContentBlock {
+	switch v := content.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []ContentBlock{{Type: "text", Text: v}}
+	case []llm.ContentBlock:
+		var blocks []ContentBlock
+		for _, block := range v {
+			switch block.Type {
+			case "thinking":
+				// Claude's own thinking must be returned with its signature;
+				// thought summaries from other providers carry none and would
+				// be rejected
+				if block.ThoughtSignature != "" {
+					blocks = append(blocks, ContentBlock{Type: "thinking", Thinking: block.Text, Signature: block.ThoughtSignature})
+				}
+				continue
+			case "redacted_thinking":
+				blocks = append(blocks, ContentBlock{Type: "redacted_thinking", Data: block.ThoughtSignature})
+				continue
+			case "text":
+				if block.Text == "" {
+					continue
+				}
+			}
+			out := ContentBlock{
+				Type:      block.Type,
+				Text:      block.Text,
+				ID:        block.ID,
+				Name:      block.Name,
+				Input:     block.Input,
+				ToolUseID: block.ToolUseID,
+				Content:   block.Content,
+			}
+			if block.Type == "tool_use" && out.Input == nil {
+				out.Input = map[string]interface{}{}
+			}
+			if block.Source != nil {
+				out.Source = &ImageSource{
+					Type:      block.Source.Type,
+					MediaType: block.Source.MediaType,
+					Data:      block.Source.Data,
+					URL:       block.Source.URL,
+				}
+			}
+			blocks = append(blocks, out)
+		}
+		return blocks
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestConvertMessages(t *testing.T) {
+	messages := []llm.Message{
+		llm.SystemText("You are a sourcing assistant."),
+		llm.UserText("Find Go developers"),
+		llm.SystemText("Answer in JSON."),
+		llm.UserText("in Lima"),
+		llm.AssistantBlocks(
+			llm.ContentBlock{Type: "thinking", Text: "Searching by language first"},
+			llm.ContentBlock{Type: "tool_use", ID: "call_search", Name: "search_github_developers", ThoughtSignature: "c2ln"},
+		),
+		llm.UserBlocks(llm.ToolResult("call_search", "[]")),
+	}
+
+	system, out := convertMessages(messages)
+	if system != "You are a sourcing assistant.\n\nAnswer in JSON." {
+		t.Errorf("Expected both system messages in the system parameter, got %q", system)
+	}
+	if len(out) != 3 || out[0].Role != "user" || out[1].Role != "assistant" || out[2].Role != "user" {
+		t.Fatalf("Expected alternating user/assistant/user turns, got %+v", out)
+	}
+
+	merged, ok := out[0].Content.([]ContentBlock)
+	if !ok || len(merged) != 2 || merged[1].Text != "in Lima" {
+		t.Errorf("Expected consecutive user messages merged, got %+v", out[0].Content)
+	}
+
+	data, _ := json.Marshal(out[1])
+	if strings.Contains(string(data), "thought_signature") || strings.Contains(string(data), "thinking") {
+		t.Errorf("Expected provider-specific fields and thoughts dropped, got %s", data)
+	}
+	if !strings.Contains(string(data), `"input":{}`) {
+		t.Errorf("Expected tool_use input to default to an empty object, got %s", data)
+	}
+}
+
+func TestConvertMessagesKeepsStringContent(t *testing.T) {
+	_, out := convertMessages([]llm.Message{llm.UserText("hi")})
+	if len(out) != 1 || out[0].Content != "hi" {
+		t.Errorf("Expected plain string content, got %+v", out)
+	}
+}
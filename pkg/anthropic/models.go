@@ -2,11 +2,31 @@ package anthropic
 
 // Request represents the request payload for Anthropic API
 type Request struct {
-	Model            string    `json:"model"`
-	MaxTokens        int       `json:"max_tokens"`
-	Messages         []Message `json:"messages"`
-	Tools            []Tool    `json:"tools,omitempty"`
-	AnthropicVersion string    `json:"anthropic_version,omitempty"`
+	Model            string      `json:"model,omitempty"` // Empty on Vertex AI and Bedrock, which take it in the URL
+	MaxTokens        int         `json:"max_tokens"`
+	System           string      `json:"system,omitempty"`
+	Temperature      *float32    `json:"temperature,omitempty"`
+	TopP             *float32    `json:"top_p,omitempty"`
+	TopK             *int32      `json:"top_k,omitempty"`
+	StopSequences    []string    `json:"stop_sequences,omitempty"`
+	Messages         []Message   `json:"messages"`
+	Tools            []Tool      `json:"tools,omitempty"`
+	ToolChoice       *ToolChoice `json:"tool_choice,omitempty"`
+	Stream           bool        `json:"stream,omitempty"`
+	Thinking         *Thinking   `json:"thinking,omitempty"`
+	AnthropicVersion string      `json:"anthropic_version,omitempty"`
+}
+
+// ToolChoice controls how Claude uses the provided tools
+type ToolChoice struct {
+	Type string `json:"type"`           // "auto", "any", "tool" or "none"
+	Name string `json:"name,omitempty"` // Required when Type is "tool"
+}
+
+// Thinking enables extended thinking with a reasoning budget
+type Thinking struct {
+	Type         string `json:"type"` // "enabled"
+	BudgetTokens int    `json:"budget_tokens"`
 }
 
 // Message represents a message in the conversation
@@ -17,14 +37,25 @@ type Message struct {
 
 // ContentBlock represents a content block (text or tool_use or tool_result)
 type ContentBlock struct {
-	Type             string      `json:"type"`
-	Text             string      `json:"text,omitempty"`
-	ID               string      `json:"id,omitempty"`
-	Name             string      `json:"name,omitempty"`
-	Input            interface{} `json:"input,omitempty"`
-	ToolUseID        string      `json:"tool_use_id,omitempty"`
-	Content          string      `json:"content,omitempty"`
-	ThoughtSignature string      `json:"thought_signature,omitempty"`
+	Type      string       `json:"type"`
+	Text      string       `json:"text,omitempty"`
+	ID        string       `json:"id,omitempty"`
+	Name      string       `json:"name,omitempty"`
+	Input     interface{}  `json:"input,omitempty"`
+	ToolUseID string       `json:"tool_use_id,omitempty"`
+	Content   string       `json:"content,omitempty"`
+	Source    *ImageSource `json:"source,omitempty"`
+	Thinking  string       `json:"thinking,omitempty"`  // Set on "thinking" blocks
+	Signature string       `json:"signature,omitempty"` // Set on "thinking" blocks
+	Data      string       `json:"data,omitempty"`      // Set on "redacted_thinking" blocks
+}
+
+// ImageSource is the source of an image content block
+type ImageSource struct {
+	Type      string `json:"type"` // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
 }
 
 // Tool represents a tool definition for Claude
@@ -43,9 +74,13 @@ type InputSchema struct {
 
 // Property defines a property in the tool input schema
 type Property struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
-	Default     int    `json:"default,omitempty"`
+	Type        string              `json:"type"`
+	Description string              `json:"description,omitempty"`
+	Default     interface{}         `json:"default,omitempty"`
+	Enum        []string            `json:"enum,omitempty"`
+	Items       *Property           `json:"items,omitempty"`
+	Properties  map[string]Property `json:"properties,omitempty"`
+	Required    []string            `json:"required,omitempty"`
 }
 
 // Response represents the response from Anthropic API
@@ -57,7 +92,75 @@ type Response struct {
 	Model      string         `json:"model"`
 	StopReason string         `json:"stop_reason"`
 	Usage      struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
+		InputTokens              int `json:"input_tokens"` // Excludes cache reads and writes
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
 	} `json:"usage"`
 }
+
+// MessageBatchRequest is one Messages API request within a message batch
+type MessageBatchRequest struct {
+	CustomID string  `json:"custom_id"`
+	Params   Request `json:"params"`
+}
+
+// MessageBatch is the status of a message batch
+type MessageBatch struct {
+	ID               string `json:"id"`
+	Type             string `json:"type"`
+	ProcessingStatus string `json:"processing_status"` // in_progress, canceling or ended
+	RequestCounts    struct {
+		Processing int `json:"processing"`
+		Succeeded  int `json:"succeeded"`
+		Errored    int `json:"errored"`
+		Canceled   int `json:"canceled"`
+		Expired    int `json:"expired"`
+	} `json:"request_counts"`
+	ResultsURL string `json:"results_url,omitempty"` // Set once the batch has ended
+}
+
+// MessageBatchResult is one line of the results of an ended message batch
+type MessageBatchResult struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string         `json:"type"` // succeeded, errored, canceled or expired
+		Message *Response      `json:"message,omitempty"`
+		Error   *ErrorResponse `json:"error,omitempty"`
+	} `json:"result"`
+}
+
+// ErrorResponse represents an error payload returned by Anthropic API
+type ErrorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// StreamEvent is a server-sent event from the streaming Messages API
+type StreamEvent struct {
+	Type         string        `json:"type"` // message_start, content_block_start, content_block_delta, content_block_stop, message_delta, message_stop, ping or error
+	Message      *Response     `json:"message,omitempty"`
+	Index        int           `json:"index"`
+	ContentBlock *ContentBlock `json:"content_block,omitempty"`
+	Delta        *StreamDelta  `json:"delta,omitempty"`
+	Usage        *struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// StreamDelta is the incremental content of a content_block_delta or message_delta event
+type StreamDelta struct {
+	Type        string `json:"type,omitempty"` // text_delta, input_json_delta, thinking_delta or signature_delta
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	Signature   string `json:"signature,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
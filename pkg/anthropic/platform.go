@@ -0,0 +1,145 @@
+package anthropic
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/auth/credentials"
+)
+
+// Platforms serving Claude
+const (
+	PlatformAnthropic = "anthropic" // The Anthropic API, authenticated with an API key
+	PlatformVertex    = "vertex"    // Claude on Google Cloud Vertex AI, authenticated with Google credentials
+	PlatformBedrock   = "bedrock"   // Claude on Amazon Bedrock, authenticated with AWS credentials or a Bedrock API key
+)
+
+const (
+	// DefaultVertexModel is the Vertex AI name of DefaultModel
+	DefaultVertexModel = "claude-sonnet-4@20250514"
+	// DefaultBedrockModel is the Bedrock model ID of DefaultModel
+	DefaultBedrockModel = "anthropic.claude-sonnet-4-20250514-v1:0"
+
+	vertexVersion  = "vertex-2023-10-16"
+	bedrockVersion = "bedrock-2023-05-31"
+
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+)
+
+// AWSCredentials are the static AWS credentials used to sign Bedrock requests
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Set for temporary credentials
+}
+
+// defaultModelFor returns the name of DefaultModel on platform
+func defaultModelFor(platform string) string {
+	switch platform {
+	case PlatformVertex:
+		return DefaultVertexModel
+	case PlatformBedrock:
+		return DefaultBedrockModel
+	default:
+		return DefaultModel
+	}
+}
+
+// endpoint returns the URL requestBody is posted to. Vertex AI and Bedrock
+// take the model in the URL and the API version in the body instead.
+func (c *Client) endpoint(requestBody *Request) (string, error) {
+	switch c.Platform {
+	case "", PlatformAnthropic:
+		return apiURL, nil
+
+	case PlatformVertex:
+		if c.ProjectID == "" || c.Region == "" {
+			return "", fmt.Errorf("claude on vertex ai requires a project ID and region")
+		}
+		host := c.Region + "-aiplatform.googleapis.com"
+		if c.Region == "global" {
+			host = "aiplatform.googleapis.com"
+		}
+		method := "rawPredict"
+		if requestBody.Stream {
+			method = "streamRawPredict"
+		}
+		model := requestBody.Model
+		requestBody.Model = ""
+		requestBody.AnthropicVersion = vertexVersion
+		return fmt.Sprintf("https://%s/v1/projects/%s/locations/%s/publishers/anthropic/models/%s:%s",
+			host, c.ProjectID, c.Region, model, method), nil
+
+	case PlatformBedrock:
+		if c.Region == "" {
+			return "", fmt.Errorf("claude on bedrock requires a region")
+		}
+		// Bedrock streams in AWS event-stream framing rather than server-sent
+		// events, so responses are fetched whole and replayed to the handler
+		requestBody.Stream = false
+		model := strings.ReplaceAll(url.PathEscape(requestBody.Model), ":", "%3A")
+		requestBody.Model = ""
+		requestBody.AnthropicVersion = bedrockVersion
+		return fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com/model/%s/invoke", c.Region, model), nil
+
+	default:
+		return "", fmt.Errorf("unknown anthropic platform %q", c.Platform)
+	}
+}
+
+// authorize sets the authentication and version headers of req for the
+// platform. body is the request payload, which Bedrock signatures cover.
+func (c *Client) authorize(ctx context.Context, req *http.Request, body []byte) error {
+	switch c.Platform {
+	case PlatformVertex:
+		token, err := c.googleToken(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get google credentials for claude on vertex ai: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+
+	case PlatformBedrock:
+		// A Bedrock API key replaces request signing
+		if c.APIKey != "" {
+			req.Header.Set("Authorization", "Bearer "+c.APIKey)
+			return nil
+		}
+		if c.AWS.AccessKeyID == "" || c.AWS.SecretAccessKey == "" {
+			return fmt.Errorf("claude on bedrock requires AWS credentials or a bedrock API key")
+		}
+		signV4(req, body, c.AWS, c.Region, "bedrock", time.Now())
+
+	default:
+		req.Header.Set("x-api-key", c.APIKey)
+		req.Header.Set("anthropic-version", "2023-06-01")
+	}
+	return nil
+}
+
+// googleToken returns an access token from GoogleCredentials, detecting
+// Application Default Credentials on first use
+func (c *Client) googleToken(ctx context.Context) (string, error) {
+	c.credsOnce.Do(func() {
+		if c.GoogleCredentials != nil {
+			return
+		}
+		creds, err := credentials.DetectDefault(&credentials.DetectOptions{Scopes: []string{cloudPlatformScope}})
+		if err != nil {
+			c.credsErr = err
+			return
+		}
+		c.GoogleCredentials = creds
+	})
+	if c.credsErr != nil {
+		return "", c.credsErr
+	}
+	token, err := c.GoogleCredentials.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	return token.Value, nil
+}
@@ -0,0 +1,118 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/auth"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+type staticToken string
+
+func (s staticToken) Token(context.Context) (*auth.Token, error) {
+	return &auth.Token{Value: string(s)}, nil
+}
+
+func TestCallAPIVertex(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		want := "https://us-east5-aiplatform.googleapis.com/v1/projects/my-project/locations/us-east5/publishers/anthropic/models/claude-sonnet-4@20250514:rawPredict"
+		if req.URL.String() != want {
+			t.Errorf("Expected URL %s, got %s", want, req.URL)
+		}
+		if req.Header.Get("Authorization") != "Bearer ya29.token" || req.Header.Get("x-api-key") != "" {
+			t.Errorf("Expected a Google bearer token and no API key, got %v", req.Header)
+		}
+		if body.Model != "" || body.AnthropicVersion != vertexVersion {
+			t.Errorf("Expected the model in the URL and version %s in the body, got %q and %q", vertexVersion, body.Model, body.AnthropicVersion)
+		}
+		return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}], "usage": {"input_tokens": 3, "output_tokens": 1}}`
+	})
+	client.Platform = PlatformVertex
+	client.ProjectID = "my-project"
+	client.Region = "us-east5"
+	client.Model = DefaultVertexModel
+	client.GoogleCredentials = staticToken("ya29.token")
+
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.Model != DefaultVertexModel {
+		t.Errorf("Expected the requested model reported, got %q", resp.Model)
+	}
+}
+
+func TestCallAPIBedrock(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiKey   string
+		wantAuth string
+	}{
+		{"api key", "bedrock-key", "Bearer bedrock-key"},
+		{"signed", "", "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(func(req *http.Request, body Request) (int, string) {
+				want := "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-sonnet-4-20250514-v1%3A0/invoke"
+				if req.URL.String() != want {
+					t.Errorf("Expected URL %s, got %s", want, req.URL)
+				}
+				if !strings.HasPrefix(req.Header.Get("Authorization"), tt.wantAuth) {
+					t.Errorf("Expected authorization %q, got %q", tt.wantAuth, req.Header.Get("Authorization"))
+				}
+				if body.Model != "" || body.AnthropicVersion != bedrockVersion || body.Stream {
+					t.Errorf("Expected an unstreamed bedrock request, got %+v", body)
+				}
+				return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}]}`
+			})
+			client.APIKey = tt.apiKey
+			client.Platform = PlatformBedrock
+			client.Region = "us-east-1"
+			client.Model = DefaultBedrockModel
+			client.AWS = AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+
+			// Bedrock responses are replayed to stream handlers in one go
+			var streamed string
+			_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil,
+				llm.WithStream(func(chunk llm.StreamChunk) { streamed += chunk.Text }))
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if streamed != "ok" {
+				t.Errorf("Expected the response replayed to the handler, got %q", streamed)
+			}
+		})
+	}
+}
+
+func TestCallAPIPlatformErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"vertex without project", Config{Platform: PlatformVertex, Region: "us-east5"}},
+		{"bedrock without credentials", Config{Platform: PlatformBedrock, Region: "us-east-1"}},
+		{"unknown platform", Config{Platform: "azure"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClientWithConfig(tt.config)
+			if _, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestNewClientPlatformDefaults(t *testing.T) {
+	if model := NewClientWithConfig(Config{Platform: PlatformBedrock}).Model; model != DefaultBedrockModel {
+		t.Errorf("Expected %s, got %s", DefaultBedrockModel, model)
+	}
+	if model := NewClientWithConfig(Config{Platform: PlatformVertex}).Model; model != DefaultVertexModel {
+		t.Errorf("Expected %s, got %s", DefaultVertexModel, model)
+	}
+}
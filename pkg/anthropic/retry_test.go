@@ -0,0 +1,70 @@
+package anthropic
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCallAPIRetry(t *testing.T) {
+	overloaded := `{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`
+	retry := llm.RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Multiplier: 2}
+
+	t.Run("RetriesOverloaded", func(t *testing.T) {
+		calls := 0
+		client := newTestClient(func(req *http.Request, body Request) (int, string) {
+			calls++
+			if calls == 1 {
+				return 529, overloaded
+			}
+			if calls == 2 {
+				return http.StatusTooManyRequests, `{"type": "error", "error": {"type": "rate_limit_error", "message": "Slow down"}}`
+			}
+			return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}]}`
+		})
+		client.Retry = retry
+
+		resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		if err != nil {
+			t.Fatalf("Expected success after retries, got %v", err)
+		}
+		if calls != 3 || llm.ResponseText(resp) != "ok" {
+			t.Errorf("Expected 3 calls and ok, got %d and %q", calls, llm.ResponseText(resp))
+		}
+	})
+
+	t.Run("FatalErrorsAreNotRetried", func(t *testing.T) {
+		calls := 0
+		client := newTestClient(func(req *http.Request, body Request) (int, string) {
+			calls++
+			return http.StatusBadRequest, `{"type": "error", "error": {"type": "invalid_request_error", "message": "max_tokens too large"}}`
+		})
+		client.Retry = retry
+
+		_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		var apiErr *llm.APIError
+		if !errors.As(err, &apiErr) || apiErr.Status != "invalid_request_error" || llm.IsTransient(err) {
+			t.Errorf("Expected a fatal invalid_request_error, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected a single attempt, got %d", calls)
+		}
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		calls := 0
+		client := newTestClient(func(req *http.Request, body Request) (int, string) {
+			calls++
+			return 529, overloaded
+		})
+
+		_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		if !llm.IsTransient(err) || calls != 1 {
+			t.Errorf("Expected one attempt returning a transient error, got %d and %v", calls, err)
+		}
+	})
+}
@@ -0,0 +1,26 @@
+package anthropic
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestSignV4 checks the get-vanilla case of the AWS SigV4 test suite
+func TestSignV4(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signV4(req, nil, creds, "us-east-1", "service", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func TestCanonicalURI(t *testing.T) {
+	if got := canonicalURI("/model/anthropic.claude-v2%3A1/invoke"); got != "/model/anthropic.claude-v2%253A1/invoke" {
+		t.Errorf("Expected escaped path encoded twice, got %s", got)
+	}
+}
@@ -0,0 +1,167 @@
+package anthropic
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// maxEventSize bounds a single server-sent event line
+const maxEventSize = 1 << 20
+
+// streamErrorStatus maps error types reported mid-stream to the HTTP status
+// the API uses for them, so llm.IsTransient classifies them the same way
+var streamErrorStatus = map[string]int{
+	"overloaded_error":      529,
+	"rate_limit_error":      http.StatusTooManyRequests,
+	"api_error":             http.StatusInternalServerError,
+	"invalid_request_error": http.StatusBadRequest,
+}
+
+// readStream assembles a Response from the server-sent events in body,
+// forwarding text deltas and completed tool calls to handler. Input deltas of
+// structuredTool are the structured answer, so they are forwarded as text.
+func readStream(body io.Reader, structuredTool string, handler llm.StreamHandler) (*Response, error) {
+	var resp *Response
+	inputs := make(map[int]*strings.Builder)
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return nil, fmt.Errorf("failed to parse stream event: %w", err)
+		}
+
+		if event.Type == "error" && event.Error != nil {
+			return nil, &llm.APIError{
+				Provider:   "anthropic",
+				StatusCode: streamErrorStatus[event.Error.Type],
+				Status:     event.Error.Type,
+				Message:    event.Error.Message,
+			}
+		}
+		if event.Type == "message_start" {
+			resp = event.Message
+			if resp == nil {
+				resp = &Response{}
+			}
+			continue
+		}
+		if resp == nil {
+			continue
+		}
+
+		switch event.Type {
+		case "content_block_start":
+			if event.ContentBlock == nil {
+				continue
+			}
+			for len(resp.Content) <= event.Index {
+				resp.Content = append(resp.Content, ContentBlock{})
+			}
+			resp.Content[event.Index] = *event.ContentBlock
+			if event.ContentBlock.Type == "tool_use" {
+				inputs[event.Index] = &strings.Builder{}
+			}
+
+		case "content_block_delta":
+			if event.Delta == nil || event.Index >= len(resp.Content) {
+				continue
+			}
+			block := &resp.Content[event.Index]
+			switch event.Delta.Type {
+			case "text_delta":
+				block.Text += event.Delta.Text
+				emit(handler, llm.StreamChunk{Text: event.Delta.Text})
+			case "thinking_delta":
+				block.Thinking += event.Delta.Thinking
+			case "signature_delta":
+				block.Signature += event.Delta.Signature
+			case "input_json_delta":
+				if input, ok := inputs[event.Index]; ok {
+					input.WriteString(event.Delta.PartialJSON)
+				}
+				if block.Name == structuredTool {
+					emit(handler, llm.StreamChunk{Text: event.Delta.PartialJSON})
+				}
+			}
+
+		case "content_block_stop":
+			input, ok := inputs[event.Index]
+			if !ok || event.Index >= len(resp.Content) {
+				continue
+			}
+			block := &resp.Content[event.Index]
+			block.Input = map[string]interface{}{}
+			if input.Len() > 0 {
+				var parsed interface{}
+				if err := json.Unmarshal([]byte(input.String()), &parsed); err != nil {
+					return nil, fmt.Errorf("failed to parse streamed tool input: %w", err)
+				}
+				block.Input = parsed
+			}
+			if block.Name != structuredTool {
+				emit(handler, llm.StreamChunk{ToolUse: &llm.ContentBlock{
+					Type:  "tool_use",
+					ID:    block.ID,
+					Name:  block.Name,
+					Input: block.Input,
+				}})
+			}
+
+		case "message_delta":
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				resp.StopReason = event.Delta.StopReason
+			}
+			if event.Usage != nil {
+				resp.Usage.OutputTokens = event.Usage.OutputTokens
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("stream ended without a message")
+	}
+	return resp, nil
+}
+
+// replay forwards a response that was fetched whole to handler, for
+// platforms that cannot stream server-sent events
+func replay(resp *Response, structuredTool string, handler llm.StreamHandler) {
+	for _, block := range resp.Content {
+		switch {
+		case block.Type == "text":
+			emit(handler, llm.StreamChunk{Text: block.Text})
+		case block.Type == "tool_use" && block.Name == structuredTool:
+			data, err := json.Marshal(block.Input)
+			if err == nil {
+				emit(handler, llm.StreamChunk{Text: string(data)})
+			}
+		case block.Type == "tool_use":
+			emit(handler, llm.StreamChunk{ToolUse: &llm.ContentBlock{
+				Type:  "tool_use",
+				ID:    block.ID,
+				Name:  block.Name,
+				Input: block.Input,
+			}})
+		}
+	}
+}
+
+func emit(handler llm.StreamHandler, chunk llm.StreamChunk) {
+	if handler != nil {
+		handler(chunk)
+	}
+}
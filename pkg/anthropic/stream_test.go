@@ -0,0 +1,108 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// sse joins events into a server-sent event stream body
+func sse(events ...string) string {
+	var sb strings.Builder
+	for _, event := range events {
+		sb.WriteString("event: message\ndata: " + event + "\n\n")
+	}
+	return sb.String()
+}
+
+func TestCallAPIStream(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		if !body.Stream {
+			t.Error("Expected stream to be requested")
+		}
+		return http.StatusOK, sse(
+			`{"type": "message_start", "message": {"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-sonnet-4-20250514", "content": [], "usage": {"input_tokens": 120, "output_tokens": 1}}}`,
+			`{"type": "content_block_start", "index": 0, "content_block": {"type": "text", "text": ""}}`,
+			`{"type": "ping"}`,
+			`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "Searching "}}`,
+			`{"type": "content_block_delta", "index": 0, "delta": {"type": "text_delta", "text": "GitHub"}}`,
+			`{"type": "content_block_stop", "index": 0}`,
+			`{"type": "content_block_start", "index": 1, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "search_github_developers", "input": {}}}`,
+			`{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "{\"language\": "}}`,
+			`{"type": "content_block_delta", "index": 1, "delta": {"type": "input_json_delta", "partial_json": "\"go\"}"}}`,
+			`{"type": "content_block_stop", "index": 1}`,
+			`{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 42}}`,
+			`{"type": "message_stop"}`,
+		)
+	})
+
+	var text strings.Builder
+	var toolUses []*llm.ContentBlock
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("Find Go developers")}, nil,
+		llm.WithStream(func(chunk llm.StreamChunk) {
+			text.WriteString(chunk.Text)
+			if chunk.ToolUse != nil {
+				toolUses = append(toolUses, chunk.ToolUse)
+			}
+		}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if text.String() != "Searching GitHub" {
+		t.Errorf("Expected streamed text, got %q", text.String())
+	}
+	if len(toolUses) != 1 || toolUses[0].Input.(map[string]interface{})["language"] != "go" {
+		t.Errorf("Expected one streamed tool call with parsed input, got %+v", toolUses)
+	}
+	if resp.StopReason != "tool_use" || resp.Usage.InputTokens != 120 || resp.Usage.OutputTokens != 42 {
+		t.Errorf("Expected tool_use with final usage, got %+v", resp)
+	}
+	if len(resp.Content) != 2 || resp.Content[0].Text != "Searching GitHub" || resp.Content[1].ID != "toolu_1" {
+		t.Errorf("Expected assembled content, got %+v", resp.Content)
+	}
+}
+
+func TestCallAPIStreamStructuredOutput(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		return http.StatusOK, sse(
+			`{"type": "message_start", "message": {"id": "msg_1", "content": [], "usage": {"input_tokens": 10}}}`,
+			`{"type": "content_block_start", "index": 0, "content_block": {"type": "tool_use", "id": "toolu_1", "name": "final_result", "input": {}}}`,
+			`{"type": "content_block_delta", "index": 0, "delta": {"type": "input_json_delta", "partial_json": "{\"top_candidates\": []}"}}`,
+			`{"type": "content_block_stop", "index": 0}`,
+			`{"type": "message_delta", "delta": {"stop_reason": "tool_use"}, "usage": {"output_tokens": 8}}`,
+		)
+	})
+
+	var streamed strings.Builder
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("rank")}, nil,
+		llm.WithResponseSchema(&llm.ResponseSchema{Name: "final_result", Schema: llm.InputSchema{Type: "object"}}),
+		llm.WithStream(func(chunk llm.StreamChunk) { streamed.WriteString(chunk.Text) }))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if streamed.String() != `{"top_candidates": []}` {
+		t.Errorf("Expected structured JSON streamed as text, got %q", streamed.String())
+	}
+	if llm.ResponseText(resp) != `{"top_candidates":[]}` || resp.StopReason != "end_turn" {
+		t.Errorf("Expected structured output as text, got %+v", resp)
+	}
+}
+
+func TestCallAPIStreamError(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		return http.StatusOK, sse(
+			`{"type": "message_start", "message": {"id": "msg_1", "content": []}}`,
+			`{"type": "error", "error": {"type": "overloaded_error", "message": "Overloaded"}}`,
+		)
+	})
+
+	_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil,
+		llm.WithStream(func(llm.StreamChunk) {}))
+	if !llm.IsTransient(err) {
+		t.Errorf("Expected a transient overloaded error, got %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package anthropic
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCallAPIThinking(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		if body.Thinking == nil || body.Thinking.Type != "enabled" || body.Thinking.BudgetTokens != 2048 {
+			t.Errorf("Expected thinking enabled with 2048 tokens, got %+v", body.Thinking)
+		}
+		if body.MaxTokens != DefaultMaxTokens+2048 {
+			t.Errorf("Expected max_tokens to include the thinking budget, got %d", body.MaxTokens)
+		}
+		if body.Temperature != nil {
+			t.Errorf("Expected temperature dropped, got %v", *body.Temperature)
+		}
+		if body.ToolChoice == nil || body.ToolChoice.Type != "auto" {
+			t.Errorf("Expected forced structured tool relaxed to auto, got %+v", body.ToolChoice)
+		}
+		return http.StatusOK, `{
+			"content": [
+				{"type": "thinking", "thinking": "Rank by Go repositories first", "signature": "c2ln"},
+				{"type": "redacted_thinking", "data": "ZW5j"},
+				{"type": "tool_use", "id": "toolu_1", "name": "ranking", "input": {"top": 1}}
+			]
+		}`
+	})
+	client.StageThinkingBudgets = map[string]int{"ranking": 2048}
+
+	schema := &llm.ResponseSchema{Name: "ranking", Schema: llm.InputSchema{Type: "object"}}
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("rank")}, nil,
+		llm.WithStage("ranking"), llm.WithResponseSchema(schema), llm.WithTemperature(0.2))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resp.Content) != 3 {
+		t.Fatalf("Expected 3 content blocks, got %+v", resp.Content)
+	}
+	if resp.Content[0].Type != "thinking" || resp.Content[0].Text != "Rank by Go repositories first" || resp.Content[0].ThoughtSignature != "c2ln" {
+		t.Errorf("Expected thinking block with signature, got %+v", resp.Content[0])
+	}
+	if resp.Content[1].Type != "redacted_thinking" || resp.Content[1].ThoughtSignature != "ZW5j" {
+		t.Errorf("Expected redacted thinking block, got %+v", resp.Content[1])
+	}
+
+	// Thinking blocks must round-trip unchanged on the next turn
+	_, out := convertMessages([]llm.Message{llm.UserText("rank"), llm.AssistantBlocks(resp.Content[:2]...)})
+	blocks, ok := out[1].Content.([]ContentBlock)
+	if !ok || len(blocks) != 2 || blocks[0].Thinking != "Rank by Go repositories first" || blocks[0].Signature != "c2ln" || blocks[1].Data != "ZW5j" {
+		t.Errorf("Expected thinking blocks sent back with signatures, got %+v", out[1].Content)
+	}
+}
+
+func TestCallAPIThinkingDisabledByStage(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		if body.Thinking != nil {
+			t.Errorf("Expected no thinking, got %+v", body.Thinking)
+		}
+		return http.StatusOK, `{"content": [{"type": "text", "text": "ok"}]}`
+	})
+	client.ThinkingBudget = 4096
+	client.StageThinkingBudgets = map[string]int{"requirements": 0}
+
+	if _, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil, llm.WithStage("requirements")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestCallAPIStreamThinking(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body Request) (int, string) {
+		return http.StatusOK, sse(
+			`{"type": "message_start", "message": {"content": [], "usage": {"input_tokens": 10}}}`,
+			`{"type": "content_block_start", "index": 0, "content_block": {"type": "thinking", "thinking": ""}}`,
+			`{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "Compare "}}`,
+			`{"type": "content_block_delta", "index": 0, "delta": {"type": "thinking_delta", "thinking": "repositories"}}`,
+			`{"type": "content_block_delta", "index": 0, "delta": {"type": "signature_delta", "signature": "c2ln"}}`,
+			`{"type": "content_block_stop", "index": 0}`,
+			`{"type": "content_block_start", "index": 1, "content_block": {"type": "text", "text": ""}}`,
+			`{"type": "content_block_delta", "index": 1, "delta": {"type": "text_delta", "text": "done"}}`,
+			`{"type": "content_block_stop", "index": 1}`,
+			`{"type": "message_delta", "delta": {"stop_reason": "end_turn"}, "usage": {"output_tokens": 20}}`,
+			`{"type": "message_stop"}`,
+		)
+	})
+	client.ThinkingBudget = 1024
+
+	var streamed string
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("rank")}, nil,
+		llm.WithStream(func(chunk llm.StreamChunk) { streamed += chunk.Text }))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if streamed != "done" {
+		t.Errorf("Expected only answer text streamed, got %q", streamed)
+	}
+	if len(resp.Content) != 2 || resp.Content[0].Text != "Compare repositories" || resp.Content[0].ThoughtSignature != "c2ln" {
+		t.Errorf("Expected assembled thinking block with signature, got %+v", resp.Content)
+	}
+}
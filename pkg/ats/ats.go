@@ -0,0 +1,127 @@
+// Package ats exports ranked candidates to applicant tracking systems. Each
+// ATS is an Exporter registered by name in Exporters, so a backend is added
+// without touching the agent or the commands that export.
+package ats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Exporter creates candidates in an ATS
+type Exporter interface {
+	// ExportCandidate creates candidate, as ranked by the run with runID,
+	// in the ATS. When a step after creating them fails, the created
+	// candidate is returned along with the error.
+	ExportCandidate(ctx context.Context, runID string, candidate agent.RankedCandidate) (Exported, error)
+}
+
+// Exported is a candidate created in an ATS
+type Exported struct {
+	Username string `json:"username"`
+	ID       string `json:"id"`            // The ATS's ID of the candidate
+	URL      string `json:"url,omitempty"` // Where recruiters find them in the ATS, if known
+}
+
+// Export exports the top candidates of result, at most limit of them or all
+// for zero. A failed candidate does not stop the others: the exported ones
+// are returned with the failures joined, including those created before
+// their export failed.
+func Export(ctx context.Context, exporter Exporter, result *agent.FinalResult, limit int) ([]Exported, error) {
+	candidates := result.TopCandidates
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	var exported []Exported
+	var errs []error
+	for _, candidate := range candidates {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		e, err := exporter.ExportCandidate(ctx, result.RunID, candidate)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", candidate.Username, err))
+		}
+		if e.ID != "" {
+			exported = append(exported, e)
+		}
+	}
+	return exported, errors.Join(errs...)
+}
+
+// ExporterFactory builds the exporter of an ATS, validating its settings
+type ExporterFactory func() (Exporter, error)
+
+// Exporters is a registry of the ATS exporters a command can choose from
+type Exporters struct {
+	names     []string
+	factories map[string]ExporterFactory
+}
+
+// Register adds the factory for name, replacing any earlier registration
+func (e *Exporters) Register(name string, factory ExporterFactory) {
+	if e.factories == nil {
+		e.factories = make(map[string]ExporterFactory)
+	}
+	if _, ok := e.factories[name]; !ok {
+		e.names = append(e.names, name)
+	}
+	e.factories[name] = factory
+}
+
+// Names lists the registered exporters in registration order
+func (e *Exporters) Names() []string {
+	return append([]string(nil), e.names...)
+}
+
+// New builds the exporter of the named ATS
+func (e *Exporters) New(name string) (Exporter, error) {
+	factory, ok := e.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown ATS %q: want one of %s", name, strings.Join(e.names, ", "))
+	}
+	exporter, err := factory()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return exporter, nil
+}
+
+// candidateNote summarizes why the agent surfaced a candidate, for the note
+// an exporter attaches to them
+func candidateNote(runID string, c agent.RankedCandidate) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sourced by sourcing-agent: ranked #%d with a match score of %.1f", c.Rank, c.FinalMatchScore)
+	if runID != "" {
+		fmt.Fprintf(&b, " in run %s", runID)
+	}
+	b.WriteString(".\n")
+	if c.MatchReasoning != "" {
+		b.WriteString("\n" + c.MatchReasoning + "\n")
+	}
+	if len(c.KeyQualifications) > 0 {
+		b.WriteString("\nKey qualifications: " + strings.Join(c.KeyQualifications, ", ") + "\n")
+	}
+	if len(c.TopRelevantProjects) > 0 {
+		b.WriteString("\nTop projects:\n")
+		for _, p := range c.TopRelevantProjects {
+			line := "- " + p.Name
+			if p.URL != "" {
+				line += " (" + p.URL + ")"
+			}
+			if p.WhyRelevant != "" {
+				line += ": " + p.WhyRelevant
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if c.PotentialConcerns != "" {
+		b.WriteString("\nConcerns: " + c.PotentialConcerns + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,54 @@
+package ats
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// exporterFunc adapts a function to Exporter
+type exporterFunc func(ctx context.Context, runID string, candidate agent.RankedCandidate) (Exported, error)
+
+func (f exporterFunc) ExportCandidate(ctx context.Context, runID string, candidate agent.RankedCandidate) (Exported, error) {
+	return f(ctx, runID, candidate)
+}
+
+func TestExport(t *testing.T) {
+	exporter := exporterFunc(func(ctx context.Context, runID string, c agent.RankedCandidate) (Exported, error) {
+		if c.Username == "bob" {
+			return Exported{}, errors.New("duplicate")
+		}
+		return Exported{Username: c.Username, ID: runID + "/" + c.Username}, nil
+	})
+	result := &agent.FinalResult{RunID: "run-1", TopCandidates: []agent.RankedCandidate{{Username: "alice"}, {Username: "bob"}, {Username: "carol"}, {Username: "dave"}}}
+
+	exported, err := Export(context.Background(), exporter, result, 3)
+	if len(exported) != 2 || exported[0].ID != "run-1/alice" || exported[1].Username != "carol" {
+		t.Errorf("Expected alice and carol exported past bob's failure, got %+v", exported)
+	}
+	if err == nil || !strings.Contains(err.Error(), "bob: duplicate") {
+		t.Errorf("Expected bob's failure, got %v", err)
+	}
+}
+
+func TestExporters(t *testing.T) {
+	var exporters Exporters
+	exporters.Register("lever", func() (Exporter, error) { return nil, errors.New("LEVER_API_KEY must be set") })
+	exporters.Register("workday", func() (Exporter, error) { return exporterFunc(nil), nil })
+
+	if names := exporters.Names(); len(names) != 2 || names[0] != "lever" {
+		t.Errorf("Expected lever and workday, got %v", names)
+	}
+	if _, err := exporters.New("lever"); err == nil || err.Error() != "lever: LEVER_API_KEY must be set" {
+		t.Errorf("Expected the factory's error naming the ATS, got %v", err)
+	}
+	if _, err := exporters.New("bamboo"); err == nil || !strings.Contains(err.Error(), "want one of lever, workday") {
+		t.Errorf("Expected an unknown ATS error, got %v", err)
+	}
+	if _, err := exporters.New("workday"); err != nil {
+		t.Errorf("Expected the workday exporter, got %v", err)
+	}
+}
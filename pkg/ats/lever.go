@@ -0,0 +1,174 @@
+package ats
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// DefaultLeverBaseURL is Lever's production API; Lever's sandbox is
+// https://api.sandbox.lever.co/v1
+const DefaultLeverBaseURL = "https://api.lever.co/v1"
+
+// leverSource is the source Lever lists exported candidates under
+const leverSource = "sourcing-agent"
+
+// Lever exports candidates as opportunities through Lever's opportunities
+// API, each with a note on why they were surfaced
+type Lever struct {
+	BaseURL string // Defaults to DefaultLeverBaseURL
+	APIKey  string
+	// PerformAs is the ID of the Lever user the opportunities are created
+	// on behalf of, which Lever requires
+	PerformAs string
+	// PostingID, if set, applies the candidates to that job posting
+	PostingID string
+	// StageID, if set, places the opportunities in that pipeline stage
+	// instead of Lever's default
+	StageID string
+	// Tags are added to every opportunity, besides sourcing-agent
+	Tags []string
+
+	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// NewLever returns a Lever exporter for the API key, creating opportunities
+// as the user performAs
+func NewLever(apiKey, performAs string) *Lever {
+	return &Lever{
+		BaseURL:    DefaultLeverBaseURL,
+		APIKey:     apiKey,
+		PerformAs:  performAs,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// httpClient returns the configured HTTP client, falling back to http.DefaultClient
+func (l *Lever) httpClient() *http.Client {
+	if l.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return l.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (l *Lever) logger() *slog.Logger {
+	if l.Logger == nil {
+		return slog.Default()
+	}
+	return l.Logger
+}
+
+// leverOpportunity is the body of Lever's create opportunity request
+type leverOpportunity struct {
+	Name     string   `json:"name"`
+	Headline string   `json:"headline,omitempty"`
+	Location string   `json:"location,omitempty"`
+	Links    []string `json:"links,omitempty"`
+	Tags     []string `json:"tags"`
+	Sources  []string `json:"sources"`
+	Origin   string   `json:"origin"`
+	Stage    string   `json:"stage,omitempty"`
+	Postings []string `json:"postings,omitempty"`
+}
+
+// ExportCandidate creates an opportunity for candidate, sourced, with their
+// GitHub profile as link and a note on their ranking
+func (l *Lever) ExportCandidate(ctx context.Context, runID string, candidate agent.RankedCandidate) (Exported, error) {
+	name := candidate.Name
+	if name == "" {
+		name = candidate.Username
+	}
+	opportunity := leverOpportunity{
+		Name:     name,
+		Headline: fmt.Sprintf("GitHub @%s, match score %.1f", candidate.Username, candidate.FinalMatchScore),
+		Location: candidate.Location,
+		Tags:     append([]string{leverSource}, l.Tags...),
+		Sources:  []string{leverSource},
+		Origin:   "sourced",
+		Stage:    l.StageID,
+	}
+	if candidate.GitHubURL != "" {
+		opportunity.Links = []string{candidate.GitHubURL}
+	}
+	if l.PostingID != "" {
+		opportunity.Postings = []string{l.PostingID}
+	}
+	var created struct {
+		Data struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := l.post(ctx, "/opportunities", opportunity, &created); err != nil {
+		return Exported{}, fmt.Errorf("failed to create Lever opportunity: %w", err)
+	}
+	id := created.Data.ID
+	exported := Exported{Username: candidate.Username, ID: id, URL: "https://hire.lever.co/candidates/" + url.PathEscape(id)}
+
+	note := map[string]string{"value": candidateNote(runID, candidate)}
+	if err := l.post(ctx, "/opportunities/"+url.PathEscape(id)+"/notes", note, nil); err != nil {
+		// The opportunity exists, so it is reported along with the failure
+		return exported, fmt.Errorf("created Lever opportunity %s but failed to add its note: %w", id, err)
+	}
+	return exported, nil
+}
+
+// post sends body as JSON to the API path on behalf of PerformAs, decoding
+// the response into out if not nil
+func (l *Lever) post(ctx context.Context, path string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	baseURL := l.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultLeverBaseURL
+	}
+	endpoint := baseURL + path + "?" + url.Values{"perform_as": {l.PerformAs}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	// Lever takes the API key as the basic auth user, without a password
+	req.SetBasicAuth(l.APIKey, "")
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := l.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	l.logger().Debug("Lever request", "path", path, "status", resp.StatusCode, "duration", time.Since(start))
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("status %d (%s): %s", resp.StatusCode, apiErr.Code, apiErr.Message)
+		}
+		return fmt.Errorf("status %d: %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
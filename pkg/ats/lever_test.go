@@ -0,0 +1,73 @@
+package ats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+func TestLeverExportCandidate(t *testing.T) {
+	var opportunity leverOpportunity
+	var note map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, _, _ := r.BasicAuth(); user != "lever-key" || r.URL.Query().Get("perform_as") != "user-1" {
+			t.Errorf("Expected the API key and perform_as, got %q and %q", user, r.URL.RawQuery)
+		}
+		switch r.URL.Path {
+		case "/opportunities":
+			json.NewDecoder(r.Body).Decode(&opportunity)
+			w.Write([]byte(`{"data": {"id": "opp-1"}}`))
+		case "/opportunities/opp-1/notes":
+			json.NewDecoder(r.Body).Decode(&note)
+			w.Write([]byte(`{"data": {"noteId": "note-1"}}`))
+		default:
+			t.Errorf("Unexpected request to %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	lever := NewLever("lever-key", "user-1")
+	lever.BaseURL, lever.PostingID, lever.Tags = server.URL, "posting-1", []string{"go"}
+	candidate := agent.RankedCandidate{
+		Rank: 2, Username: "alice", Location: "Lima", GitHubURL: "https://github.com/alice", FinalMatchScore: 87.5,
+		MatchReasoning: "Maintains a Go HTTP router.", TopRelevantProjects: []agent.RelevantProject{{Name: "router", URL: "https://github.com/alice/router"}},
+	}
+	exported, err := lever.ExportCandidate(context.Background(), "run-1", candidate)
+	if err != nil {
+		t.Fatalf("Expected the candidate exported, got %v", err)
+	}
+	if exported.ID != "opp-1" || exported.Username != "alice" || exported.URL != "https://hire.lever.co/candidates/opp-1" {
+		t.Errorf("Expected opportunity opp-1 for alice, got %+v", exported)
+	}
+	// A candidate without a name goes by their username
+	if opportunity.Name != "alice" || opportunity.Origin != "sourced" || !slices.Equal(opportunity.Links, []string{"https://github.com/alice"}) ||
+		!slices.Equal(opportunity.Tags, []string{"sourcing-agent", "go"}) || !slices.Equal(opportunity.Postings, []string{"posting-1"}) {
+		t.Errorf("Unexpected opportunity %+v", opportunity)
+	}
+	for _, want := range []string{"ranked #2 with a match score of 87.5 in run run-1", "Maintains a Go HTTP router.", "- router (https://github.com/alice/router)"} {
+		if !strings.Contains(note["value"], want) {
+			t.Errorf("Expected the note to contain %q, got %q", want, note["value"])
+		}
+	}
+}
+
+func TestLeverExportCandidateError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code": "BadRequestError", "message": "perform_as is not a valid user"}`))
+	}))
+	defer server.Close()
+
+	lever := NewLever("lever-key", "nobody")
+	lever.BaseURL = server.URL
+	_, err := lever.ExportCandidate(context.Background(), "", agent.RankedCandidate{Username: "alice"})
+	if err == nil || !strings.Contains(err.Error(), "status 400 (BadRequestError): perform_as is not a valid user") {
+		t.Errorf("Expected Lever's error message, got %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+// Package bitbucket sources developers from Bitbucket Cloud behind the same
+// SourceProvider interface as GitHub. Bitbucket has no user search, and its
+// profiles carry no location, so developers are the personal workspaces
+// owning public repositories in a language, and a location is not checked.
+// A developer's username is their workspace slug.
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// DefaultBaseURL is the API of Bitbucket Cloud
+const DefaultBaseURL = "https://api.bitbucket.org/2.0"
+
+// maxRepositoryPages bounds the repository pages SearchDevelopers reads for owners
+const maxRepositoryPages = 5
+
+// Client handles interactions with the Bitbucket Cloud REST API
+type Client struct {
+	BaseURL string
+	// Token is an access token sent as a bearer token. Username and
+	// Password, an app password, authenticate instead when set. Public data
+	// is readable without either, under a lower rate limit.
+	Token      string
+	Username   string
+	Password   string
+	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// NewClient creates a client of Bitbucket Cloud authenticating with token
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL: DefaultBaseURL,
+		Token:   token,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// httpClient returns the configured HTTP client, falling back to http.DefaultClient
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+// get decodes the JSON response to a GET of path, or of an absolute next
+// page URL, into v
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	apiURL := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		apiURL = strings.TrimRight(c.BaseURL, "/") + path
+	}
+	c.logger().Debug("Bitbucket request", "url", apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	switch {
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse Bitbucket response: %w", err)
+	}
+	return nil
+}
+
+// link is a Bitbucket hyperlink, e.g. links.html
+type link struct {
+	Href string `json:"href"`
+}
+
+// repository is a Bitbucket repository as listed
+type repository struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Language    string `json:"language"`
+	CreatedOn   string `json:"created_on"`
+	UpdatedOn   string `json:"updated_on"`
+	Links       struct {
+		HTML link `json:"html"`
+	} `json:"links"`
+	Owner struct {
+		Type string `json:"type"` // "user" or "team"
+	} `json:"owner"`
+	Workspace struct {
+		Slug string `json:"slug"`
+	} `json:"workspace"`
+}
+
+// page is a page of a paginated listing
+type page[T any] struct {
+	Size   int    `json:"size"` // Total across pages; absent from some listings
+	Values []T    `json:"values"`
+	Next   string `json:"next"` // URL of the next page; empty on the last
+}
+
+// workspace is a Bitbucket workspace
+type workspace struct {
+	Slug  string `json:"slug"`
+	Name  string `json:"name"`
+	Links struct {
+		HTML   link `json:"html"`
+		Avatar link `json:"avatar"`
+	} `json:"links"`
+}
+
+// SearchDevelopers finds the personal workspaces owning the most recently
+// updated public repositories in input.Language, matching input.Keywords in
+// their name or description if set. MinRepos is checked against each
+// workspace's repository count. input.Location cannot be checked.
+func (c *Client) SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error) {
+	if input.MinRepos == 0 {
+		input.MinRepos = 5
+	}
+	if input.MaxResults == 0 {
+		input.MaxResults = 10
+	}
+	if input.Location != "" {
+		c.logger().Info("Bitbucket profiles have no location; not filtering by it", "location", input.Location)
+	}
+
+	filter := fmt.Sprintf("language = %s", bbqlString(strings.ToLower(input.Language)))
+	if input.Keywords != "" {
+		keywords := bbqlString(input.Keywords)
+		filter += fmt.Sprintf(" AND (name ~ %s OR description ~ %s)", keywords, keywords)
+	}
+	query := url.Values{}
+	query.Set("q", filter)
+	query.Set("sort", "-updated_on")
+	query.Set("pagelen", "100")
+
+	candidates := []github.Candidate{}
+	seen := make(map[string]bool)
+	next := "/repositories?" + query.Encode()
+	for pages := 0; next != "" && pages < maxRepositoryPages && len(candidates) < input.MaxResults; pages++ {
+		var repos page[repository]
+		if err := c.get(ctx, next, &repos); err != nil {
+			if pages == 0 {
+				return nil, err
+			}
+			c.logger().Warn("Failed to list Bitbucket repositories", "page", pages+1, "error", err)
+			break
+		}
+		next = repos.Next
+		for _, r := range repos.Values {
+			slug := r.Workspace.Slug
+			if r.Owner.Type != "user" || slug == "" || seen[slug] || len(candidates) >= input.MaxResults {
+				continue
+			}
+			seen[slug] = true
+			detail, err := c.GetUserDetail(ctx, slug)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				// Log error but continue with other users
+				c.logger().Warn("Failed to get user details", "username", slug, "error", err)
+				continue
+			}
+			if detail.PublicRepos < input.MinRepos {
+				continue
+			}
+			candidates = append(candidates, github.Candidate{
+				Username:    detail.Login,
+				Name:        detail.Name,
+				PublicRepos: detail.PublicRepos,
+				GitHubURL:   detail.HTMLURL,
+				AvatarURL:   detail.AvatarURL,
+			})
+		}
+	}
+
+	return &github.SearchResult{
+		Candidates: candidates,
+		TotalFound: len(candidates),
+		SearchCriteria: map[string]interface{}{
+			"source":      "bitbucket",
+			"language":    input.Language,
+			"keywords":    input.Keywords,
+			"min_repos":   input.MinRepos,
+			"max_results": input.MaxResults,
+		},
+	}, nil
+}
+
+// GetUserDetail retrieves the workspace named username, with the number of
+// its public repositories as PublicRepos
+func (c *Client) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	var w workspace
+	if err := c.get(ctx, "/workspaces/"+url.PathEscape(username), &w); err != nil {
+		return nil, err
+	}
+	// The size of a one-item page counts the repositories
+	var repos page[repository]
+	if err := c.get(ctx, "/repositories/"+url.PathEscape(username)+"?pagelen=1", &repos); err != nil {
+		return nil, err
+	}
+	return &github.UserDetail{
+		Login:       w.Slug,
+		Name:        w.Name,
+		PublicRepos: repos.Size,
+		HTMLURL:     w.Links.HTML.Href,
+		AvatarURL:   w.Links.Avatar.Href,
+	}, nil
+}
+
+// GetRepositories retrieves the most recently updated repositories of a
+// workspace. Bitbucket has no stars, so Stars and Forks are left at zero.
+func (c *Client) GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error) {
+	var repos page[repository]
+	path := fmt.Sprintf("/repositories/%s?sort=-updated_on&pagelen=%d", url.PathEscape(username), maxRepos)
+	if err := c.get(ctx, path, &repos); err != nil {
+		return nil, err
+	}
+	out := make([]github.Repository, 0, len(repos.Values))
+	for _, r := range repos.Values {
+		out = append(out, github.Repository{
+			Name:        r.Name,
+			Description: r.Description,
+			Language:    r.Language,
+			URL:         r.Links.HTML.Href,
+			CreatedAt:   r.CreatedOn,
+			UpdatedAt:   r.UpdatedOn,
+		})
+	}
+	return out, nil
+}
+
+// bbqlString quotes s as a string literal of the Bitbucket query language
+func bbqlString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,114 @@
+package bitbucket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// newTestServer serves two pages of Go repositories, owned by a team and by
+// the workspaces ana (12 repositories) and bob (2)
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	repo := func(slug, ownerType string) map[string]any {
+		return map[string]any{
+			"name": slug + "-service", "language": "go", "updated_on": "2024-05-06T00:00:00Z",
+			"links":     map[string]any{"html": map[string]string{"href": "https://bitbucket.org/" + slug + "/" + slug + "-service"}},
+			"owner":     map[string]string{"type": ownerType},
+			"workspace": map[string]string{"slug": slug},
+		}
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repositories", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("Expected the bearer token, got %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Query().Get("page") == "2" {
+			writeJSON(w, map[string]any{"values": []any{repo("ana", "user")}})
+			return
+		}
+		if got := r.URL.Query().Get("q"); got != `language = "go" AND (name ~ "grpc" OR description ~ "grpc")` {
+			t.Errorf("Unexpected query %q", got)
+		}
+		writeJSON(w, map[string]any{"values": []any{repo("acme", "team"), repo("bob", "user")}, "next": server.URL + "/repositories?page=2"})
+	})
+	mux.HandleFunc("GET /workspaces/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		slug := r.PathValue("slug")
+		if slug != "ana" && slug != "bob" {
+			http.Error(w, `{"type": "error", "error": {"message": "No workspace"}}`, http.StatusNotFound)
+			return
+		}
+		writeJSON(w, map[string]any{"slug": slug, "name": "Workspace " + slug,
+			"links": map[string]any{"html": map[string]string{"href": "https://bitbucket.org/" + slug + "/"}}})
+	})
+	mux.HandleFunc("GET /repositories/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		size := map[string]int{"ana": 12, "bob": 2}[r.PathValue("slug")]
+		writeJSON(w, map[string]any{"size": size, "values": []any{repo(r.PathValue("slug"), "user")}})
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(server *httptest.Server) *Client {
+	c := NewClient("test-token")
+	c.BaseURL = server.URL
+	return c
+}
+
+func TestSearchDevelopers(t *testing.T) {
+	c := newTestClient(newTestServer(t))
+	result, err := c.SearchDevelopers(context.Background(), github.ToolInput{Language: "Go", Keywords: "grpc", Location: "Lima", MinRepos: 5})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The team is skipped, bob has too few repositories, and ana is on the next page
+	if len(result.Candidates) != 1 {
+		t.Fatalf("Expected one candidate, got %+v", result.Candidates)
+	}
+	got := result.Candidates[0]
+	if got.Username != "ana" || got.Name != "Workspace ana" || got.PublicRepos != 12 || got.GitHubURL != "https://bitbucket.org/ana/" {
+		t.Errorf("Unexpected candidate %+v", got)
+	}
+}
+
+func TestGetRepositories(t *testing.T) {
+	c := newTestClient(newTestServer(t))
+	repos, err := c.GetRepositories(context.Background(), "ana", 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(repos) != 1 || repos[0].Name != "ana-service" || repos[0].Language != "go" || repos[0].URL != "https://bitbucket.org/ana/ana-service" {
+		t.Errorf("Unexpected repositories %+v", repos)
+	}
+
+	_, err = c.GetUserDetail(context.Background(), "nobody")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.RateLimited() {
+		t.Errorf("Expected a not found APIError, got %v", err)
+	}
+}
+
+func TestBasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "ana" || pass != "app-password" {
+			t.Errorf("Expected the app password, got %q %q", user, pass)
+		}
+		w.Write([]byte(`{"values": []}`))
+	}))
+	defer server.Close()
+	c := &Client{BaseURL: server.URL, Username: "ana", Password: "app-password"}
+	if _, err := c.GetRepositories(context.Background(), "ana", 5); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package bitbucket
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIError is a non-200 response from the Bitbucket API
+type APIError struct {
+	StatusCode int
+	Message    string // Response body
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Bitbucket API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// RateLimited reports whether the request was rejected by a rate limit
+// rather than for being invalid
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
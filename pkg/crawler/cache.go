@@ -0,0 +1,199 @@
+// Package crawler fetches the developer profiles of target languages and
+// regions ahead of searches, within the source platform's rate limits, and
+// caches them so searches are served from the cache first.
+package crawler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+)
+
+// DefaultMaxAge is how long cached profiles are used before being fetched again
+const DefaultMaxAge = 7 * 24 * time.Hour
+
+// Cache stores the profiles fetched from a source platform, e.g. storage.Store
+type Cache interface {
+	Profile(ctx context.Context, username string) (*storage.Profile, error)
+	FindProfiles(ctx context.Context, filter storage.ProfileFilter) ([]storage.Profile, error)
+	SaveProfile(ctx context.Context, username, platform string, detail github.UserDetail, at time.Time) error
+	SaveRepositories(ctx context.Context, username string, repos []github.Repository, limit int, at time.Time) error
+}
+
+var _ Cache = (*storage.Store)(nil)
+
+// CachedSource serves searches, profiles and repositories from Cache when
+// it holds them fresh enough, and from Source otherwise, caching what Source
+// returns. A cache failure is logged and falls back to Source.
+type CachedSource struct {
+	Source agent.SourceProvider
+	Cache  Cache
+	// MaxAge is how long cached profiles are used. Defaults to DefaultMaxAge.
+	MaxAge time.Duration
+	// Logger defaults to slog.Default()
+	Logger *slog.Logger
+	// now is overridden by tests
+	now func() time.Time
+}
+
+var _ agent.SourceProvider = (*CachedSource)(nil)
+
+func (c *CachedSource) maxAge() time.Duration {
+	if c.MaxAge <= 0 {
+		return DefaultMaxAge
+	}
+	return c.MaxAge
+}
+
+func (c *CachedSource) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+func (c *CachedSource) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
+
+// fresh reports whether something fetched at t can still be used
+func (c *CachedSource) fresh(t *time.Time) bool {
+	return t != nil && c.clock().Sub(*t) < c.maxAge()
+}
+
+// SearchDevelopers returns the cached developers matching input when the
+// cache holds input.MaxResults of them, the most followed first. Otherwise
+// it searches Source and caches the profiles found.
+func (c *CachedSource) SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error) {
+	maxResults := input.MaxResults
+	if maxResults == 0 {
+		maxResults = 10 // As the source clients default to
+	}
+	profiles, err := c.Cache.FindProfiles(ctx, storage.ProfileFilter{
+		Language: input.Language,
+		Location: input.Location,
+		MinRepos: input.MinRepos,
+		Since:    c.clock().Add(-c.maxAge()),
+		Limit:    maxResults,
+	})
+	if err != nil {
+		c.logger().Warn("Profile cache not read", "error", err)
+	}
+	if err == nil && len(profiles) >= maxResults {
+		c.logger().Debug("Search served from the profile cache", "language", input.Language, "location", input.Location, "candidates", len(profiles))
+		result := &github.SearchResult{
+			Candidates: make([]github.Candidate, len(profiles)),
+			TotalFound: len(profiles),
+			SearchCriteria: map[string]interface{}{
+				"language":    input.Language,
+				"location":    input.Location,
+				"keywords":    input.Keywords,
+				"min_repos":   input.MinRepos,
+				"max_results": input.MaxResults,
+				"cached":      true,
+			},
+		}
+		for i, p := range profiles {
+			result.Candidates[i] = candidate(*p.Detail, p.Username, p.Platform)
+		}
+		return result, nil
+	}
+
+	result, err := c.Source.SearchDevelopers(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	for _, candidate := range result.Candidates {
+		c.saveProfile(ctx, candidate.Username, candidate.Platform, detail(candidate))
+	}
+	return result, nil
+}
+
+// GetUserDetail returns the cached profile of username, if fresh, or else
+// fetches and caches it
+func (c *CachedSource) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	if p := c.cached(ctx, username); p != nil && p.Detail != nil && c.fresh(p.DetailFetchedAt) {
+		return p.Detail, nil
+	}
+	detail, err := c.Source.GetUserDetail(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	c.saveProfile(ctx, username, "", *detail)
+	return detail, nil
+}
+
+// GetRepositories returns the cached repositories of username, if fresh and
+// fetched up to at least maxRepos, or else fetches and caches them
+func (c *CachedSource) GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error) {
+	if p := c.cached(ctx, username); p != nil && p.RepositoriesLimit >= maxRepos && c.fresh(p.RepositoriesFetchedAt) {
+		return p.Repositories[:min(len(p.Repositories), maxRepos)], nil
+	}
+	repos, err := c.Source.GetRepositories(ctx, username, maxRepos)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Cache.SaveRepositories(ctx, username, repos, maxRepos, c.clock()); err != nil {
+		c.logger().Warn("Repositories not cached", "username", username, "error", err)
+	}
+	return repos, nil
+}
+
+// cached returns the cached profile of username, nil if there is none
+func (c *CachedSource) cached(ctx context.Context, username string) *storage.Profile {
+	p, err := c.Cache.Profile(ctx, username)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		c.logger().Warn("Profile cache not read", "username", username, "error", err)
+	}
+	return p
+}
+
+func (c *CachedSource) saveProfile(ctx context.Context, username, platform string, detail github.UserDetail) {
+	if err := c.Cache.SaveProfile(ctx, username, platform, detail, c.clock()); err != nil {
+		c.logger().Warn("Profile not cached", "username", username, "error", err)
+	}
+}
+
+// detail returns the profile of a candidate found by a search
+func detail(c github.Candidate) github.UserDetail {
+	return github.UserDetail{
+		Login:       c.Username,
+		Name:        c.Name,
+		Company:     c.Company,
+		Blog:        c.Blog,
+		Location:    c.Location,
+		Bio:         c.Bio,
+		PublicRepos: c.PublicRepos,
+		Followers:   c.Followers,
+		HTMLURL:     c.GitHubURL,
+		AvatarURL:   c.AvatarURL,
+	}
+}
+
+// candidate returns the candidate of a cached profile
+func candidate(d github.UserDetail, username, platform string) github.Candidate {
+	if d.Login == "" {
+		d.Login = username
+	}
+	return github.Candidate{
+		Username:    d.Login,
+		Name:        d.Name,
+		Location:    d.Location,
+		Company:     d.Company,
+		Bio:         d.Bio,
+		Blog:        d.Blog,
+		PublicRepos: d.PublicRepos,
+		Followers:   d.Followers,
+		GitHubURL:   d.HTMLURL,
+		AvatarURL:   d.AvatarURL,
+		Platform:    platform,
+	}
+}
@@ -0,0 +1,226 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// Defaults of the Crawler settings
+const (
+	DefaultProfilesPerTarget = 100 // The most one search page returns
+	DefaultMaxRepos          = 10  // As many as the enrichment stage reads
+	DefaultBackoff           = 15 * time.Minute
+)
+
+// Target is a language and region whose developers are crawled
+type Target struct {
+	Language string `json:"language"`
+	Location string `json:"location,omitempty"` // Anywhere if empty
+}
+
+func (t Target) String() string {
+	if t.Location == "" {
+		return t.Language
+	}
+	return t.Language + " in " + t.Location
+}
+
+// Targets returns every combination of languages and locations, by
+// language; each language anywhere when there are no locations
+func Targets(languages, locations []string) []Target {
+	if len(locations) == 0 {
+		locations = []string{""}
+	}
+	var targets []Target
+	for _, language := range languages {
+		for _, location := range locations {
+			targets = append(targets, Target{Language: language, Location: location})
+		}
+	}
+	return targets
+}
+
+// Crawler fetches the developers of its targets from Source into Cache:
+// the profiles a search of each target returns, and their most starred
+// repositories unless cached within MaxAge. Each profile is cached as soon
+// as it is fetched, so an interrupted crawl keeps its progress.
+type Crawler struct {
+	Source  agent.SourceProvider
+	Cache   Cache
+	Targets []Target
+	// ProfilesPerTarget is the number of developers searched per target.
+	// Defaults to DefaultProfilesPerTarget.
+	ProfilesPerTarget int
+	// MinRepos skips developers with no more public repositories than this
+	MinRepos int
+	// MaxRepos is the number of repositories fetched per developer.
+	// Defaults to DefaultMaxRepos.
+	MaxRepos int
+	// MaxAge is how long cached repositories are kept before being fetched
+	// again. Defaults to DefaultMaxAge.
+	MaxAge time.Duration
+	// Backoff is how long to wait when the source rate limits the crawl,
+	// before trying again. Defaults to DefaultBackoff.
+	Backoff time.Duration
+	// Logger defaults to slog.Default()
+	Logger *slog.Logger
+}
+
+// Stats counts what a crawl did
+type Stats struct {
+	Targets      int `json:"targets"`
+	Profiles     int `json:"profiles"`     // Profiles fetched
+	Repositories int `json:"repositories"` // Developers whose repositories were fetched
+	Fresh        int `json:"fresh"`        // Developers whose cached repositories were kept
+	Failed       int `json:"failed"`       // Developers whose repositories failed to fetch
+	RateLimited  int `json:"rate_limited"` // Times the source rate limited the crawl
+}
+
+func (c *Crawler) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+// Crawl crawls every target once. It waits out rate limits and returns
+// early only when ctx is done or a search fails otherwise.
+func (c *Crawler) Crawl(ctx context.Context) (Stats, error) {
+	var stats Stats
+	for _, target := range c.Targets {
+		if err := c.crawlTarget(ctx, target, &stats); err != nil {
+			return stats, fmt.Errorf("crawling %s: %w", target, err)
+		}
+		stats.Targets++
+	}
+	return stats, nil
+}
+
+// crawlTarget searches the developers of target and fetches their repositories
+func (c *Crawler) crawlTarget(ctx context.Context, target Target, stats *Stats) error {
+	profiles := c.ProfilesPerTarget
+	if profiles <= 0 {
+		profiles = DefaultProfilesPerTarget
+	}
+	input := github.ToolInput{Language: target.Language, Location: target.Location, MinRepos: c.MinRepos, MaxResults: profiles}
+	var result *github.SearchResult
+	err := c.retry(ctx, stats, func() (err error) {
+		result, err = c.Source.SearchDevelopers(ctx, input)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	maxRepos := c.MaxRepos
+	if maxRepos <= 0 {
+		maxRepos = DefaultMaxRepos
+	}
+	maxAge := c.MaxAge
+	if maxAge <= 0 {
+		maxAge = DefaultMaxAge
+	}
+	for _, candidate := range result.Candidates {
+		now := time.Now()
+		if err := c.Cache.SaveProfile(ctx, candidate.Username, candidate.Platform, detail(candidate), now); err != nil {
+			return err
+		}
+		stats.Profiles++
+
+		cached, err := c.Cache.Profile(ctx, candidate.Username)
+		if err != nil {
+			return err
+		}
+		if cached.RepositoriesLimit >= maxRepos && cached.RepositoriesFetchedAt != nil && now.Sub(*cached.RepositoriesFetchedAt) < maxAge {
+			stats.Fresh++
+			continue
+		}
+		var repos []github.Repository
+		err = c.retry(ctx, stats, func() (err error) {
+			repos, err = c.Source.GetRepositories(ctx, candidate.Username, maxRepos)
+			return err
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			// A developer gone since the search is no reason to stop
+			c.logger().Warn("Repositories not crawled", "username", candidate.Username, "error", err)
+			stats.Failed++
+			continue
+		}
+		if err := c.Cache.SaveRepositories(ctx, candidate.Username, repos, maxRepos, time.Now()); err != nil {
+			return err
+		}
+		stats.Repositories++
+	}
+	c.logger().Info("Target crawled", "target", target.String(), "profiles", len(result.Candidates))
+	return nil
+}
+
+// retry calls fn until it succeeds or fails other than by a rate limit,
+// waiting Backoff after each rate limit
+func (c *Crawler) retry(ctx context.Context, stats *Stats, fn func() error) error {
+	backoff := c.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackoff
+	}
+	for {
+		err := fn()
+		var limited interface{ RateLimited() bool }
+		if err == nil || !errors.As(err, &limited) || !limited.RateLimited() {
+			return err
+		}
+		stats.RateLimited++
+		c.logger().Warn("Rate limited, backing off", "until", time.Now().Add(backoff).Format(time.RFC3339), "error", err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// Throttle returns a transport middleware spacing requests evenly so at
+// most requestsPerHour are sent per hour, to leave the source's rate limit
+// to interactive searches. Requests wait for their turn, or fail when their
+// context is done first.
+func Throttle(requestsPerHour int) func(http.RoundTripper) http.RoundTripper {
+	interval := time.Hour / time.Duration(requestsPerHour)
+	var mu sync.Mutex
+	var next time.Time
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			mu.Lock()
+			now := time.Now()
+			at := next
+			if at.Before(now) {
+				at = now
+			}
+			next = at.Add(interval)
+			mu.Unlock()
+			if wait := at.Sub(now); wait > 0 {
+				timer := time.NewTimer(wait)
+				defer timer.Stop()
+				select {
+				case <-req.Context().Done():
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+			}
+			return rt.RoundTrip(req)
+		})
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/storage"
+)
+
+// fakeSource serves developers by language, counting its calls
+type fakeSource struct {
+	developers  map[string][]github.Candidate // By language
+	repos       map[string][]github.Repository
+	rateLimited int // Calls to fail with a rate limit before answering
+	searches    int
+	repoCalls   int
+}
+
+func (s *fakeSource) limited() error {
+	if s.rateLimited > 0 {
+		s.rateLimited--
+		return &github.APIError{StatusCode: http.StatusTooManyRequests}
+	}
+	return nil
+}
+
+func (s *fakeSource) SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error) {
+	if err := s.limited(); err != nil {
+		return nil, err
+	}
+	s.searches++
+	candidates := s.developers[input.Language]
+	return &github.SearchResult{Candidates: candidates[:min(len(candidates), input.MaxResults)], TotalFound: len(candidates)}, nil
+}
+
+func (s *fakeSource) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	return &github.UserDetail{Login: username}, nil
+}
+
+func (s *fakeSource) GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error) {
+	if err := s.limited(); err != nil {
+		return nil, err
+	}
+	s.repoCalls++
+	return s.repos[username], nil
+}
+
+func openTestCache(t *testing.T) *storage.Store {
+	t.Helper()
+	store, err := storage.Open(t.Context(), filepath.Join(t.TempDir(), "sourcing.db"))
+	if err != nil {
+		t.Fatalf("Expected to open the store, got %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func testSource() *fakeSource {
+	return &fakeSource{
+		developers: map[string][]github.Candidate{
+			"Go": {
+				{Username: "ana", Name: "Ana", Location: "Lima, Peru", PublicRepos: 12, Followers: 40},
+				{Username: "bob", Location: "Lima", PublicRepos: 9, Followers: 80},
+			},
+		},
+		repos: map[string][]github.Repository{
+			"ana": {{Name: "grpc-tools", Language: "Go"}},
+			"bob": {{Name: "gin-api", Language: "Go"}, {Name: "scripts", Language: "Python"}},
+		},
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	source, cache := testSource(), openTestCache(t)
+	source.rateLimited = 1
+	crawler := &Crawler{Source: source, Cache: cache, Targets: Targets([]string{"Go"}, []string{"Lima"}), Backoff: time.Millisecond}
+
+	stats, err := crawler.Crawl(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the crawl to succeed, got %v", err)
+	}
+	expected := Stats{Targets: 1, Profiles: 2, Repositories: 2, RateLimited: 1}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+	bob, err := cache.Profile(context.Background(), "bob")
+	if err != nil || bob.Detail.Location != "Lima" || len(bob.Repositories) != 2 || bob.RepositoriesLimit != DefaultMaxRepos {
+		t.Fatalf("Expected bob's profile and repositories cached, got %+v, %v", bob, err)
+	}
+
+	// A second crawl keeps the fresh repositories
+	stats, err = crawler.Crawl(context.Background())
+	if err != nil {
+		t.Fatalf("Expected the second crawl to succeed, got %v", err)
+	}
+	if stats.Profiles != 2 || stats.Fresh != 2 || stats.Repositories != 0 || source.repoCalls != 2 {
+		t.Errorf("Expected the cached repositories kept, got %+v after %d repository calls", stats, source.repoCalls)
+	}
+}
+
+func TestCachedSource(t *testing.T) {
+	source, cache := testSource(), openTestCache(t)
+	crawler := &Crawler{Source: source, Cache: cache, Targets: Targets([]string{"Go"}, nil)}
+	if _, err := crawler.Crawl(context.Background()); err != nil {
+		t.Fatalf("Expected the crawl to succeed, got %v", err)
+	}
+	cached := &CachedSource{Source: source, Cache: cache}
+	searches, repoCalls := source.searches, source.repoCalls
+
+	result, err := cached.SearchDevelopers(context.Background(), github.ToolInput{Language: "go", Location: "lima", MinRepos: 5, MaxResults: 2})
+	if err != nil {
+		t.Fatalf("Expected a cached search, got %v", err)
+	}
+	if len(result.Candidates) != 2 || result.Candidates[0].Username != "bob" || result.Candidates[1].Name != "Ana" || source.searches != searches {
+		t.Errorf("Expected bob and ana from the cache, most followed first, got %+v", result.Candidates)
+	}
+	repos, err := cached.GetRepositories(context.Background(), "ana", 10)
+	if err != nil || len(repos) != 1 || source.repoCalls != repoCalls {
+		t.Errorf("Expected ana's cached repositories, got %+v, %v", repos, err)
+	}
+
+	// Without enough cached developers the source is searched, and its results cached
+	if _, err := cached.SearchDevelopers(context.Background(), github.ToolInput{Language: "go", MaxResults: 3}); err != nil {
+		t.Fatalf("Expected a search, got %v", err)
+	}
+	if source.searches != searches+1 {
+		t.Errorf("Expected the source searched, got %d searches", source.searches-searches)
+	}
+	// Repositories fetched beyond the cached limit, or once stale, come from the source
+	if _, err := cached.GetRepositories(context.Background(), "ana", 30); err != nil || source.repoCalls != repoCalls+1 {
+		t.Errorf("Expected more repositories fetched, got %v after %d calls", err, source.repoCalls-repoCalls)
+	}
+	cached.now = func() time.Time { return time.Now().Add(DefaultMaxAge) }
+	if _, err := cached.GetRepositories(context.Background(), "bob", 10); err != nil || source.repoCalls != repoCalls+2 {
+		t.Errorf("Expected stale repositories fetched, got %v after %d calls", err, source.repoCalls-repoCalls)
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	client := &http.Client{Transport: Throttle(36000)(http.DefaultTransport)} // One request per 100ms
+
+	start := time.Now()
+	for range 3 {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected the request to succeed, got %v", err)
+		}
+		resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("Expected three requests to take at least 200ms, took %v", elapsed)
+	}
+}
@@ -0,0 +1,178 @@
+// Package export writes results as spreadsheet files: CSV, a single table of
+// the top candidates, and XLSX workbooks with a summary, candidates and
+// evidence sheet. The CLI's -format and the download endpoints of serve both
+// write through it.
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Export formats
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+// Formats lists the formats Write accepts
+var Formats = []string{CSV, XLSX}
+
+// Write writes result to w in format
+func Write(w io.Writer, format string, result *agent.FinalResult) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, result)
+	case XLSX:
+		return writeXLSX(w, workbook(result))
+	default:
+		return fmt.Errorf("unknown export format %q: want one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// ContentType is the media type of files in format, for downloads
+func ContentType(format string) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "application/octet-stream"
+}
+
+// column is a column of a table of candidates. Its value is a string, int or
+// float64, so spreadsheets keep numbers sortable.
+type column struct {
+	header string
+	value  func(c agent.RankedCandidate) any
+}
+
+// candidateColumns are the columns of the CSV and the candidates sheet
+var candidateColumns = []column{
+	{"rank", func(c agent.RankedCandidate) any { return c.Rank }},
+	{"username", func(c agent.RankedCandidate) any { return c.Username }},
+	{"name", func(c agent.RankedCandidate) any { return c.Name }},
+	{"location", func(c agent.RankedCandidate) any { return c.Location }},
+	{"github_url", func(c agent.RankedCandidate) any { return c.GitHubURL }},
+	{"final_match_score", func(c agent.RankedCandidate) any { return c.FinalMatchScore }},
+	{"required_skills_score", func(c agent.RankedCandidate) any { return c.MatchBreakdown.RequiredSkillsScore }},
+	{"repository_relevance_score", func(c agent.RankedCandidate) any { return c.MatchBreakdown.RepositoryRelevanceScore }},
+	{"experience_score", func(c agent.RankedCandidate) any { return c.MatchBreakdown.ExperienceScore }},
+	{"profile_quality_score", func(c agent.RankedCandidate) any { return c.MatchBreakdown.ProfileQualityScore }},
+	{"key_qualifications", func(c agent.RankedCandidate) any { return strings.Join(c.KeyQualifications, "; ") }},
+	{"top_projects", func(c agent.RankedCandidate) any { return strings.Join(projectNames(c), "; ") }},
+	{"match_reasoning", func(c agent.RankedCandidate) any { return c.MatchReasoning }},
+	{"potential_concerns", func(c agent.RankedCandidate) any { return c.PotentialConcerns }},
+	{"seen_before", func(c agent.RankedCandidate) any { return agent.SeenBeforeNote(c.SeenBefore) }},
+}
+
+// projectNames lists a candidate's top project names
+func projectNames(c agent.RankedCandidate) []string {
+	names := make([]string, len(c.TopRelevantProjects))
+	for i, p := range c.TopRelevantProjects {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// candidateRows is the table of the top candidates of result, headers first
+func candidateRows(result *agent.FinalResult) [][]any {
+	header := make([]any, len(candidateColumns))
+	for i, col := range candidateColumns {
+		header[i] = col.header
+	}
+	rows := [][]any{header}
+	for _, c := range result.TopCandidates {
+		row := make([]any, len(candidateColumns))
+		for i, col := range candidateColumns {
+			row[i] = col.value(c)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// summaryRows describes the run behind result as label and value rows
+func summaryRows(result *agent.FinalResult) [][]any {
+	rows := [][]any{{"field", "value"}}
+	add := func(label string, value any) { rows = append(rows, []any{label, value}) }
+	if result.RunID != "" {
+		add("run_id", result.RunID)
+	}
+	if result.Partial {
+		add("partial", "ranking failed; candidates are unranked")
+	}
+	if r := result.Requirements; r != nil {
+		add("required_skills", strings.Join(r.RequiredSkills, ", "))
+		add("experience_level", r.ExperienceLevel)
+		add("locations", strings.Join(r.Locations, ", "))
+		add("nice_to_have", strings.Join(r.NiceToHave, ", "))
+	}
+	if m := result.SearchMetadata; m != nil {
+		add("profiles_found", m.TotalProfilesFound)
+		add("profiles_analyzed", m.ProfilesAnalyzed)
+	}
+	add("candidates_found", result.Summary.TotalCandidatesFound)
+	add("candidates_presented", result.Summary.CandidatesPresented)
+	add("average_match_score", result.Summary.AverageMatchScore)
+	add("search_quality", result.Summary.SearchQuality)
+	return rows
+}
+
+// evidenceRows lists what each candidate's ranking rests on, one row per
+// qualification, project and concern
+func evidenceRows(result *agent.FinalResult) [][]any {
+	rows := [][]any{{"rank", "username", "kind", "name", "url", "detail"}}
+	for _, c := range result.TopCandidates {
+		for _, q := range c.KeyQualifications {
+			rows = append(rows, []any{c.Rank, c.Username, "qualification", q, "", ""})
+		}
+		for _, p := range c.TopRelevantProjects {
+			rows = append(rows, []any{c.Rank, c.Username, "project", p.Name, p.URL, p.WhyRelevant})
+		}
+		if c.PotentialConcerns != "" {
+			rows = append(rows, []any{c.Rank, c.Username, "concern", "", "", c.PotentialConcerns})
+		}
+	}
+	return rows
+}
+
+// workbook lays result out as the sheets of an XLSX file
+func workbook(result *agent.FinalResult) []sheet {
+	return []sheet{
+		{name: "Summary", rows: summaryRows(result)},
+		{name: "Candidates", rows: candidateRows(result)},
+		{name: "Evidence", rows: evidenceRows(result)},
+	}
+}
+
+func writeCSV(w io.Writer, result *agent.FinalResult) error {
+	cw := csv.NewWriter(w)
+	for _, row := range candidateRows(result) {
+		record := make([]string, len(row))
+		for i, v := range row {
+			record[i] = cellText(v)
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// cellText renders a cell value as text, scores with two decimals
+func cellText(v any) string {
+	switch v := v.(type) {
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+func exportFixture() *agent.FinalResult {
+	return &agent.FinalResult{
+		RunID: "run-1",
+		TopCandidates: []agent.RankedCandidate{{
+			Rank: 1, Username: "gopher_lima", Name: "Ana Quispe", Location: "Lima, Peru",
+			GitHubURL: "https://github.com/gopher_lima", FinalMatchScore: 90,
+			KeyQualifications:   []string{"Go", "gRPC"},
+			TopRelevantProjects: []agent.RelevantProject{{Name: "grpc-gateway-kit", URL: "https://github.com/gopher_lima/grpc-gateway-kit", WhyRelevant: "gRPC & <REST>"}},
+			MatchReasoning:      "Strong Go backend, \"popular\" toolkit",
+			PotentialConcerns:   "Few public repos",
+		}},
+		Summary:      agent.ResultSummary{TotalCandidatesFound: 4, CandidatesPresented: 1, AverageMatchScore: 90, SearchQuality: "good"},
+		Requirements: &agent.Requirements{RequiredSkills: []string{"Go"}, Locations: []string{"Lima"}},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, CSV, exportFixture()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected valid CSV, got %v", err)
+	}
+	if len(records) != 2 || records[0][1] != "username" {
+		t.Fatalf("Expected a header and one row, got %v", records)
+	}
+	row := records[1]
+	if row[1] != "gopher_lima" || row[5] != "90.00" || row[10] != "Go; gRPC" || row[12] != `Strong Go backend, "popular" toolkit` {
+		t.Errorf("Expected the candidate's fields, got %v", row)
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "ods", exportFixture()); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
+
+func TestEvidenceRows(t *testing.T) {
+	rows := evidenceRows(exportFixture())
+	// Header, two qualifications, a project and a concern
+	if len(rows) != 5 {
+		t.Fatalf("Expected 5 rows, got %v", rows)
+	}
+	if project := rows[3]; project[2] != "project" || project[4] != "https://github.com/gopher_lima/grpc-gateway-kit" || project[5] != "gRPC & <REST>" {
+		t.Errorf("Expected the project's evidence, got %v", project)
+	}
+}
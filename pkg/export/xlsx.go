@@ -0,0 +1,140 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// sheet is a worksheet of an XLSX file. Its first row is a header, set in
+// bold.
+type sheet struct {
+	name string
+	rows [][]any
+}
+
+// xlsxPart is a file of the XLSX zip archive
+type xlsxPart struct {
+	name  string
+	write func(w *bufio.Writer)
+}
+
+// XML namespaces and content types of the Office Open XML parts
+const (
+	nsMain          = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPackageRels   = "http://schemas.openxmlformats.org/package/2006/relationships"
+	typeWorksheet   = "application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"
+)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n"
+
+// stylesXML defines the default cell style and a bold one, for headers
+const stylesXML = xmlHeader + `<styleSheet xmlns="` + nsMain + `">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
+
+// writeXLSX writes sheets as an XLSX workbook, with the text of cells inline
+// so no shared string table is needed
+func writeXLSX(w io.Writer, sheets []sheet) error {
+	zw := zip.NewWriter(w)
+	parts := []xlsxPart{
+		{"[Content_Types].xml", func(w *bufio.Writer) {
+			w.WriteString(xmlHeader + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+			w.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+			w.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+			w.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+			w.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+			for i := range sheets {
+				fmt.Fprintf(w, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="%s"/>`, i+1, typeWorksheet)
+			}
+			w.WriteString(`</Types>`)
+		}},
+		{"_rels/.rels", func(w *bufio.Writer) {
+			w.WriteString(xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">`)
+			w.WriteString(`<Relationship Id="rId1" Type="` + nsRelationships + `/officeDocument" Target="xl/workbook.xml"/>`)
+			w.WriteString(`</Relationships>`)
+		}},
+		{"xl/workbook.xml", func(w *bufio.Writer) {
+			w.WriteString(xmlHeader + `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `"><sheets>`)
+			for i, s := range sheets {
+				w.WriteString(`<sheet name="`)
+				xml.EscapeText(w, []byte(s.name))
+				fmt.Fprintf(w, `" sheetId="%d" r:id="rId%d"/>`, i+1, i+1)
+			}
+			w.WriteString(`</sheets></workbook>`)
+		}},
+		{"xl/_rels/workbook.xml.rels", func(w *bufio.Writer) {
+			w.WriteString(xmlHeader + `<Relationships xmlns="` + nsPackageRels + `">`)
+			for i := range sheets {
+				fmt.Fprintf(w, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, nsRelationships, i+1)
+			}
+			fmt.Fprintf(w, `<Relationship Id="rId%d" Type="%s/styles" Target="styles.xml"/>`, len(sheets)+1, nsRelationships)
+			w.WriteString(`</Relationships>`)
+		}},
+		{"xl/styles.xml", func(w *bufio.Writer) { w.WriteString(stylesXML) }},
+	}
+	for i, s := range sheets {
+		parts = append(parts, xlsxPart{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), func(w *bufio.Writer) { writeSheet(w, s) }})
+	}
+	for _, p := range parts {
+		f, err := zw.Create(p.name)
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", p.name, err)
+		}
+		bw := bufio.NewWriter(f)
+		p.write(bw)
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("failed to write %s: %w", p.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+// writeSheet writes the worksheet XML of s
+func writeSheet(w *bufio.Writer, s sheet) {
+	w.WriteString(xmlHeader + `<worksheet xmlns="` + nsMain + `"><sheetData>`)
+	for r, row := range s.rows {
+		fmt.Fprintf(w, `<row r="%d">`, r+1)
+		style := ""
+		if r == 0 {
+			style = ` s="1"`
+		}
+		for c, v := range row {
+			ref := columnName(c) + strconv.Itoa(r+1)
+			switch v := v.(type) {
+			case int:
+				fmt.Fprintf(w, `<c r="%s"%s><v>%d</v></c>`, ref, style, v)
+			case float64:
+				fmt.Fprintf(w, `<c r="%s"%s><v>%s</v></c>`, ref, style, strconv.FormatFloat(v, 'f', -1, 64))
+			default:
+				text := fmt.Sprint(v)
+				if text == "" {
+					continue
+				}
+				fmt.Fprintf(w, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">`, ref, style)
+				xml.EscapeText(w, []byte(text))
+				w.WriteString(`</t></is></c>`)
+			}
+		}
+		w.WriteString(`</row>`)
+	}
+	w.WriteString(`</sheetData></worksheet>`)
+}
+
+// columnName is the letter name of the zero-based column i: A, B, ..., Z,
+// AA, AB, ...
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
@@ -0,0 +1,76 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+// xlsxCells reads the cells of a worksheet of an XLSX file, by reference
+func xlsxCells(t *testing.T, archive *zip.Reader, name string) map[string]string {
+	t.Helper()
+	f, err := archive.Open(name)
+	if err != nil {
+		t.Fatalf("Expected %s in the workbook, got %v", name, err)
+	}
+	defer f.Close()
+	var worksheet struct {
+		Rows []struct {
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	data, _ := io.ReadAll(f)
+	if err := xml.Unmarshal(data, &worksheet); err != nil {
+		t.Fatalf("Expected valid XML in %s, got %v", name, err)
+	}
+	cells := map[string]string{}
+	for _, row := range worksheet.Rows {
+		for _, c := range row.Cells {
+			cells[c.Ref] = c.Value + c.Inline
+		}
+	}
+	return cells
+}
+
+func TestWriteXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, XLSX, exportFixture()); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("Expected a zip archive, got %v", err)
+	}
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if _, err := archive.Open(part); err != nil {
+			t.Errorf("Expected %s in the workbook, got %v", part, err)
+		}
+	}
+
+	summary := xlsxCells(t, archive, "xl/worksheets/sheet1.xml")
+	if summary["A2"] != "run_id" || summary["B2"] != "run-1" {
+		t.Errorf("Expected the run ID first in the summary, got %v", summary)
+	}
+	candidates := xlsxCells(t, archive, "xl/worksheets/sheet2.xml")
+	if candidates["B1"] != "username" || candidates["B2"] != "gopher_lima" || candidates["F2"] != "90" || candidates["O1"] != "seen_before" {
+		t.Errorf("Expected the candidates table, got %v", candidates)
+	}
+	evidence := xlsxCells(t, archive, "xl/worksheets/sheet3.xml")
+	if evidence["F4"] != "gRPC & <REST>" {
+		t.Errorf("Expected the escaped project evidence, got %v", evidence)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(i); got != want {
+			t.Errorf("columnName(%d) = %s, want %s", i, got, want)
+		}
+	}
+}
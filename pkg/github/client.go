@@ -1,12 +1,13 @@
 package github
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 	"time"
 )
@@ -29,6 +30,8 @@ type Client struct {
 	BaseURL    string
 	Token      string
 	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
 }
 
 // NewClient creates a new GitHubClient
@@ -42,8 +45,24 @@ func NewClient(token string) *Client {
 	}
 }
 
+// httpClient returns the configured HTTP client, falling back to http.DefaultClient
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
 // SearchDevelopers searches GitHub for developers matching criteria
-func (c *Client) SearchDevelopers(input ToolInput) (*SearchResult, error) {
+func (c *Client) SearchDevelopers(ctx context.Context, input ToolInput) (*SearchResult, error) {
 	// Set defaults
 	if input.MinRepos == 0 {
 		input.MinRepos = 5
@@ -70,9 +89,9 @@ func (c *Client) SearchDevelopers(input ToolInput) (*SearchResult, error) {
 	// Call GitHub Search API
 	// Request up to 100 results per page to allow for filtering attrition
 	apiURL := fmt.Sprintf("%s/search/users?q=%s&per_page=100", c.BaseURL, encodedQuery)
-	fmt.Println("SearchDevelopers: ", apiURL)
+	c.logger().Debug("Searching GitHub developers", "url", apiURL)
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -80,7 +99,7 @@ func (c *Client) SearchDevelopers(input ToolInput) (*SearchResult, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := c.HTTPClient
+	client := c.httpClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
@@ -93,14 +112,14 @@ func (c *Client) SearchDevelopers(input ToolInput) (*SearchResult, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var searchResponse SearchResponse
 	if err := json.Unmarshal(body, &searchResponse); err != nil {
 		return nil, fmt.Errorf("failed to parse search response: %w", err)
 	}
-	fmt.Println("SearchResponse: ", searchResponse)
+	c.logger().Debug("GitHub search response", "total_count", searchResponse.TotalCount, "items", len(searchResponse.Items))
 
 	// Enrich each user with detailed information
 	candidates := []Candidate{}
@@ -110,10 +129,13 @@ func (c *Client) SearchDevelopers(input ToolInput) (*SearchResult, error) {
 			break
 		}
 
-		detail, err := c.GetUserDetail(user.Login)
+		detail, err := c.GetUserDetail(ctx, user.Login)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			// Log error but continue with other users
-			fmt.Fprintf(os.Stderr, "Warning: failed to get details for user %s: %v\n", user.Login, err)
+			c.logger().Warn("Failed to get user details", "username", user.Login, "error", err)
 			continue
 		}
 
@@ -121,7 +143,10 @@ func (c *Client) SearchDevelopers(input ToolInput) (*SearchResult, error) {
 			Username:    detail.Login,
 			Name:        detail.Name,
 			Location:    detail.Location,
+			Company:     detail.Company,
 			Bio:         detail.Bio,
+			Blog:        detail.Blog,
+			Email:       detail.Email,
 			PublicRepos: detail.PublicRepos,
 			Followers:   detail.Followers,
 			GitHubURL:   detail.HTMLURL,
@@ -147,11 +172,11 @@ func (c *Client) SearchDevelopers(input ToolInput) (*SearchResult, error) {
 }
 
 // GetUserDetail retrieves detailed information for a GitHub user
-func (c *Client) GetUserDetail(username string) (*UserDetail, error) {
+func (c *Client) GetUserDetail(ctx context.Context, username string) (*UserDetail, error) {
 	url := fmt.Sprintf("%s/users/%s", c.BaseURL, username)
-	fmt.Println("GetUserDetail: ", url)
+	c.logger().Debug("Getting GitHub user", "url", url)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -159,7 +184,7 @@ func (c *Client) GetUserDetail(username string) (*UserDetail, error) {
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := c.HTTPClient
+	client := c.httpClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
@@ -172,7 +197,7 @@ func (c *Client) GetUserDetail(username string) (*UserDetail, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var userDetail UserDetail
@@ -183,12 +208,12 @@ func (c *Client) GetUserDetail(username string) (*UserDetail, error) {
 	return &userDetail, nil
 }
 
-// GetDeveloperRepositories retrieves repositories for a developer
-func (c *Client) GetDeveloperRepositories(username string, maxRepos int) ([]Repository, error) {
+// GetRepositories retrieves repositories for a developer
+func (c *Client) GetRepositories(ctx context.Context, username string, maxRepos int) ([]Repository, error) {
 	url := fmt.Sprintf("%s/users/%s/repos?sort=stars&per_page=%d", c.BaseURL, username, maxRepos)
-	fmt.Println("GetDeveloperRepositories: ", url)
+	c.logger().Debug("Getting GitHub repositories", "url", url)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -196,7 +221,7 @@ func (c *Client) GetDeveloperRepositories(username string, maxRepos int) ([]Repo
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := c.HTTPClient
+	client := c.httpClient()
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
@@ -205,7 +230,7 @@ func (c *Client) GetDeveloperRepositories(username string, maxRepos int) ([]Repo
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitHub API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, newAPIError(resp, body)
 	}
 
 	var repos []Repository
@@ -215,3 +240,83 @@ func (c *Client) GetDeveloperRepositories(username string, maxRepos int) ([]Repo
 
 	return repos, nil
 }
+
+// RateLimit is the core REST API request budget of the authenticated token
+type RateLimit struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	Reset     int64 `json:"reset"` // Unix time the budget resets
+}
+
+// RateLimits are the token's rate limits and, for a classic personal access
+// token, its OAuth scopes
+type RateLimits struct {
+	Core   RateLimit
+	Search RateLimit // Searches have their own, much smaller per-minute budget
+	// ClassicToken is set for classic personal access tokens, the only ones
+	// GitHub reports Scopes for
+	ClassicToken bool
+	Scopes       []string
+}
+
+// GetRateLimit retrieves the token's core rate limit. It does not count
+// against the budget, so it doubles as a cheap check that the token works.
+func (c *Client) GetRateLimit() (*RateLimit, error) {
+	limits, err := c.GetRateLimits()
+	if err != nil {
+		return nil, err
+	}
+	return &limits.Core, nil
+}
+
+// GetRateLimits retrieves the token's core and search rate limits and scopes,
+// without counting against either budget
+func (c *Client) GetRateLimits() (*RateLimits, error) {
+	url := fmt.Sprintf("%s/rate_limit", c.BaseURL)
+	c.logger().Debug("Getting GitHub rate limit", "url", url)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", c.Token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, body)
+	}
+
+	var limits struct {
+		Resources struct {
+			Core   RateLimit `json:"core"`
+			Search RateLimit `json:"search"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(body, &limits); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit response: %w", err)
+	}
+
+	result := &RateLimits{Core: limits.Resources.Core, Search: limits.Resources.Search}
+	// Fine-grained tokens and app tokens send no scopes header at all
+	if scopes, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		result.ClassicToken = true
+		for _, scope := range strings.Split(strings.Join(scopes, ","), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				result.Scopes = append(result.Scopes, scope)
+			}
+		}
+	}
+	return result, nil
+}
@@ -1,7 +1,11 @@
 package github
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -108,7 +112,7 @@ func TestSearchDevelopers(t *testing.T) {
 			MaxResults: 10,
 		}
 
-		result, err := client.SearchDevelopers(input)
+		result, err := client.SearchDevelopers(context.Background(), input)
 		if err != nil {
 			t.Fatalf("SearchDevelopers failed: %v", err)
 		}
@@ -125,6 +129,32 @@ func TestSearchDevelopers(t *testing.T) {
 			t.Errorf("Expected first candidate to be testuser1, got %s", result.Candidates[0].Username)
 		}
 	})
+	t.Run("LogsToInjectedLogger", func(t *testing.T) {
+		var logs bytes.Buffer
+		logged := &Client{
+			BaseURL: server.URL,
+			Token:   "test-token",
+			Logger:  slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug})),
+		}
+		if _, err := logged.SearchDevelopers(context.Background(), ToolInput{Language: "go"}); err != nil {
+			t.Fatalf("SearchDevelopers failed: %v", err)
+		}
+
+		if !strings.Contains(logs.String(), "Searching GitHub developers") {
+			t.Errorf("Expected search request logged, got %s", logs.String())
+		}
+		// Profile payloads must never reach the logs
+		if strings.Contains(logs.String(), "microservices experience") {
+			t.Errorf("Expected no profile data in logs, got %s", logs.String())
+		}
+	})
+	t.Run("StopsWhenCancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := client.SearchDevelopers(ctx, ToolInput{Language: "go"}); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the search cancelled, got %v", err)
+		}
+	})
 }
 
 func TestGetUserDetail(t *testing.T) {
@@ -161,7 +191,7 @@ func TestGetUserDetail(t *testing.T) {
 
 	t.Run("ValidUsername", func(t *testing.T) {
 		username := "testuser"
-		detail, err := client.GetUserDetail(username)
+		detail, err := client.GetUserDetail(context.Background(), username)
 		if err != nil {
 			t.Fatalf("GetUserDetail failed: %v", err)
 		}
@@ -174,3 +204,82 @@ func TestGetUserDetail(t *testing.T) {
 		}
 	})
 }
+
+func TestAPIErrorRateLimited(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/users/limited":
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+		case "/users/forbidden":
+			w.Header().Set("X-RateLimit-Remaining", "42")
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+		w.Write([]byte(`{"message": "nope"}`))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{BaseURL: mockServer.URL, Token: "test-token"}
+	tests := []struct {
+		username    string
+		status      int
+		rateLimited bool
+	}{
+		{"limited", http.StatusForbidden, true},
+		{"forbidden", http.StatusForbidden, false},
+		{"broken", http.StatusBadGateway, false},
+	}
+	for _, tt := range tests {
+		_, err := client.GetUserDetail(context.Background(), tt.username)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("Expected *APIError for %s, got %v", tt.username, err)
+		}
+		if apiErr.StatusCode != tt.status || apiErr.RateLimited() != tt.rateLimited {
+			t.Errorf("Expected status %d rate limited %v for %s, got %+v", tt.status, tt.rateLimited, tt.username, apiErr)
+		}
+	}
+}
+
+func TestGetRateLimit(t *testing.T) {
+	mockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rate_limit" {
+			t.Errorf("Expected /rate_limit, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") == "token bad-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message": "Bad credentials"}`))
+			return
+		}
+		w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+		w.Write([]byte(`{"resources": {"core": {"limit": 5000, "remaining": 4990, "reset": 1700000000},
+			"search": {"limit": 30, "remaining": 28, "reset": 1700000060}}}`))
+	}))
+	defer mockServer.Close()
+
+	client := &Client{BaseURL: mockServer.URL, Token: "test-token"}
+	limit, err := client.GetRateLimit()
+	if err != nil {
+		t.Fatalf("GetRateLimit failed: %v", err)
+	}
+	if limit.Limit != 5000 || limit.Remaining != 4990 || limit.Reset != 1700000000 {
+		t.Errorf("Expected the core rate limit, got %+v", limit)
+	}
+
+	limits, err := client.GetRateLimits()
+	if err != nil {
+		t.Fatalf("GetRateLimits failed: %v", err)
+	}
+	if limits.Search.Remaining != 28 || !limits.ClassicToken || len(limits.Scopes) != 2 || limits.Scopes[1] != "read:org" {
+		t.Errorf("Expected the search limit and token scopes, got %+v", limits)
+	}
+
+	client.Token = "bad-token"
+	_, err = client.GetRateLimit()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 APIError, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// APIError is a non-200 response from the GitHub API
+type APIError struct {
+	StatusCode int
+	Message    string // Response body
+	// RateLimitRemaining is the X-RateLimit-Remaining header, or -1 when absent
+	RateLimitRemaining int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitHub API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// RateLimited reports whether the request was rejected by a primary or
+// secondary rate limit rather than for being invalid
+func (e *APIError) RateLimited() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return e.RateLimitRemaining == 0
+	}
+	return false
+}
+
+// newAPIError builds the error for resp, whose body was read as body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	remaining := -1
+	if v, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		remaining = v
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: string(body), RateLimitRemaining: remaining}
+}
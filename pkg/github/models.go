@@ -33,14 +33,22 @@ type UserDetail struct {
 
 // Candidate represents a developer candidate
 type Candidate struct {
-	Username    string `json:"username"`
-	Name        string `json:"name"`
-	Location    string `json:"location"`
-	Bio         string `json:"bio"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	Company  string `json:"company,omitempty"`
+	Bio      string `json:"bio"`
+	Blog     string `json:"blog,omitempty"`
+	// Email is the public email, if any, used to resolve identities. It is
+	// never marshaled, so it reaches neither the model nor storage.
+	Email       string `json:"-"`
 	PublicRepos int    `json:"public_repos"`
 	Followers   int    `json:"followers"`
 	GitHubURL   string `json:"github_url"`
 	AvatarURL   string `json:"avatar_url"`
+	// Platform names the source the candidate was found on when a run
+	// sources from several, e.g. gitlab
+	Platform string `json:"platform,omitempty"`
 }
 
 // SearchResult represents the complete search result
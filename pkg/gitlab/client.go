@@ -0,0 +1,306 @@
+// Package gitlab sources developers from GitLab, on gitlab.com or a
+// self-managed instance, behind the same SourceProvider interface as GitHub.
+// GitLab has no developer search by language or location, so developers are
+// found through the projects written in a language, then filtered by their
+// profile's location.
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// DefaultBaseURL is the API of gitlab.com
+const DefaultBaseURL = "https://gitlab.com/api/v4"
+
+// maxProjectPages bounds the project pages SearchDevelopers reads for owners
+const maxProjectPages = 5
+
+// Client handles interactions with the GitLab REST API
+type Client struct {
+	BaseURL string
+	// Token is a personal access token with the read_api scope. Public data
+	// is readable without one, under a lower rate limit.
+	Token      string
+	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// NewClient creates a client of gitlab.com
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL: DefaultBaseURL,
+		Token:   token,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// httpClient returns the configured HTTP client, falling back to http.DefaultClient
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+// get decodes the JSON response to a GET of path into v, returning the
+// response headers
+func (c *Client) get(ctx context.Context, path string, v any) (http.Header, error) {
+	apiURL := strings.TrimRight(c.BaseURL, "/") + path
+	c.logger().Debug("GitLab request", "url", apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.Token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.Token)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp, body)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return nil, fmt.Errorf("failed to parse GitLab response: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// user is a GitLab user as listed, and with details when fetched by ID
+type user struct {
+	ID           int    `json:"id"`
+	Username     string `json:"username"`
+	Name         string `json:"name"`
+	WebURL       string `json:"web_url"`
+	AvatarURL    string `json:"avatar_url"`
+	Bio          string `json:"bio"`
+	Location     string `json:"location"`
+	Organization string `json:"organization"`
+	PublicEmail  string `json:"public_email"`
+	WebsiteURL   string `json:"website_url"`
+	Followers    int    `json:"followers"`
+	Following    int    `json:"following"`
+}
+
+// project is a GitLab project as listed
+type project struct {
+	ID             int      `json:"id"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	WebURL         string   `json:"web_url"`
+	StarCount      int      `json:"star_count"`
+	ForksCount     int      `json:"forks_count"`
+	Topics         []string `json:"topics"`
+	CreatedAt      string   `json:"created_at"`
+	LastActivityAt string   `json:"last_activity_at"`
+	Namespace      struct {
+		Kind string `json:"kind"` // "user" or "group"
+		Path string `json:"path"`
+	} `json:"namespace"`
+}
+
+// SearchDevelopers finds the owners of the most starred projects in
+// input.Language, matching input.Keywords if set, whose profiles are in
+// input.Location. MinRepos is checked against each profile's project count.
+func (c *Client) SearchDevelopers(ctx context.Context, input github.ToolInput) (*github.SearchResult, error) {
+	if input.MinRepos == 0 {
+		input.MinRepos = 5
+	}
+	if input.MaxResults == 0 {
+		input.MaxResults = 10
+	}
+
+	query := url.Values{}
+	query.Set("with_programming_language", input.Language)
+	query.Set("order_by", "star_count")
+	query.Set("per_page", "100")
+	if input.Keywords != "" {
+		query.Set("search", input.Keywords)
+	}
+
+	candidates := []github.Candidate{}
+	seen := make(map[string]bool)
+	for page := 1; page <= maxProjectPages && len(candidates) < input.MaxResults; page++ {
+		query.Set("page", strconv.Itoa(page))
+		var projects []project
+		if _, err := c.get(ctx, "/projects?"+query.Encode(), &projects); err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			c.logger().Warn("Failed to list GitLab projects", "page", page, "error", err)
+			break
+		}
+		for _, p := range projects {
+			username := p.Namespace.Path
+			if p.Namespace.Kind != "user" || seen[username] || len(candidates) >= input.MaxResults {
+				continue
+			}
+			seen[username] = true
+			detail, err := c.GetUserDetail(ctx, username)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				// Log error but continue with other users
+				c.logger().Warn("Failed to get user details", "username", username, "error", err)
+				continue
+			}
+			if input.Location != "" && !strings.Contains(strings.ToLower(detail.Location), strings.ToLower(input.Location)) {
+				continue
+			}
+			if detail.PublicRepos < input.MinRepos {
+				continue
+			}
+			candidates = append(candidates, github.Candidate{
+				Username:    detail.Login,
+				Name:        detail.Name,
+				Location:    detail.Location,
+				Company:     detail.Company,
+				Bio:         detail.Bio,
+				Blog:        detail.Blog,
+				Email:       detail.Email,
+				PublicRepos: detail.PublicRepos,
+				Followers:   detail.Followers,
+				GitHubURL:   detail.HTMLURL,
+				AvatarURL:   detail.AvatarURL,
+			})
+		}
+		if len(projects) < 100 {
+			break
+		}
+	}
+
+	return &github.SearchResult{
+		Candidates: candidates,
+		TotalFound: len(candidates),
+		SearchCriteria: map[string]interface{}{
+			"source":      "gitlab",
+			"language":    input.Language,
+			"location":    input.Location,
+			"keywords":    input.Keywords,
+			"min_repos":   input.MinRepos,
+			"max_results": input.MaxResults,
+		},
+	}, nil
+}
+
+// GetUserDetail retrieves the profile of a GitLab user, with the number of
+// their projects as PublicRepos
+func (c *Client) GetUserDetail(ctx context.Context, username string) (*github.UserDetail, error) {
+	var users []user
+	if _, err := c.get(ctx, "/users?username="+url.QueryEscape(username), &users); err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, &APIError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("user %s not found", username)}
+	}
+	var u user
+	if _, err := c.get(ctx, fmt.Sprintf("/users/%d", users[0].ID), &u); err != nil {
+		return nil, err
+	}
+	// Only the X-Total header of a one-item page counts the projects
+	var projects []project
+	header, err := c.get(ctx, fmt.Sprintf("/users/%d/projects?per_page=1", u.ID), &projects)
+	if err != nil {
+		return nil, err
+	}
+	publicRepos, _ := strconv.Atoi(header.Get("X-Total"))
+	return &github.UserDetail{
+		Login:       u.Username,
+		Name:        u.Name,
+		Company:     u.Organization,
+		Blog:        u.WebsiteURL,
+		Location:    u.Location,
+		Email:       u.PublicEmail,
+		Bio:         u.Bio,
+		PublicRepos: publicRepos,
+		Followers:   u.Followers,
+		Following:   u.Following,
+		HTMLURL:     u.WebURL,
+		AvatarURL:   u.AvatarURL,
+	}, nil
+}
+
+// GetRepositories retrieves a user's most starred projects, each with its
+// main language
+func (c *Client) GetRepositories(ctx context.Context, username string, maxRepos int) ([]github.Repository, error) {
+	var projects []project
+	path := fmt.Sprintf("/users/%s/projects?order_by=star_count&sort=desc&per_page=%d", url.PathEscape(username), maxRepos)
+	if _, err := c.get(ctx, path, &projects); err != nil {
+		return nil, err
+	}
+	repos := make([]github.Repository, 0, len(projects))
+	for _, p := range projects {
+		language, err := c.mainLanguage(ctx, p.ID)
+		if err != nil {
+			// The repository still counts, without its language
+			c.logger().Warn("Failed to get project languages", "project", p.WebURL, "error", err)
+		}
+		repos = append(repos, github.Repository{
+			Name:        p.Name,
+			Description: p.Description,
+			Language:    language,
+			Stars:       p.StarCount,
+			Forks:       p.ForksCount,
+			Topics:      p.Topics,
+			URL:         p.WebURL,
+			CreatedAt:   p.CreatedAt,
+			UpdatedAt:   p.LastActivityAt,
+		})
+	}
+	return repos, nil
+}
+
+// mainLanguage returns the language most of a project is written in
+func (c *Client) mainLanguage(ctx context.Context, projectID int) (string, error) {
+	var shares map[string]float64
+	if _, err := c.get(ctx, fmt.Sprintf("/projects/%d/languages", projectID), &shares); err != nil {
+		return "", err
+	}
+	languages := make([]string, 0, len(shares))
+	for language := range shares {
+		languages = append(languages, language)
+	}
+	// Ties go to the alphabetically first language, for stable results
+	sort.Slice(languages, func(i, j int) bool {
+		if shares[languages[i]] != shares[languages[j]] {
+			return shares[languages[i]] > shares[languages[j]]
+		}
+		return languages[i] < languages[j]
+	})
+	if len(languages) == 0 {
+		return "", nil
+	}
+	return languages[0], nil
+}
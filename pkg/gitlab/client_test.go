@@ -0,0 +1,133 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// newTestServer serves two users: ana in Lima with 12 projects, and bob in
+// Berlin with 8, each owning a Go project, next to a group's project
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	users := map[string]user{
+		"ana": {ID: 1, Username: "ana", Name: "Ana Quispe", Location: "Lima, Peru", Organization: "Acme", WebURL: "https://gitlab.com/ana"},
+		"bob": {ID: 2, Username: "bob", Location: "Berlin", WebURL: "https://gitlab.com/bob"},
+	}
+	mux := http.NewServeMux()
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(v)
+	}
+	mux.HandleFunc("GET /projects", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "test-token" {
+			t.Errorf("Expected the PRIVATE-TOKEN header, got %q", r.Header.Get("PRIVATE-TOKEN"))
+		}
+		if got := r.URL.Query().Get("with_programming_language"); got != "go" {
+			t.Errorf("Expected projects in go, got %q", got)
+		}
+		projects := []map[string]any{
+			{"id": 10, "namespace": map[string]string{"kind": "group", "path": "acme"}},
+			{"id": 11, "namespace": map[string]string{"kind": "user", "path": "bob"}},
+			{"id": 12, "namespace": map[string]string{"kind": "user", "path": "ana"}},
+			{"id": 13, "namespace": map[string]string{"kind": "user", "path": "ana"}},
+		}
+		writeJSON(w, projects)
+	})
+	mux.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := users[r.URL.Query().Get("username")]
+		if !ok {
+			writeJSON(w, []user{})
+			return
+		}
+		writeJSON(w, []user{{ID: u.ID, Username: u.Username}})
+	})
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		for _, u := range users {
+			if r.PathValue("id") == strconv.Itoa(u.ID) {
+				writeJSON(w, u)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("GET /users/{user}/projects", func(w http.ResponseWriter, r *http.Request) {
+		total := map[string]string{"1": "12", "2": "8", "ana": "12"}[r.PathValue("user")]
+		w.Header().Set("X-Total", total)
+		writeJSON(w, []map[string]any{
+			{"id": 12, "name": "gateway", "web_url": "https://gitlab.com/ana/gateway", "star_count": 40, "forks_count": 3,
+				"topics": []string{"grpc"}, "created_at": "2022-01-02T00:00:00Z", "last_activity_at": "2024-05-06T00:00:00Z"},
+		})
+	})
+	mux.HandleFunc("GET /projects/{id}/languages", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]float64{"Go": 81.5, "Shell": 18.5})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(server *httptest.Server) *Client {
+	c := NewClient("test-token")
+	c.BaseURL = server.URL
+	return c
+}
+
+func TestSearchDevelopers(t *testing.T) {
+	c := newTestClient(newTestServer(t))
+	result, err := c.SearchDevelopers(context.Background(), github.ToolInput{Language: "go", Location: "lima", MinRepos: 10})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The group is skipped, bob is in Berlin, and ana is listed once
+	if len(result.Candidates) != 1 {
+		t.Fatalf("Expected one candidate, got %+v", result.Candidates)
+	}
+	got := result.Candidates[0]
+	if got.Username != "ana" || got.Company != "Acme" || got.PublicRepos != 12 || got.GitHubURL != "https://gitlab.com/ana" {
+		t.Errorf("Unexpected candidate %+v", got)
+	}
+	if result.SearchCriteria["source"] != "gitlab" {
+		t.Errorf("Expected the source in the criteria, got %v", result.SearchCriteria)
+	}
+}
+
+func TestGetRepositories(t *testing.T) {
+	c := newTestClient(newTestServer(t))
+	repos, err := c.GetRepositories(context.Background(), "ana", 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(repos) != 1 {
+		t.Fatalf("Expected one repository, got %+v", repos)
+	}
+	r := repos[0]
+	if r.Name != "gateway" || r.Language != "Go" || r.Stars != 40 || r.Forks != 3 || r.UpdatedAt != "2024-05-06T00:00:00Z" {
+		t.Errorf("Unexpected repository %+v", r)
+	}
+}
+
+func TestGetUserDetailErrors(t *testing.T) {
+	c := newTestClient(newTestServer(t))
+	_, err := c.GetUserDetail(context.Background(), "nobody")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected a not found APIError, got %v", err)
+	}
+
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("RateLimit-Remaining", "0")
+		http.Error(w, "Retry later", http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+	_, err = newTestClient(limited).GetUserDetail(context.Background(), "ana")
+	if !errors.As(err, &apiErr) || !apiErr.RateLimited() {
+		t.Errorf("Expected a rate limited APIError, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package gitlab
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// APIError is a non-200 response from the GitLab API
+type APIError struct {
+	StatusCode int
+	Message    string // Response body
+	// RateLimitRemaining is the RateLimit-Remaining header, or -1 when absent
+	RateLimitRemaining int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("GitLab API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// RateLimited reports whether the request was rejected by a rate limit
+// rather than for being invalid
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || (e.StatusCode == http.StatusForbidden && e.RateLimitRemaining == 0)
+}
+
+// newAPIError builds the error for resp, whose body was read as body
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	remaining := -1
+	if v, err := strconv.Atoi(resp.Header.Get("RateLimit-Remaining")); err == nil {
+		remaining = v
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: string(body), RateLimitRemaining: remaining}
+}
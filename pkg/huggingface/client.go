@@ -0,0 +1,112 @@
+// Package huggingface looks candidates up on the Hugging Face Hub, adding the
+// models and datasets they published to the ranking of machine learning
+// roles.
+package huggingface
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Hugging Face Hub
+const DefaultBaseURL = "https://huggingface.co"
+
+// Client handles interactions with the Hugging Face Hub API
+type Client struct {
+	BaseURL string
+	// Token is an access token, which raises the rate limit. Optional.
+	Token      string
+	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// NewClient creates a Hugging Face Hub client with the optional token
+func NewClient(token string) *Client {
+	return &Client{
+		BaseURL: DefaultBaseURL,
+		Token:   token,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// httpClient returns the configured HTTP client, falling back to http.DefaultClient
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+// Repo is a model or dataset on the Hub
+type Repo struct {
+	ID        string `json:"id"` // e.g. ana/bert-quechua
+	Downloads int    `json:"downloads"`
+	Likes     int    `json:"likes"`
+}
+
+// ListModels returns up to limit of author's models, most downloaded first
+func (c *Client) ListModels(ctx context.Context, author string, limit int) ([]Repo, error) {
+	return c.list(ctx, "/api/models", author, limit)
+}
+
+// ListDatasets returns up to limit of author's datasets, most downloaded first
+func (c *Client) ListDatasets(ctx context.Context, author string, limit int) ([]Repo, error) {
+	return c.list(ctx, "/api/datasets", author, limit)
+}
+
+func (c *Client) list(ctx context.Context, path, author string, limit int) ([]Repo, error) {
+	query := url.Values{
+		"author":    {author},
+		"sort":      {"downloads"},
+		"direction": {"-1"},
+		"limit":     {strconv.Itoa(limit)},
+	}
+	apiURL := strings.TrimRight(c.BaseURL, "/") + path + "?" + query.Encode()
+	c.logger().Debug("Hugging Face request", "url", apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp.StatusCode, body)
+	}
+
+	var repos []Repo
+	if err := json.Unmarshal(body, &repos); err != nil {
+		return nil, fmt.Errorf("failed to parse Hugging Face response: %w", err)
+	}
+	return repos, nil
+}
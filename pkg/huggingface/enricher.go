@@ -0,0 +1,93 @@
+package huggingface
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Platform names Hugging Face profiles in agent.ExternalProfile, and the
+// enricher in ENRICHERS
+const Platform = "huggingface"
+
+const (
+	reposPerLookup = 100 // Models or datasets counted per candidate
+	highlights     = 3   // Most downloaded models named
+)
+
+// profileLinks find the users of Hub links, skipping the Hub pages that
+// are not users
+var profileLinks = agent.ProfileLinks{
+	Pattern:  regexp.MustCompile(`(?i)(?:huggingface\.co|hf\.co)/([\w./-]+)`),
+	Sections: []string{"datasets", "spaces"},
+	Reserved: []string{
+		"api", "blog", "collections", "docs", "join", "learn", "login", "models",
+		"organizations", "papers", "posts", "pricing", "settings", "tasks",
+	},
+}
+
+// Enricher adds the models and datasets a candidate published on the Hub,
+// for machine learning roles. Only a profile linked from the candidate's
+// blog or bio is looked up: Hub profiles rarely carry a name or location to
+// score a match by name on.
+type Enricher struct {
+	Client *Client
+}
+
+var _ agent.ConditionalEnricher = (*Enricher)(nil)
+
+// Name returns Platform
+func (e *Enricher) Name() string { return Platform }
+
+// AppliesTo reports whether requirements are for a machine learning role
+func (e *Enricher) AppliesTo(requirements *agent.Requirements) bool {
+	return agent.IsMLRole(requirements)
+}
+
+// Enrich adds the Hub profile linked from the candidate's blog or bio, if
+// they published any models or datasets
+func (e *Enricher) Enrich(ctx context.Context, candidate *agent.EnrichedCandidate) error {
+	for _, p := range candidate.ExternalProfiles {
+		if p.Platform == Platform {
+			return nil
+		}
+	}
+	user, ok := profileLinks.User(candidate.Blog, candidate.Bio)
+	if !ok {
+		return nil
+	}
+
+	models, err := e.Client.ListModels(ctx, user, reposPerLookup)
+	if err != nil {
+		return err
+	}
+	datasets, err := e.Client.ListDatasets(ctx, user, reposPerLookup)
+	if err != nil {
+		return err
+	}
+	if len(models) == 0 && len(datasets) == 0 {
+		return nil
+	}
+
+	// Models first, each list most downloaded first
+	work := append(models, datasets...)
+	metrics := map[string]int{agent.MetricModels: len(models), agent.MetricDatasets: len(datasets)}
+	for _, r := range work {
+		metrics[agent.MetricDownloads] += r.Downloads
+		metrics[agent.MetricLikes] += r.Likes
+	}
+	profile := agent.ExternalProfile{
+		Platform:   Platform,
+		URL:        strings.TrimRight(e.Client.BaseURL, "/") + "/" + user,
+		Confidence: 1,
+		MatchedBy:  agent.MatchedByLink,
+		Metrics:    metrics,
+	}
+	for _, r := range work[:min(highlights, len(work))] {
+		profile.Highlights = append(profile.Highlights, r.ID)
+	}
+	candidate.ExternalProfiles = append(candidate.ExternalProfiles, profile)
+	return nil
+}
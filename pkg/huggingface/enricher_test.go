@@ -0,0 +1,100 @@
+package huggingface
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// newTestServer serves the models and dataset of ana, and rate limits bob
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	repos := map[string][]Repo{
+		"/api/models":   {{ID: "ana/bert-quechua", Downloads: 12000, Likes: 40}, {ID: "ana/whisper-aymara", Downloads: 300, Likes: 5}},
+		"/api/datasets": {{ID: "ana/quechua-corpus", Downloads: 800, Likes: 12}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Header.Get("Authorization") != "Bearer hf-token" || q.Get("sort") != "downloads" || q.Get("direction") != "-1" {
+			t.Errorf("Unexpected request %s %v", r.URL, r.Header)
+		}
+		switch q.Get("author") {
+		case "ana":
+			json.NewEncoder(w).Encode(repos[r.URL.Path])
+		case "bob":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error": "Rate limit reached"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestEnricher(server *httptest.Server) *Enricher {
+	c := NewClient("hf-token")
+	c.BaseURL = server.URL
+	return &Enricher{Client: c}
+}
+
+func TestEnrich(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	candidate := &agent.EnrichedCandidate{Username: "ana-q", Bio: "NLP for Andean languages. huggingface.co/ana"}
+	if err := e.Enrich(context.Background(), candidate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(candidate.ExternalProfiles) != 1 {
+		t.Fatalf("Expected one profile, got %+v", candidate.ExternalProfiles)
+	}
+	got := candidate.ExternalProfiles[0]
+	if got.Platform != Platform || got.URL != e.Client.BaseURL+"/ana" || got.Confidence != 1 || got.MatchedBy != agent.MatchedByLink {
+		t.Errorf("Unexpected profile %+v", got)
+	}
+	want := map[string]int{agent.MetricModels: 2, agent.MetricDatasets: 1, agent.MetricDownloads: 13100, agent.MetricLikes: 57}
+	for metric, n := range want {
+		if got.Metrics[metric] != n {
+			t.Errorf("Expected %s %d, got %d", metric, n, got.Metrics[metric])
+		}
+	}
+	if len(got.Highlights) != 3 || got.Highlights[0] != "ana/bert-quechua" || got.Highlights[2] != "ana/quechua-corpus" {
+		t.Errorf("Unexpected highlights %v", got.Highlights)
+	}
+}
+
+func TestEnrichWithoutWork(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	for _, bio := range []string{"No links here", "https://huggingface.co/docs/transformers", "huggingface.co/carla"} {
+		candidate := &agent.EnrichedCandidate{Bio: bio}
+		if err := e.Enrich(context.Background(), candidate); err != nil || len(candidate.ExternalProfiles) != 0 {
+			t.Errorf("Expected no profile for %q, got %+v, %v", bio, candidate.ExternalProfiles, err)
+		}
+	}
+
+	err := e.Enrich(context.Background(), &agent.EnrichedCandidate{Blog: "https://hf.co/bob"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.RateLimited() || apiErr.Message != "Rate limit reached" {
+		t.Errorf("Expected a rate-limited APIError, got %v", err)
+	}
+}
+
+func TestProfileLinks(t *testing.T) {
+	tests := []struct{ text, want string }{
+		{"https://huggingface.co/ana", "ana"},
+		{"See https://huggingface.co/ana/bert-quechua.", "ana"},
+		{"https://huggingface.co/datasets/ana/quechua-corpus", "ana"},
+		{"https://huggingface.co/spaces/ana/demo", "ana"},
+		{"https://huggingface.co/papers/2401.00001 and hf.co/bob", "bob"},
+		{"https://huggingface.co/models", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := profileLinks.User(tt.text); got != tt.want {
+			t.Errorf("Expected %q linked from %q, got %q", tt.want, tt.text, got)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+// Package jobs runs searches in the background for the server: a bounded
+// pool of workers takes jobs from a queue, tracks their state and per-stage
+// progress from the pipeline's events, and cancels them on request.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// State is the lifecycle state of a job
+type State string
+
+// Job states. A job is queued, then running, then in one of the final states.
+const (
+	Queued    State = "queued"
+	Running   State = "running"
+	Completed State = "completed"
+	Failed    State = "failed"
+	Cancelled State = "cancelled"
+)
+
+// Final reports whether a job in state s is finished
+func (s State) Final() bool {
+	return s == Completed || s == Failed || s == Cancelled
+}
+
+var (
+	// ErrQueueFull is returned by Submit when the queue holds its capacity
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrNotFound is returned for an unknown or no longer retained job ID
+	ErrNotFound = errors.New("job not found")
+	// ErrFinished is returned by Cancel for a job that already finished
+	ErrFinished = errors.New("job already finished")
+)
+
+// Job is a snapshot of a search job
+type Job struct {
+	ID         string             `json:"id"`
+	Query      string             `json:"query"`
+	State      State              `json:"state"`
+	Stages     []StageProgress    `json:"stages,omitempty"`
+	Enriched   int                `json:"candidates_enriched,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+	StartedAt  *time.Time         `json:"started_at,omitempty"`
+	FinishedAt *time.Time         `json:"finished_at,omitempty"`
+	Error      string             `json:"error,omitempty"`
+	ErrorClass string             `json:"error_class,omitempty"`
+	Result     *agent.FinalResult `json:"result,omitempty"`
+}
+
+// Event is a pipeline event of a job with the time it was published
+type Event struct {
+	Time  time.Time
+	Event observability.Event
+}
+
+// queuedJob is a job with the state its snapshots leave out
+type queuedJob struct {
+	Job
+	run       RunFunc            // Cleared once started
+	cancel    context.CancelFunc // Set while running
+	cancelled bool               // Cancel was called
+	events    []Event
+	changed   chan struct{} // Closed and replaced on every event and once final
+	done      chan struct{} // Closed once the job is final
+}
+
+// StageProgress is the progress of one pipeline stage of a job
+type StageProgress struct {
+	Stage      string `json:"stage"`
+	State      State  `json:"state"`       // Running, Completed or Failed
+	DurationMS int64  `json:"duration_ms"` // So far while running
+
+	started time.Time
+}
+
+// RunFunc runs the search of a job, publishing its pipeline events to
+// progress
+type RunFunc func(ctx context.Context, progress observability.Subscriber) (*agent.FinalResult, error)
+
+// Queue runs submitted jobs on a fixed number of workers. Jobs share
+// whatever clients their RunFunc uses, so client-side rate limits and the
+// GitHub token's budget apply across all of them.
+type Queue struct {
+	pending chan *queuedJob
+	retain  int
+	now     func() time.Time
+
+	mu    sync.Mutex
+	jobs  map[string]*queuedJob
+	order []string // Job IDs, oldest first
+}
+
+// NewQueue starts workers running jobs until ctx is done, which also
+// cancels the running ones. At most capacity jobs wait for a worker; the
+// last retain finished jobs stay available for Get and List.
+func NewQueue(ctx context.Context, workers, capacity, retain int) *Queue {
+	q := &Queue{
+		pending: make(chan *queuedJob, capacity),
+		retain:  retain,
+		now:     time.Now,
+		jobs:    make(map[string]*queuedJob),
+	}
+	for range max(1, workers) {
+		go q.work(ctx)
+	}
+	return q
+}
+
+// Submit queues a job running run, identified by id, e.g. the run ID of
+// its search
+func (q *Queue) Submit(id, query string, run RunFunc) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.jobs[id]; ok {
+		return Job{}, fmt.Errorf("duplicate job ID %s", id)
+	}
+	job := &queuedJob{
+		Job:     Job{ID: id, Query: query, State: Queued, CreatedAt: q.now().UTC()},
+		run:     run,
+		changed: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	select {
+	case q.pending <- job:
+	default:
+		return Job{}, ErrQueueFull
+	}
+	q.jobs[id] = job
+	q.order = append(q.order, id)
+	q.prune()
+	return job.snapshot(true), nil
+}
+
+// Get returns the job with id, including its result once completed
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return job.snapshot(true), nil
+}
+
+// List returns the retained jobs, oldest first, without their results
+func (q *Queue) List() []Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]Job, 0, len(q.order))
+	for _, id := range q.order {
+		jobs = append(jobs, q.jobs[id].snapshot(false))
+	}
+	return jobs
+}
+
+// Cancel cancels the job with id. A queued job is cancelled at once; a
+// running one once its search returns.
+func (q *Queue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if job.State.Final() {
+		return job.snapshot(true), ErrFinished
+	}
+	job.cancelled = true
+	if job.State == Queued {
+		q.finish(job, Cancelled, nil, context.Canceled)
+	} else if job.cancel != nil {
+		job.cancel()
+	}
+	return job.snapshot(true), nil
+}
+
+// Wait blocks until the job with id is finished or ctx is done
+func (q *Queue) Wait(ctx context.Context, id string) (Job, error) {
+	q.mu.Lock()
+	job, ok := q.jobs[id]
+	q.mu.Unlock()
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	select {
+	case <-job.done:
+		return q.Get(id)
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+}
+
+// Watch calls fn with the pipeline events of the job with id, from its
+// first, as they are published until the job finishes, and returns the
+// finished job. It stops early when ctx is done or fn fails.
+func (q *Queue) Watch(ctx context.Context, id string, fn func(Event) error) (Job, error) {
+	next := 0
+	for {
+		q.mu.Lock()
+		job, ok := q.jobs[id]
+		if !ok {
+			q.mu.Unlock()
+			return Job{}, ErrNotFound
+		}
+		events := slices.Clone(job.events[next:])
+		final, changed, snapshot := job.State.Final(), job.changed, job.snapshot(true)
+		q.mu.Unlock()
+
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return Job{}, err
+			}
+		}
+		next += len(events)
+		if final {
+			return snapshot, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return Job{}, ctx.Err()
+		}
+	}
+}
+
+// MetricJobs is the gauge of retained jobs by state written by RecordMetrics
+const MetricJobs = "sourcing_jobs"
+
+// RecordMetrics emits the number of retained jobs in each state into metrics
+func (q *Queue) RecordMetrics(metrics observability.Metrics) {
+	q.mu.Lock()
+	counts := make(map[State]int)
+	for _, job := range q.jobs {
+		counts[job.State]++
+	}
+	q.mu.Unlock()
+	for _, state := range []State{Queued, Running, Completed, Failed, Cancelled} {
+		metrics.Gauge(MetricJobs, float64(counts[state]), observability.Labels{"state": string(state)})
+	}
+}
+
+// work runs queued jobs one at a time until ctx is done
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-q.pending:
+			q.runJob(ctx, job)
+		}
+	}
+}
+
+// runJob runs job unless it was cancelled while queued
+func (q *Queue) runJob(ctx context.Context, job *queuedJob) {
+	q.mu.Lock()
+	if job.State != Queued {
+		q.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	started := q.now().UTC()
+	job.State, job.StartedAt, job.cancel = Running, &started, cancel
+	run := job.run
+	job.run = nil
+	q.mu.Unlock()
+
+	result, err := run(ctx, func(event observability.Event) { q.record(job, event) })
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	state := Completed
+	switch {
+	case err != nil && (job.cancelled || ctx.Err() != nil):
+		state = Cancelled
+	case err != nil:
+		state = Failed
+	}
+	q.finish(job, state, result, err)
+}
+
+// record updates the progress of job from one of its pipeline events
+func (q *Queue) record(job *queuedJob, event observability.Event) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	job.events = append(job.events, Event{Time: now.UTC(), Event: event})
+	defer job.notify()
+	switch e := event.(type) {
+	case observability.StageStarted:
+		job.endStage(Completed, now)
+		job.Stages = append(job.Stages, StageProgress{Stage: e.Stage, State: Running, started: now})
+	case observability.StageFailed:
+		for i := range job.Stages {
+			if s := &job.Stages[i]; s.Stage == e.Stage && s.State == Running {
+				s.State, s.DurationMS = Failed, now.Sub(s.started).Milliseconds()
+			}
+		}
+	case observability.CandidateEnriched:
+		if e.Err == nil {
+			job.Enriched++
+		}
+	}
+}
+
+// endStage ends the running stage of job, if any, in state
+func (job *queuedJob) endStage(state State, now time.Time) {
+	if n := len(job.Stages); n > 0 && job.Stages[n-1].State == Running {
+		s := &job.Stages[n-1]
+		s.State, s.DurationMS = state, now.Sub(s.started).Milliseconds()
+	}
+}
+
+// finish moves job to a final state. The caller holds q.mu.
+func (q *Queue) finish(job *queuedJob, state State, result *agent.FinalResult, err error) {
+	finished := q.now().UTC()
+	stageState := Completed
+	if state != Completed {
+		stageState = state
+	}
+	job.endStage(stageState, finished)
+	job.State, job.FinishedAt, job.Result, job.cancel, job.run = state, &finished, result, nil, nil
+	if err != nil {
+		job.Error = err.Error()
+		job.ErrorClass = string(observability.ClassifyError(err))
+	}
+	close(job.done)
+	job.notify()
+	q.prune()
+}
+
+// notify wakes the watchers of job. The caller holds q.mu.
+func (job *queuedJob) notify() {
+	close(job.changed)
+	job.changed = make(chan struct{})
+}
+
+// prune drops the oldest finished jobs beyond the retained number. The
+// caller holds q.mu.
+func (q *Queue) prune() {
+	finished := 0
+	for _, id := range q.order {
+		if q.jobs[id].State.Final() {
+			finished++
+		}
+	}
+	kept := q.order[:0]
+	for _, id := range q.order {
+		if finished > q.retain && q.jobs[id].State.Final() {
+			delete(q.jobs, id)
+			finished--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	q.order = kept
+}
+
+// snapshot returns a copy of job safe to hand out, with its result if
+// withResult
+func (job *queuedJob) snapshot(withResult bool) Job {
+	s := job.Job
+	s.Stages = slices.Clone(job.Stages)
+	if n := len(s.Stages); n > 0 && s.Stages[n-1].State == Running {
+		s.Stages[n-1].DurationMS = time.Since(s.Stages[n-1].started).Milliseconds()
+	}
+	if !withResult {
+		s.Result = nil
+	}
+	return s
+}
@@ -0,0 +1,196 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/observability"
+)
+
+// blockingRun returns a run publishing a stage and an enriched candidate,
+// then blocking until release is closed or its context is done
+func blockingRun(release chan struct{}) RunFunc {
+	return func(ctx context.Context, progress observability.Subscriber) (*agent.FinalResult, error) {
+		progress(observability.StageStarted{Stage: "search"})
+		progress(observability.CandidateEnriched{Username: "alice"})
+		select {
+		case <-release:
+			return &agent.FinalResult{TopCandidates: []agent.RankedCandidate{{Rank: 1, Username: "alice"}}}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// waitFor polls until the job with id is in state
+func waitFor(t *testing.T, q *Queue, id string, state State) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := q.Get(id)
+		if err == nil && job.State == state {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job %s to be %s, got %+v (%v)", id, state, job, err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueueRunsJobs(t *testing.T) {
+	q := NewQueue(t.Context(), 1, 10, 10)
+	release := make(chan struct{})
+	job, err := q.Submit("run-1", "Find Go developers", blockingRun(release))
+	if err != nil {
+		t.Fatalf("Expected the job to be queued, got %v", err)
+	}
+	if job.ID != "run-1" || job.Query != "Find Go developers" {
+		t.Errorf("Expected the submitted job, got %+v", job)
+	}
+
+	job = waitFor(t, q, "run-1", Running)
+	if job.Enriched != 1 || len(job.Stages) != 1 || job.Stages[0].Stage != "search" || job.Stages[0].State != Running {
+		t.Errorf("Expected the search stage running with 1 candidate enriched, got %+v", job)
+	}
+	if job.StartedAt == nil || job.FinishedAt != nil {
+		t.Errorf("Expected a start but no finish time, got %+v", job)
+	}
+
+	close(release)
+	job, err = q.Wait(t.Context(), "run-1")
+	if err != nil {
+		t.Fatalf("Expected to wait for the job, got %v", err)
+	}
+	if job.State != Completed || job.Result == nil || job.Stages[0].State != Completed || job.FinishedAt == nil {
+		t.Errorf("Expected a completed job with its result, got %+v", job)
+	}
+	if list := q.List(); len(list) != 1 || list[0].Result != nil {
+		t.Errorf("Expected the job listed without its result, got %+v", list)
+	}
+}
+
+func TestQueueFailedJob(t *testing.T) {
+	q := NewQueue(t.Context(), 1, 10, 10)
+	q.Submit("run-1", "query", func(ctx context.Context, progress observability.Subscriber) (*agent.FinalResult, error) {
+		progress(observability.StageStarted{Stage: "rank"})
+		err := errors.New("model unavailable")
+		progress(observability.StageFailed{Stage: "rank", Err: err})
+		return nil, err
+	})
+	job, _ := q.Wait(t.Context(), "run-1")
+	if job.State != Failed || job.Error != "model unavailable" || job.ErrorClass == "" {
+		t.Errorf("Expected a failed job with its classified error, got %+v", job)
+	}
+	if len(job.Stages) != 1 || job.Stages[0].State != Failed {
+		t.Errorf("Expected the rank stage failed, got %+v", job.Stages)
+	}
+}
+
+func TestQueueBoundsConcurrency(t *testing.T) {
+	q := NewQueue(t.Context(), 1, 1, 10)
+	release := make(chan struct{})
+	defer close(release)
+	q.Submit("run-1", "first", blockingRun(release))
+	waitFor(t, q, "run-1", Running)
+
+	if _, err := q.Submit("run-2", "second", blockingRun(release)); err != nil {
+		t.Fatalf("Expected the second job to be queued, got %v", err)
+	}
+	if _, err := q.Submit("run-3", "third", blockingRun(release)); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if job, _ := q.Get("run-2"); job.State != Queued {
+		t.Errorf("Expected the second job to wait for the worker, got %s", job.State)
+	}
+	if _, err := q.Submit("run-1", "again", blockingRun(release)); err == nil {
+		t.Error("Expected a duplicate job ID to be rejected")
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	q := NewQueue(t.Context(), 1, 10, 10)
+	release := make(chan struct{})
+	defer close(release)
+	q.Submit("running", "first", blockingRun(release))
+	q.Submit("queued", "second", blockingRun(release))
+	waitFor(t, q, "running", Running)
+
+	if job, err := q.Cancel("queued"); err != nil || job.State != Cancelled {
+		t.Errorf("Expected the queued job cancelled at once, got %+v (%v)", job, err)
+	}
+	if _, err := q.Cancel("running"); err != nil {
+		t.Fatalf("Expected to cancel the running job, got %v", err)
+	}
+	job := waitFor(t, q, "running", Cancelled)
+	if job.Stages[0].State != Cancelled || job.ErrorClass != string(observability.ErrorClassCancelled) {
+		t.Errorf("Expected the running stage cancelled, got %+v", job)
+	}
+	if _, err := q.Cancel("running"); !errors.Is(err, ErrFinished) {
+		t.Errorf("Expected ErrFinished, got %v", err)
+	}
+	if _, err := q.Cancel("unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueueRetainsFinishedJobs(t *testing.T) {
+	q := NewQueue(t.Context(), 1, 10, 2)
+	done := func(ctx context.Context, progress observability.Subscriber) (*agent.FinalResult, error) {
+		return &agent.FinalResult{}, nil
+	}
+	for _, id := range []string{"run-1", "run-2", "run-3"} {
+		q.Submit(id, "query", done)
+		q.Wait(t.Context(), id)
+	}
+	if _, err := q.Get("run-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected the oldest job to be dropped, got %v", err)
+	}
+	if list := q.List(); len(list) != 2 || list[0].ID != "run-2" {
+		t.Errorf("Expected run-2 and run-3, got %+v", list)
+	}
+}
+
+func TestQueueWatch(t *testing.T) {
+	q := NewQueue(t.Context(), 1, 10, 10)
+	release := make(chan struct{})
+	q.Submit("run-1", "query", blockingRun(release))
+	waitFor(t, q, "run-1", Running)
+
+	// A watcher joining late sees the earlier events, then the later ones
+	var names []string
+	watched := make(chan Job)
+	go func() {
+		job, err := q.Watch(t.Context(), "run-1", func(e Event) error {
+			names = append(names, e.Event.EventName())
+			if len(names) == 2 {
+				close(release)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Errorf("Expected to watch the job, got %v", err)
+		}
+		watched <- job
+	}()
+	job := <-watched
+	if job.State != Completed || job.Result == nil {
+		t.Errorf("Expected the completed job, got %+v", job)
+	}
+	if len(names) != 2 || names[0] != "stage_started" || names[1] != "candidate_enriched" {
+		t.Errorf("Expected the stage and candidate events, got %v", names)
+	}
+
+	// Watching a finished job replays its events
+	stop := errors.New("stop")
+	if _, err := q.Watch(t.Context(), "run-1", func(Event) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Expected the watch to stop with fn's error, got %v", err)
+	}
+	if _, err := q.Watch(t.Context(), "unknown", func(Event) error { return nil }); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,115 @@
+// Package kaggle looks candidates up on Kaggle, adding the notebooks and
+// datasets they published to the ranking of machine learning roles.
+package kaggle
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Kaggle public API
+const DefaultBaseURL = "https://www.kaggle.com/api/v1"
+
+// Client handles interactions with the Kaggle public API, which requires an
+// account's username and API key
+type Client struct {
+	BaseURL    string
+	Username   string
+	Key        string
+	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// NewClient creates a Kaggle API client authenticating as username with key
+func NewClient(username, key string) *Client {
+	return &Client{
+		BaseURL:  DefaultBaseURL,
+		Username: username,
+		Key:      key,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// httpClient returns the configured HTTP client, falling back to http.DefaultClient
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+// Notebook is a public notebook, called kernel by the API
+type Notebook struct {
+	Ref        string `json:"ref"` // e.g. ana/quechua-asr-baseline
+	Title      string `json:"title"`
+	TotalVotes int    `json:"totalVotes"`
+}
+
+// Dataset is a public dataset
+type Dataset struct {
+	Ref           string `json:"ref"` // e.g. ana/quechua-corpus
+	Title         string `json:"title"`
+	VoteCount     int    `json:"voteCount"`
+	DownloadCount int    `json:"downloadCount"`
+}
+
+// ListNotebooks returns the first page of user's public notebooks, most voted first
+func (c *Client) ListNotebooks(ctx context.Context, user string) ([]Notebook, error) {
+	var notebooks []Notebook
+	err := c.get(ctx, "/kernels/list", url.Values{"user": {user}, "sortBy": {"voteCount"}, "pageSize": {"100"}}, &notebooks)
+	return notebooks, err
+}
+
+// ListDatasets returns the first page of user's public datasets, most voted first
+func (c *Client) ListDatasets(ctx context.Context, user string) ([]Dataset, error) {
+	var datasets []Dataset
+	err := c.get(ctx, "/datasets/list", url.Values{"user": {user}, "sortBy": {"votes"}}, &datasets)
+	return datasets, err
+}
+
+// get decodes the response to a GET of path with query into v
+func (c *Client) get(ctx context.Context, path string, query url.Values, v any) error {
+	apiURL := strings.TrimRight(c.BaseURL, "/") + path + "?" + query.Encode()
+	c.logger().Debug("Kaggle request", "url", apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(c.Username, c.Key)
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp.StatusCode, body)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to parse Kaggle response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package kaggle
+
+import (
+	"context"
+	"regexp"
+	"slices"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Platform names Kaggle profiles in agent.ExternalProfile, and the enricher
+// in ENRICHERS
+const Platform = "kaggle"
+
+// ProfileBaseURL is where Kaggle profiles live, e.g. https://www.kaggle.com/ana
+const ProfileBaseURL = "https://www.kaggle.com"
+
+// highlights is the number of most voted notebooks and datasets named
+const highlights = 3
+
+// profileLinks find the users of Kaggle links, skipping the Kaggle pages
+// that are not users
+var profileLinks = agent.ProfileLinks{
+	Pattern:  regexp.MustCompile(`(?i)kaggle\.com/([\w./-]+)`),
+	Sections: []string{"code", "datasets", "models"},
+	Reserved: []string{
+		"account", "api", "c", "competitions", "contact", "discussions", "docs",
+		"learn", "rankings", "search", "settings", "static", "terms",
+	},
+}
+
+// Enricher adds the notebooks and datasets a candidate published on
+// Kaggle, for machine learning roles. Only a profile linked from the
+// candidate's blog or bio is looked up. The public API does not expose
+// another user's competition results, so competition work only shows
+// through the public notebooks written for competitions.
+type Enricher struct {
+	Client *Client
+}
+
+var _ agent.ConditionalEnricher = (*Enricher)(nil)
+
+// Name returns Platform
+func (e *Enricher) Name() string { return Platform }
+
+// AppliesTo reports whether requirements are for a machine learning role
+func (e *Enricher) AppliesTo(requirements *agent.Requirements) bool {
+	return agent.IsMLRole(requirements)
+}
+
+// Enrich adds the Kaggle profile linked from the candidate's blog or bio,
+// if they published any notebooks or datasets
+func (e *Enricher) Enrich(ctx context.Context, candidate *agent.EnrichedCandidate) error {
+	for _, p := range candidate.ExternalProfiles {
+		if p.Platform == Platform {
+			return nil
+		}
+	}
+	user, ok := profileLinks.User(candidate.Blog, candidate.Bio)
+	if !ok {
+		return nil
+	}
+
+	notebooks, err := e.Client.ListNotebooks(ctx, user)
+	if err != nil {
+		return err
+	}
+	datasets, err := e.Client.ListDatasets(ctx, user)
+	if err != nil {
+		return err
+	}
+	if len(notebooks) == 0 && len(datasets) == 0 {
+		return nil
+	}
+
+	type work struct {
+		title string
+		votes int
+	}
+	var published []work
+	metrics := map[string]int{agent.MetricNotebooks: len(notebooks), agent.MetricDatasets: len(datasets)}
+	for _, n := range notebooks {
+		metrics[agent.MetricLikes] += n.TotalVotes
+		published = append(published, work{n.Title, n.TotalVotes})
+	}
+	for _, d := range datasets {
+		metrics[agent.MetricLikes] += d.VoteCount
+		metrics[agent.MetricDownloads] += d.DownloadCount
+		published = append(published, work{d.Title, d.VoteCount})
+	}
+	slices.SortStableFunc(published, func(a, b work) int { return b.votes - a.votes })
+
+	profile := agent.ExternalProfile{
+		Platform:   Platform,
+		URL:        ProfileBaseURL + "/" + user,
+		Confidence: 1,
+		MatchedBy:  agent.MatchedByLink,
+		Metrics:    metrics,
+	}
+	for _, w := range published[:min(highlights, len(published))] {
+		profile.Highlights = append(profile.Highlights, w.title)
+	}
+	candidate.ExternalProfiles = append(candidate.ExternalProfiles, profile)
+	return nil
+}
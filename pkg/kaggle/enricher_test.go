@@ -0,0 +1,93 @@
+package kaggle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// newTestServer serves the notebooks and dataset of ana, and rejects bob
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	check := func(r *http.Request) {
+		if user, key, ok := r.BasicAuth(); !ok || user != "recruiter" || key != "kaggle-key" {
+			t.Errorf("Expected the API key, got %q %q", user, key)
+		}
+	}
+	mux.HandleFunc("GET /kernels/list", func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		switch r.URL.Query().Get("user") {
+		case "ana":
+			json.NewEncoder(w).Encode([]Notebook{{Ref: "ana/asr-baseline", Title: "Quechua ASR baseline", TotalVotes: 25}, {Ref: "ana/eda", Title: "EDA", TotalVotes: 3}})
+		case "bob":
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"code": 429, "message": "Too many requests"}`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+	mux.HandleFunc("GET /datasets/list", func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		if r.URL.Query().Get("user") == "ana" {
+			json.NewEncoder(w).Encode([]Dataset{{Ref: "ana/quechua-corpus", Title: "Quechua corpus", VoteCount: 40, DownloadCount: 900}})
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestEnricher(server *httptest.Server) *Enricher {
+	c := NewClient("recruiter", "kaggle-key")
+	c.BaseURL = server.URL
+	return &Enricher{Client: c}
+}
+
+func TestEnrich(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	candidate := &agent.EnrichedCandidate{Username: "ana-q", Blog: "https://www.kaggle.com/code/ana/asr-baseline"}
+	if err := e.Enrich(context.Background(), candidate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(candidate.ExternalProfiles) != 1 {
+		t.Fatalf("Expected one profile, got %+v", candidate.ExternalProfiles)
+	}
+	got := candidate.ExternalProfiles[0]
+	if got.Platform != Platform || got.URL != "https://www.kaggle.com/ana" || got.Confidence != 1 {
+		t.Errorf("Unexpected profile %+v", got)
+	}
+	want := map[string]int{agent.MetricNotebooks: 2, agent.MetricDatasets: 1, agent.MetricLikes: 68, agent.MetricDownloads: 900}
+	for metric, n := range want {
+		if got.Metrics[metric] != n {
+			t.Errorf("Expected %s %d, got %d", metric, n, got.Metrics[metric])
+		}
+	}
+	// Most voted first, across notebooks and datasets
+	if len(got.Highlights) != 3 || got.Highlights[0] != "Quechua corpus" || got.Highlights[1] != "Quechua ASR baseline" {
+		t.Errorf("Unexpected highlights %v", got.Highlights)
+	}
+}
+
+func TestEnrichWithoutWork(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	for _, bio := range []string{"No links here", "https://www.kaggle.com/competitions/titanic", "kaggle.com/carla"} {
+		candidate := &agent.EnrichedCandidate{Bio: bio}
+		if err := e.Enrich(context.Background(), candidate); err != nil || len(candidate.ExternalProfiles) != 0 {
+			t.Errorf("Expected no profile for %q, got %+v, %v", bio, candidate.ExternalProfiles, err)
+		}
+	}
+
+	err := e.Enrich(context.Background(), &agent.EnrichedCandidate{Bio: "kaggle.com/bob"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.RateLimited() || apiErr.Message != "Too many requests" {
+		t.Errorf("Expected a rate-limited APIError, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package kaggle
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// APIError is an error response from the Kaggle API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Kaggle API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// RateLimited reports whether the request was rejected for exceeding the rate limit
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// newAPIError builds an APIError from an error response, which carries its
+// message in a message field
+func newAPIError(statusCode int, body []byte) *APIError {
+	var parsed struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.Message != "" {
+		return &APIError{StatusCode: statusCode, Message: parsed.Message}
+	}
+	return &APIError{StatusCode: statusCode, Message: string(body)}
+}
@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchConcurrency bounds fan-out when BatchConfig.Concurrency is unset
+const defaultBatchConcurrency = 4
+
+// BatchRequest is a single call within a batch
+type BatchRequest struct {
+	Messages []Message
+	Tools    []Tool
+	Options  []CallOption
+}
+
+// BatchResult holds the outcome of the BatchRequest at the same index
+type BatchResult struct {
+	Response *Response
+	Err      error
+}
+
+// BatchCaller is implemented by clients with a native batch API
+type BatchCaller interface {
+	CallBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error)
+}
+
+// BatchConfig configures BatchCall
+type BatchConfig struct {
+	// Concurrency caps in-flight calls when fanning out. Wrap the client with
+	// WithRateLimit to also bound requests and tokens per minute.
+	Concurrency int
+}
+
+// BatchCall runs requests and returns one result per request, in order. It
+// uses the client's native batch API when client implements BatchCaller, and
+// otherwise fans out concurrent CallAPI calls. Middleware is never bypassed:
+// only the outermost client is checked for BatchCaller.
+func BatchCall(ctx context.Context, client Client, requests []BatchRequest, config BatchConfig) ([]BatchResult, error) {
+	if batcher, ok := client.(BatchCaller); ok {
+		return batcher.CallBatch(ctx, requests)
+	}
+
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	results := make([]BatchResult, len(requests))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, req := range requests {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < len(requests); j++ {
+				results[j].Err = ctx.Err()
+			}
+			wg.Wait()
+			return results, nil
+		}
+
+		wg.Add(1)
+		go func(i int, req BatchRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := client.CallAPI(ctx, req.Messages, req.Tools, req.Options...)
+			results[i] = BatchResult{Response: resp, Err: err}
+		}(i, req)
+	}
+	wg.Wait()
+
+	return results, nil
+}
@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// concurrencyClient records the peak number of concurrent calls
+type concurrencyClient struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+}
+
+func (c *concurrencyClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	n := c.inFlight.Add(1)
+	defer c.inFlight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	text := messages[0].Content.(string)
+	if text == "fail" {
+		return nil, errors.New("boom")
+	}
+	return textResponse("echo " + text), nil
+}
+
+type nativeBatchClient struct {
+	mockClient
+	batched int
+}
+
+func (n *nativeBatchClient) CallBatch(ctx context.Context, requests []BatchRequest) ([]BatchResult, error) {
+	n.batched = len(requests)
+	return make([]BatchResult, len(requests)), nil
+}
+
+func TestBatchCall(t *testing.T) {
+	t.Run("FansOutInOrder", func(t *testing.T) {
+		client := &concurrencyClient{}
+		var requests []BatchRequest
+		for i := 0; i < 10; i++ {
+			text := fmt.Sprintf("%d", i)
+			if i == 3 {
+				text = "fail"
+			}
+			requests = append(requests, BatchRequest{Messages: []Message{{Role: "user", Content: text}}})
+		}
+
+		results, err := BatchCall(context.Background(), client, requests, BatchConfig{Concurrency: 3})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(results) != 10 {
+			t.Fatalf("Expected 10 results, got %d", len(results))
+		}
+		if results[3].Err == nil {
+			t.Error("Expected per-request error for request 3")
+		}
+		if results[7].Response.Content[0].Text != "echo 7" {
+			t.Errorf("Expected results in request order, got %q", results[7].Response.Content[0].Text)
+		}
+		if peak := client.peak.Load(); peak > 3 || peak < 2 {
+			t.Errorf("Expected concurrency capped at 3, got peak %d", peak)
+		}
+	})
+
+	t.Run("UsesNativeBatchAPI", func(t *testing.T) {
+		client := &nativeBatchClient{}
+		BatchCall(context.Background(), client, make([]BatchRequest, 5), BatchConfig{})
+		if client.batched != 5 || client.calls != 0 {
+			t.Errorf("Expected native batch of 5 and no CallAPI, got batched=%d calls=%d", client.batched, client.calls)
+		}
+	})
+
+	t.Run("CancelledContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+		results, _ := BatchCall(ctx, mock, make([]BatchRequest, 20), BatchConfig{Concurrency: 1})
+		if !errors.Is(results[19].Err, context.Canceled) {
+			t.Errorf("Expected remaining requests to fail with context.Canceled, got %v", results[19].Err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrBudgetExceeded is returned instead of calling the provider once a
+// BudgetClient has spent its budget. It is not transient: retrying or
+// failing over would only spend more.
+var ErrBudgetExceeded = errors.New("llm budget exceeded")
+
+// BudgetClient stops calling the wrapped client once its calls have cost
+// MaxCostUSD or numbered MaxCalls. The call that crosses the cost limit is
+// let through, so a run may overshoot by the cost of one call.
+type BudgetClient struct {
+	Wrapped    Client
+	MaxCostUSD float64 // Zero for no cost limit
+	MaxCalls   int     // Zero for no call limit
+
+	mu    sync.Mutex
+	calls int
+	cost  float64
+}
+
+// WithBudget wraps client so calls fail with ErrBudgetExceeded once
+// maxCostUSD is spent or maxCalls are made. Zero disables either limit.
+func WithBudget(client Client, maxCostUSD float64, maxCalls int) *BudgetClient {
+	return &BudgetClient{Wrapped: client, MaxCostUSD: maxCostUSD, MaxCalls: maxCalls}
+}
+
+func (b *BudgetClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	b.mu.Lock()
+	switch {
+	case b.MaxCalls > 0 && b.calls >= b.MaxCalls:
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: %d calls made", ErrBudgetExceeded, b.calls)
+	case b.MaxCostUSD > 0 && b.cost >= b.MaxCostUSD:
+		b.mu.Unlock()
+		return nil, fmt.Errorf("%w: $%.4f of $%.4f spent", ErrBudgetExceeded, b.cost, b.MaxCostUSD)
+	}
+	b.calls++
+	b.mu.Unlock()
+
+	resp, err := b.Wrapped.CallAPI(ctx, messages, tools, opts...)
+	if resp != nil {
+		b.mu.Lock()
+		b.cost += resp.Usage.EstimatedCostUSD
+		b.mu.Unlock()
+	}
+	return resp, err
+}
+
+// Spent returns the calls made and their estimated cost so far
+func (b *BudgetClient) Spent() (calls int, costUSD float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls, b.cost
+}
+
+// Unwrap returns the wrapped client
+func (b *BudgetClient) Unwrap() Client { return b.Wrapped }
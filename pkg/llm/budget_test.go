@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBudgetClient(t *testing.T) {
+	costly := func(cost float64) *mockClient {
+		return &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) {
+			resp := textResponse("ok")
+			resp.Usage.EstimatedCostUSD = cost
+			return resp, nil
+		}}
+	}
+
+	t.Run("StopsAfterCost", func(t *testing.T) {
+		client := WithBudget(costly(0.02), 0.03, 0)
+		for i := 0; i < 2; i++ {
+			if _, err := client.CallAPI(context.Background(), nil, nil); err != nil {
+				t.Fatalf("Expected call %d within budget, got %v", i+1, err)
+			}
+		}
+		_, err := client.CallAPI(context.Background(), nil, nil)
+		if !errors.Is(err, ErrBudgetExceeded) {
+			t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+		}
+		if IsTransient(err) {
+			t.Error("Expected an exceeded budget not to be retried")
+		}
+		if calls, cost := client.Spent(); calls != 2 || cost < 0.039 || cost > 0.041 {
+			t.Errorf("Expected 2 calls costing $0.04, got %d and $%f", calls, cost)
+		}
+	})
+
+	t.Run("StopsAfterCalls", func(t *testing.T) {
+		client := WithBudget(costly(0), 0, 1)
+		client.CallAPI(context.Background(), nil, nil)
+		if _, err := client.CallAPI(context.Background(), nil, nil); !errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("Expected ErrBudgetExceeded on the second call, got %v", err)
+		}
+	})
+}
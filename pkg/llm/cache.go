@@ -0,0 +1,239 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// CacheStore persists cached responses
+type CacheStore interface {
+	Get(key string) (*Response, bool)
+	Set(key string, resp *Response, ttl time.Duration) error
+}
+
+// CacheConfig configures response caching
+type CacheConfig struct {
+	Model string        // Included in the cache key so different models never share entries
+	TTL   time.Duration // Zero means entries never expire
+	Store CacheStore    // Defaults to an in-memory store
+	// ModelFor returns the model serving calls labelled with a stage, e.g. from
+	// per-stage overrides, keyed in place of Model. Defaults to Model.
+	ModelFor func(stage string) string
+	// Logger receives failures to store responses. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// CacheClient serves identical requests from a cache instead of calling the provider
+type CacheClient struct {
+	Wrapped Client
+	Config  CacheConfig
+
+	Hits   int
+	Misses int
+	mu     sync.Mutex
+}
+
+// WithCache wraps client with response caching
+func WithCache(client Client, config CacheConfig) *CacheClient {
+	if config.Store == nil {
+		config.Store = NewMemoryCache()
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	return &CacheClient{Wrapped: client, Config: config}
+}
+
+func (c *CacheClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	options := ApplyOptions(opts)
+	model := c.Config.Model
+	if c.Config.ModelFor != nil {
+		model = c.Config.ModelFor(options.Stage)
+	}
+	key, err := CacheKey(model, messages, tools, options)
+	if err != nil {
+		return c.Wrapped.CallAPI(ctx, messages, tools, opts...)
+	}
+
+	if cached, ok := c.Config.Store.Get(key); ok {
+		c.mu.Lock()
+		c.Hits++
+		c.mu.Unlock()
+		// A cache hit costs nothing
+		cached.Cached = true
+		cached.Usage.EstimatedCostUSD = 0
+		return cached, nil
+	}
+
+	c.mu.Lock()
+	c.Misses++
+	c.mu.Unlock()
+
+	resp, err := c.Wrapped.CallAPI(ctx, messages, tools, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Config.Store.Set(key, resp, c.Config.TTL); err != nil {
+		c.Config.Logger.Warn("LLM response not cached", "error", err)
+	}
+	return resp, nil
+}
+
+// Unwrap returns the wrapped client
+func (c *CacheClient) Unwrap() Client { return c.Wrapped }
+
+// CacheKey returns a stable hash of (model, messages, tools, options)
+func CacheKey(model string, messages []Message, tools []Tool, options Options) (string, error) {
+	data, err := json.Marshal(struct {
+		Model    string    `json:"model"`
+		Messages []Message `json:"messages"`
+		Tools    []Tool    `json:"tools,omitempty"`
+		Options  *Options  `json:"options,omitempty"`
+	}{model, messages, tools, nonEmptyOptions(options)})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal cache key: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// nonEmptyOptions returns nil for zero options so keys recorded before options existed stay valid
+func nonEmptyOptions(o Options) *Options {
+	o.Stage = ""
+	o.Stream = nil
+	if reflect.DeepEqual(o, Options{}) {
+		return nil
+	}
+	return &o
+}
+
+type cacheEntry struct {
+	Response  *Response `json:"response"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+func (e cacheEntry) expired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && now.After(e.ExpiresAt)
+}
+
+func newCacheEntry(resp *Response, ttl time.Duration) cacheEntry {
+	entry := cacheEntry{Response: resp}
+	if ttl > 0 {
+		entry.ExpiresAt = time.Now().Add(ttl)
+	}
+	return entry
+}
+
+// cloneResponse deep-copies a response so callers can't mutate cached entries
+func cloneResponse(resp *Response) *Response {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return resp
+	}
+	var out Response
+	if err := json.Unmarshal(data, &out); err != nil {
+		return resp
+	}
+	return &out
+}
+
+// MemoryCache is an in-process CacheStore
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+// NewMemoryCache creates an empty in-memory cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]cacheEntry)}
+}
+
+func (m *MemoryCache) Get(key string) (*Response, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if entry.expired(time.Now()) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return cloneResponse(entry.Response), true
+}
+
+func (m *MemoryCache) Set(key string, resp *Response, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = newCacheEntry(cloneResponse(resp), ttl)
+	return nil
+}
+
+// FileCache is a CacheStore keeping one JSON file per entry in Dir, so cached
+// responses survive across development runs
+type FileCache struct {
+	Dir string
+}
+
+// NewFileCache creates a file cache rooted at dir, creating it if needed
+func NewFileCache(dir string) (*FileCache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &FileCache{Dir: dir}, nil
+}
+
+func (f *FileCache) path(key string) string {
+	return filepath.Join(f.Dir, key+".json")
+}
+
+func (f *FileCache) Get(key string) (*Response, bool) {
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Response == nil {
+		return nil, false
+	}
+	if entry.expired(time.Now()) {
+		os.Remove(f.path(key))
+		return nil, false
+	}
+	return entry.Response, true
+}
+
+func (f *FileCache) Set(key string, resp *Response, ttl time.Duration) error {
+	data, err := json.Marshal(newCacheEntry(resp, ttl))
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	// Write atomically so concurrent runs never read a partial file
+	tmp, err := os.CreateTemp(f.Dir, key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	return os.Rename(tmp.Name(), f.path(key))
+}
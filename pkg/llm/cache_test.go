@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"cmp"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCacheClient(t *testing.T) {
+	messages := []Message{{Role: "user", Content: "Find Go developers in Lima"}}
+
+	newMock := func() *mockClient {
+		return &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) {
+			resp := textResponse("cached answer")
+			resp.Usage = Usage{InputTokens: 10, OutputTokens: 5, EstimatedCostUSD: 0.5}
+			return resp, nil
+		}}
+	}
+
+	t.Run("ServesRepeatedRequestsFromCache", func(t *testing.T) {
+		mock := newMock()
+		client := WithCache(mock, CacheConfig{Model: "gemini"})
+
+		first, _ := client.CallAPI(context.Background(), messages, nil)
+		second, err := client.CallAPI(context.Background(), messages, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if mock.calls != 1 {
+			t.Errorf("Expected 1 provider call, got %d", mock.calls)
+		}
+		if first.Cached || !second.Cached {
+			t.Errorf("Expected only the second response to be marked cached")
+		}
+		if second.Usage.EstimatedCostUSD != 0 {
+			t.Errorf("Expected cached response to cost nothing, got %f", second.Usage.EstimatedCostUSD)
+		}
+		if second.Content[0].Text != "cached answer" {
+			t.Errorf("Expected cached content, got %q", second.Content[0].Text)
+		}
+		if client.Hits != 1 || client.Misses != 1 {
+			t.Errorf("Expected 1 hit and 1 miss, got %d hits and %d misses", client.Hits, client.Misses)
+		}
+	})
+
+	t.Run("ModelIsPartOfKey", func(t *testing.T) {
+		mock := newMock()
+		store := NewMemoryCache()
+		WithCache(mock, CacheConfig{Model: "a", Store: store}).CallAPI(context.Background(), messages, nil)
+		WithCache(mock, CacheConfig{Model: "b", Store: store}).CallAPI(context.Background(), messages, nil)
+		if mock.calls != 2 {
+			t.Errorf("Expected different models not to share entries, got %d calls", mock.calls)
+		}
+	})
+
+	t.Run("StageModelIsPartOfKey", func(t *testing.T) {
+		mock := newMock()
+		store := NewMemoryCache()
+		stageModels := map[string]string{"ranking": "a"}
+		modelFor := func(stage string) string { return cmp.Or(stageModels[stage], "gemini") }
+		client := WithCache(mock, CacheConfig{Model: "gemini", ModelFor: modelFor, Store: store})
+		client.CallAPI(context.Background(), messages, nil, WithStage("ranking"))
+		// Overriding the stage's model leaves the earlier entry behind
+		stageModels["ranking"] = "b"
+		client.CallAPI(context.Background(), messages, nil, WithStage("ranking"))
+		if mock.calls != 2 {
+			t.Errorf("Expected a changed stage model not to share entries, got %d calls", mock.calls)
+		}
+	})
+
+	t.Run("FileCacheWithTTL", func(t *testing.T) {
+		store, err := NewFileCache(t.TempDir())
+		if err != nil {
+			t.Fatalf("Failed to create file cache: %v", err)
+		}
+
+		mock := newMock()
+		WithCache(mock, CacheConfig{Store: store, TTL: time.Hour}).CallAPI(context.Background(), messages, nil)
+		// A new client over the same directory, as in a later run
+		WithCache(mock, CacheConfig{Store: store, TTL: time.Hour}).CallAPI(context.Background(), messages, nil)
+		if mock.calls != 1 {
+			t.Errorf("Expected file cache to persist across clients, got %d calls", mock.calls)
+		}
+
+		key, _ := CacheKey("", messages, nil, Options{})
+		store.Set(key, textResponse("stale"), time.Nanosecond)
+		time.Sleep(time.Millisecond)
+		if _, ok := store.Get(key); ok {
+			t.Error("Expected expired entry to be ignored")
+		}
+	})
+}
@@ -1,6 +1,16 @@
 package llm
 
+import "context"
+
 // Client defines the interface for interacting with an LLM
 type Client interface {
-	CallAPI(messages []Message, tools []Tool) (*Response, error)
+	CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error)
+}
+
+// ClientFunc adapts a function to the Client interface
+type ClientFunc func(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error)
+
+// CallAPI calls f
+func (f ClientFunc) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	return f(ctx, messages, tools, opts...)
 }
@@ -0,0 +1,28 @@
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	cases := []struct {
+		name     string
+		a, b     []float32
+		expected float64
+	}{
+		{"Identical", []float32{1, 2, 3}, []float32{1, 2, 3}, 1},
+		{"Orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"Opposite", []float32{1, 1}, []float32{-1, -1}, -1},
+		{"LengthMismatch", []float32{1}, []float32{1, 2}, 0},
+		{"Zero", []float32{0, 0}, []float32{1, 1}, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := CosineSimilarity(tc.a, tc.b); math.Abs(got-tc.expected) > 1e-6 {
+				t.Errorf("Expected %f, got %f", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError represents a non-success response returned by an LLM provider
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Status     string // Provider-specific status, e.g. "RESOURCE_EXHAUSTED" or "overloaded_error"
+	Message    string
+	RetryAfter time.Duration // Zero when the provider did not send a hint
+}
+
+func (e *APIError) Error() string {
+	if e.Status != "" {
+		return fmt.Sprintf("%s API request failed with status %d (%s): %s", e.Provider, e.StatusCode, e.Status, e.Message)
+	}
+	return fmt.Sprintf("%s API request failed with status %d: %s", e.Provider, e.StatusCode, e.Message)
+}
+
+// DecodeError is model output that could not be decoded into the expected
+// structure. Content keeps the offending output for error reports and dumps.
+type DecodeError struct {
+	Content string
+	Err     error
+}
+
+func (e *DecodeError) Error() string { return e.Err.Error() }
+
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// IsTransient reports whether err is a temporary provider failure (rate limits,
+// quota exhaustion, overload, 5xx or network failures) that may succeed on
+// another attempt or another provider.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// A single hung call may well succeed on the next attempt
+	if errors.Is(err, ErrCallTimeout) {
+		return true
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+			529: // Anthropic "overloaded"
+			return true
+		}
+		return false
+	}
+
+	// Network-level failures (timeouts, refused connections, DNS) are outages, not bad requests
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ParseRetryAfter parses a Retry-After header value given either as seconds or
+// as an HTTP date. It returns zero when the value is missing or malformed.
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// fencePattern matches markdown code fences with an optional language tag
+var fencePattern = regexp.MustCompile("(?s)```[A-Za-z0-9_-]*[ \t]*\r?\n?(.*?)```")
+
+// ExtractJSON returns the JSON document embedded in model output. It accepts
+// bare JSON, JSON inside any of several code fences, and JSON surrounded by
+// prose or trailing commentary, preferring the first valid document found.
+// When nothing valid is found it returns the trimmed input so the caller's
+// json.Unmarshal reports a meaningful error.
+func ExtractJSON(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" || json.Valid([]byte(trimmed)) {
+		return trimmed
+	}
+
+	for _, match := range fencePattern.FindAllStringSubmatch(trimmed, -1) {
+		if block := strings.TrimSpace(match[1]); json.Valid([]byte(block)) {
+			return block
+		}
+	}
+
+	// Scan for the first balanced object or array that parses
+	for start := 0; start < len(trimmed); start++ {
+		if trimmed[start] != '{' && trimmed[start] != '[' {
+			continue
+		}
+		if end := matchingBracket(trimmed, start); end > start {
+			if candidate := trimmed[start : end+1]; json.Valid([]byte(candidate)) {
+				return candidate
+			}
+		}
+	}
+
+	return trimmed
+}
+
+// matchingBracket returns the index of the bracket closing the one at start,
+// skipping brackets inside JSON strings, or -1 if it is never closed
+func matchingBracket(s string, start int) int {
+	var stack []byte
+	inString, escaped := false, false
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return -1
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,35 @@
+package llm
+
+import "testing"
+
+func TestExtractJSON(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"Bare", `{"a": 1}`, `{"a": 1}`},
+		{"BareWithWhitespace", "\n  {\"a\": 1}\n", `{"a": 1}`},
+		{"BareArray", `[1, 2]`, `[1, 2]`},
+		{"JSONFence", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"PlainFence", "```\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"FenceWithProse", "Here is the result:\n```json\n{\"a\": 1}\n```\nLet me know!", `{"a": 1}`},
+		{"MultipleFencesSkipsInvalid", "```go\nfmt.Println(1)\n```\n```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"MultipleFencesFirstWins", "```json\n{\"a\": 1}\n```\n```json\n{\"b\": 2}\n```", `{"a": 1}`},
+		{"LeadingProse", `Sure! {"a": {"b": [1, 2]}}`, `{"a": {"b": [1, 2]}}`},
+		{"TrailingCommentary", `{"a": 1} I hope this helps {really}`, `{"a": 1}`},
+		{"BracesInStrings", `Result: {"note": "use {braces} and \"quotes\" }"} done`, `{"note": "use {braces} and \"quotes\" }"}`},
+		{"SkipsInvalidBraces", `Consider {this}: {"a": 1}`, `{"a": 1}`},
+		{"UnterminatedFence", "```json\n{\"a\": 1}", `{"a": 1}`},
+		{"NoJSON", "  I could not find any candidates.  ", "I could not find any candidates."},
+		{"Empty", "", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ExtractJSON(tc.content); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
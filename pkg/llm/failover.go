@@ -0,0 +1,151 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = time.Minute
+)
+
+// Provider is a named LLM client participating in a failover chain
+type Provider struct {
+	Name   string
+	Client Client
+}
+
+// ProviderHealth is a snapshot of a provider's recent behaviour
+type ProviderHealth struct {
+	Name                string    `json:"name"`
+	Healthy             bool      `json:"healthy"`
+	Calls               int       `json:"calls"`
+	Failures            int       `json:"failures"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	UnhealthyUntil      time.Time `json:"unhealthy_until,omitempty"`
+}
+
+// FailoverClient tries providers in order, moving to the next one when a
+// provider returns a transient error (rate limit, quota exhaustion, 5xx).
+// A provider that fails FailureThreshold times in a row is skipped for
+// Cooldown, unless every provider is unhealthy.
+type FailoverClient struct {
+	FailureThreshold int
+	Cooldown         time.Duration
+
+	providers []Provider
+	mu        sync.Mutex
+	health    []ProviderHealth
+	now       func() time.Time
+}
+
+// NewFailoverClient creates a failover chain over the given providers
+func NewFailoverClient(providers ...Provider) *FailoverClient {
+	health := make([]ProviderHealth, len(providers))
+	for i, p := range providers {
+		health[i] = ProviderHealth{Name: p.Name, Healthy: true}
+	}
+
+	return &FailoverClient{
+		FailureThreshold: defaultFailureThreshold,
+		Cooldown:         defaultCooldown,
+		providers:        providers,
+		health:           health,
+		now:              time.Now,
+	}
+}
+
+// CallAPI calls each provider in order until one succeeds or returns a non-transient error
+func (f *FailoverClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	if len(f.providers) == 0 {
+		return nil, fmt.Errorf("failover: no providers configured")
+	}
+
+	var errs []string
+	var lastErr error
+	for _, i := range f.order() {
+		p := f.providers[i]
+
+		resp, err := p.Client.CallAPI(ctx, messages, tools, opts...)
+		f.record(i, err)
+		if err == nil {
+			return resp, nil
+		}
+
+		if !IsTransient(err) {
+			return nil, fmt.Errorf("%s: %w", p.Name, err)
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", p.Name, err))
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("all providers failed (%s): %w", strings.Join(errs, "; "), lastErr)
+}
+
+// Health returns a snapshot of every provider's health
+func (f *FailoverClient) Health() []ProviderHealth {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	out := make([]ProviderHealth, len(f.health))
+	for i, h := range f.health {
+		h.Healthy = !now.Before(h.UnhealthyUntil)
+		out[i] = h
+	}
+	return out
+}
+
+// Unwrap returns the primary provider's client, so capabilities such as
+// native token counting resolve to it
+func (f *FailoverClient) Unwrap() Client {
+	if len(f.providers) == 0 {
+		return nil
+	}
+	return f.providers[0].Client
+}
+
+// order returns provider indexes with healthy providers first, preserving the configured priority
+func (f *FailoverClient) order() []int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	var healthy, cooling []int
+	for i, h := range f.health {
+		if now.Before(h.UnhealthyUntil) {
+			cooling = append(cooling, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	return append(healthy, cooling...)
+}
+
+func (f *FailoverClient) record(i int, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	h := &f.health[i]
+	h.Calls++
+	if err == nil {
+		h.ConsecutiveFailures = 0
+		h.UnhealthyUntil = time.Time{}
+		return
+	}
+
+	h.Failures++
+	h.LastError = err.Error()
+	// Only transient failures count against health; a bad request is not an outage
+	if IsTransient(err) {
+		h.ConsecutiveFailures++
+	}
+	if f.FailureThreshold > 0 && h.ConsecutiveFailures >= f.FailureThreshold {
+		h.UnhealthyUntil = f.now().Add(f.Cooldown)
+	}
+}
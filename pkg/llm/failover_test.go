@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type mockClient struct {
+	calls       int
+	lastOptions Options
+	CallAPIFunc func(messages []Message, tools []Tool) (*Response, error)
+}
+
+func (m *mockClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	m.calls++
+	m.lastOptions = ApplyOptions(opts)
+	return m.CallAPIFunc(messages, tools)
+}
+
+func textResponse(text string) *Response {
+	return &Response{Content: []ContentBlock{{Type: "text", Text: text}}, StopReason: "end_turn"}
+}
+
+func TestFailoverClient(t *testing.T) {
+	quotaErr := &APIError{Provider: "primary", StatusCode: 429, Status: "RESOURCE_EXHAUSTED"}
+
+	t.Run("FailsOverOnTransientError", func(t *testing.T) {
+		primary := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return nil, quotaErr }}
+		secondary := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+
+		client := NewFailoverClient(Provider{Name: "primary", Client: primary}, Provider{Name: "secondary", Client: secondary})
+		resp, err := client.CallAPI(context.Background(), nil, nil)
+		if err != nil {
+			t.Fatalf("Expected success, got error: %v", err)
+		}
+		if resp.Content[0].Text != "ok" {
+			t.Errorf("Expected response from secondary, got %q", resp.Content[0].Text)
+		}
+		if primary.calls != 1 || secondary.calls != 1 {
+			t.Errorf("Expected one call per provider, got primary=%d secondary=%d", primary.calls, secondary.calls)
+		}
+	})
+
+	t.Run("DoesNotFailOverOnFatalError", func(t *testing.T) {
+		badRequest := &APIError{Provider: "primary", StatusCode: 400, Message: "invalid schema"}
+		primary := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return nil, badRequest }}
+		secondary := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+
+		client := NewFailoverClient(Provider{Name: "primary", Client: primary}, Provider{Name: "secondary", Client: secondary})
+		_, err := client.CallAPI(context.Background(), nil, nil)
+		if !errors.Is(err, badRequest) {
+			t.Errorf("Expected bad request error, got %v", err)
+		}
+		if secondary.calls != 0 {
+			t.Errorf("Expected secondary not to be called, got %d calls", secondary.calls)
+		}
+	})
+
+	t.Run("SkipsUnhealthyProviderDuringCooldown", func(t *testing.T) {
+		primary := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return nil, quotaErr }}
+		secondary := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+
+		client := NewFailoverClient(Provider{Name: "primary", Client: primary}, Provider{Name: "secondary", Client: secondary})
+		client.FailureThreshold = 2
+		now := time.Now()
+		client.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			if _, err := client.CallAPI(context.Background(), nil, nil); err != nil {
+				t.Fatalf("Call %d failed: %v", i, err)
+			}
+		}
+		if primary.calls != 2 {
+			t.Errorf("Expected primary to be skipped after 2 failures, got %d calls", primary.calls)
+		}
+
+		health := client.Health()
+		if health[0].Healthy {
+			t.Error("Expected primary to be reported unhealthy")
+		}
+
+		// After the cooldown the primary is tried first again
+		now = now.Add(client.Cooldown + time.Second)
+		client.CallAPI(context.Background(), nil, nil)
+		if primary.calls != 3 {
+			t.Errorf("Expected primary to be retried after cooldown, got %d calls", primary.calls)
+		}
+	})
+
+	t.Run("AllProvidersFail", func(t *testing.T) {
+		failing := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return nil, quotaErr }}
+
+		client := NewFailoverClient(Provider{Name: "a", Client: failing}, Provider{Name: "b", Client: failing})
+		_, err := client.CallAPI(context.Background(), nil, nil)
+		if err == nil {
+			t.Fatal("Expected error when all providers fail")
+		}
+		if !IsTransient(err) {
+			t.Errorf("Expected aggregated error to remain transient, got %v", err)
+		}
+	})
+}
+
+func TestIsTransient(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{"Nil", nil, false},
+		{"RateLimited", &APIError{StatusCode: 429}, true},
+		{"Overloaded", &APIError{StatusCode: 529}, true},
+		{"Unavailable", &APIError{StatusCode: 503}, true},
+		{"BadRequest", &APIError{StatusCode: 400}, false},
+		{"Plain", errors.New("failed to parse response"), false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsTransient(tc.err); got != tc.expected {
+				t.Errorf("Expected IsTransient=%v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package llm
+
+// GenerationConfig tunes sampling and output length. Nil or zero fields keep
+// the client's configured value, or the provider default.
+type GenerationConfig struct {
+	Temperature     *float32 `json:"temperature,omitempty"`
+	TopP            *float32 `json:"top_p,omitempty"`
+	TopK            *int32   `json:"top_k,omitempty"`
+	MaxOutputTokens int32    `json:"max_output_tokens,omitempty"`
+	StopSequences   []string `json:"stop_sequences,omitempty"`
+}
+
+// Merge returns g with the fields set in override taking precedence
+func (g GenerationConfig) Merge(override *GenerationConfig) GenerationConfig {
+	if override == nil {
+		return g
+	}
+	if override.Temperature != nil {
+		g.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		g.TopP = override.TopP
+	}
+	if override.TopK != nil {
+		g.TopK = override.TopK
+	}
+	if override.MaxOutputTokens > 0 {
+		g.MaxOutputTokens = override.MaxOutputTokens
+	}
+	if len(override.StopSequences) > 0 {
+		g.StopSequences = override.StopSequences
+	}
+	return g
+}
+
+// WithGeneration overrides the client's generation settings for one call.
+// Repeated options are merged, later ones winning.
+func WithGeneration(config GenerationConfig) CallOption {
+	return func(o *Options) {
+		merged := GenerationConfig{}.Merge(o.Generation).Merge(&config)
+		o.Generation = &merged
+	}
+}
+
+// WithTemperature overrides the sampling temperature for one call
+func WithTemperature(t float32) CallOption {
+	return WithGeneration(GenerationConfig{Temperature: &t})
+}
+
+// WithMaxOutputTokens caps the response length for one call
+func WithMaxOutputTokens(n int32) CallOption {
+	return WithGeneration(GenerationConfig{MaxOutputTokens: n})
+}
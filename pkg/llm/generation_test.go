@@ -0,0 +1,27 @@
+package llm
+
+import "testing"
+
+func TestGenerationConfig(t *testing.T) {
+	temp, topP := float32(0.2), float32(0.9)
+	base := GenerationConfig{Temperature: &temp, TopP: &topP, MaxOutputTokens: 1024}
+
+	options := ApplyOptions([]CallOption{
+		WithTemperature(0.7),
+		WithGeneration(GenerationConfig{StopSequences: []string{"END"}}),
+	})
+	got := base.Merge(options.Generation)
+
+	if *got.Temperature != 0.7 {
+		t.Errorf("Expected per-call temperature 0.7, got %v", *got.Temperature)
+	}
+	if *got.TopP != 0.9 || got.MaxOutputTokens != 1024 {
+		t.Errorf("Expected client settings to be kept, got %+v", got)
+	}
+	if len(got.StopSequences) != 1 || got.StopSequences[0] != "END" {
+		t.Errorf("Expected stop sequences from the second option, got %v", got.StopSequences)
+	}
+	if *base.Temperature != 0.2 {
+		t.Error("Expected Merge not to modify the base config")
+	}
+}
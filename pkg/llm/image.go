@@ -0,0 +1,61 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// imageTokenEstimate is a rough per-image prompt cost used by EstimateTokens
+const imageTokenEstimate = 1600
+
+// ImageBlock returns an image content block with inline data. The media type
+// is detected from the data when mediaType is empty.
+func ImageBlock(mediaType string, data []byte) ContentBlock {
+	if mediaType == "" {
+		mediaType = http.DetectContentType(data)
+	}
+	return ContentBlock{
+		Type: "image",
+		Source: &ImageSource{
+			Type:      "base64",
+			MediaType: mediaType,
+			Data:      base64.StdEncoding.EncodeToString(data),
+		},
+	}
+}
+
+// ImageURLBlock returns an image content block referencing a remote image
+func ImageURLBlock(mediaType, url string) ContentBlock {
+	return ContentBlock{
+		Type: "image",
+		Source: &ImageSource{
+			Type:      "url",
+			MediaType: mediaType,
+			URL:       url,
+		},
+	}
+}
+
+// ImageFileBlock reads an image from disk, e.g. a portfolio screenshot or an
+// architecture diagram attached to a job description
+func ImageFileBlock(path string) (ContentBlock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ContentBlock{}, fmt.Errorf("failed to read image: %w", err)
+	}
+	return ImageBlock("", data), nil
+}
+
+// Bytes decodes inline image data
+func (s *ImageSource) Bytes() ([]byte, error) {
+	if s.Type != "base64" {
+		return nil, fmt.Errorf("image source of type %q has no inline data", s.Type)
+	}
+	data, err := base64.StdEncoding.DecodeString(s.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image data: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// LoggingConfig configures LLM call logging
+type LoggingConfig struct {
+	Logger *slog.Logger // Defaults to slog.Default()
+	// LogContent includes prompts and responses, not only metadata
+	LogContent bool
+	// Redact is applied to logged prompts and responses. Defaults to RedactPII;
+	// set NoRedaction to log content verbatim.
+	Redact func(string) string
+}
+
+// NoRedaction logs content verbatim
+func NoRedaction(text string) string { return text }
+
+// LoggingClient logs every call with latency, token usage and, optionally,
+// redacted prompts and responses
+type LoggingClient struct {
+	Wrapped Client
+	Config  LoggingConfig
+}
+
+// WithLogging wraps client with structured call logging
+func WithLogging(client Client, config LoggingConfig) *LoggingClient {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.Redact == nil {
+		config.Redact = RedactPII
+	}
+	return &LoggingClient{Wrapped: client, Config: config}
+}
+
+func (l *LoggingClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	start := time.Now()
+	resp, err := l.Wrapped.CallAPI(ctx, messages, tools, opts...)
+	latency := time.Since(start)
+
+	attrs := []any{
+		slog.Duration("latency", latency),
+		slog.Int("messages", len(messages)),
+		slog.Int("tools", len(tools)),
+	}
+	if options := ApplyOptions(opts); options.ResponseSchema != nil {
+		attrs = append(attrs, slog.String("response_schema", options.ResponseSchema.Name))
+	}
+	if l.Config.LogContent {
+		attrs = append(attrs, slog.String("prompt", l.Config.Redact(marshalForLog(messages))))
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", l.Config.Redact(err.Error())))
+		l.Config.Logger.Error("llm call failed", attrs...)
+		return nil, err
+	}
+
+	attrs = append(attrs,
+		slog.String("model", resp.Model),
+		slog.String("stop_reason", resp.StopReason),
+		slog.Int("input_tokens", resp.Usage.InputTokens),
+		slog.Int("output_tokens", resp.Usage.OutputTokens),
+		slog.Float64("estimated_cost_usd", resp.Usage.EstimatedCostUSD),
+		slog.Bool("cached", resp.Cached),
+	)
+	if l.Config.LogContent {
+		attrs = append(attrs, slog.String("response", l.Config.Redact(marshalForLog(resp.Content))))
+	}
+	l.Config.Logger.Info("llm call", attrs...)
+
+	return resp, nil
+}
+
+// Unwrap returns the wrapped client
+func (l *LoggingClient) Unwrap() Client { return l.Wrapped }
+
+func marshalForLog(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,101 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLoggingClient(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) {
+		resp := textResponse("Contact jane@example.com or https://github.com/janedoe")
+		resp.Model = "gemini-3-pro-preview"
+		resp.Usage = Usage{InputTokens: 120, OutputTokens: 30}
+		return resp, nil
+	}}
+	client := WithLogging(mock, LoggingConfig{Logger: logger, LogContent: true})
+
+	messages := []Message{{Role: "user", Content: "Candidate phone +51 987 654 321"}}
+	if _, err := client.CallAPI(context.Background(), messages, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected a JSON log line, got %q", buf.String())
+	}
+	if entry["model"] != "gemini-3-pro-preview" || entry["input_tokens"] != float64(120) {
+		t.Errorf("Expected model and usage in log entry, got %v", entry)
+	}
+	if _, ok := entry["latency"]; !ok {
+		t.Error("Expected latency in log entry")
+	}
+	logged := buf.String()
+	for _, pii := range []string{"jane@example.com", "github.com/janedoe", "987 654 321"} {
+		if strings.Contains(logged, pii) {
+			t.Errorf("Expected %q to be redacted, got %s", pii, logged)
+		}
+	}
+
+	t.Run("LogsErrors", func(t *testing.T) {
+		buf.Reset()
+		failing := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) {
+			return nil, errors.New("boom")
+		}}
+		WithLogging(failing, LoggingConfig{Logger: logger}).CallAPI(context.Background(), nil, nil)
+		if !strings.Contains(buf.String(), `"level":"ERROR"`) || strings.Contains(buf.String(), "prompt") {
+			t.Errorf("Expected an error entry without content, got %s", buf.String())
+		}
+	})
+}
+
+func TestRedactPII(t *testing.T) {
+	got := RedactPII("Ana (ana.q@mail.pe) https://www.linkedin.com/in/ana-q github.com/anaq")
+	expected := "Ana ([EMAIL]) [PROFILE_URL] [PROFILE_URL]"
+	if got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestRedactPIINames(t *testing.T) {
+	payload := `{"username":"anaq","name":"Ana Quispe","bio":"Go dev"}`
+	want := `{"username":"[NAME]","name":"[NAME]","bio":"Go dev"}`
+	if got := RedactPII(payload); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// Candidate JSON embedded in a logged prompt is escaped
+	escaped := `{"text":"Input Data: {\"name\":\"Ana Quispe\",\"followers\":12}"}`
+	want = `{"text":"Input Data: {\"name\":\"[NAME]\",\"followers\":12}"}`
+	if got := RedactPII(escaped); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestParseRedaction(t *testing.T) {
+	r, err := ParseRedaction("emails, names")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !r.Emails || !r.Names || r.ProfileURLs || r.Phones {
+		t.Errorf("Expected only emails and names, got %+v", r)
+	}
+	got := r.Redactor()(`{"login":"anaq"} ana@mail.pe github.com/anaq`)
+	if want := `{"login":"[NAME]"} [EMAIL] github.com/anaq`; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if r, _ := ParseRedaction("none"); r.Redactor()("ana@mail.pe") != "ana@mail.pe" {
+		t.Error("Expected none to redact nothing")
+	}
+	if _, err := ParseRedaction("emails,ssn"); err == nil {
+		t.Error("Expected error for unknown redaction")
+	}
+}
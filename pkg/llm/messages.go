@@ -0,0 +1,150 @@
+package llm
+
+import "encoding/json"
+
+// SystemText returns a system message
+func SystemText(text string) Message {
+	return Message{Role: "system", Content: text}
+}
+
+// UserText returns a user message
+func UserText(text string) Message {
+	return Message{Role: "user", Content: text}
+}
+
+// AssistantText returns an assistant message
+func AssistantText(text string) Message {
+	return Message{Role: "assistant", Content: text}
+}
+
+// AssistantBlocks returns an assistant message made of blocks, typically a
+// previous response's content including its tool_use blocks
+func AssistantBlocks(blocks ...ContentBlock) Message {
+	return Message{Role: "assistant", Content: blocks}
+}
+
+// UserBlocks returns a user message made of blocks, e.g. tool results or images
+func UserBlocks(blocks ...ContentBlock) Message {
+	return Message{Role: "user", Content: blocks}
+}
+
+// TextBlock returns a text content block
+func TextBlock(text string) ContentBlock {
+	return ContentBlock{Type: "text", Text: text}
+}
+
+// ToolResult returns the result block answering the tool_use block with id.
+// String payloads are sent as-is; anything else is encoded as JSON.
+func ToolResult(id string, payload interface{}) ContentBlock {
+	content, ok := payload.(string)
+	if !ok {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			content = "error: failed to encode tool result: " + err.Error()
+		} else {
+			content = string(data)
+		}
+	}
+	return ContentBlock{Type: "tool_result", ToolUseID: id, Content: content}
+}
+
+// ToolUses returns the tool_use blocks of resp
+func ToolUses(resp *Response) []ContentBlock {
+	var uses []ContentBlock
+	for _, block := range resp.Content {
+		if block.Type == "tool_use" {
+			uses = append(uses, block)
+		}
+	}
+	return uses
+}
+
+// Conversation accumulates the messages of a multi-turn exchange around a
+// fixed system prompt
+type Conversation struct {
+	System string
+	turns  []Message
+}
+
+// NewConversation starts a conversation with an optional system prompt
+func NewConversation(system string) *Conversation {
+	return &Conversation{System: system}
+}
+
+// Append adds messages to the conversation
+func (c *Conversation) Append(messages ...Message) *Conversation {
+	c.turns = append(c.turns, messages...)
+	return c
+}
+
+// AddUser appends a user text message
+func (c *Conversation) AddUser(text string) *Conversation {
+	return c.Append(UserText(text))
+}
+
+// AddResponse appends resp as the assistant's turn, keeping tool_use blocks
+// so their results can follow
+func (c *Conversation) AddResponse(resp *Response) *Conversation {
+	return c.Append(AssistantBlocks(resp.Content...))
+}
+
+// AddToolResults appends tool results as the user's turn
+func (c *Conversation) AddToolResults(results ...ContentBlock) *Conversation {
+	return c.Append(UserBlocks(results...))
+}
+
+// Len returns the number of messages, excluding the system prompt
+func (c *Conversation) Len() int {
+	return len(c.turns)
+}
+
+// Messages returns the system prompt followed by the conversation
+func (c *Conversation) Messages() []Message {
+	messages := make([]Message, 0, len(c.turns)+1)
+	if c.System != "" {
+		messages = append(messages, SystemText(c.System))
+	}
+	return append(messages, c.turns...)
+}
+
+// Truncate keeps at most the last maxMessages messages. The kept history
+// always starts at a plain user message, so tool results are never separated
+// from the tool_use blocks they answer.
+func (c *Conversation) Truncate(maxMessages int) {
+	if len(c.turns) <= maxMessages {
+		return
+	}
+	c.turns = c.turns[len(c.turns)-max(maxMessages, 0):]
+	c.trimToTurnStart()
+}
+
+// TruncateToTokens drops the oldest turns until the estimated prompt size is
+// within budget tokens. The most recent message is always kept.
+func (c *Conversation) TruncateToTokens(budget int) {
+	for len(c.turns) > 1 && EstimateTokens(c.Messages(), nil) > budget {
+		c.turns = c.turns[1:]
+		c.trimToTurnStart()
+	}
+}
+
+// trimToTurnStart drops leading messages until the history starts with a
+// user message that is not a tool result
+func (c *Conversation) trimToTurnStart() {
+	for len(c.turns) > 1 && !isTurnStart(c.turns[0]) {
+		c.turns = c.turns[1:]
+	}
+}
+
+func isTurnStart(msg Message) bool {
+	if msg.Role != "user" {
+		return false
+	}
+	if blocks, ok := msg.Content.([]ContentBlock); ok {
+		for _, block := range blocks {
+			if block.Type == "tool_result" {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToolResult(t *testing.T) {
+	if got := ToolResult("toolu_1", "plain"); got.Content != "plain" || got.ToolUseID != "toolu_1" || got.Type != "tool_result" {
+		t.Errorf("Expected string payload as-is, got %+v", got)
+	}
+	if got := ToolResult("toolu_2", map[string]int{"count": 3}); got.Content != `{"count":3}` {
+		t.Errorf("Expected JSON payload, got %q", got.Content)
+	}
+}
+
+func TestConversation(t *testing.T) {
+	toolUse := &Response{Content: []ContentBlock{{Type: "tool_use", ID: "toolu_1", Name: "search_github_developers"}}}
+
+	newConversation := func() *Conversation {
+		c := NewConversation("You are a sourcing assistant.")
+		c.AddUser("Find Go developers in Lima")
+		c.AddResponse(toolUse)
+		c.AddToolResults(ToolResult("toolu_1", "[]"))
+		c.Append(AssistantText("No candidates found."))
+		c.AddUser("Try Peru instead")
+		return c
+	}
+
+	t.Run("Messages", func(t *testing.T) {
+		messages := newConversation().Messages()
+		if len(messages) != 6 || messages[0].Role != "system" || messages[2].Role != "assistant" {
+			t.Fatalf("Expected system prompt followed by 5 messages, got %+v", messages)
+		}
+		if uses := ToolUses(toolUse); len(uses) != 1 || uses[0].ID != "toolu_1" {
+			t.Errorf("Expected one tool use, got %+v", uses)
+		}
+	})
+
+	t.Run("TruncateKeepsToolPairs", func(t *testing.T) {
+		c := newConversation()
+		// The last 4 messages start with a tool result, which must not be orphaned
+		c.Truncate(4)
+		messages := c.Messages()
+		if c.Len() != 1 || messages[1].Content != "Try Peru instead" {
+			t.Errorf("Expected history to restart at the last user turn, got %+v", messages)
+		}
+		if messages[0].Role != "system" {
+			t.Error("Expected system prompt to survive truncation")
+		}
+	})
+
+	t.Run("TruncateToTokens", func(t *testing.T) {
+		c := NewConversation("")
+		c.AddUser(strings.Repeat("a", 400))
+		c.Append(AssistantText("ok"))
+		c.AddUser("latest question")
+		c.TruncateToTokens(20)
+		if c.Len() != 1 || c.Messages()[0].Content != "latest question" {
+			t.Errorf("Expected only the latest turn within budget, got %+v", c.Messages())
+		}
+	})
+}
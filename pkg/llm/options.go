@@ -0,0 +1,94 @@
+package llm
+
+// Options holds per-call settings. Providers map each field to their native
+// mechanism and ignore fields they don't support.
+type Options struct {
+	ResponseSchema *ResponseSchema   `json:"response_schema,omitempty"`
+	Generation     *GenerationConfig `json:"generation,omitempty"`
+	// JSONMode asks for a JSON response without a schema. Providers without a
+	// native JSON mode ignore it and rely on the prompt.
+	JSONMode bool `json:"json_mode,omitempty"`
+	// ToolChoice controls whether the model must, may or must not call tools
+	ToolChoice *ToolChoice `json:"tool_choice,omitempty"`
+	// Stage labels the pipeline step making the call for usage accounting.
+	// It never reaches the provider and is excluded from cache keys.
+	Stage string `json:"-"`
+	// Stream receives the response incrementally; see WithStream
+	Stream StreamHandler `json:"-"`
+}
+
+// CallOption configures a single CallAPI invocation
+type CallOption func(*Options)
+
+// ResponseSchema describes the JSON document a call must return. Providers use
+// their native structured-output feature (Vertex responseSchema, an Anthropic
+// forced tool call) so the response text is the bare JSON document.
+type ResponseSchema struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Schema      InputSchema `json:"schema"`
+}
+
+// WithResponseSchema requests a structured response conforming to schema
+func WithResponseSchema(schema *ResponseSchema) CallOption {
+	return func(o *Options) {
+		o.ResponseSchema = schema
+	}
+}
+
+// WithJSONMode requests a JSON response without constraining its shape
+func WithJSONMode() CallOption {
+	return func(o *Options) {
+		o.JSONMode = true
+	}
+}
+
+// Tool choice modes
+const (
+	ToolChoiceAuto = "auto" // The model decides whether to call a tool
+	ToolChoiceAny  = "any"  // The model must call one of the tools
+	ToolChoiceTool = "tool" // The model must call the tool named in ToolChoice.Name
+	ToolChoiceNone = "none" // The model must not call tools
+)
+
+// ToolChoice constrains tool use for a call
+type ToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"` // Required when Type is ToolChoiceTool
+}
+
+// WithToolChoice sets how the model may use the tools passed to the call
+func WithToolChoice(choice ToolChoice) CallOption {
+	return func(o *Options) {
+		o.ToolChoice = &choice
+	}
+}
+
+// WithForcedTool requires the model to call the named tool
+func WithForcedTool(name string) CallOption {
+	return WithToolChoice(ToolChoice{Type: ToolChoiceTool, Name: name})
+}
+
+// WithoutTools forbids tool calls while keeping the tool definitions in the
+// prompt, e.g. to force a final answer after tool results
+func WithoutTools() CallOption {
+	return WithToolChoice(ToolChoice{Type: ToolChoiceNone})
+}
+
+// WithStage labels the call with the pipeline stage issuing it
+func WithStage(name string) CallOption {
+	return func(o *Options) {
+		o.Stage = name
+	}
+}
+
+// ApplyOptions resolves call options into an Options value
+func ApplyOptions(opts []CallOption) Options {
+	var o Options
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
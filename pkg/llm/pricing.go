@@ -0,0 +1,73 @@
+package llm
+
+import "strings"
+
+// ModelPricing is the list price of a model in USD per million tokens
+type ModelPricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+	// CachedInputPerMillion prices input served from a context cache. Zero
+	// means cached input is billed at InputPerMillion.
+	CachedInputPerMillion float64
+}
+
+// Pricing maps model name prefixes to their list prices. Model names returned
+// by providers often carry version suffixes (e.g. "claude-sonnet-4-20250514"),
+// so lookups use the longest matching prefix. Callers may add or override entries.
+var Pricing = map[string]ModelPricing{
+	// Google Gemini (Vertex AI)
+	"gemini-3-pro":          {InputPerMillion: 2.00, OutputPerMillion: 12.00, CachedInputPerMillion: 0.20},
+	"gemini-2.5-pro":        {InputPerMillion: 1.25, OutputPerMillion: 10.00, CachedInputPerMillion: 0.125},
+	"gemini-2.5-flash":      {InputPerMillion: 0.30, OutputPerMillion: 2.50, CachedInputPerMillion: 0.03},
+	"gemini-2.5-flash-lite": {InputPerMillion: 0.10, OutputPerMillion: 0.40, CachedInputPerMillion: 0.01},
+
+	// Anthropic Claude (cache reads are billed at a tenth of the input price)
+	"claude-opus-4":     {InputPerMillion: 15.00, OutputPerMillion: 75.00, CachedInputPerMillion: 1.50},
+	"claude-opus-4-5":   {InputPerMillion: 5.00, OutputPerMillion: 25.00, CachedInputPerMillion: 0.50},
+	"claude-sonnet-4":   {InputPerMillion: 3.00, OutputPerMillion: 15.00, CachedInputPerMillion: 0.30},
+	"claude-haiku-4":    {InputPerMillion: 1.00, OutputPerMillion: 5.00, CachedInputPerMillion: 0.10},
+	"claude-3-5-haiku":  {InputPerMillion: 0.80, OutputPerMillion: 4.00, CachedInputPerMillion: 0.08},
+	"claude-3-7-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00, CachedInputPerMillion: 0.30},
+
+	// OpenAI (local Ollama models are unpriced)
+	"gpt-4.1":      {InputPerMillion: 2.00, OutputPerMillion: 8.00, CachedInputPerMillion: 0.50},
+	"gpt-4.1-mini": {InputPerMillion: 0.40, OutputPerMillion: 1.60, CachedInputPerMillion: 0.10},
+	"gpt-4.1-nano": {InputPerMillion: 0.10, OutputPerMillion: 0.40, CachedInputPerMillion: 0.025},
+	"gpt-4o":       {InputPerMillion: 2.50, OutputPerMillion: 10.00, CachedInputPerMillion: 1.25},
+	"gpt-4o-mini":  {InputPerMillion: 0.15, OutputPerMillion: 0.60, CachedInputPerMillion: 0.075},
+}
+
+// LookupPricing returns the pricing for model using the longest matching prefix
+func LookupPricing(model string) (ModelPricing, bool) {
+	model = strings.ToLower(model)
+	// Publisher-qualified names such as "publishers/google/models/gemini-2.5-pro"
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+
+	var best string
+	for prefix := range Pricing {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return ModelPricing{}, false
+	}
+	return Pricing[best], true
+}
+
+// EstimateCost returns the estimated cost in USD of usage on model, or 0 if the model is unknown
+func EstimateCost(model string, usage Usage) float64 {
+	pricing, ok := LookupPricing(model)
+	if !ok {
+		return 0
+	}
+	cachedRate := pricing.CachedInputPerMillion
+	if cachedRate == 0 {
+		cachedRate = pricing.InputPerMillion
+	}
+	return float64(usage.InputTokens-usage.CachedInputTokens)/1e6*pricing.InputPerMillion +
+		float64(usage.CachedInputTokens)/1e6*cachedRate +
+		float64(usage.OutputTokens)/1e6*pricing.OutputPerMillion
+}
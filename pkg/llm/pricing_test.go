@@ -0,0 +1,30 @@
+package llm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	cases := []struct {
+		name     string
+		model    string
+		usage    Usage
+		expected float64
+	}{
+		{"VersionedClaude", "claude-sonnet-4-20250514", Usage{InputTokens: 1_000_000, OutputTokens: 100_000}, 4.5},
+		{"LongestPrefixWins", "gemini-2.5-flash-lite", Usage{InputTokens: 1_000_000}, 0.10},
+		{"PublisherPath", "publishers/google/models/gemini-2.5-pro", Usage{OutputTokens: 1_000_000}, 10.0},
+		{"CachedInput", "gemini-2.5-pro", Usage{InputTokens: 1_000_000, CachedInputTokens: 800_000}, 0.25 + 0.1},
+		{"UnknownModel", "my-local-model", Usage{InputTokens: 1000}, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := EstimateCost(tc.model, tc.usage)
+			if math.Abs(got-tc.expected) > 1e-9 {
+				t.Errorf("Expected cost %.6f, got %.6f", tc.expected, got)
+			}
+		})
+	}
+}
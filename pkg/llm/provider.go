@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ProviderFactory builds a provider's client from its configuration,
+// returning an error that names any missing setting
+type ProviderFactory func(ctx context.Context) (Client, error)
+
+// Providers maps provider names to the factories that build their clients,
+// so the provider can be chosen at run time. The zero value is ready to use.
+type Providers struct {
+	names     []string
+	factories map[string]ProviderFactory
+}
+
+// Register adds the factory for name, replacing any earlier registration
+func (p *Providers) Register(name string, factory ProviderFactory) {
+	if p.factories == nil {
+		p.factories = make(map[string]ProviderFactory)
+	}
+	if _, ok := p.factories[name]; !ok {
+		p.names = append(p.names, name)
+	}
+	p.factories[name] = factory
+}
+
+// Names lists the registered providers in registration order
+func (p *Providers) Names() []string {
+	return append([]string(nil), p.names...)
+}
+
+// New builds the client of the named provider
+func (p *Providers) New(ctx context.Context, name string) (Client, error) {
+	factory, ok := p.factories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown LLM provider %q: want one of %s", name, strings.Join(p.names, ", "))
+	}
+	client, err := factory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return client, nil
+}
@@ -0,0 +1,33 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestProviders(t *testing.T) {
+	var providers Providers
+	stub := ClientFunc(func(context.Context, []Message, []Tool, ...CallOption) (*Response, error) {
+		return &Response{}, nil
+	})
+	providers.Register("local", func(context.Context) (Client, error) { return stub, nil })
+	providers.Register("hosted", func(context.Context) (Client, error) { return nil, errors.New("HOSTED_API_KEY is not set") })
+
+	if names := providers.Names(); len(names) != 2 || names[0] != "local" || names[1] != "hosted" {
+		t.Errorf("Expected providers in registration order, got %v", names)
+	}
+	if client, err := providers.New(context.Background(), "local"); err != nil || client == nil {
+		t.Errorf("Expected local client, got %v, %v", client, err)
+	}
+
+	_, err := providers.New(context.Background(), "hosted")
+	if err == nil || err.Error() != "hosted: HOSTED_API_KEY is not set" {
+		t.Errorf("Expected the missing setting to be named, got %v", err)
+	}
+	_, err = providers.New(context.Background(), "other")
+	if err == nil || !strings.Contains(err.Error(), "local, hosted") {
+		t.Errorf("Expected unknown provider error listing providers, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitConfig defines client-side quotas. Zero values disable a limit.
+type RateLimitConfig struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// RateLimitClient delays calls so the wrapped client stays within the
+// configured requests/minute and tokens/minute budgets.
+type RateLimitClient struct {
+	Wrapped Client
+	Config  RateLimitConfig
+
+	mu       sync.Mutex
+	requests *tokenBucket
+	tokens   *tokenBucket
+	now      func() time.Time
+	sleep    func(context.Context, time.Duration) error
+}
+
+// WithRateLimit wraps client with token-bucket rate limiting
+func WithRateLimit(client Client, config RateLimitConfig) *RateLimitClient {
+	r := &RateLimitClient{
+		Wrapped: client,
+		Config:  config,
+		now:     time.Now,
+		sleep:   sleepContext,
+	}
+	if config.RequestsPerMinute > 0 {
+		r.requests = newTokenBucket(config.RequestsPerMinute)
+	}
+	if config.TokensPerMinute > 0 {
+		r.tokens = newTokenBucket(config.TokensPerMinute)
+	}
+	return r
+}
+
+func (r *RateLimitClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	estimated := EstimateTokens(messages, tools)
+	if err := r.wait(ctx, estimated); err != nil {
+		return nil, fmt.Errorf("rate limit wait aborted: %w", err)
+	}
+
+	resp, err := r.Wrapped.CallAPI(ctx, messages, tools, opts...)
+
+	// Reconcile the estimate with the real usage reported by the provider
+	if err == nil && resp != nil && r.tokens != nil {
+		actual := resp.Usage.InputTokens + resp.Usage.OutputTokens
+		if actual > 0 {
+			r.mu.Lock()
+			r.tokens.take(r.now(), float64(actual-estimated))
+			r.mu.Unlock()
+		}
+	}
+
+	return resp, err
+}
+
+// Unwrap returns the wrapped client
+func (r *RateLimitClient) Unwrap() Client { return r.Wrapped }
+
+// wait blocks until both buckets can cover the call or ctx is done, then reserves capacity
+func (r *RateLimitClient) wait(ctx context.Context, estimatedTokens int) error {
+	r.mu.Lock()
+	now := r.now()
+	var delay time.Duration
+	if r.requests != nil {
+		delay = max(delay, r.requests.reserve(now, 1))
+	}
+	if r.tokens != nil {
+		// A single call larger than the whole bucket can never fit; cap it so it waits at most a minute
+		delay = max(delay, r.tokens.reserve(now, min(float64(estimatedTokens), r.tokens.capacity)))
+	}
+	r.mu.Unlock()
+
+	if delay > 0 {
+		return r.sleep(ctx, delay)
+	}
+	return nil
+}
+
+// tokenBucket is a minimal token bucket refilled continuously at capacity per minute.
+// The balance may go negative, which makes subsequent callers wait longer.
+type tokenBucket struct {
+	capacity  float64
+	available float64
+	perSecond float64
+	last      time.Time
+}
+
+func newTokenBucket(perMinute int) *tokenBucket {
+	return &tokenBucket{
+		capacity:  float64(perMinute),
+		available: float64(perMinute),
+		perSecond: float64(perMinute) / 60,
+	}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.available += now.Sub(b.last).Seconds() * b.perSecond
+		if b.available > b.capacity {
+			b.available = b.capacity
+		}
+	}
+	b.last = now
+}
+
+// take removes n tokens (n may be negative to return tokens)
+func (b *tokenBucket) take(now time.Time, n float64) {
+	b.refill(now)
+	b.available -= n
+	if b.available > b.capacity {
+		b.available = b.capacity
+	}
+}
+
+// reserve takes n tokens and returns how long the caller must wait for them to be available
+func (b *tokenBucket) reserve(now time.Time, n float64) time.Duration {
+	b.take(now, n)
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.perSecond * float64(time.Second))
+}
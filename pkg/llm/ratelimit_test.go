@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRateLimitClient(t *testing.T) {
+	newClient := func(config RateLimitConfig, wrapped Client) (*RateLimitClient, *time.Time, *[]time.Duration) {
+		now := time.Now()
+		var delays []time.Duration
+		client := WithRateLimit(wrapped, config)
+		client.now = func() time.Time { return now }
+		client.sleep = func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			now = now.Add(d)
+			return nil
+		}
+		return client, &now, &delays
+	}
+
+	t.Run("RequestsPerMinute", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+		client, _, delays := newClient(RateLimitConfig{RequestsPerMinute: 2}, mock)
+
+		for i := 0; i < 3; i++ {
+			client.CallAPI(context.Background(), nil, nil)
+		}
+		if len(*delays) != 1 {
+			t.Fatalf("Expected only the third call to wait, got delays %v", *delays)
+		}
+		if (*delays)[0] != 30*time.Second {
+			t.Errorf("Expected a 30s wait, got %v", (*delays)[0])
+		}
+	})
+
+	t.Run("RefillsOverTime", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+		client, now, delays := newClient(RateLimitConfig{RequestsPerMinute: 1}, mock)
+
+		client.CallAPI(context.Background(), nil, nil)
+		*now = now.Add(time.Minute)
+		client.CallAPI(context.Background(), nil, nil)
+		if len(*delays) != 0 {
+			t.Errorf("Expected no wait after a full refill, got %v", *delays)
+		}
+	})
+
+	t.Run("TokensPerMinuteUsesReportedUsage", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) {
+			resp := textResponse("ok")
+			resp.Usage = Usage{InputTokens: 900, OutputTokens: 100}
+			return resp, nil
+		}}
+		client, _, delays := newClient(RateLimitConfig{TokensPerMinute: 1000}, mock)
+
+		messages := []Message{{Role: "user", Content: strings.Repeat("a", 40)}}
+		client.CallAPI(context.Background(), messages, nil)
+		if len(*delays) != 0 {
+			t.Fatalf("Expected first call not to wait, got %v", *delays)
+		}
+
+		// The first call consumed the whole budget, so the next one has to wait for a refill
+		client.CallAPI(context.Background(), messages, nil)
+		if len(*delays) != 1 || (*delays)[0] <= 0 {
+			t.Errorf("Expected second call to wait for token refill, got %v", *delays)
+		}
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+		client, _, delays := newClient(RateLimitConfig{}, mock)
+
+		for i := 0; i < 10; i++ {
+			client.CallAPI(context.Background(), nil, nil)
+		}
+		if len(*delays) != 0 {
+			t.Errorf("Expected no waits without limits, got %v", *delays)
+		}
+	})
+}
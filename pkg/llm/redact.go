@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	emailPattern   = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	profilePattern = regexp.MustCompile(`(?i)(https?://)?(www\.)?(github\.com|gitlab\.com|linkedin\.com/in|twitter\.com|x\.com)/[A-Za-z0-9_.\-]+`)
+	phonePattern   = regexp.MustCompile(`\+?\d[\d\s().\-]{8,}\d`)
+)
+
+// DefaultNameFields are the JSON fields holding candidate names and logins
+var DefaultNameFields = []string{"name", "username", "login"}
+
+// Redaction selects which candidate personal data a redactor masks
+type Redaction struct {
+	Emails      bool
+	ProfileURLs bool
+	Phones      bool
+	// Names masks the values of NameFields (DefaultNameFields if empty) in
+	// JSON payloads, including JSON escaped inside logged strings. Free-text
+	// names cannot be told apart from other words and are left alone.
+	Names      bool
+	NameFields []string
+}
+
+// DefaultRedaction masks everything
+func DefaultRedaction() Redaction {
+	return Redaction{Emails: true, ProfileURLs: true, Phones: true, Names: true}
+}
+
+// ParseRedaction parses a comma-separated list of emails, profile_urls,
+// phones and names, or "all" or "none". An empty spec means all.
+func ParseRedaction(spec string) (Redaction, error) {
+	switch strings.TrimSpace(spec) {
+	case "", "all":
+		return DefaultRedaction(), nil
+	case "none":
+		return Redaction{}, nil
+	}
+	var r Redaction
+	for _, item := range strings.Split(spec, ",") {
+		switch strings.TrimSpace(item) {
+		case "emails":
+			r.Emails = true
+		case "profile_urls":
+			r.ProfileURLs = true
+		case "phones":
+			r.Phones = true
+		case "names":
+			r.Names = true
+		default:
+			return Redaction{}, fmt.Errorf("unknown redaction %q: want emails, profile_urls, phones, names, all or none", item)
+		}
+	}
+	return r, nil
+}
+
+// Redactor returns a function masking the selected data in text
+func (r Redaction) Redactor() func(string) string {
+	var namePattern *regexp.Regexp
+	if r.Names {
+		fields := r.NameFields
+		if len(fields) == 0 {
+			fields = DefaultNameFields
+		}
+		quoted := make([]string, len(fields))
+		for i, f := range fields {
+			quoted[i] = regexp.QuoteMeta(f)
+		}
+		// Quotes may be escaped when the JSON is itself inside a JSON string
+		namePattern = regexp.MustCompile(`(\\*"(?:` + strings.Join(quoted, "|") + `)\\*"\s*:\s*\\*")(.*?)(\\*")`)
+	}
+
+	return func(text string) string {
+		if namePattern != nil {
+			text = namePattern.ReplaceAllString(text, "${1}[NAME]${3}")
+		}
+		if r.Emails {
+			text = emailPattern.ReplaceAllString(text, "[EMAIL]")
+		}
+		if r.ProfileURLs {
+			text = profilePattern.ReplaceAllString(text, "[PROFILE_URL]")
+		}
+		if r.Phones {
+			text = phonePattern.ReplaceAllString(text, "[PHONE]")
+		}
+		return text
+	}
+}
+
+var defaultRedactor = DefaultRedaction().Redactor()
+
+// RedactPII masks candidate personal data (emails, profile URLs, phone
+// numbers and names in JSON payloads) in text. It is the default redactor
+// for logging middleware.
+func RedactPII(text string) string {
+	return defaultRedactor(text)
+}
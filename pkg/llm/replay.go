@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Interaction is a single recorded request/response pair
+type Interaction struct {
+	Key      string    `json:"key"`
+	Messages []Message `json:"messages"`
+	Tools    []Tool    `json:"tools,omitempty"`
+	Options  *Options  `json:"options,omitempty"`
+	Response *Response `json:"response"`
+}
+
+// Recording is the golden file format shared by RecordingClient and ReplayClient
+type Recording struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LoadRecording reads a golden file
+func LoadRecording(path string) (*Recording, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	var rec Recording
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("failed to parse recording %s: %w", path, err)
+	}
+	return &rec, nil
+}
+
+// Save writes the recording to path, creating parent directories as needed
+func (r *Recording) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal recording: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// RecordingClient forwards calls to a real provider and writes every
+// interaction to a golden file after each call
+type RecordingClient struct {
+	Wrapped Client
+	Path    string
+
+	mu        sync.Mutex
+	recording Recording
+}
+
+// NewRecordingClient records interactions of client into the golden file at path
+func NewRecordingClient(client Client, path string) *RecordingClient {
+	return &RecordingClient{Wrapped: client, Path: path}
+}
+
+func (r *RecordingClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	resp, err := r.Wrapped.CallAPI(ctx, messages, tools, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	options := ApplyOptions(opts)
+	key, err := CacheKey("", messages, tools, options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording.Interactions = append(r.recording.Interactions, Interaction{
+		Key:      key,
+		Messages: messages,
+		Tools:    tools,
+		Options:  nonEmptyOptions(options),
+		Response: cloneResponse(resp),
+	})
+	if err := r.recording.Save(r.Path); err != nil {
+		return nil, fmt.Errorf("failed to save recording: %w", err)
+	}
+
+	return resp, nil
+}
+
+// Unwrap returns the wrapped client
+func (r *RecordingClient) Unwrap() Client { return r.Wrapped }
+
+// ReplayClient serves responses from a golden file without calling any provider.
+// Requests are matched by content; when Strict is false, an unmatched request
+// is served the next unused interaction in recorded order, so small prompt
+// edits don't invalidate the whole recording.
+type ReplayClient struct {
+	Strict bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayClient loads the golden file at path
+func NewReplayClient(path string) (*ReplayClient, error) {
+	rec, err := LoadRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayClient{
+		interactions: rec.Interactions,
+		used:         make([]bool, len(rec.Interactions)),
+	}, nil
+}
+
+func (r *ReplayClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	options := ApplyOptions(opts)
+	key, err := CacheKey("", messages, tools, options)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, in := range r.interactions {
+		if !r.used[i] && in.Key == key {
+			r.used[i] = true
+			return cloneResponse(in.Response), nil
+		}
+	}
+
+	if !r.Strict {
+		for i, in := range r.interactions {
+			if !r.used[i] {
+				r.used[i] = true
+				return cloneResponse(in.Response), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("replay: no recorded response for request %s (re-record the golden file)", key[:12])
+}
+
+// Remaining returns how many recorded interactions have not been replayed
+func (r *ReplayClient) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for _, used := range r.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+// NewGoldenClient returns a client that records real responses from client
+// into path when record is true, and replays path otherwise. It lets the same
+// test run against live providers once and deterministically afterwards.
+func NewGoldenClient(client Client, path string, record bool) (Client, error) {
+	if record {
+		if client == nil {
+			return nil, fmt.Errorf("recording %s requires a real client", path)
+		}
+		return NewRecordingClient(client, path), nil
+	}
+	return NewReplayClient(path)
+}
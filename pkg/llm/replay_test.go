@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden", "conversation.json")
+	first := []Message{{Role: "user", Content: "first"}}
+	second := []Message{{Role: "user", Content: "second"}}
+
+	provider := &mockClient{}
+	provider.CallAPIFunc = func(messages []Message, tools []Tool) (*Response, error) {
+		return textResponse("answer to " + messages[0].Content.(string)), nil
+	}
+
+	recorder, err := NewGoldenClient(provider, path, true)
+	if err != nil {
+		t.Fatalf("Failed to create recorder: %v", err)
+	}
+	recorder.CallAPI(context.Background(), first, nil)
+	recorder.CallAPI(context.Background(), second, nil)
+
+	t.Run("ReplaysByContent", func(t *testing.T) {
+		replay, err := NewReplayClient(path)
+		if err != nil {
+			t.Fatalf("Failed to load recording: %v", err)
+		}
+		replay.Strict = true
+
+		resp, err := replay.CallAPI(context.Background(), second, nil)
+		if err != nil {
+			t.Fatalf("Expected recorded response, got %v", err)
+		}
+		if resp.Content[0].Text != "answer to second" {
+			t.Errorf("Expected response matched by content, got %q", resp.Content[0].Text)
+		}
+		if replay.Remaining() != 1 {
+			t.Errorf("Expected 1 remaining interaction, got %d", replay.Remaining())
+		}
+	})
+
+	t.Run("StrictRejectsUnknownRequest", func(t *testing.T) {
+		replay, _ := NewReplayClient(path)
+		replay.Strict = true
+
+		if _, err := replay.CallAPI(context.Background(), []Message{{Role: "user", Content: "edited prompt"}}, nil); err == nil {
+			t.Error("Expected error for unrecorded request in strict mode")
+		}
+	})
+
+	t.Run("LenientFallsBackToOrder", func(t *testing.T) {
+		replay, _ := NewReplayClient(path)
+
+		resp, err := replay.CallAPI(context.Background(), []Message{{Role: "user", Content: "edited prompt"}}, nil)
+		if err != nil {
+			t.Fatalf("Expected fallback response, got %v", err)
+		}
+		if resp.Content[0].Text != "answer to first" {
+			t.Errorf("Expected first recorded response, got %q", resp.Content[0].Text)
+		}
+	})
+}
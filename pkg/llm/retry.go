@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryConfig controls how transient LLM failures are retried
+type RetryConfig struct {
+	MaxAttempts  int           // Total attempts including the first call
+	InitialDelay time.Duration // Delay before the first retry
+	MaxDelay     time.Duration // Upper bound for any single delay, including Retry-After hints
+	Multiplier   float64       // Backoff growth factor between attempts
+}
+
+// DefaultRetryConfig returns the retry policy used by the agent pipeline
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:  4,
+		InitialDelay: time.Second,
+		MaxDelay:     30 * time.Second,
+		Multiplier:   2,
+	}
+}
+
+// RetryClient retries transient failures (429, 5xx, overloaded) of the wrapped
+// client with exponential backoff and jitter, honoring Retry-After hints.
+type RetryClient struct {
+	Wrapped Client
+	Config  RetryConfig
+
+	sleep func(context.Context, time.Duration) error
+}
+
+// WithRetry wraps client with retry handling
+func WithRetry(client Client, config RetryConfig) *RetryClient {
+	return &RetryClient{
+		Wrapped: client,
+		Config:  config,
+		sleep:   sleepContext,
+	}
+}
+
+func (r *RetryClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	attempts := r.Config.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var resp *Response
+		resp, err = r.Wrapped.CallAPI(ctx, messages, tools, opts...)
+		if err == nil {
+			return resp, nil
+		}
+		if !IsTransient(err) || attempt == attempts {
+			break
+		}
+		if sleepErr := r.sleep(ctx, r.delay(attempt, err)); sleepErr != nil {
+			return nil, fmt.Errorf("retry aborted after %d attempts: %w", attempt, err)
+		}
+	}
+
+	if IsTransient(err) && attempts > 1 {
+		return nil, fmt.Errorf("giving up after %d attempts: %w", attempts, err)
+	}
+	return nil, err
+}
+
+// Unwrap returns the wrapped client
+func (r *RetryClient) Unwrap() Client { return r.Wrapped }
+
+// delay computes the wait before the next attempt
+func (r *RetryClient) delay(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return r.cap(apiErr.RetryAfter)
+	}
+
+	backoff := float64(r.Config.InitialDelay)
+	for i := 1; i < attempt; i++ {
+		backoff *= r.Config.Multiplier
+	}
+	d := r.cap(time.Duration(backoff))
+	if d <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half the backoff, randomize the rest
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(d-half)+1))
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *RetryClient) cap(d time.Duration) time.Duration {
+	if r.Config.MaxDelay > 0 && d > r.Config.MaxDelay {
+		return r.Config.MaxDelay
+	}
+	return d
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryClient(t *testing.T) {
+	newClient := func(wrapped Client) (*RetryClient, *[]time.Duration) {
+		var delays []time.Duration
+		client := WithRetry(wrapped, RetryConfig{
+			MaxAttempts:  3,
+			InitialDelay: 100 * time.Millisecond,
+			MaxDelay:     time.Second,
+			Multiplier:   2,
+		})
+		client.sleep = func(_ context.Context, d time.Duration) error {
+			delays = append(delays, d)
+			return nil
+		}
+		return client, &delays
+	}
+
+	t.Run("RetriesTransientErrors", func(t *testing.T) {
+		mock := &mockClient{}
+		mock.CallAPIFunc = func([]Message, []Tool) (*Response, error) {
+			if mock.calls < 3 {
+				return nil, &APIError{StatusCode: 503}
+			}
+			return textResponse("ok"), nil
+		}
+
+		client, delays := newClient(mock)
+		if _, err := client.CallAPI(context.Background(), nil, nil); err != nil {
+			t.Fatalf("Expected success after retries, got %v", err)
+		}
+		if mock.calls != 3 {
+			t.Errorf("Expected 3 calls, got %d", mock.calls)
+		}
+		if len(*delays) != 2 {
+			t.Fatalf("Expected 2 backoff delays, got %d", len(*delays))
+		}
+		if d := (*delays)[1]; d < 100*time.Millisecond || d > 200*time.Millisecond {
+			t.Errorf("Expected second delay within [100ms, 200ms], got %v", d)
+		}
+	})
+
+	t.Run("HonorsRetryAfter", func(t *testing.T) {
+		mock := &mockClient{}
+		mock.CallAPIFunc = func([]Message, []Tool) (*Response, error) {
+			if mock.calls == 1 {
+				return nil, &APIError{StatusCode: 429, RetryAfter: 700 * time.Millisecond}
+			}
+			return textResponse("ok"), nil
+		}
+
+		client, delays := newClient(mock)
+		client.CallAPI(context.Background(), nil, nil)
+		if len(*delays) != 1 || (*delays)[0] != 700*time.Millisecond {
+			t.Errorf("Expected a single 700ms delay, got %v", *delays)
+		}
+	})
+
+	t.Run("CapsRetryAfter", func(t *testing.T) {
+		mock := &mockClient{}
+		mock.CallAPIFunc = func([]Message, []Tool) (*Response, error) {
+			if mock.calls == 1 {
+				return nil, &APIError{StatusCode: 429, RetryAfter: time.Hour}
+			}
+			return textResponse("ok"), nil
+		}
+
+		client, delays := newClient(mock)
+		client.CallAPI(context.Background(), nil, nil)
+		if (*delays)[0] != time.Second {
+			t.Errorf("Expected delay capped at 1s, got %v", (*delays)[0])
+		}
+	})
+
+	t.Run("StopsOnFatalError", func(t *testing.T) {
+		fatal := &APIError{StatusCode: 401}
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return nil, fatal }}
+
+		client, _ := newClient(mock)
+		_, err := client.CallAPI(context.Background(), nil, nil)
+		if !errors.Is(err, fatal) {
+			t.Errorf("Expected fatal error to be returned, got %v", err)
+		}
+		if mock.calls != 1 {
+			t.Errorf("Expected 1 call, got %d", mock.calls)
+		}
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return nil, &APIError{StatusCode: 529} }}
+
+		client, _ := newClient(mock)
+		if _, err := client.CallAPI(context.Background(), nil, nil); err == nil {
+			t.Fatal("Expected error after exhausting attempts")
+		}
+		if mock.calls != 3 {
+			t.Errorf("Expected 3 calls, got %d", mock.calls)
+		}
+	})
+}
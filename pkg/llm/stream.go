@@ -0,0 +1,23 @@
+package llm
+
+// StreamChunk is an incremental piece of a response delivered while it is
+// being generated
+type StreamChunk struct {
+	// Text is the next piece of text output; empty for tool calls
+	Text string
+	// ToolUse is a tool call, delivered once its input is complete
+	ToolUse *ContentBlock
+}
+
+// StreamHandler receives chunks in order on the calling goroutine. It should
+// return quickly, as the provider waits for it before reading further.
+type StreamHandler func(StreamChunk)
+
+// WithStream asks the provider to stream the response to handler as it is
+// generated. CallAPI still returns the complete Response. Providers that
+// cannot stream ignore it. Retries may replay chunks from the start.
+func WithStream(handler StreamHandler) CallOption {
+	return func(o *Options) {
+		o.Stream = handler
+	}
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CallStructured calls client requesting a response that conforms to schema
+// and unmarshals the result into target
+func CallStructured(ctx context.Context, client Client, messages []Message, schema *ResponseSchema, target interface{}, opts ...CallOption) (*Response, error) {
+	opts = append(opts, WithResponseSchema(schema))
+	resp, err := client.CallAPI(ctx, messages, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := DecodeStructured(resp, target); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// DecodeStructured unmarshals the text content of resp into target
+func DecodeStructured(resp *Response, target interface{}) error {
+	// Tolerate providers that still wrap the document in a code fence or prose
+	text := ExtractJSON(ResponseText(resp))
+	if err := json.Unmarshal([]byte(text), target); err != nil {
+		return fmt.Errorf("failed to parse structured response: %w", &DecodeError{Content: text, Err: err})
+	}
+	return nil
+}
+
+// ResponseText concatenates the text blocks of resp
+func ResponseText(resp *Response) string {
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCallStructured(t *testing.T) {
+	schema := &ResponseSchema{
+		Name: "requirements",
+		Schema: InputSchema{
+			Type:       "object",
+			Properties: map[string]Property{"level": {Type: "string"}},
+			Required:   []string{"level"},
+		},
+	}
+
+	t.Run("PassesSchemaAndDecodes", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) {
+			return textResponse(`{"level": "senior"}`), nil
+		}}
+
+		var out struct {
+			Level string `json:"level"`
+		}
+		if _, err := CallStructured(context.Background(), mock, nil, schema, &out); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if out.Level != "senior" {
+			t.Errorf("Expected level 'senior', got %q", out.Level)
+		}
+		if mock.lastOptions.ResponseSchema != schema {
+			t.Error("Expected response schema to be passed to the provider")
+		}
+	})
+
+	t.Run("ToleratesCodeFence", func(t *testing.T) {
+		var out map[string]string
+		resp := textResponse("```json\n{\"level\": \"mid\"}\n```")
+		if err := DecodeStructured(resp, &out); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if out["level"] != "mid" {
+			t.Errorf("Expected level 'mid', got %q", out["level"])
+		}
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		var out map[string]string
+		if err := DecodeStructured(textResponse("not json"), &out); err == nil {
+			t.Error("Expected error for invalid JSON")
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package llm
+
+import "strings"
+
+// SplitSystem separates system-role messages from the conversation. The text
+// of all system messages is joined with blank lines, in order, so providers
+// can map it to their native mechanism (Anthropic's system parameter, Gemini's
+// systemInstruction) instead of sending it inline.
+func SplitSystem(messages []Message) (string, []Message) {
+	var system []string
+	rest := make([]Message, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Role != "system" {
+			rest = append(rest, msg)
+			continue
+		}
+		if text := messageText(msg.Content); text != "" {
+			system = append(system, text)
+		}
+	}
+	return strings.Join(system, "\n\n"), rest
+}
+
+// messageText returns the text of string content or of its text blocks
+func messageText(content interface{}) string {
+	switch v := content.(type) {
+	case string:
+		return v
+	case []ContentBlock:
+		var texts []string
+		for _, block := range v {
+			if block.Type == "text" && block.Text != "" {
+				texts = append(texts, block.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
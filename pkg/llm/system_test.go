@@ -0,0 +1,27 @@
+package llm
+
+import "testing"
+
+func TestSplitSystem(t *testing.T) {
+	messages := []Message{
+		{Role: "system", Content: "You are a sourcing assistant."},
+		{Role: "user", Content: "Find Go developers"},
+		{Role: "system", Content: []ContentBlock{{Type: "text", Text: "Answer in JSON."}}},
+		{Role: "assistant", Content: "ok"},
+	}
+
+	system, rest := SplitSystem(messages)
+	if system != "You are a sourcing assistant.\n\nAnswer in JSON." {
+		t.Errorf("Expected joined system prompt, got %q", system)
+	}
+	if len(rest) != 2 || rest[0].Role != "user" || rest[1].Role != "assistant" {
+		t.Errorf("Expected user and assistant messages in order, got %+v", rest)
+	}
+
+	t.Run("NoSystem", func(t *testing.T) {
+		system, rest := SplitSystem([]Message{{Role: "user", Content: "hi"}})
+		if system != "" || len(rest) != 1 {
+			t.Errorf("Expected no system prompt, got %q and %d messages", system, len(rest))
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrCallTimeout is returned when a call exceeds the TimeoutClient deadline.
+// It is transient, so retry and failover middleware treat it like an outage.
+var ErrCallTimeout = errors.New("llm call timed out")
+
+// TimeoutClient bounds every call of the wrapped client with a deadline
+type TimeoutClient struct {
+	Wrapped Client
+	Timeout time.Duration
+}
+
+// WithTimeout wraps client so each call fails after timeout. A zero or
+// negative timeout disables the deadline.
+func WithTimeout(client Client, timeout time.Duration) *TimeoutClient {
+	return &TimeoutClient{Wrapped: client, Timeout: timeout}
+}
+
+func (t *TimeoutClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	if t.Timeout <= 0 {
+		return t.Wrapped.CallAPI(ctx, messages, tools, opts...)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, t.Timeout)
+	defer cancel()
+
+	type result struct {
+		resp *Response
+		err  error
+	}
+	// Run the call in a goroutine so providers that ignore ctx still fail fast
+	done := make(chan result, 1)
+	go func() {
+		resp, err := t.Wrapped.CallAPI(callCtx, messages, tools, opts...)
+		done <- result{resp, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return nil, t.timeoutError()
+		}
+		return r.resp, r.err
+	case <-callCtx.Done():
+		// The caller's own cancellation is not a timeout of this call
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, t.timeoutError()
+	}
+}
+
+// Unwrap returns the wrapped client
+func (t *TimeoutClient) Unwrap() Client { return t.Wrapped }
+
+func (t *TimeoutClient) timeoutError() error {
+	return fmt.Errorf("%w after %v", ErrCallTimeout, t.Timeout)
+}
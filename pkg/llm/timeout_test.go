@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingClient blocks until released, ignoring ctx like a hung provider
+type blockingClient struct {
+	release chan struct{}
+}
+
+func (b *blockingClient) CallAPI(ctx context.Context, messages []Message, tools []Tool, opts ...CallOption) (*Response, error) {
+	<-b.release
+	return textResponse("late"), nil
+}
+
+func TestTimeoutClient(t *testing.T) {
+	t.Run("FailsFastOnHungCall", func(t *testing.T) {
+		hung := &blockingClient{release: make(chan struct{})}
+		defer close(hung.release)
+
+		start := time.Now()
+		_, err := WithTimeout(hung, 20*time.Millisecond).CallAPI(context.Background(), nil, nil)
+		if !errors.Is(err, ErrCallTimeout) {
+			t.Fatalf("Expected ErrCallTimeout, got %v", err)
+		}
+		if !IsTransient(err) {
+			t.Error("Expected timeout to be transient")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected call to fail fast, took %v", elapsed)
+		}
+	})
+
+	t.Run("PassesThroughFastCalls", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return textResponse("ok"), nil }}
+		resp, err := WithTimeout(mock, time.Second).CallAPI(context.Background(), nil, nil)
+		if err != nil || resp.Content[0].Text != "ok" {
+			t.Errorf("Expected ok response, got %v, %v", resp, err)
+		}
+	})
+
+	t.Run("CallerCancellationIsNotTimeout", func(t *testing.T) {
+		hung := &blockingClient{release: make(chan struct{})}
+		defer close(hung.release)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := WithTimeout(hung, time.Second).CallAPI(ctx, nil, nil)
+		if !errors.Is(err, context.Canceled) || errors.Is(err, ErrCallTimeout) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("RetryStopsWhenContextDone", func(t *testing.T) {
+		mock := &mockClient{CallAPIFunc: func([]Message, []Tool) (*Response, error) { return nil, &APIError{StatusCode: 503} }}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		WithRetry(mock, DefaultRetryConfig()).CallAPI(ctx, nil, nil)
+		if mock.calls != 1 {
+			t.Errorf("Expected retries to stop on cancelled context, got %d calls", mock.calls)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// charsPerToken is the rough ratio used when no provider tokenizer is available
+const charsPerToken = 4
+
+// TokenCounter is implemented by clients that can count prompt tokens natively
+type TokenCounter interface {
+	CountTokens(ctx context.Context, messages []Message, tools []Tool) (int, error)
+}
+
+// Wrapper is implemented by middleware clients to expose the client they wrap
+type Wrapper interface {
+	Unwrap() Client
+}
+
+// CountTokens counts the prompt tokens for messages and tools. It uses the
+// provider's tokenizer when client, or any client it wraps, implements
+// TokenCounter, and falls back to EstimateTokens otherwise.
+func CountTokens(ctx context.Context, client Client, messages []Message, tools []Tool) (int, error) {
+	for c := client; c != nil; {
+		if counter, ok := c.(TokenCounter); ok {
+			n, err := counter.CountTokens(ctx, messages, tools)
+			if err != nil {
+				return 0, fmt.Errorf("failed to count tokens: %w", err)
+			}
+			return n, nil
+		}
+		w, ok := c.(Wrapper)
+		if !ok {
+			break
+		}
+		c = w.Unwrap()
+	}
+	return EstimateTokens(messages, tools), nil
+}
+
+// EstimateTokens approximates the prompt size of messages and tools using a
+// characters-per-token heuristic. It is intentionally conservative for JSON.
+func EstimateTokens(messages []Message, tools []Tool) int {
+	chars := 0
+	for _, msg := range messages {
+		chars += len(msg.Role)
+		switch v := msg.Content.(type) {
+		case string:
+			chars += len(v)
+		case []ContentBlock:
+			for _, block := range v {
+				chars += len(block.Text) + len(block.Content) + len(block.Name)
+				if block.Source != nil {
+					chars += imageTokenEstimate * charsPerToken
+				}
+				if block.Input != nil {
+					if data, err := json.Marshal(block.Input); err == nil {
+						chars += len(data)
+					}
+				}
+			}
+		}
+	}
+	for _, tool := range tools {
+		if data, err := json.Marshal(tool); err == nil {
+			chars += len(data)
+		}
+	}
+	return (chars + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateTextTokens approximates the number of tokens in text
+func EstimateTextTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
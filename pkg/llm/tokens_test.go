@@ -0,0 +1,67 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type countingMock struct {
+	mockClient
+	tokens int
+}
+
+func (c *countingMock) CountTokens(context.Context, []Message, []Tool) (int, error) {
+	return c.tokens, nil
+}
+
+func TestCountTokens(t *testing.T) {
+	messages := []Message{{Role: "user", Content: strings.Repeat("a", 400)}}
+
+	t.Run("Heuristic", func(t *testing.T) {
+		n, err := CountTokens(context.Background(), &mockClient{}, messages, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if n < 100 || n > 110 {
+			t.Errorf("Expected roughly 100 tokens, got %d", n)
+		}
+	})
+
+	t.Run("ProviderCounterThroughWrappers", func(t *testing.T) {
+		provider := &countingMock{tokens: 42}
+		client := WithRetry(WithRateLimit(provider, RateLimitConfig{}), DefaultRetryConfig())
+
+		n, err := CountTokens(context.Background(), client, messages, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if n != 42 {
+			t.Errorf("Expected provider count 42, got %d", n)
+		}
+	})
+
+	t.Run("ProviderCounterBehindFailover", func(t *testing.T) {
+		client := NewFailoverClient(
+			Provider{Name: "primary", Client: &countingMock{tokens: 42}},
+			Provider{Name: "secondary", Client: &mockClient{}},
+		)
+
+		n, err := CountTokens(context.Background(), client, messages, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if n != 42 {
+			t.Errorf("Expected primary provider count 42, got %d", n)
+		}
+	})
+
+	t.Run("ContentBlocks", func(t *testing.T) {
+		blocks := []Message{{Role: "user", Content: []ContentBlock{
+			{Type: "tool_result", Content: strings.Repeat("b", 80)},
+		}}}
+		if n := EstimateTokens(blocks, nil); n < 20 {
+			t.Errorf("Expected tool result content to be counted, got %d", n)
+		}
+	})
+}
@@ -16,14 +16,24 @@ type Message struct {
 
 // ContentBlock represents a generic content block
 type ContentBlock struct {
-	Type             string      `json:"type"`
-	Text             string      `json:"text,omitempty"`
-	ID               string      `json:"id,omitempty"`
-	Name             string      `json:"name,omitempty"`
-	Input            interface{} `json:"input,omitempty"`
-	ToolUseID        string      `json:"tool_use_id,omitempty"`
-	Content          string      `json:"content,omitempty"`
-	ThoughtSignature string      `json:"thought_signature,omitempty"`
+	Type             string       `json:"type"`
+	Text             string       `json:"text,omitempty"`
+	ID               string       `json:"id,omitempty"`
+	Name             string       `json:"name,omitempty"`
+	Input            interface{}  `json:"input,omitempty"`
+	ToolUseID        string       `json:"tool_use_id,omitempty"`
+	Content          string       `json:"content,omitempty"`
+	ThoughtSignature string       `json:"thought_signature,omitempty"` // Gemini thought signature, or Claude's thinking signature/redacted data
+	Source           *ImageSource `json:"source,omitempty"`            // Set on "image" blocks
+}
+
+// ImageSource holds the image of an "image" content block, either inline
+// base64 data or a URL (https:// or gs://)
+type ImageSource struct {
+	Type      string `json:"type"` // "base64" or "url"
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"` // Base64-encoded bytes
+	URL       string `json:"url,omitempty"`
 }
 
 // Tool represents a generic tool definition
@@ -40,11 +50,17 @@ type InputSchema struct {
 	Required   []string            `json:"required"`
 }
 
-// Property defines a property in the tool input schema
+// Property defines a property in the tool input schema. Properties are
+// recursive: arrays describe their elements with Items and objects their
+// fields with Properties and Required.
 type Property struct {
-	Type        string `json:"type"`
-	Description string `json:"description"`
-	Default     int    `json:"default,omitempty"`
+	Type        string              `json:"type"` // string, integer, number, boolean, array or object
+	Description string              `json:"description"`
+	Default     interface{}         `json:"default,omitempty"` // Any JSON value; nil means no default
+	Enum        []string            `json:"enum,omitempty"`
+	Items       *Property           `json:"items,omitempty"`
+	Properties  map[string]Property `json:"properties,omitempty"`
+	Required    []string            `json:"required,omitempty"`
 }
 
 // Response represents the generic response from LLM API
@@ -56,10 +72,27 @@ type Response struct {
 	Model      string         `json:"model"`
 	StopReason string         `json:"stop_reason"`
 	Usage      Usage          `json:"usage"`
+	Cached     bool           `json:"cached,omitempty"` // Served from a response cache
 }
 
 // Usage represents token usage
 type Usage struct {
 	InputTokens  int `json:"input_tokens"`
 	OutputTokens int `json:"output_tokens"`
+	// ThinkingTokens is the part of OutputTokens spent on reasoning
+	ThinkingTokens int `json:"thinking_tokens,omitempty"`
+	// CachedInputTokens is the part of InputTokens served from a context cache
+	CachedInputTokens int     `json:"cached_input_tokens,omitempty"`
+	EstimatedCostUSD  float64 `json:"estimated_cost_usd,omitempty"`
+}
+
+// Add returns the sum of u and other
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		InputTokens:       u.InputTokens + other.InputTokens,
+		OutputTokens:      u.OutputTokens + other.OutputTokens,
+		ThinkingTokens:    u.ThinkingTokens + other.ThinkingTokens,
+		CachedInputTokens: u.CachedInputTokens + other.CachedInputTokens,
+		EstimatedCostUSD:  u.EstimatedCostUSD + other.EstimatedCostUSD,
+	}
 }
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Email sends a digest of each run to its recipients over SMTP: the run
+// report of the new candidates, as HTML with a markdown alternative
+type Email struct {
+	// Addr is the SMTP server as host:port. STARTTLS is used when the
+	// server offers it.
+	Addr string
+	// Username and Password authenticate with PLAIN auth, if set
+	Username string
+	Password string
+	From     string
+	To       []string
+
+	// Logger receives delivery diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+
+	// sendMail delivers the message, smtp.SendMail unless replaced in tests
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (e *Email) logger() *slog.Logger {
+	if e.Logger == nil {
+		return slog.Default()
+	}
+	return e.Logger
+}
+
+// Notify emails the digest of n to the recipients
+func (e *Email) Notify(ctx context.Context, n Notification) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	msg, err := e.message(n, time.Now())
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if e.Username != "" {
+		host, _, _ := net.SplitHostPort(e.Addr)
+		auth = smtp.PlainAuth("", e.Username, e.Password, host)
+	}
+	send := e.sendMail
+	if send == nil {
+		send = smtp.SendMail
+	}
+	start := time.Now()
+	if err := send(e.Addr, auth, e.From, e.To, msg); err != nil {
+		return fmt.Errorf("failed to send email digest: %w", err)
+	}
+	e.logger().Debug("Email digest sent", "recipients", len(e.To), "duration", time.Since(start))
+	return nil
+}
+
+// message builds the MIME message of the digest of n
+func (e *Email) message(n Notification, now time.Time) ([]byte, error) {
+	// The report covers the new candidates only, like the subject says
+	result := agent.FinalResult{RunID: n.RunID}
+	if n.Result != nil {
+		result = *n.Result
+	}
+	result.TopCandidates = n.Candidates
+	report := agent.RunReport{Query: n.Query, Result: &result, GeneratedAt: now}
+	var html, text bytes.Buffer
+	if err := agent.WriteReport(&html, agent.ReportHTML, report); err != nil {
+		return nil, err
+	}
+	if err := agent.WriteReport(&text, agent.ReportMarkdown, report); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	body := multipart.NewWriter(&b)
+	header := func(key, value string) { fmt.Fprintf(&b, "%s: %s\r\n", key, value) }
+	header("From", e.From)
+	header("To", strings.Join(e.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", digestSubject(n)))
+	header("Date", now.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+body.Boundary())
+	b.WriteString("\r\n")
+	// Alternatives go from plainest to richest
+	for _, part := range []struct {
+		contentType string
+		content     []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		w, err := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write(part.content); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := body.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// digestSubject summarizes n for the subject line
+func digestSubject(n Notification) string {
+	noun := "candidates"
+	if len(n.Candidates) == 1 {
+		noun = "candidate"
+	}
+	return fmt.Sprintf("%d new %s for %q", len(n.Candidates), noun, n.Query)
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+func TestEmailDigest(t *testing.T) {
+	var sentTo []string
+	var msg []byte
+	email := &Email{
+		Addr: "smtp.example.com:587", Username: "agent", Password: "secret",
+		From: "sourcing@example.com", To: []string{"hm@example.com", "cto@example.com"},
+		sendMail: func(addr string, auth smtp.Auth, from string, to []string, m []byte) error {
+			if addr != "smtp.example.com:587" || auth == nil || from != "sourcing@example.com" {
+				t.Errorf("Expected the configured server, auth and sender, got %s %v %s", addr, auth, from)
+			}
+			sentTo, msg = to, m
+			return nil
+		},
+	}
+	n := testNotification
+	n.Result = &agent.FinalResult{
+		RunID: "run-1",
+		TopCandidates: append(testNotification.Candidates,
+			agent.RankedCandidate{Rank: 2, Username: "bob", SeenBefore: &agent.SeenBefore{RunID: "run-0"}}),
+	}
+	if err := email.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Expected the digest sent, got %v", err)
+	}
+	if len(sentTo) != 2 {
+		t.Errorf("Expected both recipients, got %v", sentTo)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(string(msg)))
+	if err != nil {
+		t.Fatalf("Expected a valid message, got %v", err)
+	}
+	subject, _ := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if subject != `2 new candidates for "Go developers <Lima>"` {
+		t.Errorf("Unexpected subject %q", subject)
+	}
+	_, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := multipart.NewReader(m.Body, params["boundary"])
+	var types []string
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(part) // Decodes quoted-printable
+		types = append(types, part.Header.Get("Content-Type"))
+		if !strings.Contains(string(content), "alice") || strings.Contains(string(content), "bob") {
+			t.Errorf("Expected the report of the new candidates only in %s, got\n%s", part.Header.Get("Content-Type"), content)
+		}
+	}
+	if len(types) != 2 || !strings.HasPrefix(types[1], "text/html") {
+		t.Errorf("Expected text and HTML alternatives, got %v", types)
+	}
+}
+
+func TestEmailSendFailure(t *testing.T) {
+	email := &Email{
+		Addr: "localhost:25", From: "sourcing@example.com", To: []string{"hm@example.com"},
+		sendMail: func(string, smtp.Auth, string, []string, []byte) error {
+			return errors.New("550 mailbox unavailable")
+		},
+	}
+	err := email.Notify(context.Background(), testNotification)
+	if err == nil || !strings.Contains(err.Error(), "550 mailbox unavailable") {
+		t.Errorf("Expected the SMTP error, got %v", err)
+	}
+}
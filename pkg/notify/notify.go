@@ -0,0 +1,56 @@
+// Package notify tells people about the candidates of finished runs, in a
+// Slack channel or an email digest, so a scheduled search reaches recruiters
+// and hiring managers who are not watching the terminal.
+package notify
+
+import (
+	"context"
+	"errors"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Notification is a finished run with the candidates worth telling about
+type Notification struct {
+	Query string
+	RunID string
+	// Candidates are the new top candidates of the run, in rank order
+	Candidates []agent.RankedCandidate
+	// Presented is the number of candidates the run presented, new or not
+	Presented int
+	// Result is the whole result of the run, for notifiers that report on
+	// more than the candidates
+	Result *agent.FinalResult
+}
+
+// Notifier delivers notifications
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Multi delivers every notification to each of its notifiers, returning
+// their failures joined
+type Multi []Notifier
+
+// Notify delivers n to each notifier, even when an earlier one fails
+func (m Multi) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NewCandidates returns the candidates of result no earlier stored run
+// surfaced, i.e. without SeenBefore; all of them when runs are not stored
+func NewCandidates(result *agent.FinalResult) []agent.RankedCandidate {
+	var candidates []agent.RankedCandidate
+	for _, c := range result.TopCandidates {
+		if c.SeenBefore == nil {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates
+}
@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// slackPostMessageURL is the Slack Web API method a bot token posts with
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// slackMaxCandidates caps the candidates listed in one message
+const slackMaxCandidates = 10
+
+// Slack posts a summary of the new top candidates to a Slack channel,
+// through an incoming webhook or, with a bot token, the chat.postMessage API
+type Slack struct {
+	// WebhookURL is an incoming webhook, which posts to the channel it was
+	// created for
+	WebhookURL string
+	// Token is a bot token with the chat:write scope, used instead of the
+	// webhook to post to Channel
+	Token   string
+	Channel string
+	// APIURL overrides slackPostMessageURL, for tests
+	APIURL string
+
+	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// httpClient returns the configured HTTP client, falling back to a client
+// with a timeout
+func (s *Slack) httpClient() *http.Client {
+	if s.HTTPClient == nil {
+		return &http.Client{Timeout: 30 * time.Second}
+	}
+	return s.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (s *Slack) logger() *slog.Logger {
+	if s.Logger == nil {
+		return slog.Default()
+	}
+	return s.Logger
+}
+
+// Notify posts the new candidates of n, one line each with their name,
+// score, top repository and profile link
+func (s *Slack) Notify(ctx context.Context, n Notification) error {
+	message := map[string]any{"text": slackText(n)}
+	endpoint := s.WebhookURL
+	if s.Token != "" {
+		message["channel"] = s.Channel
+		endpoint = s.APIURL
+		if endpoint == "" {
+			endpoint = slackPostMessageURL
+		}
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack message: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create Slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+
+	start := time.Now()
+	resp, err := s.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	s.logger().Debug("Slack request", "status", resp.StatusCode, "duration", time.Since(start))
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack returned status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	if s.Token != "" {
+		// The Web API answers 200 with ok false on failure
+		var result struct {
+			OK    bool   `json:"ok"`
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("failed to parse Slack response: %w", err)
+		}
+		if !result.OK {
+			return fmt.Errorf("slack rejected the message: %s", result.Error)
+		}
+	}
+	return nil
+}
+
+// slackText formats n in Slack's mrkdwn
+func slackText(n Notification) string {
+	var b strings.Builder
+	noun := "candidates"
+	if len(n.Candidates) == 1 {
+		noun = "candidate"
+	}
+	fmt.Fprintf(&b, "*%d new %s* for “%s”", len(n.Candidates), noun, slackEscape(n.Query))
+	if n.RunID != "" {
+		fmt.Fprintf(&b, " (run `%s`, %d presented)", n.RunID, n.Presented)
+	}
+	b.WriteString("\n")
+	for i, c := range n.Candidates {
+		if i == slackMaxCandidates {
+			fmt.Fprintf(&b, "…and %d more\n", len(n.Candidates)-i)
+			break
+		}
+		title := "@" + c.Username
+		if c.Name != "" {
+			title = c.Name + " (@" + c.Username + ")"
+		}
+		if c.GitHubURL != "" {
+			title = "<" + c.GitHubURL + "|" + slackEscape(title) + ">"
+		} else {
+			title = slackEscape(title)
+		}
+		fmt.Fprintf(&b, "%d. %s, score *%.1f*", c.Rank, title, c.FinalMatchScore)
+		if len(c.TopRelevantProjects) > 0 {
+			p := c.TopRelevantProjects[0]
+			repo := slackEscape(p.Name)
+			if p.URL != "" {
+				repo = "<" + p.URL + "|" + repo + ">"
+			}
+			b.WriteString(", top repo " + repo)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// slackEscape escapes the characters Slack's mrkdwn reserves for links and
+// mentions
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+var testNotification = Notification{
+	Query: "Go developers <Lima>",
+	RunID: "run-1",
+	Candidates: []agent.RankedCandidate{
+		{Rank: 1, Username: "alice", Name: "Alice", GitHubURL: "https://github.com/alice", FinalMatchScore: 87.5,
+			TopRelevantProjects: []agent.RelevantProject{{Name: "router", URL: "https://github.com/alice/router"}}},
+		{Rank: 3, Username: "carol", FinalMatchScore: 71},
+	},
+	Presented: 5,
+}
+
+func TestSlackWebhook(t *testing.T) {
+	var message map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&message)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	slack := &Slack{WebhookURL: server.URL}
+	if err := slack.Notify(context.Background(), testNotification); err != nil {
+		t.Fatalf("Expected the message posted, got %v", err)
+	}
+	want := "*2 new candidates* for “Go developers &lt;Lima&gt;” (run `run-1`, 5 presented)\n" +
+		"1. <https://github.com/alice|Alice (@alice)>, score *87.5*, top repo <https://github.com/alice/router|router>\n" +
+		"3. @carol, score *71.0*\n"
+	if message["text"] != want {
+		t.Errorf("Expected message\n%s\ngot\n%s", want, message["text"])
+	}
+	if _, ok := message["channel"]; ok {
+		t.Errorf("Expected no channel for a webhook, got %q", message["channel"])
+	}
+}
+
+func TestSlackToken(t *testing.T) {
+	var message map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer xoxb-1" {
+			t.Errorf("Expected the bot token, got %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&message)
+		w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+	}))
+	defer server.Close()
+
+	slack := &Slack{Token: "xoxb-1", Channel: "#sourcing", APIURL: server.URL}
+	err := slack.Notify(context.Background(), testNotification)
+	if err == nil || !strings.Contains(err.Error(), "channel_not_found") {
+		t.Errorf("Expected Slack's error, got %v", err)
+	}
+	if message["channel"] != "#sourcing" {
+		t.Errorf("Expected the message posted to #sourcing, got %+v", message)
+	}
+}
+
+// notifierFunc adapts a function to Notifier
+type notifierFunc func(ctx context.Context, n Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, n Notification) error { return f(ctx, n) }
+
+func TestMulti(t *testing.T) {
+	var delivered int
+	ok := notifierFunc(func(context.Context, Notification) error { delivered++; return nil })
+	failing := notifierFunc(func(context.Context, Notification) error { return errors.New("smtp down") })
+	err := Multi{failing, ok}.Notify(context.Background(), testNotification)
+	if delivered != 1 || err == nil || err.Error() != "smtp down" {
+		t.Errorf("Expected delivery past the failure and its error, got %d and %v", delivered, err)
+	}
+}
+
+func TestNewCandidates(t *testing.T) {
+	result := &agent.FinalResult{TopCandidates: []agent.RankedCandidate{
+		{Username: "alice", SeenBefore: &agent.SeenBefore{RunID: "run-0"}},
+		{Username: "bob"},
+	}}
+	if got := NewCandidates(result); len(got) != 1 || got[0].Username != "bob" {
+		t.Errorf("Expected only bob new, got %+v", got)
+	}
+}
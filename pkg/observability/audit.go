@@ -0,0 +1,180 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Audited resources of the source platforms
+const (
+	AuditResourceSearch       = "search"       // A developer search; returns profile summaries
+	AuditResourceProfile      = "profile"      // A user's full profile
+	AuditResourceRepositories = "repositories" // A user's public repositories
+	AuditResourceOther        = "other"
+)
+
+// AuditEntry records one access to a source platform's data
+type AuditEntry struct {
+	Time       time.Time `json:"time"`
+	RunID      string    `json:"run_id,omitempty"`
+	Query      string    `json:"query,omitempty"` // The sourcing query the access was made for
+	Resource   string    `json:"resource"`
+	Username   string    `json:"username,omitempty"` // Empty for searches
+	Method     string    `json:"method"`
+	URL        string    `json:"url"`
+	StatusCode int       `json:"status_code,omitempty"` // 0 when the request failed
+	Error      string    `json:"error,omitempty"`
+}
+
+// AuditLog is an append-only trail of every developer profile accessed by a run,
+// written as JSON lines for compliance review
+type AuditLog struct {
+	RunID string
+	Query string
+
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewAuditLog writes audit entries for the run to w
+func NewAuditLog(w io.Writer, runID, query string) *AuditLog {
+	return &AuditLog{RunID: runID, Query: query, w: w}
+}
+
+// OpenAuditLog appends audit entries for the run to the file at path,
+// creating it readable by the owner only
+func OpenAuditLog(path, runID, query string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	a := NewAuditLog(f, runID, query)
+	a.closer = f
+	return a, nil
+}
+
+// Close closes the underlying file, if the log opened one
+func (a *AuditLog) Close() error {
+	if a.closer == nil {
+		return nil
+	}
+	return a.closer.Close()
+}
+
+// Record appends entry, stamping the time, run ID and query when unset
+func (a *AuditLog) Record(entry AuditEntry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	if entry.RunID == "" {
+		entry.RunID = a.RunID
+	}
+	if entry.Query == "" {
+		entry.Query = a.Query
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, err = a.w.Write(append(line, '\n'))
+	return err
+}
+
+// auditRunKey is the context key of the run a request is made for
+type auditRunKey struct{}
+
+// auditRun is a run whose requests are audited under its ID and query
+type auditRun struct {
+	id, query string
+}
+
+// WithAuditRun returns a copy of ctx whose requests are audited as made by
+// the run, over the run the log was opened for. Runs sharing the clients,
+// as in serve and batch, pass it down with each run's context.
+func WithAuditRun(ctx context.Context, runID, query string) context.Context {
+	return context.WithValue(ctx, auditRunKey{}, auditRun{id: runID, query: query})
+}
+
+// Transport wraps transport (http.DefaultTransport if nil) so every request is audited
+func (a *AuditLog) Transport(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &auditTransport{Transport: transport, log: a}
+}
+
+// auditTransport records each request in an AuditLog. A response that could
+// not be audited is withheld, so no data is accessed without a trail.
+type auditTransport struct {
+	Transport http.RoundTripper
+	log       *AuditLog
+}
+
+func (t *auditTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, username := auditResource(req.URL)
+	entry := AuditEntry{Resource: resource, Username: username, Method: req.Method, URL: req.URL.String()}
+	if run, ok := req.Context().Value(auditRunKey{}).(auditRun); ok {
+		entry.RunID, entry.Query = run.id, run.query
+	}
+
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.StatusCode = resp.StatusCode
+	}
+
+	if auditErr := t.log.Record(entry); auditErr != nil {
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, fmt.Errorf("failed to write audit log: %w", auditErr)
+	}
+	return resp, err
+}
+
+// auditResource classifies a GitHub, GitLab, Bitbucket, Stack Exchange,
+// Hugging Face or Kaggle API URL, returning the user it concerns. GitLab
+// users are looked up by username, then by numeric ID; Bitbucket users are
+// workspaces; Stack Overflow users are searched by name; Hugging Face and
+// Kaggle work is listed by author.
+func auditResource(u *url.URL) (resource, username string) {
+	path := strings.Trim(u.Path, "/")
+	for _, prefix := range []string{"api/v4/", "api/v1/", "2.0/", "2.3/"} {
+		path = strings.TrimPrefix(path, prefix)
+	}
+	segments := strings.Split(path, "/")
+	switch {
+	case len(segments) >= 2 && segments[0] == "search",
+		len(segments) == 1 && (segments[0] == "projects" || segments[0] == "repositories"):
+		return AuditResourceSearch, ""
+	case len(segments) == 1 && segments[0] == "users" && u.Query().Has("inname"):
+		return AuditResourceSearch, ""
+	case len(segments) == 1 && segments[0] == "users" && u.Query().Has("username"):
+		return AuditResourceProfile, u.Query().Get("username")
+	case len(segments) == 2 && (segments[0] == "users" || segments[0] == "workspaces"):
+		return AuditResourceProfile, segments[1]
+	case len(segments) == 2 && segments[0] == "repositories":
+		return AuditResourceRepositories, segments[1]
+	case len(segments) == 3 && segments[0] == "users" && (segments[2] == "repos" || segments[2] == "projects"):
+		return AuditResourceRepositories, segments[1]
+	case len(segments) == 2 && segments[0] == "api" && u.Query().Has("author"):
+		return AuditResourceRepositories, u.Query().Get("author")
+	case len(segments) == 2 && segments[1] == "list" && u.Query().Has("user"):
+		return AuditResourceRepositories, u.Query().Get("user")
+	default:
+		return AuditResourceOther, ""
+	}
+}
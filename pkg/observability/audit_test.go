@@ -0,0 +1,145 @@
+package observability
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLogTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/ghost" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	audit, err := OpenAuditLog(path, "run-1", "Go developers in Lima")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	client := &http.Client{Transport: audit.Transport(nil)}
+	for _, p := range []string{"/search/users?q=language:go", "/users/gopher", "/users/gopher/repos?sort=stars", "/users/ghost"} {
+		resp, err := client.Get(server.URL + p)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+	}
+	audit.Close()
+
+	// Reopening appends rather than truncating
+	audit, err = OpenAuditLog(path, "run-2", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	audit.Record(AuditEntry{Resource: AuditResourceProfile, Username: "octocat", Method: http.MethodGet, URL: "https://api.github.com/users/octocat"})
+	audit.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Expected audit log file, got %v", err)
+	}
+	defer f.Close()
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("Expected JSON lines, got %q", scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) != 5 {
+		t.Fatalf("Expected 5 audit entries, got %+v", entries)
+	}
+	want := []struct{ resource, username string }{
+		{AuditResourceSearch, ""},
+		{AuditResourceProfile, "gopher"},
+		{AuditResourceRepositories, "gopher"},
+		{AuditResourceProfile, "ghost"},
+		{AuditResourceProfile, "octocat"},
+	}
+	for i, w := range want {
+		if entries[i].Resource != w.resource || entries[i].Username != w.username {
+			t.Errorf("Expected entry %d for %s %q, got %+v", i, w.resource, w.username, entries[i])
+		}
+	}
+	if entries[0].RunID != "run-1" || entries[0].Query != "Go developers in Lima" || entries[0].Time.IsZero() {
+		t.Errorf("Expected entry stamped with run, query and time, got %+v", entries[0])
+	}
+	if entries[3].StatusCode != http.StatusNotFound {
+		t.Errorf("Expected failed lookup audited with its status, got %+v", entries[3])
+	}
+	if entries[4].RunID != "run-2" {
+		t.Errorf("Expected appended entry for run-2, got %+v", entries[4])
+	}
+}
+
+func TestAuditTransportRunFromContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var log bytes.Buffer
+	// A log shared by several runs has none of its own
+	client := &http.Client{Transport: NewAuditLog(&log, "", "").Transport(nil)}
+	ctx := WithAuditRun(context.Background(), "run-7", "Rust developers")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/users/gopher", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	var entry AuditEntry
+	if err := json.Unmarshal(log.Bytes(), &entry); err != nil || entry.RunID != "run-7" || entry.Query != "Rust developers" {
+		t.Errorf("Expected the entry stamped with the context's run, got %+v, %v", entry, err)
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestAuditTransportWithholdsUnauditedResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewAuditLog(failingWriter{}, "run-1", "").Transport(nil)}
+	if _, err := client.Get(server.URL + "/users/gopher"); err == nil {
+		t.Error("Expected error when the audit entry cannot be written")
+	}
+}
+
+func TestAuditResourceSources(t *testing.T) {
+	tests := []struct{ url, resource, username string }{
+		{"https://gitlab.com/api/v4/projects?with_programming_language=go", AuditResourceSearch, ""},
+		{"https://gitlab.com/api/v4/users?username=gopher", AuditResourceProfile, "gopher"},
+		{"https://gitlab.com/api/v4/users/42", AuditResourceProfile, "42"},
+		{"https://gitlab.com/api/v4/users/gopher/projects?per_page=10", AuditResourceRepositories, "gopher"},
+		{"https://gitlab.com/api/v4/projects/7/languages", AuditResourceOther, ""},
+		{"https://api.bitbucket.org/2.0/repositories?q=language", AuditResourceSearch, ""},
+		{"https://api.bitbucket.org/2.0/workspaces/gopher", AuditResourceProfile, "gopher"},
+		{"https://api.bitbucket.org/2.0/repositories/gopher?pagelen=1", AuditResourceRepositories, "gopher"},
+		{"https://api.stackexchange.com/2.3/users?inname=Ana&site=stackoverflow", AuditResourceSearch, ""},
+		{"https://api.stackexchange.com/2.3/users/1?site=stackoverflow", AuditResourceProfile, "1"},
+		{"https://huggingface.co/api/models?author=gopher&sort=downloads", AuditResourceRepositories, "gopher"},
+		{"https://www.kaggle.com/api/v1/kernels/list?user=gopher", AuditResourceRepositories, "gopher"},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse(tt.url)
+		if resource, username := auditResource(u); resource != tt.resource || username != tt.username {
+			t.Errorf("Expected %s classified %s %q, got %s %q", tt.url, tt.resource, tt.username, resource, username)
+		}
+	}
+}
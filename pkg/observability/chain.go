@@ -0,0 +1,64 @@
+package observability
+
+import (
+	"net/http"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// Middleware wraps an llm.Client, e.g. with retries, caching or counting
+type Middleware func(llm.Client) llm.Client
+
+// TransportMiddleware wraps an http.RoundTripper, e.g. with auditing or counting
+type TransportMiddleware func(http.RoundTripper) http.RoundTripper
+
+// Chain wraps client in middlewares, listed outermost first: a call passes
+// through them in the order given before reaching client. Nil middlewares
+// are skipped, so optional layers can be declared in place.
+func Chain(client llm.Client, middlewares ...Middleware) llm.Client {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			client = middlewares[i](client)
+		}
+	}
+	return client
+}
+
+// ChainTransport wraps transport (http.DefaultTransport if nil) in
+// middlewares, listed outermost first. Nil middlewares are skipped.
+func ChainTransport(transport http.RoundTripper, middlewares ...TransportMiddleware) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			transport = middlewares[i](transport)
+		}
+	}
+	return transport
+}
+
+// RetryMiddleware retries transient failures, see llm.WithRetry
+func RetryMiddleware(config llm.RetryConfig) Middleware {
+	return func(c llm.Client) llm.Client { return llm.WithRetry(c, config) }
+}
+
+// RateLimitMiddleware enforces client-side quotas, see llm.WithRateLimit
+func RateLimitMiddleware(config llm.RateLimitConfig) Middleware {
+	return func(c llm.Client) llm.Client { return llm.WithRateLimit(c, config) }
+}
+
+// LoggingMiddleware logs every call, see llm.WithLogging
+func LoggingMiddleware(config llm.LoggingConfig) Middleware {
+	return func(c llm.Client) llm.Client { return llm.WithLogging(c, config) }
+}
+
+// CountingMiddleware reports every call into collector, see NewCountingLLMClient
+func CountingMiddleware(collector *UsageCollector) Middleware {
+	return func(c llm.Client) llm.Client { return NewCountingLLMClient(c, collector) }
+}
+
+// CountingTransportMiddleware reports every request into collector, see NewCountingTransport
+func CountingTransportMiddleware(collector *UsageCollector) TransportMiddleware {
+	return func(t http.RoundTripper) http.RoundTripper { return NewCountingTransport(t, collector) }
+}
@@ -0,0 +1,62 @@
+package observability
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// roundTripFunc lets tests declare transport middleware inline
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	layer := func(name string) Middleware {
+		return func(next llm.Client) llm.Client {
+			return llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+				order = append(order, name)
+				return next.CallAPI(ctx, messages, tools, opts...)
+			})
+		}
+	}
+
+	client := Chain(&stubLLMClient{resp: &llm.Response{}}, layer("retry"), nil, layer("cache"), layer("counting"))
+	if _, err := client.CallAPI(context.Background(), nil, nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := []string{"retry", "cache", "counting"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("Expected calls through %v, got %v", want, order)
+	}
+}
+
+func TestChainTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	collector := NewUsageCollector()
+	var seenByOuter bool
+	outer := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			seenByOuter = true
+			return next.RoundTrip(req)
+		})
+	}
+	client := &http.Client{Transport: ChainTransport(nil, outer, CountingTransportMiddleware(collector))}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	resp.Body.Close()
+
+	if !seenByOuter || collector.Report().HTTPRequests() != 1 {
+		t.Errorf("Expected the request through both layers, got outer=%v report=%+v", seenByOuter, collector.Report())
+	}
+}
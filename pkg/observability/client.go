@@ -1,34 +1,87 @@
 package observability
 
 import (
+	"context"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
 )
 
-// CountingTransport tracks the number of HTTP requests
+// CountingTransport reports every HTTP request into a UsageCollector, keyed
+// by host, along with the rate limit budget the host reports
 type CountingTransport struct {
 	Transport http.RoundTripper
-	Count     int
+	Collector *UsageCollector
+}
+
+// NewCountingTransport wraps transport (http.DefaultTransport if nil),
+// reporting into collector (a new one if nil)
+func NewCountingTransport(transport http.RoundTripper, collector *UsageCollector) *CountingTransport {
+	if collector == nil {
+		collector = NewUsageCollector()
+	}
+	return &CountingTransport{Transport: transport, Collector: collector}
 }
 
 func (t *CountingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	t.Count++
 	// Use default transport if nil
 	transport := t.Transport
 	if transport == nil {
 		transport = http.DefaultTransport
 	}
-	return transport.RoundTrip(req)
+	resp, err := transport.RoundTrip(req)
+
+	if t.Collector != nil {
+		status, remaining := 0, -1
+		if resp != nil {
+			status = resp.StatusCode
+			// GitHub's header, or GitLab's
+			header := resp.Header.Get("X-RateLimit-Remaining")
+			if header == "" {
+				header = resp.Header.Get("RateLimit-Remaining")
+			}
+			if n, convErr := strconv.Atoi(header); convErr == nil {
+				remaining = n
+			}
+		}
+		t.Collector.RecordRequest(req.URL.Host, status, remaining, err)
+	}
+	return resp, err
 }
 
-// CountingLLMClient tracks the number of LLM API calls
+// CountingLLMClient reports every LLM API call, with its model, usage,
+// estimated cost and latency, into a UsageCollector, keyed by the stage set
+// with llm.WithStage
 type CountingLLMClient struct {
-	Wrapped llm.Client
-	Count   int
+	Wrapped   llm.Client
+	Collector *UsageCollector
 }
 
-func (c *CountingLLMClient) CallAPI(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
-	c.Count++
-	return c.Wrapped.CallAPI(messages, tools)
+// NewCountingLLMClient wraps client, reporting into collector (a new one if nil)
+func NewCountingLLMClient(client llm.Client, collector *UsageCollector) *CountingLLMClient {
+	if collector == nil {
+		collector = NewUsageCollector()
+	}
+	return &CountingLLMClient{Wrapped: client, Collector: collector}
 }
+
+func (c *CountingLLMClient) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	start := time.Now()
+	resp, err := c.Wrapped.CallAPI(ctx, messages, tools, opts...)
+
+	call := LLMCall{Stage: llm.ApplyOptions(opts).Stage, Duration: time.Since(start)}
+	if err != nil {
+		call.Error = err.Error()
+		call.ErrorClass = ClassifyError(err)
+	} else if resp != nil {
+		call.Model = resp.Model
+		call.Usage = resp.Usage
+	}
+	c.Collector.RecordCall(call)
+	return resp, err
+}
+
+// Unwrap returns the wrapped client
+func (c *CountingLLMClient) Unwrap() llm.Client { return c.Wrapped }
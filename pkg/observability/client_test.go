@@ -0,0 +1,147 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+type stubLLMClient struct {
+	resp *llm.Response
+	err  error
+}
+
+func (s *stubLLMClient) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	return s.resp, s.err
+}
+
+func TestCountingLLMClient(t *testing.T) {
+	stub := &stubLLMClient{resp: &llm.Response{
+		Usage: llm.Usage{InputTokens: 100, OutputTokens: 20, EstimatedCostUSD: 0.01},
+	}}
+	client := NewCountingLLMClient(stub, nil)
+
+	client.CallAPI(context.Background(), nil, nil, llm.WithStage("ranking"))
+	client.CallAPI(context.Background(), nil, nil, llm.WithStage("ranking"))
+	client.CallAPI(context.Background(), nil, nil)
+
+	stages := client.Collector.Stages()
+	if len(stages) != 2 {
+		t.Fatalf("Expected 2 stages, got %+v", stages)
+	}
+	if stages[0].Stage != "ranking" || stages[0].Calls != 2 || stages[0].Usage.InputTokens != 200 {
+		t.Errorf("Expected 2 ranking calls with 200 input tokens, got %+v", stages[0])
+	}
+	if stages[1].Stage != UnlabeledStage || stages[1].Calls != 1 {
+		t.Errorf("Expected 1 unlabeled call, got %+v", stages[1])
+	}
+
+	total := client.Collector.Total()
+	if total.Calls != 3 || total.Usage.OutputTokens != 60 {
+		t.Errorf("Expected 3 calls and 60 output tokens, got %+v", total)
+	}
+	if math.Abs(total.Usage.EstimatedCostUSD-0.03) > 1e-9 {
+		t.Errorf("Expected cost 0.03, got %f", total.Usage.EstimatedCostUSD)
+	}
+}
+
+func TestUsageCollector(t *testing.T) {
+	t.Run("CountsErrors", func(t *testing.T) {
+		collector := NewUsageCollector()
+		client := NewCountingLLMClient(&stubLLMClient{err: errors.New("boom")}, collector)
+		client.CallAPI(context.Background(), nil, nil, llm.WithStage("strategy"))
+
+		stages := collector.Stages()
+		if len(stages) != 1 || stages[0].Calls != 1 || stages[0].Errors != 1 {
+			t.Errorf("Expected one failed strategy call, got %+v", stages)
+		}
+	})
+
+	t.Run("SumsThinkingAndCachedTokens", func(t *testing.T) {
+		collector := NewUsageCollector()
+		collector.Record("ranking", llm.Usage{InputTokens: 100, OutputTokens: 50, ThinkingTokens: 30, CachedInputTokens: 80}, nil)
+		collector.Record("ranking", llm.Usage{InputTokens: 100, OutputTokens: 50, ThinkingTokens: 20}, nil)
+
+		total := collector.Total()
+		if total.Usage.ThinkingTokens != 50 || total.Usage.CachedInputTokens != 80 || total.Usage.OutputTokens != 100 {
+			t.Errorf("Expected 50 thinking, 80 cached and 100 output tokens, got %+v", total.Usage)
+		}
+	})
+
+	t.Run("SharedAcrossClients", func(t *testing.T) {
+		collector := NewUsageCollector()
+		stub := &stubLLMClient{resp: &llm.Response{Usage: llm.Usage{InputTokens: 1}}}
+		a := NewCountingLLMClient(stub, collector)
+		b := NewCountingLLMClient(stub, collector)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() { defer wg.Done(); a.CallAPI(context.Background(), nil, nil, llm.WithStage("x")) }()
+			go func() { defer wg.Done(); b.CallAPI(context.Background(), nil, nil, llm.WithStage("x")) }()
+		}
+		wg.Wait()
+
+		if total := collector.Total(); total.Calls != 100 || total.Usage.InputTokens != 100 {
+			t.Errorf("Expected 100 calls and tokens, got %+v", total)
+		}
+	})
+}
+
+func TestCountingLLMClientRecordsModels(t *testing.T) {
+	collector := NewUsageCollector()
+	flash := NewCountingLLMClient(&stubLLMClient{resp: &llm.Response{Model: "gemini-2.5-flash", Usage: llm.Usage{InputTokens: 10, EstimatedCostUSD: 0.001}}}, collector)
+	pro := NewCountingLLMClient(&stubLLMClient{resp: &llm.Response{Model: "gemini-2.5-pro", Usage: llm.Usage{InputTokens: 20, EstimatedCostUSD: 0.01}}}, collector)
+	failing := NewCountingLLMClient(&stubLLMClient{err: errors.New("boom")}, collector)
+
+	flash.CallAPI(context.Background(), nil, nil, llm.WithStage("requirements"))
+	flash.CallAPI(context.Background(), nil, nil, llm.WithStage("strategy"))
+	pro.CallAPI(context.Background(), nil, nil, llm.WithStage("ranking"))
+	failing.CallAPI(context.Background(), nil, nil, llm.WithStage("ranking"))
+
+	report := collector.Report()
+	if len(report.Models) != 2 || report.Models[0].Model != "gemini-2.5-flash" || report.Models[0].Calls != 2 || report.Models[1].Usage.InputTokens != 20 {
+		t.Errorf("Expected usage per model, got %+v", report.Models)
+	}
+	if len(report.Calls) != 4 || report.Calls[2].Model != "gemini-2.5-pro" || report.Calls[3].Error != "boom" {
+		t.Errorf("Expected every call recorded in order, got %+v", report.Calls)
+	}
+	if report.Total.Calls != 4 || report.Total.Errors != 1 || math.Abs(report.Total.Usage.EstimatedCostUSD-0.012) > 1e-9 {
+		t.Errorf("Expected 4 calls, 1 error and $0.012, got %+v", report.Total)
+	}
+}
+
+func TestCountingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+	}))
+	defer server.Close()
+
+	transport := NewCountingTransport(nil, nil)
+	client := &http.Client{Transport: transport}
+	for _, path := range []string{"/users/a", "/users/b", "/missing"} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	report := transport.Collector.Report()
+	if len(report.HTTP) != 1 || report.HTTPRequests() != 3 || report.HTTP[0].Errors != 1 {
+		t.Fatalf("Expected 3 requests with 1 error to one host, got %+v", report.HTTP)
+	}
+	if remaining := report.HTTP[0].RateLimitRemaining; remaining == nil || *remaining != 4999 {
+		t.Errorf("Expected rate limit remaining 4999, got %v", remaining)
+	}
+}
@@ -0,0 +1,139 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/luillyfe/sourcing-agent/pkg/bitbucket"
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/gitlab"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// ErrorClass is a failure mode in the taxonomy used to count failures across runs
+type ErrorClass string
+
+const (
+	ErrorClassLLMParse           ErrorClass = "llm_parse"            // The model's output could not be decoded
+	ErrorClassLLMQuota           ErrorClass = "llm_quota"            // Rate limited or quota exhausted by the provider
+	ErrorClassLLMUnavailable     ErrorClass = "llm_unavailable"      // Provider 5xx, overload or timeout
+	ErrorClassLLMError           ErrorClass = "llm_error"            // Any other provider rejection, e.g. a bad request
+	ErrorClassGitHubRateLimit    ErrorClass = "github_rate_limit"    // Primary or secondary GitHub rate limit
+	ErrorClassGitHubServer       ErrorClass = "github_server"        // GitHub 5xx
+	ErrorClassGitHubError        ErrorClass = "github_error"         // Any other GitHub rejection, e.g. a missing user
+	ErrorClassGitLabRateLimit    ErrorClass = "gitlab_rate_limit"    // GitLab rate limit
+	ErrorClassGitLabServer       ErrorClass = "gitlab_server"        // GitLab 5xx
+	ErrorClassGitLabError        ErrorClass = "gitlab_error"         // Any other GitLab rejection
+	ErrorClassBitbucketRateLimit ErrorClass = "bitbucket_rate_limit" // Bitbucket rate limit
+	ErrorClassBitbucketServer    ErrorClass = "bitbucket_server"     // Bitbucket 5xx
+	ErrorClassBitbucketError     ErrorClass = "bitbucket_error"      // Any other Bitbucket rejection
+	ErrorClassValidation         ErrorClass = "validation"           // Decoded output failed validation
+	ErrorClassCancelled          ErrorClass = "cancelled"            // The run was cancelled or ran out of time
+	ErrorClassOther              ErrorClass = "other"
+)
+
+// ClassifiedError tags an error with its class where the class cannot be
+// told from the error's type, such as decoded output that failed validation
+type ClassifiedError struct {
+	Class ErrorClass
+	Err   error
+}
+
+func (e *ClassifiedError) Error() string { return e.Err.Error() }
+
+func (e *ClassifiedError) Unwrap() error { return e.Err }
+
+// WithErrorClass tags err with class. It returns nil for a nil err.
+func WithErrorClass(class ErrorClass, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &ClassifiedError{Class: class, Err: err}
+}
+
+// ClassifyError returns the class of err, or "" for a nil err. Explicit tags
+// win over provider and source API errors, which win over cancellation.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ""
+	}
+
+	var classified *ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.Class
+	}
+
+	var decodeErr *llm.DecodeError
+	if errors.As(err, &decodeErr) {
+		return ErrorClassLLMParse
+	}
+
+	var llmErr *llm.APIError
+	if errors.As(err, &llmErr) {
+		switch {
+		case llmErr.StatusCode == http.StatusTooManyRequests,
+			llmErr.Status == "RESOURCE_EXHAUSTED",
+			llmErr.Status == "rate_limit_error":
+			return ErrorClassLLMQuota
+		case llm.IsTransient(llmErr):
+			return ErrorClassLLMUnavailable
+		default:
+			return ErrorClassLLMError
+		}
+	}
+	if errors.Is(err, llm.ErrCallTimeout) {
+		return ErrorClassLLMUnavailable
+	}
+
+	var githubErr *github.APIError
+	if errors.As(err, &githubErr) {
+		return classifyGitHubError(githubErr)
+	}
+	var gitlabErr *gitlab.APIError
+	if errors.As(err, &gitlabErr) {
+		return classifyGitLabError(gitlabErr)
+	}
+	var bitbucketErr *bitbucket.APIError
+	if errors.As(err, &bitbucketErr) {
+		return classifyBitbucketError(bitbucketErr)
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassCancelled
+	}
+	return ErrorClassOther
+}
+
+func classifyGitHubError(err *github.APIError) ErrorClass {
+	switch {
+	case err.RateLimited():
+		return ErrorClassGitHubRateLimit
+	case err.StatusCode >= 500:
+		return ErrorClassGitHubServer
+	default:
+		return ErrorClassGitHubError
+	}
+}
+
+func classifyGitLabError(err *gitlab.APIError) ErrorClass {
+	switch {
+	case err.RateLimited():
+		return ErrorClassGitLabRateLimit
+	case err.StatusCode >= 500:
+		return ErrorClassGitLabServer
+	default:
+		return ErrorClassGitLabError
+	}
+}
+
+func classifyBitbucketError(err *bitbucket.APIError) ErrorClass {
+	switch {
+	case err.RateLimited():
+		return ErrorClassBitbucketRateLimit
+	case err.StatusCode >= 500:
+		return ErrorClassBitbucketServer
+	default:
+		return ErrorClassBitbucketError
+	}
+}
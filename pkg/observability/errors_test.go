@@ -0,0 +1,76 @@
+package observability
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/bitbucket"
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/gitlab"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want ErrorClass
+	}{
+		{"Nil", nil, ""},
+		{"Tagged", fmt.Errorf("step 1: %w", WithErrorClass(ErrorClassValidation, errors.New("empty skills"))), ErrorClassValidation},
+		{"Undecodable", fmt.Errorf("ranking: %w", &llm.DecodeError{Content: "not json", Err: errors.New("bad json")}), ErrorClassLLMParse},
+		{"LLMRateLimit", &llm.APIError{Provider: "Anthropic", StatusCode: http.StatusTooManyRequests}, ErrorClassLLMQuota},
+		{"VertexQuota", &llm.APIError{Provider: "Vertex AI", StatusCode: http.StatusForbidden, Status: "RESOURCE_EXHAUSTED"}, ErrorClassLLMQuota},
+		{"LLMOverloaded", fmt.Errorf("call: %w", &llm.APIError{Provider: "Anthropic", StatusCode: 529}), ErrorClassLLMUnavailable},
+		{"LLMTimeout", llm.ErrCallTimeout, ErrorClassLLMUnavailable},
+		{"LLMBadRequest", &llm.APIError{Provider: "Anthropic", StatusCode: http.StatusBadRequest}, ErrorClassLLMError},
+		{"GitHubRateLimit", &github.APIError{StatusCode: http.StatusForbidden, RateLimitRemaining: 0}, ErrorClassGitHubRateLimit},
+		{"GitHubServer", fmt.Errorf("search: %w", &github.APIError{StatusCode: http.StatusBadGateway, RateLimitRemaining: -1}), ErrorClassGitHubServer},
+		{"GitHubNotFound", &github.APIError{StatusCode: http.StatusNotFound, RateLimitRemaining: 10}, ErrorClassGitHubError},
+		{"GitLabRateLimit", &gitlab.APIError{StatusCode: http.StatusTooManyRequests, RateLimitRemaining: -1}, ErrorClassGitLabRateLimit},
+		{"GitLabServer", &gitlab.APIError{StatusCode: http.StatusServiceUnavailable, RateLimitRemaining: -1}, ErrorClassGitLabServer},
+		{"BitbucketRateLimit", fmt.Errorf("search: %w", &bitbucket.APIError{StatusCode: http.StatusTooManyRequests}), ErrorClassBitbucketRateLimit},
+		{"Cancelled", fmt.Errorf("run: %w", context.Canceled), ErrorClassCancelled},
+		{"Other", errors.New("boom"), ErrorClassOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUsageCollectorFailures(t *testing.T) {
+	collector := NewUsageCollector()
+	collector.Record("ranking", llm.Usage{}, &llm.APIError{Provider: "Anthropic", StatusCode: http.StatusTooManyRequests})
+	collector.Record("ranking", llm.Usage{}, &llm.APIError{Provider: "Anthropic", StatusCode: http.StatusTooManyRequests})
+	collector.RecordRequest("api.github.com", http.StatusForbidden, 0, nil)
+	collector.RecordRequest("api.github.com", http.StatusOK, 10, nil)
+
+	bus := NewEventBus(collector.Subscriber())
+	bus.Publish(StageFailed{Stage: "ranking", Err: WithErrorClass(ErrorClassLLMParse, errors.New("bad json"))})
+	bus.Publish(StageStarted{Stage: "ranking"})
+
+	want := []FailureCount{
+		{Source: FailureSourceHTTP, Class: ErrorClassGitHubRateLimit, Count: 1},
+		{Source: FailureSourceLLM, Class: ErrorClassLLMQuota, Count: 2},
+		{Source: FailureSourceStage, Class: ErrorClassLLMParse, Count: 1},
+	}
+	got := collector.Report().Failures
+	if len(got) != len(want) {
+		t.Fatalf("Expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], got[i])
+		}
+	}
+	if calls := collector.Report().Calls; calls[0].ErrorClass != ErrorClassLLMQuota {
+		t.Errorf("Expected failed call classified, got %+v", calls[0])
+	}
+}
@@ -0,0 +1,223 @@
+package observability
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Event is a pipeline lifecycle event published on an EventBus
+type Event interface {
+	EventName() string
+}
+
+// StageStarted is published when a pipeline stage begins
+type StageStarted struct {
+	Stage string
+}
+
+// StageFailed is published when a pipeline stage fails, whether the run
+// then aborts or falls back
+type StageFailed struct {
+	Stage string
+	Err   error
+}
+
+// SearchExecuted is published after each GitHub developer search
+type SearchExecuted struct {
+	Language string
+	Location string
+	Keywords string
+	Fallback int // 0 for the primary search, otherwise the 1-based fallback strategy
+	Results  int
+	Err      error
+}
+
+// CandidateEnriched is published after a candidate's repositories were fetched and analyzed
+type CandidateEnriched struct {
+	Username             string
+	RelevantRepositories int
+	Err                  error // Set when the candidate was skipped
+}
+
+// ExternalProfileLookup is published after an enricher looked a candidate
+// up on another platform, e.g. Stack Overflow
+type ExternalProfileLookup struct {
+	Enricher string
+	Username string
+	Found    int   // External profiles added to the candidate
+	Err      error // Set when the lookup failed; the run goes on without it
+}
+
+// ProfilesMerged is published when identity resolution took profiles on
+// two source platforms to be the same person
+type ProfilesMerged struct {
+	Username   string // The candidate kept
+	Merged     string // The profile merged into it, e.g. gitlab:ana
+	Confidence float64
+	Signals    []string // What matched, e.g. email, name
+}
+
+// FallbackUsed is published when a stage falls back to a degraded strategy
+type FallbackUsed struct {
+	Stage  string
+	Reason string
+	Err    error // The failure that caused the fallback, if any
+}
+
+// BudgetWarning is published when a payload exceeded its token budget and was trimmed
+type BudgetWarning struct {
+	Stage        string
+	BudgetTokens int
+	Kept         int // Candidates kept after trimming
+	Candidates   int // Candidates before trimming
+}
+
+func (StageStarted) EventName() string          { return "stage_started" }
+func (StageFailed) EventName() string           { return "stage_failed" }
+func (SearchExecuted) EventName() string        { return "search_executed" }
+func (CandidateEnriched) EventName() string     { return "candidate_enriched" }
+func (ExternalProfileLookup) EventName() string { return "external_profile_lookup" }
+func (ProfilesMerged) EventName() string        { return "profiles_merged" }
+func (FallbackUsed) EventName() string          { return "fallback_used" }
+func (BudgetWarning) EventName() string         { return "budget_warning" }
+
+// Subscriber receives published events. It runs on the publishing goroutine,
+// so it must be quick and must not publish on the same bus.
+type Subscriber func(Event)
+
+// EventBus fans pipeline events out to its subscribers. A nil *EventBus
+// discards events, so publishers need not check for one.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []Subscriber
+}
+
+// NewEventBus returns a bus delivering to subscribers
+func NewEventBus(subscribers ...Subscriber) *EventBus {
+	return &EventBus{subscribers: subscribers}
+}
+
+// Subscribe adds a subscriber for all subsequent events
+func (b *EventBus) Subscribe(subscriber Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscriber)
+}
+
+// Publish delivers event to every subscriber in subscription order
+func (b *EventBus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+	for _, subscriber := range subscribers {
+		subscriber(event)
+	}
+}
+
+// LogSubscriber logs each event to logger, warning on failures and fallbacks
+func LogSubscriber(logger *slog.Logger) Subscriber {
+	return func(event Event) {
+		level, msg := slog.LevelInfo, event.EventName()
+		var attrs []any
+		switch e := event.(type) {
+		case StageStarted:
+			msg = "Stage started"
+			attrs = []any{"stage", e.Stage}
+		case StageFailed:
+			level, msg = slog.LevelWarn, "Stage failed"
+			attrs = []any{"stage", e.Stage, "error_class", ClassifyError(e.Err), "error", e.Err}
+		case SearchExecuted:
+			level, msg = slog.LevelDebug, "Search executed"
+			attrs = []any{"language", e.Language, "location", e.Location, "keywords", e.Keywords, "fallback", e.Fallback, "results", e.Results}
+			if e.Err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, "error", e.Err)
+			}
+		case CandidateEnriched:
+			level, msg = slog.LevelDebug, "Candidate enriched"
+			attrs = []any{"username", e.Username, "relevant_repositories", e.RelevantRepositories}
+			if e.Err != nil {
+				level, msg = slog.LevelWarn, "Candidate skipped"
+				attrs = []any{"username", e.Username, "error", e.Err}
+			}
+		case ExternalProfileLookup:
+			level, msg = slog.LevelDebug, "External profiles looked up"
+			attrs = []any{"enricher", e.Enricher, "username", e.Username, "found", e.Found}
+			if e.Err != nil {
+				level, msg = slog.LevelWarn, "External profile lookup failed"
+				attrs = []any{"enricher", e.Enricher, "username", e.Username, "error", e.Err}
+			}
+		case ProfilesMerged:
+			msg = "Profiles merged"
+			attrs = []any{"username", e.Username, "merged", e.Merged, "confidence", e.Confidence, "signals", e.Signals}
+		case FallbackUsed:
+			msg = "Fallback used"
+			attrs = []any{"stage", e.Stage, "reason", e.Reason}
+			if e.Err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, "error", e.Err)
+			}
+		case BudgetWarning:
+			level, msg = slog.LevelWarn, "Token budget exceeded, payload trimmed"
+			attrs = []any{"stage", e.Stage, "budget_tokens", e.BudgetTokens, "kept", e.Kept, "candidates", e.Candidates}
+		}
+		logger.Log(context.Background(), level, msg, attrs...)
+	}
+}
+
+// EventCounter is a metrics subscriber counting events by name
+type EventCounter struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// Subscriber returns the subscriber feeding the counter
+func (c *EventCounter) Subscriber() Subscriber {
+	return func(event Event) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.counts == nil {
+			c.counts = make(map[string]int)
+		}
+		c.counts[event.EventName()]++
+	}
+}
+
+// Counts returns a copy of the event counts, keyed by EventName
+func (c *EventCounter) Counts() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := make(map[string]int, len(c.counts))
+	for name, n := range c.counts {
+		counts[name] = n
+	}
+	return counts
+}
+
+// SearchLog is a subscriber keeping every SearchExecuted event, e.g. for a run report
+type SearchLog struct {
+	mu       sync.Mutex
+	searches []SearchExecuted
+}
+
+// Subscriber returns the subscriber feeding the log
+func (l *SearchLog) Subscriber() Subscriber {
+	return func(event Event) {
+		if search, ok := event.(SearchExecuted); ok {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.searches = append(l.searches, search)
+		}
+	}
+}
+
+// Searches returns a copy of the searches executed so far, in order
+func (l *SearchLog) Searches() []SearchExecuted {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]SearchExecuted(nil), l.searches...)
+}
@@ -0,0 +1,56 @@
+package observability
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEventBusFansOut(t *testing.T) {
+	var got []string
+	bus := NewEventBus(func(e Event) { got = append(got, "first:"+e.EventName()) })
+	counter := &EventCounter{}
+	bus.Subscribe(counter.Subscriber())
+	bus.Subscribe(func(e Event) { got = append(got, "second:"+e.EventName()) })
+
+	bus.Publish(StageStarted{Stage: "ranking"})
+	bus.Publish(FallbackUsed{Stage: "ranking"})
+
+	want := "first:stage_started second:stage_started first:fallback_used second:fallback_used"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected %q, got %q", want, strings.Join(got, " "))
+	}
+	if counts := counter.Counts(); counts["stage_started"] != 1 || counts["fallback_used"] != 1 {
+		t.Errorf("Expected one of each event counted, got %v", counts)
+	}
+
+	// A nil bus discards events
+	var nilBus *EventBus
+	nilBus.Publish(StageStarted{Stage: "ranking"})
+}
+
+func TestLogSubscriber(t *testing.T) {
+	var buf bytes.Buffer
+	bus := NewEventBus(LogSubscriber(slog.New(slog.NewTextHandler(&buf, nil))))
+
+	bus.Publish(StageStarted{Stage: "strategy"})
+	bus.Publish(CandidateEnriched{Username: "gopher", RelevantRepositories: 2}) // Debug, filtered out
+	bus.Publish(CandidateEnriched{Username: "octocat", Err: errors.New("not found")})
+	bus.Publish(BudgetWarning{Stage: "ranking", BudgetTokens: 100, Kept: 3, Candidates: 10})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], `level=INFO msg="Stage started" stage=strategy`) {
+		t.Errorf("Expected stage started line, got %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "level=WARN") || !strings.Contains(lines[1], "username=octocat") {
+		t.Errorf("Expected warning for skipped candidate, got %q", lines[1])
+	}
+	if !strings.Contains(lines[2], "level=WARN") || !strings.Contains(lines[2], "kept=3 candidates=10") {
+		t.Errorf("Expected budget warning, got %q", lines[2])
+	}
+}
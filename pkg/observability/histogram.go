@@ -0,0 +1,193 @@
+package observability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// DefaultTokenBuckets are the upper bounds of the token histogram buckets,
+// doubling from 256 up to 128k tokens
+var DefaultTokenBuckets = []int{256, 512, 1024, 2048, 4096, 8192, 16384, 32768, 65536, 131072}
+
+// minQuantileSamples is how many observations a histogram needs before its
+// quantiles are trusted to flag regressions
+const minQuantileSamples = 20
+
+// Histogram counts observations into fixed buckets. Counts has one more entry
+// than Buckets for observations above the last bound.
+type Histogram struct {
+	Buckets []int `json:"buckets"`
+	Counts  []int `json:"counts"`
+	Count   int   `json:"count"`
+	Sum     int   `json:"sum"`
+	Min     int   `json:"min"`
+	Max     int   `json:"max"`
+}
+
+// NewHistogram returns an empty histogram with the given ascending bucket bounds
+func NewHistogram(buckets []int) *Histogram {
+	return &Histogram{Buckets: append([]int(nil), buckets...), Counts: make([]int, len(buckets)+1)}
+}
+
+// Observe adds one value
+func (h *Histogram) Observe(v int) {
+	i := sort.SearchInts(h.Buckets, v)
+	h.Counts[i]++
+	if h.Count == 0 || v < h.Min {
+		h.Min = v
+	}
+	if v > h.Max {
+		h.Max = v
+	}
+	h.Count++
+	h.Sum += v
+}
+
+// Mean returns the average observation, or 0 when empty
+func (h *Histogram) Mean() float64 {
+	if h.Count == 0 {
+		return 0
+	}
+	return float64(h.Sum) / float64(h.Count)
+}
+
+// Quantile returns an upper bound for the q quantile (0 < q <= 1): the bound
+// of the bucket holding it, capped at the largest observation
+func (h *Histogram) Quantile(q float64) int {
+	if h.Count == 0 {
+		return 0
+	}
+	rank := int(q*float64(h.Count) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	seen := 0
+	for i, n := range h.Counts {
+		seen += n
+		if seen >= rank && i < len(h.Buckets) {
+			return min(h.Buckets[i], h.Max)
+		}
+	}
+	return h.Max
+}
+
+func (h *Histogram) clone() *Histogram {
+	c := *h
+	c.Buckets = append([]int(nil), h.Buckets...)
+	c.Counts = append([]int(nil), h.Counts...)
+	return &c
+}
+
+// StageTokenHistograms is the token distribution of one stage's LLM calls
+type StageTokenHistograms struct {
+	Stage  string     `json:"stage"`
+	Input  *Histogram `json:"input"`
+	Output *Histogram `json:"output"`
+}
+
+// TokenHistograms tracks input and output token distributions per stage
+// across runs, so prompt growth (e.g. a bloated ranking payload) shows up
+// as a shifted distribution. It is safe for concurrent use.
+type TokenHistograms struct {
+	mu     sync.Mutex
+	stages map[string]*StageTokenHistograms
+}
+
+// NewTokenHistograms returns empty histograms
+func NewTokenHistograms() *TokenHistograms {
+	return &TokenHistograms{stages: make(map[string]*StageTokenHistograms)}
+}
+
+// Observe adds one successful call's usage to stage
+func (t *TokenHistograms) Observe(stage string, usage llm.Usage) {
+	if stage == "" {
+		stage = UnlabeledStage
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stages[stage]
+	if !ok {
+		s = &StageTokenHistograms{Stage: stage, Input: NewHistogram(DefaultTokenBuckets), Output: NewHistogram(DefaultTokenBuckets)}
+		t.stages[stage] = s
+	}
+	s.Input.Observe(usage.InputTokens)
+	s.Output.Observe(usage.OutputTokens)
+}
+
+// ObserveReport adds every successful call of a run's report
+func (t *TokenHistograms) ObserveReport(report Report) {
+	for _, call := range report.Calls {
+		if call.Error == "" {
+			t.Observe(call.Stage, call.Usage)
+		}
+	}
+}
+
+// Stages returns a snapshot of the histograms sorted by stage
+func (t *TokenHistograms) Stages() []StageTokenHistograms {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stages := make([]StageTokenHistograms, 0, len(t.stages))
+	for _, s := range t.stages {
+		stages = append(stages, StageTokenHistograms{Stage: s.Stage, Input: s.Input.clone(), Output: s.Output.clone()})
+	}
+	sort.Slice(stages, func(i, j int) bool { return stages[i].Stage < stages[j].Stage })
+	return stages
+}
+
+// InputAbove reports whether inputTokens exceeds the q quantile of stage's
+// input distribution, returning that quantile. Stages with too few
+// observations never report a regression.
+func (t *TokenHistograms) InputAbove(stage string, inputTokens int, q float64) (int, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stages[stage]
+	if !ok || s.Input.Count < minQuantileSamples {
+		return 0, false
+	}
+	threshold := s.Input.Quantile(q)
+	return threshold, inputTokens > threshold
+}
+
+// LoadTokenHistograms reads histograms saved with Save. A missing file
+// yields empty histograms, so the first run starts the history.
+func LoadTokenHistograms(path string) (*TokenHistograms, error) {
+	t := NewTokenHistograms()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token histograms: %w", err)
+	}
+	var stages []StageTokenHistograms
+	if err := json.Unmarshal(data, &stages); err != nil {
+		return nil, fmt.Errorf("failed to parse token histograms: %w", err)
+	}
+	for _, s := range stages {
+		if s.Input == nil || s.Output == nil {
+			continue
+		}
+		s := s
+		t.stages[s.Stage] = &s
+	}
+	return t, nil
+}
+
+// Save writes the histograms to path as JSON
+func (t *TokenHistograms) Save(path string) error {
+	data, err := json.MarshalIndent(t.Stages(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,65 @@
+package observability
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]int{100, 200, 400})
+	for _, v := range []int{50, 150, 150, 180, 390, 1000} {
+		h.Observe(v)
+	}
+
+	if h.Count != 6 || h.Min != 50 || h.Max != 1000 || h.Mean() != 320 {
+		t.Errorf("Expected count 6, min 50, max 1000, mean 320, got %+v (mean %.1f)", h, h.Mean())
+	}
+	if want := []int{1, 3, 1, 1}; len(h.Counts) != 4 || h.Counts[0] != want[0] || h.Counts[1] != want[1] || h.Counts[2] != want[2] || h.Counts[3] != want[3] {
+		t.Errorf("Expected bucket counts %v, got %v", want, h.Counts)
+	}
+	if q := h.Quantile(0.5); q != 200 {
+		t.Errorf("Expected median bound 200, got %d", q)
+	}
+	if q := h.Quantile(1); q != 1000 {
+		t.Errorf("Expected overflow quantile capped at max 1000, got %d", q)
+	}
+}
+
+func TestTokenHistogramsAcrossRuns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+
+	// Twenty earlier runs with a steady ranking prompt
+	for run := 0; run < minQuantileSamples; run++ {
+		histograms, err := LoadTokenHistograms(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		collector := NewUsageCollector()
+		collector.Record("ranking", llm.Usage{InputTokens: 3000, OutputTokens: 800}, nil)
+		collector.Record("ranking", llm.Usage{}, &llm.APIError{StatusCode: 500}) // Failed calls are not observed
+		histograms.ObserveReport(collector.Report())
+		if err := histograms.Save(path); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	histograms, err := LoadTokenHistograms(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	stages := histograms.Stages()
+	if len(stages) != 1 || stages[0].Stage != "ranking" || stages[0].Input.Count != minQuantileSamples {
+		t.Fatalf("Expected %d ranking observations, got %+v", minQuantileSamples, stages)
+	}
+	if threshold, above := histograms.InputAbove("ranking", 24000, 0.95); !above || threshold != 3000 {
+		t.Errorf("Expected a bloated ranking prompt flagged above p95 3000, got %d, %v", threshold, above)
+	}
+	if _, above := histograms.InputAbove("ranking", 2900, 0.95); above {
+		t.Error("Expected a typical ranking prompt not flagged")
+	}
+	if _, above := histograms.InputAbove("strategy", 24000, 0.95); above {
+		t.Error("Expected a stage without history not flagged")
+	}
+}
@@ -0,0 +1,76 @@
+package observability
+
+// Labels are the dimensions of a metric sample, e.g. {"stage": "ranking"}
+type Labels map[string]string
+
+// Metrics receives the agent's telemetry. Implementations adapt it to a
+// monitoring stack, e.g. PrometheusMetrics or StatsDMetrics, and must be
+// safe for concurrent use.
+type Metrics interface {
+	// Counter adds value to a monotonically increasing counter
+	Counter(name string, value float64, labels Labels)
+	// Gauge sets the current value of a gauge
+	Gauge(name string, value float64, labels Labels)
+	// Histogram observes value into a distribution
+	Histogram(name string, value float64, labels Labels)
+}
+
+// NoopMetrics discards every sample
+type NoopMetrics struct{}
+
+func (NoopMetrics) Counter(string, float64, Labels)   {}
+func (NoopMetrics) Gauge(string, float64, Labels)     {}
+func (NoopMetrics) Histogram(string, float64, Labels) {}
+
+// Metric names emitted by MetricsSubscriber and RecordReportMetrics
+const (
+	MetricEvents           = "sourcing_events_total"
+	MetricStageFailures    = "sourcing_stage_failures_total"
+	MetricSearchResults    = "sourcing_search_results"
+	MetricLLMCalls         = "sourcing_llm_calls_total"
+	MetricLLMCallSeconds   = "sourcing_llm_call_duration_seconds"
+	MetricLLMTokens        = "sourcing_llm_tokens_total"
+	MetricLLMCostUSD       = "sourcing_llm_cost_usd_total"
+	MetricHTTPRequests     = "sourcing_http_requests_total"
+	MetricHTTPErrors       = "sourcing_http_errors_total"
+	MetricRateLimitBalance = "sourcing_http_rate_limit_remaining"
+)
+
+// MetricsSubscriber counts pipeline events into metrics as they are published
+func MetricsSubscriber(metrics Metrics) Subscriber {
+	return func(e Event) {
+		metrics.Counter(MetricEvents, 1, Labels{"event": e.EventName()})
+		switch e := e.(type) {
+		case StageFailed:
+			metrics.Counter(MetricStageFailures, 1, Labels{"stage": e.Stage, "class": string(ClassifyError(e.Err))})
+		case SearchExecuted:
+			if e.Err == nil {
+				metrics.Histogram(MetricSearchResults, float64(e.Results), nil)
+			}
+		}
+	}
+}
+
+// RecordReportMetrics emits a run's LLM and HTTP usage into metrics
+func RecordReportMetrics(metrics Metrics, report Report) {
+	for _, call := range report.Calls {
+		status := "ok"
+		if call.Error != "" {
+			status = "error"
+		}
+		metrics.Counter(MetricLLMCalls, 1, Labels{"stage": call.Stage, "status": status})
+		metrics.Histogram(MetricLLMCallSeconds, call.Duration.Seconds(), Labels{"stage": call.Stage})
+	}
+	for _, stage := range report.Stages {
+		metrics.Counter(MetricLLMTokens, float64(stage.Usage.InputTokens), Labels{"stage": stage.Stage, "direction": "input"})
+		metrics.Counter(MetricLLMTokens, float64(stage.Usage.OutputTokens), Labels{"stage": stage.Stage, "direction": "output"})
+		metrics.Counter(MetricLLMCostUSD, stage.Usage.EstimatedCostUSD, Labels{"stage": stage.Stage})
+	}
+	for _, host := range report.HTTP {
+		metrics.Counter(MetricHTTPRequests, float64(host.Requests), Labels{"host": host.Host})
+		metrics.Counter(MetricHTTPErrors, float64(host.Errors), Labels{"host": host.Host})
+		if host.RateLimitRemaining != nil {
+			metrics.Gauge(MetricRateLimitBalance, float64(*host.RateLimitRemaining), Labels{"host": host.Host})
+		}
+	}
+}
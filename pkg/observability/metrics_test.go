@@ -0,0 +1,99 @@
+package observability
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.Counter("runs_total", 1, nil)
+	metrics.Counter("runs_total", 2, nil)
+	metrics.Gauge("rate_limit", 29, Labels{"host": "api.github.com"})
+	metrics.Histogram("latency_seconds", 0.3, Labels{"stage": "ranking"})
+	metrics.Histogram("latency_seconds", 7, Labels{"stage": "ranking"})
+
+	var buf bytes.Buffer
+	metrics.WriteTo(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		"# TYPE runs_total counter\nruns_total 3\n",
+		`rate_limit{host="api.github.com"} 29`,
+		"# TYPE latency_seconds histogram\n",
+		`latency_seconds_bucket{stage="ranking",le="0.25"} 0`,
+		`latency_seconds_bucket{stage="ranking",le="0.5"} 1`,
+		`latency_seconds_bucket{stage="ranking",le="10"} 2`,
+		`latency_seconds_bucket{stage="ranking",le="+Inf"} 2`,
+		`latency_seconds_sum{stage="ranking"} 7.3`,
+		`latency_seconds_count{stage="ranking"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected exposition to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Body.String() != out {
+		t.Errorf("Expected the handler to serve the same exposition, got:\n%s", rec.Body.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "sourcing.prom")
+	if err := metrics.WriteFile(path); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != out {
+		t.Errorf("Expected the textfile to hold the exposition, got:\n%s", data)
+	}
+}
+
+func TestStatsDMetrics(t *testing.T) {
+	var buf bytes.Buffer
+	metrics := &StatsDMetrics{W: &buf, Prefix: "team."}
+	metrics.Counter("calls_total", 1, Labels{"status": "ok", "stage": "ranking"})
+	metrics.Gauge("rate_limit", 29, nil)
+	metrics.Histogram("latency_seconds", 0.25, nil)
+
+	want := "team.calls_total:1|c|#stage:ranking,status:ok\nteam.rate_limit:29|g\nteam.latency_seconds:0.25|h\n"
+	if buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestMetricsSubscriberAndReport(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	bus := NewEventBus(MetricsSubscriber(metrics))
+	bus.Publish(StageStarted{Stage: "ranking"})
+	bus.Publish(StageFailed{Stage: "ranking", Err: &llm.DecodeError{Err: errors.New("bad json")}})
+	bus.Publish(SearchExecuted{Results: 4})
+
+	collector := NewUsageCollector()
+	collector.RecordCall(LLMCall{Stage: "ranking", Duration: 2 * time.Second, Usage: llm.Usage{InputTokens: 100, OutputTokens: 20}})
+	collector.RecordRequest("api.github.com", 200, 29, nil)
+	RecordReportMetrics(metrics, collector.Report())
+
+	var buf bytes.Buffer
+	metrics.WriteTo(&buf)
+	out := buf.String()
+	for _, want := range []string{
+		`sourcing_events_total{event="stage_failed"} 1`,
+		`sourcing_stage_failures_total{class="llm_parse",stage="ranking"} 1`,
+		`sourcing_search_results_count 1`,
+		`sourcing_llm_calls_total{stage="ranking",status="ok"} 1`,
+		`sourcing_llm_tokens_total{direction="input",stage="ranking"} 100`,
+		`sourcing_http_requests_total{host="api.github.com"} 1`,
+		`sourcing_http_rate_limit_remaining{host="api.github.com"} 29`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected exposition to contain %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package observability
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProgressReporter shows the progress of a long-running step, such as
+// enriching candidates one GitHub profile at a time
+type ProgressReporter interface {
+	// Update reports that done of total items of step are complete
+	Update(step string, done, total int)
+	// Finish ends the display of step
+	Finish(step string)
+}
+
+// ItemReporter is implemented by progress reporters that also show the item
+// being worked on, such as the username being enriched
+type ItemReporter interface {
+	// UpdateItem reports that done of total items of step are complete and
+	// item is in progress
+	UpdateItem(step string, done, total int, item string)
+}
+
+// UpdateItem reports progress to p, naming item if p can show it
+func UpdateItem(p ProgressReporter, step string, done, total int, item string) {
+	if r, ok := p.(ItemReporter); ok {
+		r.UpdateItem(step, done, total, item)
+		return
+	}
+	p.Update(step, done, total)
+}
+
+// NoopProgress discards progress, for non-interactive runs
+type NoopProgress struct{}
+
+func (NoopProgress) Update(step string, done, total int) {}
+func (NoopProgress) Finish(step string)                  {}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// progressBarWidth is the number of cells in the terminal progress bar
+const progressBarWidth = 20
+
+// TerminalProgress redraws a single spinner and progress bar line with an
+// ETA and the current item, e.g.
+// "⠹ Enriching candidates [########------------] 6/15 ETA 12s gopher_lima".
+// Write it to stderr only when stderr is a terminal.
+type TerminalProgress struct {
+	W   io.Writer
+	Now func() time.Time // Defaults to time.Now
+
+	mu      sync.Mutex
+	step    string
+	started time.Time
+	frame   int
+}
+
+// NewTerminalProgress returns a reporter drawing on w
+func NewTerminalProgress(w io.Writer) *TerminalProgress {
+	return &TerminalProgress{W: w}
+}
+
+func (p *TerminalProgress) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+	return p.Now()
+}
+
+func (p *TerminalProgress) Update(step string, done, total int) {
+	p.UpdateItem(step, done, total, "")
+}
+
+func (p *TerminalProgress) UpdateItem(step string, done, total int, item string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if step != p.step {
+		p.step, p.started, p.frame = step, p.now(), 0
+	}
+	line := spinnerFrames[p.frame%len(spinnerFrames)] + " " + step
+	p.frame++
+
+	if total > 0 {
+		filled := progressBarWidth * done / total
+		if filled > progressBarWidth {
+			filled = progressBarWidth
+		}
+		line += fmt.Sprintf(" [%s%s] %d/%d", strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), done, total)
+		if eta, ok := p.eta(done, total); ok {
+			line += " ETA " + eta.String()
+		}
+	}
+	if item != "" {
+		line += " " + item
+	}
+	// Return to the line start and clear it before redrawing
+	fmt.Fprint(p.W, "\r\033[K"+line)
+}
+
+// eta extrapolates the time left from the average time per completed item
+func (p *TerminalProgress) eta(done, total int) (time.Duration, bool) {
+	if done <= 0 || done >= total {
+		return 0, false
+	}
+	perItem := p.now().Sub(p.started) / time.Duration(done)
+	return (perItem * time.Duration(total-done)).Round(time.Second), true
+}
+
+func (p *TerminalProgress) Finish(step string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if step != p.step {
+		return
+	}
+	p.step = ""
+	fmt.Fprint(p.W, "\r\033[K")
+}
@@ -0,0 +1,57 @@
+package observability
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTerminalProgress(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 11, 20, 10, 0, 0, 0, time.UTC)
+	progress := NewTerminalProgress(&buf)
+	progress.Now = func() time.Time { return now }
+
+	progress.Update("Enriching candidates", 0, 10)
+	now = now.Add(8 * time.Second)
+	progress.Update("Enriching candidates", 4, 10)
+
+	lines := strings.Split(buf.String(), "\r\033[K")
+	last := lines[len(lines)-1]
+	want := "⠙ Enriching candidates [########------------] 4/10 ETA 12s"
+	if last != want {
+		t.Errorf("Expected %q, got %q", want, last)
+	}
+	if strings.Contains(lines[1], "ETA") {
+		t.Errorf("Expected no ETA before any item completed, got %q", lines[1])
+	}
+
+	progress.Finish("Enriching candidates")
+	if !strings.HasSuffix(buf.String(), "\r\033[K") {
+		t.Errorf("Expected the line cleared on finish, got %q", buf.String())
+	}
+}
+
+func TestTerminalProgressItem(t *testing.T) {
+	var buf bytes.Buffer
+	progress := NewTerminalProgress(&buf)
+
+	UpdateItem(progress, "Enriching candidates", 0, 2, "gopher_lima")
+	if want := "⠋ Enriching candidates [--------------------] 0/2 gopher_lima"; !strings.HasSuffix(buf.String(), want) {
+		t.Errorf("Expected line ending %q, got %q", want, buf.String())
+	}
+
+	// Reporters that cannot show items still get the count
+	var counted int
+	UpdateItem(countingProgress(func(done int) { counted = done }), "Enriching candidates", 1, 2, "rustacean_pe")
+	if counted != 1 {
+		t.Errorf("Expected Update with 1 done, got %d", counted)
+	}
+}
+
+// countingProgress records the done count of each Update
+type countingProgress func(done int)
+
+func (f countingProgress) Update(step string, done, total int) { f(done) }
+func (f countingProgress) Finish(step string)                  {}
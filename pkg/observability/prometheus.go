@@ -0,0 +1,188 @@
+package observability
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultPrometheusBuckets are the histogram bucket bounds used unless
+// PrometheusMetrics.Buckets overrides them for a metric. They suit latencies
+// in seconds as well as small counts.
+var DefaultPrometheusBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 25, 50, 100}
+
+// PrometheusMetrics keeps samples in memory and exposes them in the
+// Prometheus text format, either scraped over HTTP (it is an http.Handler)
+// or written to a node_exporter textfile collector for short-lived runs
+type PrometheusMetrics struct {
+	// Buckets overrides DefaultPrometheusBuckets per metric name
+	Buckets map[string][]float64
+
+	mu     sync.Mutex
+	series map[string]*promSeries // Keyed by name and rendered labels
+	kinds  map[string]string      // Metric name to counter, gauge or histogram
+}
+
+type promSeries struct {
+	name   string
+	labels string
+	value  float64 // Counter or gauge value, histogram sum
+	counts []uint64
+	count  uint64
+}
+
+// NewPrometheusMetrics returns an empty registry
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{series: map[string]*promSeries{}, kinds: map[string]string{}}
+}
+
+func (p *PrometheusMetrics) Counter(name string, value float64, labels Labels) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.get("counter", name, labels).value += value
+}
+
+func (p *PrometheusMetrics) Gauge(name string, value float64, labels Labels) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.get("gauge", name, labels).value = value
+}
+
+func (p *PrometheusMetrics) Histogram(name string, value float64, labels Labels) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.get("histogram", name, labels)
+	if s.counts == nil {
+		s.counts = make([]uint64, len(p.buckets(name)))
+	}
+	for i, bound := range p.buckets(name) {
+		if value <= bound {
+			s.counts[i]++
+		}
+	}
+	s.count++
+	s.value += value
+}
+
+func (p *PrometheusMetrics) buckets(name string) []float64 {
+	if b, ok := p.Buckets[name]; ok {
+		return b
+	}
+	return DefaultPrometheusBuckets
+}
+
+// get returns the series for name and labels, creating it as kind. A name
+// keeps the kind it was first used with, as Prometheus requires.
+func (p *PrometheusMetrics) get(kind, name string, labels Labels) *promSeries {
+	if _, ok := p.kinds[name]; !ok {
+		p.kinds[name] = kind
+	}
+	rendered := renderPromLabels(labels)
+	key := name + rendered
+	s, ok := p.series[key]
+	if !ok {
+		s = &promSeries{name: name, labels: rendered}
+		p.series[key] = s
+	}
+	return s
+}
+
+// WriteTo writes every series in the Prometheus text exposition format
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]string, 0, len(p.series))
+	for k := range p.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	typed := map[string]bool{}
+	for _, k := range keys {
+		s := p.series[k]
+		kind := p.kinds[s.name]
+		if !typed[s.name] {
+			fmt.Fprintf(&b, "# TYPE %s %s\n", s.name, kind)
+			typed[s.name] = true
+		}
+		if kind != "histogram" {
+			fmt.Fprintf(&b, "%s%s %s\n", s.name, s.labels, formatPromValue(s.value))
+			continue
+		}
+		for i, bound := range p.buckets(s.name) {
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", s.name, withPromLabel(s.labels, "le", formatPromValue(bound)), s.counts[i])
+		}
+		fmt.Fprintf(&b, "%s_bucket%s %d\n", s.name, withPromLabel(s.labels, "le", "+Inf"), s.count)
+		fmt.Fprintf(&b, "%s_sum%s %s\n", s.name, s.labels, formatPromValue(s.value))
+		fmt.Fprintf(&b, "%s_count%s %d\n", s.name, s.labels, s.count)
+	}
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// WriteFile atomically replaces path with the current samples, as the
+// node_exporter textfile collector expects
+func (p *PrometheusMetrics) WriteFile(path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".metrics-*")
+	if err != nil {
+		return fmt.Errorf("failed to create metrics file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := p.WriteTo(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write metrics file: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// ServeHTTP serves the samples for scraping
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	p.WriteTo(w)
+}
+
+func renderPromLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + strconv.Quote(labels[name])
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func withPromLabel(rendered, name, value string) string {
+	label := name + "=" + strconv.Quote(value)
+	if rendered == "" {
+		return "{" + label + "}"
+	}
+	return rendered[:len(rendered)-1] + "," + label + "}"
+}
+
+func formatPromValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
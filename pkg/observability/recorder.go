@@ -0,0 +1,367 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// Files written to a recording directory
+const (
+	RunFile  = "run.json"  // RunMetadata
+	LLMFile  = "llm.json"  // llm.Recording, loadable with llm.NewReplayClient
+	HTTPFile = "http.json" // HTTPRecording
+)
+
+// RunMetadata describes a recorded run
+type RunMetadata struct {
+	RunID     string    `json:"run_id,omitempty"`
+	Query     string    `json:"query"`
+	StartedAt time.Time `json:"started_at"`
+	Redacted  bool      `json:"redacted,omitempty"`
+	// Source is the platform developers were sourced from; empty for GitHub
+	Source string `json:"source,omitempty"`
+	// Enrichers names the enrichers that looked candidates up, in order
+	Enrichers []string `json:"enrichers,omitempty"`
+}
+
+// HTTPInteraction is a recorded HTTP request and its response. Request
+// headers are never recorded so credentials stay out of recordings.
+type HTTPInteraction struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// HTTPRecording is the format of HTTPFile
+type HTTPRecording struct {
+	Interactions []HTTPInteraction `json:"interactions"`
+}
+
+// recordedHeaders are the response headers kept in recordings
+var recordedHeaders = []string{"Content-Type", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "RateLimit-Remaining", "X-Total"}
+
+// RecorderConfig configures a Recorder
+type RecorderConfig struct {
+	Dir   string // Created if missing
+	RunID string // Correlates the recording with the run's logs and result
+	Query string // Saved so the run can be replayed
+	// Source names the source platform, e.g. "gitlab"; empty for GitHub
+	Source string
+	// Enrichers names the enrichers of the run, e.g. "stackoverflow"
+	Enrichers []string
+	// Redact is applied to every string in recorded prompts, responses and
+	// HTTP bodies, e.g. llm.RedactPII. Nil records content verbatim. Redacted
+	// prompts no longer match their recorded keys, so replays fall back to
+	// serving responses in recorded order.
+	Redact func(string) string
+	// Logger receives failures to record, which leave the run going.
+	// Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Recorder persists every LLM call and HTTP request of a run to a directory
+// for debugging, and for replaying the run with LoadRun. Files are rewritten
+// after each interaction so a crashed run still leaves its recording behind.
+type Recorder struct {
+	Config RecorderConfig
+
+	mu   sync.Mutex
+	llm  llm.Recording
+	http HTTPRecording
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (r *Recorder) logger() *slog.Logger {
+	if r.Config.Logger == nil {
+		return slog.Default()
+	}
+	return r.Config.Logger
+}
+
+// NewRecorder creates the recording directory and writes the run metadata
+func NewRecorder(config RecorderConfig) (*Recorder, error) {
+	if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create recording directory: %w", err)
+	}
+	r := &Recorder{Config: config}
+	meta := RunMetadata{RunID: config.RunID, Query: config.Query, StartedAt: time.Now().UTC(), Redacted: config.Redact != nil, Source: config.Source, Enrichers: config.Enrichers}
+	if err := r.write(RunFile, meta); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Client wraps client so its successful calls are recorded
+func (r *Recorder) Client(client llm.Client) llm.Client {
+	return &recordingLLMClient{Wrapped: client, recorder: r}
+}
+
+// Transport wraps transport (http.DefaultTransport if nil) so its responses are recorded
+func (r *Recorder) Transport(transport http.RoundTripper) http.RoundTripper {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &recordingTransport{Transport: transport, recorder: r}
+}
+
+func (r *Recorder) recordLLM(interaction llm.Interaction) error {
+	// Keys, URLs and metadata are left alone so replays still match
+	if r.Config.Redact != nil {
+		interaction.Messages = redacted(interaction.Messages, r.Config.Redact)
+		interaction.Response = redacted(interaction.Response, r.Config.Redact)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.llm.Interactions = append(r.llm.Interactions, interaction)
+	return r.write(LLMFile, r.llm)
+}
+
+func (r *Recorder) recordHTTP(interaction HTTPInteraction) error {
+	if r.Config.Redact != nil {
+		if json.Valid([]byte(interaction.Body)) {
+			interaction.Body = string(redactJSON([]byte(interaction.Body), r.Config.Redact))
+		} else {
+			interaction.Body = r.Config.Redact(interaction.Body)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.http.Interactions = append(r.http.Interactions, interaction)
+	return r.write(HTTPFile, r.http)
+}
+
+// write saves v as indented JSON to name in the recording directory
+func (r *Recorder) write(name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	return os.WriteFile(filepath.Join(r.Config.Dir, name), append(data, '\n'), 0o644)
+}
+
+// redacted returns a copy of v with redact applied to every string in it.
+// The copy is decoded fresh so nothing is shared with the caller's value.
+func redacted[T any](v T, redact func(string) string) T {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var out T
+	if err := json.Unmarshal(redactJSON(data, redact), &out); err != nil {
+		return v
+	}
+	return out
+}
+
+// redactJSON applies redact to every string value in a JSON document,
+// leaving its structure intact. Documents that are not JSON are returned as is.
+func redactJSON(data []byte, redact func(string) string) []byte {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data
+	}
+	out, err := json.Marshal(redactValue(doc, redact))
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+func redactValue(v interface{}, redact func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return redact(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = redactValue(v[i], redact)
+		}
+	case map[string]interface{}:
+		for k := range v {
+			if str, ok := v[k].(string); ok {
+				v[k] = redactField(k, str, redact)
+			} else {
+				v[k] = redactValue(v[k], redact)
+			}
+		}
+	}
+	return v
+}
+
+// redactField redacts a string value together with its key, so redactors
+// keyed on field names (e.g. llm.Redaction.Names) see it in context
+func redactField(key, value string, redact func(string) string) string {
+	fragment, err := json.Marshal(map[string]string{key: value})
+	if err != nil {
+		return redact(value)
+	}
+	var out map[string]string
+	if err := json.Unmarshal([]byte(redact(string(fragment))), &out); err != nil {
+		return redact(value)
+	}
+	if redactedValue, ok := out[key]; ok {
+		return redactedValue
+	}
+	return redact(value)
+}
+
+// recordingLLMClient records the calls of a run into a Recorder
+type recordingLLMClient struct {
+	Wrapped  llm.Client
+	recorder *Recorder
+}
+
+func (c *recordingLLMClient) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	resp, err := c.Wrapped.CallAPI(ctx, messages, tools, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	options := llm.ApplyOptions(opts)
+	key, err := llm.CacheKey("", messages, tools, options)
+	if err != nil {
+		return resp, nil
+	}
+	if err := c.recorder.recordLLM(llm.Interaction{
+		Key:      key,
+		Messages: messages,
+		Tools:    tools,
+		Options:  &options,
+		Response: resp,
+	}); err != nil {
+		// A broken recording must not fail the run it is recording
+		c.recorder.logger().Warn("LLM call not recorded", "error", err)
+	}
+	return resp, nil
+}
+
+// Unwrap returns the wrapped client
+func (c *recordingLLMClient) Unwrap() llm.Client { return c.Wrapped }
+
+// recordingTransport records the HTTP responses of a run into a Recorder
+type recordingTransport struct {
+	Transport http.RoundTripper
+	recorder  *Recorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := http.Header{}
+	for _, name := range recordedHeaders {
+		if v := resp.Header.Get(name); v != "" {
+			header.Set(name, v)
+		}
+	}
+	if err := t.recorder.recordHTTP(HTTPInteraction{
+		Method:     req.Method,
+		URL:        req.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     header,
+		Body:       string(body),
+	}); err != nil {
+		t.recorder.logger().Warn("HTTP request not recorded", "error", err)
+	}
+	return resp, nil
+}
+
+// RecordedRun is a run loaded from a recording directory, ready to be
+// replayed through the pipeline without calling any provider or GitHub
+type RecordedRun struct {
+	Meta RunMetadata
+	// LLM serves the recorded responses, matched by request content and
+	// falling back to recorded order
+	LLM *llm.ReplayClient
+	// HTTP serves the recorded responses, matched by method and URL
+	HTTP *ReplayTransport
+}
+
+// LoadRun loads the recording in dir
+func LoadRun(dir string) (*RecordedRun, error) {
+	var meta RunMetadata
+	if err := readJSON(filepath.Join(dir, RunFile), &meta); err != nil {
+		return nil, err
+	}
+	run := &RecordedRun{Meta: meta, HTTP: &ReplayTransport{}}
+
+	llmPath := filepath.Join(dir, LLMFile)
+	if _, err := os.Stat(llmPath); err == nil {
+		if run.LLM, err = llm.NewReplayClient(llmPath); err != nil {
+			return nil, err
+		}
+	} else {
+		run.LLM = &llm.ReplayClient{}
+	}
+
+	httpPath := filepath.Join(dir, HTTPFile)
+	if _, err := os.Stat(httpPath); err == nil {
+		if err := readJSON(httpPath, &run.HTTP.recording); err != nil {
+			return nil, err
+		}
+		run.HTTP.used = make([]bool, len(run.HTTP.recording.Interactions))
+	}
+	return run, nil
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read recording: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse recording %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReplayTransport serves recorded HTTP responses. Each recorded response is
+// served once, in recorded order, to the request with the same method and URL.
+type ReplayTransport struct {
+	mu        sync.Mutex
+	recording HTTPRecording
+	used      []bool
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, in := range t.recording.Interactions {
+		if t.used[i] || in.Method != req.Method || in.URL != req.URL.String() {
+			continue
+		}
+		t.used[i] = true
+		return &http.Response{
+			StatusCode: in.StatusCode,
+			Status:     fmt.Sprintf("%d %s", in.StatusCode, http.StatusText(in.StatusCode)),
+			Header:     in.Header.Clone(),
+			Body:       io.NopCloser(bytes.NewReader([]byte(in.Body))),
+			Request:    req,
+		}, nil
+	}
+	return nil, fmt.Errorf("replay: no recorded response for %s %s", req.Method, req.URL)
+}
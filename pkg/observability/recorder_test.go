@@ -0,0 +1,125 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestRecorderRecordsAndReplaysRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "29")
+		w.Write([]byte(`{"login": "dev", "email": "dev@example.com", "public_repos": 12345678901}`))
+	}))
+	defer server.Close()
+
+	dir := filepath.Join(t.TempDir(), "run")
+	recorder, err := NewRecorder(RecorderConfig{Dir: dir, Query: "Go developers", Redact: llm.RedactPII})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	httpClient := &http.Client{Transport: recorder.Transport(nil)}
+	resp, err := httpClient.Get(server.URL + "/users/dev")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "dev@example.com") {
+		t.Errorf("Expected caller to receive the unredacted body, got %s", body)
+	}
+
+	stub := &stubLLMClient{resp: &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: "write to dev@example.com"}}}}
+	client := recorder.Client(stub)
+	if _, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("rank")}, nil, llm.WithStage("ranking")); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, name := range []string{HTTPFile, LLMFile} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("Expected %s written, got %v", name, err)
+		}
+		if strings.Contains(string(data), "dev@example.com") {
+			t.Errorf("Expected email redacted from %s, got %s", name, data)
+		}
+	}
+
+	run, err := LoadRun(dir)
+	if err != nil {
+		t.Fatalf("Expected no error loading run, got %v", err)
+	}
+	if run.Meta.Query != "Go developers" || !run.Meta.Redacted {
+		t.Errorf("Expected redacted run metadata for the query, got %+v", run.Meta)
+	}
+
+	replayed, err := (&http.Client{Transport: run.HTTP}).Get(server.URL + "/users/dev")
+	if err != nil {
+		t.Fatalf("Expected recorded response, got %v", err)
+	}
+	body, _ = io.ReadAll(replayed.Body)
+	replayed.Body.Close()
+	// Numbers survive redaction untouched so the body still decodes
+	if !strings.Contains(string(body), `"public_repos":12345678901`) || replayed.Header.Get("X-RateLimit-Remaining") != "29" {
+		t.Errorf("Expected recorded body and headers, got %s %v", body, replayed.Header)
+	}
+	if _, err := (&http.Client{Transport: run.HTTP}).Get(server.URL + "/users/dev"); err == nil {
+		t.Error("Expected error once the recorded response was used")
+	}
+
+	llmResp, err := run.LLM.CallAPI(context.Background(), []llm.Message{llm.UserText("rank")}, nil)
+	if err != nil {
+		t.Fatalf("Expected recorded LLM response, got %v", err)
+	}
+	if len(llmResp.Content) != 1 || strings.Contains(llmResp.Content[0].Text, "dev@example.com") {
+		t.Errorf("Expected redacted recorded response, got %+v", llmResp.Content)
+	}
+}
+
+func TestRecorderSkipsFailedCalls(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(RecorderConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	client := recorder.Client(&stubLLMClient{err: io.ErrUnexpectedEOF})
+	if _, err := client.CallAPI(context.Background(), nil, nil); err == nil {
+		t.Fatal("Expected error to pass through")
+	}
+	if _, err := os.Stat(filepath.Join(dir, LLMFile)); !os.IsNotExist(err) {
+		t.Errorf("Expected no LLM recording for a failed call, got %v", err)
+	}
+
+	run, err := LoadRun(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.LLM.Remaining() != 0 {
+		t.Errorf("Expected empty LLM replay, got %d interactions", run.LLM.Remaining())
+	}
+}
+
+func TestRedactJSONNameFields(t *testing.T) {
+	body := []byte(`{"login":"anaq","name":"Ana Quispe","public_repos":32,"repos":[{"name":"tiny-kv"}]}`)
+	var got map[string]interface{}
+	if err := json.Unmarshal(redactJSON(body, llm.RedactPII), &got); err != nil {
+		t.Fatalf("Expected redacted body to decode, got %v", err)
+	}
+	if got["login"] != "[NAME]" || got["name"] != "[NAME]" {
+		t.Errorf("Expected login and name redacted, got %v", got)
+	}
+	if got["public_repos"] != float64(32) {
+		t.Errorf("Expected numbers untouched, got %v", got["public_repos"])
+	}
+}
@@ -0,0 +1,213 @@
+package observability
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// maxSnippetLength caps payload snippets attached to error reports
+const maxSnippetLength = 1000
+
+// ErrorReport is a pipeline failure with the context needed to triage it
+type ErrorReport struct {
+	Err   error
+	Stage string
+	RunID string
+	// Extra holds payload snippets, e.g. the model output that failed to
+	// parse. Reporters sanitize them before sending.
+	Extra map[string]string
+}
+
+// ErrorReporter sends pipeline failures to an error tracking service
+type ErrorReporter interface {
+	ReportError(ctx context.Context, report ErrorReport) error
+}
+
+// SentryReporter sends error reports to Sentry, or any service accepting
+// Sentry's store API, as configured by a DSN
+type SentryReporter struct {
+	Environment string
+	Release     string
+	HTTPClient  *http.Client
+	// Redact sanitizes error messages and snippets. Defaults to llm.RedactPII
+	// so candidate data never leaves the process.
+	Redact func(string) string
+
+	storeURL string
+	auth     string
+}
+
+// NewSentryReporter parses a DSN of the form https://<key>@<host>/<project>
+func NewSentryReporter(dsn string) (*SentryReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sentry DSN: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, errors.New("invalid sentry DSN: missing public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 || path[i+1:] == "" {
+		return nil, errors.New("invalid sentry DSN: missing project ID")
+	}
+	prefix, project := path[:i], path[i+1:]
+
+	auth := "Sentry sentry_version=7, sentry_client=sourcing-agent/1.0, sentry_key=" + u.User.Username()
+	if secret, ok := u.User.Password(); ok {
+		auth += ", sentry_secret=" + secret
+	}
+	return &SentryReporter{
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+		storeURL:   fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, prefix, project),
+		auth:       auth,
+	}, nil
+}
+
+func (r *SentryReporter) redact(s string) string {
+	if r.Redact == nil {
+		return llm.RedactPII(s)
+	}
+	return r.Redact(s)
+}
+
+// sentryEvent is the subset of the Sentry event payload the reporter sends
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Message     sentryMessage     `json:"message"`
+	Exception   sentryExceptions  `json:"exception"`
+	Tags        map[string]string `json:"tags"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+type sentryMessage struct {
+	Formatted string `json:"formatted"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// ReportError sends report as a Sentry event, tagged with its stage, error
+// class and run ID. Messages and snippets are sanitized and truncated.
+func (r *SentryReporter) ReportError(ctx context.Context, report ErrorReport) error {
+	id := make([]byte, 16)
+	rand.Read(id)
+	class := ClassifyError(report.Err)
+	message := r.redact(report.Err.Error())
+
+	event := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Level:       "error",
+		Platform:    "go",
+		Logger:      "sourcing-agent",
+		Environment: r.Environment,
+		Release:     r.Release,
+		Message:     sentryMessage{Formatted: message},
+		Exception:   sentryExceptions{Values: []sentryException{{Type: string(class), Value: message}}},
+		Tags:        map[string]string{"error_class": string(class)},
+	}
+	if report.Stage != "" {
+		event.Tags["stage"] = report.Stage
+	}
+	if report.RunID != "" {
+		event.Tags["run_id"] = report.RunID
+	}
+	extra := report.Extra
+	var decodeErr *llm.DecodeError
+	if errors.As(report.Err, &decodeErr) {
+		extra = map[string]string{"model_output": decodeErr.Content}
+		for k, v := range report.Extra {
+			extra[k] = v
+		}
+	}
+	if len(extra) > 0 {
+		event.Extra = make(map[string]string, len(extra))
+		for k, v := range extra {
+			event.Extra[k] = snippet(r.redact(v), maxSnippetLength)
+		}
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.storeURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	client := r.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send error report: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("error report rejected with status %d: %s", resp.StatusCode, msg)
+	}
+	return nil
+}
+
+// snippet truncates s to at most n bytes on a rune boundary, marking the cut
+func snippet(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	cut := n
+	for cut > 0 && !isRuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…[truncated]"
+}
+
+func isRuneStart(b byte) bool { return b&0xC0 != 0x80 }
+
+// ErrorReportSubscriber reports every StageFailed event of run runID. Reports
+// are sent synchronously so a failure that ends the run is delivered before
+// the process exits; send errors are logged to logger, slog.Default() if nil.
+func ErrorReportSubscriber(reporter ErrorReporter, runID string, logger *slog.Logger) Subscriber {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return func(event Event) {
+		e, ok := event.(StageFailed)
+		if !ok || e.Err == nil {
+			return
+		}
+		if err := reporter.ReportError(context.Background(), ErrorReport{Err: e.Err, Stage: e.Stage, RunID: runID}); err != nil {
+			logger.Warn("Error report not sent", "run_id", runID, "stage", e.Stage, "error", err)
+		}
+	}
+}
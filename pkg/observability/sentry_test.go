@@ -0,0 +1,59 @@
+package observability
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestSentryReporter(t *testing.T) {
+	var event sentryEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/sentry/api/42/store/" {
+			t.Errorf("Expected store endpoint for project 42, got %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("X-Sentry-Auth"); !strings.Contains(auth, "sentry_key=public") || !strings.Contains(auth, "sentry_version=7") {
+			t.Errorf("Expected sentry auth header with the DSN key, got %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("Expected JSON event, got %v", err)
+		}
+	}))
+	defer server.Close()
+
+	reporter, err := NewSentryReporter(strings.Replace(server.URL, "http://", "http://public@", 1) + "/sentry/42")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	reporter.Environment = "test"
+
+	output := `Top pick: gopher (gopher@example.com) ` + strings.Repeat("x", 2*maxSnippetLength)
+	bus := NewEventBus(ErrorReportSubscriber(reporter, "run-7", nil))
+	bus.Publish(StageFailed{Stage: "ranking", Err: fmt.Errorf("failed to parse final result JSON: %w",
+		&llm.DecodeError{Content: output, Err: errors.New("invalid character 'T'")})})
+
+	if event.Tags["stage"] != "ranking" || event.Tags["error_class"] != string(ErrorClassLLMParse) || event.Tags["run_id"] != "run-7" {
+		t.Errorf("Expected stage, class and run tags, got %v", event.Tags)
+	}
+	if event.Environment != "test" || len(event.EventID) != 32 || event.Level != "error" {
+		t.Errorf("Expected a complete event, got %+v", event)
+	}
+	got := event.Extra["model_output"]
+	if !strings.HasPrefix(got, "Top pick: gopher ([EMAIL])") || !strings.HasSuffix(got, "…[truncated]") {
+		t.Errorf("Expected a redacted, truncated model output snippet, got %.60q", got)
+	}
+}
+
+func TestNewSentryReporterInvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"https://sentry.example.com/42", "https://key@sentry.example.com/", "://"} {
+		if _, err := NewSentryReporter(dsn); err == nil {
+			t.Errorf("Expected error for DSN %q", dsn)
+		}
+	}
+}
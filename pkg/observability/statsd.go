@@ -0,0 +1,66 @@
+package observability
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// StatsDMetrics sends every sample as a StatsD line, with labels as
+// DogStatsD-style tags, e.g. "sourcing_llm_calls_total:1|c|#stage:ranking,status:ok"
+type StatsDMetrics struct {
+	W      io.Writer // One Write per sample, e.g. a UDP connection
+	Prefix string    // Prepended to every metric name, e.g. "recruiting."
+
+	mu sync.Mutex
+}
+
+// NewStatsDMetrics sends samples over UDP to addr (host:port)
+func NewStatsDMetrics(addr, prefix string) (*StatsDMetrics, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to StatsD at %s: %w", addr, err)
+	}
+	return &StatsDMetrics{W: conn, Prefix: prefix}, nil
+}
+
+func (s *StatsDMetrics) Counter(name string, value float64, labels Labels) {
+	s.send(name, value, "c", labels)
+}
+
+func (s *StatsDMetrics) Gauge(name string, value float64, labels Labels) {
+	s.send(name, value, "g", labels)
+}
+
+func (s *StatsDMetrics) Histogram(name string, value float64, labels Labels) {
+	s.send(name, value, "h", labels)
+}
+
+// Close closes the underlying writer if it is closable
+func (s *StatsDMetrics) Close() error {
+	if c, ok := s.W.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// send writes one sample. StatsD is fire-and-forget, so write errors are
+// dropped rather than failing the run.
+func (s *StatsDMetrics) send(name string, value float64, kind string, labels Labels) {
+	line := s.Prefix + name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + kind
+	if len(labels) > 0 {
+		tags := make([]string, 0, len(labels))
+		for k, v := range labels {
+			tags = append(tags, k+":"+v)
+		}
+		sort.Strings(tags)
+		line += "|#" + strings.Join(tags, ",")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.W.Write([]byte(line + "\n"))
+}
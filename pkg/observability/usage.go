@@ -0,0 +1,291 @@
+package observability
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// UnlabeledStage is the key for calls made without llm.WithStage
+const UnlabeledStage = "unlabeled"
+
+// StageUsage aggregates the LLM calls of one pipeline stage
+type StageUsage struct {
+	Stage  string    `json:"stage"`
+	Calls  int       `json:"calls"`
+	Errors int       `json:"errors,omitempty"`
+	Usage  llm.Usage `json:"usage"` // Token usage and estimated cost summed across successful calls
+}
+
+// ModelUsage aggregates the successful LLM calls served by one model
+type ModelUsage struct {
+	Model string    `json:"model"`
+	Calls int       `json:"calls"`
+	Usage llm.Usage `json:"usage"`
+}
+
+// LLMCall records a single LLM API call
+type LLMCall struct {
+	Stage    string        `json:"stage"`
+	Model    string        `json:"model,omitempty"` // Empty when the call failed
+	Usage    llm.Usage     `json:"usage"`
+	Duration time.Duration `json:"duration_ns"`
+	Error    string        `json:"error,omitempty"`
+	// ErrorClass is the failure mode of a failed call, counted in Report.Failures
+	ErrorClass ErrorClass `json:"error_class,omitempty"`
+}
+
+// HostUsage aggregates the HTTP requests sent to one host
+type HostUsage struct {
+	Host     string `json:"host"`
+	Requests int    `json:"requests"`
+	Errors   int    `json:"errors,omitempty"` // Transport errors and non-2xx responses
+	// RateLimitRemaining is the last X-RateLimit-Remaining value the host
+	// reported, or nil if it never sent one
+	RateLimitRemaining *int `json:"rate_limit_remaining,omitempty"`
+}
+
+// Sources of counted failures
+const (
+	FailureSourceLLM   = "llm_call" // A failed LLM API call, retries counted separately
+	FailureSourceHTTP  = "http"     // A failed or non-2xx HTTP request
+	FailureSourceStage = "stage"    // A pipeline stage that failed or fell back, from StageFailed events
+)
+
+// FailureCount is how often one failure mode occurred at one source
+type FailureCount struct {
+	Source string     `json:"source"`
+	Class  ErrorClass `json:"class"`
+	Count  int        `json:"count"`
+}
+
+type failureKey struct {
+	source string
+	class  ErrorClass
+}
+
+// Report is a snapshot of everything a UsageCollector recorded
+type Report struct {
+	Total  StageUsage   `json:"total"`
+	Stages []StageUsage `json:"stages"`
+	Models []ModelUsage `json:"models"`
+	Calls  []LLMCall    `json:"calls"`
+	HTTP   []HostUsage  `json:"http"`
+	// Failures counts failures by source and class, so recurring failure
+	// modes can be compared across runs
+	Failures []FailureCount `json:"failures,omitempty"`
+}
+
+// HTTPRequests returns the number of HTTP requests sent to all hosts
+func (r Report) HTTPRequests() int {
+	total := 0
+	for _, h := range r.HTTP {
+		total += h.Requests
+	}
+	return total
+}
+
+// UsageCollector is a thread-safe accumulator of LLM usage, keyed by stage
+// and model, and of HTTP requests, keyed by host. A single collector can be
+// shared by every client and transport in a run.
+type UsageCollector struct {
+	mu     sync.Mutex
+	stages map[string]*StageUsage
+	models map[string]*ModelUsage
+	hosts  map[string]*HostUsage
+	calls  []LLMCall
+
+	failures map[failureKey]int
+}
+
+// NewUsageCollector creates an empty collector
+func NewUsageCollector() *UsageCollector {
+	return &UsageCollector{
+		stages: make(map[string]*StageUsage),
+		models: make(map[string]*ModelUsage),
+		hosts:  make(map[string]*HostUsage),
+
+		failures: make(map[failureKey]int),
+	}
+}
+
+// Record adds the outcome of one call to stage
+func (u *UsageCollector) Record(stage string, usage llm.Usage, err error) {
+	call := LLMCall{Stage: stage, Usage: usage}
+	if err != nil {
+		call.Error = err.Error()
+		call.ErrorClass = ClassifyError(err)
+	}
+	u.RecordCall(call)
+}
+
+// RecordCall adds one LLM call to the per-stage and per-model totals
+func (u *UsageCollector) RecordCall(call LLMCall) {
+	if call.Stage == "" {
+		call.Stage = UnlabeledStage
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.calls = append(u.calls, call)
+	s, ok := u.stages[call.Stage]
+	if !ok {
+		s = &StageUsage{Stage: call.Stage}
+		u.stages[call.Stage] = s
+	}
+	s.Calls++
+	if call.Error != "" {
+		s.Errors++
+		if call.ErrorClass == "" {
+			call.ErrorClass = ErrorClassOther
+		}
+		u.failures[failureKey{FailureSourceLLM, call.ErrorClass}]++
+		return
+	}
+	s.Usage = s.Usage.Add(call.Usage)
+
+	if call.Model == "" {
+		return
+	}
+	m, ok := u.models[call.Model]
+	if !ok {
+		m = &ModelUsage{Model: call.Model}
+		u.models[call.Model] = m
+	}
+	m.Calls++
+	m.Usage = m.Usage.Add(call.Usage)
+}
+
+// RecordRequest adds one HTTP request to host. status is 0 when the request
+// failed before a response, and rateLimitRemaining is negative when unknown.
+// Failed responses are classified as GitHub failures, the API the counting
+// transport serves.
+func (u *UsageCollector) RecordRequest(host string, status int, rateLimitRemaining int, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	h, ok := u.hosts[host]
+	if !ok {
+		h = &HostUsage{Host: host}
+		u.hosts[host] = h
+	}
+	h.Requests++
+	if err != nil {
+		h.Errors++
+		u.failures[failureKey{FailureSourceHTTP, ClassifyError(err)}]++
+	} else if status < 200 || status > 299 {
+		h.Errors++
+		class := classifyGitHubError(&github.APIError{StatusCode: status, RateLimitRemaining: rateLimitRemaining})
+		u.failures[failureKey{FailureSourceHTTP, class}]++
+	}
+	if rateLimitRemaining >= 0 {
+		remaining := rateLimitRemaining
+		h.RateLimitRemaining = &remaining
+	}
+}
+
+// RecordFailure counts one failure of class at source
+func (u *UsageCollector) RecordFailure(source string, class ErrorClass) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.failures[failureKey{source, class}]++
+}
+
+// Failures returns a snapshot of failure counts sorted by source and class
+func (u *UsageCollector) Failures() []FailureCount {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	failures := make([]FailureCount, 0, len(u.failures))
+	for key, n := range u.failures {
+		failures = append(failures, FailureCount{Source: key.source, Class: key.class, Count: n})
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		if failures[i].Source != failures[j].Source {
+			return failures[i].Source < failures[j].Source
+		}
+		return failures[i].Class < failures[j].Class
+	})
+	return failures
+}
+
+// Subscriber returns an event subscriber counting StageFailed events as stage failures
+func (u *UsageCollector) Subscriber() Subscriber {
+	return func(event Event) {
+		if e, ok := event.(StageFailed); ok {
+			u.RecordFailure(FailureSourceStage, ClassifyError(e.Err))
+		}
+	}
+}
+
+// Stages returns a snapshot of per-stage usage sorted by stage name
+func (u *UsageCollector) Stages() []StageUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	stages := make([]StageUsage, 0, len(u.stages))
+	for _, s := range u.stages {
+		stages = append(stages, *s)
+	}
+	sort.Slice(stages, func(i, j int) bool { return stages[i].Stage < stages[j].Stage })
+	return stages
+}
+
+// Models returns a snapshot of per-model usage sorted by model name
+func (u *UsageCollector) Models() []ModelUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	models := make([]ModelUsage, 0, len(u.models))
+	for _, m := range u.models {
+		models = append(models, *m)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Model < models[j].Model })
+	return models
+}
+
+// Hosts returns a snapshot of per-host HTTP requests sorted by host
+func (u *UsageCollector) Hosts() []HostUsage {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	hosts := make([]HostUsage, 0, len(u.hosts))
+	for _, h := range u.hosts {
+		hosts = append(hosts, *h)
+	}
+	sort.Slice(hosts, func(i, j int) bool { return hosts[i].Host < hosts[j].Host })
+	return hosts
+}
+
+// Total returns usage summed across all stages
+func (u *UsageCollector) Total() StageUsage {
+	total := StageUsage{Stage: "total"}
+	for _, s := range u.Stages() {
+		total.Calls += s.Calls
+		total.Errors += s.Errors
+		total.Usage = total.Usage.Add(s.Usage)
+	}
+	return total
+}
+
+// Report returns a snapshot of all recorded LLM calls and HTTP requests
+func (u *UsageCollector) Report() Report {
+	u.mu.Lock()
+	calls := make([]LLMCall, len(u.calls))
+	copy(calls, u.calls)
+	u.mu.Unlock()
+
+	return Report{
+		Total:  u.Total(),
+		Stages: u.Stages(),
+		Models: u.Models(),
+		Calls:  calls,
+		HTTP:   u.Hosts(),
+
+		Failures: u.Failures(),
+	}
+}
@@ -0,0 +1,251 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// modelFor returns the model to use for a call with options
+func (c *Client) modelFor(options llm.Options) string {
+	if model, ok := c.StageModels[options.Stage]; ok && model != "" {
+		return model
+	}
+	if c.Model != "" {
+		return c.Model
+	}
+	return DefaultModel
+}
+
+// CallAPI calls the Chat Completions API with messages and tools
+func (c *Client) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	if c.Retry.MaxAttempts > 1 {
+		return llm.WithRetry(llm.ClientFunc(c.call), c.Retry).CallAPI(ctx, messages, tools, opts...)
+	}
+	return c.call(ctx, messages, tools, opts...)
+}
+
+// call makes a single Chat Completions request. Streaming is not supported,
+// so a stream handler in opts is ignored.
+func (c *Client) call(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	options := llm.ApplyOptions(opts)
+	requestBody := c.buildChatRequest(messages, tools, options)
+
+	var apiResponse ChatResponse
+	if err := c.post(ctx, "/chat/completions", requestBody, &apiResponse); err != nil {
+		return nil, err
+	}
+	if apiResponse.Model == "" {
+		apiResponse.Model = requestBody.Model
+	}
+	return convertChatResponse(&apiResponse)
+}
+
+// buildChatRequest converts a call into a Chat Completions request
+func (c *Client) buildChatRequest(messages []llm.Message, tools []llm.Tool, options llm.Options) ChatRequest {
+	requestBody := ChatRequest{
+		Model:     c.modelFor(options),
+		Messages:  convertChatMessages(messages),
+		MaxTokens: c.MaxTokens,
+	}
+	for _, tool := range tools {
+		requestBody.Tools = append(requestBody.Tools, ChatTool{
+			Type: "function",
+			Function: Function{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  schemaMap(tool.InputSchema),
+			},
+		})
+	}
+	if gen := options.Generation; gen != nil {
+		if gen.MaxOutputTokens > 0 {
+			requestBody.MaxTokens = int(gen.MaxOutputTokens)
+		}
+		requestBody.Temperature = gen.Temperature
+		requestBody.TopP = gen.TopP
+		requestBody.Stop = gen.StopSequences
+	}
+	if choice := options.ToolChoice; choice != nil && len(requestBody.Tools) > 0 {
+		requestBody.ToolChoice = convertToolChoice(*choice)
+	}
+
+	switch {
+	case options.ResponseSchema != nil:
+		requestBody.ResponseFormat = &ResponseFormat{Type: "json_schema", JSONSchema: &JSONSchema{
+			Name:        options.ResponseSchema.Name,
+			Description: options.ResponseSchema.Description,
+			Schema:      schemaMap(options.ResponseSchema.Schema),
+		}}
+	case options.JSONMode:
+		requestBody.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+	return requestBody
+}
+
+// convertToolChoice maps a tool choice to the tool_choice parameter
+func convertToolChoice(choice llm.ToolChoice) interface{} {
+	switch choice.Type {
+	case llm.ToolChoiceAny:
+		return "required"
+	case llm.ToolChoiceTool:
+		named := NamedToolChoice{Type: "function"}
+		named.Function.Name = choice.Name
+		return named
+	case llm.ToolChoiceNone:
+		return "none"
+	default:
+		return "auto"
+	}
+}
+
+// schemaMap returns schema as a JSON Schema object, omitting the empty
+// required list the API rejects as null
+func schemaMap(schema llm.InputSchema) map[string]interface{} {
+	properties := schema.Properties
+	if properties == nil {
+		properties = map[string]llm.Property{}
+	}
+	out := map[string]interface{}{
+		"type":       schema.Type,
+		"properties": properties,
+	}
+	if len(schema.Required) > 0 {
+		out["required"] = schema.Required
+	}
+	return out
+}
+
+// convertChatMessages maps the conversation to chat messages: tool_use blocks
+// become the assistant's tool_calls, each tool_result becomes a "tool" message,
+// and images become image_url parts. Thinking blocks from other providers are
+// dropped.
+func convertChatMessages(messages []llm.Message) []ChatMessage {
+	var out []ChatMessage
+	for _, msg := range messages {
+		blocks, ok := msg.Content.([]llm.ContentBlock)
+		if !ok {
+			if text, _ := msg.Content.(string); text != "" {
+				out = append(out, ChatMessage{Role: msg.Role, Content: text})
+			}
+			continue
+		}
+
+		message := ChatMessage{Role: msg.Role}
+		var text string
+		var parts []ContentPart
+		for _, block := range blocks {
+			switch block.Type {
+			case "text":
+				text += block.Text
+				parts = append(parts, ContentPart{Type: "text", Text: block.Text})
+			case "image":
+				if url := imageURL(block.Source); url != "" {
+					parts = append(parts, ContentPart{Type: "image_url", ImageURL: &ImageURL{URL: url}})
+				}
+			case "tool_use":
+				args, _ := json.Marshal(block.Input)
+				if block.Input == nil {
+					args = []byte("{}")
+				}
+				message.ToolCalls = append(message.ToolCalls, ToolCall{
+					ID:       block.ID,
+					Type:     "function",
+					Function: FunctionCall{Name: block.Name, Arguments: string(args)},
+				})
+			case "tool_result":
+				out = append(out, ChatMessage{Role: "tool", ToolCallID: block.ToolUseID, Content: block.Content})
+			}
+		}
+
+		switch {
+		case len(parts) > 0 && len(parts) != countText(parts):
+			message.Content = parts
+		case text != "":
+			message.Content = text
+		}
+		if message.Content != nil || len(message.ToolCalls) > 0 {
+			out = append(out, message)
+		}
+	}
+	return out
+}
+
+// countText returns the number of text parts
+func countText(parts []ContentPart) int {
+	n := 0
+	for _, p := range parts {
+		if p.Type == "text" {
+			n++
+		}
+	}
+	return n
+}
+
+// imageURL returns the URL of an image source, inlining base64 data as a data: URL
+func imageURL(source *llm.ImageSource) string {
+	if source == nil {
+		return ""
+	}
+	if source.Type == "base64" {
+		return "data:" + source.MediaType + ";base64," + source.Data
+	}
+	return source.URL
+}
+
+// convertChatResponse converts the first choice of a chat response to an llm.Response
+func convertChatResponse(apiResponse *ChatResponse) (*llm.Response, error) {
+	if len(apiResponse.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+	choice := apiResponse.Choices[0]
+
+	var content []llm.ContentBlock
+	if text, _ := choice.Message.Content.(string); text != "" {
+		content = append(content, llm.ContentBlock{Type: "text", Text: text})
+	}
+	for _, call := range choice.Message.ToolCalls {
+		var input map[string]interface{}
+		if call.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(call.Function.Arguments), &input); err != nil {
+				return nil, fmt.Errorf("failed to parse arguments of %s: %w", call.Function.Name, err)
+			}
+		}
+		content = append(content, llm.ContentBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input})
+	}
+
+	usage := llm.Usage{
+		InputTokens:       apiResponse.Usage.PromptTokens,
+		OutputTokens:      apiResponse.Usage.CompletionTokens,
+		ThinkingTokens:    apiResponse.Usage.CompletionTokensDetails.ReasoningTokens,
+		CachedInputTokens: apiResponse.Usage.PromptTokensDetails.CachedTokens,
+	}
+	usage.EstimatedCostUSD = llm.EstimateCost(apiResponse.Model, usage)
+
+	return &llm.Response{
+		ID:         apiResponse.ID,
+		Type:       "message",
+		Role:       "assistant",
+		Content:    content,
+		Model:      apiResponse.Model,
+		StopReason: convertFinishReason(choice.FinishReason),
+		Usage:      usage,
+	}, nil
+}
+
+// convertFinishReason maps a finish_reason to the Anthropic-style stop
+// reasons the agent checks
+func convertFinishReason(reason string) string {
+	switch reason {
+	case "tool_calls", "function_call":
+		return "tool_use"
+	case "length":
+		return "max_tokens"
+	case "stop":
+		return "end_turn"
+	default:
+		return reason
+	}
+}
@@ -0,0 +1,118 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCallAPIToolConversation(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body []byte) (int, string) {
+		if req.URL.String() != DefaultBaseURL+"/chat/completions" {
+			t.Errorf("Expected chat completions endpoint, got %s", req.URL)
+		}
+		var chatReq ChatRequest
+		if err := json.Unmarshal(body, &chatReq); err != nil {
+			t.Fatalf("Failed to parse request: %v", err)
+		}
+		if chatReq.Model != DefaultModel {
+			t.Errorf("Expected model %s, got %s", DefaultModel, chatReq.Model)
+		}
+		if len(chatReq.Messages) != 4 {
+			t.Fatalf("Expected 4 messages, got %+v", chatReq.Messages)
+		}
+		if chatReq.Messages[0].Role != "system" || chatReq.Messages[0].Content != "Be terse." {
+			t.Errorf("Expected system message first, got %+v", chatReq.Messages[0])
+		}
+		call := chatReq.Messages[2]
+		if call.Role != "assistant" || len(call.ToolCalls) != 1 || call.ToolCalls[0].Function.Arguments != `{"q":"go"}` {
+			t.Errorf("Expected assistant tool call, got %+v", call)
+		}
+		result := chatReq.Messages[3]
+		if result.Role != "tool" || result.ToolCallID != "call_1" || result.Content != "3 users" {
+			t.Errorf("Expected tool result message, got %+v", result)
+		}
+		if len(chatReq.Tools) != 1 || chatReq.Tools[0].Function.Name != "search" {
+			t.Errorf("Expected search tool, got %+v", chatReq.Tools)
+		}
+		if chatReq.ToolChoice != "required" {
+			t.Errorf("Expected required tool choice, got %v", chatReq.ToolChoice)
+		}
+		return http.StatusOK, `{"id": "chatcmpl-1", "model": "gpt-4.1-mini-2025-04-14",
+			"choices": [{"index": 0, "finish_reason": "tool_calls", "message": {"role": "assistant",
+				"tool_calls": [{"id": "call_2", "type": "function", "function": {"name": "search", "arguments": "{\"q\":\"rust\"}"}}]}}],
+			"usage": {"prompt_tokens": 1000, "completion_tokens": 100, "prompt_tokens_details": {"cached_tokens": 200}}}`
+	})
+
+	messages := []llm.Message{
+		{Role: "system", Content: "Be terse."},
+		llm.UserText("Find Go developers"),
+		{Role: "assistant", Content: []llm.ContentBlock{{Type: "tool_use", ID: "call_1", Name: "search", Input: map[string]interface{}{"q": "go"}}}},
+		{Role: "user", Content: []llm.ContentBlock{{Type: "tool_result", ToolUseID: "call_1", Content: "3 users"}}},
+	}
+	tools := []llm.Tool{{Name: "search", Description: "Search users", InputSchema: llm.InputSchema{
+		Type:       "object",
+		Properties: map[string]llm.Property{"q": {Type: "string"}},
+	}}}
+
+	var _ llm.Client = client
+	resp, err := client.CallAPI(context.Background(), messages, tools, llm.WithToolChoice(llm.ToolChoice{Type: llm.ToolChoiceAny}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StopReason != "tool_use" {
+		t.Errorf("Expected tool_use stop reason, got %s", resp.StopReason)
+	}
+	if len(resp.Content) != 1 || resp.Content[0].Name != "search" || resp.Content[0].Input.(map[string]interface{})["q"] != "rust" {
+		t.Errorf("Expected search tool call, got %+v", resp.Content)
+	}
+	if resp.Usage.InputTokens != 1000 || resp.Usage.CachedInputTokens != 200 || resp.Usage.EstimatedCostUSD == 0 {
+		t.Errorf("Expected priced usage, got %+v", resp.Usage)
+	}
+}
+
+func TestCallAPIResponseSchema(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body []byte) (int, string) {
+		var chatReq ChatRequest
+		json.Unmarshal(body, &chatReq)
+		format := chatReq.ResponseFormat
+		if format == nil || format.Type != "json_schema" || format.JSONSchema.Name != "requirements" {
+			t.Errorf("Expected json_schema response format, got %+v", format)
+		}
+		if chatReq.Model != "gpt-4.1" || chatReq.MaxTokens != 512 {
+			t.Errorf("Expected stage model and call max tokens, got %s and %d", chatReq.Model, chatReq.MaxTokens)
+		}
+		return http.StatusOK, `{"id": "chatcmpl-2", "choices": [{"finish_reason": "stop",
+			"message": {"role": "assistant", "content": "{\"skills\":[\"go\"]}"}}]}`
+	})
+	client.StageModels = map[string]string{"stage1": "gpt-4.1"}
+
+	schema := &llm.ResponseSchema{Name: "requirements", Schema: llm.InputSchema{Type: "object"}}
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("Go devs")}, nil,
+		llm.WithResponseSchema(schema), llm.WithStage("stage1"), llm.WithMaxOutputTokens(512))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.StopReason != "end_turn" || resp.Content[0].Text != `{"skills":["go"]}` {
+		t.Errorf("Expected JSON text response, got %+v", resp)
+	}
+	if resp.Model != "gpt-4.1" {
+		t.Errorf("Expected request model when the response has none, got %s", resp.Model)
+	}
+}
+
+func TestCallAPIProviderName(t *testing.T) {
+	client := newTestClient(func(*http.Request, []byte) (int, string) {
+		return http.StatusNotFound, `{"error": {"message": "model \"llama3\" not found", "type": "api_error"}}`
+	})
+	client.Provider = "ollama"
+
+	_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+	apiErr, ok := err.(*llm.APIError)
+	if !ok || apiErr.Provider != "ollama" || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected ollama API error, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+const (
+	// DefaultBaseURL is the OpenAI API endpoint; OpenAI-compatible servers can override it
+	DefaultBaseURL = "https://api.openai.com/v1"
+	// DefaultEmbeddingModel is the model used by Embed
+	DefaultEmbeddingModel = "text-embedding-3-small"
+	// DefaultModel is the chat model used by CallAPI
+	DefaultModel = "gpt-4.1-mini"
+	// maxEmbedBatch is the number of inputs OpenAI accepts per embedding request
+	maxEmbedBatch = 2048
+)
+
+// Config configures an OpenAI client
+type Config struct {
+	// APIKey authenticates to the API. OpenAI-compatible servers such as
+	// Ollama need none.
+	APIKey string
+	// BaseURL defaults to DefaultBaseURL
+	BaseURL string
+	// Model is the chat model called by CallAPI. Defaults to DefaultModel.
+	Model string
+	// StageModels overrides Model for calls labelled with llm.WithStage
+	StageModels map[string]string
+	// MaxTokens caps the output of each call; calls can override it with
+	// llm.WithMaxOutputTokens. Zero leaves it to the server.
+	MaxTokens int
+	// Retry retries rate limit and other transient errors inside the
+	// client. The zero value disables it.
+	Retry llm.RetryConfig
+	// Provider names the service in errors. Defaults to "openai".
+	Provider string
+}
+
+// Client handles interactions with the OpenAI API
+type Client struct {
+	APIKey         string
+	BaseURL        string
+	EmbeddingModel string
+	Model          string
+	StageModels    map[string]string
+	MaxTokens      int
+	Retry          llm.RetryConfig
+	Provider       string
+	HTTPClient     *http.Client
+}
+
+// NewClient creates a new OpenAI Client
+func NewClient(apiKey string) *Client {
+	return NewClientWithConfig(Config{APIKey: apiKey})
+}
+
+// NewClientWithConfig creates a new OpenAI Client from config
+func NewClientWithConfig(config Config) *Client {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	model := config.Model
+	if model == "" {
+		model = DefaultModel
+	}
+	provider := config.Provider
+	if provider == "" {
+		provider = "openai"
+	}
+	return &Client{
+		APIKey:         config.APIKey,
+		BaseURL:        strings.TrimSuffix(baseURL, "/"),
+		EmbeddingModel: DefaultEmbeddingModel,
+		Model:          model,
+		StageModels:    config.StageModels,
+		MaxTokens:      config.MaxTokens,
+		Retry:          config.Retry,
+		Provider:       provider,
+		HTTPClient: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// Embed returns one embedding per text, in input order
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxEmbedBatch {
+		end := min(start+maxEmbedBatch, len(texts))
+
+		var resp EmbeddingResponse
+		if err := c.post(ctx, "/embeddings", EmbeddingRequest{Model: c.EmbeddingModel, Input: texts[start:end]}, &resp); err != nil {
+			return nil, err
+		}
+		if len(resp.Data) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(resp.Data))
+		}
+
+		batch := make([][]float32, end-start)
+		for _, e := range resp.Data {
+			if e.Index < 0 || e.Index >= len(batch) {
+				return nil, fmt.Errorf("embedding index %d out of range", e.Index)
+			}
+			batch[e.Index] = e.Embedding
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+// post sends a JSON request to path and decodes the JSON response into out
+func (c *Client) post(ctx context.Context, path string, payload, out interface{}) error {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return c.newAPIError(resp, body)
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) newAPIError(resp *http.Response, body []byte) error {
+	provider := c.Provider
+	if provider == "" {
+		provider = "openai"
+	}
+	apiErr := &llm.APIError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Message:    string(body),
+		RetryAfter: llm.ParseRetryAfter(resp.Header.Get("retry-after")),
+	}
+
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+		apiErr.Status = errResp.Error.Type
+		apiErr.Message = errResp.Error.Message
+	}
+
+	return apiErr
+}
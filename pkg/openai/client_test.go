@@ -0,0 +1,82 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// roundTripFunc lets tests intercept requests sent to the OpenAI API
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func newTestClient(handler func(req *http.Request, body []byte) (int, string)) *Client {
+	client := NewClient("test-key")
+	client.HTTPClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(req.Body)
+		status, respBody := handler(req, data)
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Retry-After": []string{"2"}},
+			Body:       io.NopCloser(strings.NewReader(respBody)),
+		}, nil
+	})}
+	return client
+}
+
+func TestEmbed(t *testing.T) {
+	client := newTestClient(func(req *http.Request, body []byte) (int, string) {
+		if req.URL.String() != DefaultBaseURL+"/embeddings" {
+			t.Errorf("Expected embeddings endpoint, got %s", req.URL)
+		}
+		if req.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("Expected bearer auth, got %q", req.Header.Get("Authorization"))
+		}
+		var embedReq EmbeddingRequest
+		json.Unmarshal(body, &embedReq)
+		if embedReq.Model != DefaultEmbeddingModel || len(embedReq.Input) != 2 {
+			t.Errorf("Unexpected request %+v", embedReq)
+		}
+		// Data is returned out of order; Embed must restore input order
+		return http.StatusOK, `{"data": [
+			{"index": 1, "embedding": [0, 1]},
+			{"index": 0, "embedding": [1, 0]}
+		], "model": "text-embedding-3-small"}`
+	})
+
+	var _ llm.Embedder = client
+	embeddings, err := client.Embed(context.Background(), []string{"go developer", "rust developer"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 1 || embeddings[1][1] != 1 {
+		t.Errorf("Expected embeddings in input order, got %v", embeddings)
+	}
+}
+
+func TestEmbedAPIError(t *testing.T) {
+	client := newTestClient(func(*http.Request, []byte) (int, string) {
+		return http.StatusTooManyRequests, `{"error": {"message": "Rate limit reached", "type": "requests", "code": "rate_limit_exceeded"}}`
+	})
+
+	_, err := client.Embed(context.Background(), []string{"x"})
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Expected *llm.APIError, got %v", err)
+	}
+	if apiErr.Provider != "openai" || apiErr.Message != "Rate limit reached" || apiErr.RetryAfter == 0 {
+		t.Errorf("Unexpected API error %+v", apiErr)
+	}
+	if !llm.IsTransient(err) {
+		t.Error("Expected rate limit to be transient")
+	}
+}
@@ -0,0 +1,140 @@
+package openai
+
+// EmbeddingRequest is the body of a POST /embeddings call
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse is the body returned by /embeddings
+type EmbeddingResponse struct {
+	Data  []Embedding `json:"data"`
+	Model string      `json:"model"`
+	Usage struct {
+		PromptTokens int `json:"prompt_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// Embedding is a single embedding vector and the index of its input
+type Embedding struct {
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// ErrorResponse is the error body returned by the OpenAI API
+type ErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+	} `json:"error"`
+}
+
+// ChatRequest is the body of a POST /chat/completions call
+type ChatRequest struct {
+	Model          string          `json:"model"`
+	Messages       []ChatMessage   `json:"messages"`
+	Tools          []ChatTool      `json:"tools,omitempty"`
+	ToolChoice     interface{}     `json:"tool_choice,omitempty"` // "auto", "required", "none" or a NamedToolChoice
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	MaxTokens      int             `json:"max_tokens,omitempty"`
+	Temperature    *float32        `json:"temperature,omitempty"`
+	TopP           *float32        `json:"top_p,omitempty"`
+	Stop           []string        `json:"stop,omitempty"`
+}
+
+// ChatMessage is one message of a chat completion. Content is a string or,
+// for user messages with images, a list of ContentPart.
+type ChatMessage struct {
+	Role       string      `json:"role"`
+	Content    interface{} `json:"content,omitempty"`
+	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`
+	ToolCallID string      `json:"tool_call_id,omitempty"`
+}
+
+// ContentPart is a text or image part of a user message
+type ContentPart struct {
+	Type     string    `json:"type"` // "text" or "image_url"
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+// ImageURL references an image by URL or inline data: URL
+type ImageURL struct {
+	URL string `json:"url"`
+}
+
+// ToolCall is a function call requested by the model
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"` // Always "function"
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall names the function to call and its JSON-encoded arguments
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ChatTool declares a function the model may call
+type ChatTool struct {
+	Type     string   `json:"type"` // Always "function"
+	Function Function `json:"function"`
+}
+
+// Function describes a callable function and its JSON Schema parameters
+type Function struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters"`
+}
+
+// NamedToolChoice forces a call to one function
+type NamedToolChoice struct {
+	Type     string `json:"type"` // Always "function"
+	Function struct {
+		Name string `json:"name"`
+	} `json:"function"`
+}
+
+// ResponseFormat constrains the response to JSON, optionally to a schema
+type ResponseFormat struct {
+	Type       string      `json:"type"` // "json_object" or "json_schema"
+	JSONSchema *JSONSchema `json:"json_schema,omitempty"`
+}
+
+// JSONSchema is the schema of a json_schema response format
+type JSONSchema struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Schema      map[string]interface{} `json:"schema"`
+}
+
+// ChatResponse is the body returned by /chat/completions
+type ChatResponse struct {
+	ID      string       `json:"id"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   ChatUsage    `json:"usage"`
+}
+
+// ChatChoice is one completion of a chat response
+type ChatChoice struct {
+	Index        int         `json:"index"`
+	Message      ChatMessage `json:"message"`
+	FinishReason string      `json:"finish_reason"`
+}
+
+// ChatUsage reports the tokens billed for a chat completion
+type ChatUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	PromptTokensDetails struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details"`
+	CompletionTokensDetails struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details"`
+}
@@ -0,0 +1,138 @@
+// Package outreach drafts the messages recruiters send to the candidates of a
+// run: a template with placeholders for the candidate's name, top repository
+// and matched skills is filled per candidate, optionally polished by the LLM,
+// and written as a file ready for a mail merge.
+package outreach
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// DefaultTemplate is the template used when none is configured
+const DefaultTemplate = `Subject: {{if .TopRepo}}Your work on {{.TopRepo}}{{else}}Your work on GitHub{{end}}
+
+Hi {{.FirstName}},
+
+{{if .TopRepo}}I came across {{.TopRepo}} ({{.TopRepoURL}}){{else}}I came across your GitHub profile{{end}} while looking for engineers with {{.MatchedSkills}} experience, and it stood out.
+
+We are hiring{{with .Role}} for a {{.}}{{end}}, and your work looks like a close match. Would you be open to a short chat about it?
+
+Best,
+{{.Sender}}
+`
+
+// Fields are the placeholders of a template, e.g. {{.FirstName}}
+type Fields struct {
+	Name          string // The candidate's name, their username if unknown
+	FirstName     string
+	Username      string
+	GitHubURL     string
+	Location      string
+	Score         float64
+	TopRepo       string // Name of the candidate's most relevant project
+	TopRepoURL    string
+	TopRepoWhy    string   // Why the ranking found it relevant
+	MatchedSkills string   // The key qualifications, comma-separated
+	Skills        []string // The key qualifications
+	// Role and Sender are the same for every candidate
+	Role   string
+	Sender string
+}
+
+// CandidateFields fills the fields of c, on top of the shared Role and
+// Sender of base
+func CandidateFields(c agent.RankedCandidate, base Fields) Fields {
+	f := base
+	f.Username = c.Username
+	f.Name = strings.TrimSpace(c.Name)
+	if f.Name == "" {
+		f.Name = c.Username
+	}
+	f.FirstName, _, _ = strings.Cut(f.Name, " ")
+	f.GitHubURL = c.GitHubURL
+	f.Location = c.Location
+	f.Score = c.FinalMatchScore
+	if len(c.TopRelevantProjects) > 0 {
+		p := c.TopRelevantProjects[0]
+		f.TopRepo, f.TopRepoURL, f.TopRepoWhy = p.Name, p.URL, p.WhyRelevant
+	}
+	f.Skills = c.KeyQualifications
+	f.MatchedSkills = strings.Join(c.KeyQualifications, ", ")
+	return f
+}
+
+// Template is a parsed outreach template: a text/template body, optionally
+// preceded by a "Subject: ..." line and a blank line
+type Template struct {
+	subject *template.Template // Nil without a subject line
+	body    *template.Template
+}
+
+// ParseTemplate parses text as an outreach template, rejecting placeholders
+// that are not among Fields
+func ParseTemplate(text string) (*Template, error) {
+	var t Template
+	if rest, ok := strings.CutPrefix(text, "Subject:"); ok {
+		subject, body, _ := strings.Cut(rest, "\n")
+		s, err := template.New("subject").Parse(strings.TrimSpace(subject))
+		if err != nil {
+			return nil, fmt.Errorf("invalid outreach subject: %w", err)
+		}
+		t.subject = s
+		text = strings.TrimLeft(body, "\r\n")
+	}
+	body, err := template.New("body").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid outreach template: %w", err)
+	}
+	t.body = body
+	// Executing the template catches unknown placeholders before any candidate
+	if _, _, err := t.Execute(Fields{}); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// Execute fills the template with f
+func (t *Template) Execute(f Fields) (subject, body string, err error) {
+	var b strings.Builder
+	if t.subject != nil {
+		if err := t.subject.Execute(&b, f); err != nil {
+			return "", "", fmt.Errorf("failed to fill outreach subject: %w", err)
+		}
+		subject = b.String()
+		b.Reset()
+	}
+	if err := t.body.Execute(&b, f); err != nil {
+		return "", "", fmt.Errorf("failed to fill outreach template: %w", err)
+	}
+	return subject, b.String(), nil
+}
+
+// Message is the outreach to one candidate
+type Message struct {
+	Username  string `json:"username"`
+	Name      string `json:"name,omitempty"`
+	GitHubURL string `json:"github_url"`
+	Subject   string `json:"subject,omitempty"`
+	Body      string `json:"body"`
+	// Polished is set when the LLM rewrote the body
+	Polished bool `json:"polished,omitempty"`
+}
+
+// Merge fills t for each of candidates, with the shared fields of base
+func Merge(t *Template, candidates []agent.RankedCandidate, base Fields) ([]Message, error) {
+	messages := make([]Message, 0, len(candidates))
+	for _, c := range candidates {
+		subject, body, err := t.Execute(CandidateFields(c, base))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Username, err)
+		}
+		messages = append(messages, Message{Username: c.Username, Name: c.Name, GitHubURL: c.GitHubURL, Subject: subject, Body: body})
+	}
+	return messages, nil
+}
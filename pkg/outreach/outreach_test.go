@@ -0,0 +1,101 @@
+package outreach
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+var testCandidates = []agent.RankedCandidate{
+	{Rank: 1, Username: "gopher_lima", Name: "Ana Quispe", GitHubURL: "https://github.com/gopher_lima", FinalMatchScore: 90,
+		KeyQualifications:   []string{"Go", "gRPC"},
+		TopRelevantProjects: []agent.RelevantProject{{Name: "grpc-gateway-kit", URL: "https://github.com/gopher_lima/grpc-gateway-kit"}}},
+	{Rank: 2, Username: "rustacean", KeyQualifications: []string{"Rust"}},
+}
+
+func TestMerge(t *testing.T) {
+	tmpl, err := ParseTemplate("Subject: {{.TopRepo}} caught our eye\n\nHi {{.FirstName}}, your {{.MatchedSkills}} work on {{.TopRepoURL}} fits our {{.Role}} role.\n{{.Sender}}\n")
+	if err != nil {
+		t.Fatalf("Expected the template parsed, got %v", err)
+	}
+	messages, err := Merge(tmpl, testCandidates, Fields{Role: "backend", Sender: "Luis"})
+	if err != nil {
+		t.Fatalf("Expected the messages filled, got %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("Expected a message per candidate, got %+v", messages)
+	}
+	if m := messages[0]; m.Subject != "grpc-gateway-kit caught our eye" ||
+		m.Body != "Hi Ana, your Go, gRPC work on https://github.com/gopher_lima/grpc-gateway-kit fits our backend role.\nLuis\n" {
+		t.Errorf("Unexpected message %+v", m)
+	}
+	// Without a name, the username is used
+	if !strings.HasPrefix(messages[1].Body, "Hi rustacean, your Rust work") {
+		t.Errorf("Expected the username as first name, got %q", messages[1].Body)
+	}
+}
+
+func TestParseTemplateErrors(t *testing.T) {
+	for _, text := range []string{"Hi {{.Nickname}}", "Hi {{.Name", "Subject: {{.Repo}}\n\nHi"} {
+		if _, err := ParseTemplate(text); err == nil {
+			t.Errorf("Expected %q rejected", text)
+		}
+	}
+	if _, err := ParseTemplate(DefaultTemplate); err != nil {
+		t.Errorf("Expected the default template to parse, got %v", err)
+	}
+}
+
+func TestPolish(t *testing.T) {
+	var system, user string
+	client := llm.ClientFunc(func(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+		system, user = messages[0].Content.(string), messages[1].Content.(string)
+		return &llm.Response{Content: []llm.ContentBlock{{Type: "text", Text: "Hi Ana, loved your gateway kit.\n"}}}, nil
+	})
+	m, err := Polish(context.Background(), client, Message{Username: "gopher_lima", Subject: "Hello", Body: "Hi Ana, your work fits."})
+	if err != nil {
+		t.Fatalf("Expected the message polished, got %v", err)
+	}
+	if !strings.Contains(system, "Keep every fact") || user != "Hi Ana, your work fits." {
+		t.Errorf("Expected the polishing prompt and the body sent, got %q and %q", system, user)
+	}
+	if m.Body != "Hi Ana, loved your gateway kit.\n" || m.Subject != "Hello" || !m.Polished {
+		t.Errorf("Expected a polished body under the same subject, got %+v", m)
+	}
+
+	failing := llm.ClientFunc(func(context.Context, []llm.Message, []llm.Tool, ...llm.CallOption) (*llm.Response, error) {
+		return nil, errors.New("quota exhausted")
+	})
+	m, err = Polish(context.Background(), failing, Message{Body: "Hi"})
+	if err == nil || m.Body != "Hi" || m.Polished {
+		t.Errorf("Expected the message unchanged with the error, got %+v and %v", m, err)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tmpl, _ := ParseTemplate(DefaultTemplate)
+	messages, _ := Merge(tmpl, testCandidates, Fields{Sender: "Luis"})
+
+	var md bytes.Buffer
+	if err := Write(&md, FileFormat("outreach.md"), "run-1", messages); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(md.String(), "# Outreach for run run-1\n\n## 1. Ana Quispe (@gopher_lima)\n\nProfile: https://github.com/gopher_lima\nSubject: Your work on grpc-gateway-kit\n\nHi Ana,") {
+		t.Errorf("Unexpected markdown:\n%s", md.String())
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, FileFormat("outreach.CSV"), "run-1", messages); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil || len(records) != 3 || records[2][3] != "Your work on GitHub" {
+		t.Errorf("Expected a header and a row per message, got %v (%v)", records, err)
+	}
+}
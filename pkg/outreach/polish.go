@@ -0,0 +1,43 @@
+package outreach
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/prompts"
+)
+
+// Stage labels the polishing calls for usage accounting and per-stage models
+const Stage = "outreach"
+
+// Polish has client rewrite the body of m so it reads naturally, keeping its
+// facts. The subject is kept as it is.
+func Polish(ctx context.Context, client llm.Client, m Message) (Message, error) {
+	systemPrompt, err := prompts.Render(prompts.Outreach, prompts.Data{})
+	if err != nil {
+		return m, err
+	}
+	messages := []llm.Message{
+		llm.SystemText(systemPrompt),
+		llm.UserText(m.Body),
+	}
+	resp, err := client.CallAPI(ctx, messages, nil, llm.WithStage(Stage))
+	if err != nil {
+		return m, fmt.Errorf("failed to call LLM: %w", err)
+	}
+	var content string
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			content += block.Text
+		}
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return m, fmt.Errorf("LLM returned an empty message")
+	}
+	m.Body = content + "\n"
+	m.Polished = true
+	return m, nil
+}
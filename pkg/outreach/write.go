@@ -0,0 +1,84 @@
+package outreach
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Outreach file formats
+const (
+	FormatMarkdown = "markdown"
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+)
+
+// FileFormat picks the format of an outreach file by its extension: CSV for
+// .csv, for mail-merge tools, JSON for .json and markdown otherwise
+func FileFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	}
+	return FormatMarkdown
+}
+
+// Write writes the messages of the run with runID to w in format
+func Write(w io.Writer, format, runID string, messages []Message) error {
+	switch format {
+	case FormatMarkdown:
+		return writeMarkdown(w, runID, messages)
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"username", "name", "github_url", "subject", "body", "polished"})
+		for _, m := range messages {
+			cw.Write([]string{m.Username, m.Name, m.GitHubURL, m.Subject, m.Body, strconv.FormatBool(m.Polished)})
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatJSON:
+		data, err := json.MarshalIndent(messages, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal outreach: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	default:
+		return fmt.Errorf("unknown outreach format %q: want %s, %s or %s", format, FormatMarkdown, FormatCSV, FormatJSON)
+	}
+}
+
+// writeMarkdown writes one section per message, separated by rules
+func writeMarkdown(w io.Writer, runID string, messages []Message) error {
+	var b strings.Builder
+	b.WriteString("# Outreach")
+	if runID != "" {
+		b.WriteString(" for run " + runID)
+	}
+	b.WriteString("\n")
+	for i, m := range messages {
+		title := "@" + m.Username
+		if m.Name != "" {
+			title = m.Name + " (@" + m.Username + ")"
+		}
+		fmt.Fprintf(&b, "\n## %d. %s\n\n", i+1, title)
+		if m.GitHubURL != "" {
+			fmt.Fprintf(&b, "Profile: %s\n", m.GitHubURL)
+		}
+		if m.Subject != "" {
+			fmt.Fprintf(&b, "Subject: %s\n", m.Subject)
+		}
+		b.WriteString("\n" + strings.TrimRight(m.Body, "\n") + "\n")
+		if i < len(messages)-1 {
+			b.WriteString("\n---\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
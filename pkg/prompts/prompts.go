@@ -0,0 +1,52 @@
+// Package prompts renders the system prompts used by the sourcing agent from
+// text/template files, so shared fragments and per-run variables live in one
+// place instead of being spliced into string literals.
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"text/template"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// Template names, one per stage prompt
+const (
+	Requirements = "requirements"
+	Strategy     = "strategy"
+	Ranking      = "ranking"
+	SearchAgent  = "search_agent"
+	// Outreach polishes outreach messages, after the pipeline
+	Outreach = "outreach"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.New("prompts").Option("missingkey=error").ParseFS(templateFS, "templates/*.tmpl"))
+
+// Data holds the variables available to every prompt template
+type Data struct {
+	// TargetCount is the number of candidates to present; zero leaves it to the model
+	TargetCount int
+	// Tools lists the tools available to the model
+	Tools []llm.Tool
+	// Guidance is organization-specific instruction appended to the prompt
+	Guidance string
+	// Language is the language the ranking writes its prose in; empty for English
+	Language string
+}
+
+// Render executes the named template with data
+func Render(name string, data Data) (string, error) {
+	if templates.Lookup(name) == nil {
+		return "", fmt.Errorf("unknown prompt template %q", name)
+	}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt %q: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,66 @@
+package prompts
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestRender(t *testing.T) {
+	t.Run("AllStagesRender", func(t *testing.T) {
+		for _, name := range []string{Requirements, Strategy, Ranking, SearchAgent, Outreach} {
+			got, err := Render(name, Data{Tools: []llm.Tool{{Name: "search_github_developers"}}})
+			if err != nil {
+				t.Fatalf("Expected %s to render, got %v", name, err)
+			}
+			if strings.Contains(got, "Organization Guidance") || strings.Contains(got, "{{") {
+				t.Errorf("Expected %s without guidance or template markup, got %q", name, got)
+			}
+		}
+	})
+
+	t.Run("Guidance", func(t *testing.T) {
+		got, _ := Render(Requirements, Data{Guidance: "We only hire in LATAM time zones."})
+		if !strings.HasSuffix(got, "## Organization Guidance\n\nWe only hire in LATAM time zones.") {
+			t.Errorf("Expected guidance appended, got %q", got)
+		}
+	})
+
+	t.Run("TargetCount", func(t *testing.T) {
+		got, _ := Render(Ranking, Data{TargetCount: 5})
+		if !strings.Contains(got, "3. Provide reasoning for each candidate\n4. Present at most 5 candidates") {
+			t.Errorf("Expected target count instruction, got %q", got)
+		}
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		got, _ := Render(Ranking, Data{Language: "Spanish"})
+		if !strings.Contains(got, "search_quality in Spanish") {
+			t.Errorf("Expected language instruction, got %q", got)
+		}
+		if english, _ := Render(Ranking, Data{}); strings.Contains(english, "Keep everything else") {
+			t.Errorf("Expected no language instruction for English, got %q", english)
+		}
+	})
+
+	t.Run("Tools", func(t *testing.T) {
+		one, _ := Render(SearchAgent, Data{Tools: []llm.Tool{{Name: "search_github_developers"}}})
+		if !strings.Contains(one, "You have ONE tool: search_github_developers") {
+			t.Errorf("Expected single tool line, got %q", one)
+		}
+		many, _ := Render(SearchAgent, Data{Tools: []llm.Tool{
+			{Name: "search_github_developers", Description: "Search users"},
+			{Name: "get_user_repos", Description: "List repositories"},
+		}})
+		if !strings.Contains(many, "- get_user_repos: List repositories") {
+			t.Errorf("Expected tool list, got %q", many)
+		}
+	})
+
+	t.Run("UnknownTemplate", func(t *testing.T) {
+		if _, err := Render("missing", Data{}); err == nil {
+			t.Error("Expected error for unknown template")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+// Package stackoverflow looks candidates up on Stack Overflow through the
+// Stack Exchange API, adding their reputation and top tags to the ranking as
+// an additional experience signal.
+package stackoverflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Stack Exchange API
+const DefaultBaseURL = "https://api.stackexchange.com/2.3"
+
+// site is the Stack Exchange site queried
+const site = "stackoverflow"
+
+// Client handles interactions with the Stack Exchange API
+type Client struct {
+	BaseURL string
+	// Key is an app key, which raises the daily quota from 300 requests per
+	// IP address to 10,000. Optional.
+	Key        string
+	HTTPClient *http.Client
+	// Logger receives request diagnostics at debug level. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// NewClient creates a Stack Exchange API client with the optional key
+func NewClient(key string) *Client {
+	return &Client{
+		BaseURL: DefaultBaseURL,
+		Key:     key,
+		HTTPClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// httpClient returns the configured HTTP client, falling back to http.DefaultClient
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
+// User is a Stack Overflow user
+type User struct {
+	UserID       int    `json:"user_id"`
+	DisplayName  string `json:"display_name"`
+	Reputation   int    `json:"reputation"`
+	Link         string `json:"link"`
+	Location     string `json:"location"`
+	WebsiteURL   string `json:"website_url"`
+	CreationDate int64  `json:"creation_date"` // Unix time
+}
+
+// Tag is one of a user's top tags, by answer and question score
+type Tag struct {
+	Name          string `json:"tag_name"`
+	AnswerCount   int    `json:"answer_count"`
+	AnswerScore   int    `json:"answer_score"`
+	QuestionCount int    `json:"question_count"`
+	QuestionScore int    `json:"question_score"`
+}
+
+// wrapper is the envelope of every Stack Exchange API response
+type wrapper[T any] struct {
+	Items          []T    `json:"items"`
+	QuotaRemaining int    `json:"quota_remaining"`
+	Backoff        int    `json:"backoff"` // Seconds to wait before the next request to the same method
+	ErrorID        int    `json:"error_id"`
+	ErrorName      string `json:"error_name"`
+	ErrorMessage   string `json:"error_message"`
+}
+
+// get decodes the items of the response to a GET of path with query
+func get[T any](ctx context.Context, c *Client, path string, query url.Values) ([]T, error) {
+	query.Set("site", site)
+	if c.Key != "" {
+		query.Set("key", c.Key)
+	}
+	apiURL := strings.TrimRight(c.BaseURL, "/") + path + "?" + query.Encode()
+	c.logger().Debug("Stack Exchange request", "url", apiURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	// The API always compresses; the transport decompresses transparently
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var w wrapper[T]
+	if err := json.Unmarshal(body, &w); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return nil, &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		}
+		return nil, fmt.Errorf("failed to parse Stack Exchange response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || w.ErrorID != 0 {
+		return nil, &APIError{StatusCode: resp.StatusCode, Name: w.ErrorName, Message: w.ErrorMessage}
+	}
+	if w.Backoff > 0 {
+		c.logger().Warn("Stack Exchange asked to back off", "seconds", w.Backoff, "quota_remaining", w.QuotaRemaining)
+	}
+	return w.Items, nil
+}
+
+// GetUser retrieves a user by ID, nil when there is none
+func (c *Client) GetUser(ctx context.Context, id int) (*User, error) {
+	users, err := get[User](ctx, c, "/users/"+strconv.Itoa(id), url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return &users[0], nil
+}
+
+// SearchUsers returns up to limit users whose display name contains name,
+// highest reputation first
+func (c *Client) SearchUsers(ctx context.Context, name string, limit int) ([]User, error) {
+	return get[User](ctx, c, "/users", url.Values{
+		"inname":   {name},
+		"sort":     {"reputation"},
+		"order":    {"desc"},
+		"pagesize": {strconv.Itoa(limit)},
+	})
+}
+
+// TopTags returns up to limit of a user's top tags, best first
+func (c *Client) TopTags(ctx context.Context, id int, limit int) ([]Tag, error) {
+	return get[Tag](ctx, c, "/users/"+strconv.Itoa(id)+"/top-tags", url.Values{
+		"pagesize": {strconv.Itoa(limit)},
+	})
+}
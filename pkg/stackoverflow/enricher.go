@@ -0,0 +1,184 @@
+package stackoverflow
+
+import (
+	"context"
+	"html"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Platform names Stack Overflow profiles in agent.ExternalProfile, and the
+// enricher in ENRICHERS
+const Platform = "stackoverflow"
+
+// DefaultMinConfidence is the confidence a profile matched by name needs to
+// be kept: a full name no namesake shares, or with a location or website in
+// common
+const DefaultMinConfidence = 0.6
+
+// Confidence of each name heuristic; a profile linked from the candidate's
+// profile has a confidence of 1
+const (
+	confidenceFullName      = 0.5  // The display name is the candidate's full name
+	confidenceUsername      = 0.4  // The display name is the candidate's username
+	confidenceSingleName    = 0.3  // The display name is the candidate's one-word name
+	confidenceWebsite       = 0.4  // The profile's website is the candidate's blog or profile
+	confidenceLocation      = 0.2  // The locations have a place in common
+	confidenceUnique        = 0.1  // No other result has the same name
+	maxNameMatchConfidence  = 0.95 // A name match is never certain
+	searchResultsPerLookup  = 10
+	defaultTopTagsPerResult = 5
+)
+
+// linkPattern matches a Stack Overflow profile link, capturing the user ID
+var linkPattern = regexp.MustCompile(`stackoverflow\.com/(?:users|u)/(\d+)`)
+
+// Enricher adds a candidate's Stack Overflow profile, with their reputation
+// and top tags, found through a link on their profile or by name
+type Enricher struct {
+	Client *Client
+	// MinConfidence is the confidence a name match needs to be kept.
+	// Defaults to DefaultMinConfidence.
+	MinConfidence float64
+	// TopTags is the number of top tags kept. Defaults to 5.
+	TopTags int
+}
+
+var _ agent.Enricher = (*Enricher)(nil)
+
+// Name returns Platform
+func (e *Enricher) Name() string { return Platform }
+
+// Enrich adds the candidate's Stack Overflow profile, if one is linked from
+// their blog or bio, or else found by name with enough confidence
+func (e *Enricher) Enrich(ctx context.Context, candidate *agent.EnrichedCandidate) error {
+	for _, p := range candidate.ExternalProfiles {
+		if p.Platform == Platform {
+			return nil
+		}
+	}
+
+	if id, ok := linkedUserID(candidate.Blog, candidate.Bio); ok {
+		user, err := e.Client.GetUser(ctx, id)
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			return e.add(ctx, candidate, *user, 1, agent.MatchedByLink)
+		}
+	}
+
+	name := strings.TrimSpace(candidate.Name)
+	if name == "" {
+		name = candidate.Username
+	}
+	users, err := e.Client.SearchUsers(ctx, name, searchResultsPerLookup)
+	if err != nil {
+		return err
+	}
+	user, confidence := bestNameMatch(candidate, users)
+	if user == nil || confidence < e.minConfidence() {
+		return nil
+	}
+	return e.add(ctx, candidate, *user, confidence, agent.MatchedByName)
+}
+
+func (e *Enricher) minConfidence() float64 {
+	if e.MinConfidence <= 0 {
+		return DefaultMinConfidence
+	}
+	return e.MinConfidence
+}
+
+// add appends user's profile, with their top tags, to candidate
+func (e *Enricher) add(ctx context.Context, candidate *agent.EnrichedCandidate, user User, confidence float64, matchedBy string) error {
+	limit := e.TopTags
+	if limit <= 0 {
+		limit = defaultTopTagsPerResult
+	}
+	tags, err := e.Client.TopTags(ctx, user.UserID, limit)
+	if err != nil {
+		return err
+	}
+	profile := agent.ExternalProfile{
+		Platform:    Platform,
+		URL:         user.Link,
+		DisplayName: html.UnescapeString(user.DisplayName),
+		Confidence:  confidence,
+		MatchedBy:   matchedBy,
+		Reputation:  user.Reputation,
+	}
+	for _, t := range tags {
+		profile.TopTags = append(profile.TopTags, t.Name)
+	}
+	candidate.ExternalProfiles = append(candidate.ExternalProfiles, profile)
+	return nil
+}
+
+// linkedUserID returns the user ID of the first Stack Overflow profile
+// linked from texts
+func linkedUserID(texts ...string) (int, bool) {
+	for _, text := range texts {
+		if m := linkPattern.FindStringSubmatch(text); m != nil {
+			id, err := strconv.Atoi(m[1])
+			return id, err == nil
+		}
+	}
+	return 0, false
+}
+
+// bestNameMatch returns the user most likely to be candidate, with the
+// confidence of the match. Users come highest reputation first, which
+// breaks ties.
+func bestNameMatch(candidate *agent.EnrichedCandidate, users []User) (*User, float64) {
+	fullName := agent.NormalizeName(candidate.Name)
+	username := agent.NormalizeName(candidate.Username)
+	base := func(u User) float64 {
+		switch name := agent.NormalizeName(html.UnescapeString(u.DisplayName)); {
+		case name == "":
+			return 0
+		case name == fullName && strings.Contains(fullName, " "):
+			return confidenceFullName
+		case name == username:
+			return confidenceUsername
+		case name == fullName:
+			return confidenceSingleName
+		}
+		return 0
+	}
+
+	var best *User
+	var bestConfidence float64
+	for i, u := range users {
+		confidence := base(u)
+		if confidence == 0 {
+			// inname also matches parts of names, which say nothing
+			continue
+		}
+		if website := agent.NormalizeURL(u.WebsiteURL); website != "" &&
+			(website == agent.NormalizeURL(candidate.Blog) || website == agent.NormalizeURL(candidate.GitHubURL)) {
+			confidence += confidenceWebsite
+		}
+		if agent.SharePlace(candidate.Location, html.UnescapeString(u.Location)) {
+			confidence += confidenceLocation
+		}
+		namesakes := 0
+		for _, other := range users {
+			if base(other) > 0 {
+				namesakes++
+			}
+		}
+		if namesakes == 1 {
+			confidence += confidenceUnique
+		}
+		confidence = math.Round(min(confidence, maxNameMatchConfidence)*100) / 100
+		if confidence > bestConfidence {
+			best, bestConfidence = &users[i], confidence
+		}
+	}
+	return best, bestConfidence
+}
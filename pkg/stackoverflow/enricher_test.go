@@ -0,0 +1,180 @@
+package stackoverflow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// newTestServer serves the users 1 (Ana Pérez, in Lima, linking back to
+// github.com/anap), 2 (Ana Perez, in Madrid), 3 (ana) and 4 (ana perez, in
+// Quito) with tags
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	users := map[string]map[string]any{
+		"1": {"user_id": 1, "display_name": "Ana P&#233;rez", "reputation": 5400, "link": "https://stackoverflow.com/users/1/ana-perez",
+			"location": "Lima, Peru", "website_url": "https://github.com/anap"},
+		"2": {"user_id": 2, "display_name": "Ana Perez", "reputation": 900, "link": "https://stackoverflow.com/users/2/ana-perez",
+			"location": "Madrid, Spain"},
+		"3": {"user_id": 3, "display_name": "ana", "reputation": 12000, "link": "https://stackoverflow.com/users/3/ana"},
+		"4": {"user_id": 4, "display_name": "ana perez", "reputation": 300, "link": "https://stackoverflow.com/users/4/ana-perez",
+			"location": "Quito, Ecuador"},
+	}
+	writeItems := func(w http.ResponseWriter, items ...any) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"items": items, "quota_remaining": 299})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("site") != "stackoverflow" || q.Get("key") != "test-key" || q.Get("sort") != "reputation" {
+			t.Errorf("Unexpected query %v", q)
+		}
+		switch q.Get("inname") {
+		case "Ana Pérez":
+			writeItems(w, users["3"], users["1"], users["2"])
+		case "Ana Perez":
+			writeItems(w, users["2"], users["4"])
+		case "Ana":
+			writeItems(w, users["3"])
+		case "throttled":
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{"error_id": 502, "error_name": "throttle_violation", "error_message": "too many requests"})
+		default:
+			writeItems(w)
+		}
+	})
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if user, ok := users[r.PathValue("id")]; ok {
+			writeItems(w, user)
+			return
+		}
+		writeItems(w)
+	})
+	mux.HandleFunc("GET /users/{id}/top-tags", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("pagesize") != "5" {
+			t.Errorf("Expected five tags, got %q", r.URL.Query().Get("pagesize"))
+		}
+		writeItems(w, map[string]any{"tag_name": "go", "answer_score": 900}, map[string]any{"tag_name": "grpc", "answer_score": 120})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestEnricher(server *httptest.Server) *Enricher {
+	c := NewClient("test-key")
+	c.BaseURL = server.URL
+	return &Enricher{Client: c}
+}
+
+func TestEnrichByLink(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	candidate := &agent.EnrichedCandidate{Username: "anap", Name: "Someone Else", Bio: "Gopher. https://stackoverflow.com/users/2/ana-perez"}
+	if err := e.Enrich(context.Background(), candidate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(candidate.ExternalProfiles) != 1 {
+		t.Fatalf("Expected one profile, got %+v", candidate.ExternalProfiles)
+	}
+	got := candidate.ExternalProfiles[0]
+	if got.Platform != Platform || got.Confidence != 1 || got.MatchedBy != agent.MatchedByLink || got.Reputation != 900 ||
+		len(got.TopTags) != 2 || got.TopTags[0] != "go" {
+		t.Errorf("Unexpected profile %+v", got)
+	}
+
+	// A second lookup keeps the profile found
+	if err := e.Enrich(context.Background(), candidate); err != nil || len(candidate.ExternalProfiles) != 1 {
+		t.Errorf("Expected the profile to be kept, got %+v, %v", candidate.ExternalProfiles, err)
+	}
+}
+
+func TestEnrichByName(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	candidate := &agent.EnrichedCandidate{Username: "anap", Name: "Ana Pérez", Location: "Lima", GitHubURL: "https://github.com/anap"}
+	if err := e.Enrich(context.Background(), candidate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(candidate.ExternalProfiles) != 1 {
+		t.Fatalf("Expected one profile, got %+v", candidate.ExternalProfiles)
+	}
+	// The full name, website and location all match user 1, over the
+	// namesake with more reputation
+	got := candidate.ExternalProfiles[0]
+	if got.DisplayName != "Ana Pérez" || got.Confidence != maxNameMatchConfidence || got.MatchedBy != agent.MatchedByName {
+		t.Errorf("Unexpected profile %+v", got)
+	}
+}
+
+func TestEnrichBelowConfidence(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	// A one-word name matching a single user scores 0.4
+	candidate := &agent.EnrichedCandidate{Username: "ana-dev", Name: "Ana"}
+	if err := e.Enrich(context.Background(), candidate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(candidate.ExternalProfiles) != 0 {
+		t.Errorf("Expected no profile, got %+v", candidate.ExternalProfiles)
+	}
+
+	e.MinConfidence = 0.4
+	if err := e.Enrich(context.Background(), candidate); err != nil || len(candidate.ExternalProfiles) != 1 {
+		t.Errorf("Expected the profile with a lower minimum, got %+v, %v", candidate.ExternalProfiles, err)
+	}
+}
+
+func TestEnrichNamesakes(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	// The full name alone matches users 2 and 4 at 0.5, with nothing else in
+	// common to tell them apart
+	candidate := &agent.EnrichedCandidate{Username: "ana-tokyo", Name: "Ana Perez", Location: "Tokyo"}
+	if err := e.Enrich(context.Background(), candidate); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(candidate.ExternalProfiles) != 0 {
+		t.Errorf("Expected no profile, got %+v", candidate.ExternalProfiles)
+	}
+
+	// A location in common tells them apart
+	candidate.Location = "Madrid"
+	if err := e.Enrich(context.Background(), candidate); err != nil || len(candidate.ExternalProfiles) != 1 ||
+		candidate.ExternalProfiles[0].Reputation != 900 {
+		t.Errorf("Expected user 2 by location, got %+v, %v", candidate.ExternalProfiles, err)
+	}
+}
+
+func TestEnrichThrottled(t *testing.T) {
+	e := newTestEnricher(newTestServer(t))
+	err := e.Enrich(context.Background(), &agent.EnrichedCandidate{Username: "throttled"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !apiErr.RateLimited() {
+		t.Errorf("Expected a rate-limited APIError, got %v", err)
+	}
+}
+
+func TestBestNameMatch(t *testing.T) {
+	candidate := &agent.EnrichedCandidate{Username: "jdoe", Name: "Jane Doe", Location: "Berlin, Germany", Blog: "https://jane.dev/"}
+	tests := []struct {
+		name  string
+		users []User
+		want  float64
+	}{
+		{"no namesake", []User{{DisplayName: "Jane Doe-Smith"}}, 0},
+		{"full name", []User{{DisplayName: "Jane Doe"}, {DisplayName: "jane  doe"}}, 0.5},
+		{"unique full name", []User{{DisplayName: "Jane Doe"}}, 0.6},
+		{"username and place", []User{{DisplayName: "jdoe", Location: "Berlin"}, {DisplayName: "Jane Doe"}}, 0.6},
+		{"website", []User{{DisplayName: "Jane Doe", WebsiteURL: "http://www.jane.dev"}, {DisplayName: "Jane Doe"}}, 0.9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := bestNameMatch(candidate, tt.users); got != tt.want {
+				t.Errorf("Expected confidence %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package stackoverflow
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// APIError is an error response from the Stack Exchange API
+type APIError struct {
+	StatusCode int
+	Name       string // e.g. throttle_violation
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("Stack Exchange API request failed with status %d: %s: %s", e.StatusCode, e.Name, e.Message)
+	}
+	return fmt.Sprintf("Stack Exchange API request failed with status %d: %s", e.StatusCode, e.Message)
+}
+
+// RateLimited reports whether the request was rejected for exceeding the
+// throttle or the daily quota
+func (e *APIError) RateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.Name == "throttle_violation"
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Feedback is a recruiter's verdict on a candidate surfaced by a run,
+// learned from for the role family of the run's requirements
+type Feedback struct {
+	Time       time.Time `json:"time"`
+	RunID      string    `json:"run_id"`
+	RoleFamily string    `json:"role_family"`
+	Verdict    string    `json:"verdict"` // One of agent.Verdicts
+}
+
+// checkVerdict returns an error for an unknown verdict
+func checkVerdict(verdict string) error {
+	if !slices.Contains(agent.Verdicts, verdict) {
+		return fmt.Errorf("%w verdict %q: want one of %s", ErrInvalid, verdict, strings.Join(agent.Verdicts, ", "))
+	}
+	return nil
+}
+
+// AddFeedback records a verdict on username at the given time, on their
+// ranking by runID, or by the latest run that surfaced them when empty. A
+// later verdict on the same run replaces the earlier one.
+func (s *Store) AddFeedback(ctx context.Context, username, runID, verdict string, at time.Time) (*Feedback, error) {
+	if err := checkVerdict(verdict); err != nil {
+		return nil, err
+	}
+	query := `
+		SELECT c.run_id, r.requirements FROM candidates c
+		JOIN runs r ON r.id = c.run_id
+		WHERE LOWER(c.username) = ?`
+	args := []any{strings.ToLower(username)}
+	if runID != "" {
+		query += ` AND c.run_id = ?`
+		args = append(args, runID)
+	}
+	feedback := Feedback{Time: at.UTC(), Verdict: verdict}
+	var requirements sql.NullString
+	err := s.db.QueryRowContext(ctx, s.rebind(query+` ORDER BY r.started_at DESC, r.id DESC LIMIT 1`), args...).
+		Scan(&feedback.RunID, &requirements)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) && runID != "":
+		return nil, fmt.Errorf("candidate %s in run %s %w", username, runID, ErrNotFound)
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("candidate %s %w", username, ErrNotFound)
+	case err != nil:
+		return nil, fmt.Errorf("failed to read candidate: %w", err)
+	}
+	var req *agent.Requirements
+	if requirements.Valid {
+		if err := json.Unmarshal([]byte(requirements.String), &req); err != nil {
+			return nil, fmt.Errorf("failed to decode requirements of run %s: %w", feedback.RunID, err)
+		}
+	}
+	feedback.RoleFamily = agent.RoleFamily(req)
+
+	if _, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO feedback (username, run_id, role_family, verdict, created_at) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (username, run_id) DO UPDATE SET
+			role_family = excluded.role_family, verdict = excluded.verdict, created_at = excluded.created_at`),
+		strings.ToLower(username), feedback.RunID, feedback.RoleFamily, verdict, feedback.Time); err != nil {
+		return nil, fmt.Errorf("failed to record feedback: %w", err)
+	}
+	return &feedback, nil
+}
+
+// feedback returns the verdicts on username, oldest first
+func (s *Store) feedback(ctx context.Context, username string) ([]Feedback, error) {
+	var list []Feedback
+	err := s.scanAll(ctx, `SELECT created_at, run_id, role_family, verdict FROM feedback WHERE username = ? ORDER BY created_at`,
+		[]any{strings.ToLower(username)}, func(rows *sql.Rows) error {
+			var f Feedback
+			err := rows.Scan(&f.Time, &f.RunID, &f.RoleFamily, &f.Verdict)
+			list = append(list, f)
+			return err
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	}
+	return list, nil
+}
+
+// Scorings returns the scoring of every role family with feedback, by
+// family name; the default weights for those without enough feedback yet
+func (s *Store) Scorings(ctx context.Context) ([]agent.Scoring, error) {
+	var scorings []agent.Scoring
+	err := s.scanAll(ctx, `SELECT role_family, COUNT(*) FROM feedback GROUP BY role_family ORDER BY role_family`, nil, func(rows *sql.Rows) error {
+		scoring := agent.Scoring{Weights: agent.DefaultScoringWeights}
+		err := rows.Scan(&scoring.RoleFamily, &scoring.Feedback)
+		scorings = append(scorings, scoring)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	}
+	for i, scoring := range scorings {
+		if scoring.Feedback < agent.MinFeedback {
+			continue
+		}
+		learned, err := s.Scoring(ctx, scoring.RoleFamily)
+		if err != nil {
+			return nil, err
+		}
+		if learned != nil {
+			scorings[i] = *learned
+		}
+	}
+	return scorings, nil
+}
+
+// Scoring returns the scoring learned from the verdicts on candidates of
+// family, with their match breakdowns and keywords in the rated runs; nil
+// without enough feedback. It implements agent.ScoringStore.
+func (s *Store) Scoring(ctx context.Context, family string) (*agent.Scoring, error) {
+	var feedback []agent.Feedback
+	err := s.scanAll(ctx, `
+		SELECT f.verdict, c.profile, k.candidate FROM feedback f
+		JOIN candidates c ON c.run_id = f.run_id AND LOWER(c.username) = f.username
+		LEFT JOIN rankings k ON k.run_id = f.run_id AND LOWER(k.username) = f.username
+		WHERE f.role_family = ?
+		ORDER BY f.created_at`, []any{family}, func(rows *sql.Rows) error {
+		var verdict, profile string
+		var ranked sql.NullString
+		if err := rows.Scan(&verdict, &profile, &ranked); err != nil {
+			return err
+		}
+		f := agent.Feedback{Verdict: verdict}
+		var candidate agent.EnrichedCandidate
+		if json.Unmarshal([]byte(profile), &candidate) == nil {
+			f.Keywords = agent.CandidateKeywords(candidate)
+		}
+		var rankedCandidate agent.RankedCandidate
+		if ranked.Valid && json.Unmarshal([]byte(ranked.String), &rankedCandidate) == nil {
+			f.Breakdown = &rankedCandidate.MatchBreakdown
+		}
+		feedback = append(feedback, f)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feedback: %w", err)
+	}
+	return agent.LearnScoring(family, feedback), nil
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// saveBackendRun saves a run for a backend role ranking each of the
+// usernames with breakdown and a relevant repository in language
+func saveBackendRun(t *testing.T, store *Store, id string, at time.Time, breakdown agent.MatchBreakdown, language string, usernames ...string) {
+	t.Helper()
+	candidates := &agent.EnrichedCandidates{}
+	result := &agent.FinalResult{RunID: id}
+	for i, username := range usernames {
+		candidates.Candidates = append(candidates.Candidates, agent.EnrichedCandidate{Username: username,
+			RelevantRepositories: []agent.RelevantRepository{{Name: "repo", Language: language}}})
+		result.TopCandidates = append(result.TopCandidates, agent.RankedCandidate{Rank: i + 1, Username: username, MatchBreakdown: breakdown})
+	}
+	run := agent.RunRecord{
+		Checkpoint: agent.Checkpoint{RunID: id, Query: "Go developers", Stage: agent.StageRanking, Time: at,
+			Requirements: &agent.Requirements{RequiredSkills: []string{"Go", "PostgreSQL"}}, Candidates: candidates},
+		StartedAt: at,
+		Result:    result,
+	}
+	if err := store.SaveRun(context.Background(), run); err != nil {
+		t.Fatalf("Expected to save %s, got %v", id, err)
+	}
+}
+
+func TestFeedback(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	started := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	experienced := agent.MatchBreakdown{RequiredSkillsScore: 60, RepositoryRelevanceScore: 70, ExperienceScore: 90, ProfileQualityScore: 60}
+	skilled := agent.MatchBreakdown{RequiredSkillsScore: 90, RepositoryRelevanceScore: 70, ExperienceScore: 40, ProfileQualityScore: 60}
+	var liked, disliked []string
+	for i := range 3 {
+		liked, disliked = append(liked, fmt.Sprint("liked", i)), append(disliked, fmt.Sprint("disliked", i))
+	}
+	saveBackendRun(t, store, "run-1", started, experienced, "Rust", liked...)
+	saveBackendRun(t, store, "run-2", started.Add(time.Hour), skilled, "PHP", disliked...)
+
+	if _, err := store.AddFeedback(ctx, "liked0", "", "love", started); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Expected ErrInvalid for an unknown verdict, got %v", err)
+	}
+	if _, err := store.AddFeedback(ctx, "dave", "", agent.VerdictUp, started); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an unknown candidate, got %v", err)
+	}
+	if _, err := store.AddFeedback(ctx, "liked0", "run-2", agent.VerdictUp, started); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a run that did not surface them, got %v", err)
+	}
+
+	// A verdict defaults to the latest run, and a later one on the same run
+	// replaces it
+	feedback, err := store.AddFeedback(ctx, "Liked0", "", agent.VerdictDown, started)
+	if err != nil {
+		t.Fatalf("Expected to record the feedback, got %v", err)
+	}
+	if feedback.RunID != "run-1" || feedback.RoleFamily != agent.RoleFamilyBackend {
+		t.Errorf("Expected feedback on run-1 for a backend role, got %+v", feedback)
+	}
+	for _, username := range liked {
+		if _, err := store.AddFeedback(ctx, username, "run-1", agent.VerdictHired, started.Add(time.Hour)); err != nil {
+			t.Fatalf("Expected to record the feedback, got %v", err)
+		}
+	}
+	c, err := store.Candidate(ctx, "liked0")
+	if err != nil {
+		t.Fatalf("Expected liked0, got %v", err)
+	}
+	if len(c.Feedback) != 1 || c.Feedback[0].Verdict != agent.VerdictHired {
+		t.Errorf("Expected the hired verdict to replace the earlier one, got %+v", c.Feedback)
+	}
+
+	// Too little feedback keeps the defaults
+	if scoring, err := store.Scoring(ctx, agent.RoleFamilyBackend); err != nil || scoring != nil {
+		t.Errorf("Expected no learned scoring yet, got %+v, %v", scoring, err)
+	}
+	for _, username := range disliked {
+		if _, err := store.AddFeedback(ctx, username, "", agent.VerdictRejected, started.Add(time.Hour)); err != nil {
+			t.Fatalf("Expected to record the feedback, got %v", err)
+		}
+	}
+	scoring, err := store.Scoring(ctx, agent.RoleFamilyBackend)
+	if err != nil || scoring == nil {
+		t.Fatalf("Expected a learned scoring, got %+v, %v", scoring, err)
+	}
+	if scoring.Feedback != 6 || scoring.Weights.Experience <= agent.DefaultScoringWeights.Experience {
+		t.Errorf("Expected experience weighed up from 6 entries, got %+v", scoring)
+	}
+	if scoring.KeywordBoosts["rust"] <= 0 || scoring.KeywordBoosts["php"] >= 0 {
+		t.Errorf("Expected rust boosted and php penalized, got %v", scoring.KeywordBoosts)
+	}
+
+	scorings, err := store.Scorings(ctx)
+	if err != nil || len(scorings) != 1 || scorings[0].RoleFamily != agent.RoleFamilyBackend || scorings[0].KeywordBoosts == nil {
+		t.Errorf("Expected the learned backend scoring, got %+v, %v", scorings, err)
+	}
+}
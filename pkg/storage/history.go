@@ -0,0 +1,270 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Candidate is a candidate as tracked across the stored runs. Usernames are
+// matched case-insensitively, as on GitHub.
+type Candidate struct {
+	Username string `json:"username"`
+	// Name and Location as of the latest run that surfaced the candidate
+	Name        string     `json:"name,omitempty"`
+	Location    string     `json:"location,omitempty"`
+	FirstSeenAt time.Time  `json:"first_seen_at"`
+	LastSeenAt  time.Time  `json:"last_seen_at"`
+	Runs        int        `json:"runs"` // Number of runs that surfaced the candidate
+	BestScore   *float64   `json:"best_score,omitempty"`
+	ContactedAt *time.Time `json:"contacted_at,omitempty"` // When last contacted, if ever
+
+	// The candidate in the talent pool: their pipeline status, new until
+	// set, and tags
+	Status string   `json:"status"`
+	Tags   []string `json:"tags,omitempty"`
+
+	// Score history, contacts, notes and feedback, oldest first; left out
+	// by ListCandidates
+	Sightings []Sighting `json:"sightings,omitempty"`
+	Contacts  []Contact  `json:"contacts,omitempty"`
+	Notes     []Note     `json:"notes,omitempty"`
+	Feedback  []Feedback `json:"feedback,omitempty"`
+}
+
+// Sighting is a run that surfaced a candidate, with the scores it gave them
+type Sighting struct {
+	RunID             string    `json:"run_id"`
+	Query             string    `json:"query"`
+	Time              time.Time `json:"time"` // When the run started
+	InitialMatchScore float64   `json:"initial_match_score"`
+	// Rank and FinalMatchScore are set when the run ranked the candidate
+	Rank            int      `json:"rank,omitempty"`
+	FinalMatchScore *float64 `json:"final_match_score,omitempty"`
+
+	username string
+	profile  string
+}
+
+// Contact records that a candidate was contacted, from the results of RunID
+// if set
+type Contact struct {
+	Time  time.Time `json:"time"`
+	RunID string    `json:"run_id,omitempty"`
+}
+
+// sightings returns the sightings of the usernames, all candidates for none,
+// in the runs other than exceptRun, oldest first
+func (s *Store) sightings(ctx context.Context, exceptRun string, usernames []string) ([]Sighting, error) {
+	query := `
+		SELECT c.username, c.profile, r.id, r.query, r.started_at, c.initial_match_score, k.rank, k.final_match_score
+		FROM candidates c
+		JOIN runs r ON r.id = c.run_id
+		LEFT JOIN rankings k ON k.run_id = c.run_id AND k.username = c.username
+		WHERE c.run_id <> ?`
+	args := []any{exceptRun}
+	if len(usernames) > 0 {
+		query += ` AND LOWER(c.username) IN (?` + strings.Repeat(`, ?`, len(usernames)-1) + `)`
+		for _, username := range usernames {
+			args = append(args, strings.ToLower(username))
+		}
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(query+` ORDER BY r.started_at, r.id`), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read candidate history: %w", err)
+	}
+	defer rows.Close()
+	var sightings []Sighting
+	for rows.Next() {
+		var sighting Sighting
+		var rank sql.NullInt64
+		var score sql.NullFloat64
+		if err := rows.Scan(&sighting.username, &sighting.profile, &sighting.RunID, &sighting.Query, &sighting.Time,
+			&sighting.InitialMatchScore, &rank, &score); err != nil {
+			return nil, fmt.Errorf("failed to read candidate history: %w", err)
+		}
+		if rank.Valid {
+			sighting.Rank, sighting.FinalMatchScore = int(rank.Int64), &score.Float64
+		}
+		sightings = append(sightings, sighting)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read candidate history: %w", err)
+	}
+	return sightings, nil
+}
+
+// contacts returns the contacts of the usernames, all candidates for none,
+// by lowercased username, oldest first
+func (s *Store) contacts(ctx context.Context, usernames []string) (map[string][]Contact, error) {
+	where, args := usernameFilter(usernames)
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT username, contacted_at, run_id FROM contacts`+where+` ORDER BY contacted_at`), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read contacts: %w", err)
+	}
+	defer rows.Close()
+	contacts := make(map[string][]Contact)
+	for rows.Next() {
+		var username string
+		var contact Contact
+		var runID sql.NullString
+		if err := rows.Scan(&username, &contact.Time, &runID); err != nil {
+			return nil, fmt.Errorf("failed to read contacts: %w", err)
+		}
+		contact.RunID = runID.String
+		contacts[username] = append(contacts[username], contact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read contacts: %w", err)
+	}
+	return contacts, nil
+}
+
+// SeenBefore returns what the runs other than runID knew of the usernames
+// they surfaced, by username as given. It implements
+// agent.CandidateHistory.
+func (s *Store) SeenBefore(ctx context.Context, runID string, usernames []string) (map[string]agent.SeenBefore, error) {
+	if len(usernames) == 0 {
+		return nil, nil
+	}
+	candidates, err := s.candidates(ctx, runID, usernames, false)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]agent.SeenBefore)
+	for _, username := range usernames {
+		c, ok := candidates[strings.ToLower(username)]
+		if !ok {
+			continue
+		}
+		last := c.Sightings[len(c.Sightings)-1]
+		seen[username] = agent.SeenBefore{RunID: last.RunID, Query: last.Query, Time: last.Time,
+			FinalMatchScore: last.FinalMatchScore, Runs: c.Runs, ContactedAt: c.ContactedAt, Status: c.Status, Tags: c.Tags}
+	}
+	return seen, nil
+}
+
+// candidates returns the history of the usernames, all candidates for none,
+// in the runs other than exceptRun, with their place in the talent pool, by
+// lowercased username
+func (s *Store) candidates(ctx context.Context, exceptRun string, usernames []string, withNotes bool) (map[string]*Candidate, error) {
+	sightings, err := s.sightings(ctx, exceptRun, usernames)
+	if err != nil {
+		return nil, err
+	}
+	contacts, err := s.contacts(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := s.pool(ctx, usernames, withNotes)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make(map[string]*Candidate)
+	for _, sighting := range sightings {
+		key := strings.ToLower(sighting.username)
+		c, ok := candidates[key]
+		if !ok {
+			c = &Candidate{Username: sighting.username, FirstSeenAt: sighting.Time, Contacts: contacts[key], Status: StatusNew}
+			if n := len(c.Contacts); n > 0 {
+				c.ContactedAt = &c.Contacts[n-1].Time
+			}
+			if entry, ok := pool[key]; ok {
+				c.Status, c.Tags, c.Notes = entry.status, entry.tags, entry.notes
+			}
+			candidates[key] = c
+		}
+		// Sightings come oldest first, so the latest profile wins
+		var profile agent.EnrichedCandidate
+		if json.Unmarshal([]byte(sighting.profile), &profile) == nil {
+			c.Username, c.Name, c.Location = sighting.username, profile.Name, profile.Location
+		}
+		c.LastSeenAt = sighting.Time
+		c.Runs++
+		if score := sighting.FinalMatchScore; score != nil && (c.BestScore == nil || *score > *c.BestScore) {
+			c.BestScore = score
+		}
+		c.Sightings = append(c.Sightings, sighting)
+	}
+	return candidates, nil
+}
+
+// Candidate returns the history of username across the stored runs: every
+// run that surfaced them, with its scores, every contact, note and verdict
+func (s *Store) Candidate(ctx context.Context, username string) (*Candidate, error) {
+	candidates, err := s.candidates(ctx, "", []string{username}, true)
+	if err != nil {
+		return nil, err
+	}
+	c, ok := candidates[strings.ToLower(username)]
+	if !ok {
+		return nil, fmt.Errorf("candidate %s %w", username, ErrNotFound)
+	}
+	if c.Feedback, err = s.feedback(ctx, username); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ListCandidates returns the candidates selected by filter, most recently
+// seen first, without their sightings, contacts and notes
+func (s *Store) ListCandidates(ctx context.Context, filter CandidateFilter) ([]Candidate, error) {
+	if filter.Status != "" {
+		if err := checkStatus(filter.Status); err != nil {
+			return nil, err
+		}
+	}
+	candidates, err := s.candidates(ctx, "", nil, false)
+	if err != nil {
+		return nil, err
+	}
+	tag := strings.ToLower(strings.TrimSpace(filter.Tag))
+	list := make([]Candidate, 0, len(candidates))
+	for _, c := range candidates {
+		if (filter.Status != "" && c.Status != filter.Status) || (tag != "" && !slices.Contains(c.Tags, tag)) {
+			continue
+		}
+		c.Sightings, c.Contacts, c.Notes = nil, nil, nil
+		list = append(list, *c)
+	}
+	slices.SortFunc(list, func(a, b Candidate) int {
+		if c := b.LastSeenAt.Compare(a.LastSeenAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Username, b.Username)
+	})
+	if filter.Limit > 0 && len(list) > filter.Limit {
+		list = list[:filter.Limit]
+	}
+	return list, nil
+}
+
+// MarkContacted records that username was contacted at the given time, from
+// the results of runID if not empty, moving a new candidate to contacted in
+// the pipeline. The candidate must have been surfaced by a stored run.
+func (s *Store) MarkContacted(ctx context.Context, username, runID string, at time.Time) error {
+	if err := s.checkKnown(ctx, username); err != nil {
+		return err
+	}
+	var run sql.NullString
+	if runID != "" {
+		run = sql.NullString{String: runID, Valid: true}
+	}
+	if _, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO contacts (username, contacted_at, run_id) VALUES (?, ?, ?) ON CONFLICT DO NOTHING`),
+		strings.ToLower(username), at.UTC(), run); err != nil {
+		return fmt.Errorf("failed to record contact: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO pool (username, status, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (username) DO UPDATE SET status = excluded.status, updated_at = excluded.updated_at
+		WHERE pool.status = ?`), strings.ToLower(username), StatusContacted, at.UTC(), StatusNew); err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// saveRankedRun saves a finished run surfacing the usernames, ranking the
+// first one with score
+func saveRankedRun(t *testing.T, store *Store, id string, at time.Time, score float64, usernames ...string) {
+	t.Helper()
+	candidates := &agent.EnrichedCandidates{}
+	for _, username := range usernames {
+		candidates.Candidates = append(candidates.Candidates, agent.EnrichedCandidate{Username: username, Name: "Name of " + id, InitialMatchScore: 0.5})
+	}
+	run := agent.RunRecord{
+		Checkpoint: agent.Checkpoint{RunID: id, Query: "query " + id, Stage: agent.StageRanking, Time: at, Candidates: candidates},
+		StartedAt:  at,
+		Result: &agent.FinalResult{RunID: id, TopCandidates: []agent.RankedCandidate{
+			{Rank: 1, Username: usernames[0], FinalMatchScore: score},
+		}},
+	}
+	if err := store.SaveRun(context.Background(), run); err != nil {
+		t.Fatalf("Expected to save %s, got %v", id, err)
+	}
+}
+
+func TestCandidateHistory(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	started := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	saveRankedRun(t, store, "run-1", started, 70, "alice", "bob")
+	saveRankedRun(t, store, "run-2", started.Add(time.Hour), 85, "Alice")
+	saveRankedRun(t, store, "run-3", started.Add(2*time.Hour), 60, "carol", "alice")
+
+	alice, err := store.Candidate(ctx, "ALICE")
+	if err != nil {
+		t.Fatalf("Expected alice's history, got %v", err)
+	}
+	if alice.Runs != 3 || len(alice.Sightings) != 3 || alice.Name != "Name of run-3" || !alice.FirstSeenAt.Equal(started) {
+		t.Errorf("Expected alice seen in 3 runs since run-1, got %+v", alice)
+	}
+	if alice.BestScore == nil || *alice.BestScore != 85 || alice.Sightings[2].FinalMatchScore != nil || alice.Sightings[1].Rank != 1 {
+		t.Errorf("Expected a best score of 85 and no ranking in run-3, got %+v", alice)
+	}
+	if _, err := store.Candidate(ctx, "dave"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	contacted := started.Add(3 * time.Hour)
+	if err := store.MarkContacted(ctx, "alice", "run-2", contacted); err != nil {
+		t.Fatalf("Expected to record the contact, got %v", err)
+	}
+	if err := store.MarkContacted(ctx, "dave", "", contacted); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected contacting an unknown candidate to fail with ErrNotFound, got %v", err)
+	}
+
+	// A new run learns what the others knew, but not what it surfaced itself
+	seen, err := store.SeenBefore(ctx, "run-3", []string{"Alice", "carol", "dave"})
+	if err != nil {
+		t.Fatalf("Expected the candidates seen before, got %v", err)
+	}
+	if len(seen) != 1 {
+		t.Fatalf("Expected only Alice seen before, got %+v", seen)
+	}
+	before := seen["Alice"]
+	if before.RunID != "run-2" || before.Runs != 2 || before.FinalMatchScore == nil || *before.FinalMatchScore != 85 ||
+		before.ContactedAt == nil || !before.ContactedAt.Equal(contacted) {
+		t.Errorf("Expected Alice last seen in run-2 with 85 and contacted, got %+v", before)
+	}
+
+	list, err := store.ListCandidates(ctx, CandidateFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected the candidates, got %v", err)
+	}
+	if len(list) != 2 || list[0].Username != "alice" || list[1].Username != "carol" || list[0].Sightings != nil || list[0].ContactedAt == nil {
+		t.Errorf("Expected alice then carol, last seen in run-3, got %+v", list)
+	}
+}
@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Pipeline statuses of a candidate in the talent pool
+const (
+	StatusNew       = "new"
+	StatusContacted = "contacted"
+	StatusReplied   = "replied"
+	StatusRejected  = "rejected"
+)
+
+// Statuses lists the pipeline statuses, in pipeline order
+var Statuses = []string{StatusNew, StatusContacted, StatusReplied, StatusRejected}
+
+// ErrInvalid is returned for a status, tag or note the pool does not accept
+var ErrInvalid = errors.New("invalid")
+
+// Note is a recruiter's note on a candidate
+type Note struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// CandidateFilter selects the candidates ListCandidates returns
+type CandidateFilter struct {
+	Status string // Only candidates with this pipeline status, if set
+	Tag    string // Only candidates tagged so, if set
+	Limit  int    // At most this many, the most recently seen; all for zero
+}
+
+// checkStatus returns an error for an unknown pipeline status
+func checkStatus(status string) error {
+	if !slices.Contains(Statuses, status) {
+		return fmt.Errorf("%w status %q: want one of %s", ErrInvalid, status, strings.Join(Statuses, ", "))
+	}
+	return nil
+}
+
+// checkKnown returns ErrNotFound for a username no stored run surfaced, as
+// the pool only holds candidates found by a run
+func (s *Store) checkKnown(ctx context.Context, username string) error {
+	var n int
+	if err := s.db.QueryRowContext(ctx, s.rebind(`SELECT COUNT(*) FROM candidates WHERE LOWER(username) = ?`),
+		strings.ToLower(username)).Scan(&n); err != nil {
+		return fmt.Errorf("failed to read candidate: %w", err)
+	}
+	if n == 0 {
+		return fmt.Errorf("candidate %s %w", username, ErrNotFound)
+	}
+	return nil
+}
+
+// SetStatus moves username to status in the pipeline at the given time.
+// Moving them to contacted records a contact, as MarkContacted does.
+func (s *Store) SetStatus(ctx context.Context, username, status string, at time.Time) error {
+	if err := checkStatus(status); err != nil {
+		return err
+	}
+	if err := s.checkKnown(ctx, username); err != nil {
+		return err
+	}
+	if status == StatusContacted {
+		if err := s.MarkContacted(ctx, username, "", at); err != nil {
+			return err
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO pool (username, status, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (username) DO UPDATE SET status = excluded.status, updated_at = excluded.updated_at`),
+		strings.ToLower(username), status, at.UTC()); err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
+	return nil
+}
+
+// normalizeTags returns tags trimmed and lowercased, without duplicates,
+// failing for an empty one
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			return nil, fmt.Errorf("%w tag: empty", ErrInvalid)
+		}
+		if !slices.Contains(normalized, tag) {
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
+
+// AddTags tags username with tags, lowercased; tags it already has are kept
+func (s *Store) AddTags(ctx context.Context, username string, tags ...string) error {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if err := s.checkKnown(ctx, username); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO tags (username, tag) VALUES (?, ?) ON CONFLICT DO NOTHING`),
+			strings.ToLower(username), tag); err != nil {
+			return fmt.Errorf("failed to tag candidate: %w", err)
+		}
+	}
+	return nil
+}
+
+// RemoveTags removes tags from username; tags it does not have are ignored
+func (s *Store) RemoveTags(ctx context.Context, username string, tags ...string) error {
+	tags, err := normalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM tags WHERE username = ? AND tag = ?`),
+			strings.ToLower(username), tag); err != nil {
+			return fmt.Errorf("failed to untag candidate: %w", err)
+		}
+	}
+	return nil
+}
+
+// AddNote attaches a note to username at the given time
+func (s *Store) AddNote(ctx context.Context, username, text string, at time.Time) error {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("%w note: empty", ErrInvalid)
+	}
+	if err := s.checkKnown(ctx, username); err != nil {
+		return err
+	}
+	if _, err := s.db.ExecContext(ctx, s.rebind(`INSERT INTO notes (username, created_at, text) VALUES (?, ?, ?)`),
+		strings.ToLower(username), at.UTC(), text); err != nil {
+		return fmt.Errorf("failed to add note: %w", err)
+	}
+	return nil
+}
+
+// poolEntry is what the pool holds of a candidate
+type poolEntry struct {
+	status string
+	tags   []string
+	notes  []Note
+}
+
+// pool returns the pool entries of the usernames, all candidates for none,
+// by lowercased username; notes are left out unless withNotes
+func (s *Store) pool(ctx context.Context, usernames []string, withNotes bool) (map[string]*poolEntry, error) {
+	where, args := usernameFilter(usernames)
+	entries := make(map[string]*poolEntry)
+	entry := func(username string) *poolEntry {
+		e, ok := entries[username]
+		if !ok {
+			e = &poolEntry{status: StatusNew}
+			entries[username] = e
+		}
+		return e
+	}
+	err := s.scanAll(ctx, `SELECT username, status FROM pool`+where, args, func(rows *sql.Rows) error {
+		var username, status string
+		err := rows.Scan(&username, &status)
+		entry(username).status = status
+		return err
+	})
+	if err == nil {
+		err = s.scanAll(ctx, `SELECT username, tag FROM tags`+where+` ORDER BY tag`, args, func(rows *sql.Rows) error {
+			var username, tag string
+			err := rows.Scan(&username, &tag)
+			entry(username).tags = append(entry(username).tags, tag)
+			return err
+		})
+	}
+	if err == nil && withNotes {
+		err = s.scanAll(ctx, `SELECT username, created_at, text FROM notes`+where+` ORDER BY created_at`, args, func(rows *sql.Rows) error {
+			var username string
+			var note Note
+			err := rows.Scan(&username, &note.Time, &note.Text)
+			entry(username).notes = append(entry(username).notes, note)
+			return err
+		})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read talent pool: %w", err)
+	}
+	return entries, nil
+}
+
+// usernameFilter returns a WHERE clause on the lowercased usernames, and
+// its arguments; none for no usernames
+func usernameFilter(usernames []string) (string, []any) {
+	if len(usernames) == 0 {
+		return "", nil
+	}
+	args := make([]any, len(usernames))
+	for i, username := range usernames {
+		args[i] = strings.ToLower(username)
+	}
+	return ` WHERE username IN (?` + strings.Repeat(`, ?`, len(usernames)-1) + `)`, args
+}
+
+// scanAll runs query and calls scan for every row
+func (s *Store) scanAll(ctx context.Context, query string, args []any, scan func(rows *sql.Rows) error) error {
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
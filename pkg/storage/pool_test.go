@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestTalentPool(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	started := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	saveRankedRun(t, store, "run-1", started, 70, "alice", "bob")
+
+	bob, err := store.Candidate(ctx, "bob")
+	if err != nil {
+		t.Fatalf("Expected bob, got %v", err)
+	}
+	if bob.Status != StatusNew || bob.Tags != nil || bob.Notes != nil {
+		t.Errorf("Expected bob new, untagged and without notes, got %+v", bob)
+	}
+
+	if err := store.AddTags(ctx, "Alice", "Backend", "remote", "backend"); err != nil {
+		t.Fatalf("Expected to tag alice, got %v", err)
+	}
+	if err := store.RemoveTags(ctx, "alice", "remote"); err != nil {
+		t.Fatalf("Expected to untag alice, got %v", err)
+	}
+	if err := store.AddNote(ctx, "alice", "  Prefers async teams ", started.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected to add a note, got %v", err)
+	}
+	// Contacting a new candidate moves them to contacted, but not back from replied
+	if err := store.MarkContacted(ctx, "alice", "run-1", started.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected to record the contact, got %v", err)
+	}
+	if err := store.SetStatus(ctx, "alice", StatusReplied, started.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Expected to set the status, got %v", err)
+	}
+	if err := store.MarkContacted(ctx, "alice", "", started.Add(3*time.Hour)); err != nil {
+		t.Fatalf("Expected to record the contact, got %v", err)
+	}
+	alice, err := store.Candidate(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Expected alice, got %v", err)
+	}
+	if alice.Status != StatusReplied || !slices.Equal(alice.Tags, []string{"backend"}) || len(alice.Contacts) != 2 {
+		t.Errorf("Expected alice replied, tagged backend and contacted twice, got %+v", alice)
+	}
+	if len(alice.Notes) != 1 || alice.Notes[0].Text != "Prefers async teams" {
+		t.Errorf("Expected the trimmed note, got %+v", alice.Notes)
+	}
+
+	// Moving to contacted records a contact
+	if err := store.SetStatus(ctx, "bob", StatusContacted, started.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected to set the status, got %v", err)
+	}
+	if bob, _ := store.Candidate(ctx, "bob"); bob.Status != StatusContacted || bob.ContactedAt == nil {
+		t.Errorf("Expected bob contacted, got %+v", bob)
+	}
+
+	for name, err := range map[string]error{
+		"unknown status":    store.SetStatus(ctx, "alice", "hired", started),
+		"empty tag":         store.AddTags(ctx, "alice", " "),
+		"empty note":        store.AddNote(ctx, "alice", "", started),
+		"status filter":     func() error { _, err := store.ListCandidates(ctx, CandidateFilter{Status: "hired"}); return err }(),
+		"unknown candidate": store.AddTags(ctx, "dave", "backend"),
+	} {
+		want := ErrInvalid
+		if name == "unknown candidate" {
+			want = ErrNotFound
+		}
+		if !errors.Is(err, want) {
+			t.Errorf("Expected %v for the %s, got %v", want, name, err)
+		}
+	}
+
+	for _, tc := range []struct {
+		filter CandidateFilter
+		want   []string
+	}{
+		{CandidateFilter{Status: StatusReplied}, []string{"alice"}},
+		{CandidateFilter{Status: StatusContacted}, []string{"bob"}},
+		{CandidateFilter{Tag: "BACKEND"}, []string{"alice"}},
+		{CandidateFilter{Status: StatusRejected}, nil},
+	} {
+		list, err := store.ListCandidates(ctx, tc.filter)
+		if err != nil {
+			t.Fatalf("Expected the candidates of %+v, got %v", tc.filter, err)
+		}
+		var got []string
+		for _, c := range list {
+			got = append(got, c.Username)
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("Expected %v for %+v, got %v", tc.want, tc.filter, got)
+		}
+	}
+
+	seen, err := store.SeenBefore(ctx, "run-2", []string{"alice"})
+	if err != nil || seen["alice"].Status != StatusReplied || !slices.Equal(seen["alice"].Tags, []string{"backend"}) {
+		t.Errorf("Expected alice seen before as replied and tagged, got %+v, %v", seen, err)
+	}
+}
@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+// Profile is a developer's profile and repositories as cached from their
+// source platform. Usernames are matched case-insensitively, as on GitHub.
+type Profile struct {
+	Username string `json:"username"`
+	Platform string `json:"platform,omitempty"` // Set when fetched from one of several platforms
+	// Detail is nil until the profile is fetched
+	Detail          *github.UserDetail `json:"detail,omitempty"`
+	DetailFetchedAt *time.Time         `json:"detail_fetched_at,omitempty"`
+	// Repositories are the most starred of the developer's repositories, up
+	// to RepositoriesLimit, nil until fetched
+	Repositories          []github.Repository `json:"repositories,omitempty"`
+	RepositoriesLimit     int                 `json:"repositories_limit,omitempty"`
+	RepositoriesFetchedAt *time.Time          `json:"repositories_fetched_at,omitempty"`
+}
+
+// ProfileFilter selects the profiles FindProfiles returns, those fetched
+// with their repositories since Since
+type ProfileFilter struct {
+	Language string // Only developers with a repository in this language, if set
+	Location string // Only developers whose location contains this, if set
+	MinRepos int    // Only developers with more public repositories than this
+	Since    time.Time
+	Limit    int // At most this many, the most followed; all for zero
+}
+
+const profileColumns = `username, platform, detail, detail_fetched_at, repositories, repositories_limit, repositories_fetched_at`
+
+// SaveProfile caches detail as the profile of username, fetched from
+// platform, if named, at the given time. The public email is left out, as
+// emails are never saved.
+func (s *Store) SaveProfile(ctx context.Context, username, platform string, detail github.UserDetail, at time.Time) error {
+	detail.Email = ""
+	data, err := json.Marshal(detail)
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO profiles (username, platform, location, languages, public_repos, followers, detail, detail_fetched_at, repositories_limit)
+		VALUES (?, ?, ?, '', ?, ?, ?, ?, 0)
+		ON CONFLICT (username) DO UPDATE SET
+			platform = CASE WHEN excluded.platform = '' THEN profiles.platform ELSE excluded.platform END,
+			location = excluded.location, public_repos = excluded.public_repos, followers = excluded.followers,
+			detail = excluded.detail, detail_fetched_at = excluded.detail_fetched_at`),
+		strings.ToLower(username), platform, strings.ToLower(detail.Location), detail.PublicRepos, detail.Followers,
+		string(data), at.UTC()); err != nil {
+		return fmt.Errorf("failed to cache profile: %w", err)
+	}
+	return nil
+}
+
+// SaveRepositories caches repos as the most starred repositories of
+// username, fetched up to limit at the given time
+func (s *Store) SaveRepositories(ctx context.Context, username string, repos []github.Repository, limit int, at time.Time) error {
+	if repos == nil {
+		repos = []github.Repository{}
+	}
+	data, err := json.Marshal(repos)
+	if err != nil {
+		return fmt.Errorf("failed to marshal repositories: %w", err)
+	}
+	if _, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO profiles (username, platform, location, languages, public_repos, followers, repositories, repositories_limit, repositories_fetched_at)
+		VALUES (?, '', '', ?, 0, 0, ?, ?, ?)
+		ON CONFLICT (username) DO UPDATE SET languages = excluded.languages, repositories = excluded.repositories,
+			repositories_limit = excluded.repositories_limit, repositories_fetched_at = excluded.repositories_fetched_at`),
+		strings.ToLower(username), languageList(repos), string(data), limit, at.UTC()); err != nil {
+		return fmt.Errorf("failed to cache repositories: %w", err)
+	}
+	return nil
+}
+
+// languageList returns the lowercased languages of repos as ",go,rust,", so
+// a language is matched by LIKE '%,go,%'
+func languageList(repos []github.Repository) string {
+	var languages []string
+	for _, r := range repos {
+		if language := strings.ToLower(r.Language); language != "" && !slices.Contains(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+	if len(languages) == 0 {
+		return ""
+	}
+	return "," + strings.Join(languages, ",") + ","
+}
+
+// Profile returns the cached profile of username
+func (s *Store) Profile(ctx context.Context, username string) (*Profile, error) {
+	profiles, err := s.profiles(ctx, `SELECT `+profileColumns+` FROM profiles WHERE username = ?`, strings.ToLower(username))
+	if err != nil {
+		return nil, err
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("profile of %s %w", username, ErrNotFound)
+	}
+	return &profiles[0], nil
+}
+
+// FindProfiles returns the cached profiles matching filter, most followed
+// first
+func (s *Store) FindProfiles(ctx context.Context, filter ProfileFilter) ([]Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM profiles
+		WHERE detail_fetched_at >= ? AND repositories_fetched_at >= ? AND public_repos > ?`
+	since := filter.Since.UTC()
+	args := []any{since, since, filter.MinRepos}
+	if filter.Language != "" {
+		query += ` AND languages LIKE ?`
+		args = append(args, "%,"+strings.ToLower(filter.Language)+",%")
+	}
+	if filter.Location != "" {
+		query += ` AND location LIKE ?`
+		args = append(args, "%"+strings.ToLower(filter.Location)+"%")
+	}
+	query += ` ORDER BY followers DESC, username`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+	return s.profiles(ctx, query, args...)
+}
+
+// profiles runs query, selecting the profileColumns
+func (s *Store) profiles(ctx context.Context, query string, args ...any) ([]Profile, error) {
+	profiles := []Profile{}
+	err := s.scanAll(ctx, query, args, func(rows *sql.Rows) error {
+		var p Profile
+		var detail, repos sql.NullString
+		var detailFetchedAt, reposFetchedAt sql.NullTime
+		if err := rows.Scan(&p.Username, &p.Platform, &detail, &detailFetchedAt, &repos, &p.RepositoriesLimit, &reposFetchedAt); err != nil {
+			return err
+		}
+		if detail.Valid {
+			if err := json.Unmarshal([]byte(detail.String), &p.Detail); err != nil {
+				return fmt.Errorf("profile of %s: %w", p.Username, err)
+			}
+			p.DetailFetchedAt = &detailFetchedAt.Time
+		}
+		if repos.Valid {
+			if err := json.Unmarshal([]byte(repos.String), &p.Repositories); err != nil {
+				return fmt.Errorf("repositories of %s: %w", p.Username, err)
+			}
+			p.RepositoriesFetchedAt = &reposFetchedAt.Time
+		}
+		profiles = append(profiles, p)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cached profiles: %w", err)
+	}
+	return profiles, nil
+}
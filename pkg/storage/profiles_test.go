@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+)
+
+func TestSaveProfileWithoutEmail(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	detail := github.UserDetail{Login: "ana", Name: "Ana", Email: "ana@example.com"}
+	if err := store.SaveProfile(ctx, "ana", "github", detail, time.Now()); err != nil {
+		t.Fatalf("Expected to cache ana, got %v", err)
+	}
+	var stored string
+	if err := store.db.QueryRowContext(ctx, `SELECT detail FROM profiles WHERE username = 'ana'`).Scan(&stored); err != nil {
+		t.Fatalf("Expected the cached profile, got %v", err)
+	}
+	if strings.Contains(stored, "ana@example.com") || !strings.Contains(stored, `"name":"Ana"`) {
+		t.Errorf("Expected the profile stored without the email, got %s", stored)
+	}
+}
+
+func TestProfileCache(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	if _, err := store.Profile(ctx, "Ana"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected no cached profile, got %v", err)
+	}
+
+	save := func(username, platform, location string, repos, followers int, languages ...string) {
+		t.Helper()
+		detail := github.UserDetail{Login: username, Location: location, PublicRepos: repos, Followers: followers}
+		if err := store.SaveProfile(ctx, username, platform, detail, at); err != nil {
+			t.Fatalf("Expected to cache %s, got %v", username, err)
+		}
+		var repositories []github.Repository
+		for _, language := range languages {
+			repositories = append(repositories, github.Repository{Name: language + "-tools", Language: language})
+		}
+		if err := store.SaveRepositories(ctx, username, repositories, 10, at); err != nil {
+			t.Fatalf("Expected to cache the repositories of %s, got %v", username, err)
+		}
+	}
+	save("Ana", "github", "Lima, Peru", 20, 50, "Go", "Rust")
+	save("bob", "", "Lima", 8, 90, "Go")
+	save("cruz", "", "Cusco, Peru", 30, 10, "Python")
+	save("dee", "", "Lima", 2, 500, "Go")
+
+	// A later fetch without a platform keeps the known one
+	if err := store.SaveProfile(ctx, "ana", "", github.UserDetail{Login: "Ana", Location: "Lima, Peru", PublicRepos: 21, Followers: 50}, at.Add(time.Hour)); err != nil {
+		t.Fatalf("Expected to refresh ana, got %v", err)
+	}
+	ana, err := store.Profile(ctx, "ANA")
+	if err != nil {
+		t.Fatalf("Expected ana's cached profile, got %v", err)
+	}
+	if ana.Platform != "github" || ana.Detail.PublicRepos != 21 || len(ana.Repositories) != 2 || ana.RepositoriesLimit != 10 ||
+		!ana.DetailFetchedAt.Equal(at.Add(time.Hour)) || !ana.RepositoriesFetchedAt.Equal(at) {
+		t.Errorf("Expected ana's refreshed profile with the repositories cached before, got %+v", ana)
+	}
+
+	profiles, err := store.FindProfiles(ctx, ProfileFilter{Language: "go", Location: "lima", MinRepos: 5, Since: at})
+	if err != nil {
+		t.Fatalf("Expected to find profiles, got %v", err)
+	}
+	var usernames []string
+	for _, p := range profiles {
+		usernames = append(usernames, p.Username)
+	}
+	if len(usernames) != 2 || usernames[0] != "bob" || usernames[1] != "ana" {
+		t.Errorf("Expected the Go developers in Lima with more than 5 repositories, most followed first, got %v", usernames)
+	}
+
+	// Profiles fetched before Since are stale
+	profiles, err = store.FindProfiles(ctx, ProfileFilter{Language: "Go", Since: at.Add(time.Minute), Limit: 5})
+	if err != nil {
+		t.Fatalf("Expected to find profiles, got %v", err)
+	}
+	if len(profiles) != 0 {
+		t.Errorf("Expected no profile with repositories fetched since, got %+v", profiles)
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// Run is a stored run. ListRuns leaves out its requirements, strategy,
+// candidates and result.
+type Run struct {
+	ID       string `json:"run_id"`
+	Query    string `json:"query"`
+	Stage    string `json:"stage"` // Last completed stage
+	Language string `json:"language,omitempty"`
+	// FinishedAt is set once the run ranked its candidates, or presented
+	// them unranked when Partial
+	StartedAt  time.Time  `json:"started_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Partial    bool       `json:"partial,omitempty"`
+	// Counts of the enriched and ranked candidates
+	CandidateCount int `json:"candidate_count"`
+	RankedCount    int `json:"ranked_count"`
+
+	Requirements *agent.Requirements       `json:"requirements,omitempty"`
+	Strategy     *agent.SearchStrategy     `json:"strategy,omitempty"`
+	Candidates   []agent.EnrichedCandidate `json:"candidates,omitempty"`
+	Result       *agent.FinalResult        `json:"result,omitempty"`
+}
+
+const runColumns = `id, query, stage, language, started_at, updated_at, finished_at, partial,
+	(SELECT COUNT(*) FROM candidates WHERE run_id = runs.id),
+	(SELECT COUNT(*) FROM rankings WHERE run_id = runs.id)`
+
+// scanRun reads the runColumns of a row
+func scanRun(row interface{ Scan(...any) error }) (Run, error) {
+	var run Run
+	var finishedAt sql.NullTime
+	err := row.Scan(&run.ID, &run.Query, &run.Stage, &run.Language, &run.StartedAt, &run.UpdatedAt, &finishedAt, &run.Partial,
+		&run.CandidateCount, &run.RankedCount)
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	return run, err
+}
+
+// ListRuns returns the last limit runs, newest first; all of them for a
+// limit of zero
+func (s *Store) ListRuns(ctx context.Context, limit int) ([]Run, error) {
+	query := `SELECT ` + runColumns + ` FROM runs ORDER BY started_at DESC, id DESC`
+	var args []any
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	defer rows.Close()
+	var runs []Run
+	for rows.Next() {
+		run, err := scanRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list runs: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	return runs, nil
+}
+
+// GetRun returns the run with id, with everything stored of it
+func (s *Store) GetRun(ctx context.Context, id string) (*Run, error) {
+	var requirements, strategy, result sql.NullString
+	row := s.db.QueryRowContext(ctx, s.rebind(`SELECT `+runColumns+`, requirements, strategy, result FROM runs WHERE id = ?`), id)
+	run, err := scanRun(scanFunc(func(dest ...any) error {
+		return row.Scan(append(dest, &requirements, &strategy, &result)...)
+	}))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("run %s %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run: %w", err)
+	}
+	for _, column := range []struct {
+		data sql.NullString
+		v    any
+	}{{requirements, &run.Requirements}, {strategy, &run.Strategy}, {result, &run.Result}} {
+		if !column.data.Valid {
+			continue
+		}
+		if err := json.Unmarshal([]byte(column.data.String), column.v); err != nil {
+			return nil, fmt.Errorf("failed to parse stored run %s: %w", id, err)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT profile FROM candidates WHERE run_id = ? ORDER BY initial_match_score DESC, username`), id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read candidates: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var profile string
+		var candidate agent.EnrichedCandidate
+		if err := rows.Scan(&profile); err != nil {
+			return nil, fmt.Errorf("failed to read candidates: %w", err)
+		}
+		if err := json.Unmarshal([]byte(profile), &candidate); err != nil {
+			return nil, fmt.Errorf("failed to parse stored candidate: %w", err)
+		}
+		run.Candidates = append(run.Candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read candidates: %w", err)
+	}
+	return &run, nil
+}
+
+// scanFunc adapts a function to the Scan method scanRun reads rows with
+type scanFunc func(dest ...any) error
+
+func (f scanFunc) Scan(dest ...any) error { return f(dest...) }
@@ -0,0 +1,286 @@
+// Package storage persists runs, with their queries, requirements,
+// strategies, enriched candidates and rankings, in SQLite or Postgres, so
+// results outlive the process and can be queried later. It tracks each
+// candidate across the runs that surfaced them, keeps a talent pool of them
+// with pipeline statuses, tags and notes, learns scoring from recruiter
+// feedback on ranked candidates, and caches developer profiles fetched from
+// the source platforms.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+
+	_ "github.com/lib/pq"           // Postgres driver
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+// ErrNotFound is returned for a run or candidate the store does not hold
+var ErrNotFound = errors.New("not found")
+
+// Store is a database of runs. It implements agent.RunStore and is safe
+// for concurrent use.
+type Store struct {
+	db       *sql.DB
+	postgres bool
+}
+
+// Open opens the database at url and creates its tables if missing: a
+// postgres:// or postgresql:// URL, or else the path of a SQLite file,
+// optionally prefixed with sqlite://, created with its directory
+func Open(ctx context.Context, url string) (*Store, error) {
+	var s Store
+	var err error
+	if strings.HasPrefix(url, "postgres://") || strings.HasPrefix(url, "postgresql://") {
+		s.postgres = true
+		s.db, err = sql.Open("postgres", url)
+	} else {
+		path := strings.TrimPrefix(url, "sqlite://")
+		if path == "" {
+			return nil, errors.New("empty database path")
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+		// WAL and a busy timeout let concurrent runs write without failing
+		s.db, err = sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000&_foreign_keys=on")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if err := s.migrate(ctx); err != nil {
+		s.db.Close()
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Close closes the database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// schema creates the tables. {{timestamp}} and {{json}} are replaced by the
+// column types of the database.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id TEXT PRIMARY KEY,
+	query TEXT NOT NULL,
+	stage TEXT NOT NULL,
+	language TEXT NOT NULL,
+	started_at {{timestamp}} NOT NULL,
+	updated_at {{timestamp}} NOT NULL,
+	finished_at {{timestamp}},
+	partial BOOLEAN NOT NULL,
+	requirements {{json}},
+	strategy {{json}},
+	search_metadata {{json}},
+	result {{json}}
+);
+CREATE TABLE IF NOT EXISTS candidates (
+	run_id TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+	username TEXT NOT NULL,
+	initial_match_score DOUBLE PRECISION NOT NULL,
+	profile {{json}} NOT NULL,
+	created_at {{timestamp}} NOT NULL,
+	PRIMARY KEY (run_id, username)
+);
+CREATE TABLE IF NOT EXISTS rankings (
+	run_id TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+	rank INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	final_match_score DOUBLE PRECISION NOT NULL,
+	candidate {{json}} NOT NULL,
+	created_at {{timestamp}} NOT NULL,
+	PRIMARY KEY (run_id, rank)
+);
+CREATE TABLE IF NOT EXISTS contacts (
+	username TEXT NOT NULL,
+	contacted_at {{timestamp}} NOT NULL,
+	run_id TEXT,
+	PRIMARY KEY (username, contacted_at)
+);
+CREATE TABLE IF NOT EXISTS pool (
+	username TEXT PRIMARY KEY,
+	status TEXT NOT NULL,
+	updated_at {{timestamp}} NOT NULL
+);
+CREATE TABLE IF NOT EXISTS tags (
+	username TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (username, tag)
+);
+CREATE TABLE IF NOT EXISTS notes (
+	username TEXT NOT NULL,
+	created_at {{timestamp}} NOT NULL,
+	text TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS profiles (
+	username TEXT PRIMARY KEY,
+	platform TEXT NOT NULL,
+	location TEXT NOT NULL,
+	languages TEXT NOT NULL,
+	public_repos INTEGER NOT NULL,
+	followers INTEGER NOT NULL,
+	detail {{json}},
+	detail_fetched_at {{timestamp}},
+	repositories {{json}},
+	repositories_limit INTEGER NOT NULL,
+	repositories_fetched_at {{timestamp}}
+);
+CREATE TABLE IF NOT EXISTS feedback (
+	username TEXT NOT NULL,
+	run_id TEXT NOT NULL REFERENCES runs (id) ON DELETE CASCADE,
+	role_family TEXT NOT NULL,
+	verdict TEXT NOT NULL,
+	created_at {{timestamp}} NOT NULL,
+	PRIMARY KEY (username, run_id)
+);
+CREATE INDEX IF NOT EXISTS runs_started_at ON runs (started_at);
+CREATE INDEX IF NOT EXISTS candidates_username ON candidates (username);
+CREATE INDEX IF NOT EXISTS rankings_username ON rankings (username);
+CREATE INDEX IF NOT EXISTS candidates_username_lower ON candidates (LOWER(username));
+CREATE INDEX IF NOT EXISTS tags_tag ON tags (tag);
+CREATE INDEX IF NOT EXISTS notes_username ON notes (username);
+CREATE INDEX IF NOT EXISTS profiles_followers ON profiles (followers);
+CREATE INDEX IF NOT EXISTS feedback_role_family ON feedback (role_family);
+`
+
+// migrate creates the tables and indexes missing from the database
+func (s *Store) migrate(ctx context.Context) error {
+	types := strings.NewReplacer("{{timestamp}}", "TIMESTAMP", "{{json}}", "TEXT")
+	if s.postgres {
+		types = strings.NewReplacer("{{timestamp}}", "TIMESTAMPTZ", "{{json}}", "JSONB")
+	}
+	for _, statement := range strings.Split(types.Replace(schema), ";") {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("failed to create tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// rebind returns query with its ? placeholders numbered for Postgres
+func (s *Store) rebind(query string) string {
+	if !s.postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// SaveRun saves run, replacing what was saved of it before except its start
+// time. The candidates are replaced once enriched, the rankings once ranked.
+func (s *Store) SaveRun(ctx context.Context, run agent.RunRecord) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to store run: %w", err)
+	}
+	defer tx.Rollback()
+
+	var finishedAt *time.Time
+	var partial bool
+	var metadata *agent.SearchMetadata
+	if run.Candidates != nil {
+		metadata = &run.Candidates.SearchMetadata
+	}
+	if run.Result != nil {
+		finished := run.Time.UTC()
+		finishedAt, partial = &finished, run.Result.Partial
+	}
+	requirements, err1 := jsonColumn(run.Requirements)
+	strategy, err2 := jsonColumn(run.Strategy)
+	searchMetadata, err3 := jsonColumn(metadata)
+	result, err4 := jsonColumn(run.Result)
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return err
+	}
+	values := []any{run.RunID, run.Query, run.Stage, run.Language, run.StartedAt.UTC(), run.Time.UTC(), finishedAt, partial,
+		requirements, strategy, searchMetadata, result}
+	if _, err := tx.ExecContext(ctx, s.rebind(`
+		INSERT INTO runs (id, query, stage, language, started_at, updated_at, finished_at, partial,
+			requirements, strategy, search_metadata, result)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET query = excluded.query, stage = excluded.stage,
+			language = excluded.language, updated_at = excluded.updated_at,
+			finished_at = excluded.finished_at, partial = excluded.partial,
+			requirements = excluded.requirements, strategy = excluded.strategy,
+			search_metadata = excluded.search_metadata, result = excluded.result`), values...); err != nil {
+		return fmt.Errorf("failed to store run: %w", err)
+	}
+
+	if run.Candidates != nil {
+		if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM candidates WHERE run_id = ?`), run.RunID); err != nil {
+			return fmt.Errorf("failed to store candidates: %w", err)
+		}
+		for _, c := range run.Candidates.Candidates {
+			profile, err := json.Marshal(c)
+			if err != nil {
+				return fmt.Errorf("failed to marshal candidate: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, s.rebind(`
+				INSERT INTO candidates (run_id, username, initial_match_score, profile, created_at)
+				VALUES (?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`),
+				run.RunID, c.Username, c.InitialMatchScore, string(profile), run.Time.UTC()); err != nil {
+				return fmt.Errorf("failed to store candidates: %w", err)
+			}
+		}
+	}
+
+	if run.Result != nil {
+		if _, err := tx.ExecContext(ctx, s.rebind(`DELETE FROM rankings WHERE run_id = ?`), run.RunID); err != nil {
+			return fmt.Errorf("failed to store rankings: %w", err)
+		}
+		for _, c := range run.Result.TopCandidates {
+			candidate, err := json.Marshal(c)
+			if err != nil {
+				return fmt.Errorf("failed to marshal ranked candidate: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, s.rebind(`
+				INSERT INTO rankings (run_id, rank, username, final_match_score, candidate, created_at)
+				VALUES (?, ?, ?, ?, ?, ?) ON CONFLICT DO NOTHING`),
+				run.RunID, c.Rank, c.Username, c.FinalMatchScore, string(candidate), run.Time.UTC()); err != nil {
+				return fmt.Errorf("failed to store rankings: %w", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store run: %w", err)
+	}
+	return nil
+}
+
+// jsonColumn returns v as the text of a JSON column, nil for a nil v
+func jsonColumn[T any](v *T) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(t.Context(), filepath.Join(t.TempDir(), "db", "sourcing.db"))
+	if err != nil {
+		t.Fatalf("Expected to open the store, got %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSaveRun(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	started := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	run := agent.RunRecord{
+		Checkpoint: agent.Checkpoint{
+			RunID: "run-1", Query: "Go developers in Lima", Stage: agent.StageStrategy, Time: started.Add(time.Second), Language: "es",
+			Requirements: &agent.Requirements{RequiredSkills: []string{"Go"}},
+			Strategy:     &agent.SearchStrategy{StrategyNotes: "Lima first"},
+		},
+		StartedAt: started,
+	}
+	if err := store.SaveRun(ctx, run); err != nil {
+		t.Fatalf("Expected to save the run, got %v", err)
+	}
+	stored, err := store.GetRun(ctx, "run-1")
+	if err != nil {
+		t.Fatalf("Expected the stored run, got %v", err)
+	}
+	if stored.Stage != agent.StageStrategy || stored.FinishedAt != nil || stored.Strategy.StrategyNotes != "Lima first" || len(stored.Candidates) != 0 {
+		t.Errorf("Expected an unfinished run at the strategy stage, got %+v", stored)
+	}
+
+	// Later stages replace the run, keeping its start
+	run.Stage, run.Time = agent.StageRanking, started.Add(time.Minute)
+	run.Candidates = &agent.EnrichedCandidates{
+		Candidates: []agent.EnrichedCandidate{
+			{Username: "alice", InitialMatchScore: 0.7},
+			{Username: "bob", InitialMatchScore: 0.9},
+		},
+		SearchMetadata: agent.SearchMetadata{ProfilesAnalyzed: 2},
+	}
+	run.Result = &agent.FinalResult{RunID: "run-1", TopCandidates: []agent.RankedCandidate{
+		{Rank: 1, Username: "bob", FinalMatchScore: 0.92},
+	}}
+	run.StartedAt = started.Add(time.Hour)
+	if err := store.SaveRun(ctx, run); err != nil {
+		t.Fatalf("Expected to save the finished run, got %v", err)
+	}
+	stored, err = store.GetRun(ctx, "run-1")
+	if err != nil {
+		t.Fatalf("Expected the stored run, got %v", err)
+	}
+	if !stored.StartedAt.Equal(started) {
+		t.Errorf("Expected the run to keep its start %v, got %v", started, stored.StartedAt)
+	}
+	if stored.FinishedAt == nil || !stored.FinishedAt.Equal(run.Time) || stored.Language != "es" {
+		t.Errorf("Expected the run finished at %v in es, got %+v", run.Time, stored)
+	}
+	if stored.CandidateCount != 2 || stored.RankedCount != 1 || len(stored.Candidates) != 2 || stored.Candidates[0].Username != "bob" {
+		t.Errorf("Expected 2 candidates, bob first, and 1 ranking, got %+v", stored)
+	}
+	if stored.Result == nil || stored.Result.TopCandidates[0].FinalMatchScore != 0.92 || stored.Requirements.RequiredSkills[0] != "Go" {
+		t.Errorf("Expected the result and requirements, got %+v", stored)
+	}
+
+	if _, err := store.GetRun(ctx, "unknown"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestListRuns(t *testing.T) {
+	store := openTestStore(t)
+	ctx := context.Background()
+	started := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i, id := range []string{"run-1", "run-2", "run-3"} {
+		at := started.Add(time.Duration(i) * time.Hour)
+		run := agent.RunRecord{Checkpoint: agent.Checkpoint{RunID: id, Query: "query " + id, Stage: agent.StageRequirements, Time: at}, StartedAt: at}
+		if err := store.SaveRun(ctx, run); err != nil {
+			t.Fatalf("Expected to save %s, got %v", id, err)
+		}
+	}
+
+	runs, err := store.ListRuns(ctx, 2)
+	if err != nil {
+		t.Fatalf("Expected the runs, got %v", err)
+	}
+	if len(runs) != 2 || runs[0].ID != "run-3" || runs[1].ID != "run-2" {
+		t.Errorf("Expected run-3 and run-2, got %+v", runs)
+	}
+	if runs, _ := store.ListRuns(ctx, 0); len(runs) != 3 {
+		t.Errorf("Expected all 3 runs without a limit, got %d", len(runs))
+	}
+
+	// The store survives reopening
+	path := filepath.Join(t.TempDir(), "sourcing.db")
+	first, err := Open(ctx, "sqlite://"+path)
+	if err != nil {
+		t.Fatalf("Expected to open the store, got %v", err)
+	}
+	first.SaveRun(ctx, agent.RunRecord{Checkpoint: agent.Checkpoint{RunID: "run-1", Query: "q", Stage: agent.StageRequirements, Time: started}, StartedAt: started})
+	first.Close()
+	second, err := Open(ctx, path)
+	if err != nil {
+		t.Fatalf("Expected to reopen the store, got %v", err)
+	}
+	defer second.Close()
+	if runs, _ := second.ListRuns(ctx, 0); len(runs) != 1 {
+		t.Errorf("Expected the run saved before reopening, got %+v", runs)
+	}
+}
+
+func TestRebind(t *testing.T) {
+	s := &Store{postgres: true}
+	if got := s.rebind("SELECT a FROM t WHERE b = ? AND c = ?"); got != "SELECT a FROM t WHERE b = $1 AND c = $2" {
+		t.Errorf("Expected numbered placeholders, got %q", got)
+	}
+	if got := (&Store{}).rebind("b = ?"); got != "b = ?" {
+		t.Errorf("Expected SQLite placeholders unchanged, got %q", got)
+	}
+}
@@ -0,0 +1,65 @@
+package vectorstore
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// Memory is a Store held in memory, searched by comparing the vector with
+// every record
+type Memory struct {
+	mu      sync.RWMutex
+	records map[string]Record
+}
+
+var _ Store = (*Memory)(nil)
+
+// NewMemory creates an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{records: map[string]Record{}}
+}
+
+// Upsert saves records, replacing earlier records of the same IDs
+func (m *Memory) Upsert(_ context.Context, records ...Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range records {
+		m.records[r.ID] = r
+	}
+	return nil
+}
+
+// Search returns up to limit records most similar to vector
+func (m *Memory) Search(_ context.Context, vector []float32, limit int) ([]Match, error) {
+	m.mu.RLock()
+	matches := make([]Match, 0, len(m.records))
+	for _, r := range m.records {
+		if len(r.Vector) != len(vector) {
+			continue
+		}
+		score := llm.CosineSimilarity(vector, r.Vector)
+		r.Vector = nil
+		matches = append(matches, Match{Record: r, Score: score})
+	}
+	m.mu.RUnlock()
+	return top(matches, limit), nil
+}
+
+// Close does nothing
+func (m *Memory) Close() error { return nil }
+
+// top sorts matches most similar first, by ID among equals, and keeps the
+// first limit of them
+func top(matches []Match, limit int) []Match {
+	slices.SortFunc(matches, func(a, b Match) int {
+		return cmp.Or(cmp.Compare(b.Score, a.Score), cmp.Compare(a.ID, b.ID))
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,246 @@
+package vectorstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+
+	_ "github.com/lib/pq"           // Postgres driver
+	_ "github.com/mattn/go-sqlite3" // SQLite driver
+)
+
+// SQL is a Store in a database: SQLite, which keeps vectors as blobs and
+// compares them in Go, or Postgres, which compares them with pgvector
+type SQL struct {
+	db       *sql.DB
+	postgres bool
+}
+
+var _ Store = (*SQL)(nil)
+
+// schema creates the table. {{timestamp}}, {{json}} and {{vector}} are
+// replaced by the column types of the database. Vectors of any number of
+// dimensions share the table, so pgvector compares them without an index.
+const schema = `
+CREATE TABLE IF NOT EXISTS candidate_embeddings (
+	id TEXT PRIMARY KEY,
+	run_id TEXT NOT NULL,
+	dims INTEGER NOT NULL,
+	embedding {{vector}} NOT NULL,
+	candidate {{json}} NOT NULL,
+	updated_at {{timestamp}} NOT NULL
+)`
+
+// openSQL opens the database at url and creates its table if missing
+func openSQL(ctx context.Context, url string) (*SQL, error) {
+	var s SQL
+	var err error
+	if strings.HasPrefix(url, "postgres://") || strings.HasPrefix(url, "postgresql://") {
+		s.postgres = true
+		s.db, err = sql.Open("postgres", url)
+	} else {
+		path := strings.TrimPrefix(url, "sqlite://")
+		if path == "" {
+			return nil, errors.New("empty vector store path")
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create vector store directory: %w", err)
+		}
+		s.db, err = sql.Open("sqlite3", "file:"+path+"?_journal_mode=WAL&_busy_timeout=5000")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vector store: %w", err)
+	}
+	if err := s.migrate(ctx); err != nil {
+		s.db.Close()
+		return nil, err
+	}
+	return &s, nil
+}
+
+// migrate creates the table, and in Postgres the pgvector extension
+func (s *SQL) migrate(ctx context.Context) error {
+	types := strings.NewReplacer("{{timestamp}}", "TIMESTAMP", "{{json}}", "TEXT", "{{vector}}", "BLOB")
+	if s.postgres {
+		types = strings.NewReplacer("{{timestamp}}", "TIMESTAMPTZ", "{{json}}", "JSONB", "{{vector}}", "vector")
+		if _, err := s.db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
+			return fmt.Errorf("failed to enable pgvector: %w", err)
+		}
+	}
+	if _, err := s.db.ExecContext(ctx, types.Replace(schema)); err != nil {
+		return fmt.Errorf("failed to create vector table: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database
+func (s *SQL) Close() error {
+	return s.db.Close()
+}
+
+// Upsert saves records, replacing earlier records of the same IDs
+func (s *SQL) Upsert(ctx context.Context, records ...Record) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to store embeddings: %w", err)
+	}
+	defer tx.Rollback()
+	for _, r := range records {
+		candidate, err := json.Marshal(r.Candidate)
+		if err != nil {
+			return fmt.Errorf("failed to marshal candidate: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, s.rebind(`
+			INSERT INTO candidate_embeddings (id, run_id, dims, embedding, candidate, updated_at)
+			VALUES (?, ?, ?, `+s.vectorParam()+`, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET run_id = excluded.run_id, dims = excluded.dims,
+				embedding = excluded.embedding, candidate = excluded.candidate, updated_at = excluded.updated_at`),
+			r.ID, r.RunID, len(r.Vector), s.encode(r.Vector), string(candidate), r.UpdatedAt.UTC()); err != nil {
+			return fmt.Errorf("failed to store embeddings: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to store embeddings: %w", err)
+	}
+	return nil
+}
+
+// Search returns up to limit records most similar to vector
+func (s *SQL) Search(ctx context.Context, vector []float32, limit int) ([]Match, error) {
+	if s.postgres {
+		return s.searchPostgres(ctx, vector, limit)
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, run_id, embedding, candidate, updated_at FROM candidate_embeddings WHERE dims = ?`, len(vector))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search embeddings: %w", err)
+	}
+	defer rows.Close()
+	var matches []Match
+	for rows.Next() {
+		var m Match
+		var embedding []byte
+		var candidate string
+		if err := rows.Scan(&m.ID, &m.RunID, &embedding, &candidate, &m.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read embedding: %w", err)
+		}
+		if err := json.Unmarshal([]byte(candidate), &m.Candidate); err != nil {
+			return nil, fmt.Errorf("failed to parse candidate %s: %w", m.ID, err)
+		}
+		m.Score = llm.CosineSimilarity(vector, decodeBlob(embedding))
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search embeddings: %w", err)
+	}
+	return top(matches, limit), nil
+}
+
+// searchPostgres has pgvector order the records by cosine distance
+func (s *SQL) searchPostgres(ctx context.Context, vector []float32, limit int) ([]Match, error) {
+	query := `
+		SELECT id, run_id, candidate, updated_at, 1 - (embedding <=> $1::vector)
+		FROM candidate_embeddings WHERE dims = $2
+		ORDER BY embedding <=> $1::vector, id`
+	args := []any{vectorLiteral(vector), len(vector)}
+	if limit > 0 {
+		query += ` LIMIT $3`
+		args = append(args, limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search embeddings: %w", err)
+	}
+	defer rows.Close()
+	var matches []Match
+	for rows.Next() {
+		var m Match
+		var candidate []byte
+		var score sql.NullFloat64 // NULL for a zero vector
+		if err := rows.Scan(&m.ID, &m.RunID, &candidate, &m.UpdatedAt, &score); err != nil {
+			return nil, fmt.Errorf("failed to read embedding: %w", err)
+		}
+		if err := json.Unmarshal(candidate, &m.Candidate); err != nil {
+			return nil, fmt.Errorf("failed to parse candidate %s: %w", m.ID, err)
+		}
+		if score.Valid && !math.IsNaN(score.Float64) {
+			m.Score = score.Float64
+		}
+		matches = append(matches, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search embeddings: %w", err)
+	}
+	return matches, nil
+}
+
+// vectorParam is the placeholder of an embedding in an insert
+func (s *SQL) vectorParam() string {
+	if s.postgres {
+		return "?::vector"
+	}
+	return "?"
+}
+
+// encode returns vector as the embedding column of the database
+func (s *SQL) encode(vector []float32) any {
+	if s.postgres {
+		return vectorLiteral(vector)
+	}
+	blob := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(v))
+	}
+	return blob
+}
+
+// decodeBlob returns the vector encoded in a SQLite blob
+func decodeBlob(blob []byte) []float32 {
+	vector := make([]float32, len(blob)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[4*i:]))
+	}
+	return vector
+}
+
+// vectorLiteral returns vector in pgvector's text form, e.g. [0.1,0.2]
+func vectorLiteral(vector []float32) string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// rebind returns query with its ? placeholders numbered for Postgres
+func (s *SQL) rebind(query string) string {
+	if !s.postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
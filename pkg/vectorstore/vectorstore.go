@@ -0,0 +1,141 @@
+// Package vectorstore keeps embeddings of the candidates of past runs, their
+// bios and repositories, so candidates similar to a job description can be
+// found among them without searching GitHub again. Embeddings live in
+// memory, in SQLite, or in Postgres with the pgvector extension.
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// Record is a candidate's embedding, with the candidate as last enriched
+type Record struct {
+	ID        string                  `json:"id"` // The candidate's username
+	RunID     string                  `json:"run_id"`
+	Vector    []float32               `json:"-"`
+	Candidate agent.EnrichedCandidate `json:"candidate"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+// Match is a record found by Search, with its cosine similarity (-1 to 1)
+// to the searched vector
+type Match struct {
+	Record
+	Score float64 `json:"score"`
+}
+
+// Store holds one record per candidate. Implementations are safe for
+// concurrent use.
+type Store interface {
+	// Upsert saves records, replacing earlier records of the same IDs
+	Upsert(ctx context.Context, records ...Record) error
+	// Search returns up to limit records most similar to vector, most
+	// similar first, leaving their Vector unset. Records embedded with a
+	// different number of dimensions, e.g. by another model, are skipped.
+	Search(ctx context.Context, vector []float32, limit int) ([]Match, error)
+	Close() error
+}
+
+// Open opens the store at url: memory for an in-process store, a
+// postgres:// or postgresql:// URL of a database with the pgvector
+// extension, or else the path of a SQLite file, optionally prefixed with
+// sqlite://
+func Open(ctx context.Context, url string) (Store, error) {
+	if url == "memory" {
+		return NewMemory(), nil
+	}
+	return openSQL(ctx, url)
+}
+
+// Indexer embeds candidates into a Store. It implements agent.RunStore,
+// indexing the enriched candidates of every finished run.
+type Indexer struct {
+	Store    Store
+	Embedder llm.Embedder
+}
+
+// SaveRun indexes the candidates of run once it has a result
+func (ix *Indexer) SaveRun(ctx context.Context, run agent.RunRecord) error {
+	if run.Result == nil || run.Candidates == nil {
+		return nil
+	}
+	return ix.Index(ctx, run.RunID, run.Candidates.Candidates)
+}
+
+// Index embeds candidates, found by the run runID, and saves them
+func (ix *Indexer) Index(ctx context.Context, runID string, candidates []agent.EnrichedCandidate) error {
+	if len(candidates) == 0 {
+		return nil
+	}
+	texts := make([]string, len(candidates))
+	for i, c := range candidates {
+		texts[i] = CandidateText(c)
+	}
+	vectors, err := ix.Embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed candidates: %w", err)
+	}
+	if len(vectors) != len(candidates) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(candidates), len(vectors))
+	}
+	now := time.Now().UTC()
+	records := make([]Record, len(candidates))
+	for i, c := range candidates {
+		records[i] = Record{ID: c.Username, RunID: runID, Vector: vectors[i], Candidate: c, UpdatedAt: now}
+	}
+	return ix.Store.Upsert(ctx, records...)
+}
+
+// Similar returns up to limit indexed candidates most similar to text,
+// e.g. a job description
+func (ix *Indexer) Similar(ctx context.Context, text string, limit int) ([]Match, error) {
+	vectors, err := ix.Embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed text: %w", err)
+	}
+	if len(vectors) != 1 {
+		return nil, fmt.Errorf("expected 1 embedding, got %d", len(vectors))
+	}
+	return ix.Store.Search(ctx, vectors[0], limit)
+}
+
+// CandidateText is the text a candidate is embedded from: their profile,
+// skills and relevant repositories, and the topics of their external
+// profiles
+func CandidateText(c agent.EnrichedCandidate) string {
+	var b strings.Builder
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", label, value)
+		}
+	}
+	line("Name", c.Name)
+	line("Location", c.Location)
+	line("Company", c.Company)
+	line("Bio", c.Bio)
+	line("Skills", strings.Join(c.SkillsFound, ", "))
+	for _, r := range c.RelevantRepositories {
+		repo := r.Name
+		if r.Language != "" {
+			repo += " (" + r.Language + ")"
+		}
+		if r.Description != "" {
+			repo += ": " + r.Description
+		}
+		if len(r.Topics) > 0 {
+			repo += " [" + strings.Join(r.Topics, ", ") + "]"
+		}
+		line("Repository", repo)
+	}
+	for _, p := range c.ExternalProfiles {
+		line(p.Platform, strings.Join(slices.Concat(p.TopTags, p.Highlights), ", "))
+	}
+	return strings.TrimSpace(b.String())
+}
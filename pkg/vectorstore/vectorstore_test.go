@@ -0,0 +1,127 @@
+package vectorstore
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+)
+
+// keywordEmbedder embeds a text as the counts of its keywords
+type keywordEmbedder struct{ keywords []string }
+
+func (e keywordEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = make([]float32, len(e.keywords))
+		for j, k := range e.keywords {
+			vectors[i][j] = float32(strings.Count(strings.ToLower(text), k))
+		}
+	}
+	return vectors, nil
+}
+
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	sqlite, err := Open(t.Context(), "sqlite://"+filepath.Join(t.TempDir(), "index", "vectors.db"))
+	if err != nil {
+		t.Fatalf("Expected to open the SQLite store, got %v", err)
+	}
+	t.Cleanup(func() { sqlite.Close() })
+	memory, err := Open(t.Context(), "memory")
+	if err != nil {
+		t.Fatalf("Expected to open the in-memory store, got %v", err)
+	}
+	return map[string]Store{"memory": memory, "sqlite": sqlite}
+}
+
+func TestStoreSearch(t *testing.T) {
+	for name, store := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			at := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+			err := store.Upsert(ctx,
+				Record{ID: "ana", RunID: "run-1", Vector: []float32{1, 0}, Candidate: agent.EnrichedCandidate{Username: "ana", Location: "Lima"}, UpdatedAt: at},
+				Record{ID: "bob", RunID: "run-1", Vector: []float32{0, 1}, Candidate: agent.EnrichedCandidate{Username: "bob"}, UpdatedAt: at},
+				Record{ID: "cruz", RunID: "run-1", Vector: []float32{1, 1}, Candidate: agent.EnrichedCandidate{Username: "cruz"}, UpdatedAt: at},
+				Record{ID: "dee", RunID: "run-1", Vector: []float32{1, 0, 0}, Candidate: agent.EnrichedCandidate{Username: "dee"}, UpdatedAt: at},
+			)
+			if err != nil {
+				t.Fatalf("Expected to upsert, got %v", err)
+			}
+			// A later run replaces bob's record
+			if err := store.Upsert(ctx, Record{ID: "bob", RunID: "run-2", Vector: []float32{0.9, 0.1}, Candidate: agent.EnrichedCandidate{Username: "bob"}, UpdatedAt: at}); err != nil {
+				t.Fatalf("Expected to replace bob, got %v", err)
+			}
+
+			matches, err := store.Search(ctx, []float32{1, 0}, 2)
+			if err != nil {
+				t.Fatalf("Expected to search, got %v", err)
+			}
+			if len(matches) != 2 || matches[0].ID != "ana" || matches[1].ID != "bob" {
+				t.Fatalf("Expected ana then bob, got %+v", matches)
+			}
+			if matches[0].Score < 0.999 || matches[0].Candidate.Location != "Lima" || !matches[0].UpdatedAt.Equal(at) {
+				t.Errorf("Expected ana's record with a score of 1, got %+v", matches[0])
+			}
+			if matches[1].RunID != "run-2" {
+				t.Errorf("Expected bob's record from run-2, got %s", matches[1].RunID)
+			}
+
+			// Vectors of other dimensions are skipped, no limit returns all
+			matches, err = store.Search(ctx, []float32{0, 1}, 0)
+			if err != nil {
+				t.Fatalf("Expected to search, got %v", err)
+			}
+			if len(matches) != 3 || matches[0].ID != "cruz" {
+				t.Errorf("Expected the three 2-dimensional records, cruz first, got %+v", matches)
+			}
+		})
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	indexer := &Indexer{Store: NewMemory(), Embedder: keywordEmbedder{keywords: []string{"rust", "kubernetes", "react"}}}
+	ctx := context.Background()
+	candidates := &agent.EnrichedCandidates{Candidates: []agent.EnrichedCandidate{
+		{Username: "ana", Bio: "Rust and Kubernetes", RelevantRepositories: []agent.RelevantRepository{{Name: "kube-rs", Language: "Rust", Topics: []string{"kubernetes"}}}},
+		{Username: "bob", Bio: "React developer", SkillsFound: []string{"React"}},
+	}}
+
+	// Runs are indexed once they have a result
+	run := agent.RunRecord{Checkpoint: agent.Checkpoint{RunID: "run-1", Candidates: candidates}}
+	if err := indexer.SaveRun(ctx, run); err != nil {
+		t.Fatalf("Expected to save the run, got %v", err)
+	}
+	if matches, _ := indexer.Similar(ctx, "rust", 10); len(matches) != 0 {
+		t.Fatalf("Expected nothing indexed before the run finished, got %+v", matches)
+	}
+	run.Result = &agent.FinalResult{}
+	if err := indexer.SaveRun(ctx, run); err != nil {
+		t.Fatalf("Expected to save the finished run, got %v", err)
+	}
+
+	matches, err := indexer.Similar(ctx, "Senior Rust engineer for our Kubernetes operators", 1)
+	if err != nil {
+		t.Fatalf("Expected to find similar candidates, got %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != "ana" || matches[0].RunID != "run-1" {
+		t.Errorf("Expected ana, got %+v", matches)
+	}
+}
+
+func TestCandidateText(t *testing.T) {
+	text := CandidateText(agent.EnrichedCandidate{
+		Username: "ana", Name: "Ana Quispe", Bio: "Go developer", SkillsFound: []string{"Go", "gRPC"},
+		RelevantRepositories: []agent.RelevantRepository{{Name: "grpc-tools", Language: "Go", Description: "gRPC helpers", Topics: []string{"grpc", "protobuf"}}},
+		ExternalProfiles:     []agent.ExternalProfile{{Platform: "stackoverflow", TopTags: []string{"go", "grpc"}}},
+	})
+	expected := "Name: Ana Quispe\nBio: Go developer\nSkills: Go, gRPC\n" +
+		"Repository: grpc-tools (Go): gRPC helpers [grpc, protobuf]\nstackoverflow: go, grpc"
+	if text != expected {
+		t.Errorf("Expected %q, got %q", expected, text)
+	}
+}
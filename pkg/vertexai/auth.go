@@ -0,0 +1,104 @@
+package vertexai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"cloud.google.com/go/auth"
+	"cloud.google.com/go/auth/credentials"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// supportedCredentialTypes are the credential JSON types accepted in
+// Config.CredentialsFile and Config.CredentialsJSON
+var supportedCredentialTypes = map[string]bool{
+	"service_account":                  true,
+	"external_account":                 true, // Workload identity federation (CI, AWS, Azure, OIDC)
+	"impersonated_service_account":     true,
+	"authorized_user":                  true,
+	"external_account_authorized_user": true,
+}
+
+// AuthError reports that the client could not authenticate to Vertex AI,
+// either while loading credentials or when Vertex rejected them
+type AuthError struct {
+	// Source describes the credentials used, e.g. "application default credentials"
+	Source string
+	Err    error
+}
+
+func (e *AuthError) Error() string {
+	if e.Source == "" {
+		return fmt.Sprintf("vertexai authentication failed: %v", e.Err)
+	}
+	return fmt.Sprintf("vertexai authentication failed using %s: %v", e.Source, e.Err)
+}
+
+func (e *AuthError) Unwrap() error { return e.Err }
+
+// loadCredentials resolves the credentials selected by config: an explicit
+// credentials file or JSON document when set, Application Default Credentials
+// otherwise. ADC already covers GOOGLE_APPLICATION_CREDENTIALS and workload
+// identity on GKE and Cloud Run through the metadata server.
+func loadCredentials(config Config) (*auth.Credentials, error) {
+	opts := &credentials.DetectOptions{Scopes: []string{cloudPlatformScope}}
+	source := "application default credentials"
+
+	switch {
+	case config.CredentialsFile != "" && len(config.CredentialsJSON) > 0:
+		return nil, &AuthError{Source: "explicit credentials", Err: errors.New("set only one of CredentialsFile and CredentialsJSON")}
+	case config.CredentialsFile != "":
+		source = fmt.Sprintf("credentials file %s", config.CredentialsFile)
+		data, err := os.ReadFile(config.CredentialsFile)
+		if err != nil {
+			return nil, &AuthError{Source: source, Err: err}
+		}
+		if err := checkCredentialType(data); err != nil {
+			return nil, &AuthError{Source: source, Err: err}
+		}
+		opts.CredentialsJSON = data
+	case len(config.CredentialsJSON) > 0:
+		source = "credentials JSON"
+		if err := checkCredentialType(config.CredentialsJSON); err != nil {
+			return nil, &AuthError{Source: source, Err: err}
+		}
+		opts.CredentialsJSON = config.CredentialsJSON
+	}
+
+	creds, err := credentials.DetectDefault(opts)
+	if err != nil {
+		return nil, &AuthError{Source: source, Err: err}
+	}
+	return creds, nil
+}
+
+// checkCredentialType rejects documents that are not Google credentials
+// before they reach the auth library, whose errors are less specific
+func checkCredentialType(data []byte) error {
+	var doc struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("invalid credentials JSON: %w", err)
+	}
+	if !supportedCredentialTypes[doc.Type] {
+		return fmt.Errorf("unsupported credentials type %q", doc.Type)
+	}
+	return nil
+}
+
+// authFailure reports whether err means Vertex rejected the credentials, or a
+// token could not be obtained for the request
+func authFailure(err error) bool {
+	var apiErr *llm.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == 401 || apiErr.StatusCode == 403 ||
+			apiErr.Status == "UNAUTHENTICATED" || apiErr.Status == "PERMISSION_DENIED"
+	}
+	var tokenErr *auth.Error
+	return errors.As(err, &tokenErr)
+}
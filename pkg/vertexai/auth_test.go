@@ -0,0 +1,77 @@
+package vertexai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+// workloadIdentityConfig is a workload identity federation config reading its
+// subject token from a file, as GitHub Actions and other CI systems provide
+const workloadIdentityConfig = `{
+  "type": "external_account",
+  "audience": "//iam.googleapis.com/projects/123/locations/global/workloadIdentityPools/ci/providers/github",
+  "subject_token_type": "urn:ietf:params:oauth:token-type:jwt",
+  "token_url": "https://sts.googleapis.com/v1/token",
+  "credential_source": {"file": "/var/run/secrets/token"}
+}`
+
+func TestLoadCredentials(t *testing.T) {
+	t.Run("workload identity file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "wif.json")
+		if err := os.WriteFile(path, []byte(workloadIdentityConfig), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		creds, err := loadCredentials(Config{CredentialsFile: path})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if creds == nil {
+			t.Error("Expected credentials, got nil")
+		}
+	})
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"both set", Config{CredentialsFile: "key.json", CredentialsJSON: []byte(workloadIdentityConfig)}},
+		{"missing file", Config{CredentialsFile: filepath.Join(t.TempDir(), "missing.json")}},
+		{"invalid JSON", Config{CredentialsJSON: []byte("not json")}},
+		{"unsupported type", Config{CredentialsJSON: []byte(`{"type": "api_key"}`)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadCredentials(tt.config)
+			var authErr *AuthError
+			if !errors.As(err, &authErr) {
+				t.Errorf("Expected AuthError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCallAPIAuthError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "message": "Permission 'aiplatform.endpoints.predict' denied", "status": "PERMISSION_DENIED"}}`))
+	})
+
+	_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Expected AuthError, got %v", err)
+	}
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 403 {
+		t.Errorf("Expected the underlying APIError to be preserved, got %v", err)
+	}
+	if llm.IsTransient(err) {
+		t.Error("Expected auth failures not to be retried")
+	}
+}
@@ -0,0 +1,45 @@
+package vertexai
+
+import "google.golang.org/genai"
+
+// selectCandidate picks the response candidate to return when Gemini produces
+// several: a candidate that finished normally beats one cut off at the token
+// limit, which beats any other stop, and filtered candidates come last. Ties go
+// to the lowest index so the choice is deterministic.
+func selectCandidate(candidates []*genai.Candidate) *genai.Candidate {
+	var best *genai.Candidate
+	for _, cand := range candidates {
+		if cand == nil {
+			continue
+		}
+		if best == nil || candidateRank(cand) < candidateRank(best) ||
+			(candidateRank(cand) == candidateRank(best) && cand.Index < best.Index) {
+			best = cand
+		}
+	}
+	return best
+}
+
+// candidateRank orders candidates by how usable their finish reason is; lower is better
+func candidateRank(cand *genai.Candidate) int {
+	switch {
+	case blockedFinishReasons[cand.FinishReason] || safetyBlocked(cand.SafetyRatings):
+		return 3
+	case cand.FinishReason == "" || cand.FinishReason == genai.FinishReasonStop:
+		return 0
+	case cand.FinishReason == genai.FinishReasonMaxTokens:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// safetyBlocked reports whether any rating blocked the content
+func safetyBlocked(ratings []*genai.SafetyRating) bool {
+	for _, rating := range ratings {
+		if rating != nil && rating.Blocked {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package vertexai
+
+import (
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+func textCandidate(index int32, reason genai.FinishReason, text string) *genai.Candidate {
+	return &genai.Candidate{
+		Index:        index,
+		FinishReason: reason,
+		Content:      &genai.Content{Role: genai.RoleModel, Parts: []*genai.Part{{Text: text}}},
+	}
+}
+
+func TestSelectCandidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []*genai.Candidate
+		want       string
+	}{
+		{"single", []*genai.Candidate{textCandidate(0, genai.FinishReasonStop, "a")}, "a"},
+		{"prefers stop over max tokens", []*genai.Candidate{
+			textCandidate(0, genai.FinishReasonMaxTokens, "truncated"),
+			textCandidate(1, genai.FinishReasonStop, "complete"),
+		}, "complete"},
+		{"skips safety blocked", []*genai.Candidate{
+			textCandidate(0, genai.FinishReasonSafety, "blocked"),
+			textCandidate(1, genai.FinishReasonRecitation, "recited"),
+		}, "recited"},
+		{"lowest index breaks ties", []*genai.Candidate{
+			textCandidate(1, genai.FinishReasonStop, "second"),
+			textCandidate(0, genai.FinishReasonStop, "first"),
+		}, "first"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := selectCandidate(tt.candidates)
+			if got == nil || got.Content.Parts[0].Text != tt.want {
+				t.Errorf("Expected %q, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConvertResponseMultipleCandidates(t *testing.T) {
+	resp := convertResponse(&genai.GenerateContentResponse{Candidates: []*genai.Candidate{
+		textCandidate(0, genai.FinishReasonMaxTokens, `{"top_candidates": [`),
+		textCandidate(1, genai.FinishReasonStop, `{"top_candidates": []}`),
+	}})
+
+	if text := llm.ResponseText(resp); text != `{"top_candidates": []}` {
+		t.Errorf("Expected only the complete candidate's text, got %q", text)
+	}
+}
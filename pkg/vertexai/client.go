@@ -2,41 +2,170 @@ package vertexai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
 
 	"github.com/luillyfe/sourcing-agent/pkg/llm"
 	"google.golang.org/genai"
 )
 
 const (
-	modelName = "gemini-3-pro-preview"
+	// DefaultModel is the Gemini model used by the client
+	DefaultModel = "gemini-3-pro-preview"
 )
 
-// Client handles interactions with the Gemini API on Vertex AI
-type Client struct {
+// Config configures a Vertex AI client
+type Config struct {
 	ProjectID string
 	Region    string
-	client    *genai.Client
+	// FallbackRegions are tried in order when Region rejects a call for
+	// capacity or quota, e.g. while a preview model is scarce
+	FallbackRegions []string
+	// CredentialsFile is a service account key or workload identity
+	// federation config to authenticate with. When neither it nor
+	// CredentialsJSON is set, Application Default Credentials are used.
+	CredentialsFile string
+	// CredentialsJSON holds the same document inline, e.g. from a CI secret
+	CredentialsJSON []byte
+	// Model is the Gemini model to call. Defaults to DefaultModel.
+	Model string
+	// StageModels overrides Model for calls labelled with llm.WithStage,
+	// e.g. a flash-class model for the cheaper stages
+	StageModels map[string]string
+	// Generation holds the default sampling settings; calls can override
+	// them with llm.WithGeneration. Temperature defaults to 0.
+	Generation llm.GenerationConfig
+	// SafetySettings adjusts Gemini's content filter thresholds per harm category
+	SafetySettings []*genai.SafetySetting
+	// Retry retries RESOURCE_EXHAUSTED/UNAVAILABLE and other transient errors
+	// inside the client. The zero value disables it, leaving retries to
+	// llm.WithRetry around the client.
+	Retry llm.RetryConfig
+	// Thinking sets the reasoning budget for models that think before
+	// answering. Nil keeps the model's default.
+	Thinking *genai.ThinkingConfig
+	// StageThinking overrides Thinking for calls labelled with llm.WithStage,
+	// e.g. a larger budget for ranking
+	StageThinking map[string]*genai.ThinkingConfig
+	// CandidateCount asks Gemini for several candidates per call; the best
+	// one by finish reason is returned. Zero uses the model default of one.
+	CandidateCount int32
+	// ContextCache caches long system prompts on Vertex so repeated calls
+	// and runs reuse them at the cached-token rate
+	ContextCache ContextCacheConfig
+	// Logger receives region failovers and context cache failures.
+	// Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Client handles interactions with the Gemini API on Vertex AI
+type Client struct {
+	ProjectID       string
+	Region          string
+	FallbackRegions []string
+	Model           string
+	StageModels     map[string]string
+	Generation      llm.GenerationConfig
+	SafetySettings  []*genai.SafetySetting
+	Retry           llm.RetryConfig
+	Thinking        *genai.ThinkingConfig
+	StageThinking   map[string]*genai.ThinkingConfig
+	CandidateCount  int32
+	ContextCache    ContextCacheConfig
+	Logger          *slog.Logger
+	client          *genai.Client
+	fallbacks       []regionalClient
+
+	mu          sync.Mutex
+	regionUsage map[string]*RegionUsage
+	promptCache map[string]cachedPrompt
 }
 
-// NewClient creates a new Vertex AI Gemini Client
+// NewClient creates a new Vertex AI Gemini Client using DefaultModel
 func NewClient(ctx context.Context, projectID, region string) (*Client, error) {
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		Project:  projectID,
-		Location: region,
-		Backend:  genai.BackendVertexAI,
-	})
+	return NewClientWithConfig(ctx, Config{ProjectID: projectID, Region: region})
+}
+
+// NewClientWithConfig creates a new Vertex AI Gemini Client from config
+func NewClientWithConfig(ctx context.Context, config Config) (*Client, error) {
+	creds, err := loadCredentials(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create vertexai client: %w", err)
+		return nil, err
+	}
+	newClient := func(region string) (*genai.Client, error) {
+		client, err := genai.NewClient(ctx, &genai.ClientConfig{
+			Project:     config.ProjectID,
+			Location:    region,
+			Backend:     genai.BackendVertexAI,
+			Credentials: creds,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create vertexai client for %s: %w", region, err)
+		}
+		return client, nil
+	}
+	client, err := newClient(config.Region)
+	if err != nil {
+		return nil, err
+	}
+	var fallbacks []regionalClient
+	for _, region := range config.FallbackRegions {
+		if region == "" || region == config.Region {
+			continue
+		}
+		fallback, err := newClient(region)
+		if err != nil {
+			return nil, err
+		}
+		fallbacks = append(fallbacks, regionalClient{region: region, client: fallback})
+	}
+
+	model := config.Model
+	if model == "" {
+		model = DefaultModel
 	}
 
 	return &Client{
-		ProjectID: projectID,
-		Region:    region,
-		client:    client,
+		ProjectID:       config.ProjectID,
+		Region:          config.Region,
+		FallbackRegions: config.FallbackRegions,
+		Model:           model,
+		StageModels:     config.StageModels,
+		Generation:      config.Generation,
+		SafetySettings:  config.SafetySettings,
+		Retry:           config.Retry,
+		Thinking:        config.Thinking,
+		StageThinking:   config.StageThinking,
+		CandidateCount:  config.CandidateCount,
+		ContextCache:    config.ContextCache,
+		Logger:          config.Logger,
+		client:          client,
+		fallbacks:       fallbacks,
 	}, nil
 }
 
+// modelFor returns the model to use for a call with options
+func (c *Client) modelFor(options llm.Options) string {
+	if model, ok := c.StageModels[options.Stage]; ok && model != "" {
+		return model
+	}
+	if c.Model != "" {
+		return c.Model
+	}
+	return DefaultModel
+}
+
+// logger returns the configured logger, falling back to slog.Default
+func (c *Client) logger() *slog.Logger {
+	if c.Logger == nil {
+		return slog.Default()
+	}
+	return c.Logger
+}
+
 // Close closes the underlying client connection
 // The new SDK Client doesn't have a Close method exposed in the interface shown by go doc?
 // Wait, go doc didn't show Close.
@@ -52,7 +181,17 @@ func (c *Client) Close() error {
 }
 
 // CallAPI calls the Gemini API and adapts the response to generic format
-func (c *Client) CallAPI(messages []llm.Message, tools []llm.Tool) (*llm.Response, error) {
+func (c *Client) CallAPI(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	if c.Retry.MaxAttempts > 1 {
+		return llm.WithRetry(llm.ClientFunc(c.generate), c.Retry).CallAPI(ctx, messages, tools, opts...)
+	}
+	return c.generate(ctx, messages, tools, opts...)
+}
+
+// generate makes a single GenerateContent request
+func (c *Client) generate(ctx context.Context, messages []llm.Message, tools []llm.Tool, opts ...llm.CallOption) (*llm.Response, error) {
+	options := llm.ApplyOptions(opts)
+
 	// 1. Configure Tools
 	var toolConfig *genai.Tool
 	if len(tools) > 0 {
@@ -66,49 +205,62 @@ func (c *Client) CallAPI(messages []llm.Message, tools []llm.Tool) (*llm.Respons
 	}
 
 	// 2. Convert Messages to Gemini Contents
-	var contents []*genai.Content
-	var systemInstruction *genai.Content
-
-	for _, msg := range messages {
-		if msg.Role == "system" {
-			// Handle System Instruction
-			parts := convertMessageContent(msg.Content)
-			systemInstruction = &genai.Content{
-				Parts: parts,
-			}
-			continue
-		}
-
-		role := "user"
-		if msg.Role == "assistant" {
-			role = "model"
-		}
-
-		parts := convertMessageContent(msg.Content)
-		contents = append(contents, &genai.Content{
-			Role:  role,
-			Parts: parts,
-		})
-	}
+	contents, systemInstruction := convertMessages(messages)
 
 	// 3. Generate Content
-	config := &genai.GenerateContentConfig{
-		Temperature: float32Ptr(0),
-	}
+	config := generateContentConfig(c.Generation.Merge(options.Generation))
+	config.SafetySettings = c.SafetySettings
+	config.ThinkingConfig = c.thinkingFor(options)
+	config.CandidateCount = c.CandidateCount
 	if toolConfig != nil {
 		config.Tools = []*genai.Tool{toolConfig}
+		config.ToolConfig = convertToolChoice(options.ToolChoice)
 	}
 	if systemInstruction != nil {
 		config.SystemInstruction = systemInstruction
 	}
+	// Native JSON mode returns the bare document, with no markdown fences to strip
+	if options.ResponseSchema != nil || options.JSONMode {
+		config.ResponseMIMEType = "application/json"
+	}
+	if options.ResponseSchema != nil {
+		config.ResponseSchema = convertInputSchema(options.ResponseSchema.Schema)
+	}
 
-	resp, err := c.client.Models.GenerateContent(context.Background(), modelName, contents, config)
+	model := c.modelFor(options)
+	resp, err := c.generateInRegions(ctx, model, contents, config, options.Stream)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
+	if err := checkBlocked(resp); err != nil {
+		return nil, err
+	}
 
 	// 4. Convert Response to generic format
-	return convertResponse(resp), nil
+	llmResp := convertResponse(resp)
+	if llmResp.Model == "" {
+		llmResp.Model = model
+	}
+	llmResp.Usage.EstimatedCostUSD = llm.EstimateCost(llmResp.Model, llmResp.Usage)
+	return llmResp, nil
+}
+
+// generateContentConfig maps generation settings to genai, keeping the
+// deterministic temperature of 0 unless one is configured
+func generateContentConfig(gen llm.GenerationConfig) *genai.GenerateContentConfig {
+	config := &genai.GenerateContentConfig{
+		Temperature:     float32Ptr(0),
+		TopP:            gen.TopP,
+		MaxOutputTokens: gen.MaxOutputTokens,
+		StopSequences:   gen.StopSequences,
+	}
+	if gen.Temperature != nil {
+		config.Temperature = gen.Temperature
+	}
+	if gen.TopK != nil {
+		config.TopK = float32Ptr(float32(*gen.TopK))
+	}
+	return config
 }
 
 func float32Ptr(v float32) *float32 {
@@ -117,39 +269,168 @@ func float32Ptr(v float32) *float32 {
 
 // --- Adapter Helpers ---
 
+// convertError maps genai API errors to llm.APIError so callers can classify
+// them, wrapping authentication failures in AuthError
+func convertError(err error) error {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		err = &llm.APIError{
+			Provider:   "vertexai",
+			StatusCode: apiErr.Code,
+			Status:     apiErr.Status,
+			Message:    apiErr.Message,
+		}
+	}
+	if authFailure(err) {
+		return &AuthError{Err: err}
+	}
+	return err
+}
+
+// convertToolChoice maps a tool choice to Gemini's function calling mode
+func convertToolChoice(choice *llm.ToolChoice) *genai.ToolConfig {
+	if choice == nil {
+		return nil
+	}
+	calling := &genai.FunctionCallingConfig{}
+	switch choice.Type {
+	case llm.ToolChoiceAny:
+		calling.Mode = genai.FunctionCallingConfigModeAny
+	case llm.ToolChoiceTool:
+		calling.Mode = genai.FunctionCallingConfigModeAny
+		calling.AllowedFunctionNames = []string{choice.Name}
+	case llm.ToolChoiceNone:
+		calling.Mode = genai.FunctionCallingConfigModeNone
+	default:
+		calling.Mode = genai.FunctionCallingConfigModeAuto
+	}
+	return &genai.ToolConfig{FunctionCallingConfig: calling}
+}
+
 func convertTool(tool llm.Tool) *genai.FunctionDeclaration {
 	// Convert InputSchema to OpenAPI Schema
 	// Anthropic InputSchema is already very similar to JSON Schema
 	// We need to map it to genai.Schema
 
-	// Simplified conversion for the specific tool we have
-	// For a generic solution, we would need a recursive converter
+	return &genai.FunctionDeclaration{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Parameters:  convertInputSchema(tool.InputSchema),
+	}
+}
+
+// convertInputSchema maps a tool or response schema to genai.Schema
+func convertInputSchema(schema llm.InputSchema) *genai.Schema {
+	return &genai.Schema{
+		Type:       genai.TypeObject,
+		Properties: convertProperties(schema.Properties),
+		Required:   schema.Required,
+	}
+}
+
+func convertProperties(props map[string]llm.Property) map[string]*genai.Schema {
+	if len(props) == 0 {
+		return nil
+	}
+	out := make(map[string]*genai.Schema, len(props))
+	for name, prop := range props {
+		out[name] = convertProperty(prop)
+	}
+	return out
+}
+
+// convertProperty recursively maps a JSON schema property to genai.Schema
+func convertProperty(prop llm.Property) *genai.Schema {
+	schema := &genai.Schema{
+		Type:        convertType(prop.Type),
+		Description: prop.Description,
+		Default:     prop.Default,
+		Enum:        prop.Enum,
+		Properties:  convertProperties(prop.Properties),
+		Required:    prop.Required,
+	}
+	if len(prop.Enum) > 0 && schema.Type == genai.TypeString {
+		schema.Format = "enum"
+	}
+	if prop.Items != nil {
+		schema.Items = convertProperty(*prop.Items)
+	}
+	return schema
+}
+
+func convertType(t string) genai.Type {
+	switch t {
+	case "integer":
+		return genai.TypeInteger
+	case "number":
+		return genai.TypeNumber
+	case "boolean":
+		return genai.TypeBoolean
+	case "array":
+		return genai.TypeArray
+	case "object":
+		return genai.TypeObject
+	default:
+		return genai.TypeString
+	}
+}
+
+// convertMessages maps the conversation to Gemini contents, with all system
+// messages combined into a single systemInstruction
+func convertMessages(messages []llm.Message) ([]*genai.Content, *genai.Content) {
+	system, messages := llm.SplitSystem(messages)
+
+	var systemInstruction *genai.Content
+	if system != "" {
+		systemInstruction = &genai.Content{Parts: []*genai.Part{{Text: system}}}
+	}
+
+	names := toolNames(messages)
 
-	properties := make(map[string]*genai.Schema)
-	for name, prop := range tool.InputSchema.Properties {
-		propType := genai.TypeString
-		if prop.Type == "integer" {
-			propType = genai.TypeInteger
+	var contents []*genai.Content
+	for _, msg := range messages {
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "model"
 		}
 
-		properties[name] = &genai.Schema{
-			Type:        propType,
-			Description: prop.Description,
+		parts := convertMessageContent(msg.Content, names)
+		contents = append(contents, &genai.Content{
+			Role:  role,
+			Parts: parts,
+		})
+	}
+	return contents, systemInstruction
+}
+
+// toolNames maps each tool_use ID in the conversation to its tool name, since
+// Gemini matches a FunctionResponse to its call by name rather than by ID
+func toolNames(messages []llm.Message) map[string]string {
+	names := make(map[string]string)
+	for _, msg := range messages {
+		blocks, ok := msg.Content.([]llm.ContentBlock)
+		if !ok {
+			continue
+		}
+		for _, block := range blocks {
+			if block.Type == "tool_use" && block.ID != "" {
+				names[block.ID] = block.Name
+			}
 		}
 	}
+	return names
+}
 
-	return &genai.FunctionDeclaration{
-		Name:        tool.Name,
-		Description: tool.Description,
-		Parameters: &genai.Schema{
-			Type:       genai.TypeObject,
-			Properties: properties,
-			Required:   tool.InputSchema.Required,
-		},
+// toolNameFor returns the name of the tool a result answers. IDs generated by
+// convertResponse embed the name, which covers results without their call.
+func toolNameFor(toolUseID string, names map[string]string) string {
+	if name, ok := names[toolUseID]; ok {
+		return name
 	}
+	return strings.TrimPrefix(toolUseID, "call_")
 }
 
-func convertMessageContent(content interface{}) []*genai.Part {
+func convertMessageContent(content interface{}, toolNames map[string]string) []*genai.Part {
 	var parts []*genai.Part
 
 	switch v := content.(type) {
@@ -160,6 +441,10 @@ func convertMessageContent(content interface{}) []*genai.Part {
 			switch block.Type {
 			case "text":
 				parts = append(parts, &genai.Part{Text: block.Text})
+			case "image":
+				if part := convertImage(block.Source); part != nil {
+					parts = append(parts, part)
+				}
 			case "tool_use":
 				// block.Input is interface{}, likely map[string]interface{}
 				var args map[string]interface{}
@@ -189,7 +474,7 @@ func convertMessageContent(content interface{}) []*genai.Part {
 
 				parts = append(parts, &genai.Part{
 					FunctionResponse: &genai.FunctionResponse{
-						Name:     "search_github_developers", // Hardcoded as before
+						Name:     toolNameFor(block.ToolUseID, toolNames),
 						Response: response,
 					},
 				})
@@ -199,42 +484,65 @@ func convertMessageContent(content interface{}) []*genai.Part {
 	return parts
 }
 
+// convertImage maps an image source to inline data or a file reference
+func convertImage(source *llm.ImageSource) *genai.Part {
+	if source == nil {
+		return nil
+	}
+	if source.Type == "url" {
+		return &genai.Part{FileData: &genai.FileData{FileURI: source.URL, MIMEType: source.MediaType}}
+	}
+	data, err := source.Bytes()
+	if err != nil {
+		return nil
+	}
+	return &genai.Part{InlineData: &genai.Blob{Data: data, MIMEType: source.MediaType}}
+}
+
 func convertResponse(resp *genai.GenerateContentResponse) *llm.Response {
 	llmResp := &llm.Response{
-		Role: "assistant",
-		Type: "message",
+		ID:    resp.ResponseID,
+		Role:  "assistant",
+		Type:  "message",
+		Model: resp.ModelVersion,
 	}
 
 	var content []llm.ContentBlock
 
-	for _, cand := range resp.Candidates {
-		if cand.Content != nil {
-			for _, part := range cand.Content.Parts {
-				if part.Text != "" {
-					content = append(content, llm.ContentBlock{
-						Type: "text",
-						Text: part.Text,
-					})
-				}
+	// Only one candidate is returned; concatenating parts from several
+	// would interleave alternative answers
+	if cand := selectCandidate(resp.Candidates); cand != nil && cand.Content != nil {
+		for _, part := range cand.Content.Parts {
+			// Thought summaries are kept apart from the answer text
+			if part.Text != "" && part.Thought {
+				content = append(content, llm.ContentBlock{
+					Type: "thinking",
+					Text: part.Text,
+				})
+			} else if part.Text != "" {
+				content = append(content, llm.ContentBlock{
+					Type: "text",
+					Text: part.Text,
+				})
+			}
+
+			if part.FunctionCall != nil {
+				toolID := fmt.Sprintf("call_%s", part.FunctionCall.Name)
 
-				if part.FunctionCall != nil {
-					toolID := fmt.Sprintf("call_%s", part.FunctionCall.Name)
-
-					// Capture ThoughtSignature
-					var thoughtSig string
-					if len(part.ThoughtSignature) > 0 {
-						thoughtSig = string(part.ThoughtSignature)
-					}
-
-					content = append(content, llm.ContentBlock{
-						Type:             "tool_use",
-						Name:             part.FunctionCall.Name,
-						ID:               toolID,
-						Input:            part.FunctionCall.Args,
-						ThoughtSignature: thoughtSig,
-					})
-					llmResp.StopReason = "tool_use"
+				// Capture ThoughtSignature
+				var thoughtSig string
+				if len(part.ThoughtSignature) > 0 {
+					thoughtSig = string(part.ThoughtSignature)
 				}
+
+				content = append(content, llm.ContentBlock{
+					Type:             "tool_use",
+					Name:             part.FunctionCall.Name,
+					ID:               toolID,
+					Input:            part.FunctionCall.Args,
+					ThoughtSignature: thoughtSig,
+				})
+				llmResp.StopReason = "tool_use"
 			}
 		}
 	}
@@ -246,9 +554,12 @@ func convertResponse(resp *genai.GenerateContentResponse) *llm.Response {
 
 	// Populate Usage Metadata
 	if resp.UsageMetadata != nil {
+		// Thoughts are billed as output but not counted in CandidatesTokenCount
 		llmResp.Usage = llm.Usage{
-			InputTokens:  int(resp.UsageMetadata.PromptTokenCount),
-			OutputTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+			InputTokens:       int(resp.UsageMetadata.PromptTokenCount),
+			OutputTokens:      int(resp.UsageMetadata.CandidatesTokenCount + resp.UsageMetadata.ThoughtsTokenCount),
+			ThinkingTokens:    int(resp.UsageMetadata.ThoughtsTokenCount),
+			CachedInputTokens: int(resp.UsageMetadata.CachedContentTokenCount),
 		}
 	}
 
@@ -0,0 +1,311 @@
+package vertexai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+func TestConvertInputSchema(t *testing.T) {
+	schema := llm.InputSchema{
+		Type: "object",
+		Properties: map[string]llm.Property{
+			"skills": {
+				Type:  "array",
+				Items: &llm.Property{Type: "string"},
+			},
+			"level": {
+				Type: "string",
+				Enum: []string{"junior", "mid", "senior"},
+			},
+			"remote": {Type: "boolean", Default: false},
+			"location": {
+				Type: "object",
+				Properties: map[string]llm.Property{
+					"city":   {Type: "string"},
+					"radius": {Type: "number"},
+				},
+				Required: []string{"city"},
+			},
+		},
+		Required: []string{"skills"},
+	}
+
+	got := convertInputSchema(schema)
+
+	if got.Type != genai.TypeObject || len(got.Required) != 1 {
+		t.Fatalf("Expected object schema with 1 required field, got %+v", got)
+	}
+	if skills := got.Properties["skills"]; skills.Type != genai.TypeArray || skills.Items == nil || skills.Items.Type != genai.TypeString {
+		t.Errorf("Expected array of strings for skills, got %+v", skills)
+	}
+	if level := got.Properties["level"]; len(level.Enum) != 3 || level.Format != "enum" {
+		t.Errorf("Expected enum for level, got %+v", level)
+	}
+	if remote := got.Properties["remote"]; remote.Type != genai.TypeBoolean || remote.Default != false {
+		t.Errorf("Expected boolean for remote with false default, got %+v", remote)
+	}
+	location := got.Properties["location"]
+	if location.Type != genai.TypeObject || location.Properties["radius"].Type != genai.TypeNumber {
+		t.Errorf("Expected nested object for location, got %+v", location)
+	}
+	if len(location.Required) != 1 || location.Required[0] != "city" {
+		t.Errorf("Expected nested required fields, got %v", location.Required)
+	}
+}
+
+func TestConvertMessageContentImages(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n fake image")
+	parts := convertMessageContent([]llm.ContentBlock{
+		{Type: "text", Text: "Review this architecture diagram"},
+		llm.ImageBlock("", png),
+		llm.ImageURLBlock("image/jpeg", "gs://bucket/portfolio.jpg"),
+	}, nil)
+
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 parts, got %d", len(parts))
+	}
+	inline := parts[1].InlineData
+	if inline == nil || inline.MIMEType != "image/png" || string(inline.Data) != string(png) {
+		t.Errorf("Expected inline PNG data, got %+v", inline)
+	}
+	file := parts[2].FileData
+	if file == nil || file.FileURI != "gs://bucket/portfolio.jpg" || file.MIMEType != "image/jpeg" {
+		t.Errorf("Expected file reference, got %+v", file)
+	}
+}
+
+func TestConvertMessages(t *testing.T) {
+	messages := []llm.Message{
+		{Role: "system", Content: "Be concise."},
+		{Role: "system", Content: "Answer in JSON."},
+		{Role: "user", Content: "Find Go developers"},
+		{Role: "assistant", Content: "ok"},
+	}
+
+	contents, system := convertMessages(messages)
+	if system == nil || len(system.Parts) != 1 || system.Parts[0].Text != "Be concise.\n\nAnswer in JSON." {
+		t.Fatalf("Expected both system messages in systemInstruction, got %+v", system)
+	}
+	if len(contents) != 2 || contents[0].Role != "user" || contents[1].Role != "model" {
+		t.Errorf("Expected user and model contents, got %+v", contents)
+	}
+}
+
+func TestConvertMessagesToolResponses(t *testing.T) {
+	messages := []llm.Message{
+		llm.UserText("Find Go developers and their top repos"),
+		llm.AssistantBlocks(
+			llm.ContentBlock{Type: "tool_use", ID: "toolu_01", Name: "search_github_developers", Input: map[string]interface{}{"language": "go"}},
+			llm.ContentBlock{Type: "tool_use", ID: "toolu_02", Name: "get_user_repositories", Input: map[string]interface{}{"username": "gopher"}},
+		),
+		llm.UserBlocks(
+			llm.ToolResult("toolu_01", "[]"),
+			llm.ToolResult("toolu_02", "[]"),
+			llm.ToolResult("call_get_user_details", "{}"),
+		),
+	}
+
+	contents, _ := convertMessages(messages)
+	if len(contents) != 3 {
+		t.Fatalf("Expected 3 contents, got %d", len(contents))
+	}
+
+	want := []string{"search_github_developers", "get_user_repositories", "get_user_details"}
+	parts := contents[2].Parts
+	if len(parts) != len(want) {
+		t.Fatalf("Expected %d function responses, got %d", len(want), len(parts))
+	}
+	for i, name := range want {
+		if parts[i].FunctionResponse == nil || parts[i].FunctionResponse.Name != name {
+			t.Errorf("Expected function response %d named %s, got %+v", i, name, parts[i].FunctionResponse)
+		}
+	}
+}
+
+func TestModelFor(t *testing.T) {
+	client := &Client{Model: "gemini-2.5-pro", StageModels: map[string]string{"strategy": "gemini-2.5-flash"}}
+
+	if got := client.modelFor(llm.ApplyOptions([]llm.CallOption{llm.WithStage("strategy")})); got != "gemini-2.5-flash" {
+		t.Errorf("Expected stage override, got %s", got)
+	}
+	if got := client.modelFor(llm.ApplyOptions([]llm.CallOption{llm.WithStage("ranking")})); got != "gemini-2.5-pro" {
+		t.Errorf("Expected configured model, got %s", got)
+	}
+	if got := (&Client{}).modelFor(llm.Options{}); got != DefaultModel {
+		t.Errorf("Expected default model, got %s", got)
+	}
+}
+
+func TestGenerateContentConfig(t *testing.T) {
+	t.Run("DefaultsToZeroTemperature", func(t *testing.T) {
+		config := generateContentConfig(llm.GenerationConfig{})
+		if config.Temperature == nil || *config.Temperature != 0 {
+			t.Errorf("Expected temperature 0, got %v", config.Temperature)
+		}
+	})
+
+	t.Run("CallOverridesClient", func(t *testing.T) {
+		topK := int32(40)
+		client := llm.GenerationConfig{TopK: &topK, MaxOutputTokens: 2048}
+		options := llm.ApplyOptions([]llm.CallOption{llm.WithTemperature(0.4), llm.WithMaxOutputTokens(512)})
+
+		config := generateContentConfig(client.Merge(options.Generation))
+		if *config.Temperature != 0.4 || config.MaxOutputTokens != 512 {
+			t.Errorf("Expected per-call temperature and max tokens, got %v and %d", *config.Temperature, config.MaxOutputTokens)
+		}
+		if config.TopK == nil || *config.TopK != 40 {
+			t.Errorf("Expected client top-k 40, got %v", config.TopK)
+		}
+	})
+}
+
+func TestCallAPIHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := client.CallAPI(ctx, []llm.Message{llm.UserText("hi")}, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected cancellation to stop the request, took %v", elapsed)
+	}
+}
+
+func TestCallAPIJSONMode(t *testing.T) {
+	var body map[string]interface{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "{\"level\": \"senior\"}"}]}, "finishReason": "STOP"}]}`))
+	})
+
+	schema := &llm.ResponseSchema{
+		Name:   "requirements",
+		Schema: llm.InputSchema{Type: "object", Properties: map[string]llm.Property{"level": {Type: "string"}}},
+	}
+	var out struct {
+		Level string `json:"level"`
+	}
+	if _, err := llm.CallStructured(context.Background(), client, []llm.Message{llm.UserText("hi")}, schema, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if out.Level != "senior" {
+		t.Errorf("Expected level senior, got %q", out.Level)
+	}
+
+	config, _ := body["generationConfig"].(map[string]interface{})
+	if config["responseMimeType"] != "application/json" || config["responseSchema"] == nil {
+		t.Errorf("Expected native JSON mode with a response schema, got %v", config)
+	}
+
+	t.Run("WithoutSchema", func(t *testing.T) {
+		client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil, llm.WithJSONMode())
+		config, _ := body["generationConfig"].(map[string]interface{})
+		if config["responseMimeType"] != "application/json" || config["responseSchema"] != nil {
+			t.Errorf("Expected JSON MIME type without schema, got %v", config)
+		}
+	})
+}
+
+func TestThinkingFor(t *testing.T) {
+	client := &Client{
+		Thinking:      ThinkingBudget(1024, false),
+		StageThinking: map[string]*genai.ThinkingConfig{"ranking": ThinkingBudget(8192, true)},
+	}
+
+	t.Run("default", func(t *testing.T) {
+		got := client.thinkingFor(llm.ApplyOptions([]llm.CallOption{llm.WithStage("strategy")}))
+		if got == nil || *got.ThinkingBudget != 1024 {
+			t.Errorf("Expected default budget 1024, got %+v", got)
+		}
+	})
+
+	t.Run("stage override", func(t *testing.T) {
+		got := client.thinkingFor(llm.ApplyOptions([]llm.CallOption{llm.WithStage("ranking")}))
+		if got == nil || *got.ThinkingBudget != 8192 || !got.IncludeThoughts {
+			t.Errorf("Expected ranking budget 8192 with thoughts, got %+v", got)
+		}
+	})
+}
+
+func TestParseThinkingBudgets(t *testing.T) {
+	budgets, err := ParseThinkingBudgets("strategy=1024, ranking=-1", false)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(budgets) != 2 || *budgets["strategy"].ThinkingBudget != 1024 || *budgets["ranking"].ThinkingBudget != -1 {
+		t.Errorf("Expected strategy=1024 and ranking=-1, got %+v", budgets)
+	}
+
+	if _, err := ParseThinkingBudgets("ranking=lots", false); err == nil {
+		t.Error("Expected error for non-numeric budget, got nil")
+	}
+}
+
+func TestConvertResponseThoughts(t *testing.T) {
+	resp := convertResponse(&genai.GenerateContentResponse{
+		Candidates: []*genai.Candidate{{Content: &genai.Content{Parts: []*genai.Part{
+			{Text: "Weighing Go experience first", Thought: true},
+			{Text: `{"ok": true}`},
+		}}}},
+	})
+
+	if len(resp.Content) != 2 || resp.Content[0].Type != "thinking" || resp.Content[1].Type != "text" {
+		t.Fatalf("Expected thinking then text blocks, got %+v", resp.Content)
+	}
+	if text := llm.ResponseText(resp); text != `{"ok": true}` {
+		t.Errorf("Expected thoughts excluded from response text, got %q", text)
+	}
+}
+
+func TestConvertResponseUsage(t *testing.T) {
+	resp := convertResponse(&genai.GenerateContentResponse{
+		UsageMetadata: &genai.GenerateContentResponseUsageMetadata{
+			PromptTokenCount:        1200,
+			CachedContentTokenCount: 1000,
+			CandidatesTokenCount:    300,
+			ThoughtsTokenCount:      500,
+		},
+	})
+
+	want := llm.Usage{InputTokens: 1200, OutputTokens: 800, ThinkingTokens: 500, CachedInputTokens: 1000}
+	if resp.Usage != want {
+		t.Errorf("Expected %+v, got %+v", want, resp.Usage)
+	}
+}
+
+func TestConvertToolChoice(t *testing.T) {
+	if convertToolChoice(nil) != nil {
+		t.Error("Expected no tool config when unset")
+	}
+
+	forced := convertToolChoice(&llm.ToolChoice{Type: llm.ToolChoiceTool, Name: "search_github_developers"})
+	if fc := forced.FunctionCallingConfig; fc.Mode != genai.FunctionCallingConfigModeAny || len(fc.AllowedFunctionNames) != 1 {
+		t.Errorf("Expected ANY mode restricted to one function, got %+v", fc)
+	}
+
+	none := convertToolChoice(&llm.ToolChoice{Type: llm.ToolChoiceNone})
+	if none.FunctionCallingConfig.Mode != genai.FunctionCallingConfigModeNone {
+		t.Errorf("Expected NONE mode, got %s", none.FunctionCallingConfig.Mode)
+	}
+}
@@ -0,0 +1,143 @@
+package vertexai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+const (
+	// contextCachePrefix marks cached contents created by this client so later
+	// runs can find and reuse them
+	contextCachePrefix = "sourcing-agent-"
+	// DefaultContextCacheTTL is how long a cached prompt lives unless configured
+	DefaultContextCacheTTL = time.Hour
+	// contextCacheMargin avoids sending a cache that expires mid-request
+	contextCacheMargin = time.Minute
+)
+
+// ContextCacheConfig configures Gemini context caching. When enabled, the
+// system instruction and tools of each call are stored as Vertex cached
+// content and referenced by name, so repeated prompts are billed at the
+// cached-token rate across calls and runs.
+type ContextCacheConfig struct {
+	Enabled bool
+	// TTL is how long a cached prompt lives. Defaults to DefaultContextCacheTTL.
+	TTL time.Duration
+}
+
+// cachedPrompt is a cached content resource, or a prompt that cannot be cached
+// when name is empty
+type cachedPrompt struct {
+	name    string
+	expires time.Time
+}
+
+// withContextCache returns config referencing a cached copy of its system
+// instruction and tools, creating the cache if needed. Caching is best effort:
+// on failure the original config is returned and the call is sent uncached.
+func (c *Client) withContextCache(ctx context.Context, endpoint regionalClient, model string, config *genai.GenerateContentConfig) *genai.GenerateContentConfig {
+	// Cached content would have to carry the tool config too; calls that set
+	// one are rare enough to send uncached
+	if !c.ContextCache.Enabled || config.SystemInstruction == nil || config.ToolConfig != nil {
+		return config
+	}
+	key, err := contextCacheKey(endpoint.region, model, config)
+	if err != nil {
+		return config
+	}
+	name := c.cachedPromptName(ctx, endpoint.client, key, model, config)
+	if name == "" {
+		return config
+	}
+
+	// Cached content already carries the system instruction and tools, and
+	// Vertex rejects requests that repeat them
+	cached := *config
+	cached.CachedContent = name
+	cached.SystemInstruction = nil
+	cached.Tools = nil
+	return &cached
+}
+
+// cachedPromptName returns the cached content for key, reusing one from this
+// process or an earlier run before creating a new one
+func (c *Client) cachedPromptName(ctx context.Context, client *genai.Client, key, model string, config *genai.GenerateContentConfig) string {
+	now := time.Now()
+	c.mu.Lock()
+	entry, ok := c.promptCache[key]
+	c.mu.Unlock()
+	if ok && (entry.name == "" || entry.expires.After(now.Add(contextCacheMargin))) {
+		return entry.name
+	}
+
+	displayName := contextCachePrefix + key[:16]
+	for cached, err := range client.Caches.All(ctx) {
+		if err != nil {
+			break
+		}
+		if cached.DisplayName == displayName && cached.ExpireTime.After(now.Add(contextCacheMargin)) {
+			c.storePrompt(key, cachedPrompt{name: cached.Name, expires: cached.ExpireTime})
+			return cached.Name
+		}
+	}
+
+	ttl := c.ContextCache.TTL
+	if ttl <= 0 {
+		ttl = DefaultContextCacheTTL
+	}
+	created, err := client.Caches.Create(ctx, model, &genai.CreateCachedContentConfig{
+		DisplayName:       displayName,
+		TTL:               ttl,
+		SystemInstruction: config.SystemInstruction,
+		Tools:             config.Tools,
+	})
+	if err != nil {
+		err = convertError(err)
+		// Remember prompts Vertex refuses to cache, e.g. below the minimum
+		// token count, so they are not retried on every call
+		if ctx.Err() == nil && !llm.IsTransient(err) {
+			c.storePrompt(key, cachedPrompt{})
+		}
+		c.logger().Warn("Sending prompt without context cache", "error", err)
+		return ""
+	}
+
+	expires := created.ExpireTime
+	if expires.IsZero() {
+		expires = now.Add(ttl)
+	}
+	c.storePrompt(key, cachedPrompt{name: created.Name, expires: expires})
+	return created.Name
+}
+
+func (c *Client) storePrompt(key string, entry cachedPrompt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.promptCache == nil {
+		c.promptCache = make(map[string]cachedPrompt)
+	}
+	c.promptCache[key] = entry
+}
+
+// contextCacheKey identifies a cacheable prompt prefix. Cached content is
+// regional and model-specific, so both are part of the key.
+func contextCacheKey(region, model string, config *genai.GenerateContentConfig) (string, error) {
+	data, err := json.Marshal(struct {
+		Region            string         `json:"region"`
+		Model             string         `json:"model"`
+		SystemInstruction *genai.Content `json:"system_instruction"`
+		Tools             []*genai.Tool  `json:"tools,omitempty"`
+	}{region, model, config.SystemInstruction, config.Tools})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
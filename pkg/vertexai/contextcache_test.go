@@ -0,0 +1,113 @@
+package vertexai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+func TestCallAPIContextCache(t *testing.T) {
+	messages := []llm.Message{llm.SystemText("You are a ranking assistant."), llm.UserText("Input Data: {}")}
+	okResponse := `{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`
+
+	// cacheServer serves cachedContents requests, recording each generate
+	// request body, and answers creation with createStatus
+	type cacheServer struct {
+		lists, creates int
+		existing       string
+		generated      []map[string]json.RawMessage
+	}
+	newServer := func(t *testing.T, s *cacheServer, createStatus int) *Client {
+		return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasSuffix(r.URL.Path, "/cachedContents") && r.Method == http.MethodGet:
+				s.lists++
+				fmt.Fprintf(w, `{"cachedContents": [%s]}`, s.existing)
+			case strings.HasSuffix(r.URL.Path, "/cachedContents"):
+				s.creates++
+				w.WriteHeader(createStatus)
+				if createStatus != http.StatusOK {
+					w.Write([]byte(`{"error": {"code": 400, "message": "Cached content is too small", "status": "INVALID_ARGUMENT"}}`))
+					return
+				}
+				fmt.Fprintf(w, `{"name": "cachedContents/new", "expireTime": %q}`, time.Now().Add(time.Hour).Format(time.RFC3339))
+			default:
+				var body map[string]json.RawMessage
+				json.NewDecoder(r.Body).Decode(&body)
+				s.generated = append(s.generated, body)
+				w.Write([]byte(okResponse))
+			}
+		})
+	}
+
+	t.Run("CreatesOnceAndReuses", func(t *testing.T) {
+		s := &cacheServer{}
+		client := newServer(t, s, http.StatusOK)
+		client.ContextCache = ContextCacheConfig{Enabled: true}
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.CallAPI(context.Background(), messages, nil); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+		}
+		if s.lists != 1 || s.creates != 1 {
+			t.Errorf("Expected 1 list and 1 create, got %d and %d", s.lists, s.creates)
+		}
+		for _, body := range s.generated {
+			if string(body["cachedContent"]) != `"cachedContents/new"` {
+				t.Errorf("Expected cachedContent reference, got %s", body["cachedContent"])
+			}
+			if _, ok := body["systemInstruction"]; ok {
+				t.Error("Expected systemInstruction to be omitted when cached")
+			}
+		}
+	})
+
+	t.Run("ReusesEarlierRun", func(t *testing.T) {
+		s := &cacheServer{}
+		client := newServer(t, s, http.StatusOK)
+		client.ContextCache = ContextCacheConfig{Enabled: true}
+
+		_, system := convertMessages(messages)
+		key, err := contextCacheKey("", client.Model, &genai.GenerateContentConfig{SystemInstruction: system})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.existing = fmt.Sprintf(`{"name": "cachedContents/earlier", "displayName": %q, "expireTime": %q}`,
+			contextCachePrefix+key[:16], time.Now().Add(time.Hour).Format(time.RFC3339))
+
+		if _, err := client.CallAPI(context.Background(), messages, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if s.creates != 0 || string(s.generated[0]["cachedContent"]) != `"cachedContents/earlier"` {
+			t.Errorf("Expected the earlier cache to be reused, got %d creates and %s", s.creates, s.generated[0]["cachedContent"])
+		}
+	})
+
+	t.Run("FallsBackWhenUncacheable", func(t *testing.T) {
+		s := &cacheServer{}
+		client := newServer(t, s, http.StatusBadRequest)
+		client.ContextCache = ContextCacheConfig{Enabled: true}
+
+		for i := 0; i < 2; i++ {
+			if _, err := client.CallAPI(context.Background(), messages, nil); err != nil {
+				t.Fatalf("Expected uncached call to succeed, got %v", err)
+			}
+		}
+		if s.creates != 1 {
+			t.Errorf("Expected a single create attempt, got %d", s.creates)
+		}
+		for _, body := range s.generated {
+			if _, ok := body["systemInstruction"]; !ok {
+				t.Error("Expected systemInstruction to be sent uncached")
+			}
+		}
+	})
+}
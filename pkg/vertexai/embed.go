@@ -0,0 +1,46 @@
+package vertexai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+const (
+	// DefaultEmbeddingModel is the Vertex AI text embedding model used by Embed
+	DefaultEmbeddingModel = "text-embedding-005"
+	// maxEmbedBatch is the number of texts Vertex accepts per embedding request
+	maxEmbedBatch = 250
+)
+
+// Embed returns one embedding per text using DefaultEmbeddingModel.
+// Long inputs are truncated by the API rather than rejected.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxEmbedBatch {
+		end := min(start+maxEmbedBatch, len(texts))
+
+		contents := make([]*genai.Content, 0, end-start)
+		for _, text := range texts[start:end] {
+			contents = append(contents, genai.NewContentFromText(text, genai.RoleUser))
+		}
+
+		resp, err := c.client.Models.EmbedContent(ctx, DefaultEmbeddingModel, contents, &genai.EmbedContentConfig{
+			AutoTruncate: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed content: %w", convertError(err))
+		}
+		if len(resp.Embeddings) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(resp.Embeddings))
+		}
+		for _, e := range resp.Embeddings {
+			embeddings = append(embeddings, e.Values)
+		}
+	}
+	return embeddings, nil
+}
+
+var _ llm.Embedder = (*Client)(nil)
@@ -0,0 +1,107 @@
+package vertexai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+// regionalClient is a genai client bound to one Vertex AI region
+type regionalClient struct {
+	region string
+	client *genai.Client
+}
+
+// RegionUsage reports how many calls a region served and how many it
+// rejected for lack of capacity or quota
+type RegionUsage struct {
+	Region         string
+	Calls          int
+	CapacityErrors int
+}
+
+// endpoints returns the primary region followed by the fallback regions
+func (c *Client) endpoints() []regionalClient {
+	return append([]regionalClient{{region: c.Region, client: c.client}}, c.fallbacks...)
+}
+
+// generateInRegions sends the request to each region in turn, moving on only
+// when a region rejects it for capacity or quota
+func (c *Client) generateInRegions(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig, stream llm.StreamHandler) (*genai.GenerateContentResponse, error) {
+	endpoints := c.endpoints()
+	var lastErr error
+	for i, endpoint := range endpoints {
+		regionConfig := c.withContextCache(ctx, endpoint, model, config)
+		var resp *genai.GenerateContentResponse
+		var err error
+		if stream != nil {
+			resp, err = generateStream(ctx, endpoint.client, model, contents, regionConfig, stream)
+		} else {
+			resp, err = endpoint.client.Models.GenerateContent(ctx, model, contents, regionConfig)
+		}
+		if err == nil {
+			c.recordRegion(endpoint.region, false)
+			return resp, nil
+		}
+
+		err = convertError(err)
+		if !capacityError(err) {
+			return nil, err
+		}
+		c.recordRegion(endpoint.region, true)
+		lastErr = err
+		if i < len(endpoints)-1 {
+			c.logger().Warn("Vertex AI region out of capacity, retrying in the next region", "region", endpoint.region, "next_region", endpoints[i+1].region)
+		}
+	}
+	return nil, lastErr
+}
+
+// capacityError reports whether err is a quota or capacity rejection that
+// another region may not share
+func capacityError(err error) bool {
+	var apiErr *llm.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.Status {
+	case "RESOURCE_EXHAUSTED", "UNAVAILABLE":
+		return true
+	}
+	return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusServiceUnavailable
+}
+
+func (c *Client) recordRegion(region string, capacityErr bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.regionUsage == nil {
+		c.regionUsage = make(map[string]*RegionUsage)
+	}
+	usage, ok := c.regionUsage[region]
+	if !ok {
+		usage = &RegionUsage{Region: region}
+		c.regionUsage[region] = usage
+	}
+	usage.Calls++
+	if capacityErr {
+		usage.CapacityErrors++
+	}
+}
+
+// RegionUsage returns a snapshot of the calls each region served, in failover order
+func (c *Client) RegionUsage() []RegionUsage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var out []RegionUsage
+	for _, endpoint := range c.endpoints() {
+		if usage, ok := c.regionUsage[endpoint.region]; ok {
+			out = append(out, *usage)
+		}
+	}
+	return out
+}
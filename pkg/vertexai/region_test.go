@@ -0,0 +1,66 @@
+package vertexai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCallAPIRegionFailover(t *testing.T) {
+	exhausted := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error": {"code": 429, "message": "Quota exceeded", "status": "RESOURCE_EXHAUSTED"}}`))
+	}
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+	}
+
+	t.Run("FailsOverOnCapacityErrors", func(t *testing.T) {
+		client := newTestClient(t, exhausted)
+		client.Region = "us-central1"
+		client.fallbacks = []regionalClient{
+			{region: "us-east4", client: newTestGenaiClient(t, exhausted)},
+			{region: "europe-west4", client: newTestGenaiClient(t, ok)},
+		}
+
+		resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		if err != nil {
+			t.Fatalf("Expected success in the last region, got %v", err)
+		}
+		if llm.ResponseText(resp) != "ok" {
+			t.Errorf("Expected ok, got %q", llm.ResponseText(resp))
+		}
+
+		usage := client.RegionUsage()
+		if len(usage) != 3 || usage[0].CapacityErrors != 1 || usage[1].CapacityErrors != 1 {
+			t.Fatalf("Expected capacity errors in the first two regions, got %+v", usage)
+		}
+		if usage[2].Region != "europe-west4" || usage[2].Calls != 1 || usage[2].CapacityErrors != 0 {
+			t.Errorf("Expected europe-west4 to serve the call, got %+v", usage[2])
+		}
+	})
+
+	t.Run("KeepsOtherErrorsInRegion", func(t *testing.T) {
+		fallbackCalls := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": {"code": 400, "message": "Invalid argument", "status": "INVALID_ARGUMENT"}}`))
+		})
+		client.fallbacks = []regionalClient{{region: "us-east4", client: newTestGenaiClient(t, func(w http.ResponseWriter, r *http.Request) {
+			fallbackCalls++
+			ok(w, r)
+		})}}
+
+		_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		var apiErr *llm.APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+			t.Errorf("Expected the 400 to be returned, got %v", err)
+		}
+		if fallbackCalls != 0 {
+			t.Errorf("Expected no fallback calls, got %d", fallbackCalls)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package vertexai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCallAPIRetry(t *testing.T) {
+	exhausted := `{"error": {"code": 429, "message": "Quota exceeded", "status": "RESOURCE_EXHAUSTED"}}`
+	retry := llm.RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond, Multiplier: 2}
+
+	t.Run("RetriesResourceExhausted", func(t *testing.T) {
+		calls := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls < 3 {
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(exhausted))
+				return
+			}
+			w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+		})
+		client.Retry = retry
+
+		resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		if err != nil {
+			t.Fatalf("Expected success after retries, got %v", err)
+		}
+		if calls != 3 || llm.ResponseText(resp) != "ok" {
+			t.Errorf("Expected 3 calls and ok, got %d and %q", calls, llm.ResponseText(resp))
+		}
+	})
+
+	t.Run("GivesUpAtAttemptCap", func(t *testing.T) {
+		calls := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error": {"code": 503, "message": "Overloaded", "status": "UNAVAILABLE"}}`))
+		})
+		client.Retry = retry
+
+		_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		var apiErr *llm.APIError
+		if !errors.As(err, &apiErr) || apiErr.Status != "UNAVAILABLE" {
+			t.Errorf("Expected UNAVAILABLE API error, got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("Expected 3 attempts, got %d", calls)
+		}
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		calls := 0
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(exhausted))
+		})
+
+		client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		if calls != 1 {
+			t.Errorf("Expected a single attempt, got %d", calls)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+package vertexai
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// BlockedError reports a prompt or response that Gemini's safety filters blocked
+type BlockedError struct {
+	// Reason is the prompt block reason or the candidate finish reason, e.g. "SAFETY"
+	Reason string
+	// Message is Vertex's explanation, when provided
+	Message string
+	// Categories lists the harm categories that triggered the block
+	Categories []string
+}
+
+func (e *BlockedError) Error() string {
+	msg := fmt.Sprintf("vertexai response blocked (%s)", e.Reason)
+	if len(e.Categories) > 0 {
+		msg += ": " + strings.Join(e.Categories, ", ")
+	}
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// blockedFinishReasons are candidate finish reasons caused by content filters
+var blockedFinishReasons = map[genai.FinishReason]bool{
+	genai.FinishReasonSafety:            true,
+	genai.FinishReasonBlocklist:         true,
+	genai.FinishReasonProhibitedContent: true,
+	genai.FinishReasonSPII:              true,
+}
+
+// checkBlocked returns a BlockedError when the prompt was blocked or every
+// candidate was stopped by a content filter
+func checkBlocked(resp *genai.GenerateContentResponse) error {
+	if fb := resp.PromptFeedback; fb != nil && fb.BlockReason != "" {
+		return &BlockedError{
+			Reason:     string(fb.BlockReason),
+			Message:    fb.BlockReasonMessage,
+			Categories: blockedCategories(fb.SafetyRatings),
+		}
+	}
+
+	if len(resp.Candidates) == 0 {
+		return nil
+	}
+	var blocked *BlockedError
+	for _, cand := range resp.Candidates {
+		if !blockedFinishReasons[cand.FinishReason] {
+			return nil
+		}
+		if blocked == nil {
+			blocked = &BlockedError{
+				Reason:     string(cand.FinishReason),
+				Message:    cand.FinishMessage,
+				Categories: blockedCategories(cand.SafetyRatings),
+			}
+		}
+	}
+	return blocked
+}
+
+func blockedCategories(ratings []*genai.SafetyRating) []string {
+	var categories []string
+	for _, r := range ratings {
+		if r != nil && r.Blocked {
+			categories = append(categories, string(r.Category))
+		}
+	}
+	return categories
+}
+
+// ParseSafetySettings parses a comma-separated list of category=threshold
+// pairs, e.g. "HARASSMENT=BLOCK_ONLY_HIGH,HATE_SPEECH=BLOCK_ONLY_HIGH".
+// The HARM_CATEGORY_ prefix is optional.
+func ParseSafetySettings(spec string) ([]*genai.SafetySetting, error) {
+	var settings []*genai.SafetySetting
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		category, threshold, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid safety setting %q: expected category=threshold", pair)
+		}
+		category = strings.ToUpper(strings.TrimSpace(category))
+		if !strings.HasPrefix(category, "HARM_CATEGORY_") {
+			category = "HARM_CATEGORY_" + category
+		}
+		settings = append(settings, &genai.SafetySetting{
+			Category:  genai.HarmCategory(category),
+			Threshold: genai.HarmBlockThreshold(strings.ToUpper(strings.TrimSpace(threshold))),
+		})
+	}
+	return settings, nil
+}
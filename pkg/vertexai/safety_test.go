@@ -0,0 +1,78 @@
+package vertexai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+func TestSafetySettings(t *testing.T) {
+	t.Run("SentWithRequest", func(t *testing.T) {
+		var body string
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			body = string(data)
+			w.Write([]byte(`{"candidates": [{"content": {"role": "model", "parts": [{"text": "ok"}]}, "finishReason": "STOP"}]}`))
+		})
+		client.SafetySettings, _ = ParseSafetySettings("harassment=block_only_high")
+
+		if _, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(body, `"category":"HARM_CATEGORY_HARASSMENT"`) || !strings.Contains(body, `"threshold":"BLOCK_ONLY_HIGH"`) {
+			t.Errorf("Expected safety settings in request, got %s", body)
+		}
+	})
+
+	t.Run("BlockedPromptReturnsReason", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"promptFeedback": {"blockReason": "SAFETY", "safetyRatings": [
+				{"category": "HARM_CATEGORY_HATE_SPEECH", "probability": "HIGH", "blocked": true},
+				{"category": "HARM_CATEGORY_HARASSMENT", "probability": "LOW"}
+			]}}`))
+		})
+
+		_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		var blocked *BlockedError
+		if !errors.As(err, &blocked) {
+			t.Fatalf("Expected *BlockedError, got %v", err)
+		}
+		if blocked.Reason != "SAFETY" || len(blocked.Categories) != 1 || blocked.Categories[0] != "HARM_CATEGORY_HATE_SPEECH" {
+			t.Errorf("Unexpected blocked error %+v", blocked)
+		}
+		if llm.IsTransient(err) {
+			t.Error("Expected a blocked prompt not to be retried")
+		}
+	})
+
+	t.Run("BlockedCandidate", func(t *testing.T) {
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"candidates": [{"finishReason": "SAFETY", "safetyRatings": [{"category": "HARM_CATEGORY_HARASSMENT", "blocked": true}]}]}`))
+		})
+
+		_, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+		var blocked *BlockedError
+		if !errors.As(err, &blocked) || blocked.Reason != "SAFETY" {
+			t.Errorf("Expected SAFETY block, got %v", err)
+		}
+	})
+}
+
+func TestParseSafetySettings(t *testing.T) {
+	settings, err := ParseSafetySettings("HATE_SPEECH=BLOCK_NONE, HARM_CATEGORY_HARASSMENT=BLOCK_ONLY_HIGH")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(settings) != 2 || settings[0].Category != genai.HarmCategoryHateSpeech || settings[1].Threshold != genai.HarmBlockThresholdBlockOnlyHigh {
+		t.Errorf("Unexpected settings %+v", settings)
+	}
+	if _, err := ParseSafetySettings("HATE_SPEECH"); err == nil {
+		t.Error("Expected error for a setting without threshold")
+	}
+}
@@ -0,0 +1,35 @@
+package vertexai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// newTestClient returns a Client whose requests are served by handler. It uses
+// the Gemini API backend with a fake key so no Google credentials are needed;
+// the request and response formats match Vertex AI.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	return &Client{Model: DefaultModel, client: newTestGenaiClient(t, handler)}
+}
+
+// newTestGenaiClient returns a genai client whose requests are served by handler
+func newTestGenaiClient(t *testing.T, handler http.HandlerFunc) *genai.Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:      "test-key",
+		Backend:     genai.BackendGeminiAPI,
+		HTTPOptions: genai.HTTPOptions{BaseURL: server.URL},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create genai client: %v", err)
+	}
+	return client
+}
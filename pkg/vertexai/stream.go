@@ -0,0 +1,107 @@
+package vertexai
+
+import (
+	"context"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+// generateStream makes a streaming GenerateContent request, forwarding text
+// and tool calls to handler as they arrive, and returns the merged response
+func generateStream(ctx context.Context, client *genai.Client, model string, contents []*genai.Content, config *genai.GenerateContentConfig, handler llm.StreamHandler) (*genai.GenerateContentResponse, error) {
+	merged := &genai.GenerateContentResponse{}
+	for chunk, err := range client.Models.GenerateContentStream(ctx, model, contents, config) {
+		if err != nil {
+			return nil, err
+		}
+		mergeStreamChunk(merged, chunk, handler)
+	}
+	return merged, nil
+}
+
+// mergeStreamChunk folds chunk into merged, emitting its new content to handler
+func mergeStreamChunk(merged, chunk *genai.GenerateContentResponse, handler llm.StreamHandler) {
+	if chunk.ResponseID != "" {
+		merged.ResponseID = chunk.ResponseID
+	}
+	if chunk.ModelVersion != "" {
+		merged.ModelVersion = chunk.ModelVersion
+	}
+	if chunk.PromptFeedback != nil {
+		merged.PromptFeedback = chunk.PromptFeedback
+	}
+	// Usage is cumulative; the last chunk carries the totals
+	if chunk.UsageMetadata != nil {
+		merged.UsageMetadata = chunk.UsageMetadata
+	}
+
+	for _, cand := range chunk.Candidates {
+		if cand == nil {
+			continue
+		}
+		// Chunks identify candidates by index when several are requested
+		i := int(cand.Index)
+		for len(merged.Candidates) <= i {
+			merged.Candidates = append(merged.Candidates, &genai.Candidate{
+				Index:   int32(len(merged.Candidates)),
+				Content: &genai.Content{Role: genai.RoleModel},
+			})
+		}
+		target := merged.Candidates[i]
+		if cand.FinishReason != "" {
+			target.FinishReason = cand.FinishReason
+			target.FinishMessage = cand.FinishMessage
+		}
+		if len(cand.SafetyRatings) > 0 {
+			target.SafetyRatings = cand.SafetyRatings
+		}
+		if cand.Content == nil {
+			continue
+		}
+
+		for _, part := range cand.Content.Parts {
+			if part == nil {
+				continue
+			}
+			// Only the first candidate is streamed; the final response may
+			// still select another by finish reason
+			if i == 0 && handler != nil {
+				emitPart(part, handler)
+			}
+			appendPart(target.Content, part)
+		}
+	}
+}
+
+func emitPart(part *genai.Part, handler llm.StreamHandler) {
+	switch {
+	case part.FunctionCall != nil:
+		handler(llm.StreamChunk{ToolUse: &llm.ContentBlock{
+			Type:  "tool_use",
+			ID:    "call_" + part.FunctionCall.Name,
+			Name:  part.FunctionCall.Name,
+			Input: part.FunctionCall.Args,
+		}})
+	case part.Text != "" && !part.Thought:
+		handler(llm.StreamChunk{Text: part.Text})
+	}
+}
+
+// appendPart adds part to content, joining consecutive text deltas into one part
+func appendPart(content *genai.Content, part *genai.Part) {
+	if n := len(content.Parts); n > 0 && part.Text != "" && part.FunctionCall == nil {
+		last := content.Parts[n-1]
+		if last.Text != "" && last.FunctionCall == nil && last.Thought == part.Thought {
+			joined := *last
+			joined.Text += part.Text
+			if len(part.ThoughtSignature) > 0 {
+				joined.ThoughtSignature = part.ThoughtSignature
+			}
+			content.Parts[n-1] = &joined
+			return
+		}
+	}
+	copied := *part
+	content.Parts = append(content.Parts, &copied)
+}
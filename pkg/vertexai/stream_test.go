@@ -0,0 +1,69 @@
+package vertexai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCallAPIStream(t *testing.T) {
+	chunks := []string{
+		`{"candidates": [{"content": {"role": "model", "parts": [{"text": "{\"top_"}]}}], "responseId": "resp-1", "modelVersion": "gemini-2.5-pro"}`,
+		`{"candidates": [{"content": {"role": "model", "parts": [{"text": "candidates\": []}"}]}}]}`,
+		`{"candidates": [{"content": {"role": "model", "parts": [{"text": ""}]}, "finishReason": "STOP"}], "usageMetadata": {"promptTokenCount": 100, "candidatesTokenCount": 12}}`,
+	}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "streamGenerateContent") {
+			t.Errorf("Expected a streaming request, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+			w.(http.Flusher).Flush()
+		}
+	})
+
+	var streamed []string
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("rank")}, nil,
+		llm.WithStream(func(chunk llm.StreamChunk) { streamed = append(streamed, chunk.Text) }))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(streamed) != 2 || streamed[0] != `{"top_` {
+		t.Errorf("Expected 2 text chunks in order, got %q", streamed)
+	}
+	if len(resp.Content) != 1 || resp.Content[0].Text != `{"top_candidates": []}` {
+		t.Errorf("Expected deltas merged into one text block, got %+v", resp.Content)
+	}
+	if resp.ID != "resp-1" || resp.Model != "gemini-2.5-pro" || resp.Usage.InputTokens != 100 || resp.Usage.OutputTokens != 12 {
+		t.Errorf("Expected response metadata and final usage, got %+v", resp)
+	}
+}
+
+func TestCallAPIStreamToolUse(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `data: {"candidates": [{"content": {"role": "model", "parts": [{"functionCall": {"name": "search_github_developers", "args": {"language": "go"}}}]}, "finishReason": "STOP"}]}`+"\n\n")
+	})
+
+	var toolUses []*llm.ContentBlock
+	resp, err := client.CallAPI(context.Background(), []llm.Message{llm.UserText("find")}, nil,
+		llm.WithStream(func(chunk llm.StreamChunk) {
+			if chunk.ToolUse != nil {
+				toolUses = append(toolUses, chunk.ToolUse)
+			}
+		}))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(toolUses) != 1 || toolUses[0].Name != "search_github_developers" {
+		t.Errorf("Expected streamed tool call, got %+v", toolUses)
+	}
+	if resp.StopReason != "tool_use" {
+		t.Errorf("Expected tool_use stop reason, got %s", resp.StopReason)
+	}
+}
@@ -0,0 +1,48 @@
+package vertexai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+// thinkingFor returns the thinking config for a call with options, preferring
+// the stage override over the client default
+func (c *Client) thinkingFor(options llm.Options) *genai.ThinkingConfig {
+	if thinking, ok := c.StageThinking[options.Stage]; ok && thinking != nil {
+		return thinking
+	}
+	return c.Thinking
+}
+
+// ThinkingBudget returns a thinking config capping reasoning at budget tokens.
+// A budget of 0 disables thinking on models that allow it and -1 lets the
+// model decide.
+func ThinkingBudget(budget int32, includeThoughts bool) *genai.ThinkingConfig {
+	return &genai.ThinkingConfig{ThinkingBudget: &budget, IncludeThoughts: includeThoughts}
+}
+
+// ParseThinkingBudgets parses per-stage thinking budgets such as
+// "strategy=1024,ranking=8192"
+func ParseThinkingBudgets(spec string, includeThoughts bool) (map[string]*genai.ThinkingConfig, error) {
+	budgets := make(map[string]*genai.ThinkingConfig)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		stage, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid thinking budget %q: expected stage=tokens", pair)
+		}
+		budget, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil || budget < -1 {
+			return nil, fmt.Errorf("invalid thinking budget %q: expected a token count", pair)
+		}
+		budgets[strings.TrimSpace(stage)] = ThinkingBudget(int32(budget), includeThoughts)
+	}
+	return budgets, nil
+}
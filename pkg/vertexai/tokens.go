@@ -0,0 +1,32 @@
+package vertexai
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"google.golang.org/genai"
+)
+
+var _ llm.TokenCounter = (*Client)(nil)
+
+// CountTokens counts the prompt tokens for messages and tools with Gemini's
+// tokenizer, so payloads can be checked against the input limit before a call
+func (c *Client) CountTokens(ctx context.Context, messages []llm.Message, tools []llm.Tool) (int, error) {
+	contents, systemInstruction := convertMessages(messages)
+
+	config := &genai.CountTokensConfig{SystemInstruction: systemInstruction}
+	if len(tools) > 0 {
+		tool := &genai.Tool{}
+		for _, t := range tools {
+			tool.FunctionDeclarations = append(tool.FunctionDeclarations, convertTool(t))
+		}
+		config.Tools = []*genai.Tool{tool}
+	}
+
+	resp, err := c.client.Models.CountTokens(ctx, c.modelFor(llm.Options{}), contents, config)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", convertError(err))
+	}
+	return int(resp.TotalTokens), nil
+}
@@ -0,0 +1,49 @@
+package vertexai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+)
+
+func TestCountTokens(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ":countTokens") {
+			t.Errorf("Expected a countTokens request, got %s", r.URL.Path)
+		}
+		var body struct {
+			Contents []json.RawMessage `json:"contents"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		if len(body.Contents) != 1 {
+			t.Errorf("Expected 1 content, got %d", len(body.Contents))
+		}
+		w.Write([]byte(`{"totalTokens": 1234}`))
+	})
+
+	n, err := client.CountTokens(context.Background(), []llm.Message{llm.UserText("Input Data: {}")}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if n != 1234 {
+		t.Errorf("Expected 1234 tokens, got %d", n)
+	}
+}
+
+func TestCountTokensError(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"error": {"code": 503, "message": "unavailable", "status": "UNAVAILABLE"}}`))
+	})
+
+	_, err := client.CountTokens(context.Background(), []llm.Message{llm.UserText("hi")}, nil)
+	if !llm.IsTransient(err) {
+		t.Errorf("Expected a transient error, got %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/luillyfe/sourcing-agent/pkg/anthropic"
+	"github.com/luillyfe/sourcing-agent/pkg/llm"
+	"github.com/luillyfe/sourcing-agent/pkg/openai"
+	"github.com/luillyfe/sourcing-agent/pkg/vertexai"
+)
+
+// LLM providers selectable with -llm or LLM_PROVIDER
+const (
+	providerVertexAI  = "vertexai"
+	providerAnthropic = "anthropic"
+	providerOpenAI    = "openai"
+	providerOllama    = "ollama"
+)
+
+// defaultOllamaBaseURL is the OpenAI-compatible endpoint of a local Ollama
+const defaultOllamaBaseURL = "http://localhost:11434/v1"
+
+// defaultOllamaEmbeddingModel embeds candidates for VECTOR_STORE_URL with Ollama
+const defaultOllamaEmbeddingModel = "nomic-embed-text"
+
+// llmProviders registers the providers -llm chooses from. Each factory
+// validates its own settings, so only the selected provider needs them.
+func llmProviders() *llm.Providers {
+	var providers llm.Providers
+	providers.Register(providerVertexAI, func(ctx context.Context) (llm.Client, error) {
+		client, err := newVertexClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	})
+	providers.Register(providerAnthropic, func(context.Context) (llm.Client, error) {
+		config, ok := anthropicPlatform()
+		if !ok {
+			return nil, errors.New("ANTHROPIC_API_KEY or ANTHROPIC_PLATFORM must be set")
+		}
+		return newAnthropicClient(config), nil
+	})
+	providers.Register(providerOpenAI, func(context.Context) (llm.Client, error) {
+		if err := requireEnv("OPENAI_API_KEY"); err != nil {
+			return nil, err
+		}
+		client := openai.NewClientWithConfig(openai.Config{
+			APIKey:      os.Getenv("OPENAI_API_KEY"),
+			BaseURL:     os.Getenv("OPENAI_BASE_URL"),
+			Model:       os.Getenv("OPENAI_MODEL"),
+			StageModels: envMap("OPENAI_STAGE_MODELS"),
+			MaxTokens:   envInt("OPENAI_MAX_TOKENS"),
+			Retry:       retryConfig(envInt("OPENAI_MAX_ATTEMPTS")),
+		})
+		if model := os.Getenv("OPENAI_EMBEDDING_MODEL"); model != "" {
+			client.EmbeddingModel = model
+		}
+		return client, nil
+	})
+	providers.Register(providerOllama, func(context.Context) (llm.Client, error) {
+		if err := requireEnv("OLLAMA_MODEL"); err != nil {
+			return nil, err
+		}
+		baseURL := os.Getenv("OLLAMA_BASE_URL")
+		if baseURL == "" {
+			baseURL = defaultOllamaBaseURL
+		}
+		client := openai.NewClientWithConfig(openai.Config{
+			BaseURL:     baseURL,
+			Model:       os.Getenv("OLLAMA_MODEL"),
+			StageModels: envMap("OLLAMA_STAGE_MODELS"),
+			Provider:    providerOllama,
+		})
+		client.EmbeddingModel = os.Getenv("OLLAMA_EMBEDDING_MODEL")
+		if client.EmbeddingModel == "" {
+			client.EmbeddingModel = defaultOllamaEmbeddingModel
+		}
+		// Local models on modest hardware can take minutes per call
+		client.HTTPClient.Timeout = 5 * time.Minute
+		return client, nil
+	})
+	return &providers
+}
+
+// llmFlag adds the -llm provider flag to fs, defaulting to LLM_PROVIDER or Vertex AI
+func llmFlag(fs *flag.FlagSet) *string {
+	return fs.String("llm", defaultLLMProvider(), "LLM `provider`: "+strings.Join(llmProviders().Names(), ", "))
+}
+
+// defaultLLMProvider returns the provider named by LLM_PROVIDER, or Vertex AI
+func defaultLLMProvider() string {
+	if provider := os.Getenv("LLM_PROVIDER"); provider != "" {
+		return provider
+	}
+	return providerVertexAI
+}
+
+// providerModel returns the default model of a provider's client
+func providerModel(client llm.Client) string {
+	switch c := client.(type) {
+	case *vertexai.Client:
+		return c.Model
+	case *anthropic.Client:
+		return c.Model
+	case *openai.Client:
+		return c.Model
+	}
+	return ""
+}
+
+// providerStageModels returns the per-stage model overrides of a provider's client
+func providerStageModels(client llm.Client) map[string]string {
+	switch c := client.(type) {
+	case *vertexai.Client:
+		return c.StageModels
+	case *anthropic.Client:
+		return c.StageModels
+	case *openai.Client:
+		return c.StageModels
+	}
+	return nil
+}
+
+// providerModelFor returns the model a provider's client uses for calls
+// labelled with each stage
+func providerModelFor(client llm.Client) func(stage string) string {
+	model, stageModels := providerModel(client), providerStageModels(client)
+	return func(stage string) string {
+		if stageModel := stageModels[stage]; stageModel != "" {
+			return stageModel
+		}
+		return model
+	}
+}
+
+// newAnthropicClient builds the Claude client from the ANTHROPIC_* settings
+func newAnthropicClient(config anthropic.Config) *anthropic.Client {
+	config.Model = os.Getenv("ANTHROPIC_MODEL")
+	config.StageModels = envMap("ANTHROPIC_STAGE_MODELS")
+	config.MaxTokens = envInt("ANTHROPIC_MAX_TOKENS")
+	config.Retry = retryConfig(envInt("ANTHROPIC_MAX_ATTEMPTS"))
+	config.ThinkingBudget = envInt("ANTHROPIC_THINKING_BUDGET")
+	config.StageThinkingBudgets = envIntMap("ANTHROPIC_STAGE_THINKING_BUDGETS")
+	return anthropic.NewClientWithConfig(config)
+}
+
+// requireEnv returns an error naming the keys that are not set
+func requireEnv(keys ...string) error {
+	var missing []string
+	for _, key := range keys {
+		if os.Getenv(key) == "" {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s must be set", strings.Join(missing, " and "))
+}
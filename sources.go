@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/luillyfe/sourcing-agent/pkg/agent"
+	"github.com/luillyfe/sourcing-agent/pkg/bitbucket"
+	"github.com/luillyfe/sourcing-agent/pkg/github"
+	"github.com/luillyfe/sourcing-agent/pkg/gitlab"
+)
+
+// Source platforms selectable with -source or SOURCE_PROVIDER
+const (
+	sourceGitHub    = "github"
+	sourceGitLab    = "gitlab"
+	sourceBitbucket = "bitbucket"
+)
+
+// sourceNames lists the source platforms, the default first
+var sourceNames = []string{sourceGitHub, sourceGitLab, sourceBitbucket}
+
+// sourceFlag adds the -source platform flag to fs, defaulting to SOURCE_PROVIDER or GitHub
+func sourceFlag(fs *flag.FlagSet) *string {
+	return fs.String("source", defaultSource(), "`platforms` developers are sourced from, comma-separated: "+strings.Join(sourceNames, ", "))
+}
+
+// defaultSource returns the platforms named by SOURCE_PROVIDER, or GitHub
+func defaultSource() string {
+	if source := os.Getenv("SOURCE_PROVIDER"); source != "" {
+		return source
+	}
+	return sourceGitHub
+}
+
+// checkSource exits with a usage error when names are not source platforms
+func checkSource(names string) {
+	if err := parseSources(names); err != nil {
+		exitf(exitUsage, "Error: %v\n", err)
+	}
+}
+
+// parseSources checks that names is a comma-separated list of distinct
+// source platforms
+func parseSources(names string) error {
+	var seen []string
+	for _, name := range splitList(names) {
+		if !slices.Contains(sourceNames, name) {
+			return fmt.Errorf("unknown source %q: want any of %s", name, strings.Join(sourceNames, ", "))
+		}
+		if slices.Contains(seen, name) {
+			return fmt.Errorf("source %q listed twice", name)
+		}
+		seen = append(seen, name)
+	}
+	return nil
+}
+
+// newSources builds the clients of the comma-separated source platforms
+// names. Several are combined in an agent.MultiSource, whose candidates are
+// merged across platforms by identity resolution.
+func newSources(names string, httpClient *http.Client, logger *slog.Logger) (agent.SourceProvider, error) {
+	list := splitList(names)
+	if len(list) <= 1 {
+		return newSourceClient(names, httpClient, logger)
+	}
+	var multi agent.MultiSource
+	for _, name := range list {
+		source, err := newSourceClient(name, httpClient, logger)
+		if err != nil {
+			return nil, err
+		}
+		multi = append(multi, agent.NamedSource{Name: name, SourceProvider: source})
+	}
+	return multi, nil
+}
+
+// newSourceClient builds the client of the source platform name from its
+// settings, sending its requests through httpClient. Only GitHub requires a
+// token; the others read public data without one, under a lower rate limit.
+func newSourceClient(name string, httpClient *http.Client, logger *slog.Logger) (agent.SourceProvider, error) {
+	switch name = strings.TrimSpace(name); name {
+	case "", sourceGitHub:
+		githubClient := github.NewClient(os.Getenv("GITHUB_TOKEN"))
+		githubClient.HTTPClient = httpClient
+		githubClient.Logger = logger
+		return githubClient, nil
+	case sourceGitLab:
+		gitlabClient := gitlab.NewClient(os.Getenv("GITLAB_TOKEN"))
+		if baseURL := os.Getenv("GITLAB_BASE_URL"); baseURL != "" {
+			gitlabClient.BaseURL = baseURL
+		}
+		gitlabClient.HTTPClient = httpClient
+		gitlabClient.Logger = logger
+		return gitlabClient, nil
+	case sourceBitbucket:
+		bitbucketClient := bitbucket.NewClient(os.Getenv("BITBUCKET_TOKEN"))
+		if username := os.Getenv("BITBUCKET_USERNAME"); username != "" {
+			if err := requireEnv("BITBUCKET_APP_PASSWORD"); err != nil {
+				return nil, fmt.Errorf("with BITBUCKET_USERNAME, %w", err)
+			}
+			bitbucketClient.Username, bitbucketClient.Password = username, os.Getenv("BITBUCKET_APP_PASSWORD")
+		}
+		bitbucketClient.HTTPClient = httpClient
+		bitbucketClient.Logger = logger
+		return bitbucketClient, nil
+	default:
+		return nil, fmt.Errorf("unknown source %q: want one of %s", name, strings.Join(sourceNames, ", "))
+	}
+}